@@ -0,0 +1,61 @@
+// Command auditverify walks the activity_events hash chain offline and prints
+// the verification report as JSON. It exits 1 when the chain is broken, so it
+// can gate a cron job or CI step without the server running.
+//
+// The database and the checkpoint signing key come from the same .env / DB_*
+// and AUDIT_CHAIN_KEY (or AES_SECRET) settings the server uses.
+//
+// Usage: go run cmd/auditverify/main.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/vukyn/isme/internal/config"
+	activityRepo "github.com/vukyn/isme/internal/domains/activity/repository"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+
+	kueryDb "github.com/vukyn/kuery/bun/db"
+)
+
+func main() {
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := kueryDb.Open(kueryDb.Config{
+		Driver:      kueryDb.Driver(cfg.DB.Driver),
+		SQLitePath:  cfg.DB.SQLitePath,
+		PostgresDSN: cfg.DB.DSN,
+		Host:        cfg.DB.Host,
+		Port:        cfg.DB.Port,
+		User:        cfg.DB.User,
+		Password:    cfg.DB.Password,
+		DBName:      cfg.DB.DBName,
+		SSLMode:     cfg.DB.SSLMode,
+	})
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	uc := activityUsecase.NewUsecase(activityRepo.NewRepository(db), cfg, sink.NoopPublisher{})
+	report, err := uc.VerifyChain(context.Background())
+	if err != nil {
+		log.Fatalf("verification failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("failed to print report: %v", err)
+	}
+	if !report.Valid {
+		os.Exit(1)
+	}
+}
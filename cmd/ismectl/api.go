@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/external/admin/models"
+	"github.com/vukyn/isme/external/admin/services"
+	settingsEntity "github.com/vukyn/isme/internal/domains/settings/entity"
+	"github.com/vukyn/isme/internal/rbacmanifest"
+)
+
+// listPageSize is the page size the API backend walks list endpoints with.
+const listPageSize = 100
+
+// apiBackend drives a running isme through the admin SDK, as the user behind
+// the token; isme enforces that user's permissions and records them as the
+// actor.
+type apiBackend struct {
+	svc services.IService
+}
+
+func newAPIBackend(endpoint, token string) *apiBackend {
+	return &apiBackend{svc: services.NewService(endpoint, services.StaticToken(token))}
+}
+
+func (b *apiBackend) Close() error { return nil }
+
+func (b *apiBackend) ListUsers(ctx context.Context, filter userFilter) ([]userRow, error) {
+	res, err := b.svc.ListUsers(ctx, &models.ListUsersRequest{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Search:   filter.Search,
+		AppCode:  filter.AppCode,
+		RoleCode: filter.RoleCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]userRow, 0, len(res.Items))
+	for _, user := range res.Items {
+		roles := make([]string, 0, len(user.Roles))
+		for _, role := range user.Roles {
+			roles = append(roles, role.AppCode+"/"+role.RoleCode)
+		}
+		rows = append(rows, userRow{
+			ID:       user.ID,
+			Name:     user.Name,
+			Email:    user.Email,
+			Status:   statusName(userStatusNames, user.Status),
+			Verified: user.IsVerified,
+			Roles:    roles,
+		})
+	}
+	return rows, nil
+}
+
+func (b *apiBackend) CreateUser(context.Context, string, string, string) (userRow, error) {
+	return userRow{}, fmt.Errorf("create user: %w (invite the user from the console instead)", errAPIUnsupported)
+}
+
+func (b *apiBackend) SetUserStatus(ctx context.Context, user string, status int32) error {
+	userID, err := b.userID(ctx, user)
+	if err != nil {
+		return err
+	}
+	return b.svc.UpdateUserStatus(ctx, &models.UpdateUserStatusRequest{UserID: userID, Status: status})
+}
+
+func (b *apiBackend) ListRoles(ctx context.Context, appCode string) ([]roleRow, error) {
+	roles, err := b.svc.ListRoles(ctx, &models.ListRolesRequest{AppCode: appCode})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]roleRow, 0, len(roles))
+	for _, role := range roles {
+		rows = append(rows, roleRow{
+			ID:       role.ID,
+			AppCode:  role.AppCode,
+			Code:     role.Code,
+			Name:     role.Name,
+			IsSystem: role.IsSystem,
+			Members:  role.MembersCount,
+		})
+	}
+	return rows, nil
+}
+
+func (b *apiBackend) AssignRole(ctx context.Context, user, appCode, roleCode string) error {
+	userID, err := b.userID(ctx, user)
+	if err != nil {
+		return err
+	}
+	role, err := b.role(ctx, appCode, roleCode)
+	if err != nil {
+		return err
+	}
+	return b.svc.AddRoleMembers(ctx, &models.AddRoleMembersRequest{RoleID: role.ID, UserIDs: []string{userID}})
+}
+
+func (b *apiBackend) RevokeRole(ctx context.Context, user, appCode, roleCode string) error {
+	userID, err := b.userID(ctx, user)
+	if err != nil {
+		return err
+	}
+	role, err := b.role(ctx, appCode, roleCode)
+	if err != nil {
+		return err
+	}
+	return b.svc.RemoveRoleMember(ctx, &models.RemoveRoleMemberRequest{RoleID: role.ID, UserID: userID, AppServiceID: role.AppID})
+}
+
+func (b *apiBackend) ListApps(ctx context.Context) ([]appRow, error) {
+	apps, err := b.apps(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]appRow, 0, len(apps))
+	for _, app := range apps {
+		rows = append(rows, appRow{
+			ID:          app.ID,
+			Code:        app.AppCode,
+			Name:        app.AppName,
+			Status:      statusName(appStatusNames, app.Status),
+			CtxInfo:     app.CtxInfo,
+			RedirectURL: app.RedirectURL,
+		})
+	}
+	return rows, nil
+}
+
+func (b *apiBackend) RegisterApp(ctx context.Context, app appRegistration) (secretRow, error) {
+	res, err := b.svc.RegisterAppService(ctx, &models.RegisterAppServiceRequest{
+		AppCode:     app.Code,
+		AppName:     app.Name,
+		RedirectURL: app.RedirectURL,
+		CtxInfo:     app.CtxInfo,
+	})
+	if err != nil {
+		return secretRow{}, err
+	}
+	return secretRow{AppCode: app.Code, AppSecret: res.AppSecret}, nil
+}
+
+func (b *apiBackend) RotateSecret(ctx context.Context, appCode, currentSecret string) (secretRow, error) {
+	if currentSecret == "" {
+		return secretRow{}, fmt.Errorf("rotate secret: the current secret is required over the API (-secret)")
+	}
+	apps, err := b.apps(ctx, appCode)
+	if err != nil {
+		return secretRow{}, err
+	}
+	for _, app := range apps {
+		if app.AppCode != appCode {
+			continue
+		}
+		res, err := b.svc.RefreshAppServiceSecret(ctx, &models.RefreshAppServiceSecretRequest{
+			AppCode:   appCode,
+			AppSecret: currentSecret,
+			CtxInfo:   app.CtxInfo,
+		})
+		if err != nil {
+			return secretRow{}, err
+		}
+		return secretRow{AppCode: appCode, AppSecret: res.AppSecret}, nil
+	}
+	return secretRow{}, fmt.Errorf("app %s not found", appCode)
+}
+
+func (b *apiBackend) ListSessions(ctx context.Context, user string) ([]sessionRow, error) {
+	userID, err := b.userID(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := b.svc.ListUserSessions(ctx, &models.UserRequest{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]sessionRow, 0, len(sessions))
+	for _, session := range sessions {
+		rows = append(rows, sessionRow{
+			ID:          session.ID,
+			ClientIP:    session.ClientIP,
+			UserAgent:   session.UserAgent,
+			LastLoginAt: session.LastLoginAt,
+			ExpiresAt:   session.ExpiresAt,
+		})
+	}
+	return rows, nil
+}
+
+func (b *apiBackend) RevokeSession(ctx context.Context, user, sessionID string) error {
+	userID, err := b.userID(ctx, user)
+	if err != nil {
+		return err
+	}
+	return b.svc.RevokeUserSession(ctx, &models.RevokeUserSessionRequest{UserID: userID, SessionID: sessionID})
+}
+
+func (b *apiBackend) ListJobs(ctx context.Context) ([]jobRow, error) {
+	req := &models.SettingsRequest{}
+	sessionRevoke, err := b.svc.GetSessionRevokeSettings(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rotationCleanup, err := b.svc.GetRotationCleanupSettings(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	activityCleanup, err := b.svc.GetActivityCleanupSettings(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	databaseBackup, err := b.svc.GetDatabaseBackupSettings(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return []jobRow{
+		{Key: settingsEntity.JobKeySessionRevoke, Enabled: sessionRevoke.Enabled, Cron: sessionRevoke.Cron, LastRunAt: unixTime(sessionRevoke.LastRunAt)},
+		{Key: settingsEntity.JobKeyRotationCleanup, Enabled: rotationCleanup.Enabled, Cron: rotationCleanup.Cron, LastRunAt: unixTime(rotationCleanup.LastRunAt)},
+		{Key: settingsEntity.JobKeyActivityCleanup, Enabled: activityCleanup.Enabled, Cron: activityCleanup.Cron, LastRunAt: unixTime(activityCleanup.LastRunAt)},
+		{Key: settingsEntity.JobKeyDatabaseBackup, Enabled: databaseBackup.Enabled, Cron: databaseBackup.Cron, LastRunAt: unixTime(databaseBackup.LastRunAt)},
+	}, nil
+}
+
+func (b *apiBackend) RunJob(context.Context, string) (jobRow, error) {
+	return jobRow{}, fmt.Errorf("run job: %w", errAPIUnsupported)
+}
+
+func (b *apiBackend) PlanRBAC(context.Context, *rbacmanifest.Manifest, bool) ([]rbacmanifest.Change, error) {
+	return nil, fmt.Errorf("rbac plan: %w", errAPIUnsupported)
+}
+
+func (b *apiBackend) ApplyRBAC(context.Context, *rbacmanifest.Manifest, bool) (rbacmanifest.Result, error) {
+	return rbacmanifest.Result{}, fmt.Errorf("rbac apply: %w", errAPIUnsupported)
+}
+
+// userID resolves an email to the user's id; anything else is taken as an id.
+func (b *apiBackend) userID(ctx context.Context, user string) (string, error) {
+	if !strings.Contains(user, "@") {
+		return user, nil
+	}
+	res, err := b.svc.ListUsers(ctx, &models.ListUsersRequest{Search: user, PageSize: listPageSize})
+	if err != nil {
+		return "", err
+	}
+	for _, item := range res.Items {
+		if strings.EqualFold(item.Email, user) {
+			return item.ID, nil
+		}
+	}
+	return "", fmt.Errorf("user %s not found", user)
+}
+
+func (b *apiBackend) role(ctx context.Context, appCode, roleCode string) (models.Role, error) {
+	roles, err := b.svc.ListRoles(ctx, &models.ListRolesRequest{AppCode: appCode})
+	if err != nil {
+		return models.Role{}, err
+	}
+	for _, role := range roles {
+		if role.Code == roleCode {
+			return role, nil
+		}
+	}
+	return models.Role{}, fmt.Errorf("role %s not found in app %s", roleCode, appCode)
+}
+
+// apps walks every page of the app list.
+func (b *apiBackend) apps(ctx context.Context, search string) ([]models.AppService, error) {
+	var apps []models.AppService
+	for page := 1; ; page++ {
+		res, err := b.svc.ListAppServices(ctx, &models.ListAppServicesRequest{Page: page, PageSize: listPageSize, Search: search})
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, res.Items...)
+		if len(res.Items) == 0 || int64(len(apps)) >= res.Total {
+			return apps, nil
+		}
+	}
+}
+
+func unixTime(seconds *int64) string {
+	if seconds == nil {
+		return ""
+	}
+	return time.Unix(*seconds, 0).UTC().Format(time.RFC3339)
+}
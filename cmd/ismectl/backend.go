@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vukyn/isme/internal/rbacmanifest"
+)
+
+// errAPIUnsupported is returned by the API backend for the operations isme
+// exposes no endpoint for; they need direct database access.
+var errAPIUnsupported = errors.New("not available over the API; run ismectl on the isme host without -api")
+
+// backend is what the commands drive: the database on the isme host, or the
+// admin API of a running isme. Users are addressed by id or email, apps and
+// roles by code.
+type backend interface {
+	ListUsers(ctx context.Context, filter userFilter) ([]userRow, error)
+	CreateUser(ctx context.Context, name, email, password string) (userRow, error)
+	SetUserStatus(ctx context.Context, user string, status int32) error
+
+	ListRoles(ctx context.Context, appCode string) ([]roleRow, error)
+	AssignRole(ctx context.Context, user, appCode, roleCode string) error
+	RevokeRole(ctx context.Context, user, appCode, roleCode string) error
+
+	ListApps(ctx context.Context) ([]appRow, error)
+	RegisterApp(ctx context.Context, app appRegistration) (secretRow, error)
+	// RotateSecret issues a new secret for the app. currentSecret is only
+	// needed over the API, where isme insists on it.
+	RotateSecret(ctx context.Context, appCode, currentSecret string) (secretRow, error)
+
+	ListSessions(ctx context.Context, user string) ([]sessionRow, error)
+	RevokeSession(ctx context.Context, user, sessionID string) error
+
+	ListJobs(ctx context.Context) ([]jobRow, error)
+	RunJob(ctx context.Context, key string) (jobRow, error)
+
+	// PlanRBAC lists what ApplyRBAC would change to converge app services,
+	// permission catalogs and roles on the manifest.
+	PlanRBAC(ctx context.Context, m *rbacmanifest.Manifest, prune bool) ([]rbacmanifest.Change, error)
+	ApplyRBAC(ctx context.Context, m *rbacmanifest.Manifest, prune bool) (rbacmanifest.Result, error)
+
+	Close() error
+}
+
+type userFilter struct {
+	Search   string
+	AppCode  string
+	RoleCode string
+	Page     int
+	PageSize int
+}
+
+type appRegistration struct {
+	Code        string
+	Name        string
+	RedirectURL string
+	CtxInfo     string
+}
+
+type userRow struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	Status   string   `json:"status"`
+	Verified bool     `json:"verified"`
+	Roles    []string `json:"roles"`
+}
+
+type roleRow struct {
+	ID       string `json:"id"`
+	AppCode  string `json:"app_code"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	IsSystem bool   `json:"is_system"`
+	Members  int    `json:"members"`
+}
+
+type appRow struct {
+	ID          string `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	CtxInfo     string `json:"ctx_info"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+type secretRow struct {
+	AppCode   string `json:"app_code"`
+	AppSecret string `json:"app_secret"`
+}
+
+type sessionRow struct {
+	ID          string `json:"id"`
+	ClientIP    string `json:"client_ip"`
+	UserAgent   string `json:"user_agent"`
+	LastLoginAt string `json:"last_login_at"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+type jobRow struct {
+	Key       string `json:"key"`
+	Enabled   bool   `json:"enabled"`
+	Cron      string `json:"cron"`
+	LastRunAt string `json:"last_run_at"`
+	// LastResult is the job's own summary of its last run, as JSON; the API
+	// does not expose it.
+	LastResult string `json:"last_result,omitempty"`
+}
+
+var (
+	userStatusNames = map[int32]string{1: "active", 2: "inactive"}
+	appStatusNames  = map[int32]string{1: "active", 2: "inactive", 3: "terminated"}
+)
+
+func statusName(names map[int32]string, status int32) string {
+	if name, ok := names[status]; ok {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	userConstants "github.com/vukyn/isme/internal/domains/user/constants"
+	"github.com/vukyn/isme/internal/rbacmanifest"
+
+	"github.com/vukyn/kuery/cryp/rand"
+)
+
+// errUsage marks a malformed command line; main prints the command's usage.
+var errUsage = errors.New("usage")
+
+type env struct {
+	backend backend
+	out     printer
+	stderr  io.Writer
+}
+
+type command struct {
+	// usage lists the command's flags, then its arguments
+	usage string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"users list":         {"[-search TEXT] [-app CODE -role CODE] [-page N] [-size N]", usersList},
+	"users create":       {"-email EMAIL -name NAME [-password PASSWORD]", usersCreate},
+	"users activate":     {"USER", usersSetStatus(userConstants.UserStatusActive, "activated")},
+	"users deactivate":   {"USER", usersSetStatus(userConstants.UserStatusInactive, "deactivated")},
+	"roles list":         {"[-app CODE]", rolesList},
+	"roles assign":       {"-app CODE -role CODE USER", rolesAssign},
+	"roles revoke":       {"-app CODE -role CODE USER", rolesRevoke},
+	"apps list":          {"", appsList},
+	"apps register":      {"-code CODE -name NAME -redirect-url URL [-ctx-info authen|app_service]", appsRegister},
+	"apps rotate-secret": {"[-secret CURRENT] CODE", appsRotateSecret},
+	"sessions list":      {"USER", sessionsList},
+	"sessions revoke":    {"USER SESSION_ID", sessionsRevoke},
+	"jobs list":          {"", jobsList},
+	"jobs run":           {"KEY", jobsRun},
+	"rbac plan":          {"[-prune] FILE", rbacPlan},
+	"rbac apply":         {"[-prune] FILE", rbacApply},
+}
+
+// parse parses the command's flags and checks it got exactly nargs arguments.
+func parse(fs *flag.FlagSet, args []string, nargs int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() != nargs {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+func usersList(ctx context.Context, e *env, args []string) error {
+	var filter userFilter
+	fs := flag.NewFlagSet("users list", flag.ContinueOnError)
+	fs.StringVar(&filter.Search, "search", "", "")
+	fs.StringVar(&filter.AppCode, "app", "", "")
+	fs.StringVar(&filter.RoleCode, "role", "", "")
+	fs.IntVar(&filter.Page, "page", 1, "")
+	fs.IntVar(&filter.PageSize, "size", 50, "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if filter.RoleCode != "" && filter.AppCode == "" {
+		return fmt.Errorf("%w: -role needs -app", errUsage)
+	}
+
+	users, err := e.backend.ListUsers(ctx, filter)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, []string{user.ID, user.Email, user.Name, user.Status, yesNo(user.Verified), strings.Join(user.Roles, ",")})
+	}
+	return e.out.table(users, []string{"ID", "EMAIL", "NAME", "STATUS", "VERIFIED", "ROLES"}, rows)
+}
+
+func usersCreate(ctx context.Context, e *env, args []string) error {
+	var email, name, password string
+	fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+	fs.StringVar(&email, "email", "", "")
+	fs.StringVar(&name, "name", "", "")
+	fs.StringVar(&password, "password", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if email == "" || name == "" {
+		return fmt.Errorf("%w: -email and -name are required", errUsage)
+	}
+	generated := password == ""
+	if generated {
+		password = rand.RandMixedString(16, true, true)
+	}
+
+	user, err := e.backend.CreateUser(ctx, name, email, password)
+	if err != nil {
+		return err
+	}
+	result := struct {
+		userRow
+		// Password is echoed only when ismectl generated it
+		Password string `json:"password,omitempty"`
+	}{userRow: user}
+	fields := [][2]string{{"id", user.ID}, {"email", user.Email}, {"name", user.Name}}
+	if generated {
+		result.Password = password
+		fields = append(fields, [2]string{"password", password})
+	}
+	return e.out.record(result, fields)
+}
+
+func usersSetStatus(status int32, verb string) func(context.Context, *env, []string) error {
+	return func(ctx context.Context, e *env, args []string) error {
+		args, err := parse(flag.NewFlagSet("users", flag.ContinueOnError), args, 1)
+		if err != nil {
+			return err
+		}
+		if err := e.backend.SetUserStatus(ctx, args[0], status); err != nil {
+			return err
+		}
+		return e.out.done(fmt.Sprintf("user %s %s", args[0], verb))
+	}
+}
+
+func rolesList(ctx context.Context, e *env, args []string) error {
+	var appCode string
+	fs := flag.NewFlagSet("roles list", flag.ContinueOnError)
+	fs.StringVar(&appCode, "app", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	roles, err := e.backend.ListRoles(ctx, appCode)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(roles))
+	for _, role := range roles {
+		rows = append(rows, []string{role.ID, role.AppCode, role.Code, role.Name, yesNo(role.IsSystem), fmt.Sprint(role.Members)})
+	}
+	return e.out.table(roles, []string{"ID", "APP", "CODE", "NAME", "SYSTEM", "MEMBERS"}, rows)
+}
+
+// roleFlags parses the -app/-role pair and the user argument shared by assign
+// and revoke.
+func roleFlags(name string, args []string) (user, appCode, roleCode string, err error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&appCode, "app", "", "")
+	fs.StringVar(&roleCode, "role", "", "")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return "", "", "", err
+	}
+	if appCode == "" || roleCode == "" {
+		return "", "", "", fmt.Errorf("%w: -app and -role are required", errUsage)
+	}
+	return rest[0], appCode, roleCode, nil
+}
+
+func rolesAssign(ctx context.Context, e *env, args []string) error {
+	user, appCode, roleCode, err := roleFlags("roles assign", args)
+	if err != nil {
+		return err
+	}
+	if err := e.backend.AssignRole(ctx, user, appCode, roleCode); err != nil {
+		return err
+	}
+	return e.out.done(fmt.Sprintf("assigned %s/%s to %s", appCode, roleCode, user))
+}
+
+func rolesRevoke(ctx context.Context, e *env, args []string) error {
+	user, appCode, roleCode, err := roleFlags("roles revoke", args)
+	if err != nil {
+		return err
+	}
+	if err := e.backend.RevokeRole(ctx, user, appCode, roleCode); err != nil {
+		return err
+	}
+	return e.out.done(fmt.Sprintf("revoked %s/%s from %s", appCode, roleCode, user))
+}
+
+func appsList(ctx context.Context, e *env, args []string) error {
+	if _, err := parse(flag.NewFlagSet("apps list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+	apps, err := e.backend.ListApps(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(apps))
+	for _, app := range apps {
+		rows = append(rows, []string{app.ID, app.Code, app.Name, app.Status, app.CtxInfo, app.RedirectURL})
+	}
+	return e.out.table(apps, []string{"ID", "CODE", "NAME", "STATUS", "CTX_INFO", "REDIRECT_URL"}, rows)
+}
+
+func appsRegister(ctx context.Context, e *env, args []string) error {
+	var app appRegistration
+	fs := flag.NewFlagSet("apps register", flag.ContinueOnError)
+	fs.StringVar(&app.Code, "code", "", "")
+	fs.StringVar(&app.Name, "name", "", "")
+	fs.StringVar(&app.RedirectURL, "redirect-url", "", "")
+	fs.StringVar(&app.CtxInfo, "ctx-info", "authen", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if app.Code == "" || app.Name == "" || app.RedirectURL == "" {
+		return fmt.Errorf("%w: -code, -name and -redirect-url are required", errUsage)
+	}
+
+	secret, err := e.backend.RegisterApp(ctx, app)
+	if err != nil {
+		return err
+	}
+	return e.printSecret(secret)
+}
+
+func appsRotateSecret(ctx context.Context, e *env, args []string) error {
+	var current string
+	fs := flag.NewFlagSet("apps rotate-secret", flag.ContinueOnError)
+	fs.StringVar(&current, "secret", "", "")
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	secret, err := e.backend.RotateSecret(ctx, args[0], current)
+	if err != nil {
+		return err
+	}
+	return e.printSecret(secret)
+}
+
+// printSecret prints a freshly issued app secret; isme cannot show it again.
+func (e *env) printSecret(secret secretRow) error {
+	if e.out.format != outputJSON {
+		fmt.Fprintln(e.stderr, "Store the secret now, it cannot be shown again.")
+	}
+	return e.out.record(secret, [][2]string{{"app_code", secret.AppCode}, {"app_secret", secret.AppSecret}})
+}
+
+func sessionsList(ctx context.Context, e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("sessions list", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	sessions, err := e.backend.ListSessions(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(sessions))
+	for _, session := range sessions {
+		rows = append(rows, []string{session.ID, session.ClientIP, session.LastLoginAt, session.ExpiresAt, session.UserAgent})
+	}
+	return e.out.table(sessions, []string{"ID", "CLIENT_IP", "LAST_LOGIN_AT", "EXPIRES_AT", "USER_AGENT"}, rows)
+}
+
+func sessionsRevoke(ctx context.Context, e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("sessions revoke", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+	if err := e.backend.RevokeSession(ctx, args[0], args[1]); err != nil {
+		return err
+	}
+	return e.out.done(fmt.Sprintf("session %s revoked", args[1]))
+}
+
+func jobsList(ctx context.Context, e *env, args []string) error {
+	if _, err := parse(flag.NewFlagSet("jobs list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+	jobs, err := e.backend.ListJobs(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(jobs))
+	for _, job := range jobs {
+		rows = append(rows, jobRowCells(job))
+	}
+	return e.out.table(jobs, []string{"KEY", "ENABLED", "CRON", "LAST_RUN_AT", "LAST_RESULT"}, rows)
+}
+
+func jobsRun(ctx context.Context, e *env, args []string) error {
+	args, err := parse(flag.NewFlagSet("jobs run", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	job, err := e.backend.RunJob(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.out.table(job, []string{"KEY", "ENABLED", "CRON", "LAST_RUN_AT", "LAST_RESULT"}, [][]string{jobRowCells(job)})
+}
+
+func jobRowCells(job jobRow) []string {
+	return []string{job.Key, yesNo(job.Enabled), job.Cron, job.LastRunAt, job.LastResult}
+}
+
+// rbacFlags parses a manifest command: -prune also deletes the roles and
+// permissions of the manifest's apps that it does not declare.
+func rbacFlags(name string, args []string) (*rbacmanifest.Manifest, bool, error) {
+	var prune bool
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&prune, "prune", false, "")
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, false, err
+	}
+	m, err := rbacmanifest.Load(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+	return m, prune, nil
+}
+
+func rbacPlan(ctx context.Context, e *env, args []string) error {
+	m, prune, err := rbacFlags("rbac plan", args)
+	if err != nil {
+		return err
+	}
+	changes, err := e.backend.PlanRBAC(ctx, m, prune)
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		changes = []rbacmanifest.Change{}
+	}
+	if len(changes) == 0 && e.out.format != outputJSON {
+		return e.out.done("no changes: the database matches the manifest")
+	}
+	return e.printChanges(changes)
+}
+
+func rbacApply(ctx context.Context, e *env, args []string) error {
+	m, prune, err := rbacFlags("rbac apply", args)
+	if err != nil {
+		return err
+	}
+	result, err := e.backend.ApplyRBAC(ctx, m, prune)
+	if err != nil {
+		return err
+	}
+	if result.Changes == nil {
+		result.Changes = []rbacmanifest.Change{}
+	}
+	if result.Secrets == nil {
+		result.Secrets = []rbacmanifest.Secret{}
+	}
+	if e.out.format == outputJSON {
+		return e.out.json(result)
+	}
+	if len(result.Changes) == 0 {
+		return e.out.done("no changes: the database matches the manifest")
+	}
+	if err := e.printChanges(result.Changes); err != nil {
+		return err
+	}
+	for _, secret := range result.Secrets {
+		if err := e.printSecret(secretRow{AppCode: secret.AppCode, AppSecret: secret.AppSecret}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *env) printChanges(changes []rbacmanifest.Change) error {
+	rows := make([][]string, 0, len(changes))
+	for _, change := range changes {
+		rows = append(rows, []string{change.Action, change.Kind, change.App, change.Target, change.Detail})
+	}
+	return e.out.table(changes, []string{"ACTION", "KIND", "APP", "TARGET", "DETAIL"}, rows)
+}
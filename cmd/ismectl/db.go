@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/constants"
+	idi "github.com/vukyn/isme/internal/di"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+	appServiceModels "github.com/vukyn/isme/internal/domains/app_service/models"
+	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	appServiceUsecase "github.com/vukyn/isme/internal/domains/app_service/usecase"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
+	roleUsecase "github.com/vukyn/isme/internal/domains/role/usecase"
+	settingsEntity "github.com/vukyn/isme/internal/domains/settings/entity"
+	settingsRepo "github.com/vukyn/isme/internal/domains/settings/repository"
+	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
+	userModels "github.com/vukyn/isme/internal/domains/user/models"
+	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	userUsecase "github.com/vukyn/isme/internal/domains/user/usecase"
+	"github.com/vukyn/isme/internal/rbacmanifest"
+	"github.com/vukyn/isme/internal/transaction"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/vukyn/kuery/log"
+)
+
+// jobKeys lists the scheduled jobs in the order the console shows them.
+var jobKeys = []string{
+	settingsEntity.JobKeySessionRevoke,
+	settingsEntity.JobKeyRotationCleanup,
+	settingsEntity.JobKeyActivityCleanup,
+	settingsEntity.JobKeyDatabaseBackup,
+	settingsEntity.JobKeyRoleMemberExpiry,
+}
+
+// dbBackend works on the database directly, through the same usecases the
+// API handlers call. There is no signed-in caller, so audit rows record the
+// system actor. It can do what the API cannot: create a user with a
+// password, rotate a lost app secret and run a job on demand.
+type dbBackend struct {
+	app     di.Container
+	request di.Container
+	cfg     *config.Config
+
+	users          userUsecase.IUseCase
+	roles          roleUsecase.IUseCase
+	apps           appServiceUsecase.IUseCase
+	activity       activityUsecase.IUseCase
+	userRepo       userRepo.IRepository
+	roleRepo       roleRepo.IRepository
+	appServiceRepo appServiceRepo.IRepository
+	settingsRepo   settingsRepo.IRepository
+	txRunner       transaction.Runner
+}
+
+// newDBBackend builds the server's container from the same .env / DB_*
+// settings the server uses. Schedulers are never started.
+func newDBBackend() (*dbBackend, error) {
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	// command output goes to stdout; keep the server's info logs out of it
+	if err := log.Init(log.Config{Mode: cfg.Logger.Mode, Level: "warn"}); err != nil {
+		return nil, fmt.Errorf("init logger: %w", err)
+	}
+
+	builder := idi.NewBuilder()
+	// the stock config definition announces itself on stdout
+	err = builder.Add(&di.Def{
+		Name:  constants.CONTAINER_NAME_CONFIG,
+		Scope: di.App,
+		Build: func(di.Container) (any, error) { return cfg, nil },
+	})
+	if err != nil {
+		return nil, err
+	}
+	app, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("build container: %w", err)
+	}
+	request, err := app.SubContainer()
+	if err != nil {
+		_ = app.Delete()
+		return nil, err
+	}
+
+	b := &dbBackend{app: app, request: request, cfg: cfg, txRunner: idi.GetTxRunner(app)}
+	if err := b.resolve(); err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *dbBackend) resolve() (err error) {
+	if b.users, err = idi.GetUserUsecase(b.request); err != nil {
+		return err
+	}
+	if b.roles, err = idi.GetRoleUsecase(b.request); err != nil {
+		return err
+	}
+	if b.apps, err = idi.GetAppServiceUsecase(b.request); err != nil {
+		return err
+	}
+	if b.activity, err = idi.GetActivityUsecase(b.request); err != nil {
+		return err
+	}
+	if b.userRepo, err = idi.GetUserRepository(b.request); err != nil {
+		return err
+	}
+	if b.roleRepo, err = idi.GetRoleRepository(b.request); err != nil {
+		return err
+	}
+	if b.appServiceRepo, err = idi.GetAppServiceRepository(b.request); err != nil {
+		return err
+	}
+	b.settingsRepo, err = idi.GetSettingsRepository(b.request)
+	return err
+}
+
+func (b *dbBackend) Close() error {
+	_ = b.request.Delete()
+	return b.app.Delete()
+}
+
+func (b *dbBackend) ListUsers(ctx context.Context, filter userFilter) ([]userRow, error) {
+	res, err := b.users.List(ctx, userModels.ListRequest{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Search:   filter.Search,
+		AppCode:  filter.AppCode,
+		RoleID:   filter.RoleCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]userRow, 0, len(res.Items))
+	for _, user := range res.Items {
+		roles := make([]string, 0, len(user.Roles))
+		for _, role := range user.Roles {
+			roles = append(roles, role.AppCode+"/"+role.RoleCode)
+		}
+		rows = append(rows, userRow{
+			ID:       user.ID,
+			Name:     user.Name,
+			Email:    user.Email,
+			Status:   statusName(userStatusNames, user.Status),
+			Verified: user.IsVerified,
+			Roles:    roles,
+		})
+	}
+	return rows, nil
+}
+
+// CreateUser adds an active, verified user who can sign in with password
+// straight away, skipping the invitation flow.
+func (b *dbBackend) CreateUser(ctx context.Context, name, email, password string) (userRow, error) {
+	existing, err := b.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return userRow{}, err
+	}
+	if existing.ID != "" {
+		return userRow{}, fmt.Errorf("user %s already exists", email)
+	}
+
+	var userID string
+	err = b.txRunner.Run(ctx, func(ctx context.Context) error {
+		id, err := b.userRepo.Create(ctx, userModels.CreateRequest{Name: name, Email: email})
+		if err != nil {
+			return err
+		}
+		userID = id
+		if err := b.userRepo.SetPassword(ctx, id, password); err != nil {
+			return err
+		}
+		if err := b.userRepo.Verify(ctx, id); err != nil {
+			return err
+		}
+		return b.activity.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeUserCreated,
+			TargetType: activityConstants.TargetTypeUser,
+			TargetID:   id,
+			After:      map[string]any{"name": name, "email": email},
+			Meta:       map[string]any{"via": "ismectl"},
+		})
+	})
+	if err != nil {
+		return userRow{}, err
+	}
+	return userRow{ID: userID, Name: name, Email: email, Status: statusName(userStatusNames, 1), Verified: true, Roles: []string{}}, nil
+}
+
+func (b *dbBackend) SetUserStatus(ctx context.Context, user string, status int32) error {
+	found, err := b.user(ctx, user)
+	if err != nil {
+		return err
+	}
+	return b.users.UpdateStatus(ctx, found.ID, userModels.UpdateStatusRequest{Status: status})
+}
+
+func (b *dbBackend) ListRoles(ctx context.Context, appCode string) ([]roleRow, error) {
+	roles, err := b.roles.List(ctx, roleModels.ListRequest{AppCode: appCode})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]roleRow, 0, len(roles))
+	for _, role := range roles {
+		rows = append(rows, roleRow{
+			ID:       role.ID,
+			AppCode:  role.AppCode,
+			Code:     role.Code,
+			Name:     role.Name,
+			IsSystem: role.IsSystem,
+			Members:  role.MembersCount,
+		})
+	}
+	return rows, nil
+}
+
+func (b *dbBackend) AssignRole(ctx context.Context, user, appCode, roleCode string) error {
+	found, err := b.user(ctx, user)
+	if err != nil {
+		return err
+	}
+	role, err := b.role(ctx, appCode, roleCode)
+	if err != nil {
+		return err
+	}
+	return b.roles.AddMembers(ctx, role.ID, roleModels.AddMembersRequest{UserIDs: []string{found.ID}})
+}
+
+func (b *dbBackend) RevokeRole(ctx context.Context, user, appCode, roleCode string) error {
+	found, err := b.user(ctx, user)
+	if err != nil {
+		return err
+	}
+	role, err := b.role(ctx, appCode, roleCode)
+	if err != nil {
+		return err
+	}
+	return b.roles.RemoveMember(ctx, role.ID, found.ID, &role.AppID)
+}
+
+func (b *dbBackend) ListApps(ctx context.Context) ([]appRow, error) {
+	var rows []appRow
+	for page := 1; ; page++ {
+		res, err := b.apps.ListApps(ctx, appServiceModels.ListRequest{Page: page, PageSize: listPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, app := range res.Items {
+			rows = append(rows, appRow{
+				ID:          app.ID,
+				Code:        app.AppCode,
+				Name:        app.AppName,
+				Status:      statusName(appStatusNames, app.Status),
+				CtxInfo:     app.CtxInfo,
+				RedirectURL: app.RedirectURL,
+			})
+		}
+		if len(res.Items) == 0 || int64(len(rows)) >= res.Total {
+			return rows, nil
+		}
+	}
+}
+
+func (b *dbBackend) RegisterApp(ctx context.Context, app appRegistration) (secretRow, error) {
+	res, err := b.apps.RegisterApp(ctx, appServiceModels.RegisterRequest{
+		AppCode:     app.Code,
+		AppName:     app.Name,
+		RedirectURL: app.RedirectURL,
+		CtxInfo:     app.CtxInfo,
+	})
+	if err != nil {
+		return secretRow{}, err
+	}
+	return secretRow{AppCode: app.Code, AppSecret: res.AppSecret}, nil
+}
+
+// RotateSecret replaces the app's secret without the current one: unlike the
+// API's refresh, it is the way back when the secret is lost.
+func (b *dbBackend) RotateSecret(ctx context.Context, appCode, _ string) (secretRow, error) {
+	res, err := b.apps.RotateSecret(ctx, appServiceModels.RotateSecretRequest{AppCode: appCode, Via: "ismectl"})
+	if err != nil {
+		return secretRow{}, err
+	}
+	return secretRow{AppCode: appCode, AppSecret: res.AppSecret}, nil
+}
+
+func (b *dbBackend) ListSessions(ctx context.Context, user string) ([]sessionRow, error) {
+	found, err := b.user(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := b.users.ListSessions(ctx, found.ID)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]sessionRow, 0, len(sessions))
+	for _, session := range sessions {
+		rows = append(rows, sessionRow{
+			ID:          session.ID,
+			ClientIP:    session.ClientIP,
+			UserAgent:   session.UserAgent,
+			LastLoginAt: session.LastLoginAt,
+			ExpiresAt:   session.ExpiresAt,
+		})
+	}
+	return rows, nil
+}
+
+func (b *dbBackend) RevokeSession(ctx context.Context, user, sessionID string) error {
+	found, err := b.user(ctx, user)
+	if err != nil {
+		return err
+	}
+	return b.users.RevokeSession(ctx, found.ID, sessionID)
+}
+
+func (b *dbBackend) ListJobs(ctx context.Context) ([]jobRow, error) {
+	rows := make([]jobRow, 0, len(jobKeys))
+	for _, key := range jobKeys {
+		row, err := b.job(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// RunJob runs the job's body once, now, whether or not its schedule is
+// enabled. The body records its own run, which the returned row reflects.
+func (b *dbBackend) RunJob(ctx context.Context, key string) (jobRow, error) {
+	for _, job := range idi.SchedulerJobs(b.app) {
+		if string(job.Key) != key {
+			continue
+		}
+		if err := job.Run(ctx); err != nil {
+			return jobRow{}, fmt.Errorf("run %s: %w", key, err)
+		}
+		return b.job(ctx, key)
+	}
+	return jobRow{}, fmt.Errorf("unknown job %q (one of %s)", key, strings.Join(jobKeys, ", "))
+}
+
+func (b *dbBackend) PlanRBAC(ctx context.Context, m *rbacmanifest.Manifest, prune bool) ([]rbacmanifest.Change, error) {
+	return b.reconciler().Plan(ctx, m, prune)
+}
+
+func (b *dbBackend) ApplyRBAC(ctx context.Context, m *rbacmanifest.Manifest, prune bool) (rbacmanifest.Result, error) {
+	return b.reconciler().Apply(ctx, m, prune)
+}
+
+func (b *dbBackend) reconciler() *rbacmanifest.Reconciler {
+	return rbacmanifest.NewReconciler(b.roleRepo, b.appServiceRepo, b.roles, b.apps, b.txRunner)
+}
+
+func (b *dbBackend) job(ctx context.Context, key string) (jobRow, error) {
+	schedule, err := b.settingsRepo.GetSchedule(ctx, key)
+	if err != nil {
+		return jobRow{}, err
+	}
+	row := jobRow{Key: key, Enabled: schedule.Enabled, Cron: schedule.Cron}
+	if schedule.LastRunAt != nil {
+		row.LastRunAt = schedule.LastRunAt.UTC().Format(time.RFC3339)
+	}
+	if schedule.LastResult != nil {
+		row.LastResult = *schedule.LastResult
+	}
+	return row, nil
+}
+
+// user resolves an id or an email to the stored user.
+func (b *dbBackend) user(ctx context.Context, user string) (userEntity.User, error) {
+	var (
+		found userEntity.User
+		err   error
+	)
+	if strings.Contains(user, "@") {
+		found, err = b.userRepo.GetByEmail(ctx, user)
+	} else {
+		found, err = b.userRepo.GetByID(ctx, user)
+	}
+	if err != nil {
+		return userEntity.User{}, err
+	}
+	if found.ID == "" {
+		return userEntity.User{}, fmt.Errorf("user %s not found", user)
+	}
+	return found, nil
+}
+
+func (b *dbBackend) role(ctx context.Context, appCode, roleCode string) (roleModels.RoleListItem, error) {
+	roles, err := b.roles.List(ctx, roleModels.ListRequest{AppCode: appCode})
+	if err != nil {
+		return roleModels.RoleListItem{}, err
+	}
+	for _, role := range roles {
+		if role.Code == roleCode {
+			return role, nil
+		}
+	}
+	return roleModels.RoleListItem{}, fmt.Errorf("role %s not found in app %s", roleCode, appCode)
+}
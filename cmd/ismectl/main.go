@@ -0,0 +1,111 @@
+// Command ismectl runs administrative isme operations from a terminal or a
+// script: managing users, role assignments, apps, sessions and scheduled jobs,
+// and converging roles and permission catalogs on a reviewed manifest.
+//
+// By default it works directly against the database, using the same .env /
+// DB_* settings as the server, so it can bootstrap a fresh install or recover
+// a lost app secret. With -api it drives a running isme through the admin API
+// instead, acting as the user behind -token (or ISME_TOKEN); isme then checks
+// that user's permissions and records them in the audit log.
+//
+// Usage: go run ./cmd/ismectl [-api URL -token TOKEN] [-o table|json] <group> <action> [flags] [args]
+//
+// Run it without arguments for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// openFunc opens the backend selected by the global flags.
+type openFunc func(endpoint, token string) (backend, error)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, openBackend))
+}
+
+func openBackend(endpoint, token string) (backend, error) {
+	if endpoint != "" {
+		if token == "" {
+			return nil, errors.New("-api needs -token or ISME_TOKEN")
+		}
+		return newAPIBackend(endpoint, token), nil
+	}
+	return newDBBackend()
+}
+
+// run executes one command line and returns the process exit code: 0 on
+// success, 1 when the command failed and 2 when it was malformed.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, open openFunc) int {
+	var endpoint, token, format string
+	fs := flag.NewFlagSet("ismectl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&endpoint, "api", "", "")
+	fs.StringVar(&token, "token", os.Getenv("ISME_TOKEN"), "")
+	fs.StringVar(&format, "o", outputTable, "")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(stderr, "ismectl: %v\n", err)
+		printUsage(stderr)
+		return 2
+	}
+	if format != outputTable && format != outputJSON {
+		fmt.Fprintf(stderr, "ismectl: unknown output format %q\n", format)
+		return 2
+	}
+
+	args = fs.Args()
+	if len(args) < 2 {
+		printUsage(stderr)
+		return 2
+	}
+	name := args[0] + " " + args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "ismectl: unknown command %q\n", name)
+		printUsage(stderr)
+		return 2
+	}
+
+	b, err := open(endpoint, token)
+	if err != nil {
+		fmt.Fprintf(stderr, "ismectl: %v\n", err)
+		return 1
+	}
+	defer b.Close()
+
+	e := &env{backend: b, out: printer{w: stdout, format: format}, stderr: stderr}
+	if err := cmd.run(ctx, e, args[2:]); err != nil {
+		if errors.Is(err, errUsage) {
+			if msg := strings.TrimPrefix(err.Error(), errUsage.Error()+": "); msg != errUsage.Error() {
+				fmt.Fprintf(stderr, "ismectl %s: %s\n", name, msg)
+			}
+			fmt.Fprintf(stderr, "usage: ismectl %s %s\n", name, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "ismectl %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: ismectl [-api URL -token TOKEN] [-o table|json] <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "USER is an email or a user id. Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s %s\n", name, commands[name].usage)
+	}
+}
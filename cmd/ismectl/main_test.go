@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vukyn/isme/internal/rbacmanifest"
+)
+
+// fakeBackend records the calls it gets and serves canned rows.
+type fakeBackend struct {
+	calls  []string
+	closed bool
+}
+
+func (f *fakeBackend) record(call string) { f.calls = append(f.calls, call) }
+
+func (f *fakeBackend) ListUsers(_ context.Context, filter userFilter) ([]userRow, error) {
+	f.record("ListUsers " + filter.Search + " " + filter.AppCode + " " + filter.RoleCode)
+	return []userRow{{ID: "u1", Name: "Ada", Email: "ada@example.com", Status: "active", Verified: true, Roles: []string{"console/admin"}}}, nil
+}
+
+func (f *fakeBackend) CreateUser(_ context.Context, name, email, password string) (userRow, error) {
+	f.record("CreateUser " + name + " " + email + " " + password)
+	return userRow{ID: "u2", Name: name, Email: email, Status: "active", Verified: true, Roles: []string{}}, nil
+}
+
+func (f *fakeBackend) SetUserStatus(_ context.Context, user string, status int32) error {
+	f.record("SetUserStatus " + user + " " + statusName(userStatusNames, status))
+	return nil
+}
+
+func (f *fakeBackend) ListRoles(context.Context, string) ([]roleRow, error) { return []roleRow{}, nil }
+
+func (f *fakeBackend) AssignRole(_ context.Context, user, appCode, roleCode string) error {
+	f.record("AssignRole " + user + " " + appCode + " " + roleCode)
+	return nil
+}
+
+func (f *fakeBackend) RevokeRole(context.Context, string, string, string) error { return nil }
+
+func (f *fakeBackend) ListApps(context.Context) ([]appRow, error) { return []appRow{}, nil }
+
+func (f *fakeBackend) RegisterApp(context.Context, appRegistration) (secretRow, error) {
+	return secretRow{}, nil
+}
+
+func (f *fakeBackend) RotateSecret(_ context.Context, appCode, _ string) (secretRow, error) {
+	return secretRow{AppCode: appCode, AppSecret: "s3cret"}, nil
+}
+
+func (f *fakeBackend) ListSessions(context.Context, string) ([]sessionRow, error) {
+	return []sessionRow{}, nil
+}
+
+func (f *fakeBackend) RevokeSession(context.Context, string, string) error { return nil }
+
+func (f *fakeBackend) ListJobs(context.Context) ([]jobRow, error) { return []jobRow{}, nil }
+
+func (f *fakeBackend) RunJob(context.Context, string) (jobRow, error) {
+	return jobRow{}, errors.New("job failed")
+}
+
+func (f *fakeBackend) PlanRBAC(_ context.Context, m *rbacmanifest.Manifest, prune bool) ([]rbacmanifest.Change, error) {
+	f.record("PlanRBAC " + m.Apps[0].Code + " " + yesNo(prune))
+	return []rbacmanifest.Change{{Action: rbacmanifest.ActionCreate, Kind: rbacmanifest.KindRole, App: m.Apps[0].Code, Target: "editor"}}, nil
+}
+
+func (f *fakeBackend) ApplyRBAC(_ context.Context, m *rbacmanifest.Manifest, _ bool) (rbacmanifest.Result, error) {
+	return rbacmanifest.Result{
+		Changes: []rbacmanifest.Change{{Action: rbacmanifest.ActionCreate, Kind: rbacmanifest.KindApp, App: m.Apps[0].Code, Target: m.Apps[0].Code}},
+		Secrets: []rbacmanifest.Secret{{AppCode: m.Apps[0].Code, AppSecret: "s3cret"}},
+	}, nil
+}
+
+func (f *fakeBackend) Close() error {
+	f.closed = true
+	return nil
+}
+
+func runFake(t *testing.T, args ...string) (*fakeBackend, int, string, string) {
+	t.Helper()
+	fake := &fakeBackend{}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr, func(string, string) (backend, error) {
+		return fake, nil
+	})
+	return fake, code, stdout.String(), stderr.String()
+}
+
+func TestRunTableOutput(t *testing.T) {
+	fake, code, stdout, _ := runFake(t, "users", "list", "-search", "ada", "-app", "console", "-role", "admin")
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if got, want := strings.Join(fake.calls, ";"), "ListUsers ada console admin"; got != want {
+		t.Fatalf("calls = %q, want %q", got, want)
+	}
+	if !fake.closed {
+		t.Fatal("backend was not closed")
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "ada@example.com") {
+		t.Fatalf("unexpected table:\n%s", stdout)
+	}
+}
+
+func TestRunJSONOutput(t *testing.T) {
+	_, code, stdout, _ := runFake(t, "-o", "json", "users", "create", "-email", "bob@example.com", "-name", "Bob")
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	var got struct {
+		Email    string   `json:"email"`
+		Roles    []string `json:"roles"`
+		Password string   `json:"password"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if got.Email != "bob@example.com" || got.Roles == nil || len(got.Password) != 16 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+
+	_, _, stdout, _ = runFake(t, "-o", "json", "roles", "list")
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Fatalf("empty list = %q, want []", stdout)
+	}
+}
+
+func TestRunMutations(t *testing.T) {
+	fake, code, stdout, _ := runFake(t, "users", "deactivate", "ada@example.com")
+	if code != 0 || fake.calls[0] != "SetUserStatus ada@example.com inactive" {
+		t.Fatalf("code = %d, calls = %v", code, fake.calls)
+	}
+	if strings.TrimSpace(stdout) != "user ada@example.com deactivated" {
+		t.Fatalf("stdout = %q", stdout)
+	}
+
+	fake, code, _, _ = runFake(t, "roles", "assign", "-app", "console", "-role", "admin", "u1")
+	if code != 0 || fake.calls[0] != "AssignRole u1 console admin" {
+		t.Fatalf("code = %d, calls = %v", code, fake.calls)
+	}
+
+	_, code, stdout, stderr := runFake(t, "apps", "rotate-secret", "billing")
+	if code != 0 || !strings.Contains(stdout, "s3cret") || !strings.Contains(stderr, "cannot be shown again") {
+		t.Fatalf("code = %d, stdout = %q, stderr = %q", code, stdout, stderr)
+	}
+}
+
+func TestRunRBAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rbac.yaml")
+	manifest := "apps: [{code: billing, name: Billing, redirect_url: 'https://billing.example.com/cb'}]\n"
+	if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake, code, stdout, _ := runFake(t, "rbac", "plan", "-prune", path)
+	if code != 0 || fake.calls[0] != "PlanRBAC billing yes" {
+		t.Fatalf("code = %d, calls = %v", code, fake.calls)
+	}
+	if !strings.HasPrefix(stdout, "ACTION") || !strings.Contains(stdout, "editor") {
+		t.Fatalf("unexpected plan:\n%s", stdout)
+	}
+
+	_, code, stdout, stderr := runFake(t, "rbac", "apply", path)
+	if code != 0 || !strings.Contains(stdout, "s3cret") || !strings.Contains(stderr, "cannot be shown again") {
+		t.Fatalf("code = %d, stdout = %q, stderr = %q", code, stdout, stderr)
+	}
+
+	if err := os.WriteFile(path, []byte("apps: [{code: isme}]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, code, _, stderr = runFake(t, "rbac", "plan", path)
+	if code != 1 || !strings.Contains(stderr, "platform app") {
+		t.Fatalf("an invalid manifest: code = %d, stderr = %q", code, stderr)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"no command", nil, 2, "usage: ismectl"},
+		{"unknown command", []string{"users", "purge"}, 2, `unknown command "users purge"`},
+		{"unknown format", []string{"-o", "yaml", "users", "list"}, 2, "unknown output format"},
+		{"missing argument", []string{"sessions", "revoke", "u1"}, 2, "usage: ismectl sessions revoke USER SESSION_ID"},
+		{"missing flag", []string{"roles", "assign", "u1"}, 2, "-app and -role are required"},
+		{"backend error", []string{"jobs", "run", "session_revoke"}, 1, "ismectl jobs run: job failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code, _, stderr := runFake(t, tt.args...)
+			if code != tt.code {
+				t.Fatalf("exit code = %d, want %d", code, tt.code)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Fatalf("stderr = %q, want it to contain %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestRunOpenError(t *testing.T) {
+	var stderr bytes.Buffer
+	code := run(context.Background(), []string{"apps", "list"}, &bytes.Buffer{}, &stderr, func(string, string) (backend, error) {
+		return nil, errors.New("connection refused")
+	})
+	if code != 1 || !strings.Contains(stderr.String(), "connection refused") {
+		t.Fatalf("code = %d, stderr = %q", code, stderr.String())
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes command results as an aligned table for people or as JSON
+// for scripts. Lists are always JSON arrays, never null.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// table prints v, or header and rows in table mode.
+func (p printer) table(v any, header []string, rows [][]string) error {
+	if p.format == outputJSON {
+		return p.json(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// record prints one result as key/value lines.
+func (p printer) record(v any, fields [][2]string) error {
+	if p.format == outputJSON {
+		return p.json(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, field := range fields {
+		fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+	}
+	return tw.Flush()
+}
+
+// done reports a mutation that returns nothing.
+func (p printer) done(message string) error {
+	if p.format == outputJSON {
+		return p.json(map[string]any{"ok": true, "message": message})
+	}
+	_, err := fmt.Fprintln(p.w, message)
+	return err
+}
+
+func (p printer) json(v any) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
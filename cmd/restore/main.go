@@ -0,0 +1,300 @@
+// Command restore lists the snapshots the database_backup job writes to
+// db/backups/ and restores one over the live database. Run it with the server
+// stopped: it refuses to swap the file while any process holds the database.
+//
+// With DB_DRIVER=postgres the snapshots are logical dumps (app-<ts>.dump):
+// restore checks the dump is complete and at the database's migration
+// version, dumps the current data to db/backups/pre-restore-<ts>.dump, then
+// truncates and reloads every table in one transaction.
+//
+// Before the swap the chosen snapshot must pass PRAGMA integrity_check and
+// carry the core isme tables, and the current database is saved as
+// db/backups/pre-restore-<ts>.db so the restore can itself be undone. The
+// report ends with the schema delta between the restored file and this
+// build's migrations; run `make migrate-up DB=sqlite` when migrations are
+// pending.
+//
+// When off-host backups are configured (BACKUP_S3_*), list also shows the
+// copies in the bucket, and a source of the form s3:<key> is downloaded
+// before it is checked and restored.
+//
+// Encrypted snapshots (*.db.enc) are verified against their manifest and
+// decrypted with BACKUP_ENCRYPTION_KEY or one of
+// BACKUP_ENCRYPTION_RETIRED_KEYS before the check; with a key configured the
+// safety snapshot is encrypted too.
+//
+// The database path comes from the same .env / DB_SQLITE_PATH setting the
+// server uses.
+//
+// Usage:
+//
+//	go run cmd/restore/main.go list
+//	go run cmd/restore/main.go restore <backup name, path or s3:key>
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/backup"
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/constants"
+
+	"github.com/uptrace/bun"
+	kueryDb "github.com/vukyn/kuery/bun/db"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] == "restore" && len(os.Args) != 3) {
+		fmt.Println("Usage:")
+		fmt.Println("  go run cmd/restore/main.go list")
+		fmt.Println("  go run cmd/restore/main.go restore <backup name, path or s3:key>")
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	store, err := backup.NewStore(cfg)
+	if err != nil {
+		log.Fatalf("failed to configure the backup store: %v", err)
+	}
+	keys, err := backup.NewKeyring(cfg.Backup.EncryptionKey, cfg.Backup.EncryptionRetiredKeys...)
+	if err != nil {
+		log.Fatalf("invalid BACKUP_ENCRYPTION_KEY: %v", err)
+	}
+	r := restorer{
+		backupDir: filepath.Join(filepath.Dir(constants.DB_FILE_PATH), "backups"),
+		target:    cfg.DB.SQLitePath,
+		prefix:    cfg.Backup.S3Prefix,
+		keys:      keys,
+	}
+	if cfg.DB.Driver != "" && cfg.DB.Driver != string(kueryDb.DriverSQLite) {
+		db, err := kueryDb.Open(kueryDb.Config{
+			Driver:      kueryDb.Driver(cfg.DB.Driver),
+			PostgresDSN: cfg.DB.DSN,
+			Host:        cfg.DB.Host,
+			Port:        cfg.DB.Port,
+			User:        cfg.DB.User,
+			Password:    cfg.DB.Password,
+			DBName:      cfg.DB.DBName,
+			SSLMode:     cfg.DB.SSLMode,
+		})
+		if err != nil {
+			log.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+		r.db = db
+		r.target = "postgres database " + cfg.DB.DBName
+	}
+	// store stays a nil interface when off-host backups are not configured.
+	if store != nil {
+		r.store = store
+	}
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "list":
+		if err := r.list(ctx); err != nil {
+			log.Fatalf("list backups: %v", err)
+		}
+	case "restore":
+		if err := r.restore(ctx, os.Args[2]); err != nil {
+			log.Fatalf("restore failed: %v", err)
+		}
+	default:
+		fmt.Printf("Unknown command: %s\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+// remoteScheme prefixes a restore source that is an object key in the
+// off-host store.
+const remoteScheme = "s3:"
+
+type restorer struct {
+	backupDir string
+	target    string
+	store     backup.Store
+	prefix    string
+	keys      *backup.Keyring
+	// db is set when DB_DRIVER=postgres; target is then only a label.
+	db *bun.DB
+}
+
+func (r restorer) list(ctx context.Context) error {
+	backups, err := backup.List(ctx, r.backupDir)
+	if err != nil {
+		return err
+	}
+	if r.store != nil {
+		remote, err := backup.ListRemote(ctx, r.store, r.prefix)
+		if err != nil {
+			return fmt.Errorf("list off-host backups: %w", err)
+		}
+		backups = append(backups, remote...)
+	}
+	if len(backups) == 0 {
+		fmt.Printf("No backups in %s\n", r.backupDir)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tSIZE\tTAKEN AT\tMIGRATION")
+	for _, b := range backups {
+		source, version := b.Name, b.Version
+		switch {
+		case b.Remote:
+			source, version = remoteScheme+b.Path, "(download to inspect)"
+		case b.Err != "":
+			version = "unreadable: " + b.Err
+		case b.Encrypted:
+			version += " (encrypted, key " + b.KeyID + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", source, humanSize(b.Size), b.ModTime.UTC().Format(time.RFC3339), version)
+	}
+	return tw.Flush()
+}
+
+func (r restorer) restore(ctx context.Context, source string) error {
+	src, cleanup, err := r.resolve(ctx, source)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if backup.IsDump(src) != (r.db != nil) {
+		return fmt.Errorf("%s is a %s backup, but DB_DRIVER selects %s", source, formatOf(backup.IsDump(src)), formatOf(r.db != nil))
+	}
+
+	fmt.Printf("Restoring %s over %s\n", source, r.target)
+	var result backup.Result
+	if r.db != nil {
+		result, err = backup.RestorePostgres(ctx, r.db, src, r.backupDir, r.keys, time.Now())
+	} else {
+		result, err = backup.Restore(ctx, src, r.target, r.backupDir, r.keys, time.Now())
+	}
+	if errors.Is(err, backup.ErrIntegrity) {
+		fmt.Println("Integrity check output:")
+		for _, line := range result.Inspection.Integrity {
+			fmt.Printf("  %s\n", line)
+		}
+		return fmt.Errorf("%w; %s was not touched", err, r.target)
+	}
+	if errors.Is(err, backup.ErrNoKey) || errors.Is(err, backup.ErrUnknownKey) {
+		return fmt.Errorf("%w; set BACKUP_ENCRYPTION_KEY or BACKUP_ENCRYPTION_RETIRED_KEYS to the key that sealed it", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.Snapshot != "" {
+		fmt.Printf("Previous database saved as %s\n", result.Snapshot)
+	}
+	fmt.Printf("Restored migration version: %s\n", result.Inspection.Version())
+
+	delta := backup.SchemaDelta(incrementalApplied(result.Inspection.Applied), knownMigrations())
+	if delta.Current() {
+		fmt.Println("Schema is current with this build.")
+	}
+	if len(delta.Pending) > 0 {
+		fmt.Printf("%d migration(s) pending, run `make migrate-up DB=sqlite` before starting the server:\n", len(delta.Pending))
+		for _, name := range delta.Pending {
+			fmt.Printf("  + %s\n", name)
+		}
+	}
+	if len(delta.Unknown) > 0 {
+		fmt.Printf("%d migration(s) applied that this build does not know; the backup is from a newer version:\n", len(delta.Unknown))
+		for _, name := range delta.Unknown {
+			fmt.Printf("  ? %s\n", name)
+		}
+	}
+	fmt.Println("Restore complete; start the service.")
+	return nil
+}
+
+// resolve maps a restore source to a local file: a bare name is a file in the
+// backup directory and s3:<key> is downloaded next to it first. cleanup
+// removes the download once the restore has copied it.
+func (r restorer) resolve(ctx context.Context, source string) (path string, cleanup func(), err error) {
+	key, remote := strings.CutPrefix(source, remoteScheme)
+	if !remote {
+		if !strings.ContainsRune(source, filepath.Separator) {
+			source = filepath.Join(r.backupDir, source)
+		}
+		return source, func() {}, nil
+	}
+	if r.store == nil {
+		return "", nil, errors.New("off-host backups are not configured (BACKUP_S3_BUCKET)")
+	}
+
+	if err := os.MkdirAll(r.backupDir, 0o755); err != nil {
+		return "", nil, err
+	}
+	// The download is named download-*, which neither the job's pruning nor
+	// list picks up, so a crash mid-restore leaves no fake snapshot behind.
+	// An encrypted snapshot's manifest lands next to it.
+	path = filepath.Join(r.backupDir, "download-"+filepath.Base(key))
+	cleanup = func() {
+		os.Remove(path)
+		os.Remove(path + backup.ManifestSuffix)
+	}
+	cleanup()
+	fmt.Printf("Downloading %s\n", key)
+	if err := backup.Download(ctx, r.store, key, path); err != nil {
+		return "", nil, err
+	}
+	return path, cleanup, nil
+}
+
+func formatOf(postgres bool) string {
+	if postgres {
+		return "Postgres"
+	}
+	return "SQLite"
+}
+
+// incrementalApplied drops the baseline's bookkeeping row: a baselined
+// database has the incremental set stamped as applied, so the baseline itself
+// is neither pending nor unknown.
+func incrementalApplied(applied []string) []string {
+	names := make([]string, 0, len(applied))
+	for _, name := range applied {
+		if name != sqliteHistory.BaselineMigration.Name {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// knownMigrations lists the incremental migration names this build applies.
+func knownMigrations() []string {
+	names := make([]string, 0, len(sqliteHistory.Migrations))
+	for _, migration := range sqliteHistory.Migrations {
+		names = append(names, migration.Name)
+	}
+	return names
+}
+
+func humanSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
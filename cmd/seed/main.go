@@ -232,7 +232,9 @@ func main() {
 		if err := ensureRole(ctx, db, a.adminRoleID, a.id, "admin", "Admin", "Full access to every resource", true); err != nil {
 			log.Fatalf("role %s: %v", a.adminRoleID, err)
 		}
-		granted, err := seedPermsAndGrant(ctx, db, a.id, a.adminRoleID, a.perms)
+		// every app catalog also carries the reserved access_request:approve,
+		// held by its admin role (migration 039 / ProvisionDefaultRoles)
+		granted, err := seedPermsAndGrant(ctx, db, a.id, a.adminRoleID, append(a.perms, permission{"access_request", "approve"}))
 		if err != nil {
 			log.Fatalf("perms %s: %v", a.code, err)
 		}
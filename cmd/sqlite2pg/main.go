@@ -0,0 +1,221 @@
+// Command sqlite2pg moves an isme deployment from SQLite to Postgres. It
+// migrates the Postgres database named by the DB_* settings to the version of
+// the SQLite file (a fresh database gets the baseline first), copies every
+// table in foreign-key order with the values converted to the Postgres column
+// types, moves the identity sequences past the copied keys, and finally
+// compares row counts and checksums table by table.
+//
+// Run it with the server stopped: it holds the SQLite file's lock and the
+// Postgres advisory lock for the whole copy. The SQLite file must be at this
+// build's migration version (`make migrate-up DB=sqlite`) and is only read.
+// The copy runs in one transaction, so a failure leaves Postgres as it was
+// after migrating. A Postgres database that already holds users is refused
+// unless -replace is given, which overwrites it.
+//
+// Usage:
+//
+//	go run cmd/sqlite2pg/main.go [-source db/app.db] [-replace]
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/backup"
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/dbcopy"
+	"github.com/vukyn/isme/internal/migration"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	kueryDb "github.com/vukyn/kuery/bun/db"
+	"github.com/vukyn/kuery/bun/migrate"
+)
+
+// postgresDriver is the kuery driver name for Postgres, whatever DB_DRIVER
+// says: the target is always Postgres.
+const postgresDriver = "postgres"
+
+func main() {
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	source := flag.String("source", cfg.DB.SQLitePath, "SQLite database to copy from")
+	replace := flag.Bool("replace", false, "overwrite a Postgres database that already holds users")
+	flag.Parse()
+
+	if err := run(context.Background(), cfg, *source, *replace); err != nil {
+		log.Fatalf("sqlite2pg: %v", err)
+	}
+}
+
+func run(ctx context.Context, cfg *config.Config, source string, replace bool) error {
+	lock, err := backup.TryExclusive(source)
+	if errors.Is(err, backup.ErrInUse) {
+		return fmt.Errorf("%s is in use; stop the server first", source)
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	inspection, err := backup.Inspect(ctx, source)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", source, err)
+	}
+	if !inspection.OK() {
+		return fmt.Errorf("%s failed its integrity check: %v", source, inspection.Integrity)
+	}
+	sourceApplied := incrementalApplied(inspection.Applied)
+	if delta := backup.SchemaDelta(sourceApplied, knownMigrations()); !delta.Current() {
+		return fmt.Errorf("%s is not at this build's migration version (%d pending, %d unknown); run `make migrate-up DB=sqlite` first",
+			source, len(delta.Pending), len(delta.Unknown))
+	}
+
+	src, err := sql.Open(sqliteshim.ShimName, "file:"+source+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := kueryDb.Open(kueryDb.Config{
+		Driver:      kueryDb.Driver(postgresDriver),
+		PostgresDSN: cfg.DB.DSN,
+		Host:        cfg.DB.Host,
+		Port:        cfg.DB.Port,
+		User:        cfg.DB.User,
+		Password:    cfg.DB.Password,
+		DBName:      cfg.DB.DBName,
+		SSLMode:     cfg.DB.SSLMode,
+	})
+	if err != nil {
+		return fmt.Errorf("open postgres: %w", err)
+	}
+	defer dst.Close()
+
+	pgLock, err := backup.TryExclusiveDB(ctx, dst)
+	if errors.Is(err, backup.ErrInUse) {
+		return errors.New("postgres database is in use; stop the server first")
+	}
+	if err != nil {
+		return err
+	}
+	defer pgLock.Close()
+
+	if err := migrateTarget(ctx, dst); err != nil {
+		return err
+	}
+	targetApplied, err := migration.Applied(ctx, dst)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(sourceApplied, incrementalApplied(targetApplied)) {
+		return errors.New("postgres and SQLite applied different migrations; they cannot be copied row for row")
+	}
+
+	fmt.Printf("Copying %s into postgres database %s\n", source, cfg.DB.DBName)
+	plan, err := dbcopy.Copy(ctx, src, dst, replace)
+	if errors.Is(err, dbcopy.ErrNotEmpty) {
+		return fmt.Errorf("%w; pass -replace to overwrite it", err)
+	}
+	if err != nil {
+		return err
+	}
+	for _, table := range plan.Skipped {
+		fmt.Printf("Skipped %s: Postgres has no such table\n", table)
+	}
+
+	reports, err := dbcopy.Verify(ctx, src, dst, plan.Tables)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tSQLITE ROWS\tPOSTGRES ROWS\tCHECKSUM")
+	mismatched := 0
+	for _, r := range reports {
+		status := "ok"
+		if !r.OK() {
+			status = "MISMATCH " + r.SourceChecksum + " != " + r.TargetChecksum
+			mismatched++
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", r.Table, r.SourceRows, r.TargetRows, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if mismatched > 0 {
+		return fmt.Errorf("%d table(s) differ after the copy", mismatched)
+	}
+	fmt.Println("Copy verified; set DB_DRIVER=postgres and start the service.")
+	return nil
+}
+
+// migrateTarget brings Postgres to this build's version: a database without
+// a migrations table gets the baseline, then the incremental set runs, which
+// is a no-op right after the baseline. The checksums of what it applied are
+// recorded as `make migrate-up` would.
+func migrateTarget(ctx context.Context, db *bun.DB) error {
+	before, err := migration.Applied(ctx, db)
+	if err != nil {
+		return err
+	}
+	var exists bool
+	if err := db.NewRaw(`SELECT to_regclass('migrations') IS NOT NULL`).Scan(ctx, &exists); err != nil {
+		return err
+	}
+	if !exists {
+		stats, err := migrate.Run(db, []migrate.Migration{sqliteHistory.BaselineMigration})
+		if err != nil {
+			return fmt.Errorf("baseline postgres: %w", err)
+		}
+		fmt.Printf("Applied the baseline (%d migration(s))\n", stats.TotalSuccess)
+	}
+	stats, err := migrate.Run(db, sqliteHistory.Migrations)
+	if err != nil {
+		return fmt.Errorf("migrate postgres: %w", err)
+	}
+	fmt.Printf("Postgres migrated: %d applied, %d already current\n", stats.TotalSuccess, stats.TotalSkipped)
+
+	after, err := migration.Applied(ctx, db)
+	if err != nil {
+		return err
+	}
+	added := []string{}
+	for _, name := range after {
+		if !slices.Contains(before, name) {
+			added = append(added, name)
+		}
+	}
+	return migration.Record(ctx, db, added, sqliteHistory.SourceChecksum)
+}
+
+// incrementalApplied drops the baseline's bookkeeping row, which only a
+// baselined database carries.
+func incrementalApplied(applied []string) []string {
+	names := make([]string, 0, len(applied))
+	for _, name := range applied {
+		if name != sqliteHistory.BaselineMigration.Name {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// knownMigrations lists the incremental migration names this build applies.
+func knownMigrations() []string {
+	names := make([]string, 0, len(sqliteHistory.Migrations))
+	for _, m := range sqliteHistory.Migrations {
+		names = append(names, m.Name)
+	}
+	return names
+}
@@ -0,0 +1,55 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Extend activity_events into the admin audit trail. user_id stays the ACTOR
+// (the account that performed the action, so the Welcome feed keeps working);
+// the new columns record WHAT was acted on and FROM WHERE:
+//
+//   - target_type / target_id — the mutated resource (e.g. "role" / rol_...)
+//   - client_ip / user_agent  — the request origin of the actor
+//
+// The before/after diff rides in the existing meta JSON blob, so no JSON column
+// is needed. Every column is portable TEXT with an empty-string default, so —
+// like m031 — both dialects take the identical DDL and existing self-service
+// rows (sign_in, password_changed, ...) simply carry empty audit fields.
+//
+// idx_activity_events_target_created backs the "history of this resource"
+// lookup an audit reviewer starts from.
+var m032AddAuditColumnsToActivityEvents = pkgMigrate.Migration{
+	Name: "032_add_audit_columns_to_activity_events",
+	Up: func(db bun.IDB) error {
+		for _, stmt := range []string{
+			`ALTER TABLE activity_events ADD COLUMN target_type TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE activity_events ADD COLUMN target_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE activity_events ADD COLUMN client_ip TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE activity_events ADD COLUMN user_agent TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_activity_events_target_created ON activity_events (target_type, target_id, created_at)`,
+		} {
+			if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db bun.IDB) error {
+		for _, stmt := range []string{
+			`DROP INDEX IF EXISTS idx_activity_events_target_created`,
+			`ALTER TABLE activity_events DROP COLUMN user_agent`,
+			`ALTER TABLE activity_events DROP COLUMN client_ip`,
+			`ALTER TABLE activity_events DROP COLUMN target_id`,
+			`ALTER TABLE activity_events DROP COLUMN target_type`,
+		} {
+			if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,46 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Seeds the audit:read permission gating the admin audit search/export
+// endpoints and grants it to the admin system role. Unlike 022 this runs after
+// the baseline cut-over, so it may execute against Postgres and branches on
+// dialect for the conflict-ignoring inserts.
+var m033SeedAuditPermission = pkgMigrate.Migration{
+	Name: "033_seed_audit_permission",
+	Up: func(db bun.IDB) error {
+		ctx := context.Background()
+		permSQL := `INSERT OR IGNORE INTO permissions (app_id, resource, action) VALUES ('app_isme', 'audit', 'read')`
+		grantSQL := `INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+			SELECT 'rol_admin', id FROM permissions WHERE app_id = 'app_isme' AND resource = 'audit' AND action = 'read'`
+		if isPostgres(db) {
+			permSQL = `INSERT INTO permissions (app_id, resource, action) VALUES ('app_isme', 'audit', 'read')
+				ON CONFLICT (app_id, resource, action) DO NOTHING`
+			grantSQL = `INSERT INTO role_permissions (role_id, permission_id)
+				SELECT 'rol_admin', id FROM permissions WHERE app_id = 'app_isme' AND resource = 'audit' AND action = 'read'
+				ON CONFLICT (role_id, permission_id) DO NOTHING`
+		}
+		if _, err := db.ExecContext(ctx, permSQL); err != nil {
+			return err
+		}
+		_, err := db.ExecContext(ctx, grantSQL)
+		return err
+	},
+	Down: func(db bun.IDB) error {
+		_, err := db.ExecContext(context.Background(), `
+			DELETE FROM role_permissions
+			WHERE permission_id IN (SELECT id FROM permissions WHERE app_id = 'app_isme' AND resource = 'audit' AND action = 'read')
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = db.ExecContext(context.Background(), `DELETE FROM permissions WHERE app_id = 'app_isme' AND resource = 'audit' AND action = 'read'`)
+		return err
+	},
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Make the audit trail tamper-evident. Every new activity_events row is
+// appended to a hash chain:
+//
+//   - seq       — gapless 1-based position in the chain (NULL on rows written
+//     before this migration, which stay unchained)
+//   - prev_hash — the hash of row seq-1 (all zeroes for the first row)
+//   - hash      — SHA-256 over the row's fields plus prev_hash
+//
+// activity_chain_head is a single-row table holding the current head (seq and
+// hash). An append first bumps it with an UPDATE, which takes the row lock on
+// Postgres and the write lock on SQLite, so concurrent appends queue behind
+// each other until commit instead of reading the same head. The unique index on
+// seq is the backstop: a racing append fails rather than forking the chain
+// (NULLs are distinct, so legacy rows do not collide).
+//
+// activity_checkpoints records each range the activity-cleanup job prunes: the
+// seq range, the hash of its last row (the prev_hash the first surviving row
+// links to) and an HMAC signature, so retention does not break verification.
+// chainGenesisHash is the prev_hash of the first chained row (chain.Genesis).
+const chainGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+var m034AddHashChainToActivityEvents = pkgMigrate.Migration{
+	Name: "034_add_hash_chain_to_activity_events",
+	Up: func(db bun.IDB) error {
+		seqType, timeType := "INTEGER", "DATETIME"
+		if isPostgres(db) {
+			seqType, timeType = "BIGINT", "TIMESTAMPTZ"
+		}
+		for _, stmt := range []string{
+			`ALTER TABLE activity_events ADD COLUMN seq ` + seqType,
+			`ALTER TABLE activity_events ADD COLUMN prev_hash TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE activity_events ADD COLUMN hash TEXT NOT NULL DEFAULT ''`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_events_seq ON activity_events (seq)`,
+			`CREATE TABLE IF NOT EXISTS activity_chain_head (
+				id INTEGER PRIMARY KEY,
+				seq ` + seqType + ` NOT NULL DEFAULT 0,
+				hash TEXT NOT NULL
+			)`,
+			`INSERT INTO activity_chain_head (id, seq, hash) VALUES (1, 0, '` + chainGenesisHash + `')`,
+			`CREATE TABLE IF NOT EXISTS activity_checkpoints (
+				id TEXT PRIMARY KEY NOT NULL,
+				from_seq ` + seqType + ` NOT NULL,
+				to_seq ` + seqType + ` NOT NULL,
+				row_count ` + seqType + ` NOT NULL,
+				last_hash TEXT NOT NULL,
+				pruned_before ` + timeType + ` NOT NULL,
+				signature TEXT NOT NULL,
+				created_at ` + timeType + ` NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_checkpoints_to_seq ON activity_checkpoints (to_seq)`,
+		} {
+			if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db bun.IDB) error {
+		for _, stmt := range []string{
+			`DROP TABLE IF EXISTS activity_checkpoints`,
+			`DROP TABLE IF EXISTS activity_chain_head`,
+			`DROP INDEX IF EXISTS idx_activity_events_seq`,
+			`ALTER TABLE activity_events DROP COLUMN hash`,
+			`ALTER TABLE activity_events DROP COLUMN prev_hash`,
+			`ALTER TABLE activity_events DROP COLUMN seq`,
+		} {
+			if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
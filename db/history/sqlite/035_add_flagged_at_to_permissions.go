@@ -0,0 +1,30 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Let an app service sync its own permission catalog without losing rows it
+// stops listing. flagged_at is set when a sync no longer includes the
+// permission and cleared when a later sync lists it again; the row, and the
+// role grants on it, stay until an admin confirms the removal by deleting it.
+// NULL = not flagged, so existing rows need no backfill.
+var m035AddFlaggedAtToPermissions = pkgMigrate.Migration{
+	Name: "035_add_flagged_at_to_permissions",
+	Up: func(db bun.IDB) error {
+		timeType := "DATETIME"
+		if isPostgres(db) {
+			timeType = "TIMESTAMPTZ"
+		}
+		_, err := db.ExecContext(context.Background(), `ALTER TABLE permissions ADD COLUMN flagged_at `+timeType)
+		return err
+	},
+	Down: func(db bun.IDB) error {
+		_, err := db.ExecContext(context.Background(), `ALTER TABLE permissions DROP COLUMN flagged_at`)
+		return err
+	},
+}
@@ -0,0 +1,44 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Composite roles: a row grants role_id every permission of included_role_id
+// (and, transitively, of the roles that one includes). Both roles belong to the
+// same app and the graph stays acyclic; the role usecase enforces both on
+// write, so there are no FKs or CHECKs here, matching role_permissions. The
+// reverse index serves the cycle check and "who includes this role" lookups.
+var m036CreateRoleIncludesTable = pkgMigrate.Migration{
+	Name: "036_create_role_includes_table",
+	Up: func(db bun.IDB) error {
+		timeType := "DATETIME"
+		if isPostgres(db) {
+			timeType = "TIMESTAMPTZ"
+		}
+		if _, err := db.ExecContext(context.Background(), `
+			CREATE TABLE IF NOT EXISTS role_includes (
+				role_id TEXT NOT NULL,
+				included_role_id TEXT NOT NULL,
+				created_at `+timeType+` DEFAULT CURRENT_TIMESTAMP,
+				created_by TEXT DEFAULT '',
+				PRIMARY KEY (role_id, included_role_id)
+			)
+		`); err != nil {
+			return err
+		}
+		_, err := db.ExecContext(context.Background(), `CREATE INDEX IF NOT EXISTS role_includes_included_role_id_idx ON role_includes (included_role_id)`)
+		return err
+	},
+	Down: func(db bun.IDB) error {
+		if _, err := db.ExecContext(context.Background(), `DROP INDEX IF EXISTS role_includes_included_role_id_idx`); err != nil {
+			return err
+		}
+		_, err := db.ExecContext(context.Background(), `DROP TABLE IF EXISTS role_includes`)
+		return err
+	},
+}
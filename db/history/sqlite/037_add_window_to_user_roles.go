@@ -0,0 +1,46 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Time-bound role assignments. starts_at/expires_at bound when a user_roles
+// row counts toward the user's roles and permissions; NULL is an open bound
+// (active from creation / never expires), so existing rows stay permanent and
+// need no backfill. The expires_at index serves the role-member-expiry job's
+// sweep.
+var m037AddWindowToUserRoles = pkgMigrate.Migration{
+	Name: "037_add_window_to_user_roles",
+	Up: func(db bun.IDB) error {
+		timeType := "DATETIME"
+		if isPostgres(db) {
+			timeType = "TIMESTAMPTZ"
+		}
+		for _, statement := range []string{
+			`ALTER TABLE user_roles ADD COLUMN starts_at ` + timeType,
+			`ALTER TABLE user_roles ADD COLUMN expires_at ` + timeType,
+			`CREATE INDEX IF NOT EXISTS user_roles_expires_at_idx ON user_roles (expires_at)`,
+		} {
+			if _, err := db.ExecContext(context.Background(), statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db bun.IDB) error {
+		for _, statement := range []string{
+			`DROP INDEX IF EXISTS user_roles_expires_at_idx`,
+			`ALTER TABLE user_roles DROP COLUMN expires_at`,
+			`ALTER TABLE user_roles DROP COLUMN starts_at`,
+		} {
+			if _, err := db.ExecContext(context.Background(), statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,34 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Seed the fifth scheduled job (role_member_expiry) into schedule_config. It
+// removes expired time-bound role assignments and revokes the sessions still
+// carrying their permissions. Unlike the retention jobs it is ENABLED by
+// default: an expiry nobody enforces would leave those sessions live. A short
+// cron keeps the gap between expires_at and revocation small. Runs after the
+// baseline cut-over, so the conflict-ignoring insert branches on dialect.
+var m038SeedRoleMemberExpirySchedule = pkgMigrate.Migration{
+	Name: "038_seed_role_member_expiry_schedule",
+	Up: func(db bun.IDB) error {
+		seedSQL := `INSERT OR IGNORE INTO schedule_config (job_key, enabled, cron, params)
+			VALUES ('role_member_expiry', 1, '*/5 * * * *', '{}')`
+		if isPostgres(db) {
+			seedSQL = `INSERT INTO schedule_config (job_key, enabled, cron, params)
+				VALUES ('role_member_expiry', TRUE, '*/5 * * * *', '{}')
+				ON CONFLICT (job_key) DO NOTHING`
+		}
+		_, err := db.ExecContext(context.Background(), seedSQL)
+		return err
+	},
+	Down: func(db bun.IDB) error {
+		_, err := db.ExecContext(context.Background(), `DELETE FROM schedule_config WHERE job_key = 'role_member_expiry'`)
+		return err
+	},
+}
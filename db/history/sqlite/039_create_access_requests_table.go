@@ -0,0 +1,92 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// Just-in-time access requests: a user asks for a role in an app for
+// duration_hours, and an approver of that app approves or denies it. Approval
+// stamps decided_* and the expires_at of the membership it created. The partial
+// unique index allows one pending request per user and role.
+//
+// Approvers are the users holding access_request:approve in the app, so the
+// pair is seeded into every live app's catalog (isme included) and granted to
+// each app's admin role. Apps registered later get it from
+// ProvisionDefaultRoles.
+var m039CreateAccessRequestsTable = pkgMigrate.Migration{
+	Name: "039_create_access_requests_table",
+	Up: func(db bun.IDB) error {
+		ctx := context.Background()
+		timeType := "DATETIME"
+		if isPostgres(db) {
+			timeType = "TIMESTAMPTZ"
+		}
+		statements := []string{
+			`CREATE TABLE IF NOT EXISTS access_requests (
+				id TEXT PRIMARY KEY NOT NULL,
+				user_id TEXT NOT NULL,
+				app_service_id TEXT NOT NULL,
+				role_id TEXT NOT NULL,
+				justification TEXT NOT NULL,
+				duration_hours INTEGER NOT NULL,
+				status INTEGER NOT NULL DEFAULT 1,
+				decided_by TEXT,
+				decided_at ` + timeType + `,
+				decision_note TEXT,
+				expires_at ` + timeType + `,
+				created_at ` + timeType + ` DEFAULT CURRENT_TIMESTAMP,
+				updated_at ` + timeType + ` DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS access_requests_user_id_idx ON access_requests (user_id)`,
+			`CREATE INDEX IF NOT EXISTS access_requests_app_status_idx ON access_requests (app_service_id, status)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS access_requests_pending_uidx ON access_requests (user_id, role_id) WHERE status = 1`,
+		}
+
+		permSQL := `INSERT OR IGNORE INTO permissions (app_id, resource, action)
+			SELECT id, 'access_request', 'approve' FROM app_services WHERE deleted_at IS NULL`
+		grantSQL := `INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+			SELECT rol.id, per.id FROM roles AS rol
+			JOIN permissions AS per ON per.app_id = rol.app_id AND per.resource = 'access_request' AND per.action = 'approve'
+			WHERE rol.code = 'admin' AND rol.deleted_at IS NULL`
+		if isPostgres(db) {
+			permSQL = `INSERT INTO permissions (app_id, resource, action)
+				SELECT id, 'access_request', 'approve' FROM app_services WHERE deleted_at IS NULL
+				ON CONFLICT (app_id, resource, action) DO NOTHING`
+			grantSQL = `INSERT INTO role_permissions (role_id, permission_id)
+				SELECT rol.id, per.id FROM roles AS rol
+				JOIN permissions AS per ON per.app_id = rol.app_id AND per.resource = 'access_request' AND per.action = 'approve'
+				WHERE rol.code = 'admin' AND rol.deleted_at IS NULL
+				ON CONFLICT (role_id, permission_id) DO NOTHING`
+		}
+		statements = append(statements, permSQL, grantSQL)
+
+		for _, stmt := range statements {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db bun.IDB) error {
+		ctx := context.Background()
+		statements := []string{
+			`DELETE FROM role_permissions
+			WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'access_request' AND action = 'approve')`,
+			`DELETE FROM permissions WHERE resource = 'access_request' AND action = 'approve'`,
+			`DROP INDEX IF EXISTS access_requests_pending_uidx`,
+			`DROP INDEX IF EXISTS access_requests_app_status_idx`,
+			`DROP INDEX IF EXISTS access_requests_user_id_idx`,
+			`DROP TABLE IF EXISTS access_requests`,
+		}
+		for _, stmt := range statements {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
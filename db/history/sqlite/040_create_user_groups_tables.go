@@ -0,0 +1,78 @@
+package history
+
+import (
+	"context"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+)
+
+// User groups: a named set of users (user_group_members) holding a set of roles
+// (user_group_roles). Members inherit every role of the groups they belong to,
+// in the role's own app, for as long as they stay in the group. Group names are
+// unique among live groups. Like role_includes there are no FKs; the group
+// usecase validates users and roles on write, and reads skip deleted rows.
+var m040CreateUserGroupsTables = pkgMigrate.Migration{
+	Name: "040_create_user_groups_tables",
+	Up: func(db bun.IDB) error {
+		ctx := context.Background()
+		timeType := "DATETIME"
+		if isPostgres(db) {
+			timeType = "TIMESTAMPTZ"
+		}
+		statements := []string{
+			`CREATE TABLE IF NOT EXISTS user_groups (
+				id TEXT PRIMARY KEY NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				created_at ` + timeType + ` DEFAULT CURRENT_TIMESTAMP,
+				created_by TEXT DEFAULT '',
+				updated_at ` + timeType + ` DEFAULT CURRENT_TIMESTAMP,
+				updated_by TEXT DEFAULT '',
+				deleted_at ` + timeType + `,
+				deleted_by TEXT DEFAULT ''
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS user_groups_name_uidx ON user_groups (name) WHERE deleted_at IS NULL`,
+			`CREATE TABLE IF NOT EXISTS user_group_members (
+				group_id TEXT NOT NULL,
+				user_id TEXT NOT NULL,
+				created_at ` + timeType + ` DEFAULT CURRENT_TIMESTAMP,
+				created_by TEXT DEFAULT '',
+				PRIMARY KEY (group_id, user_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS user_group_members_user_id_idx ON user_group_members (user_id)`,
+			`CREATE TABLE IF NOT EXISTS user_group_roles (
+				group_id TEXT NOT NULL,
+				role_id TEXT NOT NULL,
+				created_at ` + timeType + ` DEFAULT CURRENT_TIMESTAMP,
+				created_by TEXT DEFAULT '',
+				PRIMARY KEY (group_id, role_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS user_group_roles_role_id_idx ON user_group_roles (role_id)`,
+		}
+		for _, stmt := range statements {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(db bun.IDB) error {
+		ctx := context.Background()
+		statements := []string{
+			`DROP INDEX IF EXISTS user_group_roles_role_id_idx`,
+			`DROP TABLE IF EXISTS user_group_roles`,
+			`DROP INDEX IF EXISTS user_group_members_user_id_idx`,
+			`DROP TABLE IF EXISTS user_group_members`,
+			`DROP INDEX IF EXISTS user_groups_name_uidx`,
+			`DROP TABLE IF EXISTS user_groups`,
+		}
+		for _, stmt := range statements {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -9,10 +9,11 @@ import (
 )
 
 // BaselineMigration is a squashed, dual-dialect (SQLite + Postgres) snapshot of
-// the entire final schema (all 12 application tables + their indexes) plus the
+// the entire final schema (all 19 application tables + their indexes) plus the
 // migration-embedded seed data (RBAC roles/permissions/grants, the isme
-// self-app_service row, and the four schedule_config job rows), used as the
-// fresh-install path for a brand-new database on either dialect.
+// self-app_service row, the five schedule_config job rows and the audit chain
+// head row), used as the fresh-install path for a brand-new database on either
+// dialect.
 //
 // It is intentionally NOT registered in the Migrations slice in migrations.go —
 // the incremental 001-029 set is left byte-identical so existing dev/prod SQLite
@@ -104,16 +105,27 @@ func baselineSQLiteStatements() []string {
 			permission_id INTEGER NOT NULL,
 			PRIMARY KEY (role_id, permission_id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS role_includes (
+			role_id TEXT NOT NULL,
+			included_role_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			PRIMARY KEY (role_id, included_role_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS role_includes_included_role_id_idx ON role_includes (included_role_id)`,
 		`CREATE TABLE IF NOT EXISTS user_roles (
 			id TEXT PRIMARY KEY NOT NULL,
 			user_id TEXT NOT NULL,
 			role_id TEXT NOT NULL,
 			app_service_id TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			created_by TEXT DEFAULT ''
+			created_by TEXT DEFAULT '',
+			starts_at DATETIME,
+			expires_at DATETIME
 		)`,
 		`CREATE INDEX IF NOT EXISTS user_roles_user_id_idx ON user_roles (user_id)`,
 		`CREATE INDEX IF NOT EXISTS user_roles_role_id_idx ON user_roles (role_id)`,
+		`CREATE INDEX IF NOT EXISTS user_roles_expires_at_idx ON user_roles (expires_at)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS user_roles_user_role_app_uidx ON user_roles (user_id, role_id, IFNULL(app_service_id, ''))`,
 		`CREATE TABLE IF NOT EXISTS permissions (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -122,6 +134,7 @@ func baselineSQLiteStatements() []string {
 			action TEXT NOT NULL,
 			icon TEXT NOT NULL DEFAULT '',
 			color TEXT NOT NULL DEFAULT '',
+			flagged_at DATETIME,
 			UNIQUE (app_id, resource, action)
 		)`,
 		`CREATE TABLE IF NOT EXISTS roles (
@@ -177,6 +190,52 @@ func baselineSQLiteStatements() []string {
 		`CREATE INDEX IF NOT EXISTS user_invitations_token_hash_idx ON user_invitations (token_hash)`,
 		`CREATE INDEX IF NOT EXISTS user_invitations_email_idx ON user_invitations (email)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS user_invitations_pending_email_uidx ON user_invitations (email) WHERE status = 1 AND deleted_at IS NULL`,
+		`CREATE TABLE IF NOT EXISTS access_requests (
+			id TEXT PRIMARY KEY NOT NULL,
+			user_id TEXT NOT NULL,
+			app_service_id TEXT NOT NULL,
+			role_id TEXT NOT NULL,
+			justification TEXT NOT NULL,
+			duration_hours INTEGER NOT NULL,
+			status INTEGER NOT NULL DEFAULT 1,
+			decided_by TEXT,
+			decided_at DATETIME,
+			decision_note TEXT,
+			expires_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS access_requests_user_id_idx ON access_requests (user_id)`,
+		`CREATE INDEX IF NOT EXISTS access_requests_app_status_idx ON access_requests (app_service_id, status)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS access_requests_pending_uidx ON access_requests (user_id, role_id) WHERE status = 1`,
+		`CREATE TABLE IF NOT EXISTS user_groups (
+			id TEXT PRIMARY KEY NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT DEFAULT '',
+			deleted_at DATETIME,
+			deleted_by TEXT DEFAULT ''
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS user_groups_name_uidx ON user_groups (name) WHERE deleted_at IS NULL`,
+		`CREATE TABLE IF NOT EXISTS user_group_members (
+			group_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			PRIMARY KEY (group_id, user_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS user_group_members_user_id_idx ON user_group_members (user_id)`,
+		`CREATE TABLE IF NOT EXISTS user_group_roles (
+			group_id TEXT NOT NULL,
+			role_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			PRIMARY KEY (group_id, role_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS user_group_roles_role_id_idx ON user_group_roles (role_id)`,
 		`CREATE TABLE IF NOT EXISTS token_rotation_events (
 			id TEXT PRIMARY KEY,
 			user_id TEXT NOT NULL,
@@ -200,9 +259,34 @@ func baselineSQLiteStatements() []string {
 			user_id TEXT NOT NULL,
 			type TEXT NOT NULL,
 			meta TEXT NOT NULL DEFAULT '{}',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			target_type TEXT NOT NULL DEFAULT '',
+			target_id TEXT NOT NULL DEFAULT '',
+			client_ip TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			seq INTEGER,
+			prev_hash TEXT NOT NULL DEFAULT '',
+			hash TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE IF NOT EXISTS activity_chain_head (
+			id INTEGER PRIMARY KEY,
+			seq INTEGER NOT NULL DEFAULT 0,
+			hash TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS activity_checkpoints (
+			id TEXT PRIMARY KEY NOT NULL,
+			from_seq INTEGER NOT NULL,
+			to_seq INTEGER NOT NULL,
+			row_count INTEGER NOT NULL,
+			last_hash TEXT NOT NULL,
+			pruned_before DATETIME NOT NULL,
+			signature TEXT NOT NULL,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_activity_events_user_created ON activity_events (user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_activity_events_target_created ON activity_events (target_type, target_id, created_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_events_seq ON activity_events (seq)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_checkpoints_to_seq ON activity_checkpoints (to_seq)`,
 	}
 }
 
@@ -279,13 +363,22 @@ func baselinePostgresStatements() []string {
 			permission_id BIGINT NOT NULL,
 			PRIMARY KEY (role_id, permission_id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS role_includes (
+			role_id TEXT NOT NULL,
+			included_role_id TEXT NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			PRIMARY KEY (role_id, included_role_id)
+		)`,
 		`CREATE TABLE IF NOT EXISTS user_roles (
 			id TEXT PRIMARY KEY NOT NULL,
 			user_id TEXT NOT NULL,
 			role_id TEXT NOT NULL,
 			app_service_id TEXT,
 			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
-			created_by TEXT DEFAULT ''
+			created_by TEXT DEFAULT '',
+			starts_at TIMESTAMPTZ,
+			expires_at TIMESTAMPTZ
 		)`,
 		`CREATE TABLE IF NOT EXISTS permissions (
 			id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
@@ -294,6 +387,7 @@ func baselinePostgresStatements() []string {
 			action TEXT NOT NULL,
 			icon TEXT NOT NULL DEFAULT '',
 			color TEXT NOT NULL DEFAULT '',
+			flagged_at TIMESTAMPTZ,
 			UNIQUE (app_id, resource, action)
 		)`,
 		`CREATE TABLE IF NOT EXISTS roles (
@@ -342,6 +436,46 @@ func baselinePostgresStatements() []string {
 			deleted_by TEXT DEFAULT '',
 			FOREIGN KEY (invitation_id) REFERENCES user_invitations (id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS access_requests (
+			id TEXT PRIMARY KEY NOT NULL,
+			user_id TEXT NOT NULL,
+			app_service_id TEXT NOT NULL,
+			role_id TEXT NOT NULL,
+			justification TEXT NOT NULL,
+			duration_hours INTEGER NOT NULL,
+			status INTEGER NOT NULL DEFAULT 1,
+			decided_by TEXT,
+			decided_at TIMESTAMPTZ,
+			decision_note TEXT,
+			expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_groups (
+			id TEXT PRIMARY KEY NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			updated_by TEXT DEFAULT '',
+			deleted_at TIMESTAMPTZ,
+			deleted_by TEXT DEFAULT ''
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_group_members (
+			group_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			PRIMARY KEY (group_id, user_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_group_roles (
+			group_id TEXT NOT NULL,
+			role_id TEXT NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			created_by TEXT DEFAULT '',
+			PRIMARY KEY (group_id, role_id)
+		)`,
 		`CREATE TABLE IF NOT EXISTS token_rotation_events (
 			id TEXT PRIMARY KEY,
 			user_id TEXT NOT NULL,
@@ -364,6 +498,28 @@ func baselinePostgresStatements() []string {
 			user_id TEXT NOT NULL,
 			type TEXT NOT NULL,
 			meta TEXT NOT NULL DEFAULT '{}',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			target_type TEXT NOT NULL DEFAULT '',
+			target_id TEXT NOT NULL DEFAULT '',
+			client_ip TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			seq BIGINT,
+			prev_hash TEXT NOT NULL DEFAULT '',
+			hash TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE IF NOT EXISTS activity_chain_head (
+			id INTEGER PRIMARY KEY,
+			seq BIGINT NOT NULL DEFAULT 0,
+			hash TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS activity_checkpoints (
+			id TEXT PRIMARY KEY NOT NULL,
+			from_seq BIGINT NOT NULL,
+			to_seq BIGINT NOT NULL,
+			row_count BIGINT NOT NULL,
+			last_hash TEXT NOT NULL,
+			pruned_before TIMESTAMPTZ NOT NULL,
+			signature TEXT NOT NULL,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)`,
 		// --- Phase 2: indexes (every referenced table now exists) ---
@@ -372,6 +528,8 @@ func baselinePostgresStatements() []string {
 		`CREATE INDEX IF NOT EXISTS app_services_app_code_idx ON app_services (app_code)`,
 		`CREATE INDEX IF NOT EXISTS user_roles_user_id_idx ON user_roles (user_id)`,
 		`CREATE INDEX IF NOT EXISTS user_roles_role_id_idx ON user_roles (role_id)`,
+		`CREATE INDEX IF NOT EXISTS user_roles_expires_at_idx ON user_roles (expires_at)`,
+		`CREATE INDEX IF NOT EXISTS role_includes_included_role_id_idx ON role_includes (included_role_id)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS user_roles_user_role_app_uidx ON user_roles (user_id, role_id, COALESCE(app_service_id, ''))`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS permissions_app_resource_action_uidx ON permissions (app_id, resource, action)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS roles_app_code_uidx ON roles (app_id, code)`,
@@ -381,8 +539,17 @@ func baselinePostgresStatements() []string {
 		`CREATE INDEX IF NOT EXISTS user_invitations_token_hash_idx ON user_invitations (token_hash)`,
 		`CREATE INDEX IF NOT EXISTS user_invitations_email_idx ON user_invitations (email)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS user_invitations_pending_email_uidx ON user_invitations (email) WHERE status = 1 AND deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS access_requests_user_id_idx ON access_requests (user_id)`,
+		`CREATE INDEX IF NOT EXISTS access_requests_app_status_idx ON access_requests (app_service_id, status)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS access_requests_pending_uidx ON access_requests (user_id, role_id) WHERE status = 1`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS user_groups_name_uidx ON user_groups (name) WHERE deleted_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS user_group_members_user_id_idx ON user_group_members (user_id)`,
+		`CREATE INDEX IF NOT EXISTS user_group_roles_role_id_idx ON user_group_roles (role_id)`,
 		`CREATE INDEX IF NOT EXISTS token_rotation_events_user_rotated_idx ON token_rotation_events (user_id, rotated_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_activity_events_user_created ON activity_events (user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_activity_events_target_created ON activity_events (target_type, target_id, created_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_events_seq ON activity_events (seq)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_checkpoints_to_seq ON activity_checkpoints (to_seq)`,
 	}
 }
 
@@ -400,8 +567,10 @@ var baselineSystemRoles = []struct {
 
 // baselinePermissionCatalog is the final permission catalog: the 18 base
 // permissions from migration 010, the user:verify permission from migration 012,
-// then the 2 settings permissions from migration 022. Order matches the
-// step-by-step `up` so SQLite AUTOINCREMENT ids line up identically.
+// the 2 settings permissions from migration 022, the audit:read permission from
+// migration 033, then the isme row of access_request:approve from migration
+// 039. Order matches the step-by-step `up` so SQLite AUTOINCREMENT ids line up
+// identically.
 var baselinePermissionCatalog = []struct {
 	resource string
 	action   string
@@ -427,6 +596,8 @@ var baselinePermissionCatalog = []struct {
 	{"user", "verify"},
 	{"settings", "read"},
 	{"settings", "update"},
+	{"audit", "read"},
+	{"access_request", "approve"},
 }
 
 // baselineReadOnlyCodes are the core read permissions granted to the member and
@@ -442,21 +613,24 @@ var baselineReadOnlyCodes = []struct {
 }
 
 // baselineScheduleJobs is the final set of schedule_config rows after migrations
-// 025 (session_revoke + rotation_cleanup), 027 (activity_cleanup) and 029
-// (database_backup). All disabled by default.
+// 025 (session_revoke + rotation_cleanup), 027 (activity_cleanup), 029
+// (database_backup) and 038 (role_member_expiry). Only role_member_expiry is
+// enabled by default.
 var baselineScheduleJobs = []struct {
-	jobKey string
-	cron   string
-	params string
+	jobKey  string
+	enabled bool
+	cron    string
+	params  string
 }{
-	{"session_revoke", "0 3 * * *", "{}"},
-	{"rotation_cleanup", "0 4 * * *", `{"retention_hours":48}`},
-	{"activity_cleanup", "0 5 * * *", `{"retention_days":90}`},
-	{"database_backup", "0 3 * * *", `{"retain_count":10}`},
+	{"session_revoke", false, "0 3 * * *", "{}"},
+	{"rotation_cleanup", false, "0 4 * * *", `{"retention_hours":48}`},
+	{"activity_cleanup", false, "0 5 * * *", `{"retention_days":90}`},
+	{"database_backup", false, "0 3 * * *", `{"retain_count":10}`},
+	{"role_member_expiry", true, "*/5 * * * *", "{}"},
 }
 
 // baselineSeed reproduces the migration-embedded seed data (010/014/022/025/
-// 027/029) in their final shape, dialect-aware (SQLite INSERT OR IGNORE vs
+// 027/029/038) in their final shape, dialect-aware (SQLite INSERT OR IGNORE vs
 // Postgres ON CONFLICT DO NOTHING). Grants reference permissions by
 // (resource, action) subquery so they are id-agnostic across dialects.
 func baselineSeed(ctx context.Context, db bun.IDB) error {
@@ -520,17 +694,26 @@ func baselineSeed(ctx context.Context, db bun.IDB) error {
 		return fmt.Errorf("baseline seed app_isme: %w", err)
 	}
 
-	// schedule_config job rows (migrations 025/027/029)
-	scheduleSQL := `INSERT OR IGNORE INTO schedule_config (job_key, enabled, cron, params) VALUES (?, 0, ?, ?)`
+	// schedule_config job rows (migrations 025/027/029/038)
+	scheduleSQL := `INSERT OR IGNORE INTO schedule_config (job_key, enabled, cron, params) VALUES (?, ?, ?, ?)`
 	if pg {
-		scheduleSQL = `INSERT INTO schedule_config (job_key, enabled, cron, params) VALUES (?, FALSE, ?, ?) ON CONFLICT (job_key) DO NOTHING`
+		scheduleSQL = `INSERT INTO schedule_config (job_key, enabled, cron, params) VALUES (?, ?, ?, ?) ON CONFLICT (job_key) DO NOTHING`
 	}
 	for _, job := range baselineScheduleJobs {
-		if _, err := db.ExecContext(ctx, scheduleSQL, job.jobKey, job.cron, job.params); err != nil {
+		if _, err := db.ExecContext(ctx, scheduleSQL, job.jobKey, job.enabled, job.cron, job.params); err != nil {
 			return fmt.Errorf("baseline seed schedule %s: %w", job.jobKey, err)
 		}
 	}
 
+	// audit hash chain head row (migration 034)
+	chainHeadSQL := `INSERT OR IGNORE INTO activity_chain_head (id, seq, hash) VALUES (1, 0, ?)`
+	if pg {
+		chainHeadSQL = `INSERT INTO activity_chain_head (id, seq, hash) VALUES (1, 0, ?) ON CONFLICT (id) DO NOTHING`
+	}
+	if _, err := db.ExecContext(ctx, chainHeadSQL, chainGenesisHash); err != nil {
+		return fmt.Errorf("baseline seed activity_chain_head: %w", err)
+	}
+
 	return nil
 }
 
@@ -591,11 +774,18 @@ func baselineDown(db bun.IDB) error {
 	ctx := context.Background()
 
 	tables := []string{
+		"user_group_roles",
+		"user_group_members",
+		"user_groups",
+		"access_requests",
 		"user_invitation_roles",
 		"user_invitations",
 		"user_roles",
 		"role_permissions",
+		"role_includes",
 		"token_rotation_events",
+		"activity_checkpoints",
+		"activity_chain_head",
 		"activity_events",
 		"schedule_config",
 		"permissions",
@@ -38,4 +38,13 @@ var Migrations = []pkgMigrate.Migration{
 	m029SeedDatabaseBackupSchedule,
 	m030FixBoolColumnsPg,
 	m031AddRedirectURLsToAppServices,
+	m032AddAuditColumnsToActivityEvents,
+	m033SeedAuditPermission,
+	m034AddHashChainToActivityEvents,
+	m035AddFlaggedAtToPermissions,
+	m036CreateRoleIncludesTable,
+	m037AddWindowToUserRoles,
+	m038SeedRoleMemberExpirySchedule,
+	m039CreateAccessRequestsTable,
+	m040CreateUserGroupsTables,
 }
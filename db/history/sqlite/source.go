@@ -0,0 +1,59 @@
+package history
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"go/scanner"
+	"go/token"
+)
+
+// sources embeds the migration files so the binary can checksum the exact
+// code each migration runs.
+//
+//go:embed *.go
+var sources embed.FS
+
+// SourceChecksum returns the SHA-256 of the named migration's code: the Go
+// tokens of its file (NNN_<name>.go) without comments or layout, so
+// reformatting or re-commenting a migration keeps its checksum while changing
+// a statement does not.
+//
+// The baseline gets no checksum. baseline.go is rewritten whenever a new
+// migration lands, to keep it the final schema, so its code differs from what
+// ran on every database it ever created; the schema fingerprint still covers
+// what it left behind.
+func SourceChecksum(name string) (string, error) {
+	if name == BaselineMigration.Name {
+		return "", nil
+	}
+	file := name + ".go"
+	src, err := sources.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("no source for migration %s: %w", name, err)
+	}
+
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	var scanErr error
+	s.Init(fset.AddFile(file, fset.Base(), len(src)), src, func(_ token.Position, msg string) {
+		scanErr = fmt.Errorf("scan %s: %s", file, msg)
+	}, 0)
+	sum := sha256.New()
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON {
+			// an inserted semicolon's literal is the newline it replaces
+			lit = ""
+		}
+		fmt.Fprintf(sum, "%d:%q ", tok, lit)
+	}
+	if scanErr != nil {
+		return "", scanErr
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
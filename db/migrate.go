@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
 
 	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
 	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/migration"
 
+	"github.com/uptrace/bun"
 	kueryDb "github.com/vukyn/kuery/bun/db"
 	"github.com/vukyn/kuery/bun/migrate"
 )
@@ -24,6 +30,10 @@ func main() {
 		fmt.Println("  down     - Rollback last migration")
 		fmt.Println("  reset    - Rollback all migrations")
 		fmt.Println("  baseline - Apply the squashed dual-dialect baseline (fresh install)")
+		fmt.Println("  status   - List applied and pending migrations")
+		fmt.Println("  dry-run  - Print the SQL the pending migrations run, then roll back")
+		fmt.Println("  verify   - Check applied migrations and the live schema for drift")
+		fmt.Println("  accept   - Record the current checksums and schema as expected")
 		os.Exit(1)
 	}
 
@@ -65,22 +75,27 @@ func main() {
 	}
 	defer db.Close()
 
+	ctx := context.Background()
 	switch command {
 	case "up":
+		before := applied(ctx, db)
 		stats, err := migrate.Run(db, sqliteHistory.Migrations)
 		if err != nil {
 			log.Fatalf("Migration failed: %v", err)
 		}
+		record(ctx, db, before)
 		printMigrateReport(stats.TotalSuccess, stats.TotalSkipped, "All migrations completed successfully")
 	case "baseline":
 		// Apply the squashed dual-dialect baseline as the fresh-install path:
 		// full schema + seed data in one migration, then the incremental 001-029
 		// set is stamped as already applied (inside the baseline) so a later `up`
 		// is a clean no-op. Not part of sqliteHistory.Migrations on purpose.
+		before := applied(ctx, db)
 		stats, err := migrate.Run(db, []migrate.Migration{sqliteHistory.BaselineMigration})
 		if err != nil {
 			log.Fatalf("Baseline migration failed: %v", err)
 		}
+		record(ctx, db, before)
 		printMigrateReport(stats.TotalSuccess, stats.TotalSkipped, "Baseline applied successfully")
 	case "down":
 		rolledBack, err := migrate.RollbackLast(db, sqliteHistory.Migrations)
@@ -95,6 +110,7 @@ func main() {
 			if rolledBack {
 				totalSuccess = 1
 			}
+			forget(ctx, db)
 			printMigrateReport(totalSuccess, 0, "Last migration rolled back successfully")
 		}
 	case "reset":
@@ -102,7 +118,25 @@ func main() {
 		if err != nil {
 			log.Fatalf("Rollback failed: %v", err)
 		}
+		forget(ctx, db)
 		printMigrateReport(n, 0, "All migrations rolled back successfully")
+	case "status":
+		printStatus(ctx, db)
+	case "dry-run":
+		dryRun(ctx, db)
+	case "verify":
+		if !verify(ctx, db) {
+			os.Exit(1)
+		}
+	case "accept":
+		names, err := migration.Applied(ctx, db)
+		if err != nil {
+			log.Fatalf("Accept failed: %v", err)
+		}
+		if err := migration.Record(ctx, db, names, sqliteHistory.SourceChecksum); err != nil {
+			log.Fatalf("Accept failed: %v", err)
+		}
+		fmt.Printf("Recorded checksums for %d applied migration(s) and the current schema\n", len(names))
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
@@ -116,3 +150,105 @@ func printMigrateReport(totalSuccess, totalSkipped int, msg string) {
 	fmt.Printf("Total Skipped: %d\n", totalSkipped)
 	fmt.Println(msg)
 }
+
+// applied returns the applied migration names before a run, so record can
+// tell which ones the run added.
+func applied(ctx context.Context, db *bun.DB) []string {
+	names, err := migration.Applied(ctx, db)
+	if err != nil {
+		log.Fatalf("Failed to read applied migrations: %v", err)
+	}
+	return names
+}
+
+// record stores the checksum of each migration the run applied, with the
+// schema it left behind, for verify to compare against later.
+func record(ctx context.Context, db *bun.DB, before []string) {
+	var added []string
+	for _, name := range applied(ctx, db) {
+		if !slices.Contains(before, name) {
+			added = append(added, name)
+		}
+	}
+	if err := migration.Record(ctx, db, added, sqliteHistory.SourceChecksum); err != nil {
+		log.Fatalf("Migrations applied, but recording their checksums failed: %v", err)
+	}
+}
+
+// forget drops the checksums of rolled back migrations.
+func forget(ctx context.Context, db *bun.DB) {
+	if err := migration.Forget(ctx, db); err != nil {
+		log.Fatalf("Rolled back, but updating the recorded checksums failed: %v", err)
+	}
+}
+
+// printStatus lists every migration, applied ones with the time they ran.
+func printStatus(ctx context.Context, db *bun.DB) {
+	entries, err := migration.Status(ctx, db, sqliteHistory.Migrations, sqliteHistory.BaselineMigration.Name)
+	if err != nil {
+		log.Fatalf("Status failed: %v", err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIGRATION\tSTATE\tAPPLIED AT\tCHECKSUM")
+	pending := 0
+	for _, entry := range entries {
+		state, appliedAt, checksum := "applied", entry.AppliedAt.UTC().Format(time.RFC3339), "-"
+		switch {
+		case entry.Pending:
+			state, appliedAt = "pending", "-"
+			pending++
+		case entry.Unknown:
+			state = "unknown"
+		}
+		if entry.Checksum != "" {
+			checksum = entry.Checksum[:12]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Name, state, appliedAt, checksum)
+	}
+	tw.Flush()
+	fmt.Printf("\n%d applied, %d pending\n", len(entries)-pending, pending)
+}
+
+// dryRun prints what `up` would execute without committing any of it.
+func dryRun(ctx context.Context, db *bun.DB) {
+	statements, err := migration.DryRun(ctx, db, sqliteHistory.Migrations)
+	current := ""
+	for _, statement := range statements {
+		if statement.Migration != current {
+			current = statement.Migration
+			fmt.Printf("-- %s\n", current)
+		}
+		fmt.Printf("%s;\n\n", statement.Query)
+	}
+	if err != nil {
+		log.Fatalf("Dry run failed (nothing was applied): %v", err)
+	}
+	if len(statements) == 0 {
+		fmt.Println("No pending migrations")
+		return
+	}
+	fmt.Println("-- dry run: rolled back, nothing was applied")
+}
+
+// verify reports drift and whether there was none.
+func verify(ctx context.Context, db *bun.DB) bool {
+	drift, err := migration.Verify(ctx, db, sqliteHistory.SourceChecksum)
+	if err != nil {
+		log.Fatalf("Verify failed: %v", err)
+	}
+	for _, name := range drift.Changed {
+		fmt.Printf("CHANGED     %s: its code differs from when it was applied\n", name)
+	}
+	if drift.SchemaChanged {
+		fmt.Printf("SCHEMA      live schema %s differs from %s recorded after the last migration\n",
+			drift.ActualSchema[:12], drift.ExpectedSchema[:12])
+	}
+	if len(drift.Unrecorded) > 0 {
+		fmt.Printf("UNRECORDED  %d migration(s) applied before checksums were kept; run `make migrate-accept DB=...` once the schema is known to be right\n",
+			len(drift.Unrecorded))
+	}
+	if drift.OK() {
+		fmt.Println("No drift detected")
+	}
+	return drift.OK()
+}
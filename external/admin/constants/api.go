@@ -0,0 +1,57 @@
+package constants
+
+import "time"
+
+// Paths are relative to the isme API base; {name} segments are path params.
+const (
+	// Users
+	API_USERS               = "/users"                                      // GET
+	API_USER_DETAIL         = "/users/{userID}"                             // DELETE
+	API_USER_STATUS         = "/users/{userID}/status"                      // PATCH
+	API_USER_VERIFY         = "/users/{userID}/verify"                      // POST
+	API_USER_SESSIONS       = "/users/{userID}/sessions"                    // GET
+	API_USER_SESSION_REVOKE = "/users/{userID}/sessions/{sessionID}/revoke" // POST
+	API_USER_INVITES        = "/users/invites"                              // GET, POST
+	API_USER_INVITE_REVOKE  = "/users/invites/{invitationID}/revoke"        // POST
+
+	// Roles and role members
+	API_ROLES              = "/roles"                           // GET, POST
+	API_ROLE_DETAIL        = "/roles/{roleID}"                  // GET, PUT, DELETE
+	API_ROLE_PERMISSIONS   = "/roles/{roleID}/permissions"      // PUT
+	API_ROLE_INCLUDES      = "/roles/{roleID}/includes"         // PUT
+	API_ROLE_MEMBERS       = "/roles/{roleID}/members"          // GET, POST
+	API_ROLE_MEMBER_DETAIL = "/roles/{roleID}/members/{userID}" // DELETE
+
+	// Groups and group members
+	API_GROUPS              = "/groups"                            // GET, POST
+	API_GROUP_DETAIL        = "/groups/{groupID}"                  // GET, PUT, DELETE
+	API_GROUP_ROLES         = "/groups/{groupID}/roles"            // PUT
+	API_GROUP_MEMBERS       = "/groups/{groupID}/members"          // GET, POST
+	API_GROUP_MEMBER_DETAIL = "/groups/{groupID}/members/{userID}" // DELETE
+
+	// Permission catalog
+	API_PERMISSIONS           = "/permissions"                // GET, POST
+	API_PERMISSION_APPEARANCE = "/permissions/appearance"     // PUT
+	API_PERMISSION_DETAIL     = "/permissions/{permissionID}" // DELETE
+
+	// App services
+	API_APP_SERVICES         = "/app-service"                       // GET
+	API_APP_SERVICE_REGISTER = "/app-service/register"              // POST
+	API_APP_SERVICE_VERIFY   = "/app-service/verify"                // POST
+	API_APP_SERVICE_REFRESH  = "/app-service/refresh"               // POST
+	API_APP_SERVICE_DETAIL   = "/app-service/{appServiceID}"        // GET, PATCH
+	API_APP_SERVICE_STATUS   = "/app-service/{appServiceID}/status" // PATCH
+
+	// Access requests (approver side)
+	API_ACCESS_REQUEST_QUEUE   = "/access-requests/queue"               // GET
+	API_ACCESS_REQUEST_APPROVE = "/access-requests/{requestID}/approve" // POST
+	API_ACCESS_REQUEST_DENY    = "/access-requests/{requestID}/deny"    // POST
+
+	// Settings
+	API_SETTINGS_SESSION_REVOKE   = "/settings/session-revoke"   // GET, PUT
+	API_SETTINGS_ROTATION_CLEANUP = "/settings/rotation-cleanup" // GET, PUT
+	API_SETTINGS_ACTIVITY_CLEANUP = "/settings/activity-cleanup" // GET, PUT
+	API_SETTINGS_DATABASE_BACKUP  = "/settings/database-backup"  // GET, PUT
+
+	DEFAULT_TIMEOUT = 30 * time.Second
+)
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+type ListAccessRequestQueueRequest struct {
+	models.ApiRequest
+	// Status narrows the queue (0 = every status, 1 = pending).
+	Status int32
+}
+
+type AccessRequest struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name"`
+	UserEmail     string `json:"user_email"`
+	AppServiceID  string `json:"app_service_id"`
+	AppCode       string `json:"app_code"`
+	AppName       string `json:"app_name"`
+	RoleID        string `json:"role_id"`
+	RoleCode      string `json:"role_code"`
+	RoleName      string `json:"role_name"`
+	Justification string `json:"justification"`
+	DurationHours int32  `json:"duration_hours"`
+	// Status is 1 (pending), 2 (approved), 3 (denied) or 4 (cancelled).
+	Status        int32  `json:"status"`
+	DecidedBy     string `json:"decided_by"`
+	DecidedByName string `json:"decided_by_name"`
+	DecisionNote  string `json:"decision_note"`
+	DecidedAt     string `json:"decided_at"`
+	// ExpiresAt is when the membership granted by an approval ends.
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListAccessRequestsResponse struct {
+	Items []AccessRequest `json:"items"`
+}
+
+// DecideAccessRequestRequest approves or denies a pending request. The caller
+// must hold access_request:approve in the request's app.
+type DecideAccessRequestRequest struct {
+	models.ApiRequest
+	RequestID string `json:"-"`
+	Note      string `json:"note,omitempty"`
+}
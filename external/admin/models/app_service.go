@@ -0,0 +1,100 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+type RegisterAppServiceRequest struct {
+	models.ApiRequest
+	AppCode     string `json:"app_code"`
+	AppName     string `json:"app_name"`
+	RedirectURL string `json:"redirect_url"`
+	// RedirectURLs are up to 3 additional allowed callbacks.
+	RedirectURLs []string `json:"redirect_urls"`
+	CtxInfo      string   `json:"ctx_info"`
+	Icon         string   `json:"icon"`
+	Color        string   `json:"color"`
+}
+
+type AppServiceSecretResponse struct {
+	// AppSecret is returned once; store it now.
+	AppSecret string `json:"app_secret"`
+}
+
+type VerifyAppServiceRequest struct {
+	models.ApiRequest
+	AppCode   string `json:"app_code"`
+	CtxInfo   string `json:"ctx_info"`
+	AppSecret string `json:"app_secret"`
+}
+
+type VerifyAppServiceResponse struct {
+	Ok bool `json:"ok"`
+}
+
+// RefreshAppServiceSecretRequest rotates the secret; the current one must be
+// presented.
+type RefreshAppServiceSecretRequest struct {
+	models.ApiRequest
+	AppCode   string `json:"app_code"`
+	AppSecret string `json:"app_secret"`
+	CtxInfo   string `json:"ctx_info"`
+}
+
+type ListAppServicesRequest struct {
+	models.ApiRequest
+	Page     int
+	PageSize int
+	// Search matches app name or code.
+	Search string
+	// Status is 1 (active), 2 (inactive) or 3 (terminated); 0 lists all.
+	Status  int32
+	CtxInfo string
+}
+
+type AppService struct {
+	ID             string   `json:"id"`
+	AppCode        string   `json:"app_code"`
+	AppName        string   `json:"app_name"`
+	RedirectURL    string   `json:"redirect_url"`
+	RedirectURLs   []string `json:"redirect_urls"`
+	CtxInfo        string   `json:"ctx_info"`
+	Status         int32    `json:"status"`
+	Icon           string   `json:"icon"`
+	Color          string   `json:"color"`
+	CreatedAt      string   `json:"created_at"`
+	CreatedBy      string   `json:"created_by"`
+	CreatedByEmail string   `json:"created_by_email"`
+	UpdatedAt      string   `json:"updated_at"`
+	UpdatedBy      string   `json:"updated_by"`
+}
+
+type ListAppServicesResponse struct {
+	Items []AppService `json:"items"`
+	Total int64        `json:"total"`
+	Page  int          `json:"page"`
+}
+
+type AppServiceRequest struct {
+	models.ApiRequest
+	AppServiceID string `json:"-"`
+}
+
+// UpdateAppServiceRequest changes only the fields that are set; an empty,
+// non-nil RedirectURLs clears the additional callbacks.
+type UpdateAppServiceRequest struct {
+	models.ApiRequest
+	AppServiceID string    `json:"-"`
+	AppName      *string   `json:"app_name,omitempty"`
+	RedirectURL  *string   `json:"redirect_url,omitempty"`
+	RedirectURLs *[]string `json:"redirect_urls,omitempty"`
+	Icon         *string   `json:"icon,omitempty"`
+	Color        *string   `json:"color,omitempty"`
+}
+
+type UpdateAppServiceStatusRequest struct {
+	models.ApiRequest
+	AppServiceID string `json:"-"`
+	// Status is 1 (active), 2 (inactive) or 3 (terminated).
+	Status int32 `json:"status"`
+}
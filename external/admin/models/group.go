@@ -0,0 +1,101 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+type ListGroupsRequest struct {
+	models.ApiRequest
+}
+
+type Group struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	MembersCount int    `json:"members_count"`
+	RolesCount   int    `json:"roles_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// GroupRole is a role a group grants its members, in the role's own app.
+type GroupRole struct {
+	ID      string `json:"id"`
+	AppID   string `json:"app_id"`
+	AppCode string `json:"app_code"`
+	AppName string `json:"app_name"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+}
+
+type GroupDetail struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	MembersCount int         `json:"members_count"`
+	Roles        []GroupRole `json:"roles"`
+	CreatedAt    string      `json:"created_at"`
+}
+
+type GroupRequest struct {
+	models.ApiRequest
+	GroupID string `json:"-"`
+}
+
+type CreateGroupRequest struct {
+	models.ApiRequest
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CreateGroupResponse struct {
+	ID string `json:"id"`
+}
+
+type UpdateGroupRequest struct {
+	models.ApiRequest
+	GroupID     string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SetGroupRolesRequest replaces the roles the group grants with RoleIDs (any
+// app); an empty list clears them.
+type SetGroupRolesRequest struct {
+	models.ApiRequest
+	GroupID string   `json:"-"`
+	RoleIDs []string `json:"role_ids"`
+}
+
+type ListGroupMembersRequest struct {
+	models.ApiRequest
+	GroupID  string
+	Page     int
+	PageSize int
+	// Query matches the member's name or email.
+	Query string
+}
+
+type GroupMember struct {
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListGroupMembersResponse struct {
+	Items []GroupMember `json:"items"`
+	Total int           `json:"total"`
+	Page  int           `json:"page"`
+}
+
+type AddGroupMembersRequest struct {
+	models.ApiRequest
+	GroupID string   `json:"-"`
+	UserIDs []string `json:"user_ids"`
+}
+
+type RemoveGroupMemberRequest struct {
+	models.ApiRequest
+	GroupID string `json:"-"`
+	UserID  string `json:"-"`
+}
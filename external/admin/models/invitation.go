@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+// RoleAssignment is an app-scoped role the invitee receives on accept.
+type RoleAssignment struct {
+	RoleID       string `json:"role_id"`
+	AppServiceID string `json:"app_service_id"`
+}
+
+type CreateInvitationRequest struct {
+	models.ApiRequest
+	Email       string           `json:"email"`
+	Assignments []RoleAssignment `json:"assignments"`
+}
+
+type CreateInvitationResponse struct {
+	ID string `json:"id"`
+	// InviteLink is returned once; isme keeps only a hash of its token.
+	InviteLink string `json:"invite_link"`
+}
+
+type ListInvitationsRequest struct {
+	models.ApiRequest
+}
+
+type InvitationAssignment struct {
+	RoleID       string `json:"role_id"`
+	RoleName     string `json:"role_name"`
+	RoleCode     string `json:"role_code"`
+	AppServiceID string `json:"app_service_id"`
+	AppCode      string `json:"app_code"`
+	AppName      string `json:"app_name"`
+}
+
+type Invitation struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	// Status is 1 (pending), 2 (accepted) or 3 (revoked).
+	Status      int32                  `json:"status"`
+	Assignments []InvitationAssignment `json:"assignments"`
+	ExpiresAt   string                 `json:"expires_at"`
+	AcceptedAt  string                 `json:"accepted_at"`
+	CreatedAt   string                 `json:"created_at"`
+}
+
+type ListInvitationsResponse struct {
+	Items []Invitation `json:"items"`
+}
+
+type RevokeInvitationRequest struct {
+	models.ApiRequest
+	InvitationID string `json:"-"`
+}
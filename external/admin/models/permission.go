@@ -0,0 +1,54 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+type Permission struct {
+	ID       int64  `json:"id"`
+	AppID    string `json:"app_id"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+	// FlaggedAt (RFC 3339) is set once the app's catalog sync stopped listing
+	// the permission; deleting it confirms the removal.
+	FlaggedAt string `json:"flagged_at,omitempty"`
+}
+
+// ListPermissionsRequest filters the catalog by app, by id or by code.
+// Flagged keeps only permissions awaiting removal confirmation.
+type ListPermissionsRequest struct {
+	models.ApiRequest
+	AppID   string
+	AppCode string
+	Flagged bool
+}
+
+type PermissionPair struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+type CreatePermissionsRequest struct {
+	models.ApiRequest
+	AppID       string           `json:"app_id"`
+	Permissions []PermissionPair `json:"permissions"`
+}
+
+// UpdatePermissionAppearanceRequest sets the icon and color of every action of
+// Resource in the app's catalog.
+type UpdatePermissionAppearanceRequest struct {
+	models.ApiRequest
+	AppID    string `json:"app_id"`
+	Resource string `json:"resource"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+type DeletePermissionRequest struct {
+	models.ApiRequest
+	PermissionID int64 `json:"-"`
+}
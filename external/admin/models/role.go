@@ -0,0 +1,149 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+// ListRolesRequest filters by app, by id or by code; both empty lists every
+// app's roles.
+type ListRolesRequest struct {
+	models.ApiRequest
+	AppID   string
+	AppCode string
+}
+
+type Role struct {
+	ID           string `json:"id"`
+	AppID        string `json:"app_id"`
+	AppCode      string `json:"app_code"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Icon         string `json:"icon"`
+	Color        string `json:"color"`
+	IsSystem     bool   `json:"is_system"`
+	MembersCount int    `json:"members_count"`
+}
+
+// RoleDetail splits the role's grants: Permissions are assigned to the role
+// itself, InheritedPermissions come only from the roles in Includes.
+type RoleDetail struct {
+	ID                   string                `json:"id"`
+	AppID                string                `json:"app_id"`
+	AppCode              string                `json:"app_code"`
+	Code                 string                `json:"code"`
+	Name                 string                `json:"name"`
+	Description          string                `json:"description"`
+	Icon                 string                `json:"icon"`
+	Color                string                `json:"color"`
+	IsSystem             bool                  `json:"is_system"`
+	Permissions          []Permission          `json:"permissions"`
+	Includes             []RoleRef             `json:"includes"`
+	InheritedPermissions []InheritedPermission `json:"inherited_permissions"`
+}
+
+type RoleRef struct {
+	ID   string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// InheritedPermission is granted through the included roles listed (by code)
+// in Via.
+type InheritedPermission struct {
+	Permission
+	Via []string `json:"via"`
+}
+
+type RoleRequest struct {
+	models.ApiRequest
+	RoleID string `json:"-"`
+}
+
+type CreateRoleRequest struct {
+	models.ApiRequest
+	AppID       string `json:"app_id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// CloneFromRoleID copies that role's permissions into the new one.
+	CloneFromRoleID string `json:"clone_from_role_id"`
+	Icon            string `json:"icon"`
+	Color           string `json:"color"`
+}
+
+type CreateRoleResponse struct {
+	ID string `json:"id"`
+}
+
+type UpdateRoleRequest struct {
+	models.ApiRequest
+	RoleID      string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+}
+
+// SetRolePermissionsRequest replaces the role's permissions with
+// PermissionIDs; an empty list clears them.
+type SetRolePermissionsRequest struct {
+	models.ApiRequest
+	RoleID        string  `json:"-"`
+	PermissionIDs []int64 `json:"permission_ids"`
+}
+
+// SetRoleIncludesRequest replaces the roles the role includes with RoleIDs
+// (same app only); an empty list clears them.
+type SetRoleIncludesRequest struct {
+	models.ApiRequest
+	RoleID  string   `json:"-"`
+	RoleIDs []string `json:"role_ids"`
+}
+
+type ListRoleMembersRequest struct {
+	models.ApiRequest
+	RoleID   string
+	Page     int
+	PageSize int
+	// Query matches the member's name or email.
+	Query string
+}
+
+type RoleMember struct {
+	UserID       string  `json:"user_id"`
+	Name         string  `json:"name"`
+	Email        string  `json:"email"`
+	AppServiceID *string `json:"app_service_id"`
+	CreatedAt    string  `json:"created_at"`
+	// StartsAt/ExpiresAt (RFC 3339) bound a time-bound membership; empty is
+	// an open bound. An expired member is listed until the expiry job runs.
+	StartsAt  string `json:"starts_at,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+type ListRoleMembersResponse struct {
+	Items []RoleMember `json:"items"`
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+}
+
+type AddRoleMembersRequest struct {
+	models.ApiRequest
+	RoleID  string   `json:"-"`
+	UserIDs []string `json:"user_ids"`
+	// StartsAt/ExpiresAt (RFC 3339, optional) make the membership time-bound.
+	// They replace the window of users who already hold the role, so leaving
+	// both empty makes the membership permanent.
+	StartsAt  string `json:"starts_at,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+type RemoveRoleMemberRequest struct {
+	models.ApiRequest
+	RoleID string `json:"-"`
+	UserID string `json:"-"`
+	// AppServiceID removes only the assignment scoped to that app; empty
+	// removes the user's global assignment.
+	AppServiceID string `json:"-"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+// Cron fields below are standard 5-field expressions (minute hour
+// day-of-month month day-of-week); LastRunAt is a unix timestamp, nil until
+// the job first runs.
+
+type SettingsRequest struct {
+	models.ApiRequest
+}
+
+type SessionRevokeSettings struct {
+	Enabled          bool   `json:"enabled"`
+	Cron             string `json:"cron"`
+	LastRunAt        *int64 `json:"last_run_at"`
+	LastRevokedCount *int64 `json:"last_revoked_count"`
+}
+
+type UpdateSessionRevokeSettingsRequest struct {
+	models.ApiRequest
+	Enabled bool   `json:"enabled"`
+	Cron    string `json:"cron"`
+}
+
+type RotationCleanupSettings struct {
+	Enabled          bool   `json:"enabled"`
+	Cron             string `json:"cron"`
+	RetentionHours   int64  `json:"retention_hours"`
+	LastRunAt        *int64 `json:"last_run_at"`
+	LastCleanedCount *int64 `json:"last_cleaned_count"`
+}
+
+type UpdateRotationCleanupSettingsRequest struct {
+	models.ApiRequest
+	Enabled        bool   `json:"enabled"`
+	Cron           string `json:"cron"`
+	RetentionHours int64  `json:"retention_hours"`
+}
+
+type ActivityCleanupSettings struct {
+	Enabled         bool   `json:"enabled"`
+	Cron            string `json:"cron"`
+	RetentionDays   int64  `json:"retention_days"`
+	LastRunAt       *int64 `json:"last_run_at"`
+	LastPrunedCount *int64 `json:"last_pruned_count"`
+}
+
+type UpdateActivityCleanupSettingsRequest struct {
+	models.ApiRequest
+	Enabled       bool   `json:"enabled"`
+	Cron          string `json:"cron"`
+	RetentionDays int64  `json:"retention_days"`
+}
+
+type DatabaseBackupSettings struct {
+	Enabled        bool    `json:"enabled"`
+	Cron           string  `json:"cron"`
+	RetainCount    int64   `json:"retain_count"`
+	LastRunAt      *int64  `json:"last_run_at"`
+	LastBackupPath *string `json:"last_backup_path"`
+	LastKeptCount  *int64  `json:"last_kept_count"`
+	// LastRemote* are null when the server keeps backups local only.
+	LastRemoteUploaded *bool   `json:"last_remote_uploaded"`
+	LastRemoteKey      *string `json:"last_remote_key"`
+	LastRemoteError    *string `json:"last_remote_error"`
+	// LastEncrypted is null for runs recorded before backup encryption.
+	LastEncrypted *bool   `json:"last_encrypted"`
+	LastKeyID     *string `json:"last_key_id"`
+}
+
+type UpdateDatabaseBackupSettingsRequest struct {
+	models.ApiRequest
+	Enabled     bool   `json:"enabled"`
+	Cron        string `json:"cron"`
+	RetainCount int64  `json:"retain_count"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"github.com/vukyn/isme/external/models"
+)
+
+type ListUsersRequest struct {
+	models.ApiRequest
+	Page     int
+	PageSize int
+	// Search matches name or email.
+	Search string
+	// Status is 1 (active) or 2 (inactive); 0 lists both.
+	Status int32
+	// AppCode and RoleCode narrow the list to members of that app's role.
+	AppCode  string
+	RoleCode string
+	// Verified filters on verification state; nil lists both.
+	Verified *bool
+}
+
+type AppRole struct {
+	AppCode  string `json:"app_code"`
+	AppName  string `json:"app_name"`
+	RoleCode string `json:"role_code"`
+	RoleName string `json:"role_name"`
+	// Groups names the groups the role is inherited through.
+	Groups []string `json:"groups,omitempty"`
+}
+
+type User struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Status        int32     `json:"status"`
+	IsVerified    bool      `json:"is_verified"`
+	Roles         []AppRole `json:"roles"`
+	SessionsCount int       `json:"sessions_count"`
+	LastLoginAt   string    `json:"last_login_at"`
+	CreatedAt     string    `json:"created_at"`
+}
+
+type ListUsersResponse struct {
+	Items []User `json:"items"`
+	Total int64  `json:"total"`
+	Page  int    `json:"page"`
+}
+
+// UserRequest addresses one user, for the calls that need nothing else.
+type UserRequest struct {
+	models.ApiRequest
+	UserID string `json:"-"`
+}
+
+type UpdateUserStatusRequest struct {
+	models.ApiRequest
+	UserID string `json:"-"`
+	// Status is 1 (active) or 2 (inactive).
+	Status int32 `json:"status"`
+}
+
+type UserSession struct {
+	ID          string `json:"id"`
+	ClientIP    string `json:"client_ip"`
+	UserAgent   string `json:"user_agent"`
+	LastLoginAt string `json:"last_login_at"`
+	ExpiresAt   string `json:"expires_at"`
+	Status      int32  `json:"status"`
+}
+
+type RevokeUserSessionRequest struct {
+	models.ApiRequest
+	UserID    string `json:"-"`
+	SessionID string `json:"-"`
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vukyn/isme/external/admin/models"
+)
+
+// IService calls isme's admin API. Every call is made as the user behind the
+// service's TokenProvider and needs the matching permission (user:read,
+// role:assign, ...); isme's refusals come back as *models.APIError from
+// external/models, matchable with errors.Is against its Err* values.
+type IService interface {
+	// Users
+	ListUsers(ctx context.Context, req *models.ListUsersRequest) (*models.ListUsersResponse, error)
+	UpdateUserStatus(ctx context.Context, req *models.UpdateUserStatusRequest) error
+	VerifyUser(ctx context.Context, req *models.UserRequest) error
+	DeleteUser(ctx context.Context, req *models.UserRequest) error
+	ListUserSessions(ctx context.Context, req *models.UserRequest) ([]models.UserSession, error)
+	RevokeUserSession(ctx context.Context, req *models.RevokeUserSessionRequest) error
+
+	// Roles
+	ListRoles(ctx context.Context, req *models.ListRolesRequest) ([]models.Role, error)
+	CreateRole(ctx context.Context, req *models.CreateRoleRequest) (*models.CreateRoleResponse, error)
+	GetRole(ctx context.Context, req *models.RoleRequest) (*models.RoleDetail, error)
+	UpdateRole(ctx context.Context, req *models.UpdateRoleRequest) error
+	DeleteRole(ctx context.Context, req *models.RoleRequest) error
+	SetRolePermissions(ctx context.Context, req *models.SetRolePermissionsRequest) error
+	SetRoleIncludes(ctx context.Context, req *models.SetRoleIncludesRequest) error
+
+	// Role members
+	ListRoleMembers(ctx context.Context, req *models.ListRoleMembersRequest) (*models.ListRoleMembersResponse, error)
+	AddRoleMembers(ctx context.Context, req *models.AddRoleMembersRequest) error
+	RemoveRoleMember(ctx context.Context, req *models.RemoveRoleMemberRequest) error
+
+	// Groups (members inherit the group's roles)
+	ListGroups(ctx context.Context, req *models.ListGroupsRequest) ([]models.Group, error)
+	CreateGroup(ctx context.Context, req *models.CreateGroupRequest) (*models.CreateGroupResponse, error)
+	GetGroup(ctx context.Context, req *models.GroupRequest) (*models.GroupDetail, error)
+	UpdateGroup(ctx context.Context, req *models.UpdateGroupRequest) error
+	DeleteGroup(ctx context.Context, req *models.GroupRequest) error
+	SetGroupRoles(ctx context.Context, req *models.SetGroupRolesRequest) error
+	ListGroupMembers(ctx context.Context, req *models.ListGroupMembersRequest) (*models.ListGroupMembersResponse, error)
+	AddGroupMembers(ctx context.Context, req *models.AddGroupMembersRequest) error
+	RemoveGroupMember(ctx context.Context, req *models.RemoveGroupMemberRequest) error
+
+	// Permission catalog
+	ListPermissions(ctx context.Context, req *models.ListPermissionsRequest) ([]models.Permission, error)
+	CreatePermissions(ctx context.Context, req *models.CreatePermissionsRequest) ([]models.Permission, error)
+	UpdatePermissionAppearance(ctx context.Context, req *models.UpdatePermissionAppearanceRequest) error
+	DeletePermission(ctx context.Context, req *models.DeletePermissionRequest) error
+
+	// Invitations
+	CreateInvitation(ctx context.Context, req *models.CreateInvitationRequest) (*models.CreateInvitationResponse, error)
+	ListInvitations(ctx context.Context, req *models.ListInvitationsRequest) (*models.ListInvitationsResponse, error)
+	RevokeInvitation(ctx context.Context, req *models.RevokeInvitationRequest) error
+
+	// Access requests
+	ListAccessRequestQueue(ctx context.Context, req *models.ListAccessRequestQueueRequest) (*models.ListAccessRequestsResponse, error)
+	ApproveAccessRequest(ctx context.Context, req *models.DecideAccessRequestRequest) error
+	DenyAccessRequest(ctx context.Context, req *models.DecideAccessRequestRequest) error
+
+	// App services
+	RegisterAppService(ctx context.Context, req *models.RegisterAppServiceRequest) (*models.AppServiceSecretResponse, error)
+	VerifyAppService(ctx context.Context, req *models.VerifyAppServiceRequest) (*models.VerifyAppServiceResponse, error)
+	RefreshAppServiceSecret(ctx context.Context, req *models.RefreshAppServiceSecretRequest) (*models.AppServiceSecretResponse, error)
+	ListAppServices(ctx context.Context, req *models.ListAppServicesRequest) (*models.ListAppServicesResponse, error)
+	GetAppService(ctx context.Context, req *models.AppServiceRequest) (*models.AppService, error)
+	UpdateAppService(ctx context.Context, req *models.UpdateAppServiceRequest) error
+	UpdateAppServiceStatus(ctx context.Context, req *models.UpdateAppServiceStatusRequest) error
+
+	// Settings
+	GetSessionRevokeSettings(ctx context.Context, req *models.SettingsRequest) (*models.SessionRevokeSettings, error)
+	UpdateSessionRevokeSettings(ctx context.Context, req *models.UpdateSessionRevokeSettingsRequest) error
+	GetRotationCleanupSettings(ctx context.Context, req *models.SettingsRequest) (*models.RotationCleanupSettings, error)
+	UpdateRotationCleanupSettings(ctx context.Context, req *models.UpdateRotationCleanupSettingsRequest) error
+	GetActivityCleanupSettings(ctx context.Context, req *models.SettingsRequest) (*models.ActivityCleanupSettings, error)
+	UpdateActivityCleanupSettings(ctx context.Context, req *models.UpdateActivityCleanupSettingsRequest) error
+	GetDatabaseBackupSettings(ctx context.Context, req *models.SettingsRequest) (*models.DatabaseBackupSettings, error)
+	UpdateDatabaseBackupSettings(ctx context.Context, req *models.UpdateDatabaseBackupSettingsRequest) error
+}
@@ -0,0 +1,635 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/vukyn/isme/external/admin/constants"
+	"github.com/vukyn/isme/external/admin/models"
+	"github.com/vukyn/isme/external/internal/rest"
+	externalModels "github.com/vukyn/isme/external/models"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/vukyn/kuery/log"
+)
+
+// TokenProvider supplies the bearer token of the admin the calls are made as.
+// *tokensource.TokenSource from external/auth/tokensource implements it and
+// keeps the token refreshed; StaticToken suits scripts holding one token.
+type TokenProvider interface {
+	AccessToken(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenProvider that always returns the same token.
+type StaticToken string
+
+func (t StaticToken) AccessToken(context.Context) (string, error) {
+	return string(t), nil
+}
+
+type service struct {
+	endpoint  string
+	tokens    TokenProvider
+	transport http.RoundTripper
+}
+
+// Option configures the service.
+type Option func(*service)
+
+// WithTransport sends the requests through transport. Pass an instrumented one
+// (e.g. otelhttp.NewTransport(http.DefaultTransport)) to carry the caller's
+// trace to isme.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(s *service) {
+		s.transport = transport
+	}
+}
+
+func NewService(endpoint string, tokens TokenProvider, opts ...Option) IService {
+	s := &service{
+		endpoint: endpoint,
+		tokens:   tokens,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// rest builds a client for one call made from ctx; its requests run on ctx.
+func (s *service) rest(ctx context.Context, retry int, retryInterval, timeout time.Duration) *resty.Client {
+	return rest.New(ctx, s.transport).
+		SetRetryCount(retry).
+		SetRetryWaitTime(retryInterval).
+		SetTimeout(map[bool]time.Duration{true: timeout, false: constants.DEFAULT_TIMEOUT}[timeout > 0]).
+		SetBaseURL(s.endpoint)
+}
+
+//lint:ignore U1000 For debugging purpose
+func (s *service) restWithDebug(ctx context.Context, retry int, retryInterval, timeout time.Duration) *resty.Client {
+	return s.rest(ctx, retry, retryInterval, timeout).
+		SetDebug(true).
+		SetLogger(log.New()).
+		EnableGenerateCurlOnDebug() // Enable this to generate curl command on debug
+}
+
+// call is one admin API request; path may hold {name} segments filled from
+// pathParams.
+type call struct {
+	method     string
+	path       string
+	pathParams map[string]string
+	query      url.Values
+	body       any
+}
+
+// response is the kuery envelope; Data points at the caller's result.
+type response struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// do sends c and decodes the envelope's data into result (skipped when nil).
+// Non-2xx answers become *externalModels.APIError.
+func (s *service) do(ctx context.Context, opts externalModels.ApiRequest, c call, result any) error {
+	var client *resty.Client
+	if opts.Debug {
+		client = s.restWithDebug(ctx, opts.Retry, opts.RetryInterval, opts.Timeout)
+	} else {
+		client = s.rest(ctx, opts.Retry, opts.RetryInterval, opts.Timeout)
+	}
+
+	req := client.R().
+		SetPathParams(c.pathParams).
+		SetResult(&response{Data: result}).
+		ForceContentType("application/json")
+	if s.tokens != nil {
+		accessToken, err := s.tokens.AccessToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.SetAuthToken(accessToken)
+	}
+	if c.query != nil {
+		req.SetQueryParamsFromValues(c.query)
+	}
+	if c.body != nil {
+		req.SetHeader("Content-Type", "application/json").SetBody(c.body)
+	}
+
+	resp, err := req.Execute(c.method, c.path)
+	if err != nil {
+		log.New().Errorf("Error %s %s from isme admin: %v", c.method, c.path, err)
+		return fmt.Errorf("%s %s: %w", c.method, c.path, err)
+	}
+	if resp.StatusCode() < http.StatusOK || resp.StatusCode() >= http.StatusMultipleChoices {
+		log.New().Errorf("Error %s %s from isme admin: %v", c.method, c.path, resp.String())
+		return externalModels.NewAPIError(resp.StatusCode(), resp.Body())
+	}
+	return nil
+}
+
+// query drops empty values so unset filters are not sent.
+func query(pairs ...string) url.Values {
+	values := url.Values{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			values.Set(pairs[i], pairs[i+1])
+		}
+	}
+	return values
+}
+
+func itoa[T int | int32 | int64](v T) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// Users
+
+func (s *service) ListUsers(ctx context.Context, req *models.ListUsersRequest) (*models.ListUsersResponse, error) {
+	values := query(
+		"page", itoa(req.Page),
+		"size", itoa(req.PageSize),
+		"query", req.Search,
+		"status", itoa(req.Status),
+		"app", req.AppCode,
+		"role", req.RoleCode,
+	)
+	if req.Verified != nil {
+		values.Set("verified", strconv.FormatBool(*req.Verified))
+	}
+	result := &models.ListUsersResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_USERS, query: values}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateUserStatus(ctx context.Context, req *models.UpdateUserStatusRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPatch,
+		path:       constants.API_USER_STATUS,
+		pathParams: map[string]string{"userID": req.UserID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) VerifyUser(ctx context.Context, req *models.UserRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_USER_VERIFY,
+		pathParams: map[string]string{"userID": req.UserID},
+	}, nil)
+}
+
+func (s *service) DeleteUser(ctx context.Context, req *models.UserRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodDelete,
+		path:       constants.API_USER_DETAIL,
+		pathParams: map[string]string{"userID": req.UserID},
+	}, nil)
+}
+
+func (s *service) ListUserSessions(ctx context.Context, req *models.UserRequest) ([]models.UserSession, error) {
+	var result []models.UserSession
+	if err := s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodGet,
+		path:       constants.API_USER_SESSIONS,
+		pathParams: map[string]string{"userID": req.UserID},
+	}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) RevokeUserSession(ctx context.Context, req *models.RevokeUserSessionRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_USER_SESSION_REVOKE,
+		pathParams: map[string]string{"userID": req.UserID, "sessionID": req.SessionID},
+	}, nil)
+}
+
+// Roles
+
+func (s *service) ListRoles(ctx context.Context, req *models.ListRolesRequest) ([]models.Role, error) {
+	var result []models.Role
+	if err := s.do(ctx, req.ApiRequest, call{
+		method: http.MethodGet,
+		path:   constants.API_ROLES,
+		query:  query("app_id", req.AppID, "app_code", req.AppCode),
+	}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) CreateRole(ctx context.Context, req *models.CreateRoleRequest) (*models.CreateRoleResponse, error) {
+	result := &models.CreateRoleResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_ROLES, body: req}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) GetRole(ctx context.Context, req *models.RoleRequest) (*models.RoleDetail, error) {
+	result := &models.RoleDetail{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodGet,
+		path:       constants.API_ROLE_DETAIL,
+		pathParams: map[string]string{"roleID": req.RoleID},
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateRole(ctx context.Context, req *models.UpdateRoleRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPut,
+		path:       constants.API_ROLE_DETAIL,
+		pathParams: map[string]string{"roleID": req.RoleID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) DeleteRole(ctx context.Context, req *models.RoleRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodDelete,
+		path:       constants.API_ROLE_DETAIL,
+		pathParams: map[string]string{"roleID": req.RoleID},
+	}, nil)
+}
+
+func (s *service) SetRolePermissions(ctx context.Context, req *models.SetRolePermissionsRequest) error {
+	body := *req
+	if body.PermissionIDs == nil {
+		body.PermissionIDs = []int64{} // null would not clear them
+	}
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPut,
+		path:       constants.API_ROLE_PERMISSIONS,
+		pathParams: map[string]string{"roleID": req.RoleID},
+		body:       &body,
+	}, nil)
+}
+
+func (s *service) SetRoleIncludes(ctx context.Context, req *models.SetRoleIncludesRequest) error {
+	body := *req
+	if body.RoleIDs == nil {
+		body.RoleIDs = []string{} // null would not clear them
+	}
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPut,
+		path:       constants.API_ROLE_INCLUDES,
+		pathParams: map[string]string{"roleID": req.RoleID},
+		body:       &body,
+	}, nil)
+}
+
+// Role members
+
+func (s *service) ListRoleMembers(ctx context.Context, req *models.ListRoleMembersRequest) (*models.ListRoleMembersResponse, error) {
+	result := &models.ListRoleMembersResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodGet,
+		path:       constants.API_ROLE_MEMBERS,
+		pathParams: map[string]string{"roleID": req.RoleID},
+		query:      query("page", itoa(req.Page), "size", itoa(req.PageSize), "query", req.Query),
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) AddRoleMembers(ctx context.Context, req *models.AddRoleMembersRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_ROLE_MEMBERS,
+		pathParams: map[string]string{"roleID": req.RoleID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) RemoveRoleMember(ctx context.Context, req *models.RemoveRoleMemberRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodDelete,
+		path:       constants.API_ROLE_MEMBER_DETAIL,
+		pathParams: map[string]string{"roleID": req.RoleID, "userID": req.UserID},
+		query:      query("app_service_id", req.AppServiceID),
+	}, nil)
+}
+
+// Groups
+
+func (s *service) ListGroups(ctx context.Context, req *models.ListGroupsRequest) ([]models.Group, error) {
+	var result []models.Group
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_GROUPS}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) CreateGroup(ctx context.Context, req *models.CreateGroupRequest) (*models.CreateGroupResponse, error) {
+	result := &models.CreateGroupResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_GROUPS, body: req}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) GetGroup(ctx context.Context, req *models.GroupRequest) (*models.GroupDetail, error) {
+	result := &models.GroupDetail{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodGet,
+		path:       constants.API_GROUP_DETAIL,
+		pathParams: map[string]string{"groupID": req.GroupID},
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateGroup(ctx context.Context, req *models.UpdateGroupRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPut,
+		path:       constants.API_GROUP_DETAIL,
+		pathParams: map[string]string{"groupID": req.GroupID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) DeleteGroup(ctx context.Context, req *models.GroupRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodDelete,
+		path:       constants.API_GROUP_DETAIL,
+		pathParams: map[string]string{"groupID": req.GroupID},
+	}, nil)
+}
+
+func (s *service) SetGroupRoles(ctx context.Context, req *models.SetGroupRolesRequest) error {
+	body := *req
+	if body.RoleIDs == nil {
+		body.RoleIDs = []string{} // null would not clear them
+	}
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPut,
+		path:       constants.API_GROUP_ROLES,
+		pathParams: map[string]string{"groupID": req.GroupID},
+		body:       &body,
+	}, nil)
+}
+
+func (s *service) ListGroupMembers(ctx context.Context, req *models.ListGroupMembersRequest) (*models.ListGroupMembersResponse, error) {
+	result := &models.ListGroupMembersResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodGet,
+		path:       constants.API_GROUP_MEMBERS,
+		pathParams: map[string]string{"groupID": req.GroupID},
+		query:      query("page", itoa(req.Page), "size", itoa(req.PageSize), "query", req.Query),
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) AddGroupMembers(ctx context.Context, req *models.AddGroupMembersRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_GROUP_MEMBERS,
+		pathParams: map[string]string{"groupID": req.GroupID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) RemoveGroupMember(ctx context.Context, req *models.RemoveGroupMemberRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodDelete,
+		path:       constants.API_GROUP_MEMBER_DETAIL,
+		pathParams: map[string]string{"groupID": req.GroupID, "userID": req.UserID},
+	}, nil)
+}
+
+// Permission catalog
+
+func (s *service) ListPermissions(ctx context.Context, req *models.ListPermissionsRequest) ([]models.Permission, error) {
+	var result []models.Permission
+	if err := s.do(ctx, req.ApiRequest, call{
+		method: http.MethodGet,
+		path:   constants.API_PERMISSIONS,
+		query:  query("app_id", req.AppID, "app_code", req.AppCode, "flagged", map[bool]string{true: "true"}[req.Flagged]),
+	}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) CreatePermissions(ctx context.Context, req *models.CreatePermissionsRequest) ([]models.Permission, error) {
+	var result []models.Permission
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_PERMISSIONS, body: req}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdatePermissionAppearance(ctx context.Context, req *models.UpdatePermissionAppearanceRequest) error {
+	return s.do(ctx, req.ApiRequest, call{method: http.MethodPut, path: constants.API_PERMISSION_APPEARANCE, body: req}, nil)
+}
+
+func (s *service) DeletePermission(ctx context.Context, req *models.DeletePermissionRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodDelete,
+		path:       constants.API_PERMISSION_DETAIL,
+		pathParams: map[string]string{"permissionID": strconv.FormatInt(req.PermissionID, 10)},
+	}, nil)
+}
+
+// Invitations
+
+func (s *service) CreateInvitation(ctx context.Context, req *models.CreateInvitationRequest) (*models.CreateInvitationResponse, error) {
+	result := &models.CreateInvitationResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_USER_INVITES, body: req}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) ListInvitations(ctx context.Context, req *models.ListInvitationsRequest) (*models.ListInvitationsResponse, error) {
+	result := &models.ListInvitationsResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_USER_INVITES}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) RevokeInvitation(ctx context.Context, req *models.RevokeInvitationRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_USER_INVITE_REVOKE,
+		pathParams: map[string]string{"invitationID": req.InvitationID},
+	}, nil)
+}
+
+// Access requests
+
+func (s *service) ListAccessRequestQueue(ctx context.Context, req *models.ListAccessRequestQueueRequest) (*models.ListAccessRequestsResponse, error) {
+	result := &models.ListAccessRequestsResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method: http.MethodGet,
+		path:   constants.API_ACCESS_REQUEST_QUEUE,
+		query:  query("status", itoa(req.Status)),
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) ApproveAccessRequest(ctx context.Context, req *models.DecideAccessRequestRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_ACCESS_REQUEST_APPROVE,
+		pathParams: map[string]string{"requestID": req.RequestID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) DenyAccessRequest(ctx context.Context, req *models.DecideAccessRequestRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPost,
+		path:       constants.API_ACCESS_REQUEST_DENY,
+		pathParams: map[string]string{"requestID": req.RequestID},
+		body:       req,
+	}, nil)
+}
+
+// App services
+
+func (s *service) RegisterAppService(ctx context.Context, req *models.RegisterAppServiceRequest) (*models.AppServiceSecretResponse, error) {
+	result := &models.AppServiceSecretResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_APP_SERVICE_REGISTER, body: req}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) VerifyAppService(ctx context.Context, req *models.VerifyAppServiceRequest) (*models.VerifyAppServiceResponse, error) {
+	result := &models.VerifyAppServiceResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_APP_SERVICE_VERIFY, body: req}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) RefreshAppServiceSecret(ctx context.Context, req *models.RefreshAppServiceSecretRequest) (*models.AppServiceSecretResponse, error) {
+	result := &models.AppServiceSecretResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodPost, path: constants.API_APP_SERVICE_REFRESH, body: req}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) ListAppServices(ctx context.Context, req *models.ListAppServicesRequest) (*models.ListAppServicesResponse, error) {
+	result := &models.ListAppServicesResponse{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method: http.MethodGet,
+		path:   constants.API_APP_SERVICES,
+		query: query(
+			"page", itoa(req.Page),
+			"page_size", itoa(req.PageSize),
+			"search", req.Search,
+			"status", itoa(req.Status),
+			"ctx_info", req.CtxInfo,
+		),
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) GetAppService(ctx context.Context, req *models.AppServiceRequest) (*models.AppService, error) {
+	result := &models.AppService{}
+	if err := s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodGet,
+		path:       constants.API_APP_SERVICE_DETAIL,
+		pathParams: map[string]string{"appServiceID": req.AppServiceID},
+	}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateAppService(ctx context.Context, req *models.UpdateAppServiceRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPatch,
+		path:       constants.API_APP_SERVICE_DETAIL,
+		pathParams: map[string]string{"appServiceID": req.AppServiceID},
+		body:       req,
+	}, nil)
+}
+
+func (s *service) UpdateAppServiceStatus(ctx context.Context, req *models.UpdateAppServiceStatusRequest) error {
+	return s.do(ctx, req.ApiRequest, call{
+		method:     http.MethodPatch,
+		path:       constants.API_APP_SERVICE_STATUS,
+		pathParams: map[string]string{"appServiceID": req.AppServiceID},
+		body:       req,
+	}, nil)
+}
+
+// Settings
+
+func (s *service) GetSessionRevokeSettings(ctx context.Context, req *models.SettingsRequest) (*models.SessionRevokeSettings, error) {
+	result := &models.SessionRevokeSettings{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_SETTINGS_SESSION_REVOKE}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateSessionRevokeSettings(ctx context.Context, req *models.UpdateSessionRevokeSettingsRequest) error {
+	return s.do(ctx, req.ApiRequest, call{method: http.MethodPut, path: constants.API_SETTINGS_SESSION_REVOKE, body: req}, nil)
+}
+
+func (s *service) GetRotationCleanupSettings(ctx context.Context, req *models.SettingsRequest) (*models.RotationCleanupSettings, error) {
+	result := &models.RotationCleanupSettings{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_SETTINGS_ROTATION_CLEANUP}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateRotationCleanupSettings(ctx context.Context, req *models.UpdateRotationCleanupSettingsRequest) error {
+	return s.do(ctx, req.ApiRequest, call{method: http.MethodPut, path: constants.API_SETTINGS_ROTATION_CLEANUP, body: req}, nil)
+}
+
+func (s *service) GetActivityCleanupSettings(ctx context.Context, req *models.SettingsRequest) (*models.ActivityCleanupSettings, error) {
+	result := &models.ActivityCleanupSettings{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_SETTINGS_ACTIVITY_CLEANUP}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateActivityCleanupSettings(ctx context.Context, req *models.UpdateActivityCleanupSettingsRequest) error {
+	return s.do(ctx, req.ApiRequest, call{method: http.MethodPut, path: constants.API_SETTINGS_ACTIVITY_CLEANUP, body: req}, nil)
+}
+
+func (s *service) GetDatabaseBackupSettings(ctx context.Context, req *models.SettingsRequest) (*models.DatabaseBackupSettings, error) {
+	result := &models.DatabaseBackupSettings{}
+	if err := s.do(ctx, req.ApiRequest, call{method: http.MethodGet, path: constants.API_SETTINGS_DATABASE_BACKUP}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) UpdateDatabaseBackupSettings(ctx context.Context, req *models.UpdateDatabaseBackupSettingsRequest) error {
+	return s.do(ctx, req.ApiRequest, call{method: http.MethodPut, path: constants.API_SETTINGS_DATABASE_BACKUP, body: req}, nil)
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vukyn/isme/external/admin/models"
+	externalModels "github.com/vukyn/isme/external/models"
+)
+
+type recorded struct {
+	method, path, query, authorization string
+	body                               map[string]any
+}
+
+// fakeIsme answers every request with status and body, recording the last one.
+func fakeIsme(t *testing.T, status int, body string) (IService, *recorded) {
+	t.Helper()
+	last := &recorded{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		*last = recorded{method: r.Method, path: r.URL.EscapedPath(), query: r.URL.RawQuery, authorization: r.Header.Get("Authorization")}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &last.body); err != nil {
+				t.Errorf("request body %q: %v", raw, err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return NewService(srv.URL+"/api/v1", StaticToken("admin-token")), last
+}
+
+func TestListUsers(t *testing.T) {
+	svc, last := fakeIsme(t, http.StatusOK, `{"code":200,"message":"success","data":{"items":[{"id":"u1","email":"ada@example.com","roles":[{"app_code":"isme","role_code":"admin"}]}],"total":1,"page":2}}`)
+	verified := true
+
+	users, err := svc.ListUsers(context.Background(), &models.ListUsersRequest{Page: 2, Search: "ada", AppCode: "isme", RoleCode: "admin", Verified: &verified})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if users.Total != 1 || users.Page != 2 || users.Items[0].Roles[0].RoleCode != "admin" {
+		t.Errorf("users = %+v", users)
+	}
+	if last.method != http.MethodGet || last.path != "/api/v1/users" {
+		t.Errorf("request = %s %s", last.method, last.path)
+	}
+	if want := "app=isme&page=2&query=ada&role=admin&verified=true"; last.query != want {
+		t.Errorf("query = %q, want %q (unset filters omitted)", last.query, want)
+	}
+	if last.authorization != "Bearer admin-token" {
+		t.Errorf("Authorization = %q", last.authorization)
+	}
+}
+
+func TestPathParamsAndBody(t *testing.T) {
+	svc, last := fakeIsme(t, http.StatusOK, `{"code":200,"message":"success"}`)
+
+	if err := svc.AddRoleMembers(context.Background(), &models.AddRoleMembersRequest{RoleID: "role/1", UserIDs: []string{"u1", "u2"}}); err != nil {
+		t.Fatalf("AddRoleMembers: %v", err)
+	}
+	if last.method != http.MethodPost || last.path != "/api/v1/roles/role%2F1/members" {
+		t.Errorf("request = %s %s, want the role id escaped", last.method, last.path)
+	}
+	if _, ok := last.body["role_id"]; ok || len(last.body) != 1 || len(last.body["user_ids"].([]any)) != 2 {
+		t.Errorf("body = %v, want only user_ids", last.body)
+	}
+
+	if err := svc.AddRoleMembers(context.Background(), &models.AddRoleMembersRequest{RoleID: "r1", UserIDs: []string{"u1"}, ExpiresAt: "2026-07-01T00:00:00Z"}); err != nil {
+		t.Fatalf("AddRoleMembers(time-bound): %v", err)
+	}
+	if last.body["expires_at"] != "2026-07-01T00:00:00Z" {
+		t.Errorf("body = %v, want expires_at", last.body)
+	}
+
+	if err := svc.RemoveRoleMember(context.Background(), &models.RemoveRoleMemberRequest{RoleID: "r1", UserID: "u1", AppServiceID: "a1"}); err != nil {
+		t.Fatalf("RemoveRoleMember: %v", err)
+	}
+	if last.method != http.MethodDelete || last.path != "/api/v1/roles/r1/members/u1" || last.query != "app_service_id=a1" {
+		t.Errorf("request = %s %s?%s", last.method, last.path, last.query)
+	}
+
+	if err := svc.SetRolePermissions(context.Background(), &models.SetRolePermissionsRequest{RoleID: "r1"}); err != nil {
+		t.Fatalf("SetRolePermissions: %v", err)
+	}
+	if ids, ok := last.body["permission_ids"].([]any); !ok || len(ids) != 0 {
+		t.Errorf("permission_ids = %v, want [] so the permissions are cleared", last.body["permission_ids"])
+	}
+
+	if err := svc.SetRoleIncludes(context.Background(), &models.SetRoleIncludesRequest{RoleID: "r1"}); err != nil {
+		t.Fatalf("SetRoleIncludes: %v", err)
+	}
+	if ids, ok := last.body["role_ids"].([]any); !ok || len(ids) != 0 || last.path != "/api/v1/roles/r1/includes" {
+		t.Errorf("request %s body %v, want role_ids [] so the includes are cleared", last.path, last.body)
+	}
+
+	name := "Renamed"
+	if err := svc.UpdateAppService(context.Background(), &models.UpdateAppServiceRequest{AppServiceID: "a1", AppName: &name}); err != nil {
+		t.Fatalf("UpdateAppService: %v", err)
+	}
+	if last.method != http.MethodPatch || len(last.body) != 1 || last.body["app_name"] != name {
+		t.Errorf("request = %s body %v, want only the changed field", last.method, last.body)
+	}
+}
+
+func TestErrorsAreTyped(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		body   string
+		want   error
+		msg    string
+	}{
+		{http.StatusBadRequest, `{"code":400,"message":"code must be a lowercase slug"}`, externalModels.ErrBadRequest, "code must be a lowercase slug"},
+		{http.StatusForbidden, `{"code":403,"message":"forbidden"}`, externalModels.ErrForbidden, "forbidden"},
+		{http.StatusNotFound, `{"code":404,"message":"role not found"}`, externalModels.ErrNotFound, "role not found"},
+		{http.StatusConflict, `{"code":409,"message":"role code already exists"}`, externalModels.ErrConflict, "role code already exists"},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, externalModels.ErrServer, "<html>bad gateway</html>"},
+	} {
+		svc, _ := fakeIsme(t, tc.status, tc.body)
+		_, err := svc.CreateRole(context.Background(), &models.CreateRoleRequest{AppID: "a1", Code: "viewer", Name: "Viewer"})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%d: err = %v, want %v", tc.status, err, tc.want)
+		}
+		var apiErr *externalModels.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tc.status || apiErr.Message != tc.msg {
+			t.Errorf("%d: APIError = %+v", tc.status, apiErr)
+		}
+	}
+}
+
+type failingTokens struct{}
+
+func (failingTokens) AccessToken(context.Context) (string, error) {
+	return "", errors.New("session revoked")
+}
+
+func TestTokenProviderErrorStopsTheCall(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	defer srv.Close()
+
+	svc := NewService(srv.URL, failingTokens{})
+	if _, err := svc.ListRoles(context.Background(), &models.ListRolesRequest{}); err == nil {
+		t.Fatal("expected the token error")
+	}
+	if called {
+		t.Error("request sent without a token")
+	}
+}
+
+func TestAccessRequestDecisions(t *testing.T) {
+	svc, last := fakeIsme(t, http.StatusOK, `{"code":200,"message":"success","data":{"items":[{"id":"acr1","app_code":"isme","role_code":"support","status":1,"duration_hours":8}]}}`)
+
+	queue, err := svc.ListAccessRequestQueue(context.Background(), &models.ListAccessRequestQueueRequest{Status: 1})
+	if err != nil {
+		t.Fatalf("ListAccessRequestQueue: %v", err)
+	}
+	if len(queue.Items) != 1 || queue.Items[0].RoleCode != "support" || queue.Items[0].DurationHours != 8 {
+		t.Errorf("queue = %+v", queue)
+	}
+	if last.path != "/api/v1/access-requests/queue" || last.query != "status=1" {
+		t.Errorf("request = %s?%s", last.path, last.query)
+	}
+
+	if err := svc.ApproveAccessRequest(context.Background(), &models.DecideAccessRequestRequest{RequestID: "acr1", Note: "ok for the incident"}); err != nil {
+		t.Fatalf("ApproveAccessRequest: %v", err)
+	}
+	if last.method != http.MethodPost || last.path != "/api/v1/access-requests/acr1/approve" {
+		t.Errorf("request = %s %s", last.method, last.path)
+	}
+	if len(last.body) != 1 || last.body["note"] != "ok for the incident" {
+		t.Errorf("body = %v, want only note", last.body)
+	}
+
+	if err := svc.DenyAccessRequest(context.Background(), &models.DecideAccessRequestRequest{RequestID: "acr1"}); err != nil {
+		t.Fatalf("DenyAccessRequest: %v", err)
+	}
+	if last.path != "/api/v1/access-requests/acr1/deny" || len(last.body) != 0 {
+		t.Errorf("request = %s body %v, want no body fields", last.path, last.body)
+	}
+}
+
+func TestGroupRolesAndMembers(t *testing.T) {
+	svc, last := fakeIsme(t, http.StatusOK, `{"code":200,"message":"success","data":null}`)
+
+	if err := svc.SetGroupRoles(context.Background(), &models.SetGroupRolesRequest{GroupID: "grp1"}); err != nil {
+		t.Fatalf("SetGroupRoles: %v", err)
+	}
+	if last.method != http.MethodPut || last.path != "/api/v1/groups/grp1/roles" {
+		t.Errorf("request = %s %s", last.method, last.path)
+	}
+	if roleIDs, ok := last.body["role_ids"].([]any); !ok || len(roleIDs) != 0 {
+		t.Errorf("body = %v, want an empty role_ids list that clears the roles", last.body)
+	}
+
+	if err := svc.AddGroupMembers(context.Background(), &models.AddGroupMembersRequest{GroupID: "grp1", UserIDs: []string{"usr1"}}); err != nil {
+		t.Fatalf("AddGroupMembers: %v", err)
+	}
+	if last.method != http.MethodPost || last.path != "/api/v1/groups/grp1/members" || len(last.body) != 1 {
+		t.Errorf("request = %s %s body %v", last.method, last.path, last.body)
+	}
+
+	if err := svc.RemoveGroupMember(context.Background(), &models.RemoveGroupMemberRequest{GroupID: "grp1", UserID: "usr1"}); err != nil {
+		t.Fatalf("RemoveGroupMember: %v", err)
+	}
+	if last.method != http.MethodDelete || last.path != "/api/v1/groups/grp1/members/usr1" {
+		t.Errorf("request = %s %s", last.method, last.path)
+	}
+}
+
+type traceKey struct{}
+
+// roundTripFunc lets a test stand in for an instrumented transport.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransportSeesTheCallersContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":200,"message":"success","data":{"items":[],"total":0,"page":1}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var seen any
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		seen = r.Context().Value(traceKey{})
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	svc := NewService(srv.URL+"/api/v1", StaticToken("admin-token"), WithTransport(transport))
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+	if _, err := svc.ListUsers(ctx, &models.ListUsersRequest{}); err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if seen != "trace-1" {
+		t.Errorf("transport saw context value %v, want the caller's trace-1", seen)
+	}
+}
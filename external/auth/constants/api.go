@@ -8,5 +8,11 @@ const (
 	API_AUTH_REFRESH_TOKEN = "/auth/refresh" // POST
 	API_AUTH_EXCHANGE_CODE = "/auth/exchange-code" // POST
 	API_AUTH_LOGOUT        = "/auth/logout"        // POST
+	API_AUTH_JWKS          = "/auth/jwks"          // GET
 	DEFAULT_TIMEOUT        = 30 * time.Second
 )
+
+// App-authenticated endpoints: the app sends its own app_code/app_secret.
+const (
+	API_APP_PERMISSIONS_SYNC = "/app-service/permissions/sync" // POST
+)
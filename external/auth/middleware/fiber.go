@@ -0,0 +1,86 @@
+// Package middleware guards a downstream service's routes with isme access
+// tokens, for Fiber and net/http. Auth verifies the bearer token locally (see
+// external/auth/verifier) and exposes the Principal; RequirePermission and
+// RequireAnyPermission then gate routes on the app's perms, mirroring
+// kuery/rbac.
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/vukyn/isme/external/auth/verifier"
+
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgBase "github.com/vukyn/kuery/http/base"
+	pkgErr "github.com/vukyn/kuery/http/errors"
+	pkgHttp "github.com/vukyn/kuery/http/fiber"
+	"github.com/vukyn/kuery/log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const principalLocalKey = "isme.principal"
+
+// Fiber verifies the bearer token with v. The principal goes into the fiber
+// locals and the user context (verifier.FromContext), and its perms into
+// kuery's request perms, so rbac.RequirePermission keeps working for services
+// already using it.
+func Fiber(v *verifier.Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := v.Verify(c.UserContext(), bearerToken(c.Get(fiber.HeaderAuthorization)))
+		if err != nil {
+			if errors.Is(err, verifier.ErrKeysUnavailable) {
+				log.New().Errorf("Error verify token: %v", err)
+				return pkgHttp.Err(c, pkgErr.Forward(pkgBase.Response{
+					Code:    fiber.StatusServiceUnavailable,
+					Message: "token verification unavailable",
+				}))
+			}
+			log.New().Debugf("Invalid token: %v", err)
+			return pkgHttp.Unauthorized(c)
+		}
+
+		c.Locals(principalLocalKey, principal)
+		c.SetUserContext(verifier.NewContext(c.UserContext(), principal))
+		pkgCtx.SetPermsToFiberCtx(c, principal.Permissions)
+		return c.Next()
+	}
+}
+
+// PrincipalFromFiberCtx returns the principal Fiber stored for this request.
+func PrincipalFromFiberCtx(c *fiber.Ctx) (*verifier.Principal, bool) {
+	principal, ok := c.Locals(principalLocalKey).(*verifier.Principal)
+	return principal, ok && principal != nil
+}
+
+// RequirePermission rejects the request with 403 unless the principal holds
+// perm. Mount it after Fiber.
+func RequirePermission(perm string) fiber.Handler {
+	return RequireAnyPermission(perm)
+}
+
+// RequireAnyPermission rejects the request with 403 unless the principal holds
+// at least one of perms.
+func RequireAnyPermission(perms ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := PrincipalFromFiberCtx(c)
+		if !ok {
+			return pkgHttp.Unauthorized(c)
+		}
+		if !principal.HasAnyPermission(perms...) {
+			return pkgHttp.Err(c, pkgErr.Forbidden("missing permission: "+strings.Join(perms, " or ")))
+		}
+		return c.Next()
+	}
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header;
+// anything else yields "", which Verify reports as ErrMissingToken.
+func bearerToken(authorization string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
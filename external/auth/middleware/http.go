@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/vukyn/isme/external/auth/verifier"
+
+	"github.com/vukyn/kuery/log"
+)
+
+// HTTP is Fiber for net/http: the principal is available to the wrapped
+// handler through verifier.FromContext(r.Context()).
+func HTTP(v *verifier.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := v.Verify(r.Context(), bearerToken(r.Header.Get("Authorization")))
+			if err != nil {
+				if errors.Is(err, verifier.ErrKeysUnavailable) {
+					log.New().Errorf("Error verify token: %v", err)
+					writeError(w, http.StatusServiceUnavailable, "token verification unavailable")
+					return
+				}
+				log.New().Debugf("Invalid token: %v", err)
+				writeError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(verifier.NewContext(r.Context(), principal)))
+		})
+	}
+}
+
+// RequirePermissionHTTP is RequirePermission for net/http; wrap it inside HTTP.
+func RequirePermissionHTTP(perm string) func(http.Handler) http.Handler {
+	return RequireAnyPermissionHTTP(perm)
+}
+
+// RequireAnyPermissionHTTP is RequireAnyPermission for net/http.
+func RequireAnyPermissionHTTP(perms ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := verifier.FromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+				return
+			}
+			if !principal.HasAnyPermission(perms...) {
+				writeError(w, http.StatusForbidden, "missing permission: "+strings.Join(perms, " or "))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeError answers in the same {code, message} envelope isme and kuery
+// services use, so clients parse one error shape.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{status, message})
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/external/auth/constants"
+	"github.com/vukyn/isme/external/auth/models"
+	"github.com/vukyn/isme/external/auth/verifier"
+)
+
+func newTestVerifier(t *testing.T) (*verifier.Verifier, func(perms ...string) string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != constants.API_AUTH_JWKS {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.JWKS{Keys: []models.JWK{{
+			Kty: "RSA", Use: "sig", Alg: "RS256", Kid: "k1",
+			N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(srv.Close)
+
+	sign := func(perms ...string) string {
+		header, _ := json.Marshal(map[string]any{"alg": "RS256", "kid": "k1"})
+		claims, _ := json.Marshal(map[string]any{
+			"uid": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "aud": []string{"medioa"},
+			"resource_access": map[string]any{"medioa": map[string]any{"perms": perms}},
+		})
+		input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+		digest := sha256.Sum256([]byte(input))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	return verifier.New(verifier.Config{Endpoint: srv.URL, AppCode: "medioa"}), sign
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	v, sign := newTestVerifier(t)
+	var seen *verifier.Principal
+	handler := HTTP(v)(RequirePermissionHTTP("file:upload")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = verifier.FromContext(r.Context())
+	})))
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"no header", "", http.StatusUnauthorized},
+		{"not bearer", "Basic " + sign("file:upload"), http.StatusUnauthorized},
+		{"garbage", "Bearer abc", http.StatusUnauthorized},
+		{"lacks permission", "Bearer " + sign("file:read"), http.StatusForbidden},
+		{"granted", "bearer " + sign("file:read", "file:upload"), http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/files", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.want, rec.Body.String())
+			}
+			if tc.want != http.StatusOK {
+				var body struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				}
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != tc.want {
+					t.Errorf("error body = %+v (%v), want the {code, message} envelope", body, err)
+				}
+			}
+		})
+	}
+	if seen == nil || seen.UserID != "user-1" {
+		t.Errorf("principal in context = %+v", seen)
+	}
+}
+
+func TestRequirePermissionHTTPWithoutAuth(t *testing.T) {
+	handler := RequireAnyPermissionHTTP("file:read")(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("guard let an unauthenticated request through")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+}
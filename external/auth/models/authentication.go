@@ -37,6 +37,36 @@ type RequestLoginResponse struct {
 	} `json:"data"`
 }
 
+// PermissionPair is one resource:action of the app's catalog; icon and color
+// only apply when the resource is new.
+type PermissionPair struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Icon     string `json:"icon,omitempty"`
+	Color    string `json:"color,omitempty"`
+}
+
+// SyncPermissionsRequest pushes the app's full permission catalog. Entries
+// isme holds that are not listed are flagged for an admin, never deleted.
+type SyncPermissionsRequest struct {
+	models.ApiRequest
+	AppCode     string           `json:"app_code"`
+	AppSecret   string           `json:"app_secret"`
+	CtxInfo     string           `json:"ctx_info"`
+	Permissions []PermissionPair `json:"permissions"`
+}
+
+type SyncPermissionsResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Created   []string `json:"created"`
+		Flagged   []string `json:"flagged"`
+		Restored  []string `json:"restored"`
+		Unchanged int      `json:"unchanged"`
+	} `json:"data"`
+}
+
 type RefreshTokenRequest struct {
 	models.ApiRequest
 	RefreshToken string `json:"refresh_token"`
@@ -0,0 +1,16 @@
+package models
+
+// JWKS is the key set isme publishes for verifying its access tokens.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is one RSA verification key; N and E are base64url without padding.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
@@ -12,4 +12,6 @@ type IService interface {
 	RefreshToken(ctx context.Context, req *models.RefreshTokenRequest) (*models.RefreshTokenResponse, error)
 	ExchangeCode(ctx context.Context, req *models.ExchangeCodeRequest) (*models.ExchangeCodeResponse, error)
 	Logout(ctx context.Context, req *models.LogoutRequest) (*models.LogoutResponse, error)
+	// SyncPermissions pushes the app's permission catalog with its app credentials
+	SyncPermissions(ctx context.Context, req *models.SyncPermissionsRequest) (*models.SyncPermissionsResponse, error)
 }
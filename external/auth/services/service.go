@@ -9,6 +9,7 @@ import (
 
 	"github.com/vukyn/isme/external/auth/constants"
 	"github.com/vukyn/isme/external/auth/models"
+	"github.com/vukyn/isme/external/internal/rest"
 	pkgBase "github.com/vukyn/kuery/http/base"
 	pkgErr "github.com/vukyn/kuery/http/errors"
 
@@ -17,17 +18,35 @@ import (
 )
 
 type service struct {
-	endpoint string
+	endpoint  string
+	transport http.RoundTripper
 }
 
-func NewService(endpoint string) IService {
-	return &service{
+// Option configures the service.
+type Option func(*service)
+
+// WithTransport sends the requests through transport. Pass an instrumented one
+// (e.g. otelhttp.NewTransport(http.DefaultTransport)) to carry the caller's
+// trace to the auth server.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(s *service) {
+		s.transport = transport
+	}
+}
+
+func NewService(endpoint string, opts ...Option) IService {
+	s := &service{
 		endpoint: endpoint,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *service) rest(_ context.Context, retry int, retryInterval, timeout time.Duration) *resty.Client {
-	return resty.New().
+// rest builds a client for one call made from ctx; its requests run on ctx.
+func (s *service) rest(ctx context.Context, retry int, retryInterval, timeout time.Duration) *resty.Client {
+	return rest.New(ctx, s.transport).
 		SetRetryCount(retry).
 		SetRetryWaitTime(retryInterval).
 		SetTimeout(map[bool]time.Duration{true: timeout, false: constants.DEFAULT_TIMEOUT}[timeout > 0]).
@@ -182,6 +201,34 @@ func (s *service) Logout(ctx context.Context, req *models.LogoutRequest) (*model
 	return apiResponse, nil
 }
 
+func (s *service) SyncPermissions(ctx context.Context, req *models.SyncPermissionsRequest) (*models.SyncPermissionsResponse, error) {
+	var client *resty.Client
+	if req.Debug {
+		client = s.restWithDebug(ctx, req.Retry, req.RetryInterval, req.Timeout)
+	} else {
+		client = s.rest(ctx, req.Retry, req.RetryInterval, req.Timeout)
+	}
+	client.SetHeader("Content-Type", "application/json")
+
+	apiResponse := &models.SyncPermissionsResponse{}
+	resp, err := client.R().
+		SetBody(req).
+		SetResult(apiResponse).
+		Post(constants.API_APP_PERMISSIONS_SYNC)
+
+	if err != nil {
+		log.New().Errorf("Error sync permissions to external auth: %v", err)
+		return nil, pkgErr.InternalServerError(err.Error())
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		log.New().Errorf("Error sync permissions to external auth: %v", resp.String())
+		return nil, handleResponseError(resp, resp.StatusCode())
+	}
+
+	return apiResponse, nil
+}
+
 func handleResponseError(resp *resty.Response, statusCode int) error {
 	// handle unauthorized error
 	if statusCode == http.StatusUnauthorized {
@@ -0,0 +1,54 @@
+package tokensource
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists the token pair across restarts and, when shared, across
+// instances of the app. Rotated pairs are saved as soon as isme issues them:
+// isme invalidates the previous refresh token on every refresh, so losing the
+// new one means losing the session.
+type Store interface {
+	// Load returns the saved pair, or nil when there is none.
+	Load(ctx context.Context) (*Token, error)
+	Save(ctx context.Context, token *Token) error
+	// Delete forgets the pair; it is called once isme rejects the session.
+	Delete(ctx context.Context) error
+}
+
+// MemoryStore keeps the pair in process memory only; the session does not
+// survive a restart.
+type MemoryStore struct {
+	mu    sync.Mutex
+	token *Token
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Load(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, nil
+	}
+	token := *s.token
+	return &token, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *token
+	s.token = &saved
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	return nil
+}
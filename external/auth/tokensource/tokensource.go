@@ -0,0 +1,246 @@
+// Package tokensource keeps an app's isme session usable. A TokenSource holds
+// the access/refresh pair, refreshes it shortly before the access token
+// expires, and saves every rotated pair through a Store.
+//
+// isme rotates the refresh token on every refresh and revokes the old one, so
+// two requests refreshing the same pair race: the loser's refresh is rejected
+// and the session looks revoked. TokenSource serializes refreshes; callers that
+// find the token due while another refresh is running wait for it and share
+// its result.
+package tokensource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vukyn/isme/external/auth/constants"
+	"github.com/vukyn/isme/external/auth/models"
+	"github.com/vukyn/isme/external/internal/rest"
+	pkgBase "github.com/vukyn/kuery/http/base"
+
+	"github.com/vukyn/kuery/log"
+)
+
+var (
+	// ErrNoToken means no pair was set or stored yet; log in first.
+	ErrNoToken = errors.New("no token")
+	// ErrSessionRevoked means isme rejected the refresh token: the session was
+	// logged out, revoked, or has expired, or the user was deactivated. The
+	// pair is deleted from the store; restart the login.
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+const DEFAULT_REFRESH_BEFORE = time.Minute
+
+type Config struct {
+	// Endpoint is the isme API base, the same value passed to
+	// services.NewService (e.g. https://isme.example.com/api/v1).
+	Endpoint string
+	// RefreshBefore is how long before ExpiresAt the pair is refreshed, so a
+	// token handed out is still valid when the request carrying it arrives.
+	RefreshBefore time.Duration
+	// Timeout bounds one refresh call.
+	Timeout time.Duration
+	// Transport, when set, sends the refresh calls; pass an instrumented one
+	// (e.g. otelhttp.NewTransport) to carry the caller's trace to isme.
+	Transport http.RoundTripper
+}
+
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// NewToken builds a Token from the fields of isme's exchange-code and refresh
+// responses; expiresAt is RFC 3339.
+func NewToken(accessToken, refreshToken, expiresAt string) (*Token, error) {
+	if accessToken == "" || refreshToken == "" {
+		return nil, errors.New("access and refresh token are required")
+	}
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("expires_at: %w", err)
+	}
+	return &Token{AccessToken: accessToken, RefreshToken: refreshToken, ExpiresAt: expires}, nil
+}
+
+type TokenSource struct {
+	cfg   Config
+	url   string
+	store Store
+	now   func() time.Time
+
+	mu     sync.Mutex // guards token and loaded
+	token  *Token
+	loaded bool
+
+	refreshMu sync.Mutex
+}
+
+// New returns a source backed by store (a MemoryStore when nil); zero
+// durations take the defaults. The stored pair is loaded on first use.
+func New(cfg Config, store Store) *TokenSource {
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = DEFAULT_REFRESH_BEFORE
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.DEFAULT_TIMEOUT
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &TokenSource{
+		cfg:   cfg,
+		url:   strings.TrimRight(cfg.Endpoint, "/") + constants.API_AUTH_REFRESH_TOKEN,
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Set installs a new pair, typically the one ExchangeCode returned after login.
+func (s *TokenSource) Set(ctx context.Context, token *Token) error {
+	if token == nil {
+		return ErrNoToken
+	}
+	if err := s.store.Save(ctx, token); err != nil {
+		return err
+	}
+	s.setCached(token)
+	return nil
+}
+
+// Token returns a pair whose access token is valid for at least RefreshBefore,
+// refreshing it first when needed. The error wraps ErrSessionRevoked when the
+// session is gone.
+func (s *TokenSource) Token(ctx context.Context) (*Token, error) {
+	current, err := s.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.fresh(current) {
+		return current, nil
+	}
+	return s.refresh(ctx)
+}
+
+// AccessToken is Token for the common case of only needing the bearer value.
+func (s *TokenSource) AccessToken(ctx context.Context) (string, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// refresh exchanges the current refresh token, unless another caller already
+// refreshed while this one waited for refreshMu.
+func (s *TokenSource) refresh(ctx context.Context) (*Token, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	current, err := s.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.fresh(current) {
+		return current, nil
+	}
+
+	next, err := s.exchange(ctx, current.RefreshToken)
+	if errors.Is(err, ErrSessionRevoked) {
+		// another instance sharing the store may have rotated the pair first
+		stored, loadErr := s.store.Load(ctx)
+		if loadErr == nil && stored != nil && stored.RefreshToken != current.RefreshToken {
+			s.setCached(stored)
+			if s.fresh(stored) {
+				return stored, nil
+			}
+			next, err = s.exchange(ctx, stored.RefreshToken)
+		}
+	}
+	if errors.Is(err, ErrSessionRevoked) {
+		if deleteErr := s.store.Delete(ctx); deleteErr != nil {
+			log.New().Errorf("Error delete revoked token: %v", deleteErr)
+		}
+		s.setCached(nil)
+		return nil, err
+	}
+	if err != nil {
+		// isme is unreachable: the access token is still good until it expires
+		if s.now().Before(current.ExpiresAt) {
+			log.New().Warnf("Error refresh token from external auth, using current token: %v", err)
+			return current, nil
+		}
+		return nil, err
+	}
+
+	if err := s.store.Save(ctx, next); err != nil {
+		// the old refresh token is already spent; the new pair must at least
+		// live on in memory
+		log.New().Errorf("Error save refreshed token: %v", err)
+	}
+	s.setCached(next)
+	return next, nil
+}
+
+func (s *TokenSource) exchange(ctx context.Context, refreshToken string) (*Token, error) {
+	apiResponse := &models.RefreshTokenResponse{}
+	apiError := &pkgBase.Response{}
+	resp, err := rest.New(ctx, s.cfg.Transport).SetTimeout(s.cfg.Timeout).R().
+		SetBody(&models.RefreshTokenRequest{RefreshToken: refreshToken}).
+		SetResult(apiResponse).
+		SetError(apiError).
+		Post(s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		// isme answers every unusable refresh token with 400 "invalid refresh token"
+		return nil, fmt.Errorf("%w: %s", ErrSessionRevoked, apiError.Message)
+	default:
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode(), resp.String())
+	}
+	return NewToken(apiResponse.Data.AccessToken, apiResponse.Data.RefreshToken, apiResponse.Data.ExpiresAt)
+}
+
+// current returns a copy of the cached pair, loading it from the store once.
+func (s *TokenSource) current(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loaded {
+		token, err := s.store.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.token, s.loaded = token, true
+	}
+	if s.token == nil {
+		return nil, ErrNoToken
+	}
+	token := *s.token
+	return &token, nil
+}
+
+func (s *TokenSource) setCached(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = true
+	s.token = nil
+	if token != nil {
+		cached := *token
+		s.token = &cached
+	}
+}
+
+func (s *TokenSource) fresh(token *Token) bool {
+	return s.now().Add(s.cfg.RefreshBefore).Before(token.ExpiresAt)
+}
@@ -0,0 +1,209 @@
+package tokensource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/external/auth/constants"
+)
+
+// fakeIsme rotates refresh tokens like isme does: each refresh token works
+// once, and the previous one is revoked.
+type fakeIsme struct {
+	mu        sync.Mutex
+	valid     string
+	issued    int
+	expiresIn time.Duration
+	down      bool
+	refreshes atomic.Int32
+	server    *httptest.Server
+}
+
+func newFakeIsme(t *testing.T) *fakeIsme {
+	t.Helper()
+	f := &fakeIsme{valid: "refresh-0", expiresIn: time.Hour}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != constants.API_AUTH_REFRESH_TOKEN || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		f.refreshes.Add(1)
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if f.down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if req.RefreshToken == "" || req.RefreshToken != f.valid {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":400,"message":"invalid refresh token"}`))
+			return
+		}
+		f.issued++
+		f.valid = fmt.Sprintf("refresh-%d", f.issued)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"code":    200,
+			"message": "success",
+			"data": map[string]string{
+				"access_token":  fmt.Sprintf("access-%d", f.issued),
+				"refresh_token": f.valid,
+				"expires_at":    time.Now().Add(f.expiresIn).Format(time.RFC3339),
+			},
+		})
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeIsme) source(store Store) *TokenSource {
+	return New(Config{Endpoint: f.server.URL, RefreshBefore: time.Minute}, store)
+}
+
+func expiring(refreshToken string, in time.Duration) *Token {
+	return &Token{AccessToken: "access-0", RefreshToken: refreshToken, ExpiresAt: time.Now().Add(in)}
+}
+
+func TestTokenServesFreshTokenWithoutRefresh(t *testing.T) {
+	f := newFakeIsme(t)
+	ts := f.source(nil)
+	if err := ts.Set(context.Background(), expiring("refresh-0", time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	access, err := ts.AccessToken(context.Background())
+	if err != nil || access != "access-0" {
+		t.Fatalf("AccessToken = %q, %v", access, err)
+	}
+	if n := f.refreshes.Load(); n != 0 {
+		t.Errorf("refreshed %d times, want 0", n)
+	}
+}
+
+func TestTokenRefreshesBeforeExpiryAndPersists(t *testing.T) {
+	f := newFakeIsme(t)
+	store := NewMemoryStore()
+	ts := f.source(store)
+	// inside the RefreshBefore window, not yet expired
+	if err := ts.Set(context.Background(), expiring("refresh-0", 30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token.AccessToken != "access-1" || token.RefreshToken != "refresh-1" {
+		t.Errorf("token = %+v, want the rotated pair", token)
+	}
+	stored, _ := store.Load(context.Background())
+	if stored == nil || stored.RefreshToken != "refresh-1" {
+		t.Errorf("stored = %+v, want the rotated pair persisted", stored)
+	}
+}
+
+func TestTokenDeduplicatesConcurrentRefreshes(t *testing.T) {
+	f := newFakeIsme(t)
+	ts := f.source(nil)
+	if err := ts.Set(context.Background(), expiring("refresh-0", -time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if access, err := ts.AccessToken(context.Background()); err != nil || access != "access-1" {
+				t.Errorf("AccessToken = %q, %v", access, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := f.refreshes.Load(); n != 1 {
+		t.Errorf("refreshed %d times, want once", n)
+	}
+}
+
+func TestTokenSessionRevoked(t *testing.T) {
+	f := newFakeIsme(t)
+	store := NewMemoryStore()
+	ts := f.source(store)
+	if err := ts.Set(context.Background(), expiring("revoked", -time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ts.Token(context.Background()); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("err = %v, want ErrSessionRevoked", err)
+	}
+	if stored, _ := store.Load(context.Background()); stored != nil {
+		t.Errorf("revoked pair still stored: %+v", stored)
+	}
+	if _, err := ts.Token(context.Background()); !errors.Is(err, ErrNoToken) {
+		t.Errorf("after revocation err = %v, want ErrNoToken", err)
+	}
+}
+
+func TestTokenAdoptsPairRotatedByAnotherInstance(t *testing.T) {
+	f := newFakeIsme(t)
+	store := NewMemoryStore()
+	a, b := f.source(store), f.source(store)
+	if err := a.Set(context.Background(), expiring("refresh-0", -time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	// b caches the soon-spent pair before a rotates it
+	if _, err := b.current(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Token(context.Background()); err != nil {
+		t.Fatalf("a: %v", err)
+	}
+	token, err := b.Token(context.Background())
+	if err != nil {
+		t.Fatalf("b: %v", err)
+	}
+	if token.RefreshToken != "refresh-1" {
+		t.Errorf("b token = %+v, want the pair a stored", token)
+	}
+}
+
+func TestTokenOutage(t *testing.T) {
+	f := newFakeIsme(t)
+	f.down = true
+	ts := f.source(nil)
+	if err := ts.Set(context.Background(), expiring("refresh-0", 30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	// still valid: served despite the failed refresh
+	token, err := ts.Token(context.Background())
+	if err != nil || token.AccessToken != "access-0" {
+		t.Fatalf("Token = %+v, %v; want the current token", token, err)
+	}
+
+	ts.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if _, err := ts.Token(context.Background()); err == nil || errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("err = %v, want a transient error once expired", err)
+	}
+}
+
+func TestTokenWithoutLogin(t *testing.T) {
+	ts := New(Config{Endpoint: "http://127.0.0.1:0"}, nil)
+	if _, err := ts.Token(context.Background()); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("err = %v, want ErrNoToken", err)
+	}
+}
@@ -0,0 +1,154 @@
+package verifier
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vukyn/isme/external/auth/models"
+	"github.com/vukyn/isme/external/internal/rest"
+
+	"github.com/vukyn/kuery/log"
+)
+
+var errRefreshThrottled = errors.New("key refresh throttled")
+
+// keySet caches isme's verification keys. Reads share an RWMutex; fetches are
+// serialized on fetchMu so a burst of requests arriving with a cold or expired
+// cache makes one request to isme, not one each.
+type keySet struct {
+	url           string
+	ttl           time.Duration
+	minRefresh    time.Duration
+	timeout       time.Duration
+	transport     http.RoundTripper
+	now           func() time.Time
+	mu            sync.RWMutex
+	keys          map[string]*rsa.PublicKey
+	fetchedAt     time.Time
+	fetchMu       sync.Mutex
+	lastAttemptAt time.Time
+}
+
+func newKeySet(url string, cfg Config, now func() time.Time) *keySet {
+	return &keySet{
+		url:        url,
+		ttl:        cfg.KeyCacheTTL,
+		minRefresh: cfg.MinKeyRefresh,
+		timeout:    cfg.Timeout,
+		transport:  cfg.Transport,
+		now:        now,
+	}
+}
+
+// get returns the keys to try for kid (every key when the token names none)
+// and the fetch time they came from. An expired set is refreshed first; if
+// that fails the stale keys are still used, so an isme blip does not reject
+// every request.
+func (k *keySet) get(ctx context.Context, kid string) ([]*rsa.PublicKey, time.Time, error) {
+	k.mu.RLock()
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+
+	if fetchedAt.IsZero() || k.now().Sub(fetchedAt) > k.ttl {
+		if err := k.refresh(ctx, fetchedAt); err != nil && fetchedAt.IsZero() {
+			return nil, time.Time{}, fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+		}
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if kid != "" {
+		if key, ok := k.keys[kid]; ok {
+			return []*rsa.PublicKey{key}, k.fetchedAt, nil
+		}
+		return nil, k.fetchedAt, nil
+	}
+	keys := make([]*rsa.PublicKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	return keys, k.fetchedAt, nil
+}
+
+// refresh refetches the set unless another caller already did since seen, or
+// the last attempt was under minRefresh ago.
+func (k *keySet) refresh(ctx context.Context, seen time.Time) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	k.mu.RLock()
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+	if fetchedAt.After(seen) {
+		return nil
+	}
+	now := k.now()
+	if !k.lastAttemptAt.IsZero() && now.Sub(k.lastAttemptAt) < k.minRefresh {
+		return errRefreshThrottled
+	}
+	k.lastAttemptAt = now
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		log.New().Errorf("Error fetch verification keys from external auth: %v", err)
+		return err
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = now
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *keySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	jwks := &models.JWKS{}
+	resp, err := rest.New(ctx, k.transport).SetTimeout(k.timeout).R().
+		SetResult(jwks).
+		ForceContentType("application/json"). // CDNs and proxies often drop it
+		Get(k.url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode())
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set has no RSA signing key")
+	}
+	return keys, nil
+}
+
+func parseRSAKey(jwk models.JWK) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,46 @@
+package verifier
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Principal is the verified identity behind a request.
+type Principal struct {
+	UserID    string
+	Email     string
+	TokenID   string
+	Audience  []string
+	ExpiresAt time.Time
+	// Permissions are the "resource:action" codes granted for the verifier's
+	// AppCode; ResourceAccess holds every app's grants the token carries.
+	Permissions    []string
+	ResourceAccess map[string][]string
+}
+
+func (p *Principal) HasPermission(perm string) bool {
+	return p != nil && slices.Contains(p.Permissions, perm)
+}
+
+func (p *Principal) HasAnyPermission(perms ...string) bool {
+	for _, perm := range perms {
+		if p.HasPermission(perm) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by the middleware; ok is false on
+// a route it did not guard.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,235 @@
+// Package verifier validates isme access tokens locally: the RS256 signature
+// against keys fetched (and cached) from isme's JWKS endpoint, the expiry, and
+// the audience against the calling app's code. It is the token check behind
+// the external/auth/middleware handlers.
+//
+// Local validation cannot see a session revoked at isme before the token
+// expires; keep AUTH_ACCESS_TOKEN_EXPIRE_IN short, or call services.GetMe for
+// the few operations that must observe a logout immediately.
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/external/auth/constants"
+)
+
+var (
+	ErrMissingToken     = errors.New("missing bearer token")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrExpiredToken     = errors.New("token expired")
+	ErrAudienceMismatch = errors.New("token not issued for this app")
+	// ErrKeysUnavailable means the token could not be checked at all: isme's
+	// key set was never fetched successfully. It is a server-side failure, not
+	// a bad token.
+	ErrKeysUnavailable = errors.New("verification keys unavailable")
+)
+
+const (
+	DEFAULT_KEY_CACHE_TTL          = 15 * time.Minute
+	DEFAULT_MIN_KEY_REFRESH        = 30 * time.Second
+	DEFAULT_LEEWAY                 = 30 * time.Second
+	algorithmRS256                 = "RS256"
+	resourceAccessPermissionsField = "perms"
+)
+
+type Config struct {
+	// Endpoint is the isme API base, the same value passed to
+	// services.NewService (e.g. https://isme.example.com/api/v1).
+	Endpoint string
+	// AppCode is the calling app's code; tokens whose aud does not include it
+	// are rejected, and Principal.Permissions holds its resource_access perms.
+	AppCode string
+	// KeyCacheTTL is how long a fetched key set is used before it is refetched.
+	KeyCacheTTL time.Duration
+	// MinKeyRefresh throttles the refetch triggered by a token signed with an
+	// unknown key, so a flood of forged tokens cannot hammer isme.
+	MinKeyRefresh time.Duration
+	// Leeway tolerates clock skew between isme and this service on exp/nbf.
+	Leeway time.Duration
+	// Timeout bounds one key fetch.
+	Timeout time.Duration
+	// Transport, when set, sends the key fetches; pass an instrumented one
+	// (e.g. otelhttp.NewTransport) to carry the caller's trace to isme.
+	Transport http.RoundTripper
+}
+
+type Verifier struct {
+	cfg  Config
+	keys *keySet
+	now  func() time.Time
+}
+
+// New returns a verifier for cfg; zero durations take the DEFAULT_* values. No
+// request is made until the first token is verified.
+func New(cfg Config) *Verifier {
+	if cfg.KeyCacheTTL <= 0 {
+		cfg.KeyCacheTTL = DEFAULT_KEY_CACHE_TTL
+	}
+	if cfg.MinKeyRefresh <= 0 {
+		cfg.MinKeyRefresh = DEFAULT_MIN_KEY_REFRESH
+	}
+	if cfg.Leeway <= 0 {
+		cfg.Leeway = DEFAULT_LEEWAY
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = constants.DEFAULT_TIMEOUT
+	}
+	v := &Verifier{cfg: cfg, now: time.Now}
+	v.keys = newKeySet(strings.TrimRight(cfg.Endpoint, "/")+constants.API_AUTH_JWKS, cfg, func() time.Time { return v.now() })
+	return v
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type claims struct {
+	TokenID        string                         `json:"jti"`
+	UserID         string                         `json:"uid"`
+	Email          string                         `json:"email"`
+	ExpiresAt      numericDate                    `json:"exp"`
+	NotBefore      numericDate                    `json:"nbf"`
+	Audience       audience                       `json:"aud"`
+	ResourceAccess map[string]map[string][]string `json:"resource_access"`
+}
+
+// Verify checks token and returns who it was issued to. The error wraps one of
+// the Err* values, so callers can tell a bad token (ErrInvalidToken and
+// friends) from an outage (ErrKeysUnavailable) with errors.Is.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	// pinning the algorithm rules out alg=none and HS256-with-the-public-key
+	if h.Alg != algorithmRS256 {
+		return nil, fmt.Errorf("%w: unexpected alg %q", ErrInvalidToken, h.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	if err := v.verifySignature(ctx, h.Kid, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	now := v.now()
+	if c.ExpiresAt.IsZero() || now.After(c.ExpiresAt.Add(v.cfg.Leeway)) {
+		return nil, ErrExpiredToken
+	}
+	if !c.NotBefore.IsZero() && now.Add(v.cfg.Leeway).Before(c.NotBefore.Time) {
+		return nil, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if !c.Audience.contains(v.cfg.AppCode) {
+		return nil, ErrAudienceMismatch
+	}
+
+	resourceAccess := make(map[string][]string, len(c.ResourceAccess))
+	for app, access := range c.ResourceAccess {
+		resourceAccess[app] = access[resourceAccessPermissionsField]
+	}
+	return &Principal{
+		UserID:         c.UserID,
+		Email:          c.Email,
+		TokenID:        c.TokenID,
+		Audience:       c.Audience,
+		ExpiresAt:      c.ExpiresAt.Time,
+		Permissions:    resourceAccess[v.cfg.AppCode],
+		ResourceAccess: resourceAccess,
+	}, nil
+}
+
+// verifySignature tries the cached keys first. A miss (unknown kid, or no key
+// matching) refetches the set once, throttled, to pick up a rotated key.
+func (v *Verifier) verifySignature(ctx context.Context, kid string, digest, signature []byte) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		keys, fetchedAt, err := v.keys.get(ctx, kid)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil {
+				return nil
+			}
+		}
+		if attempt == 0 && v.keys.refresh(ctx, fetchedAt) != nil {
+			break
+		}
+	}
+	return fmt.Errorf("%w: signature", ErrInvalidToken)
+}
+
+func decodeSegment(segment string, out any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// numericDate is a JWT NumericDate: seconds since the epoch, possibly
+// fractional.
+type numericDate struct{ time.Time }
+
+func (d *numericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	whole, frac := math.Modf(seconds)
+	d.Time = time.Unix(int64(whole), int64(frac*1e9))
+	return nil
+}
+
+// audience accepts aud as a single string or an array, both valid per RFC 7519.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(appCode string) bool {
+	for _, aud := range a {
+		if aud == appCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/external/auth/constants"
+	"github.com/vukyn/isme/external/auth/models"
+)
+
+type issuer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	key     *rsa.PrivateKey
+	kid     string
+	fetches atomic.Int32
+	server  *httptest.Server
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+	iss := &issuer{t: t}
+	iss.rotate("key-1")
+	iss.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1"+constants.API_AUTH_JWKS {
+			http.NotFound(w, r)
+			return
+		}
+		iss.fetches.Add(1)
+		iss.mu.Lock()
+		defer iss.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(models.JWKS{Keys: []models.JWK{{
+			Kty: "RSA", Use: "sig", Alg: "RS256", Kid: iss.kid,
+			N: base64.RawURLEncoding.EncodeToString(iss.key.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(iss.key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(iss.server.Close)
+	return iss
+}
+
+func (iss *issuer) rotate(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		iss.t.Fatalf("generate key: %v", err)
+	}
+	iss.mu.Lock()
+	iss.key, iss.kid = key, kid
+	iss.mu.Unlock()
+}
+
+func (iss *issuer) verifier(appCode string) *Verifier {
+	return New(Config{Endpoint: iss.server.URL + "/api/v1", AppCode: appCode})
+}
+
+// sign mints a token the way isme does: RS256 over kuery/claims keys.
+func (iss *issuer) sign(header map[string]any, claims map[string]any) string {
+	iss.t.Helper()
+	segment := func(v any) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			iss.t.Fatalf("marshal: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	input := segment(header) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(input))
+	iss.mu.Lock()
+	signature, err := rsa.SignPKCS1v15(rand.Reader, iss.key, crypto.SHA256, digest[:])
+	iss.mu.Unlock()
+	if err != nil {
+		iss.t.Fatalf("sign: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func accessClaims(aud any, exp time.Time) map[string]any {
+	return map[string]any{
+		"jti":   "token-1",
+		"uid":   "user-1",
+		"email": "ada@example.com",
+		"exp":   exp.Unix(),
+		"aud":   aud,
+		"resource_access": map[string]any{
+			"medioa": map[string]any{"perms": []string{"file:read", "file:upload"}},
+			"rainy":  map[string]any{"perms": []string{"forecast:read"}},
+		},
+	}
+}
+
+var rs256 = map[string]any{"alg": "RS256", "typ": "JWT"}
+
+func TestVerifyValidToken(t *testing.T) {
+	iss := newIssuer(t)
+	v := iss.verifier("medioa")
+
+	principal, err := v.Verify(context.Background(), iss.sign(rs256, accessClaims([]string{"medioa", "rainy"}, time.Now().Add(time.Hour))))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if principal.UserID != "user-1" || principal.Email != "ada@example.com" || principal.TokenID != "token-1" {
+		t.Errorf("principal = %+v", principal)
+	}
+	if !principal.HasPermission("file:upload") || principal.HasPermission("forecast:read") {
+		t.Errorf("Permissions = %v, want only the medioa grant", principal.Permissions)
+	}
+	if got := principal.ResourceAccess["rainy"]; len(got) != 1 {
+		t.Errorf("ResourceAccess = %v", principal.ResourceAccess)
+	}
+
+	// a string aud is as valid as an array
+	if _, err := v.Verify(context.Background(), iss.sign(rs256, accessClaims("medioa", time.Now().Add(time.Hour)))); err != nil {
+		t.Errorf("string aud: %v", err)
+	}
+	if n := iss.fetches.Load(); n != 1 {
+		t.Errorf("fetched keys %d times, want once", n)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	iss := newIssuer(t)
+	v := iss.verifier("medioa")
+	valid := accessClaims([]string{"medioa"}, time.Now().Add(time.Hour))
+
+	forged := iss.sign(rs256, valid)
+	forged = forged[:len(forged)-4] + "AAAA"
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"missing", "", ErrMissingToken},
+		{"malformed", "not-a-jwt", ErrInvalidToken},
+		{"other audience", iss.sign(rs256, accessClaims([]string{"rainy"}, time.Now().Add(time.Hour))), ErrAudienceMismatch},
+		{"expired", iss.sign(rs256, accessClaims([]string{"medioa"}, time.Now().Add(-time.Hour))), ErrExpiredToken},
+		{"alg none", iss.sign(map[string]any{"alg": "none"}, valid), ErrInvalidToken},
+		{"alg HS256", iss.sign(map[string]any{"alg": "HS256"}, valid), ErrInvalidToken},
+		{"bad signature", forged, ErrInvalidToken},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := v.Verify(context.Background(), tc.token); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPicksUpRotatedKey(t *testing.T) {
+	iss := newIssuer(t)
+	v := iss.verifier("medioa")
+	v.keys.minRefresh = 0
+	claims := accessClaims([]string{"medioa"}, time.Now().Add(time.Hour))
+
+	if _, err := v.Verify(context.Background(), iss.sign(rs256, claims)); err != nil {
+		t.Fatalf("before rotation: %v", err)
+	}
+	iss.rotate("key-2")
+	if _, err := v.Verify(context.Background(), iss.sign(map[string]any{"alg": "RS256", "kid": "key-2"}, claims)); err != nil {
+		t.Fatalf("after rotation: %v", err)
+	}
+	if n := iss.fetches.Load(); n != 2 {
+		t.Errorf("fetched keys %d times, want 2", n)
+	}
+}
+
+func TestVerifyThrottlesRefetchOnUnknownKey(t *testing.T) {
+	iss := newIssuer(t)
+	v := iss.verifier("medioa")
+	claims := accessClaims([]string{"medioa"}, time.Now().Add(time.Hour))
+
+	// a stranger's key: every token misses the cache
+	stranger := &issuer{t: t}
+	stranger.rotate("key-x")
+	for i := 0; i < 5; i++ {
+		if _, err := v.Verify(context.Background(), stranger.sign(rs256, claims)); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("err = %v, want invalid", err)
+		}
+	}
+	if n := iss.fetches.Load(); n != 1 {
+		t.Errorf("fetched keys %d times, want once within MinKeyRefresh", n)
+	}
+}
+
+func TestVerifyKeysUnavailable(t *testing.T) {
+	iss := newIssuer(t)
+	token := iss.sign(rs256, accessClaims([]string{"medioa"}, time.Now().Add(time.Hour)))
+	v := New(Config{Endpoint: iss.server.URL + "/missing", AppCode: "medioa", Timeout: time.Second})
+
+	if _, err := v.Verify(context.Background(), token); !errors.Is(err, ErrKeysUnavailable) {
+		t.Fatalf("err = %v, want ErrKeysUnavailable", err)
+	}
+}
+
+func TestVerifyConcurrentColdCacheFetchesOnce(t *testing.T) {
+	iss := newIssuer(t)
+	v := iss.verifier("medioa")
+	token := iss.sign(rs256, accessClaims([]string{"medioa"}, time.Now().Add(time.Hour)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := v.Verify(context.Background(), token); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := iss.fetches.Load(); n != 1 {
+		t.Errorf("fetched keys %d times, want once", n)
+	}
+}
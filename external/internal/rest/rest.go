@@ -0,0 +1,24 @@
+// Package rest builds the resty clients the external packages call isme with.
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// New returns a client for calls made from ctx. Every request carries ctx, so
+// a transport that reads the trace from the request context (such as
+// otelhttp.NewTransport) sends the caller's trace on to isme. A nil transport
+// keeps resty's default.
+func New(ctx context.Context, transport http.RoundTripper) *resty.Client {
+	client := resty.New().OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+		r.SetContext(ctx)
+		return nil
+	})
+	if transport != nil {
+		client.SetTransport(transport)
+	}
+	return client
+}
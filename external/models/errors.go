@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrServer       = errors.New("server error")
+)
+
+// APIError is a non-2xx answer from isme, decoded from the {code, message}
+// envelope kuery handlers write. errors.Is matches it against the Err* values
+// by status, so callers branch on the kind of failure without parsing
+// messages:
+//
+//	if errors.Is(err, models.ErrConflict) { ... }
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+// NewAPIError decodes body; a body that is not the envelope (a proxy's error
+// page, say) becomes the message as is.
+func NewAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Code: statusCode}
+	var envelope struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Message != "" {
+		apiErr.Message = envelope.Message
+		if envelope.Code != 0 {
+			apiErr.Code = envelope.Code
+		}
+		return apiErr
+	}
+	apiErr.Message = strings.TrimSpace(string(body))
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("isme: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
@@ -2,12 +2,17 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/vukyn/kuery/log"
 	pkgScheduler "github.com/vukyn/kuery/scheduler"
 
+	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
 	"github.com/vukyn/isme/internal/config"
 	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/health"
+	"github.com/vukyn/isme/internal/migration"
 
 	"github.com/sarulabs/di/v2"
 )
@@ -36,12 +41,32 @@ func Init() {
 	}
 	log.New().Info("Logger initialized")
 
-	// Force database initialization by accessing it
-	_ = idi.GetDB(app)
+	// Force database initialization by accessing it, then refuse to serve a
+	// schema behind the code unless explicitly allowed
+	db := idi.GetDB(app)
+	pending, err := migration.Pending(context.Background(), db, sqliteHistory.Migrations)
+	if err != nil {
+		log.New().Fatal("Failed to read applied migrations", err)
+	}
+	if len(pending) > 0 {
+		if !Config.DB.AllowPendingMigrations {
+			log.New().Fatal("Refusing to serve a database behind this build", fmt.Errorf(
+				"%d pending migration(s): %s; run `make migrate-up DB=%s` or set DB_ALLOW_PENDING_MIGRATIONS=true",
+				len(pending), strings.Join(pending, ", "), Config.DB.Driver))
+		}
+		log.New().Warnf("Serving with %d pending migration(s) (DB_ALLOW_PENDING_MIGRATIONS): %s", len(pending), strings.Join(pending, ", "))
+	}
 
-	// Force scheduler singleton construction (built from the App-scoped DB)
-	Scheduler = idi.GetScheduler(app)
+	// Force scheduler singleton construction (built from the App-scoped DB);
+	// wrapped so /readyz knows whether it is running
+	Scheduler = health.Scheduler.Wrap(idi.GetScheduler(app))
 
 	// Force schedule-provider singleton construction (the engine's initial-load path)
 	ScheduleProvider = idi.GetScheduleProvider(app)
+
+	// Register the scrape-time gauges served on /metrics
+	_ = idi.GetMetrics(app)
+
+	// Install the tracer before the server starts handling requests
+	_ = idi.GetTracer(app)
 }
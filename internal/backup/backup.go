@@ -0,0 +1,403 @@
+// Package backup inspects and restores the snapshots the database_backup
+// scheduled job writes to db/backups/ — SQLite files, or logical dumps of a
+// Postgres database — and copies them to and from the optional off-host
+// Store. Restoring is an offline operation: it refuses to run while any
+// process holds the database (see HoldShared and HoldSharedDB), so it is
+// driven from cmd/restore with the server stopped.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// Backup file name patterns. The job names its snapshots app-<timestamp>.db;
+// restore names the safety snapshot of the database it replaces
+// pre-restore-<timestamp>.db so the job's retention pruning never touches it.
+// On Postgres both are dumps with the .dump extension instead (see Dump).
+// Either may carry EncryptedSuffix when a backup key is configured.
+const (
+	BackupPattern     = "app-*.db"
+	SafetyPattern     = "pre-restore-*.db"
+	safetyPrefix      = "pre-restore-"
+	timestampLayout   = "20060102-150405"
+	integrityCheckOK  = "ok"
+	migrationsTable   = "migrations"
+	maxIntegrityLines = 20
+)
+
+// coreTables must exist in a snapshot before it may replace the database; a
+// file without them is not an isme database, however intact.
+var coreTables = []string{migrationsTable, "users", "app_services", "schedule_config"}
+
+var (
+	// ErrIntegrity is returned when a snapshot fails PRAGMA integrity_check or
+	// lacks the core isme tables.
+	ErrIntegrity = errors.New("backup failed integrity check")
+	// ErrInUse is returned when another process holds the database.
+	ErrInUse = errors.New("database is in use; stop the server first")
+)
+
+// Backup is one snapshot file in the backup directory.
+type Backup struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// Version is the last migration applied in the snapshot, or empty when
+	// the file could not be read.
+	Version string `json:"version"`
+	// Err explains an empty Version.
+	Err string `json:"error,omitempty"`
+	// Remote marks a copy in the off-host store; its Path is the object key.
+	Remote bool `json:"remote"`
+	// Encrypted marks a snapshot sealed with the backup key KeyID; its
+	// Version comes from the manifest.
+	Encrypted bool   `json:"encrypted"`
+	KeyID     string `json:"key_id,omitempty"`
+}
+
+// Inspection is what Inspect learned about a snapshot.
+type Inspection struct {
+	// Integrity holds the PRAGMA integrity_check output: a single "ok" for a
+	// healthy file, otherwise up to maxIntegrityLines problems.
+	Integrity     []string `json:"integrity"`
+	MissingTables []string `json:"missing_tables,omitempty"`
+	// Applied lists the applied migration names in execution order.
+	Applied []string `json:"applied"`
+}
+
+// OK reports whether the snapshot is intact and is an isme database.
+func (i Inspection) OK() bool {
+	return len(i.Integrity) == 1 && i.Integrity[0] == integrityCheckOK && len(i.MissingTables) == 0
+}
+
+// Version returns the last applied migration name.
+func (i Inspection) Version() string {
+	if len(i.Applied) == 0 {
+		return ""
+	}
+	return i.Applied[len(i.Applied)-1]
+}
+
+// List returns the job's snapshots and earlier safety snapshots in dir,
+// newest first. A missing dir is an empty list. Each file is opened read-only
+// to report its migration version; an unreadable file is still listed.
+func List(ctx context.Context, dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]Backup, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !isBackupName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backup := Backup{
+			Name:    entry.Name(),
+			Path:    filepath.Join(dir, entry.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		if IsEncrypted(backup.Name) {
+			backup.Encrypted = true
+			if manifest, err := ReadManifest(backup.Path + ManifestSuffix); err != nil {
+				backup.Err = err.Error()
+			} else {
+				backup.KeyID, backup.Version = manifest.KeyID, manifest.Migration
+			}
+		} else if IsDump(backup.Name) {
+			if header, err := ReadDumpHeader(backup.Path); err != nil {
+				backup.Err = err.Error()
+			} else {
+				backup.Version = header.Migration
+			}
+		} else if applied, err := appliedMigrations(ctx, backup.Path); err != nil {
+			backup.Err = err.Error()
+		} else if len(applied) > 0 {
+			backup.Version = applied[len(applied)-1]
+		}
+		backups = append(backups, backup)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ModTime.After(backups[j].ModTime) })
+	return backups, nil
+}
+
+// Inspect opens the snapshot at path read-only and runs PRAGMA
+// integrity_check, the core table check and reads its migration history.
+func Inspect(ctx context.Context, path string) (Inspection, error) {
+	db, err := openReadOnly(path)
+	if err != nil {
+		return Inspection{}, err
+	}
+	defer db.Close()
+
+	inspection := Inspection{}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA integrity_check(%d)", maxIntegrityLines))
+	if err != nil {
+		return Inspection{}, fmt.Errorf("integrity check: %w", err)
+	}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			rows.Close()
+			return Inspection{}, fmt.Errorf("integrity check: %w", err)
+		}
+		inspection.Integrity = append(inspection.Integrity, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Inspection{}, fmt.Errorf("integrity check: %w", err)
+	}
+
+	for _, table := range coreTables {
+		var name string
+		err := db.QueryRowContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name)
+		if errors.Is(err, sql.ErrNoRows) {
+			inspection.MissingTables = append(inspection.MissingTables, table)
+			continue
+		}
+		if err != nil {
+			return Inspection{}, err
+		}
+	}
+
+	if !slices.Contains(inspection.MissingTables, migrationsTable) {
+		if inspection.Applied, err = queryApplied(ctx, db); err != nil {
+			return Inspection{}, err
+		}
+	}
+	return inspection, nil
+}
+
+// Delta compares the migrations applied in a snapshot with the names the
+// running code knows. Pending are known but not applied — the next
+// `migrate up` runs them. Unknown are applied but not known, which means the
+// snapshot was taken by a newer build than this one.
+type Delta struct {
+	Pending []string `json:"pending"`
+	Unknown []string `json:"unknown"`
+}
+
+// Current reports whether the snapshot matches the code's migration set.
+func (d Delta) Current() bool {
+	return len(d.Pending) == 0 && len(d.Unknown) == 0
+}
+
+// SchemaDelta computes the Delta between applied and known migration names.
+func SchemaDelta(applied, known []string) Delta {
+	delta := Delta{Pending: []string{}, Unknown: []string{}}
+	for _, name := range known {
+		if !slices.Contains(applied, name) {
+			delta.Pending = append(delta.Pending, name)
+		}
+	}
+	for _, name := range applied {
+		if !slices.Contains(known, name) {
+			delta.Unknown = append(delta.Unknown, name)
+		}
+	}
+	return delta
+}
+
+// Result describes a completed restore.
+type Result struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Snapshot is the safety snapshot of the replaced database, empty when
+	// there was no database to replace.
+	Snapshot   string     `json:"snapshot,omitempty"`
+	Inspection Inspection `json:"inspection"`
+}
+
+// Restore replaces the SQLite database at target with the snapshot at src.
+// It checks src first and never touches target when the check fails, then
+// takes the exclusive lock (ErrInUse while the server or another tool holds
+// the database), snapshots the current database into snapshotDir via VACUUM
+// INTO, and swaps src in with a rename so target is never half-written. The
+// stale -wal/-shm files of the replaced database are removed, as SQLite would
+// otherwise replay them onto the restored file.
+//
+// An encrypted src is verified against its manifest and decrypted next to
+// target before the check. With keys set the safety snapshot is sealed too,
+// so a restore never leaves a plaintext copy behind.
+func Restore(ctx context.Context, src, target, snapshotDir string, keys *Keyring, now time.Time) (Result, error) {
+	result := Result{Source: src, Target: target}
+
+	if IsEncrypted(src) {
+		plain := target + ".decrypted"
+		os.Remove(plain)
+		if _, err := keys.Open(src, plain); err != nil {
+			return result, err
+		}
+		defer os.Remove(plain)
+		src = plain
+	}
+
+	inspection, err := Inspect(ctx, src)
+	if err != nil {
+		return result, err
+	}
+	result.Inspection = inspection
+	if !inspection.OK() {
+		return result, fmt.Errorf("%w: %s", ErrIntegrity, describeFailure(inspection))
+	}
+
+	lock, err := TryExclusive(target)
+	if err != nil {
+		return result, err
+	}
+	defer lock.Close()
+
+	if _, err := os.Stat(target); err == nil {
+		if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+			return result, fmt.Errorf("create snapshot dir: %w", err)
+		}
+		snapshot := filepath.Join(snapshotDir, safetyPrefix+now.UTC().Format(timestampLayout)+".db")
+		if err := vacuumInto(ctx, target, snapshot); err != nil {
+			return result, fmt.Errorf("snapshot current database: %w", err)
+		}
+		if keys != nil {
+			sealed, _, err := keys.Seal(ctx, snapshot, now)
+			if err != nil {
+				os.Remove(snapshot)
+				return result, fmt.Errorf("encrypt snapshot of current database: %w", err)
+			}
+			snapshot = sealed
+		}
+		result.Snapshot = snapshot
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+
+	tmp := target + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return result, fmt.Errorf("stage backup: %w", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(target + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return result, fmt.Errorf("remove stale %s: %w", suffix, err)
+		}
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return result, fmt.Errorf("swap in backup: %w", err)
+	}
+	return result, nil
+}
+
+func isBackupName(name string) bool {
+	return IsJobSnapshot(name) || MatchSnapshot(SafetyPattern, name) || MatchSnapshot(SafetyDumpPattern, name)
+}
+
+// MatchSnapshot reports whether name is a snapshot named like pattern, in the
+// clear or encrypted.
+func MatchSnapshot(pattern, name string) bool {
+	name = strings.TrimSuffix(name, EncryptedSuffix)
+	matched, _ := filepath.Match(pattern, name)
+	return matched
+}
+
+func describeFailure(inspection Inspection) string {
+	if len(inspection.MissingTables) > 0 {
+		return "missing tables " + strings.Join(inspection.MissingTables, ", ")
+	}
+	return strings.Join(inspection.Integrity, "; ")
+}
+
+// openReadOnly opens path without creating it and without write access, so
+// inspecting a snapshot can never modify it.
+func openReadOnly(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return sql.Open(sqliteshim.ShimName, "file:"+path+"?mode=ro")
+}
+
+func appliedMigrations(ctx context.Context, path string) ([]string, error) {
+	db, err := openReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return queryApplied(ctx, db)
+}
+
+func queryApplied(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM `+migrationsTable+` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("read migrations: %w", err)
+		}
+		applied = append(applied, name)
+	}
+	return applied, rows.Err()
+}
+
+// vacuumInto writes a consistent copy of the database at path to target,
+// folding in any committed pages still in its WAL.
+func vacuumInto(ctx context.Context, path, target string) error {
+	db, err := sql.Open(sqliteshim.ShimName, path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// Same fallback as the backup job: target is generated here, never user
+	// input, so inlining it is injection-safe.
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", target); err != nil {
+		if _, err := db.ExecContext(ctx, "VACUUM INTO '"+target+"'"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies src to dst and syncs it, so the rename that follows never
+// exposes a file whose contents are still in flight.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := out.ReadFrom(in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,253 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// writeDB creates a file-backed SQLite database at path with the core tables
+// and the given migrations recorded as applied.
+func writeDB(t *testing.T, path string, applied ...string) {
+	t.Helper()
+	db, err := sql.Open(sqliteshim.ShimName, path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE migrations (id INTEGER PRIMARY KEY, name TEXT UNIQUE, executed_at DATETIME)`,
+		`CREATE TABLE users (id TEXT PRIMARY KEY, email TEXT)`,
+		`CREATE TABLE app_services (id TEXT PRIMARY KEY)`,
+		`CREATE TABLE schedule_config (job_key TEXT PRIMARY KEY)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	for i, name := range applied {
+		if _, err := db.Exec(`INSERT INTO migrations (id, name) VALUES (?, ?)`, i+1, name); err != nil {
+			t.Fatalf("stamp %s: %v", name, err)
+		}
+	}
+}
+
+func userCount(t *testing.T, path string) int {
+	t.Helper()
+	db, err := sql.Open(sqliteshim.ShimName, path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+		t.Fatalf("count users: %v", err)
+	}
+	return n
+}
+
+func TestSchemaDelta(t *testing.T) {
+	delta := SchemaDelta([]string{"001_a", "002_b", "004_d"}, []string{"001_a", "002_b", "003_c"})
+	if !slices.Equal(delta.Pending, []string{"003_c"}) || !slices.Equal(delta.Unknown, []string{"004_d"}) {
+		t.Fatalf("delta = %+v, want pending [003_c] unknown [004_d]", delta)
+	}
+	if delta.Current() {
+		t.Fatal("delta with pending and unknown migrations reported current")
+	}
+	if !SchemaDelta([]string{"001_a"}, []string{"001_a"}).Current() {
+		t.Fatal("identical migration sets not reported current")
+	}
+}
+
+func TestListNewestFirstWithVersions(t *testing.T) {
+	dir := t.TempDir()
+	writeDB(t, filepath.Join(dir, "app-20260101-000000.db"), "001_a")
+	writeDB(t, filepath.Join(dir, "app-20260102-000000.db"), "001_a", "002_b")
+	if err := os.WriteFile(filepath.Join(dir, "pre-restore-20260103-000000.db"), []byte("not sqlite"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"app-20260101-000000.db", "app-20260102-000000.db", "pre-restore-20260103-000000.db"} {
+		stamp := base.Add(time.Duration(i) * 24 * time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, name), stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := List(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("got %d backups, want 3: %+v", len(backups), backups)
+	}
+	if backups[0].Name != "pre-restore-20260103-000000.db" || backups[0].Err == "" {
+		t.Fatalf("newest = %+v, want the unreadable safety snapshot with an error", backups[0])
+	}
+	if backups[1].Version != "002_b" || backups[2].Version != "001_a" {
+		t.Fatalf("versions = %q, %q, want 002_b, 001_a", backups[1].Version, backups[2].Version)
+	}
+
+	missing, err := List(context.Background(), filepath.Join(dir, "missing"))
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("List(missing dir) = %v, %v, want empty", missing, err)
+	}
+}
+
+func TestRestoreSwapsAndSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+	target := filepath.Join(dir, "app.db")
+	src := filepath.Join(dir, "app-20260101-000000.db")
+
+	writeDB(t, target, "001_a", "002_b")
+	writeDB(t, src, "001_a")
+	db, err := sql.Open(sqliteshim.ShimName, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id, email) VALUES ('u1', 'a@example.com')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if err := os.WriteFile(target+"-wal", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	result, err := Restore(context.Background(), src, target, backupDir, nil, now)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := userCount(t, target); got != 1 {
+		t.Fatalf("restored users = %d, want 1", got)
+	}
+	if want := filepath.Join(backupDir, "pre-restore-20260201-120000.db"); result.Snapshot != want {
+		t.Fatalf("snapshot = %q, want %q", result.Snapshot, want)
+	}
+	if got := userCount(t, result.Snapshot); got != 0 {
+		t.Fatalf("snapshot users = %d, want 0", got)
+	}
+	if result.Inspection.Version() != "001_a" {
+		t.Fatalf("version = %q, want 001_a", result.Inspection.Version())
+	}
+	if _, err := os.Stat(target + "-wal"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("stale -wal not removed: %v", err)
+	}
+	if _, err := os.Stat(target + ".restore"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("staging file left behind: %v", err)
+	}
+}
+
+func TestRestoreRejectsNonIsmeDatabase(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "app.db")
+	src := filepath.Join(dir, "other.db")
+	writeDB(t, target, "001_a")
+
+	db, err := sql.Open(sqliteshim.ShimName, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE things (id INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	_, err = Restore(context.Background(), src, target, filepath.Join(dir, "backups"), nil, time.Now())
+	if !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("Restore error = %v, want ErrIntegrity", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "backups")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("a rejected restore took a safety snapshot")
+	}
+	applied, err := appliedMigrations(context.Background(), target)
+	if err != nil || !slices.Equal(applied, []string{"001_a"}) {
+		t.Fatalf("target changed after a rejected restore: %v, %v", applied, err)
+	}
+}
+
+func TestRestoreRefusesWhileDatabaseHeld(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("restore locking needs flock")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "app.db")
+	src := filepath.Join(dir, "app-20260101-000000.db")
+	writeDB(t, target, "001_a", "002_b")
+	writeDB(t, src, "001_a")
+
+	lock, err := HoldShared(target)
+	if err != nil {
+		t.Fatalf("HoldShared: %v", err)
+	}
+	if _, err := Restore(context.Background(), src, target, filepath.Join(dir, "backups"), nil, time.Now()); !errors.Is(err, ErrInUse) {
+		t.Fatalf("Restore while held = %v, want ErrInUse", err)
+	}
+	lock.Close()
+
+	if _, err := Restore(context.Background(), src, target, filepath.Join(dir, "backups"), nil, time.Now()); err != nil {
+		t.Fatalf("Restore after release: %v", err)
+	}
+}
+
+func TestRestoreEncryptedBackup(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+	target := filepath.Join(dir, "app.db")
+	writeDB(t, target, "001_a", "002_b")
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(backupDir, "app-20260101-000000.db")
+	writeDB(t, plain, "001_a")
+
+	keys := testKeyring(t)
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	sealed, manifest, err := keys.Seal(context.Background(), plain, now)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if manifest.Migration != "001_a" {
+		t.Fatalf("manifest migration = %q, want 001_a", manifest.Migration)
+	}
+
+	backups, err := List(context.Background(), backupDir)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("List = %+v, %v, want the sealed snapshot", backups, err)
+	}
+	if b := backups[0]; !b.Encrypted || b.KeyID != keys.KeyID() || b.Version != "001_a" {
+		t.Fatalf("listed %+v, want encrypted 001_a under the current key", b)
+	}
+
+	if _, err := Restore(context.Background(), sealed, target, backupDir, nil, now); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("Restore without a key = %v, want ErrNoKey", err)
+	}
+	result, err := Restore(context.Background(), sealed, target, backupDir, keys, now)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if result.Inspection.Version() != "001_a" {
+		t.Fatalf("version = %q, want 001_a", result.Inspection.Version())
+	}
+	if want := filepath.Join(backupDir, "pre-restore-20260201-120000.db.enc"); result.Snapshot != want {
+		t.Fatalf("snapshot = %q, want sealed %q", result.Snapshot, want)
+	}
+	for _, leftover := range []string{strings.TrimSuffix(result.Snapshot, EncryptedSuffix), target + ".decrypted"} {
+		if _, err := os.Stat(leftover); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("plaintext %s left behind: %v", leftover, err)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Encrypted snapshot layout: a header of magic, key ID and a random salt,
+// then the snapshot in chunks of up to chunkSize bytes, each sealed with
+// AES-256-GCM under a per-file key derived from the backup key and the salt
+// (HKDF-SHA256). A chunk's nonce is its index plus a final-chunk flag, and the
+// header is every chunk's additional data, so reordered, dropped, truncated or
+// re-headed chunks all fail to open.
+const (
+	EncryptedSuffix = ".enc"
+	ManifestSuffix  = ".json"
+
+	cipherName      = "AES-256-GCM-STREAM/HKDF-SHA256"
+	manifestVersion = 1
+	chunkSize       = 64 << 10
+	keyIDSize       = 8
+	saltSize        = 32
+	minKeySize      = 32
+	hkdfInfo        = "isme database backup v1"
+)
+
+var magic = []byte("ISMEBAK\x01")
+
+var (
+	// ErrUnknownKey is returned when a snapshot was encrypted with a key that
+	// is neither the current nor a retired backup key.
+	ErrUnknownKey = errors.New("backup was encrypted with an unknown key")
+	// ErrChecksum is returned when a snapshot does not match its manifest.
+	ErrChecksum = errors.New("backup does not match its manifest checksum")
+	// ErrNoKey is returned when an encrypted snapshot is restored without a
+	// backup key configured.
+	ErrNoKey = errors.New("backup is encrypted but no backup key is configured")
+)
+
+// Manifest is stored as <snapshot>.enc.json next to every encrypted snapshot.
+// It identifies the key without revealing it and carries the checksums the
+// restore path verifies before and after decrypting.
+type Manifest struct {
+	Version          int       `json:"version"`
+	Cipher           string    `json:"cipher"`
+	KeyID            string    `json:"key_id"`
+	Snapshot         string    `json:"snapshot"`
+	CreatedAt        time.Time `json:"created_at"`
+	PlaintextSize    int64     `json:"plaintext_size"`
+	PlaintextSHA256  string    `json:"plaintext_sha256"`
+	CiphertextSize   int64     `json:"ciphertext_size"`
+	CiphertextSHA256 string    `json:"ciphertext_sha256"`
+	// Migration is the last migration applied in the snapshot, so an
+	// encrypted backup can be listed without decrypting it.
+	Migration string `json:"migration,omitempty"`
+}
+
+type backupKey struct {
+	id     string
+	secret []byte
+}
+
+// Keyring holds the backup key new snapshots are encrypted with and the
+// retired keys older snapshots may still need. It is deliberately separate
+// from AES_SECRET: leaking a backup key exposes backups, not live secrets.
+type Keyring struct {
+	current backupKey
+	keys    map[string]backupKey
+}
+
+// NewKeyring builds a keyring from the configured keys. It returns nil
+// without an error when current is empty — backups are then written in the
+// clear. Every key must be at least 32 bytes, e.g. `openssl rand -base64 32`.
+func NewKeyring(current string, retired ...string) (*Keyring, error) {
+	if current == "" {
+		return nil, nil
+	}
+	k := &Keyring{keys: map[string]backupKey{}}
+	for i, secret := range append([]string{current}, retired...) {
+		if len(secret) < minKeySize {
+			return nil, fmt.Errorf("backup key %d is shorter than %d bytes", i+1, minKeySize)
+		}
+		key := backupKey{id: keyID([]byte(secret)), secret: []byte(secret)}
+		if i == 0 {
+			k.current = key
+		}
+		k.keys[key.id] = key
+	}
+	return k, nil
+}
+
+// KeyID identifies the key new snapshots are encrypted with.
+func (k *Keyring) KeyID() string { return k.current.id }
+
+// keyID is a short fingerprint of a key: the leading bytes of a domain
+// separated hash, which identify it without helping to guess it.
+func keyID(secret []byte) string {
+	sum := sha256.Sum256(append([]byte("isme backup key id\x00"), secret...))
+	return hex.EncodeToString(sum[:keyIDSize])
+}
+
+// Seal encrypts the plaintext snapshot at path into path.enc with the current
+// key, writes its manifest and removes the plaintext. On failure nothing but
+// the plaintext is left, so the caller decides what to do with it.
+func (k *Keyring) Seal(ctx context.Context, path string, now time.Time) (string, Manifest, error) {
+	sealed := path + EncryptedSuffix
+	manifest := Manifest{
+		Version:   manifestVersion,
+		Cipher:    cipherName,
+		KeyID:     k.current.id,
+		Snapshot:  filepath.Base(sealed),
+		CreatedAt: now.UTC(),
+	}
+	if IsDump(path) {
+		if header, err := ReadDumpHeader(path); err == nil {
+			manifest.Migration = header.Migration
+		}
+	} else if applied, err := appliedMigrations(ctx, path); err == nil && len(applied) > 0 {
+		manifest.Migration = applied[len(applied)-1]
+	}
+
+	err := k.encryptFile(path, sealed, &manifest)
+	if err == nil {
+		err = writeManifest(sealed+ManifestSuffix, manifest)
+	}
+	if err != nil {
+		os.Remove(sealed)
+		os.Remove(sealed + ManifestSuffix)
+		return "", Manifest{}, err
+	}
+	if err := os.Remove(path); err != nil {
+		return "", Manifest{}, fmt.Errorf("remove plaintext snapshot: %w", err)
+	}
+	return sealed, manifest, nil
+}
+
+// Open verifies the encrypted snapshot at sealed against its manifest,
+// decrypts it into dst and checks the plaintext checksum. dst is removed when
+// any check fails.
+func (k *Keyring) Open(sealed, dst string) (Manifest, error) {
+	manifest, err := ReadManifest(sealed + ManifestSuffix)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if k == nil {
+		return manifest, ErrNoKey
+	}
+	key, ok := k.keys[manifest.KeyID]
+	if !ok {
+		return manifest, fmt.Errorf("%w %s", ErrUnknownKey, manifest.KeyID)
+	}
+
+	size, sum, err := fileSHA256(sealed)
+	if err != nil {
+		return manifest, err
+	}
+	if size != manifest.CiphertextSize || sum != manifest.CiphertextSHA256 {
+		return manifest, fmt.Errorf("%w: ciphertext differs", ErrChecksum)
+	}
+
+	if err := decryptFile(key, sealed, dst, manifest); err != nil {
+		os.Remove(dst)
+		return manifest, err
+	}
+	return manifest, nil
+}
+
+// ReadManifest reads the manifest at path.
+func ReadManifest(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if manifest.Version != manifestVersion || manifest.Cipher != cipherName {
+		return Manifest{}, fmt.Errorf("unsupported backup manifest version %d (%s)", manifest.Version, manifest.Cipher)
+	}
+	return manifest, nil
+}
+
+// IsEncrypted reports whether name is an encrypted snapshot.
+func IsEncrypted(name string) bool {
+	return strings.HasSuffix(name, EncryptedSuffix)
+}
+
+func writeManifest(path string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func (k *Keyring) encryptFile(src, dst string, manifest *Manifest) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	header := newHeader(k.current.id, salt)
+	aead, err := fileAEAD(k.current, salt)
+	if err != nil {
+		return err
+	}
+
+	plainHash, cipherHash := sha256.New(), sha256.New()
+	w := io.MultiWriter(out, cipherHash)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(io.TeeReader(in, plainHash), chunkSize)
+	chunk := make([]byte, chunkSize)
+	var plainSize int64
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(reader, chunk)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		plainSize += int64(n)
+		final := n < chunkSize
+		if !final {
+			if _, err := reader.Peek(1); errors.Is(err, io.EOF) {
+				final = true
+			}
+		}
+		if _, err := w.Write(aead.Seal(nil, chunkNonce(index, final), chunk[:n], header)); err != nil {
+			return err
+		}
+		if final {
+			break
+		}
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+
+	info, err := out.Stat()
+	if err != nil {
+		return err
+	}
+	manifest.PlaintextSize = plainSize
+	manifest.PlaintextSHA256 = hexSum(plainHash)
+	manifest.CiphertextSize = info.Size()
+	manifest.CiphertextSHA256 = hexSum(cipherHash)
+	return out.Close()
+}
+
+func decryptFile(key backupKey, src, dst string, manifest Manifest) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	reader := bufio.NewReaderSize(in, chunkSize+aesGCMOverhead)
+
+	header := make([]byte, len(magic)+keyIDSize+saltSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return fmt.Errorf("read backup header: %w", err)
+	}
+	if !bytes.Equal(header[:len(magic)], magic) {
+		return errors.New("not an encrypted isme backup")
+	}
+	if hex.EncodeToString(header[len(magic):len(magic)+keyIDSize]) != key.id {
+		return fmt.Errorf("%w: header and manifest name different keys", ErrChecksum)
+	}
+	aead, err := fileAEAD(key, header[len(magic)+keyIDSize:])
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	plainHash := sha256.New()
+	w := io.MultiWriter(out, plainHash)
+	chunk := make([]byte, chunkSize+aesGCMOverhead)
+	var plainSize int64
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(reader, chunk)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			// a clean EOF here means the final chunk never came
+			return fmt.Errorf("backup is truncated: %w", err)
+		}
+		final := n < len(chunk)
+		if !final {
+			if _, err := reader.Peek(1); errors.Is(err, io.EOF) {
+				final = true
+			}
+		}
+		plain, err := aead.Open(chunk[:0], chunkNonce(index, final), chunk[:n], header)
+		if err != nil {
+			return fmt.Errorf("decrypt backup chunk %d: %w", index, err)
+		}
+		if _, err := w.Write(plain); err != nil {
+			return err
+		}
+		plainSize += int64(len(plain))
+		if final {
+			break
+		}
+	}
+	if plainSize != manifest.PlaintextSize || hexSum(plainHash) != manifest.PlaintextSHA256 {
+		return fmt.Errorf("%w: decrypted snapshot differs", ErrChecksum)
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// aesGCMOverhead is the tag GCM appends to every sealed chunk.
+const aesGCMOverhead = 16
+
+func newHeader(keyID string, salt []byte) []byte {
+	id, _ := hex.DecodeString(keyID)
+	header := make([]byte, 0, len(magic)+keyIDSize+saltSize)
+	header = append(header, magic...)
+	header = append(header, id...)
+	return append(header, salt...)
+}
+
+func fileAEAD(key backupKey, salt []byte) (cipher.AEAD, error) {
+	fileKey, err := hkdf.Key(sha256.New, key.secret, salt, hkdfInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce is the 12-byte GCM nonce of chunk index: the index big-endian in
+// the first 11 bytes and 1 in the last byte for the final chunk.
+func chunkNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func fileSHA256(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hexSum(h), nil
+}
+
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,158 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKey    = "0123456789abcdef0123456789abcdef-current"
+	retiredKey = "0123456789abcdef0123456789abcdef-retired"
+)
+
+func testKeyring(t *testing.T, retired ...string) *Keyring {
+	t.Helper()
+	keys, err := NewKeyring(testKey, retired...)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return keys
+}
+
+// sealBytes writes data as a snapshot in a temp dir and seals it.
+func sealBytes(t *testing.T, keys *Keyring, data []byte) (string, Manifest) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app-20260101-000000.db")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sealed, manifest, err := keys.Seal(context.Background(), path, time.Now())
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("plaintext left behind after Seal: %v", err)
+	}
+	return sealed, manifest
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	keys := testKeyring(t)
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17} {
+		data := make([]byte, size)
+		rand.Read(data)
+		sealed, manifest := sealBytes(t, keys, data)
+
+		if manifest.KeyID != keys.KeyID() || manifest.PlaintextSize != int64(size) {
+			t.Fatalf("size %d: manifest = %+v", size, manifest)
+		}
+		ciphertext, _ := os.ReadFile(sealed)
+		if size > 32 && bytes.Contains(ciphertext, data[:32]) {
+			t.Fatalf("size %d: ciphertext contains plaintext", size)
+		}
+
+		dst := filepath.Join(t.TempDir(), "plain.db")
+		if _, err := keys.Open(sealed, dst); err != nil {
+			t.Fatalf("size %d: Open: %v", size, err)
+		}
+		if got, _ := os.ReadFile(dst); !bytes.Equal(got, data) {
+			t.Fatalf("size %d: decrypted %d bytes that differ from the original", size, len(got))
+		}
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	keys := testKeyring(t)
+	data := bytes.Repeat([]byte("isme"), chunkSize/2)
+
+	for name, tamper := range map[string]func(sealed string, m *Manifest){
+		"flipped byte": func(sealed string, m *Manifest) {
+			ciphertext, _ := os.ReadFile(sealed)
+			ciphertext[len(ciphertext)/2] ^= 1
+			rewrite(t, sealed, ciphertext, m)
+		},
+		"dropped final chunk": func(sealed string, m *Manifest) {
+			ciphertext, _ := os.ReadFile(sealed)
+			rewrite(t, sealed, ciphertext[:len(magic)+keyIDSize+saltSize+chunkSize+aesGCMOverhead], m)
+		},
+		"ciphertext checksum": func(sealed string, m *Manifest) {
+			ciphertext, _ := os.ReadFile(sealed)
+			ciphertext[len(ciphertext)-1] ^= 1
+			if err := os.WriteFile(sealed, ciphertext, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"plaintext checksum": func(sealed string, m *Manifest) {
+			m.PlaintextSHA256 = strings.Repeat("0", 64)
+			if err := writeManifest(sealed+ManifestSuffix, *m); err != nil {
+				t.Fatal(err)
+			}
+		},
+	} {
+		sealed, manifest := sealBytes(t, keys, data)
+		tamper(sealed, &manifest)
+
+		dst := filepath.Join(t.TempDir(), "plain.db")
+		if _, err := keys.Open(sealed, dst); err == nil {
+			t.Fatalf("%s: Open accepted a tampered backup", name)
+		}
+		if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s: rejected backup left plaintext behind", name)
+		}
+	}
+}
+
+// rewrite replaces the ciphertext and updates the manifest to match, so only
+// the authenticated encryption can catch the change.
+func rewrite(t *testing.T, sealed string, ciphertext []byte, m *Manifest) {
+	t.Helper()
+	if err := os.WriteFile(sealed, ciphertext, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	size, sum, err := fileSHA256(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.CiphertextSize, m.CiphertextSHA256 = size, sum
+	if err := writeManifest(sealed+ManifestSuffix, *m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old, err := NewKeyring(retiredKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := sealBytes(t, old, []byte("sealed under the old key"))
+
+	if _, err := testKeyring(t).Open(sealed, filepath.Join(t.TempDir(), "a.db")); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Open without the retired key = %v, want ErrUnknownKey", err)
+	}
+	dst := filepath.Join(t.TempDir(), "b.db")
+	if _, err := testKeyring(t, retiredKey).Open(sealed, dst); err != nil {
+		t.Fatalf("Open with the retired key: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "sealed under the old key" {
+		t.Fatalf("decrypted %q", got)
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	if keys, err := NewKeyring(""); keys != nil || err != nil {
+		t.Fatalf("NewKeyring(\"\") = %v, %v, want nil, nil", keys, err)
+	}
+	if _, err := NewKeyring("too short"); err == nil {
+		t.Fatal("NewKeyring accepted a short key")
+	}
+	if _, err := NewKeyring(testKey, "short"); err == nil {
+		t.Fatal("NewKeyring accepted a short retired key")
+	}
+}
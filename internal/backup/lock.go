@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+// Lock is an advisory lock on a SQLite database file, held on a sibling
+// <path>.lock file so it never interferes with SQLite's own locking, or on a
+// Postgres database via pg_advisory_lock (HoldSharedDB, TryExclusiveDB). Every
+// process that opens the database holds it shared (HoldShared); restore takes
+// it exclusively (TryExclusive), so it fails fast instead of swapping the file
+// under a live connection.
+type Lock struct {
+	release func() error
+}
+
+// Close releases the lock. It is safe to call on a nil Lock.
+func (l *Lock) Close() error {
+	if l == nil || l.release == nil {
+		return nil
+	}
+	release := l.release
+	l.release = nil
+	return release()
+}
+
+// HoldShared takes the shared lock on the database at path, blocking while a
+// restore holds it exclusively.
+func HoldShared(path string) (*Lock, error) {
+	return lockFile(path+".lock", false)
+}
+
+// TryExclusive takes the exclusive lock on the database at path, or returns
+// ErrInUse at once when another process holds it.
+func TryExclusive(path string) (*Lock, error) {
+	return lockFile(path+".lock", true)
+}
+
+// advisoryLockKey is the Postgres advisory lock that plays the role of the
+// .lock file for a Postgres database ("isme" in ASCII).
+const advisoryLockKey = 0x69736d65
+
+// HoldSharedDB takes the shared advisory lock on a Postgres database on a
+// connection of its own, held until Close, blocking while a restore holds it
+// exclusively.
+func HoldSharedDB(ctx context.Context, db *bun.DB) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock_shared(?)", advisoryLockKey); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Lock{release: func() error {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock_shared(?)", advisoryLockKey)
+		return conn.Close()
+	}}, nil
+}
+
+// TryExclusiveDB takes the exclusive advisory lock on a Postgres database, or
+// returns ErrInUse at once when another process holds it.
+func TryExclusiveDB(ctx context.Context, db *bun.DB) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", advisoryLockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrInUse
+	}
+	return &Lock{release: func() error {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", advisoryLockKey)
+		return conn.Close()
+	}}, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package backup
+
+// lockFile is a no-op where flock is unavailable: restore cannot tell whether
+// the server is running there, so stopping it first is up to the operator.
+func lockFile(string, bool) (*Lock, error) {
+	return &Lock{}, nil
+}
@@ -0,0 +1,596 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/internal/pgcatalog"
+
+	"github.com/uptrace/bun"
+)
+
+// A Postgres backup is a logical dump rather than a file copy: gzipped JSON
+// lines holding a header, then for every table in the isme schema a table
+// line naming its columns followed by one JSON array per row, and a trailer
+// with the row count of each table. Tables are written parents first, so a
+// restore can insert them in file order without deferring foreign keys. The
+// format carries no DDL: it restores into a schema migrated to the same
+// version, which keeps it portable across Postgres versions and hosts.
+const (
+	DumpPattern       = "app-*.dump"
+	SafetyDumpPattern = "pre-restore-*.dump"
+	dumpExt           = ".dump"
+	dumpFormat        = "isme-pgdump"
+	dumpVersion       = 1
+	dumpContentType   = "application/gzip"
+	dumpInsertBatch   = 200
+
+	kindHeader = "header"
+	kindTable  = "table"
+	kindEnd    = "end"
+)
+
+// ErrSchemaMismatch is returned when a dump's migration version differs from
+// the database it would be restored into.
+var ErrSchemaMismatch = errors.New("backup and database are at different migration versions")
+
+// DumpHeader opens every dump.
+type DumpHeader struct {
+	Kind      string    `json:"kind"`
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Migration is the last migration applied in the dumped database.
+	Migration string   `json:"migration"`
+	Tables    []string `json:"tables"`
+}
+
+// dumpTable starts the rows of one table. Types are the Postgres data types of
+// Columns; bytea values are base64 encoded.
+type dumpTable struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Types   []string `json:"types"`
+}
+
+// dumpEnd closes a dump; a file without it was truncated.
+type dumpEnd struct {
+	Kind string           `json:"kind"`
+	Rows map[string]int64 `json:"rows"`
+}
+
+// DumpSummary describes a written dump.
+type DumpSummary struct {
+	Tables int   `json:"tables"`
+	Rows   int64 `json:"rows"`
+}
+
+// IsDump reports whether name is a logical dump, in the clear or encrypted.
+func IsDump(name string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(name, EncryptedSuffix), dumpExt)
+}
+
+// IsJobSnapshot reports whether name is one of the backup job's snapshots:
+// a SQLite file or a Postgres dump, in the clear or encrypted.
+func IsJobSnapshot(name string) bool {
+	return MatchSnapshot(BackupPattern, name) || MatchSnapshot(DumpPattern, name)
+}
+
+// Dump writes a logical dump of the Postgres database to path. It reads every
+// table in one REPEATABLE READ transaction, so the dump is consistent while
+// the server keeps writing, and renames the finished file into place so a
+// crash never leaves a partial dump under a snapshot name.
+func Dump(ctx context.Context, db *bun.DB, path string, now time.Time) (DumpSummary, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return DumpSummary{}, err
+	}
+	defer tx.Rollback()
+
+	tables, err := pgcatalog.Tables(ctx, tx)
+	if err != nil {
+		return DumpSummary{}, err
+	}
+	applied := []string{}
+	if slices.Contains(tables, migrationsTable) {
+		if applied, err = queryAppliedIDB(ctx, tx); err != nil {
+			return DumpSummary{}, err
+		}
+	}
+
+	header := DumpHeader{CreatedAt: now.UTC(), Tables: tables}
+	if len(applied) > 0 {
+		header.Migration = applied[len(applied)-1]
+	}
+	w, err := createDump(path, header)
+	if err != nil {
+		return DumpSummary{}, err
+	}
+	defer w.abort()
+	for _, table := range tables {
+		if err := dumpRows(ctx, tx, w, table); err != nil {
+			return DumpSummary{}, fmt.Errorf("dump %s: %w", table, err)
+		}
+	}
+	return w.finish()
+}
+
+// dumpWriter writes a dump to path+".partial" and renames it into place once
+// finished; abort removes an unfinished one.
+type dumpWriter struct {
+	path    string
+	file    *os.File
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	enc     *json.Encoder
+	table   string
+	end     dumpEnd
+	summary DumpSummary
+}
+
+func createDump(path string, header DumpHeader) (*dumpWriter, error) {
+	file, err := os.OpenFile(path+".partial", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	w := &dumpWriter{path: path, file: file, gz: gzip.NewWriter(file), end: dumpEnd{Kind: kindEnd, Rows: map[string]int64{}}}
+	w.buf = bufio.NewWriter(w.gz)
+	w.enc = json.NewEncoder(w.buf)
+
+	header.Kind, header.Format, header.Version = kindHeader, dumpFormat, dumpVersion
+	if err := w.enc.Encode(header); err != nil {
+		w.abort()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *dumpWriter) beginTable(table dumpTable) error {
+	table.Kind = kindTable
+	w.table = table.Name
+	w.end.Rows[table.Name] = 0
+	w.summary.Tables++
+	return w.enc.Encode(table)
+}
+
+func (w *dumpWriter) writeRow(row []any) error {
+	w.end.Rows[w.table]++
+	w.summary.Rows++
+	return w.enc.Encode(row)
+}
+
+func (w *dumpWriter) finish() (DumpSummary, error) {
+	if err := w.enc.Encode(w.end); err != nil {
+		return DumpSummary{}, err
+	}
+	if err := w.buf.Flush(); err != nil {
+		return DumpSummary{}, err
+	}
+	if err := w.gz.Close(); err != nil {
+		return DumpSummary{}, err
+	}
+	if err := w.file.Sync(); err != nil {
+		return DumpSummary{}, err
+	}
+	if err := w.file.Close(); err != nil {
+		return DumpSummary{}, err
+	}
+	if err := os.Rename(w.path+".partial", w.path); err != nil {
+		return DumpSummary{}, err
+	}
+	return w.summary, nil
+}
+
+func (w *dumpWriter) abort() {
+	w.file.Close()
+	os.Remove(w.path + ".partial")
+}
+
+func dumpRows(ctx context.Context, tx bun.Tx, w *dumpWriter, table string) error {
+	catalog, err := pgcatalog.Columns(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+	columns, types := pgcatalog.Names(catalog), make([]string, len(catalog))
+	for i, column := range catalog {
+		types[i] = column.DataType
+	}
+	if err := w.beginTable(dumpTable{Name: table, Columns: columns, Types: types}); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT ? FROM ?", pgcatalog.Idents(columns...), bun.Ident(table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]any, len(columns))
+	scan := make([]any, len(columns))
+	for i := range values {
+		scan[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scan...); err != nil {
+			return err
+		}
+		row := make([]any, len(values))
+		for i, value := range values {
+			row[i] = encodeValue(value, types[i])
+		}
+		if err := w.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// encodeValue maps a scanned column value onto JSON. bytea is base64 encoded
+// so it survives the round trip; other byte slices are text the driver did
+// not convert.
+func encodeValue(value any, dataType string) any {
+	raw, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	if dataType == "bytea" {
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+	return string(raw)
+}
+
+// decodeValue reverses encodeValue for a value read back with UseNumber.
+func decodeValue(value any, dataType string) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case string:
+		if dataType == "bytea" {
+			return base64.StdEncoding.DecodeString(v)
+		}
+	}
+	return value, nil
+}
+
+// dumpReader walks a dump file line by line.
+type dumpReader struct {
+	file   *os.File
+	gz     *gzip.Reader
+	dec    *json.Decoder
+	Header DumpHeader
+}
+
+func openDump(path string) (*dumpReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("not a dump: %w", err)
+	}
+	r := &dumpReader{file: file, gz: gz, dec: json.NewDecoder(gz)}
+	r.dec.UseNumber()
+	if err := r.dec.Decode(&r.Header); err != nil || r.Header.Kind != kindHeader || r.Header.Format != dumpFormat {
+		r.Close()
+		return nil, errors.New("not an isme dump")
+	}
+	if r.Header.Version != dumpVersion {
+		r.Close()
+		return nil, fmt.Errorf("unsupported dump version %d", r.Header.Version)
+	}
+	return r, nil
+}
+
+func (r *dumpReader) Close() error {
+	r.gz.Close()
+	return r.file.Close()
+}
+
+// next returns the next table line, row or trailer; exactly one of them is
+// set. io.EOF without a trailer means the dump was cut short.
+func (r *dumpReader) next() (*dumpTable, []any, *dumpEnd, error) {
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, nil, io.ErrUnexpectedEOF
+		}
+		return nil, nil, nil, err
+	}
+	if len(raw) > 0 && raw[0] == '[' {
+		var row []any
+		dec := json.NewDecoder(strings.NewReader(string(raw)))
+		dec.UseNumber()
+		if err := dec.Decode(&row); err != nil {
+			return nil, nil, nil, err
+		}
+		return nil, row, nil, nil
+	}
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(raw, &kind); err != nil {
+		return nil, nil, nil, err
+	}
+	switch kind.Kind {
+	case kindTable:
+		var table dumpTable
+		if err := json.Unmarshal(raw, &table); err != nil {
+			return nil, nil, nil, err
+		}
+		return &table, nil, nil, nil
+	case kindEnd:
+		var end dumpEnd
+		if err := json.Unmarshal(raw, &end); err != nil {
+			return nil, nil, nil, err
+		}
+		return nil, nil, &end, nil
+	}
+	return nil, nil, nil, fmt.Errorf("unexpected dump line of kind %q", kind.Kind)
+}
+
+// ReadDumpHeader reads the header of the dump at path without reading its
+// rows.
+func ReadDumpHeader(path string) (DumpHeader, error) {
+	r, err := openDump(path)
+	if err != nil {
+		return DumpHeader{}, err
+	}
+	defer r.Close()
+	return r.Header, nil
+}
+
+// InspectDump reads the whole dump at path: it must decompress cleanly, end
+// with its trailer and hold as many rows per table as the trailer says. The
+// Inspection reports problems in Integrity, like PRAGMA integrity_check does
+// for a SQLite snapshot.
+func InspectDump(path string) (Inspection, error) {
+	r, err := openDump(path)
+	if err != nil {
+		return Inspection{}, err
+	}
+	defer r.Close()
+
+	inspection := Inspection{}
+	for _, table := range coreTables {
+		if !slices.Contains(r.Header.Tables, table) {
+			inspection.MissingTables = append(inspection.MissingTables, table)
+		}
+	}
+
+	counts := map[string]int64{}
+	type migration struct {
+		id   int64
+		name string
+	}
+	migrations := []migration{}
+	var current *dumpTable
+	idCol, nameCol := -1, -1
+	for {
+		table, row, end, err := r.next()
+		if err != nil {
+			inspection.Integrity = []string{"dump is unreadable or truncated: " + err.Error()}
+			return inspection, nil
+		}
+		switch {
+		case table != nil:
+			current = table
+			idCol, nameCol = slices.Index(table.Columns, "id"), slices.Index(table.Columns, "name")
+		case row != nil:
+			if current == nil || len(row) != len(current.Columns) {
+				inspection.Integrity = []string{"dump has a row that does not match its table"}
+				return inspection, nil
+			}
+			counts[current.Name]++
+			if current.Name == migrationsTable && idCol >= 0 && nameCol >= 0 {
+				number, _ := row[idCol].(json.Number)
+				id, _ := number.Int64()
+				name, _ := row[nameCol].(string)
+				migrations = append(migrations, migration{id: id, name: name})
+			}
+		default:
+			for _, name := range r.Header.Tables {
+				if counts[name] != end.Rows[name] {
+					inspection.Integrity = append(inspection.Integrity,
+						fmt.Sprintf("table %s has %d rows, trailer says %d", name, counts[name], end.Rows[name]))
+					if len(inspection.Integrity) == maxIntegrityLines {
+						break
+					}
+				}
+			}
+			if len(inspection.Integrity) == 0 {
+				inspection.Integrity = []string{integrityCheckOK}
+			}
+			sort.Slice(migrations, func(i, j int) bool { return migrations[i].id < migrations[j].id })
+			inspection.Applied = make([]string, 0, len(migrations))
+			for _, m := range migrations {
+				inspection.Applied = append(inspection.Applied, m.name)
+			}
+			return inspection, nil
+		}
+	}
+}
+
+// RestorePostgres replaces the contents of the Postgres database with the
+// dump at src. Like Restore it checks src first, refuses while the server
+// holds the database (ErrInUse) and dumps the current data to snapshotDir
+// before touching anything. The dump must be at the database's migration
+// version (ErrSchemaMismatch otherwise). Every table is then truncated and
+// reloaded in one transaction, so a failure leaves the database as it was.
+func RestorePostgres(ctx context.Context, db *bun.DB, src, snapshotDir string, keys *Keyring, now time.Time) (Result, error) {
+	result := Result{Source: src, Target: "postgres"}
+
+	if IsEncrypted(src) {
+		if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+			return result, err
+		}
+		plain := filepath.Join(snapshotDir, "decrypted-"+strings.TrimSuffix(filepath.Base(src), EncryptedSuffix))
+		os.Remove(plain)
+		if _, err := keys.Open(src, plain); err != nil {
+			return result, err
+		}
+		defer os.Remove(plain)
+		src = plain
+	}
+
+	inspection, err := InspectDump(src)
+	if err != nil {
+		return result, err
+	}
+	result.Inspection = inspection
+	if !inspection.OK() {
+		return result, fmt.Errorf("%w: %s", ErrIntegrity, describeFailure(inspection))
+	}
+
+	lock, err := TryExclusiveDB(ctx, db)
+	if err != nil {
+		return result, err
+	}
+	defer lock.Close()
+
+	current, err := queryAppliedIDB(ctx, db)
+	if err != nil {
+		return result, err
+	}
+	if delta := SchemaDelta(inspection.Applied, current); !delta.Current() {
+		return result, fmt.Errorf("%w: backup at %q, database at %q; migrate the database to the backup's version first",
+			ErrSchemaMismatch, inspection.Version(), lastOf(current))
+	}
+
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return result, fmt.Errorf("create snapshot dir: %w", err)
+	}
+	snapshot := filepath.Join(snapshotDir, safetyPrefix+now.UTC().Format(timestampLayout)+dumpExt)
+	if _, err := Dump(ctx, db, snapshot, now); err != nil {
+		return result, fmt.Errorf("snapshot current database: %w", err)
+	}
+	if keys != nil {
+		if snapshot, _, err = keys.Seal(ctx, snapshot, now); err != nil {
+			return result, fmt.Errorf("encrypt snapshot of current database: %w", err)
+		}
+	}
+	result.Snapshot = snapshot
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return loadDump(ctx, tx, src)
+	})
+	if err != nil {
+		return result, fmt.Errorf("load backup: %w", err)
+	}
+	return result, nil
+}
+
+// loadDump truncates the dumped tables and inserts the dump's rows, then moves
+// every serial and identity sequence past the restored keys.
+func loadDump(ctx context.Context, tx bun.Tx, path string) error {
+	r, err := openDump(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	existing, err := pgcatalog.Tables(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, table := range r.Header.Tables {
+		if !slices.Contains(existing, table) {
+			return fmt.Errorf("table %s is missing from the database", table)
+		}
+	}
+	if len(r.Header.Tables) > 0 {
+		if _, err := tx.ExecContext(ctx, "TRUNCATE ? RESTART IDENTITY CASCADE", pgcatalog.Idents(r.Header.Tables...)); err != nil {
+			return err
+		}
+	}
+
+	var table *dumpTable
+	var overriding bool
+	batch := [][]any{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("(?), ", len(batch)), ", ")
+		query := "INSERT INTO ? (?) "
+		if overriding {
+			query += "OVERRIDING SYSTEM VALUE "
+		}
+		args := []any{bun.Ident(table.Name), pgcatalog.Idents(table.Columns...)}
+		for _, row := range batch {
+			args = append(args, bun.In(row))
+		}
+		_, err := tx.ExecContext(ctx, query+"VALUES "+placeholders, args...)
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		next, row, end, err := r.next()
+		if err != nil {
+			return err
+		}
+		if next != nil || end != nil {
+			if err := flush(); err != nil {
+				return fmt.Errorf("insert into %s: %w", table.Name, err)
+			}
+		}
+		switch {
+		case next != nil:
+			table = next
+			columns, err := pgcatalog.Columns(ctx, tx, table.Name)
+			if err != nil {
+				return err
+			}
+			overriding = pgcatalog.HasIdentity(columns)
+		case row != nil:
+			for i := range row {
+				if row[i], err = decodeValue(row[i], table.Types[i]); err != nil {
+					return fmt.Errorf("decode %s.%s: %w", table.Name, table.Columns[i], err)
+				}
+			}
+			batch = append(batch, row)
+			if len(batch) == dumpInsertBatch {
+				if err := flush(); err != nil {
+					return fmt.Errorf("insert into %s: %w", table.Name, err)
+				}
+			}
+		default:
+			return pgcatalog.ResetSequences(ctx, tx)
+		}
+	}
+}
+
+func queryAppliedIDB(ctx context.Context, db bun.IDB) ([]string, error) {
+	applied := []string{}
+	if err := db.NewRaw(`SELECT name FROM ? ORDER BY id`, bun.Ident(migrationsTable)).Scan(ctx, &applied); err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	return applied, nil
+}
+
+func lastOf(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return names[len(names)-1]
+}
@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestDump writes a dump of the core tables with the given migrations
+// recorded as applied, the way Dump lays one out.
+func writeTestDump(t *testing.T, path string, applied ...string) {
+	t.Helper()
+	tables := []string{"app_services", migrationsTable, "schedule_config", "users"}
+	header := DumpHeader{CreatedAt: time.Now(), Tables: tables}
+	if len(applied) > 0 {
+		header.Migration = applied[len(applied)-1]
+	}
+	w, err := createDump(path, header)
+	if err != nil {
+		t.Fatalf("createDump: %v", err)
+	}
+	defer w.abort()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(w.beginTable(dumpTable{Name: "app_services", Columns: []string{"id", "secret"}, Types: []string{"text", "bytea"}}))
+	must(w.writeRow([]any{"isme", encodeValue([]byte{0, 1, 2, 0xff}, "bytea")}))
+	must(w.beginTable(dumpTable{Name: migrationsTable, Columns: []string{"id", "name", "executed_at"}, Types: []string{"bigint", "text", "timestamp with time zone"}}))
+	// out of id order: Applied must still come back in execution order
+	for i := len(applied) - 1; i >= 0; i-- {
+		must(w.writeRow([]any{int64(i + 1), applied[i], time.Now()}))
+	}
+	must(w.beginTable(dumpTable{Name: "schedule_config", Columns: []string{"job_key"}, Types: []string{"text"}}))
+	must(w.beginTable(dumpTable{Name: "users", Columns: []string{"id", "email", "deleted_at"}, Types: []string{"text", "text", "timestamp with time zone"}}))
+	must(w.writeRow([]any{"u1", "a@example.com", nil}))
+	if _, err := w.finish(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app-20260101-000000.dump")
+	writeTestDump(t, path, "001_a", "002_b")
+	if _, err := os.Stat(path + ".partial"); !os.IsNotExist(err) {
+		t.Fatalf("partial file left behind: %v", err)
+	}
+
+	header, err := ReadDumpHeader(path)
+	if err != nil {
+		t.Fatalf("ReadDumpHeader: %v", err)
+	}
+	if header.Migration != "002_b" || len(header.Tables) != 4 {
+		t.Fatalf("header = %+v", header)
+	}
+
+	inspection, err := InspectDump(path)
+	if err != nil {
+		t.Fatalf("InspectDump: %v", err)
+	}
+	if !inspection.OK() || !slices.Equal(inspection.Applied, []string{"001_a", "002_b"}) {
+		t.Fatalf("inspection = %+v", inspection)
+	}
+
+	r, err := openDump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	table, _, _, _ := r.next()
+	_, row, _, err := r.next()
+	if table == nil || table.Name != "app_services" || err != nil {
+		t.Fatalf("first table = %+v, %v", table, err)
+	}
+	secret, err := decodeValue(row[1], table.Types[1])
+	if err != nil || !bytes.Equal(secret.([]byte), []byte{0, 1, 2, 0xff}) {
+		t.Fatalf("bytea decoded as %v, %v", secret, err)
+	}
+	_, _, _, _ = r.next()
+	_, row, _, _ = r.next()
+	if id, _ := decodeValue(row[0], "bigint"); id != int64(2) {
+		t.Fatalf("bigint decoded as %#v", id)
+	}
+}
+
+func TestInspectDumpDetectsDamage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app-20260101-000000.dump")
+	writeTestDump(t, path, "001_a")
+	plain := readGzip(t, path)
+
+	lines := strings.SplitAfter(strings.TrimSuffix(plain, "\n"), "\n")
+	for name, content := range map[string]string{
+		"missing trailer": strings.Join(lines[:len(lines)-1], ""),
+		"missing row":     strings.Replace(plain, `["u1","a@example.com",null]`+"\n", "", 1),
+	} {
+		damaged := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".dump")
+		writeGzip(t, damaged, content)
+		inspection, err := InspectDump(damaged)
+		if err != nil {
+			t.Fatalf("%s: InspectDump: %v", name, err)
+		}
+		if inspection.OK() {
+			t.Fatalf("%s: damaged dump passed inspection", name)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "junk.dump"), []byte("junk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InspectDump(filepath.Join(dir, "junk.dump")); err == nil {
+		t.Fatal("InspectDump accepted a file that is not a dump")
+	}
+}
+
+func TestIsJobSnapshot(t *testing.T) {
+	for name, want := range map[string]bool{
+		"app-20260101-000000.db":            true,
+		"app-20260101-000000.dump":          true,
+		"app-20260101-000000.dump.enc":      true,
+		"app-20260101-000000.dump.enc.json": false,
+		"app-20260101-000000.dump.partial":  false,
+		"pre-restore-20260101-000000.dump":  false,
+	} {
+		if got := IsJobSnapshot(name); got != want {
+			t.Errorf("IsJobSnapshot(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(content))
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,191 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/objectstore"
+)
+
+// Content types of the uploaded files: the registered media type of a SQLite
+// database file, sealed snapshots and their manifests.
+const (
+	contentType          = "application/vnd.sqlite3"
+	encryptedContentType = "application/octet-stream"
+	manifestContentType  = "application/json"
+)
+
+// Store is the off-host object storage snapshots are copied to;
+// *objectstore.Client implements it.
+type Store interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	List(ctx context.Context, prefix string) ([]objectstore.Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore returns the client for the off-host bucket configured in
+// cfg.Backup, or nil without an error when BACKUP_S3_BUCKET is blank.
+func NewStore(cfg *config.Config) (*objectstore.Client, error) {
+	if cfg.Backup.S3Bucket == "" {
+		return nil, nil
+	}
+	return objectstore.New(objectstore.Config{
+		Endpoint:        cfg.Backup.S3Endpoint,
+		Region:          cfg.Backup.S3Region,
+		Bucket:          cfg.Backup.S3Bucket,
+		AccessKeyID:     cfg.Backup.S3AccessKeyID,
+		SecretAccessKey: cfg.Backup.S3SecretAccessKey,
+		PathStyle:       cfg.Backup.S3PathStyle,
+	})
+}
+
+// Upload copies the snapshot at path to the store as prefix + its file name
+// and returns the object key. An encrypted snapshot's manifest is uploaded
+// first, so the store never holds a sealed snapshot it cannot verify.
+func Upload(ctx context.Context, store Store, prefix, path string) (string, error) {
+	if IsEncrypted(path) {
+		if _, err := putFile(ctx, store, prefix, path+ManifestSuffix, manifestContentType); err != nil {
+			return "", fmt.Errorf("upload manifest: %w", err)
+		}
+		return putFile(ctx, store, prefix, path, encryptedContentType)
+	}
+	if IsDump(path) {
+		return putFile(ctx, store, prefix, path, dumpContentType)
+	}
+	return putFile(ctx, store, prefix, path, contentType)
+}
+
+func putFile(ctx context.Context, store Store, prefix, path, contentType string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	key := prefix + filepath.Base(path)
+	if err := store.Put(ctx, key, file, info.Size(), contentType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// ListRemote returns the job's snapshots under prefix, newest first. Their
+// Path is the object key; Version is unknown until a snapshot is downloaded.
+func ListRemote(ctx context.Context, store Store, prefix string) ([]Backup, error) {
+	objects, err := remoteSnapshots(ctx, store, prefix)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]Backup, 0, len(objects))
+	for _, object := range objects {
+		backups = append(backups, Backup{
+			Name:      strings.TrimPrefix(object.Key, prefix),
+			Path:      object.Key,
+			Size:      object.Size,
+			ModTime:   object.LastModified,
+			Remote:    true,
+			Encrypted: IsEncrypted(object.Key),
+		})
+	}
+	return backups, nil
+}
+
+// PruneRemote deletes the oldest snapshots under prefix, keeping at most
+// retainCount of the newest — the remote twin of the job's local pruning.
+// Only keys named like the job's snapshots are considered, so other objects
+// sharing the prefix are left alone; an encrypted snapshot's manifest goes
+// with it.
+func PruneRemote(ctx context.Context, store Store, prefix string, retainCount int) (deleted int, kept int, err error) {
+	objects, err := remoteSnapshots(ctx, store, prefix)
+	if err != nil {
+		return 0, 0, err
+	}
+	if retainCount < 0 {
+		retainCount = 0
+	}
+	if retainCount >= len(objects) {
+		return 0, len(objects), nil
+	}
+	for _, object := range objects[retainCount:] {
+		if err := store.Delete(ctx, object.Key); err != nil {
+			return deleted, len(objects) - deleted, err
+		}
+		deleted++
+		if IsEncrypted(object.Key) {
+			if err := store.Delete(ctx, object.Key+ManifestSuffix); err != nil {
+				return deleted, len(objects) - deleted, err
+			}
+		}
+	}
+	return deleted, retainCount, nil
+}
+
+// Download fetches the object key into a new file at dst, and an encrypted
+// snapshot's manifest into dst + ManifestSuffix. A partial download is
+// removed, never left behind looking like a snapshot.
+func Download(ctx context.Context, store Store, key, dst string) error {
+	if IsEncrypted(key) {
+		if err := download(ctx, store, key+ManifestSuffix, dst+ManifestSuffix); err != nil {
+			return err
+		}
+		if err := download(ctx, store, key, dst); err != nil {
+			os.Remove(dst + ManifestSuffix)
+			return err
+		}
+		return nil
+	}
+	return download(ctx, store, key, dst)
+}
+
+func download(ctx context.Context, store Store, key, dst string) error {
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, body)
+	if syncErr := out.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("download %s: %w", key, err)
+	}
+	return nil
+}
+
+// remoteSnapshots lists the job's snapshots under prefix, newest first. The
+// timestamped names sort lexicographically by recency, like the local ones.
+func remoteSnapshots(ctx context.Context, store Store, prefix string) ([]objectstore.Object, error) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := objects[:0]
+	for _, object := range objects {
+		if IsJobSnapshot(strings.TrimPrefix(object.Key, prefix)) {
+			snapshots = append(snapshots, object)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Key > snapshots[j].Key })
+	return snapshots, nil
+}
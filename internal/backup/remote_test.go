@@ -0,0 +1,141 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/objectstore/objectstoretest"
+)
+
+func TestUploadListPruneRemote(t *testing.T) {
+	ctx := context.Background()
+	server := objectstoretest.New(t)
+	store := server.Client(t)
+	const prefix = "isme-backups/"
+
+	server.Put(prefix+"app-20260101-000000.db", []byte("old"), time.Now())
+	server.Put(prefix+"app-20260102-000000.db", []byte("older"), time.Now())
+	server.Put(prefix+"README.txt", []byte("not a backup"), time.Now())
+	server.Put("elsewhere/app-20260103-000000.db", []byte("other prefix"), time.Now())
+
+	path := filepath.Join(t.TempDir(), "app-20260110-030000.db")
+	if err := os.WriteFile(path, []byte("snapshot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	key, err := Upload(ctx, store, prefix, path)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if key != prefix+"app-20260110-030000.db" {
+		t.Fatalf("key = %q", key)
+	}
+	if data, _ := server.Object(key); string(data) != "snapshot" {
+		t.Fatalf("uploaded object = %q", data)
+	}
+
+	remote, err := ListRemote(ctx, store, prefix)
+	if err != nil {
+		t.Fatalf("ListRemote: %v", err)
+	}
+	names := []string{}
+	for _, b := range remote {
+		if !b.Remote {
+			t.Fatalf("remote backup %q not marked Remote", b.Name)
+		}
+		names = append(names, b.Name)
+	}
+	if want := []string{"app-20260110-030000.db", "app-20260102-000000.db", "app-20260101-000000.db"}; !slices.Equal(names, want) {
+		t.Fatalf("ListRemote names = %v, want %v", names, want)
+	}
+
+	deleted, kept, err := PruneRemote(ctx, store, prefix, 2)
+	if err != nil {
+		t.Fatalf("PruneRemote: %v", err)
+	}
+	if deleted != 1 || kept != 2 {
+		t.Fatalf("PruneRemote = deleted %d kept %d, want 1 and 2", deleted, kept)
+	}
+	want := []string{"elsewhere/app-20260103-000000.db", prefix + "README.txt", prefix + "app-20260102-000000.db", prefix + "app-20260110-030000.db"}
+	if got := server.Keys(); !slices.Equal(got, want) {
+		t.Fatalf("keys after prune = %v, want %v", got, want)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	ctx := context.Background()
+	server := objectstoretest.New(t)
+	store := server.Client(t)
+	server.Put("isme-backups/app-20260101-000000.db", []byte("snapshot"), time.Now())
+
+	dst := filepath.Join(t.TempDir(), "download.db")
+	if err := Download(ctx, store, "isme-backups/app-20260101-000000.db", dst); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "snapshot" {
+		t.Fatalf("downloaded %q", data)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if err := Download(ctx, store, "isme-backups/nope.db", missing); err == nil {
+		t.Fatal("Download of a missing key succeeded")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Fatal("failed download left a file behind")
+	}
+}
+
+func TestEncryptedRemoteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	server := objectstoretest.New(t)
+	store := server.Client(t)
+	const prefix = "isme-backups/"
+	keys := testKeyring(t)
+
+	var sealedKeys []string
+	for _, name := range []string{"app-20260101-000000.db", "app-20260102-000000.db"} {
+		sealed, _ := sealBytes(t, keys, []byte("snapshot "+name))
+		renamed := filepath.Join(filepath.Dir(sealed), name+EncryptedSuffix)
+		os.Rename(sealed, renamed)
+		os.Rename(sealed+ManifestSuffix, renamed+ManifestSuffix)
+		key, err := Upload(ctx, store, prefix, renamed)
+		if err != nil {
+			t.Fatalf("Upload: %v", err)
+		}
+		sealedKeys = append(sealedKeys, key)
+	}
+	if _, ok := server.Object(sealedKeys[0] + ManifestSuffix); !ok {
+		t.Fatal("manifest not uploaded with the sealed snapshot")
+	}
+
+	remote, err := ListRemote(ctx, store, prefix)
+	if err != nil {
+		t.Fatalf("ListRemote: %v", err)
+	}
+	if len(remote) != 2 || !remote[0].Encrypted || remote[0].Path != sealedKeys[1] {
+		t.Fatalf("ListRemote = %+v, want the two sealed snapshots, manifests excluded", remote)
+	}
+
+	dst := filepath.Join(t.TempDir(), "download.db.enc")
+	if err := Download(ctx, store, sealedKeys[1], dst); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	plain := filepath.Join(t.TempDir(), "plain.db")
+	if _, err := keys.Open(dst, plain); err != nil {
+		t.Fatalf("Open downloaded snapshot: %v", err)
+	}
+	if data, _ := os.ReadFile(plain); string(data) != "snapshot app-20260102-000000.db" {
+		t.Fatalf("decrypted %q", data)
+	}
+
+	if _, _, err := PruneRemote(ctx, store, prefix, 1); err != nil {
+		t.Fatalf("PruneRemote: %v", err)
+	}
+	want := []string{sealedKeys[1], sealedKeys[1] + ManifestSuffix}
+	if got := server.Keys(); !slices.Equal(got, want) {
+		t.Fatalf("keys after prune = %v, want %v", got, want)
+	}
+}
@@ -45,6 +45,9 @@ type Config struct {
 		// DSN is an optional full Postgres DSN override; when set it takes
 		// precedence over the discrete Host/Port/... fields.
 		DSN string `envconfig:"DB_DSN" default:""`
+		// AllowPendingMigrations lets the server start on a database that is
+		// missing migrations this build ships; by default it refuses.
+		AllowPendingMigrations bool `envconfig:"DB_ALLOW_PENDING_MIGRATIONS" default:"false"`
 	}
 	Graceful struct {
 		Verbose               bool `envconfig:"GRACEFUL_VERBOSE"`
@@ -63,6 +66,73 @@ type Config struct {
 		// the persisted DB config.
 		Enabled bool `envconfig:"SCHEDULER_ENABLED" default:"true"`
 	}
+	Audit struct {
+		// ChainKey signs the checkpoints the activity-cleanup job writes when it
+		// prunes the audit hash chain. Falls back to AES_SECRET when empty.
+		ChainKey string `envconfig:"AUDIT_CHAIN_KEY"`
+
+		// External sinks: each activity event is also shipped to every sink
+		// configured below. Shipping is asynchronous — events queue in a
+		// per-sink buffer of SinkBufferSize and are written in batches of up to
+		// SinkBatchSize at least every SinkFlushIntervalMs; when a sink falls
+		// behind and its buffer fills, new events are dropped for that sink
+		// (and counted) rather than slowing the request that produced them.
+		SinkBufferSize      int `envconfig:"AUDIT_SINK_BUFFER_SIZE" default:"1024"`
+		SinkBatchSize       int `envconfig:"AUDIT_SINK_BATCH_SIZE" default:"100"`
+		SinkFlushIntervalMs int `envconfig:"AUDIT_SINK_FLUSH_INTERVAL_MS" default:"1000"`
+		// SinkMaxRetries is how often a failed batch write is retried (with
+		// exponential backoff) before the batch is dropped.
+		SinkMaxRetries int `envconfig:"AUDIT_SINK_MAX_RETRIES" default:"3"`
+
+		// SyslogAddress enables the RFC 5424 syslog sink, e.g.
+		// "udp://127.0.0.1:514" or "tcp://logs.internal:601".
+		SyslogAddress string `envconfig:"AUDIT_SYSLOG_ADDRESS"`
+		SyslogAppName string `envconfig:"AUDIT_SYSLOG_APP_NAME" default:"isme"`
+
+		// FilePath enables the append-only JSON-lines sink. The file rotates
+		// once it exceeds FileMaxSizeMB, keeping FileMaxBackups rotated files.
+		FilePath       string `envconfig:"AUDIT_FILE_PATH"`
+		FileMaxSizeMB  int    `envconfig:"AUDIT_FILE_MAX_SIZE_MB" default:"100"`
+		FileMaxBackups int    `envconfig:"AUDIT_FILE_MAX_BACKUPS" default:"5"`
+
+		// HTTPEndpoint enables the HTTP collector sink: batches are POSTed as a
+		// JSON array, with HTTPToken (when set) as a bearer token.
+		HTTPEndpoint  string `envconfig:"AUDIT_HTTP_ENDPOINT"`
+		HTTPToken     string `envconfig:"AUDIT_HTTP_TOKEN"`
+		HTTPTimeoutMs int    `envconfig:"AUDIT_HTTP_TIMEOUT_MS" default:"5000"`
+	}
+	Metrics struct {
+		// Enabled serves the Prometheus endpoint on /metrics (default true).
+		Enabled bool `envconfig:"METRICS_ENABLED" default:"true"`
+		// Token, when set, must be sent by the scraper as a bearer token. Leave
+		// empty only when /metrics is unreachable from outside (e.g. private
+		// network scraping).
+		Token string `envconfig:"METRICS_TOKEN"`
+	}
+	Health struct {
+		// CheckTimeoutMs bounds each /readyz dependency probe; a probe still
+		// running then fails.
+		CheckTimeoutMs int `envconfig:"HEALTH_CHECK_TIMEOUT_MS" default:"2000"`
+		// SlowCheckMs is the latency above which a passing probe reports the
+		// dependency as degraded.
+		SlowCheckMs int `envconfig:"HEALTH_SLOW_CHECK_MS" default:"500"`
+	}
+	Tracing struct {
+		// Exporter selects where spans go: "none" (default, tracing off),
+		// "otlp" (an OTLP/HTTP collector, JSON encoding) or "stdout" (one JSON
+		// line per span, for local debugging).
+		Exporter string `envconfig:"TRACING_EXPORTER" default:"none"`
+		// OTLPEndpoint is the collector's base URL; spans are POSTed to
+		// <OTLPEndpoint>/v1/traces.
+		OTLPEndpoint string `envconfig:"TRACING_OTLP_ENDPOINT" default:"http://localhost:4318"`
+		// OTLPHeaders are extra export headers as "key1=value1,key2=value2",
+		// e.g. the API key of a hosted backend.
+		OTLPHeaders string `envconfig:"TRACING_OTLP_HEADERS"`
+		ServiceName string `envconfig:"TRACING_SERVICE_NAME" default:"isme"`
+		// SampleRatio is the fraction of new traces recorded (0..1). Requests
+		// carrying a traceparent follow the caller's sampling decision.
+		SampleRatio float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+	}
 	Medioa struct {
 		// BaseURL is the medioa2 origin used for server-to-server upload calls.
 		// Use 127.0.0.1:<port> (not *.local) to avoid the ~5s mDNS resolver stall.
@@ -72,6 +142,33 @@ type Config struct {
 		// Minted by a member of the target medioa2 bucket; set in .env at runtime.
 		APIKey string `envconfig:"MEDIOA_API_KEY"`
 	}
+	Backup struct {
+		// S3Bucket enables the off-host copy: after each local snapshot the
+		// database_backup job uploads it to this private S3-compatible bucket
+		// (AWS S3, Cloudflare R2, MinIO) under S3Prefix, and prunes the remote
+		// copies to the same retain_count. Leave it empty to keep backups on
+		// the local volume only. The bucket must not be public — backups hold
+		// password hashes and app secrets.
+		S3Endpoint        string `envconfig:"BACKUP_S3_ENDPOINT"`
+		S3Region          string `envconfig:"BACKUP_S3_REGION" default:"auto"`
+		S3Bucket          string `envconfig:"BACKUP_S3_BUCKET"`
+		S3Prefix          string `envconfig:"BACKUP_S3_PREFIX" default:"isme-backups/"`
+		S3AccessKeyID     string `envconfig:"BACKUP_S3_ACCESS_KEY_ID"`
+		S3SecretAccessKey string `envconfig:"BACKUP_S3_SECRET_ACCESS_KEY"`
+		// S3PathStyle addresses the bucket as <endpoint>/<bucket> rather than
+		// <bucket>.<endpoint>; MinIO needs it, R2 and S3 accept both.
+		S3PathStyle bool `envconfig:"BACKUP_S3_PATH_STYLE" default:"true"`
+
+		// EncryptionKey seals every snapshot (and restore's safety snapshot)
+		// with AES-256-GCM before it is kept or uploaded, next to a
+		// <snapshot>.json manifest naming the key and checksums. At least 32
+		// bytes and separate from AES_SECRET. When set but invalid the job
+		// refuses to write backups rather than fall back to plaintext.
+		EncryptionKey string `envconfig:"BACKUP_ENCRYPTION_KEY"`
+		// EncryptionRetiredKeys is a comma-separated list of earlier keys,
+		// kept so restore can still open snapshots sealed before a rotation.
+		EncryptionRetiredKeys []string `envconfig:"BACKUP_ENCRYPTION_RETIRED_KEYS"`
+	}
 }
 
 func LoadConfig(envFiles ...string) (*Config, error) {
@@ -11,6 +11,12 @@ const (
 
 	CONTAINER_NAME_SCHEDULE_PROVIDER = "schedule_provider"
 	CONTAINER_NAME_MEDIOA_CLIENT     = "medioa_client"
+	CONTAINER_NAME_BACKUP_STORE      = "backup_store"
+	CONTAINER_NAME_TX_RUNNER         = "tx_runner"
+	CONTAINER_NAME_AUDIT_PUBLISHER   = "audit_publisher"
+	CONTAINER_NAME_METRICS           = "metrics"
+	CONTAINER_NAME_TRACER            = "tracer"
+	CONTAINER_NAME_HEALTH_CHECKER    = "health_checker"
 
 	// Repositories
 	CONTAINER_NAME_USER_REPOSITORY            = "user_repository"
@@ -20,6 +26,8 @@ const (
 	CONTAINER_NAME_USER_INVITATION_REPOSITORY = "user_invitation_repository"
 	CONTAINER_NAME_SETTINGS_REPOSITORY        = "settings_repository"
 	CONTAINER_NAME_ACTIVITY_REPOSITORY        = "activity_repository"
+	CONTAINER_NAME_ACCESS_REQUEST_REPOSITORY  = "access_request_repository"
+	CONTAINER_NAME_GROUP_REPOSITORY           = "group_repository"
 
 	// Usecases
 	CONTAINER_NAME_AUTH_USECASE            = "auth_usecase"
@@ -30,4 +38,6 @@ const (
 	CONTAINER_NAME_SETTINGS_USECASE        = "settings_usecase"
 	CONTAINER_NAME_ACTIVITY_USECASE        = "activity_usecase"
 	CONTAINER_NAME_MEDIA_USECASE           = "media_usecase"
+	CONTAINER_NAME_ACCESS_REQUEST_USECASE  = "access_request_usecase"
+	CONTAINER_NAME_GROUP_USECASE           = "group_usecase"
 )
@@ -14,6 +14,7 @@ const (
 	AUTH_ENDPOINT_SSO_CONSENT     = "/sso/consent"
 	AUTH_ENDPOINT_INVITE_DETAIL   = "/invites/:token"
 	AUTH_ENDPOINT_ACCEPT_INVITE   = "/accept-invite"
+	AUTH_ENDPOINT_JWKS            = "/jwks"
 	// Self-service session management. Register the static /sessions/others and
 	// /sessions/count BEFORE /sessions/:id so Fiber's in-order matcher does not
 	// swallow them as the :id param.
@@ -24,13 +25,14 @@ const (
 	AUTH_ENDPOINT_MY_ACTIVITY              = "/me/activity"
 
 	// App service
-	APP_SERVICE_GROUP_NAME        = "app-service"
-	APP_SERVICE_ENDPOINT_ROOT     = ""
-	APP_SERVICE_ENDPOINT_REGISTER = "/register"
-	APP_SERVICE_ENDPOINT_VERIFY   = "/verify"
-	APP_SERVICE_ENDPOINT_REFRESH  = "/refresh"
-	APP_SERVICE_ENDPOINT_DETAIL   = "/:appServiceID"
-	APP_SERVICE_ENDPOINT_STATUS   = "/:appServiceID/status"
+	APP_SERVICE_GROUP_NAME                = "app-service"
+	APP_SERVICE_ENDPOINT_ROOT             = ""
+	APP_SERVICE_ENDPOINT_REGISTER         = "/register"
+	APP_SERVICE_ENDPOINT_VERIFY           = "/verify"
+	APP_SERVICE_ENDPOINT_REFRESH          = "/refresh"
+	APP_SERVICE_ENDPOINT_PERMISSIONS_SYNC = "/permissions/sync"
+	APP_SERVICE_ENDPOINT_DETAIL           = "/:appServiceID"
+	APP_SERVICE_ENDPOINT_STATUS           = "/:appServiceID/status"
 
 	// User
 	USER_GROUP_NAME              = "/users"
@@ -48,6 +50,7 @@ const (
 	ROLE_ENDPOINT_ROOT          = ""
 	ROLE_ENDPOINT_DETAIL        = "/:roleID"
 	ROLE_ENDPOINT_PERMISSIONS   = "/:roleID/permissions"
+	ROLE_ENDPOINT_INCLUDES      = "/:roleID/includes"
 	ROLE_ENDPOINT_MEMBERS       = "/:roleID/members"
 	ROLE_ENDPOINT_MEMBER_DETAIL = "/:roleID/members/:userID"
 
@@ -66,4 +69,39 @@ const (
 	SETTINGS_ENDPOINT_ROTATION_CLEANUP = "/rotation-cleanup"
 	SETTINGS_ENDPOINT_ACTIVITY_CLEANUP = "/activity-cleanup"
 	SETTINGS_ENDPOINT_DATABASE_BACKUP  = "/database-backup"
+
+	// Audit (admin audit trail search/export)
+	AUDIT_GROUP_NAME             = "/audit"
+	AUDIT_ENDPOINT_EVENTS        = "/events"
+	AUDIT_ENDPOINT_EVENTS_EXPORT = "/events/export"
+	AUDIT_ENDPOINT_VERIFY        = "/verify"
+
+	// Access requests (self-service role requests and the approval queue)
+	ACCESS_REQUEST_GROUP_NAME       = "/access-requests"
+	ACCESS_REQUEST_ENDPOINT_ROOT    = ""
+	ACCESS_REQUEST_ENDPOINT_MINE    = "/mine"
+	ACCESS_REQUEST_ENDPOINT_QUEUE   = "/queue"
+	ACCESS_REQUEST_ENDPOINT_ROLES   = "/roles"
+	ACCESS_REQUEST_ENDPOINT_APPROVE = "/:requestID/approve"
+	ACCESS_REQUEST_ENDPOINT_DENY    = "/:requestID/deny"
+	ACCESS_REQUEST_ENDPOINT_CANCEL  = "/:requestID/cancel"
+
+	// User groups (members inherit the group's roles)
+	GROUP_GROUP_NAME             = "/groups"
+	GROUP_ENDPOINT_ROOT          = ""
+	GROUP_ENDPOINT_DETAIL        = "/:groupID"
+	GROUP_ENDPOINT_ROLES         = "/:groupID/roles"
+	GROUP_ENDPOINT_MEMBERS       = "/:groupID/members"
+	GROUP_ENDPOINT_MEMBER_DETAIL = "/:groupID/members/:userID"
+
+	// API description (under /api/v1)
+	OPENAPI_ENDPOINT = "/openapi.json"
+	DOCS_ENDPOINT    = "/docs"
+
+	// Prometheus scrape endpoint (root level, outside /api/v1)
+	METRICS_ENDPOINT = "/metrics"
+
+	// Liveness and readiness probes (root level, outside /api/v1)
+	HEALTHZ_ENDPOINT = "/healthz"
+	READYZ_ENDPOINT  = "/readyz"
 )
@@ -0,0 +1,442 @@
+// Package dbcopy copies the rows of an isme SQLite database into a Postgres
+// database migrated to the same version, and verifies the copy. SQLite is
+// loose about types where Postgres is not, so every value is fixed up to the
+// Postgres column it lands in: INTEGER 0/1 flags become booleans, DATETIME
+// text becomes timestamps, numbers stored in TEXT columns become text.
+package dbcopy
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/internal/migration"
+	"github.com/vukyn/isme/internal/pgcatalog"
+
+	"github.com/uptrace/bun"
+)
+
+// bookkeepingTables are owned by the migration tooling on each side and
+// compared by name rather than copied; the recorded schema fingerprints only
+// hold for the database that recorded them.
+var bookkeepingTables = []string{"migrations", migration.ChecksumsTable}
+
+// insertBatch is the number of rows per INSERT.
+const insertBatch = 200
+
+// ErrNotEmpty is returned when the target already holds users and the copy
+// was not asked to replace them.
+var ErrNotEmpty = errors.New("target database already holds users")
+
+// timeLayouts are the ways SQLite databases written by isme store times:
+// CURRENT_TIMESTAMP, bun's sqlitedialect and Go's time.String. Times without a
+// zone are UTC, as CURRENT_TIMESTAMP is.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+// Plan is what Copy copied.
+type Plan struct {
+	// Tables are copied in this order, parents first.
+	Tables []string
+	// Rows is the number of rows copied per table.
+	Rows map[string]int64
+	// Skipped are source tables the target schema does not have, e.g. left
+	// behind by a migration that consolidated them.
+	Skipped []string
+}
+
+// TableReport compares one table on both sides.
+type TableReport struct {
+	Table          string `json:"table"`
+	SourceRows     int64  `json:"source_rows"`
+	TargetRows     int64  `json:"target_rows"`
+	SourceChecksum string `json:"source_checksum"`
+	TargetChecksum string `json:"target_checksum"`
+}
+
+// OK reports whether both sides hold the same rows.
+func (r TableReport) OK() bool {
+	return r.SourceRows == r.TargetRows && r.SourceChecksum == r.TargetChecksum
+}
+
+// Copy copies every table the source and target share from src into dst in
+// one transaction: the target tables are truncated (dropping the baseline's
+// seed rows), filled in foreign-key order, and their sequences moved past the
+// copied keys. Unless replace is set, a target that already holds users is
+// refused with ErrNotEmpty.
+func Copy(ctx context.Context, src *sql.DB, dst *bun.DB, replace bool) (Plan, error) {
+	plan, err := plan(ctx, src, dst)
+	if err != nil {
+		return Plan{}, err
+	}
+	if !replace && slices.Contains(plan.Tables, "users") {
+		var users int64
+		if err := dst.NewRaw(`SELECT COUNT(*) FROM users`).Scan(ctx, &users); err != nil {
+			return Plan{}, err
+		}
+		if users > 0 {
+			return Plan{}, fmt.Errorf("%w (%d)", ErrNotEmpty, users)
+		}
+	}
+
+	err = dst.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(plan.Tables) > 0 {
+			if _, err := tx.ExecContext(ctx, "TRUNCATE ? RESTART IDENTITY CASCADE", pgcatalog.Idents(plan.Tables...)); err != nil {
+				return err
+			}
+		}
+		for _, table := range plan.Tables {
+			n, err := copyTable(ctx, src, tx, table)
+			if err != nil {
+				return fmt.Errorf("copy %s: %w", table, err)
+			}
+			plan.Rows[table] = n
+		}
+		return pgcatalog.ResetSequences(ctx, tx)
+	})
+	if err != nil {
+		return Plan{}, err
+	}
+	return plan, nil
+}
+
+// Verify counts and checksums every planned table on both sides. The
+// checksum is order-independent and taken over the values as Postgres
+// stores them, so a faithful copy matches exactly.
+func Verify(ctx context.Context, src *sql.DB, dst *bun.DB, tables []string) ([]TableReport, error) {
+	reports := make([]TableReport, 0, len(tables))
+	for _, table := range tables {
+		columns, err := sharedColumns(ctx, src, dst, table)
+		if err != nil {
+			return nil, err
+		}
+		report := TableReport{Table: table}
+		if report.SourceRows, report.SourceChecksum, err = checksum(ctx, src, table, columns); err != nil {
+			return nil, fmt.Errorf("checksum source %s: %w", table, err)
+		}
+		if report.TargetRows, report.TargetChecksum, err = checksum(ctx, dst, table, columns); err != nil {
+			return nil, fmt.Errorf("checksum target %s: %w", table, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func plan(ctx context.Context, src *sql.DB, dst bun.IDB) (Plan, error) {
+	sourceTables, err := sqliteTables(ctx, src)
+	if err != nil {
+		return Plan{}, err
+	}
+	targetTables, err := pgcatalog.Tables(ctx, dst)
+	if err != nil {
+		return Plan{}, err
+	}
+	p := Plan{Rows: map[string]int64{}}
+	for _, table := range targetTables {
+		if !slices.Contains(bookkeepingTables, table) && slices.Contains(sourceTables, table) {
+			p.Tables = append(p.Tables, table)
+		}
+	}
+	for _, table := range sourceTables {
+		if !slices.Contains(bookkeepingTables, table) && !slices.Contains(targetTables, table) {
+			p.Skipped = append(p.Skipped, table)
+		}
+	}
+	return p, nil
+}
+
+func copyTable(ctx context.Context, src *sql.DB, tx bun.Tx, table string) (int64, error) {
+	columns, err := sharedColumns(ctx, src, tx, table)
+	if err != nil {
+		return 0, err
+	}
+	names := pgcatalog.Names(columns)
+	rows, err := src.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", pgcatalog.Idents(names...), pgcatalog.Idents(table)))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	insert := "INSERT INTO ? (?) "
+	if pgcatalog.HasIdentity(columns) {
+		insert += "OVERRIDING SYSTEM VALUE "
+	}
+	batch := [][]any{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		args := []any{bun.Ident(table), pgcatalog.Idents(names...)}
+		for _, row := range batch {
+			args = append(args, bun.In(row))
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("(?), ", len(batch)), ", ")
+		_, err := tx.ExecContext(ctx, insert+"VALUES "+placeholders, args...)
+		batch = batch[:0]
+		return err
+	}
+
+	var n int64
+	for rows.Next() {
+		row, err := scanRow(rows, columns)
+		if err != nil {
+			return n, err
+		}
+		batch = append(batch, row)
+		n++
+		if len(batch) == insertBatch {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	return n, flush()
+}
+
+// sharedColumns returns the target columns of table that the source also
+// has. A source column the target lacks is an error: copying would drop it.
+func sharedColumns(ctx context.Context, src *sql.DB, dst bun.IDB, table string) ([]pgcatalog.Column, error) {
+	targetColumns, err := pgcatalog.Columns(ctx, dst, table)
+	if err != nil {
+		return nil, err
+	}
+	sourceColumns, err := sqliteColumns(ctx, src, table)
+	if err != nil {
+		return nil, err
+	}
+	names := pgcatalog.Names(targetColumns)
+	for _, name := range sourceColumns {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("column %s.%s has no counterpart in Postgres", table, name)
+		}
+	}
+	shared := make([]pgcatalog.Column, 0, len(targetColumns))
+	for _, column := range targetColumns {
+		if slices.Contains(sourceColumns, column.Name) {
+			shared = append(shared, column)
+		}
+	}
+	return shared, nil
+}
+
+// rowScanner is *sql.Rows on either side.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRow reads one row and fixes every value up to its Postgres column.
+func scanRow(rows rowScanner, columns []pgcatalog.Column) ([]any, error) {
+	values := make([]any, len(columns))
+	scan := make([]any, len(columns))
+	for i := range values {
+		scan[i] = &values[i]
+	}
+	if err := rows.Scan(scan...); err != nil {
+		return nil, err
+	}
+	for i, column := range columns {
+		value, err := fixValue(values[i], column.DataType)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// fixValue converts a value read from SQLite (or read back from Postgres) to
+// the Go type of a Postgres column of dataType.
+func fixValue(value any, dataType string) (any, error) {
+	if raw, ok := value.([]byte); ok {
+		if dataType == "bytea" {
+			return raw, nil
+		}
+		value = string(raw)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch dataType {
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case float64:
+			return v != 0, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case "timestamp with time zone", "timestamp without time zone", "date":
+		switch v := value.(type) {
+		case time.Time:
+			return v.UTC(), nil
+		case int64:
+			return time.Unix(v, 0).UTC(), nil
+		case string:
+			if v == "" {
+				return nil, nil
+			}
+			return parseTime(v)
+		}
+	case "bigint", "integer", "smallint":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case string:
+			return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		}
+	case "text", "character varying", "character":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		case time.Time:
+			return v.UTC().Format(time.RFC3339Nano), nil
+		}
+	case "bytea":
+		if v, ok := value.(string); ok {
+			return []byte(v), nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("cannot convert %T %v to %s", value, value, dataType)
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", s)
+}
+
+// canonical renders a fixed-up value the same way on both sides: times in
+// UTC at Postgres' microsecond precision, bytea as base64.
+func canonical(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "\x00"
+	case time.Time:
+		return v.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// querier is *sql.DB on the source and bun.IDB on the target; both run plain
+// SQL with no placeholders.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// checksum counts the rows of table and hashes them independently of order:
+// each row is hashed, the hashes sorted, and the sorted list hashed again.
+func checksum(ctx context.Context, db querier, table string, columns []pgcatalog.Column) (int64, string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", pgcatalog.Idents(pgcatalog.Names(columns)...), pgcatalog.Idents(table)))
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	hashes := [][]byte{}
+	for rows.Next() {
+		row, err := scanRow(rows, columns)
+		if err != nil {
+			return 0, "", err
+		}
+		hashes = append(hashes, rowHash(row))
+	}
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+	slices.SortFunc(hashes, func(a, b []byte) int { return strings.Compare(string(a), string(b)) })
+	sum := sha256.New()
+	for _, h := range hashes {
+		sum.Write(h)
+	}
+	return int64(len(hashes)), hex.EncodeToString(sum.Sum(nil)[:16]), nil
+}
+
+// rowHash hashes a row's canonical values, each prefixed with its length so
+// adjacent values cannot run into each other.
+func rowHash(row []any) []byte {
+	h := sha256.New()
+	for _, value := range row {
+		s := canonical(value)
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+	return h.Sum(nil)
+}
+
+func sqliteTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	return queryStrings(ctx, db, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+}
+
+func sqliteColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	return queryStrings(ctx, db, `SELECT name FROM pragma_table_info(?) ORDER BY cid`, table)
+}
+
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
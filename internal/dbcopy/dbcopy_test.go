@@ -0,0 +1,90 @@
+package dbcopy
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFixValue(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		value    any
+		dataType string
+		want     any
+	}{
+		{"bool from int", int64(1), "boolean", true},
+		{"bool from zero", int64(0), "boolean", false},
+		{"bool from text", []byte("true"), "boolean", true},
+		{"timestamp from CURRENT_TIMESTAMP", "2026-03-04 05:06:07", "timestamp with time zone", at},
+		{"timestamp from RFC 3339", "2026-03-04T07:06:07+02:00", "timestamp with time zone", at},
+		{"timestamp from go time", "2026-03-04 05:06:07 +0000 UTC", "timestamp with time zone", at},
+		{"timestamp from unix", at.Unix(), "timestamp with time zone", at},
+		{"empty timestamp", "", "timestamp with time zone", nil},
+		{"text from int", int64(42), "text", "42"},
+		{"bigint from text", "7", "bigint", int64(7)},
+		{"bigint from whole float", float64(7), "bigint", int64(7)},
+		{"null", nil, "boolean", nil},
+	} {
+		got, err := fixValue(tc.value, tc.dataType)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if want, ok := tc.want.(time.Time); ok {
+			if gotTime, _ := got.(time.Time); !gotTime.Equal(want) {
+				t.Errorf("%s: got %v, want %v", tc.name, got, want)
+			}
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+
+	got, err := fixValue("abc", "bytea")
+	if err != nil || !bytes.Equal(got.([]byte), []byte("abc")) {
+		t.Errorf("bytea from text: got %#v, %v", got, err)
+	}
+
+	for _, bad := range []struct {
+		value    any
+		dataType string
+	}{
+		{"yes please", "boolean"},
+		{"tomorrow", "timestamp with time zone"},
+		{float64(1.5), "bigint"},
+	} {
+		if _, err := fixValue(bad.value, bad.dataType); err == nil {
+			t.Errorf("fixValue(%#v, %s) accepted a value it cannot convert", bad.value, bad.dataType)
+		}
+	}
+}
+
+func TestRowHashMatchesAcrossDrivers(t *testing.T) {
+	// The same row as SQLite returns it and as Postgres does.
+	sqliteRow := []any{int64(1), "2026-03-04 05:06:07.1234567", []byte("admin"), nil}
+	postgresRow := []any{true, time.Date(2026, 3, 4, 5, 6, 7, 123456000, time.UTC), "admin", nil}
+	types := []string{"boolean", "timestamp with time zone", "text", "text"}
+
+	fixed := func(row []any) []any {
+		out := make([]any, len(row))
+		for i, value := range row {
+			v, err := fixValue(value, types[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			out[i] = v
+		}
+		return out
+	}
+	if !bytes.Equal(rowHash(fixed(sqliteRow)), rowHash(fixed(postgresRow))) {
+		t.Fatal("the same row hashed differently on each side")
+	}
+
+	// A null and an empty string must not collide.
+	if bytes.Equal(rowHash([]any{nil}), rowHash([]any{""})) {
+		t.Fatal("null and empty string hash alike")
+	}
+}
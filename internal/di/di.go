@@ -15,11 +15,17 @@ func NewBuilder() *di.EnhancedBuilder {
 	defs := []*di.Def{
 		defineConfig(),
 		defineMedioaClient(),
+		defineBackupStore(),
 		defineDB(),
+		defineTxRunner(),
+		defineAuditPublisher(),
 		defineScheduler(),
 		defineScheduleProvider(),
 		defineCache(),
 		defineMiddleware(),
+		defineMetrics(),
+		defineTracer(),
+		defineHealthChecker(),
 	}
 	defs = append(defs, defineRepository()...)
 	defs = append(defs, defineUsecase()...)
@@ -0,0 +1,90 @@
+package di
+
+import (
+	"context"
+	"time"
+
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/vukyn/kuery/log"
+)
+
+// auditSinkCloseTimeout bounds how long shutdown waits for queued events to
+// reach the sinks.
+const auditSinkCloseTimeout = 5 * time.Second
+
+// defineAuditPublisher wires the dispatcher that ships committed activity
+// events to the external sinks configured under AUDIT_*. With no sink
+// configured it holds a NoopPublisher, so the recorder never needs to check.
+//
+// A sink that fails to build (bad syslog address, unwritable file path) is
+// logged and skipped rather than failing boot: the activity_events table stays
+// the source of truth and the remaining sinks keep working.
+func defineAuditPublisher() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_AUDIT_PUBLISHER,
+		Scope: di.App,
+		Build: func(ctn di.Container) (any, error) {
+			cfg := GetConfig(ctn)
+			sinks := buildAuditSinks(cfg)
+			if len(sinks) == 0 {
+				log.New().Debug("Audit publisher initialized without sinks")
+				return sink.NoopPublisher{}, nil
+			}
+			log.New().Debugf("Audit publisher initialized with %d sink(s)", len(sinks))
+			return sink.NewDispatcher(sink.Options{
+				BufferSize:    cfg.Audit.SinkBufferSize,
+				BatchSize:     cfg.Audit.SinkBatchSize,
+				FlushInterval: time.Duration(cfg.Audit.SinkFlushIntervalMs) * time.Millisecond,
+				MaxRetries:    cfg.Audit.SinkMaxRetries,
+			}, sinks...), nil
+		},
+		Close: func(obj any) error {
+			if dispatcher, ok := obj.(*sink.Dispatcher); ok {
+				ctx, cancel := context.WithTimeout(context.Background(), auditSinkCloseTimeout)
+				defer cancel()
+				if err := dispatcher.Close(ctx); err != nil {
+					log.New().Warnf("Audit publisher closed before every sink drained: %v", err)
+				}
+			}
+			log.New().Debug("Audit publisher destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func buildAuditSinks(cfg *config.Config) []sink.Sink {
+	var sinks []sink.Sink
+	if cfg.Audit.SyslogAddress != "" {
+		syslogSink, err := sink.NewSyslogSink(cfg.Audit.SyslogAddress, cfg.Audit.SyslogAppName)
+		if err != nil {
+			log.New().Errorf("Audit syslog sink disabled: %v", err)
+		} else {
+			sinks = append(sinks, syslogSink)
+		}
+	}
+	if cfg.Audit.FilePath != "" {
+		fileSink, err := sink.NewFileSink(cfg.Audit.FilePath, int64(cfg.Audit.FileMaxSizeMB)<<20, cfg.Audit.FileMaxBackups)
+		if err != nil {
+			log.New().Errorf("Audit file sink disabled: %v", err)
+		} else {
+			sinks = append(sinks, fileSink)
+		}
+	}
+	if cfg.Audit.HTTPEndpoint != "" {
+		sinks = append(sinks, sink.NewHTTPSink(
+			cfg.Audit.HTTPEndpoint,
+			cfg.Audit.HTTPToken,
+			time.Duration(cfg.Audit.HTTPTimeoutMs)*time.Millisecond,
+		))
+	}
+	return sinks
+}
+
+func GetAuditPublisher(ctn di.Container) sink.Publisher {
+	return ctn.Get(constants.CONTAINER_NAME_AUDIT_PUBLISHER).(sink.Publisher)
+}
@@ -2,6 +2,7 @@ package di
 
 import (
 	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/metrics"
 
 	"github.com/sarulabs/di/v2"
 	pkgCache "github.com/vukyn/kuery/cache"
@@ -12,10 +13,10 @@ func defineCache() *di.Def {
 		Name:  constants.CONTAINER_NAME_CACHE,
 		Scope: di.App,
 		Build: func(ctn di.Container) (any, error) {
-			return pkgCache.NewCache[string, string](), nil
+			return metrics.NewCache(pkgCache.NewCache[string, string]()), nil
 		},
 		Close: func(obj any) error {
-			cache := obj.(*pkgCache.Cache[string, string])
+			cache := obj.(*metrics.Cache)
 			cache.Close()
 			return nil
 		},
@@ -23,6 +24,6 @@ func defineCache() *di.Def {
 	return def
 }
 
-func GetCache(ctn di.Container) *pkgCache.Cache[string, string] {
-	return ctn.Get(constants.CONTAINER_NAME_CACHE).(*pkgCache.Cache[string, string])
+func GetCache(ctn di.Container) *metrics.Cache {
+	return ctn.Get(constants.CONTAINER_NAME_CACHE).(*metrics.Cache)
 }
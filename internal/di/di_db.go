@@ -1,16 +1,26 @@
 package di
 
 import (
+	"context"
+
 	"github.com/sarulabs/di/v2"
 	"github.com/uptrace/bun"
 	kueryDb "github.com/vukyn/kuery/bun/db"
 	pkgBunHooks "github.com/vukyn/kuery/bun/hooks"
 	"github.com/vukyn/kuery/log"
 
+	"github.com/vukyn/isme/internal/backup"
 	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/metrics"
+	"github.com/vukyn/isme/internal/tracing"
+	"github.com/vukyn/isme/internal/transaction"
 )
 
 func defineDB() *di.Def {
+	// lock is the shared restore lock — on the SQLite file, or a Postgres
+	// advisory lock — held for as long as the DB is open so an offline restore
+	// refuses to replace the data under it.
+	var lock *backup.Lock
 	def := &di.Def{
 		Name:  constants.CONTAINER_NAME_DB,
 		Scope: di.App,
@@ -39,15 +49,28 @@ func defineDB() *di.Def {
 			if driver == "" {
 				driver = string(kueryDb.DriverSQLite)
 			}
+			if driver == string(kueryDb.DriverSQLite) {
+				lock, err = backup.HoldShared(cfg.DB.SQLitePath)
+			} else {
+				lock, err = backup.HoldSharedDB(context.Background(), db)
+			}
+			if err != nil {
+				db.Close()
+				return nil, err
+			}
 			log.New().Infof("Database initialized with driver %q", driver)
 
 			db.AddQueryHook(pkgBunHooks.NewQueryHook(log.New()))
+			db.AddQueryHook(metrics.QueryHook{})
+			db.AddQueryHook(tracing.QueryHook{})
 			return db, nil
 		},
 		Close: func(obj any) error {
 			db := obj.(*bun.DB)
 			log.New().Debug("Database closed")
-			return db.Close()
+			err := db.Close()
+			lock.Close()
+			return err
 		},
 	}
 	return def
@@ -56,3 +79,26 @@ func defineDB() *di.Def {
 func GetDB(ctn di.Container) *bun.DB {
 	return ctn.Get(constants.CONTAINER_NAME_DB).(*bun.DB)
 }
+
+// defineTxRunner wires the context-carried transaction runner over the shared
+// DB. Usecases use it to commit a mutation together with its audit row; the
+// repositories join the ambient transaction via transaction.Conn.
+func defineTxRunner() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_TX_RUNNER,
+		Scope: di.App,
+		Build: func(ctn di.Container) (any, error) {
+			log.New().Debug("Transaction runner initialized")
+			return transaction.NewRunner(GetDB(ctn)), nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Transaction runner destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetTxRunner(ctn di.Container) transaction.Runner {
+	return ctn.Get(constants.CONTAINER_NAME_TX_RUNNER).(transaction.Runner)
+}
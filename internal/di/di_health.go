@@ -0,0 +1,57 @@
+package di
+
+import (
+	"time"
+
+	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/health"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/vukyn/kuery/log"
+)
+
+// defineHealthChecker assembles the /readyz checks over the App-scoped
+// dependencies. medioa is probed only when its client is configured
+// (MEDIOA_API_KEY set); without it avatar upload is off by design, not broken.
+func defineHealthChecker() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_HEALTH_CHECKER,
+		Scope: di.App,
+		Build: func(ctn di.Container) (any, error) {
+			cfg := GetConfig(ctn)
+			db := GetDB(ctn)
+
+			migrationNames := make([]string, 0, len(sqliteHistory.Migrations))
+			for _, migration := range sqliteHistory.Migrations {
+				migrationNames = append(migrationNames, migration.Name)
+			}
+
+			checks := []health.Check{
+				health.DatabaseCheck(db),
+				health.MigrationsCheck(db, migrationNames, []string{sqliteHistory.BaselineMigration.Name}),
+				health.CacheCheck(GetCache(ctn)),
+				health.Scheduler.Check(cfg.Scheduler.Enabled),
+			}
+			if medioaClient, err := GetMedioaClient(ctn); err == nil && medioaClient != nil {
+				checks = append(checks, health.HTTPCheck("medioa", cfg.Medioa.BaseURL))
+			}
+
+			log.New().Debugf("Health checker initialized with %d check(s)", len(checks))
+			return health.NewChecker(
+				time.Duration(cfg.Health.CheckTimeoutMs)*time.Millisecond,
+				time.Duration(cfg.Health.SlowCheckMs)*time.Millisecond,
+				checks...,
+			), nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Health checker destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetHealthChecker(ctn di.Container) *health.Checker {
+	return ctn.Get(constants.CONTAINER_NAME_HEALTH_CHECKER).(*health.Checker)
+}
@@ -0,0 +1,66 @@
+package di
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/vukyn/isme/internal/constants"
+	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
+	"github.com/vukyn/isme/internal/metrics"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/vukyn/kuery/log"
+)
+
+// metricsGaugeTimeout bounds the database read behind a scrape-time gauge so a
+// slow database cannot hang the scraper.
+const metricsGaugeTimeout = 2 * time.Second
+
+// defineMetrics registers the gauges that are read at scrape time (active
+// sessions, cache entries) against the App-scoped DB and cache, and returns the
+// registry /metrics serves. The event counters (HTTP, logins, DB queries,
+// scheduler) are fed directly by their hooks and need no wiring here.
+func defineMetrics() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_METRICS,
+		Scope: di.App,
+		Build: func(ctn di.Container) (any, error) {
+			userSessionRepository := userSessionRepo.NewRepository(GetDB(ctn))
+			cache := GetCache(ctn)
+
+			metrics.Default.Register(
+				metrics.NewGaugeFunc(
+					"isme_sessions_active",
+					"User sessions currently in the active state.",
+					func() float64 {
+						ctx, cancel := context.WithTimeout(context.Background(), metricsGaugeTimeout)
+						defer cancel()
+						count, err := userSessionRepository.CountActive(ctx)
+						if err != nil {
+							log.New().Warnf("Metrics: failed to count active sessions: %v", err)
+							return math.NaN()
+						}
+						return float64(count)
+					},
+				),
+				metrics.NewGaugeFunc(
+					"isme_cache_entries",
+					"Entries in the in-process cache (SSO sessions, authorization codes, consent nonces).",
+					func() float64 { return float64(cache.Len()) },
+				),
+			)
+			log.New().Debug("Metrics initialized")
+			return metrics.Default, nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Metrics destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetMetrics(ctn di.Container) *metrics.Registry {
+	return ctn.Get(constants.CONTAINER_NAME_METRICS).(*metrics.Registry)
+}
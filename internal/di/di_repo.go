@@ -2,8 +2,10 @@ package di
 
 import (
 	"github.com/vukyn/isme/internal/constants"
+	accessRequestRepo "github.com/vukyn/isme/internal/domains/access_request/repository"
 	activityRepo "github.com/vukyn/isme/internal/domains/activity/repository"
 	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	groupRepo "github.com/vukyn/isme/internal/domains/group/repository"
 	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	settingsRepo "github.com/vukyn/isme/internal/domains/settings/repository"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
@@ -24,6 +26,8 @@ func defineRepository() []*di.Def {
 		defineUserInvitationRepository(),
 		defineSettingsRepository(),
 		defineActivityRepository(),
+		defineAccessRequestRepository(),
+		defineGroupRepository(),
 	}
 }
 
@@ -201,3 +205,53 @@ func GetActivityRepository(ctn di.Container) (activityRepo.IRepository, error) {
 	}
 	return repo.(activityRepo.IRepository), nil
 }
+
+func defineAccessRequestRepository() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_ACCESS_REQUEST_REPOSITORY,
+		Scope: di.Request,
+		Build: func(ctn di.Container) (any, error) {
+			db := ctn.Get(constants.CONTAINER_NAME_DB).(*bun.DB)
+			log.New().Debug("Access request repository initialized")
+			return accessRequestRepo.NewRepository(db), nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Access request repository destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetAccessRequestRepository(ctn di.Container) (accessRequestRepo.IRepository, error) {
+	repo, err := ctn.SafeGet(constants.CONTAINER_NAME_ACCESS_REQUEST_REPOSITORY)
+	if err != nil {
+		return nil, err
+	}
+	return repo.(accessRequestRepo.IRepository), nil
+}
+
+func defineGroupRepository() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_GROUP_REPOSITORY,
+		Scope: di.Request,
+		Build: func(ctn di.Container) (any, error) {
+			db := ctn.Get(constants.CONTAINER_NAME_DB).(*bun.DB)
+			log.New().Debug("Group repository initialized")
+			return groupRepo.NewRepository(db), nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Group repository destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetGroupRepository(ctn di.Container) (groupRepo.IRepository, error) {
+	repo, err := ctn.SafeGet(constants.CONTAINER_NAME_GROUP_REPOSITORY)
+	if err != nil {
+		return nil, err
+	}
+	return repo.(groupRepo.IRepository), nil
+}
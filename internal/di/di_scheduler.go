@@ -1,11 +1,19 @@
 package di
 
 import (
+	"context"
+
+	"github.com/vukyn/isme/internal/backup"
 	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/domains/activity/chain"
 	activityRepo "github.com/vukyn/isme/internal/domains/activity/repository"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	settingsEntity "github.com/vukyn/isme/internal/domains/settings/entity"
 	settingsRepo "github.com/vukyn/isme/internal/domains/settings/repository"
 	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
+	"github.com/vukyn/isme/internal/health"
+	"github.com/vukyn/isme/internal/metrics"
 
 	"github.com/sarulabs/di/v2"
 	"github.com/uptrace/bun"
@@ -16,47 +24,25 @@ import (
 
 // defineScheduler builds the app-scoped scheduler engine singleton. It is
 // constructed once during the DI build from the App-scoped DB: it registers the
-// four isme jobs (session-revoke, rotation-cleanup, activity-cleanup,
-// database-backup) with their job bodies as closures over freshly built
-// repositories. No WithLocation option
-// is passed, so the engine evaluates schedules in the process's local time —
-// matching the pre-migration engine exactly (parity).
+// five isme jobs (session-revoke, rotation-cleanup, activity-cleanup,
+// database-backup, role-member-expiry) built by SchedulerJobs. No WithLocation
+// option is passed, so the engine evaluates schedules in the process's local
+// time — matching the pre-migration engine exactly (parity).
 func defineScheduler() *di.Def {
 	def := &di.Def{
 		Name:  constants.CONTAINER_NAME_SCHEDULER,
 		Scope: di.App,
 		Build: func(ctn di.Container) (any, error) {
-			db := ctn.Get(constants.CONTAINER_NAME_DB).(*bun.DB)
 			cfg := GetConfig(ctn)
 
-			// build the repositories the job bodies close over, directly from the
-			// App-scoped DB so the engine does not depend on request-scoped containers.
-			userSessionRepository := userSessionRepo.NewRepository(db)
-			settingsRepository := settingsRepo.NewRepository(db)
-			activityRepository := activityRepo.NewRepository(db)
-
 			// NO WithLocation — gocron defaults to local time, matching the original engine.
 			engine, err := pkgScheduler.New(cfg.Scheduler.Enabled)
 			if err != nil {
 				return nil, err
 			}
-
-			engine.Register(pkgScheduler.Job{
-				Key: pkgScheduler.JobKey(settingsEntity.JobKeySessionRevoke),
-				Run: newSessionRevokeRun(userSessionRepository, settingsRepository),
-			})
-			engine.Register(pkgScheduler.Job{
-				Key: pkgScheduler.JobKey(settingsEntity.JobKeyRotationCleanup),
-				Run: newRotationCleanupRun(userSessionRepository, settingsRepository),
-			})
-			engine.Register(pkgScheduler.Job{
-				Key: pkgScheduler.JobKey(settingsEntity.JobKeyActivityCleanup),
-				Run: newActivityCleanupRun(activityRepository, settingsRepository),
-			})
-			engine.Register(pkgScheduler.Job{
-				Key: pkgScheduler.JobKey(settingsEntity.JobKeyDatabaseBackup),
-				Run: newDatabaseBackupRun(db, settingsRepository),
-			})
+			for _, job := range SchedulerJobs(ctn) {
+				engine.Register(job)
+			}
 
 			log.New().Debug("Scheduler initialized")
 			return engine, nil
@@ -71,6 +57,58 @@ func defineScheduler() *di.Def {
 	return def
 }
 
+// SchedulerJobs builds the five isme jobs with their job bodies as closures
+// over repositories built directly from the App-scoped DB, so the engine does
+// not depend on request-scoped containers. ismectl runs the same bodies on
+// demand.
+func SchedulerJobs(ctn di.Container) []pkgScheduler.Job {
+	db := ctn.Get(constants.CONTAINER_NAME_DB).(*bun.DB)
+	cfg := GetConfig(ctn)
+
+	userSessionRepository := userSessionRepo.NewRepository(db)
+	settingsRepository := settingsRepo.NewRepository(db)
+	activityRepository := activityRepo.NewRepository(db)
+	roleRepository := roleRepo.NewRepository(db)
+	activity := activityUsecase.NewUsecase(activityRepository, cfg, GetAuditPublisher(ctn))
+
+	// A nil store keeps backups local; the client is typed-nil when off-host
+	// backups are not configured, so it must not reach the interface as-is.
+	var store backup.Store
+	if client, err := GetBackupStore(ctn); err == nil && client != nil {
+		store = client
+	}
+
+	keys, keysErr := backup.NewKeyring(cfg.Backup.EncryptionKey, cfg.Backup.EncryptionRetiredKeys...)
+	backupRun := newDatabaseBackupRun(db, settingsRepository, store, cfg.Backup.S3Prefix, keys)
+	if keysErr != nil {
+		log.New().Errorf("Database backups disabled: invalid BACKUP_ENCRYPTION_KEY: %v", keysErr)
+		backupRun = refuseDatabaseBackup(keysErr)
+	}
+
+	return []pkgScheduler.Job{
+		{
+			Key: pkgScheduler.JobKey(settingsEntity.JobKeySessionRevoke),
+			Run: instrumentJob(settingsEntity.JobKeySessionRevoke, newSessionRevokeRun(userSessionRepository, settingsRepository)),
+		},
+		{
+			Key: pkgScheduler.JobKey(settingsEntity.JobKeyRotationCleanup),
+			Run: instrumentJob(settingsEntity.JobKeyRotationCleanup, newRotationCleanupRun(userSessionRepository, settingsRepository)),
+		},
+		{
+			Key: pkgScheduler.JobKey(settingsEntity.JobKeyActivityCleanup),
+			Run: instrumentJob(settingsEntity.JobKeyActivityCleanup, newActivityCleanupRun(activityRepository, settingsRepository, GetTxRunner(ctn), chain.SigningKey(cfg))),
+		},
+		{
+			Key: pkgScheduler.JobKey(settingsEntity.JobKeyDatabaseBackup),
+			Run: instrumentJob(settingsEntity.JobKeyDatabaseBackup, backupRun),
+		},
+		{
+			Key: pkgScheduler.JobKey(settingsEntity.JobKeyRoleMemberExpiry),
+			Run: instrumentJob(settingsEntity.JobKeyRoleMemberExpiry, newRoleMemberExpiryRun(roleRepository, userSessionRepository, activity, settingsRepository, GetTxRunner(ctn))),
+		},
+	}
+}
+
 // defineScheduleProvider builds the app-scoped schedule provider singleton. It
 // is the engine's initial-load path at Start: it reads each registered job's
 // persisted schedule from the settings repository (built from the App-scoped DB).
@@ -92,6 +130,12 @@ func defineScheduleProvider() *di.Def {
 	return def
 }
 
+// instrumentJob wraps a job body with its metrics and the last-run record the
+// readiness check reads.
+func instrumentJob(job string, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return health.Scheduler.TrackJob(job, metrics.InstrumentJob(job, run))
+}
+
 func GetScheduler(ctn di.Container) *pkgScheduler.Engine {
 	return ctn.Get(constants.CONTAINER_NAME_SCHEDULER).(*pkgScheduler.Engine)
 }
@@ -1,7 +1,9 @@
 package di
 
 import (
+	"github.com/vukyn/isme/internal/backup"
 	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/objectstore"
 
 	"github.com/sarulabs/di/v2"
 	"github.com/vukyn/kuery/log"
@@ -54,3 +56,43 @@ func GetMedioaClient(ctn di.Container) (*medioa.Client, error) {
 	client, _ := service.(*medioa.Client)
 	return client, nil
 }
+
+// defineBackupStore wires the S3-compatible bucket the database_backup job
+// copies snapshots to. Off-host backups are optional: with BACKUP_S3_BUCKET
+// blank the container holds a typed-nil client and the job stays local-only.
+// A bucket with an incomplete config is logged and treated the same way, so a
+// typo never stops isme from booting.
+func defineBackupStore() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_BACKUP_STORE,
+		Scope: di.App,
+		Build: func(ctn di.Container) (any, error) {
+			cfg := GetConfig(ctn)
+			client, err := backup.NewStore(cfg)
+			if err != nil {
+				log.New().Warnf("Backup store not initialized, backups stay local: %v", err)
+				return (*objectstore.Client)(nil), nil
+			}
+			if client != nil {
+				log.New().Debugf("Backup store initialized for bucket %s", client.Bucket())
+			}
+			return client, nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Backup store destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+// GetBackupStore returns the off-host backup bucket client, or nil when
+// off-host backups are not configured.
+func GetBackupStore(ctn di.Container) (*objectstore.Client, error) {
+	service, err := ctn.SafeGet(constants.CONTAINER_NAME_BACKUP_STORE)
+	if err != nil {
+		return nil, err
+	}
+	client, _ := service.(*objectstore.Client)
+	return client, nil
+}
@@ -0,0 +1,75 @@
+package di
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/tracing"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/vukyn/kuery/log"
+)
+
+const (
+	// tracingExportTimeout bounds one batch POST to the collector.
+	tracingExportTimeout = 10 * time.Second
+	// tracingCloseTimeout bounds how long shutdown waits to flush queued spans.
+	tracingCloseTimeout = 5 * time.Second
+)
+
+// defineTracer installs the global tracer selected by TRACING_EXPORTER. The
+// HTTP middleware, query hook and outbound clients are always wired and stay
+// no-ops while no tracer is installed, so "none" (the default) costs nothing.
+// An unknown exporter is logged and treated as "none" rather than failing boot.
+func defineTracer() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_TRACER,
+		Scope: di.App,
+		Build: func(ctn di.Container) (any, error) {
+			cfg := GetConfig(ctn)
+
+			var exporter tracing.Exporter
+			switch cfg.Tracing.Exporter {
+			case "otlp":
+				exporter = tracing.NewOTLPExporter(
+					cfg.Tracing.OTLPEndpoint,
+					tracing.ParseHeaders(cfg.Tracing.OTLPHeaders),
+					tracingExportTimeout,
+				)
+			case "stdout":
+				exporter = tracing.NewStdoutExporter(os.Stdout)
+			case "", "none":
+				log.New().Debug("Tracing disabled")
+				return (*tracing.Tracer)(nil), nil
+			default:
+				log.New().Errorf("Tracing disabled: unknown exporter %q", cfg.Tracing.Exporter)
+				return (*tracing.Tracer)(nil), nil
+			}
+
+			log.New().Infof("Tracing initialized with %s exporter", cfg.Tracing.Exporter)
+			return tracing.Init(exporter, tracing.Options{
+				ServiceName: cfg.Tracing.ServiceName,
+				SampleRatio: cfg.Tracing.SampleRatio,
+				Processor:   tracing.ProcessorOptions{ExportTimeout: tracingExportTimeout},
+			}), nil
+		},
+		Close: func(obj any) error {
+			if tracer := obj.(*tracing.Tracer); tracer != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), tracingCloseTimeout)
+				defer cancel()
+				if err := tracer.Shutdown(ctx); err != nil {
+					log.New().Warnf("Tracer closed before every span was exported: %v", err)
+				}
+			}
+			log.New().Debug("Tracer destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetTracer(ctn di.Container) *tracing.Tracer {
+	return ctn.Get(constants.CONTAINER_NAME_TRACER).(*tracing.Tracer)
+}
@@ -3,9 +3,11 @@ package di
 import (
 	"github.com/vukyn/isme/internal/config"
 	"github.com/vukyn/isme/internal/constants"
+	accessRequestUsecase "github.com/vukyn/isme/internal/domains/access_request/usecase"
 	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
 	appServiceUsecase "github.com/vukyn/isme/internal/domains/app_service/usecase"
 	authUsecase "github.com/vukyn/isme/internal/domains/auth/usecase"
+	groupUsecase "github.com/vukyn/isme/internal/domains/group/usecase"
 	mediaUsecase "github.com/vukyn/isme/internal/domains/media/usecase"
 	roleUsecase "github.com/vukyn/isme/internal/domains/role/usecase"
 	settingsUsecase "github.com/vukyn/isme/internal/domains/settings/usecase"
@@ -27,6 +29,8 @@ func defineUsecase() []*di.Def {
 		defineUserInvitationUsecase(),
 		defineSettingsUsecase(),
 		defineMediaUsecase(),
+		defineAccessRequestUsecase(),
+		defineGroupUsecase(),
 	}
 }
 
@@ -39,8 +43,9 @@ func defineActivityUsecase() *di.Def {
 			if err != nil {
 				return nil, err
 			}
+			cfg := ctn.Get(constants.CONTAINER_NAME_CONFIG).(*config.Config)
 			log.New().Debug("Activity usecase initialized")
-			return activityUsecase.NewUsecase(activityRepo), nil
+			return activityUsecase.NewUsecase(activityRepo, cfg, GetAuditPublisher(ctn)), nil
 		},
 		Close: func(obj any) error {
 			log.New().Debug("Activity usecase destroyed")
@@ -121,9 +126,13 @@ func defineAppServiceUsecase() *di.Def {
 			if err != nil {
 				return nil, err
 			}
+			activityUsecase, err := GetActivityUsecase(ctn)
+			if err != nil {
+				return nil, err
+			}
 			cfg := ctn.Get(constants.CONTAINER_NAME_CONFIG).(*config.Config)
 			log.New().Debug("App service usecase initialized")
-			return appServiceUsecase.NewUsecase(appServiceRepo, userRepo, roleUsecase, cfg), nil
+			return appServiceUsecase.NewUsecase(appServiceRepo, userRepo, roleUsecase, activityUsecase, GetTxRunner(ctn), cfg), nil
 		},
 		Close: func(obj any) error {
 			log.New().Debug("App service usecase destroyed")
@@ -158,8 +167,12 @@ func defineUserUsecase() *di.Def {
 			if err != nil {
 				return nil, err
 			}
+			activityUsecase, err := GetActivityUsecase(ctn)
+			if err != nil {
+				return nil, err
+			}
 			log.New().Debug("User usecase initialized")
-			return userUsecase.NewUsecase(userRepo, userSessionRepo, roleRepo), nil
+			return userUsecase.NewUsecase(userRepo, userSessionRepo, roleRepo, activityUsecase, GetTxRunner(ctn)), nil
 		},
 		Close: func(obj any) error {
 			log.New().Debug("User usecase destroyed")
@@ -194,8 +207,12 @@ func defineRoleUsecase() *di.Def {
 			if err != nil {
 				return nil, err
 			}
+			activityUsecase, err := GetActivityUsecase(ctn)
+			if err != nil {
+				return nil, err
+			}
 			log.New().Debug("Role usecase initialized")
-			return roleUsecase.NewUsecase(roleRepo, userRepo, appServiceRepo), nil
+			return roleUsecase.NewUsecase(roleRepo, userRepo, appServiceRepo, activityUsecase, GetTxRunner(ctn)), nil
 		},
 		Close: func(obj any) error {
 			log.New().Debug("Role usecase destroyed")
@@ -240,7 +257,7 @@ func defineUserInvitationUsecase() *di.Def {
 				return nil, err
 			}
 			log.New().Debug("User invitation usecase initialized")
-			return userInvitationUsecase.NewUsecase(cfg, userInvitationRepo, userRepo, roleRepo, appServiceRepo, activityUsecase), nil
+			return userInvitationUsecase.NewUsecase(cfg, userInvitationRepo, userRepo, roleRepo, appServiceRepo, activityUsecase, GetTxRunner(ctn)), nil
 		},
 		Close: func(obj any) error {
 			log.New().Debug("User invitation usecase destroyed")
@@ -267,10 +284,14 @@ func defineSettingsUsecase() *di.Def {
 			if err != nil {
 				return nil, err
 			}
+			activityUsecase, err := GetActivityUsecase(ctn)
+			if err != nil {
+				return nil, err
+			}
 			// the app-scoped scheduler engine singleton acts as the IReloader
 			reloader := ctn.Get(constants.CONTAINER_NAME_SCHEDULER).(*pkgScheduler.Engine)
 			log.New().Debug("Settings usecase initialized")
-			return settingsUsecase.NewUsecase(settingsRepo, reloader), nil
+			return settingsUsecase.NewUsecase(settingsRepo, reloader, activityUsecase, GetTxRunner(ctn)), nil
 		},
 		Close: func(obj any) error {
 			log.New().Debug("Settings usecase destroyed")
@@ -318,3 +339,83 @@ func GetMediaUsecase(ctn di.Container) (mediaUsecase.IUseCase, error) {
 	}
 	return uc.(mediaUsecase.IUseCase), nil
 }
+
+func defineAccessRequestUsecase() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_ACCESS_REQUEST_USECASE,
+		Scope: di.Request,
+		Build: func(ctn di.Container) (any, error) {
+			accessRequestRepo, err := GetAccessRequestRepository(ctn)
+			if err != nil {
+				return nil, err
+			}
+			roleRepo, err := GetRoleRepository(ctn)
+			if err != nil {
+				return nil, err
+			}
+			appServiceRepo, err := GetAppServiceRepository(ctn)
+			if err != nil {
+				return nil, err
+			}
+			activityUsecase, err := GetActivityUsecase(ctn)
+			if err != nil {
+				return nil, err
+			}
+			log.New().Debug("Access request usecase initialized")
+			return accessRequestUsecase.NewUsecase(accessRequestRepo, roleRepo, appServiceRepo, activityUsecase, GetTxRunner(ctn)), nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Access request usecase destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetAccessRequestUsecase(ctn di.Container) (accessRequestUsecase.IUseCase, error) {
+	uc, err := ctn.SafeGet(constants.CONTAINER_NAME_ACCESS_REQUEST_USECASE)
+	if err != nil {
+		return nil, err
+	}
+	return uc.(accessRequestUsecase.IUseCase), nil
+}
+
+func defineGroupUsecase() *di.Def {
+	def := &di.Def{
+		Name:  constants.CONTAINER_NAME_GROUP_USECASE,
+		Scope: di.Request,
+		Build: func(ctn di.Container) (any, error) {
+			groupRepo, err := GetGroupRepository(ctn)
+			if err != nil {
+				return nil, err
+			}
+			userRepo, err := GetUserRepository(ctn)
+			if err != nil {
+				return nil, err
+			}
+			roleRepo, err := GetRoleRepository(ctn)
+			if err != nil {
+				return nil, err
+			}
+			activityUsecase, err := GetActivityUsecase(ctn)
+			if err != nil {
+				return nil, err
+			}
+			log.New().Debug("Group usecase initialized")
+			return groupUsecase.NewUsecase(groupRepo, userRepo, roleRepo, activityUsecase, GetTxRunner(ctn)), nil
+		},
+		Close: func(obj any) error {
+			log.New().Debug("Group usecase destroyed")
+			return nil
+		},
+	}
+	return def
+}
+
+func GetGroupUsecase(ctn di.Container) (groupUsecase.IUseCase, error) {
+	uc, err := ctn.SafeGet(constants.CONTAINER_NAME_GROUP_USECASE)
+	if err != nil {
+		return nil, err
+	}
+	return uc.(groupUsecase.IUseCase), nil
+}
@@ -8,13 +8,24 @@ import (
 	"sort"
 	"time"
 
+	"github.com/vukyn/isme/internal/backup"
 	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/domains/activity/chain"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityEntity "github.com/vukyn/isme/internal/domains/activity/entity"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
 	activityRepo "github.com/vukyn/isme/internal/domains/activity/repository"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	settingsEntity "github.com/vukyn/isme/internal/domains/settings/entity"
 	settingsRepo "github.com/vukyn/isme/internal/domains/settings/repository"
 	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/vukyn/kuery/cryp"
 	pkgScheduler "github.com/vukyn/kuery/scheduler"
 
 	"github.com/vukyn/kuery/log"
@@ -32,6 +43,12 @@ type activityCleanupParams struct {
 	RetentionDays int64 `json:"retention_days"`
 }
 
+// Snapshot formats reported in the database-backup job's last_result.
+const (
+	backupFormatSQLite   = "sqlite"
+	backupFormatPostgres = "pgdump"
+)
+
 // databaseBackupParams mirrors the params JSON of the database-backup job.
 // Retention is a COUNT of backup files to keep (not a time window), so pruning
 // deletes the oldest files past that count.
@@ -144,9 +161,16 @@ func newRotationCleanupRun(
 // record the run. The retention window is read FRESH on each run, so a
 // retention-only change takes effect on the next run without a scheduler
 // reload. Errors are logged, never panicked.
+//
+// Pruning removes the oldest links of the audit hash chain, so the delete and
+// a checkpoint of the pruned seq range — signed with chainKey — commit in one
+// transaction: the verifier resumes from the checkpoint instead of reporting
+// the pruned prefix as missing.
 func newActivityCleanupRun(
 	activityRepository activityRepo.IRepository,
 	settingsRepository settingsRepo.IRepository,
+	txRunner transaction.Runner,
+	chainKey []byte,
 ) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
 		now := time.Now().UTC()
@@ -163,12 +187,39 @@ func newActivityCleanupRun(
 			}
 		}
 		before := activityCutoff(now, params.RetentionDays)
-		pruned, err := activityRepository.PruneBefore(ctx, before)
+
+		var (
+			pruned     int64
+			checkpoint activityEntity.ActivityCheckpoint
+		)
+		err = txRunner.Run(ctx, func(ctx context.Context) error {
+			chainRange, err := activityRepository.ChainRangeBefore(ctx, before)
+			if err != nil {
+				return err
+			}
+			pruned, err = activityRepository.PruneBefore(ctx, before)
+			if err != nil {
+				return err
+			}
+			if chainRange.RowCount == 0 {
+				return nil
+			}
+			checkpoint = activityEntity.ActivityCheckpoint{
+				ID:           cryp.ULID(),
+				FromSeq:      chainRange.FromSeq,
+				ToSeq:        chainRange.ToSeq,
+				RowCount:     chainRange.RowCount,
+				LastHash:     chainRange.LastHash,
+				PrunedBefore: chain.Timestamp(before),
+			}
+			checkpoint.Signature = chain.Sign(chainKey, checkpoint)
+			return activityRepository.CreateCheckpoint(ctx, checkpoint)
+		})
 		if err != nil {
 			log.New().Errorf("Scheduler: prune activity events failed: %v", err)
 			return nil
 		}
-		result, err := json.Marshal(map[string]int64{"pruned": pruned})
+		result, err := json.Marshal(map[string]int64{"pruned": pruned, "checkpoint_to_seq": checkpoint.ToSeq})
 		if err != nil {
 			log.New().Errorf("Scheduler: marshal activity-cleanup result failed: %v", err)
 			return nil
@@ -182,14 +233,107 @@ func newActivityCleanupRun(
 	}
 }
 
+// newRoleMemberExpiryRun returns the role-member-expiry job body: delete the
+// role assignments whose expires_at has passed, audit each removal and revoke
+// every session of the affected users, so tokens minted while the role was
+// held stop working now rather than at their own expiry. Token building
+// already ignores expired rows; this job is what takes the issued tokens back.
+// All of it commits in one transaction, so an assignment is never removed
+// without its audit row and revocation. Errors are logged, never panicked.
+func newRoleMemberExpiryRun(
+	roleRepository roleRepo.IRepository,
+	userSessionRepository userSessionRepo.IRepository,
+	activity activityUsecase.IUseCase,
+	settingsRepository settingsRepo.IRepository,
+	txRunner transaction.Runner,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+
+		var expired, revokedUsers int64
+		err := txRunner.Run(ctx, func(ctx context.Context) error {
+			userRoles, err := roleRepository.DeleteExpiredMembers(ctx, now)
+			if err != nil {
+				return err
+			}
+			expired = int64(len(userRoles))
+
+			revoked := map[string]bool{}
+			for _, userRole := range userRoles {
+				// no caller on the context: the audit row records the system actor
+				meta := map[string]any{}
+				if userRole.AppServiceID != nil {
+					meta["app_service_id"] = *userRole.AppServiceID
+				}
+				err := activity.RecordAudit(ctx, activityModels.AuditEntry{
+					Type:       activityConstants.ActivityTypeRoleMemberExpired,
+					TargetType: activityConstants.TargetTypeRole,
+					TargetID:   userRole.RoleID,
+					Before:     expiredMemberSnapshot(userRole.UserID, userRole.StartsAt, userRole.ExpiresAt),
+					Meta:       meta,
+				})
+				if err != nil {
+					return err
+				}
+				if revoked[userRole.UserID] {
+					continue
+				}
+				if err := userSessionRepository.InactiveAllUserSession(ctx, userRole.UserID); err != nil {
+					return err
+				}
+				revoked[userRole.UserID] = true
+			}
+			revokedUsers = int64(len(revoked))
+			return nil
+		})
+		if err != nil {
+			log.New().Errorf("Scheduler: expire role members failed: %v", err)
+			return nil
+		}
+		result, err := json.Marshal(map[string]int64{"expired": expired, "revoked_users": revokedUsers})
+		if err != nil {
+			log.New().Errorf("Scheduler: marshal role-member-expiry result failed: %v", err)
+			return nil
+		}
+		if err := settingsRepository.RecordScheduleRun(ctx, settingsEntity.JobKeyRoleMemberExpiry, now, string(result)); err != nil {
+			log.New().Errorf("Scheduler: record role-member-expiry run failed: %v", err)
+			// the expiry still happened — fall through to log it
+		}
+		log.New().Infof("Role member expiry run complete: %d assignment(s) expired, %d user(s) signed out", expired, revokedUsers)
+		return nil
+	}
+}
+
+// expiredMemberSnapshot is the audit view of an expired assignment, shaped
+// like the add-members request that granted it.
+func expiredMemberSnapshot(userID string, startsAt, expiresAt *time.Time) roleModels.AddMembersRequest {
+	snapshot := roleModels.AddMembersRequest{UserIDs: []string{userID}}
+	if startsAt != nil {
+		snapshot.StartsAt = startsAt.UTC().Format(time.RFC3339)
+	}
+	if expiresAt != nil {
+		snapshot.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+	}
+	return snapshot
+}
+
 // newDatabaseBackupRun returns the database-backup job body: snapshot the
-// file-based SQLite database via VACUUM INTO into db/backups/, then prune the
-// oldest backups beyond the configured retain count and record the run. The
-// retain count is read FRESH on each run. ALL errors are logged, never
-// panicked, so a failed backup does not crash the process or kill the schedule.
+// file-based SQLite database via VACUUM INTO into db/backups/ — or, on
+// Postgres, write a logical dump of every table there (see backup.Dump) — then
+// prune the oldest backups beyond the configured retain count and record the
+// run. With an off-host store the snapshot is also uploaded under prefix and
+// the remote copies are pruned to the same retain count; a store failure is
+// recorded in the result but never undoes or fails the local backup. With keys
+// the snapshot is sealed (see backup.Keyring.Seal) before it is pruned or
+// uploaded, and no plaintext copy is kept. The retain count is read FRESH on
+// each run. ALL errors are logged, never panicked, so a failed backup does not
+// crash the process or kill the schedule.
 func newDatabaseBackupRun(
 	db *bun.DB,
 	settingsRepository settingsRepo.IRepository,
+	store backup.Store,
+	prefix string,
+	keys *backup.Keyring,
 ) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
 		now := time.Now().UTC()
@@ -217,16 +361,36 @@ func newDatabaseBackupRun(
 			return nil
 		}
 
+		format := backupFormatSQLite
 		target := filepath.Join(backupDir, "app-"+now.Format("20060102-150405")+".db")
-		// Try the bound-param form first. The path is a server-generated
-		// timestamp (not user input), so inlining it into the SQL string on
-		// fallback is injection-safe.
-		if _, err := db.ExecContext(ctx, "VACUUM INTO ?", target); err != nil {
+		if db.Dialect().Name() == dialect.PG {
+			format = backupFormatPostgres
+			target = filepath.Join(backupDir, "app-"+now.Format("20060102-150405")+".dump")
+			if _, err := backup.Dump(ctx, db, target, now); err != nil {
+				log.New().Errorf("Scheduler: database backup (logical dump) failed: %v", err)
+				return nil
+			}
+		} else if _, err := db.ExecContext(ctx, "VACUUM INTO ?", target); err != nil {
+			// Try the bound-param form first. The path is a server-generated
+			// timestamp (not user input), so inlining it into the SQL string
+			// on fallback is injection-safe.
 			if _, err := db.ExecContext(ctx, "VACUUM INTO '"+target+"'"); err != nil {
 				log.New().Errorf("Scheduler: database backup (VACUUM INTO) failed: %v", err)
 				return nil
 			}
 		}
+		var manifest backup.Manifest
+		if keys != nil {
+			sealed, sealedManifest, err := keys.Seal(ctx, target, now)
+			if err != nil {
+				// never keep a snapshot the operator asked to have encrypted
+				// in the clear
+				os.Remove(target)
+				log.New().Errorf("Scheduler: encrypt database backup failed: %v", err)
+				return nil
+			}
+			target, manifest = sealed, sealedManifest
+		}
 
 		var bytes int64
 		if info, err := os.Stat(target); err == nil {
@@ -239,12 +403,25 @@ func newDatabaseBackupRun(
 			return nil
 		}
 
-		result, err := json.Marshal(map[string]any{
+		summary := map[string]any{
 			"backup_path": target,
+			"format":      format,
 			"kept":        kept,
 			"deleted":     deleted,
 			"bytes":       bytes,
-		})
+			"encrypted":   keys != nil,
+		}
+		if keys != nil {
+			summary["key_id"] = manifest.KeyID
+			summary["sha256"] = manifest.CiphertextSHA256
+		}
+		if store != nil {
+			for field, value := range uploadBackup(ctx, store, prefix, target, int(params.RetainCount)) {
+				summary[field] = value
+			}
+		}
+
+		result, err := json.Marshal(summary)
 		if err != nil {
 			log.New().Errorf("Scheduler: marshal database-backup result failed: %v", err)
 			return nil
@@ -258,10 +435,43 @@ func newDatabaseBackupRun(
 	}
 }
 
+// refuseDatabaseBackup replaces the database-backup job body when
+// BACKUP_ENCRYPTION_KEY is set but unusable: writing plaintext snapshots the
+// operator asked to have encrypted is worse than writing none.
+func refuseDatabaseBackup(keyErr error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		log.New().Errorf("Scheduler: database backup skipped, BACKUP_ENCRYPTION_KEY is invalid: %v", keyErr)
+		return nil
+	}
+}
+
+// uploadBackup copies the snapshot at target to the off-host store and prunes
+// the remote copies, returning the remote_* fields of the run result.
+func uploadBackup(ctx context.Context, store backup.Store, prefix, target string, retainCount int) map[string]any {
+	key, err := backup.Upload(ctx, store, prefix, target)
+	if err != nil {
+		log.New().Errorf("Scheduler: upload database backup failed: %v", err)
+		return map[string]any{"remote_uploaded": false, "remote_error": err.Error()}
+	}
+	fields := map[string]any{"remote_uploaded": true, "remote_key": key}
+
+	deleted, kept, err := backup.PruneRemote(ctx, store, prefix, retainCount)
+	if err != nil {
+		log.New().Errorf("Scheduler: prune remote backups failed: %v", err)
+		fields["remote_error"] = err.Error()
+		return fields
+	}
+	fields["remote_kept"] = kept
+	fields["remote_deleted"] = deleted
+	log.New().Infof("Database backup uploaded: %s (kept %d, pruned %d)", key, kept, deleted)
+	return fields
+}
+
 // pruneBackups deletes the oldest backup files in backupDir, keeping at most
-// retainCount of the newest. Backups are named app-<timestamp>.db, so a
-// descending name sort orders them newest-first; everything past retainCount is
-// deleted. Returns the count deleted and the count kept.
+// retainCount of the newest. Backups are named app-<timestamp>.db, or .dump on
+// Postgres (.enc appended when encrypted), so a descending name sort orders
+// them newest-first; everything past retainCount is deleted, together with an
+// encrypted backup's manifest. Returns the count deleted and the count kept.
 func pruneBackups(backupDir string, retainCount int) (deleted int, kept int, err error) {
 	entries, err := os.ReadDir(backupDir)
 	if err != nil {
@@ -273,7 +483,7 @@ func pruneBackups(backupDir string, retainCount int) (deleted int, kept int, err
 			continue
 		}
 		name := entry.Name()
-		if matched, _ := filepath.Match("app-*.db", name); matched {
+		if backup.IsJobSnapshot(name) {
 			names = append(names, name)
 		}
 	}
@@ -292,6 +502,11 @@ func pruneBackups(backupDir string, retainCount int) (deleted int, kept int, err
 			return deleted, retainCount, removeErr
 		}
 		deleted++
+		if backup.IsEncrypted(name) {
+			if removeErr := os.Remove(filepath.Join(backupDir, name+backup.ManifestSuffix)); removeErr != nil && !os.IsNotExist(removeErr) {
+				return deleted, retainCount, removeErr
+			}
+		}
 	}
 	return deleted, retainCount, nil
 }
@@ -3,15 +3,30 @@ package di
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"testing"
 	"time"
 
 	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/backup"
+	"github.com/vukyn/isme/internal/config"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityEntity "github.com/vukyn/isme/internal/domains/activity/entity"
+	activityRepo "github.com/vukyn/isme/internal/domains/activity/repository"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+	roleEntity "github.com/vukyn/isme/internal/domains/role/entity"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	settingsEntity "github.com/vukyn/isme/internal/domains/settings/entity"
 	settingsRepo "github.com/vukyn/isme/internal/domains/settings/repository"
+	userSessionConstants "github.com/vukyn/isme/internal/domains/user_session/constants"
+	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
+	"github.com/vukyn/isme/internal/objectstore/objectstoretest"
+	"github.com/vukyn/isme/internal/transaction"
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/sqlitedialect"
@@ -143,6 +158,36 @@ func TestPruneBackupsIgnoresNonBackupFiles(t *testing.T) {
 	}
 }
 
+// Encrypted backups are pruned alongside plaintext ones, manifests with them.
+func TestPruneBackupsRemovesManifests(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"app-20260101-000000.db",
+		"app-20260102-000000.db.enc", "app-20260102-000000.db.enc.json",
+		"app-20260103-000000.db.enc", "app-20260103-000000.db.enc.json",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, kept, err := pruneBackups(dir, 1)
+	if err != nil {
+		t.Fatalf("pruneBackups: %v", err)
+	}
+	if deleted != 2 || kept != 1 {
+		t.Fatalf("deleted/kept = %d/%d, want 2/1", deleted, kept)
+	}
+	entries, _ := os.ReadDir(dir)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"app-20260103-000000.db.enc", "app-20260103-000000.db.enc.json"}; !slices.Equal(names, want) {
+		t.Fatalf("left %v, want %v", names, want)
+	}
+}
+
 // newTestDB opens an in-memory SQLite database and applies every migration.
 func newTestDB(t *testing.T) *bun.DB {
 	t.Helper()
@@ -161,12 +206,12 @@ func newTestDB(t *testing.T) *bun.DB {
 	return db
 }
 
-// The migration must seed exactly the four job rows the scheduler reads, so a
+// The migration must seed exactly the five job rows the scheduler reads, so a
 // Reload/Get can never silently target a non-existent row. The job-key strings
 // are a single source of truth (settings entity consts).
 func TestJobKeysAreConsistentWithMigration(t *testing.T) {
 	db := newTestDB(t)
-	for _, jobKey := range []string{settingsEntity.JobKeySessionRevoke, settingsEntity.JobKeyRotationCleanup, settingsEntity.JobKeyActivityCleanup, settingsEntity.JobKeyDatabaseBackup} {
+	for _, jobKey := range []string{settingsEntity.JobKeySessionRevoke, settingsEntity.JobKeyRotationCleanup, settingsEntity.JobKeyActivityCleanup, settingsEntity.JobKeyDatabaseBackup, settingsEntity.JobKeyRoleMemberExpiry} {
 		var count int
 		row := db.QueryRow("SELECT COUNT(*) FROM schedule_config WHERE job_key = ?", jobKey)
 		if err := row.Scan(&count); err != nil {
@@ -200,3 +245,281 @@ func TestScheduleProviderReportsSeededJobsDisabled(t *testing.T) {
 		}
 	}
 }
+
+// Role-member expiry is the one job seeded enabled: an expiry nobody enforces
+// would leave the holder's sessions live.
+func TestScheduleProviderReportsRoleMemberExpiryEnabled(t *testing.T) {
+	db := newTestDB(t)
+	provider := newScheduleProvider(settingsRepo.NewRepository(db))
+
+	enabled, schedule, err := provider.Load(context.Background(), pkgScheduler.JobKey(settingsEntity.JobKeyRoleMemberExpiry))
+	if err != nil {
+		t.Fatalf("provider.Load: %v", err)
+	}
+	if !enabled {
+		t.Fatal("expected role_member_expiry seeded enabled")
+	}
+	if schedule != pkgScheduler.Cron("*/5 * * * *") {
+		t.Fatalf("schedule = %v, want every five minutes", schedule)
+	}
+}
+
+// The role-member-expiry run removes only the assignments past expires_at,
+// audits each removal and signs the affected user out everywhere, leaving
+// permanent and still-running assignments (and their holders' sessions) alone.
+func TestRoleMemberExpiryRemovesExpiredAndRevokesSessions(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	settingsRepository := settingsRepo.NewRepository(db)
+	activityRepository := activityRepo.NewRepository(db)
+
+	now := time.Now().UTC()
+	expired, running := now.Add(-time.Minute), now.Add(time.Hour)
+	appID := "app_isme"
+	userRoles := []roleEntity.UserRole{
+		{ID: "ur-expired", UserID: "user-1", RoleID: "rol_admin", AppServiceID: &appID, ExpiresAt: &expired},
+		{ID: "ur-permanent", UserID: "user-1", RoleID: "rol_viewer", AppServiceID: &appID},
+		{ID: "ur-running", UserID: "user-2", RoleID: "rol_admin", AppServiceID: &appID, ExpiresAt: &running},
+	}
+	if _, err := db.NewInsert().Model(&userRoles).Exec(ctx); err != nil {
+		t.Fatalf("insert user_roles: %v", err)
+	}
+	for _, session := range []struct{ id, userID string }{{"ses-1", "user-1"}, {"ses-2", "user-2"}} {
+		if _, err := db.ExecContext(ctx, `INSERT INTO user_sessions (id, user_id, email, status, client_ip) VALUES (?, ?, ?, ?, '127.0.0.1')`,
+			session.id, session.userID, session.userID+"@example.com", userSessionConstants.UserSessionStatusActive); err != nil {
+			t.Fatalf("insert session %s: %v", session.id, err)
+		}
+	}
+
+	run := newRoleMemberExpiryRun(
+		roleRepo.NewRepository(db),
+		userSessionRepo.NewRepository(db),
+		activityUsecase.NewUsecase(activityRepository, &config.Config{}, sink.NoopPublisher{}),
+		settingsRepository,
+		transaction.NewRunner(db),
+	)
+	if err := run(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var remaining []string
+	if err := db.NewSelect().Model((*roleEntity.UserRole)(nil)).Column("id").Order("id ASC").Scan(ctx, &remaining); err != nil {
+		t.Fatalf("list user_roles: %v", err)
+	}
+	if want := []string{"ur-permanent", "ur-running"}; !slices.Equal(remaining, want) {
+		t.Fatalf("remaining assignments = %v, want %v", remaining, want)
+	}
+
+	statuses := map[string]int{}
+	rows, err := db.QueryContext(ctx, `SELECT id, status FROM user_sessions`)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id     string
+			status int
+		)
+		if err := rows.Scan(&id, &status); err != nil {
+			t.Fatalf("scan session: %v", err)
+		}
+		statuses[id] = status
+	}
+	if statuses["ses-1"] != userSessionConstants.UserSessionStatusInactive || statuses["ses-2"] != userSessionConstants.UserSessionStatusActive {
+		t.Fatalf("session statuses = %v, want ses-1 revoked and ses-2 active", statuses)
+	}
+
+	var audited int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM activity_events WHERE type = ? AND target_id = 'rol_admin'`, activityConstants.ActivityTypeRoleMemberExpired).Scan(&audited); err != nil {
+		t.Fatalf("count audit rows: %v", err)
+	}
+	if audited != 1 {
+		t.Fatalf("role_member_expired audit rows = %d, want 1", audited)
+	}
+
+	config, err := settingsRepository.GetSchedule(ctx, settingsEntity.JobKeyRoleMemberExpiry)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	if config.LastResult == nil || *config.LastResult != `{"expired":1,"revoked_users":1}` {
+		t.Fatalf("last_result = %v, want one expiry and one signed-out user", config.LastResult)
+	}
+}
+
+// The activity-cleanup run prunes the expired prefix of the audit chain and, in
+// the same transaction, writes a signed checkpoint the verifier resumes from —
+// so retention leaves the chain verifiable.
+func TestActivityCleanupWritesSignedCheckpoint(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	activityRepository := activityRepo.NewRepository(db)
+	cfg := &config.Config{}
+	cfg.Audit.ChainKey = "chain-key"
+
+	now := time.Now().UTC()
+	for _, id := range []string{"old-1", "old-2", "recent"} {
+		if err := activityRepository.Create(ctx, activityEntity.ActivityEvent{ID: id, UserID: "user-1", Type: activityConstants.ActivityTypeSignIn}); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	// backdating breaks these rows' hashes, but they are exactly the ones pruned
+	// (the seeded retention is 90 days)
+	if _, err := db.NewUpdate().
+		Model((*activityEntity.ActivityEvent)(nil)).
+		Set("created_at = ?", now.Add(-100*24*time.Hour)).
+		Where("id IN (?)", bun.In([]string{"old-1", "old-2"})).
+		Exec(ctx); err != nil {
+		t.Fatalf("backdate: %v", err)
+	}
+
+	run := newActivityCleanupRun(activityRepository, settingsRepo.NewRepository(db), transaction.NewRunner(db), []byte(cfg.Audit.ChainKey))
+	if err := run(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	checkpoints, err := activityRepository.ListCheckpoints(ctx)
+	if err != nil {
+		t.Fatalf("ListCheckpoints: %v", err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].FromSeq != 1 || checkpoints[0].ToSeq != 2 {
+		t.Fatalf("expected one checkpoint covering seq 1-2, got %+v", checkpoints)
+	}
+
+	report, err := activityUsecase.NewUsecase(activityRepository, cfg, sink.NoopPublisher{}).VerifyChain(ctx)
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !report.Valid || report.PrunedEvents != 2 || report.CheckedEvents != 1 {
+		t.Fatalf("expected the pruned chain to verify, got %+v", report)
+	}
+}
+
+// With an off-host store the backup run uploads the snapshot, prunes the
+// remote copies to retain_count and records the object key in last_result.
+func TestDatabaseBackupUploadsToStore(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	settingsRepository := settingsRepo.NewRepository(db)
+	if _, err := db.ExecContext(ctx, `UPDATE schedule_config SET params = '{"retain_count":1}' WHERE job_key = ?`, settingsEntity.JobKeyDatabaseBackup); err != nil {
+		t.Fatalf("set retain_count: %v", err)
+	}
+	t.Chdir(t.TempDir())
+
+	server := objectstoretest.New(t)
+	server.Put("isme-backups/app-20200101-000000.db", []byte("stale"), time.Now())
+	server.Put("isme-backups/README.txt", []byte("keep me"), time.Now())
+
+	run := newDatabaseBackupRun(db, settingsRepository, server.Client(t), "isme-backups/", nil)
+	if err := run(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	config, err := settingsRepository.GetSchedule(ctx, settingsEntity.JobKeyDatabaseBackup)
+	if err != nil || config.LastResult == nil {
+		t.Fatalf("GetSchedule: %v (last_result %v)", err, config.LastResult)
+	}
+	var result struct {
+		BackupPath     string `json:"backup_path"`
+		RemoteUploaded bool   `json:"remote_uploaded"`
+		RemoteKey      string `json:"remote_key"`
+		RemoteDeleted  int    `json:"remote_deleted"`
+	}
+	if err := json.Unmarshal([]byte(*config.LastResult), &result); err != nil {
+		t.Fatalf("last_result: %v", err)
+	}
+	if !result.RemoteUploaded || result.RemoteKey != "isme-backups/"+filepath.Base(result.BackupPath) || result.RemoteDeleted != 1 {
+		t.Fatalf("unexpected last_result %s", *config.LastResult)
+	}
+	if want := []string{"isme-backups/README.txt", result.RemoteKey}; !slices.Equal(server.Keys(), want) {
+		t.Fatalf("remote keys = %v, want %v", server.Keys(), want)
+	}
+}
+
+// An unreachable store is recorded in last_result but the local backup stands.
+func TestDatabaseBackupSurvivesStoreFailure(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	settingsRepository := settingsRepo.NewRepository(db)
+	t.Chdir(t.TempDir())
+
+	server := objectstoretest.New(t)
+	store := server.Client(t)
+	server.Close()
+
+	if err := newDatabaseBackupRun(db, settingsRepository, store, "isme-backups/", nil)(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	config, err := settingsRepository.GetSchedule(ctx, settingsEntity.JobKeyDatabaseBackup)
+	if err != nil || config.LastResult == nil {
+		t.Fatalf("GetSchedule: %v (last_result %v)", err, config.LastResult)
+	}
+	var result struct {
+		BackupPath     string `json:"backup_path"`
+		RemoteUploaded bool   `json:"remote_uploaded"`
+		RemoteError    string `json:"remote_error"`
+	}
+	if err := json.Unmarshal([]byte(*config.LastResult), &result); err != nil {
+		t.Fatalf("last_result: %v", err)
+	}
+	if result.RemoteUploaded || result.RemoteError == "" {
+		t.Fatalf("expected a recorded upload failure, got %s", *config.LastResult)
+	}
+	if _, err := os.Stat(result.BackupPath); err != nil {
+		t.Fatalf("local backup missing after a store failure: %v", err)
+	}
+}
+
+// With a backup key the job keeps only the sealed snapshot and its manifest,
+// and uploads both.
+func TestDatabaseBackupEncrypts(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	settingsRepository := settingsRepo.NewRepository(db)
+	t.Chdir(t.TempDir())
+
+	keys, err := backup.NewKeyring("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := objectstoretest.New(t)
+	if err := newDatabaseBackupRun(db, settingsRepository, server.Client(t), "isme-backups/", keys)(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	config, err := settingsRepository.GetSchedule(ctx, settingsEntity.JobKeyDatabaseBackup)
+	if err != nil || config.LastResult == nil {
+		t.Fatalf("GetSchedule: %v (last_result %v)", err, config.LastResult)
+	}
+	var result struct {
+		BackupPath string `json:"backup_path"`
+		Encrypted  bool   `json:"encrypted"`
+		KeyID      string `json:"key_id"`
+		RemoteKey  string `json:"remote_key"`
+	}
+	if err := json.Unmarshal([]byte(*config.LastResult), &result); err != nil {
+		t.Fatalf("last_result: %v", err)
+	}
+	if !result.Encrypted || result.KeyID != keys.KeyID() || !backup.IsEncrypted(result.BackupPath) {
+		t.Fatalf("unexpected last_result %s", *config.LastResult)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(result.BackupPath))
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	base := filepath.Base(result.BackupPath)
+	if want := []string{base, base + backup.ManifestSuffix}; !slices.Equal(names, want) {
+		t.Fatalf("backup dir holds %v, want only %v", names, want)
+	}
+	if want := []string{result.RemoteKey, result.RemoteKey + backup.ManifestSuffix}; !slices.Equal(server.Keys(), want) {
+		t.Fatalf("remote keys = %v, want %v", server.Keys(), want)
+	}
+
+	plain := filepath.Join(t.TempDir(), "plain.db")
+	if _, err := keys.Open(result.BackupPath, plain); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+}
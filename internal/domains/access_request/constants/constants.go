@@ -0,0 +1,22 @@
+package constants
+
+// Access request status
+const (
+	AccessRequestStatusPending   = 1
+	AccessRequestStatusApproved  = 2
+	AccessRequestStatusDenied    = 3
+	AccessRequestStatusCancelled = 4
+)
+
+// Bounds on the membership an access request asks for. Approval grants the
+// role for DurationHours from the moment it is approved.
+const (
+	MinDurationHours = 1
+	MaxDurationHours = 30 * 24
+)
+
+// Length limits for the free-text fields
+const (
+	MaxJustificationLength = 500
+	MaxDecisionNoteLength  = 500
+)
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// AccessRequest is a user's request for a role in an app for a bounded time.
+// Decided* are set when an approver approves or denies it (or by the requester
+// on cancel); ExpiresAt is the end of the membership an approval created.
+type AccessRequest struct {
+	bun.BaseModel `bun:"table:access_requests,alias:acr"`
+	ID            string    `bun:"id,pk,notnull"`
+	UserID        string    `bun:"user_id,notnull"`
+	AppServiceID  string    `bun:"app_service_id,notnull"`
+	RoleID        string    `bun:"role_id,notnull"`
+	Justification string    `bun:"justification,notnull"`
+	DurationHours int32     `bun:"duration_hours,notnull"`
+	Status        int32     `bun:"status,notnull,default:1"`
+	DecidedBy     string    `bun:"decided_by,nullzero"`
+	DecidedAt     time.Time `bun:"decided_at,nullzero"`
+	DecisionNote  string    `bun:"decision_note,nullzero"`
+	ExpiresAt     time.Time `bun:"expires_at,nullzero"`
+	CreatedAt     time.Time `bun:"created_at,default:current_timestamp"`
+	UpdatedAt     time.Time `bun:"updated_at,default:current_timestamp"`
+}
+
+// === Hooks ===
+
+func (a *AccessRequest) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch q := query.(type) {
+	case *bun.InsertQuery:
+		a.CreatedAt = time.Now().UTC()
+	case *bun.UpdateQuery:
+		q.Column("updated_at")
+		a.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
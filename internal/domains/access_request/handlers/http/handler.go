@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+	"github.com/vukyn/isme/internal/tracing"
+
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgHttp "github.com/vukyn/kuery/http/fiber"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CreateAccessRequest(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	createRequest := models.CreateRequest{}
+	if err := c.BodyParser(&createRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	createResponse, err := uc.Create(tracing.NewContextFromFiberCtx(c), createRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, createResponse)
+}
+
+func ListMyAccessRequests(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	listRequest := models.ListRequest{}
+	if err := c.QueryParser(&listRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	listResponse, err := uc.ListMine(tracing.NewContextFromFiberCtx(c), listRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, listResponse)
+}
+
+func ListAccessRequestQueue(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	listRequest := models.ListRequest{}
+	if err := c.QueryParser(&listRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	listResponse, err := uc.ListQueue(tracing.NewContextFromFiberCtx(c), listRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, listResponse)
+}
+
+func ListRequestableRoles(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	rolesResponse, err := uc.ListRequestableRoles(tracing.NewContextFromFiberCtx(c))
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, rolesResponse)
+}
+
+func ApproveAccessRequest(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	decisionRequest := models.DecisionRequest{}
+	if err := c.BodyParser(&decisionRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.Approve(tracing.NewContextFromFiberCtx(c), c.Params("requestID"), decisionRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func DenyAccessRequest(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	decisionRequest := models.DecisionRequest{}
+	if err := c.BodyParser(&decisionRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.Deny(tracing.NewContextFromFiberCtx(c), c.Params("requestID"), decisionRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func CancelAccessRequest(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAccessRequestUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.Cancel(tracing.NewContextFromFiberCtx(c), c.Params("requestID")); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	iapp "github.com/vukyn/isme/internal/app"
+	"github.com/vukyn/isme/internal/constants"
+	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+	"github.com/vukyn/isme/internal/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupAccessRequestRoutes registers the access request endpoints. Any signed-in
+// user may request; who may decide depends on the request's app
+// (access_request:approve there), so the usecase checks it rather than an rbac
+// middleware bound to the token's app.
+func SetupAccessRequestRoutes(router fiber.Router) {
+	middleware := idi.GetMiddleware(iapp.App)
+
+	r := router.Group(constants.ACCESS_REQUEST_GROUP_NAME, middleware.AuthMiddleware)
+	r.Post(constants.ACCESS_REQUEST_ENDPOINT_ROOT, CreateAccessRequest)
+	r.Get(constants.ACCESS_REQUEST_ENDPOINT_MINE, ListMyAccessRequests)
+	r.Get(constants.ACCESS_REQUEST_ENDPOINT_QUEUE, ListAccessRequestQueue)
+	r.Get(constants.ACCESS_REQUEST_ENDPOINT_ROLES, ListRequestableRoles)
+	r.Post(constants.ACCESS_REQUEST_ENDPOINT_APPROVE, ApproveAccessRequest)
+	r.Post(constants.ACCESS_REQUEST_ENDPOINT_DENY, DenyAccessRequest)
+	r.Post(constants.ACCESS_REQUEST_ENDPOINT_CANCEL, CancelAccessRequest)
+}
+
+// Operations documents the routes registered by SetupAccessRequestRoutes; keep
+// the two in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodPost, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_ROOT, Tag: "access-requests", Summary: "Request a role for a bounded time",
+		Body: models.CreateRequest{}, Response: models.CreateResponse{}, Auth: true},
+	{Method: fiber.MethodGet, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_MINE, Tag: "access-requests", Summary: "List my access requests",
+		Query: models.ListRequest{}, Response: models.ListResponse{}, Auth: true},
+	{Method: fiber.MethodGet, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_QUEUE, Tag: "access-requests", Summary: "List requests for the apps I approve in",
+		Query: models.ListRequest{}, Response: models.ListResponse{}, Auth: true},
+	{Method: fiber.MethodGet, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_ROLES, Tag: "access-requests", Summary: "List roles I can request",
+		Response: models.RequestableRolesResponse{}, Auth: true},
+	{Method: fiber.MethodPost, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_APPROVE, Tag: "access-requests", Summary: "Approve a pending access request",
+		Body: models.DecisionRequest{}, Auth: true, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_DENY, Tag: "access-requests", Summary: "Deny a pending access request",
+		Body: models.DecisionRequest{}, Auth: true, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.ACCESS_REQUEST_GROUP_NAME + constants.ACCESS_REQUEST_ENDPOINT_CANCEL, Tag: "access-requests", Summary: "Cancel my pending access request",
+		Auth: true, Errors: []int{fiber.StatusNotFound}},
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vukyn/isme/internal/domains/access_request/constants"
+)
+
+type CreateRequest struct {
+	AppServiceID string `json:"app_service_id"`
+	RoleID       string `json:"role_id"`
+	// Justification tells the approvers why the access is needed.
+	Justification string `json:"justification"`
+	// DurationHours is how long the membership lasts once approved.
+	DurationHours int32 `json:"duration_hours"`
+}
+
+func (r CreateRequest) Validate() error {
+	if r.AppServiceID == "" {
+		return errors.New("app_service_id is required")
+	}
+	if r.RoleID == "" {
+		return errors.New("role_id is required")
+	}
+	if strings.TrimSpace(r.Justification) == "" {
+		return errors.New("justification is required")
+	}
+	if len(r.Justification) > constants.MaxJustificationLength {
+		return fmt.Errorf("justification must be at most %d characters", constants.MaxJustificationLength)
+	}
+	if r.DurationHours < constants.MinDurationHours || r.DurationHours > constants.MaxDurationHours {
+		return fmt.Errorf("duration_hours must be between %d and %d", constants.MinDurationHours, constants.MaxDurationHours)
+	}
+	return nil
+}
+
+type CreateResponse struct {
+	ID string `json:"id"`
+}
+
+// ListRequest narrows a request list by status (0 = every status).
+type ListRequest struct {
+	Status int32 `json:"status" query:"status"`
+}
+
+func (r ListRequest) Validate() error {
+	if r.Status < 0 || r.Status > constants.AccessRequestStatusCancelled {
+		return errors.New("invalid status")
+	}
+	return nil
+}
+
+// ListFilter is the repository-side filter: the requester's own list sets
+// UserID, the approval queue sets AppCodes to the apps the caller approves in.
+type ListFilter struct {
+	UserID   string
+	AppCodes []string
+	Status   int32
+}
+
+type AccessRequestItem struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name"`
+	UserEmail     string `json:"user_email"`
+	AppServiceID  string `json:"app_service_id"`
+	AppCode       string `json:"app_code"`
+	AppName       string `json:"app_name"`
+	RoleID        string `json:"role_id"`
+	RoleCode      string `json:"role_code"`
+	RoleName      string `json:"role_name"`
+	Justification string `json:"justification"`
+	DurationHours int32  `json:"duration_hours"`
+	// Status is 1 (pending), 2 (approved), 3 (denied) or 4 (cancelled).
+	Status        int32  `json:"status"`
+	DecidedBy     string `json:"decided_by"`
+	DecidedByName string `json:"decided_by_name"`
+	DecisionNote  string `json:"decision_note"`
+	DecidedAt     string `json:"decided_at"`
+	// ExpiresAt is when the membership granted by an approval ends.
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListResponse struct {
+	Items []AccessRequestItem `json:"items"`
+}
+
+// DecisionRequest carries the approver's optional note on approve or deny.
+type DecisionRequest struct {
+	Note string `json:"note"`
+}
+
+func (r DecisionRequest) Validate() error {
+	if len(r.Note) > constants.MaxDecisionNoteLength {
+		return fmt.Errorf("note must be at most %d characters", constants.MaxDecisionNoteLength)
+	}
+	return nil
+}
+
+// RequestableRole is a role a user may ask for, with its owning app.
+type RequestableRole struct {
+	RoleID       string `json:"role_id"`
+	RoleCode     string `json:"role_code"`
+	RoleName     string `json:"role_name"`
+	Description  string `json:"description"`
+	AppServiceID string `json:"app_service_id"`
+	AppCode      string `json:"app_code"`
+	AppName      string `json:"app_name"`
+}
+
+type RequestableRolesResponse struct {
+	Items []RequestableRole `json:"items"`
+}
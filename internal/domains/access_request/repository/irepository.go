@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/access_request/entity"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+)
+
+type IRepository interface {
+	// Create a pending access request (requester, role and duration set by
+	// caller). Returns the new id.
+	Create(ctx context.Context, request entity.AccessRequest) (string, error)
+	// Get access request by ID
+	GetByID(ctx context.Context, id string) (entity.AccessRequest, error)
+	// Get the pending request a user has open for a role
+	GetPending(ctx context.Context, userID string, roleID string) (entity.AccessRequest, error)
+	// List access requests joined to requester, role and app, newest first
+	List(ctx context.Context, filter models.ListFilter) ([]models.AccessRequestItem, error)
+	// Atomically move a pending request to a decided status; false when it was
+	// not pending. A zero expiresAt leaves expires_at empty.
+	MarkDecided(ctx context.Context, id string, status int32, decidedBy string, note string, expiresAt time.Time) (bool, error)
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/access_request/constants"
+	"github.com/vukyn/isme/internal/domains/access_request/entity"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+	"github.com/vukyn/isme/internal/transaction"
+
+	pkgErr "github.com/vukyn/kuery/http/errors"
+
+	"github.com/uptrace/bun"
+	"github.com/vukyn/kuery/cryp"
+)
+
+type repository struct {
+	db *bun.DB
+}
+
+func NewRepository(
+	db *bun.DB,
+) IRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, request entity.AccessRequest) (string, error) {
+	if request.UserID == "" {
+		return "", pkgErr.InvalidRequest("user_id is required")
+	}
+	if request.RoleID == "" {
+		return "", pkgErr.InvalidRequest("role_id is required")
+	}
+
+	request.ID = cryp.ULID()
+	request.Status = int32(constants.AccessRequestStatusPending)
+
+	if _, err := transaction.Conn(ctx, r.db).NewInsert().Model(&request).Exec(ctx); err != nil {
+		return "", pkgErr.DatabaseError(err.Error())
+	}
+	return request.ID, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (entity.AccessRequest, error) {
+	if id == "" {
+		return entity.AccessRequest{}, pkgErr.InvalidRequest("id is required")
+	}
+
+	request := entity.AccessRequest{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&request).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.AccessRequest{}, nil
+		}
+		return entity.AccessRequest{}, pkgErr.DatabaseError(err.Error())
+	}
+	return request, nil
+}
+
+func (r *repository) GetPending(ctx context.Context, userID string, roleID string) (entity.AccessRequest, error) {
+	if userID == "" || roleID == "" {
+		return entity.AccessRequest{}, pkgErr.InvalidRequest("user_id and role_id are required")
+	}
+
+	request := entity.AccessRequest{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&request).
+		Where("user_id = ?", userID).
+		Where("role_id = ?", roleID).
+		Where("status = ?", constants.AccessRequestStatusPending).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.AccessRequest{}, nil
+		}
+		return entity.AccessRequest{}, pkgErr.DatabaseError(err.Error())
+	}
+	return request, nil
+}
+
+func (r *repository) List(ctx context.Context, filter models.ListFilter) ([]models.AccessRequestItem, error) {
+	type requestRow struct {
+		ID            string     `bun:"id"`
+		UserID        string     `bun:"user_id"`
+		UserName      string     `bun:"user_name"`
+		UserEmail     string     `bun:"user_email"`
+		AppServiceID  string     `bun:"app_service_id"`
+		AppCode       string     `bun:"app_code"`
+		AppName       string     `bun:"app_name"`
+		RoleID        string     `bun:"role_id"`
+		RoleCode      string     `bun:"role_code"`
+		RoleName      string     `bun:"role_name"`
+		Justification string     `bun:"justification"`
+		DurationHours int32      `bun:"duration_hours"`
+		Status        int32      `bun:"status"`
+		DecidedBy     string     `bun:"decided_by"`
+		DecidedByName string     `bun:"decided_by_name"`
+		DecisionNote  string     `bun:"decision_note"`
+		DecidedAt     *time.Time `bun:"decided_at"`
+		ExpiresAt     *time.Time `bun:"expires_at"`
+		CreatedAt     time.Time  `bun:"created_at"`
+	}
+
+	query := transaction.Conn(ctx, r.db).NewSelect().
+		TableExpr("access_requests AS acr").
+		ColumnExpr("acr.id").
+		ColumnExpr("acr.user_id").
+		ColumnExpr("COALESCE(usr.name, '') AS user_name").
+		ColumnExpr("COALESCE(usr.email, '') AS user_email").
+		ColumnExpr("acr.app_service_id").
+		ColumnExpr("COALESCE(app.app_code, '') AS app_code").
+		ColumnExpr("COALESCE(app.app_name, '') AS app_name").
+		ColumnExpr("acr.role_id").
+		ColumnExpr("COALESCE(rol.code, '') AS role_code").
+		ColumnExpr("COALESCE(rol.name, '') AS role_name").
+		ColumnExpr("acr.justification").
+		ColumnExpr("acr.duration_hours").
+		ColumnExpr("acr.status").
+		ColumnExpr("COALESCE(acr.decided_by, '') AS decided_by").
+		ColumnExpr("COALESCE(decider.name, '') AS decided_by_name").
+		ColumnExpr("COALESCE(acr.decision_note, '') AS decision_note").
+		ColumnExpr("acr.decided_at").
+		ColumnExpr("acr.expires_at").
+		ColumnExpr("acr.created_at").
+		Join("LEFT JOIN users AS usr ON usr.id = acr.user_id").
+		Join("LEFT JOIN users AS decider ON decider.id = acr.decided_by").
+		Join("LEFT JOIN roles AS rol ON rol.id = acr.role_id").
+		Join("LEFT JOIN app_services AS app ON app.id = acr.app_service_id")
+	if filter.UserID != "" {
+		query = query.Where("acr.user_id = ?", filter.UserID)
+	}
+	if filter.AppCodes != nil {
+		if len(filter.AppCodes) == 0 {
+			return []models.AccessRequestItem{}, nil
+		}
+		query = query.Where("app.app_code IN (?)", bun.In(filter.AppCodes))
+	}
+	if filter.Status != 0 {
+		query = query.Where("acr.status = ?", filter.Status)
+	}
+
+	rows := []requestRow{}
+	if err := query.Order("acr.created_at DESC").Scan(ctx, &rows); err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+
+	items := make([]models.AccessRequestItem, 0, len(rows))
+	for _, row := range rows {
+		item := models.AccessRequestItem{
+			ID:            row.ID,
+			UserID:        row.UserID,
+			UserName:      row.UserName,
+			UserEmail:     row.UserEmail,
+			AppServiceID:  row.AppServiceID,
+			AppCode:       row.AppCode,
+			AppName:       row.AppName,
+			RoleID:        row.RoleID,
+			RoleCode:      row.RoleCode,
+			RoleName:      row.RoleName,
+			Justification: row.Justification,
+			DurationHours: row.DurationHours,
+			Status:        row.Status,
+			DecidedBy:     row.DecidedBy,
+			DecidedByName: row.DecidedByName,
+			DecisionNote:  row.DecisionNote,
+			CreatedAt:     row.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if row.DecidedAt != nil {
+			item.DecidedAt = row.DecidedAt.UTC().Format(time.RFC3339)
+		}
+		if row.ExpiresAt != nil {
+			item.ExpiresAt = row.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *repository) MarkDecided(ctx context.Context, id string, status int32, decidedBy string, note string, expiresAt time.Time) (bool, error) {
+	if id == "" {
+		return false, pkgErr.InvalidRequest("id is required")
+	}
+
+	now := time.Now().UTC()
+	query := transaction.Conn(ctx, r.db).NewUpdate().
+		Model((*entity.AccessRequest)(nil)).
+		Set("status = ?", status).
+		Set("decided_by = ?", decidedBy).
+		Set("decided_at = ?", now).
+		Set("decision_note = ?", note).
+		Set("updated_at = ?", now).
+		Where("id = ?", id).
+		Where("status = ?", constants.AccessRequestStatusPending)
+	if !expiresAt.IsZero() {
+		query = query.Set("expires_at = ?", expiresAt.UTC())
+	}
+	result, err := query.Exec(ctx)
+	if err != nil {
+		return false, pkgErr.DatabaseError(err.Error())
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, pkgErr.DatabaseError(err.Error())
+	}
+	return rowsAffected > 0, nil
+}
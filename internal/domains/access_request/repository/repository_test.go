@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/domains/access_request/constants"
+	"github.com/vukyn/isme/internal/domains/access_request/entity"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// newTestDB opens an in-memory SQLite database and applies every migration
+// from db/history/sqlite.
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	sqldb, err := sql.Open(sqliteshim.ShimName, ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory sqlite: %v", err)
+	}
+	// a single connection keeps every query on the same in-memory database
+	sqldb.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	for _, migration := range sqliteHistory.Migrations {
+		if err := migration.Up(db); err != nil {
+			t.Fatalf("migration %s failed: %v", migration.Name, err)
+		}
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newRequest(userID string, roleID string) entity.AccessRequest {
+	return entity.AccessRequest{
+		UserID:        userID,
+		AppServiceID:  "app_isme",
+		RoleID:        roleID,
+		Justification: "on call this week",
+		DurationHours: 8,
+	}
+}
+
+func TestMarkDecidedConditionalClaim(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	requestRepository := NewRepository(db)
+
+	requestID, err := requestRepository.Create(ctx, newRequest("usr-1", "rol_viewer"))
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	pending, err := requestRepository.GetPending(ctx, "usr-1", "rol_viewer")
+	if err != nil || pending.ID != requestID {
+		t.Fatalf("GetPending() = %+v, %v; want %s", pending, err, requestID)
+	}
+
+	// one pending request per user and role
+	if _, err := requestRepository.Create(ctx, newRequest("usr-1", "rol_viewer")); err == nil {
+		t.Fatal("expected a second pending request for the same role to be rejected")
+	}
+
+	expiresAt := time.Now().UTC().Add(8 * time.Hour).Truncate(time.Second)
+	decided, err := requestRepository.MarkDecided(ctx, requestID, constants.AccessRequestStatusApproved, "usr-admin", "ok", expiresAt)
+	if err != nil || !decided {
+		t.Fatalf("first MarkDecided() = %v, %v; want a claim", decided, err)
+	}
+	decided, err = requestRepository.MarkDecided(ctx, requestID, constants.AccessRequestStatusDenied, "usr-admin", "", time.Time{})
+	if err != nil || decided {
+		t.Fatalf("second MarkDecided() = %v, %v; want no claim", decided, err)
+	}
+
+	request, err := requestRepository.GetByID(ctx, requestID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if request.Status != constants.AccessRequestStatusApproved || request.DecidedBy != "usr-admin" || !request.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("request = %+v, want approved by usr-admin until %s", request, expiresAt)
+	}
+
+	// a decided request no longer blocks a new one
+	if _, err := requestRepository.Create(ctx, newRequest("usr-1", "rol_viewer")); err != nil {
+		t.Fatalf("re-request after decision: %v", err)
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	requestRepository := NewRepository(db)
+
+	for _, request := range []entity.AccessRequest{
+		newRequest("usr-1", "rol_viewer"),
+		newRequest("usr-2", "rol_member"),
+	} {
+		if _, err := requestRepository.Create(ctx, request); err != nil {
+			t.Fatalf("create request: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter models.ListFilter
+		want   int
+	}{
+		{"everything", models.ListFilter{}, 2},
+		{"requester", models.ListFilter{UserID: "usr-1"}, 1},
+		{"approver apps", models.ListFilter{AppCodes: []string{"isme"}}, 2},
+		{"no approver apps", models.ListFilter{AppCodes: []string{}}, 0},
+		{"other app", models.ListFilter{AppCodes: []string{"medioa"}}, 0},
+		{"status", models.ListFilter{Status: constants.AccessRequestStatusApproved}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := requestRepository.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(items) != tt.want {
+				t.Fatalf("List() = %d items, want %d", len(items), tt.want)
+			}
+		})
+	}
+
+	items, err := requestRepository.List(ctx, models.ListFilter{UserID: "usr-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if items[0].AppCode != "isme" || items[0].RoleCode != "viewer" || items[0].Status != constants.AccessRequestStatusPending {
+		t.Errorf("item = %+v, want a pending isme viewer request", items[0])
+	}
+}
+
+func TestMigrationGrantsApprovalToIsmeAdmin(t *testing.T) {
+	db := newTestDB(t)
+
+	var count int
+	err := db.QueryRowContext(context.Background(), `
+		SELECT COUNT(*) FROM role_permissions AS rp
+		JOIN permissions AS per ON per.id = rp.permission_id
+		WHERE rp.role_id = 'rol_admin' AND per.app_id = 'app_isme'
+			AND per.resource = 'access_request' AND per.action = 'approve'
+	`).Scan(&count)
+	if err != nil {
+		t.Fatalf("query grant: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("rol_admin holds access_request:approve %d times, want 1", count)
+	}
+}
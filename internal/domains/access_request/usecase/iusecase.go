@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+)
+
+type IUseCase interface {
+	// Request a role in an app for a bounded time (the caller is the requester)
+	Create(ctx context.Context, req models.CreateRequest) (models.CreateResponse, error)
+	// List the caller's own access requests
+	ListMine(ctx context.Context, req models.ListRequest) (models.ListResponse, error)
+	// List the requests for the apps the caller holds access_request:approve in
+	ListQueue(ctx context.Context, req models.ListRequest) (models.ListResponse, error)
+	// List the roles of active apps the caller does not hold yet
+	ListRequestableRoles(ctx context.Context) (models.RequestableRolesResponse, error)
+	// Approve a pending request: grant the role for the requested duration
+	Approve(ctx context.Context, id string, req models.DecisionRequest) error
+	// Deny a pending request
+	Deny(ctx context.Context, id string, req models.DecisionRequest) error
+	// Cancel the caller's own pending request
+	Cancel(ctx context.Context, id string) error
+}
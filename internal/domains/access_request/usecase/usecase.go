@@ -0,0 +1,370 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/access_request/constants"
+	"github.com/vukyn/isme/internal/domains/access_request/entity"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+	requestRepo "github.com/vukyn/isme/internal/domains/access_request/repository"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+	appServiceConstants "github.com/vukyn/isme/internal/domains/app_service/constants"
+	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
+	"github.com/vukyn/isme/internal/transaction"
+
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgErr "github.com/vukyn/kuery/http/errors"
+)
+
+type usecase struct {
+	requestRepo     requestRepo.IRepository
+	roleRepo        roleRepo.IRepository
+	appServiceRepo  appServiceRepo.IRepository
+	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
+}
+
+func NewUsecase(
+	requestRepo requestRepo.IRepository,
+	roleRepo roleRepo.IRepository,
+	appServiceRepo appServiceRepo.IRepository,
+	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
+) IUseCase {
+	return &usecase{
+		requestRepo:     requestRepo,
+		roleRepo:        roleRepo,
+		appServiceRepo:  appServiceRepo,
+		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
+	}
+}
+
+func (u *usecase) Create(ctx context.Context, req models.CreateRequest) (models.CreateResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return models.CreateResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+	userID := pkgCtx.GetUserID(ctx)
+
+	// the role must exist in the requested app, and the app must be live
+	role, err := u.roleRepo.GetByID(ctx, req.RoleID)
+	if err != nil {
+		return models.CreateResponse{}, err
+	}
+	if role.ID == "" {
+		return models.CreateResponse{}, pkgErr.InvalidRequest("role not found")
+	}
+	if role.AppID != req.AppServiceID {
+		return models.CreateResponse{}, pkgErr.InvalidRequest("role does not belong to the given app_service_id")
+	}
+	app, err := u.appServiceRepo.GetByID(ctx, req.AppServiceID)
+	if err != nil {
+		return models.CreateResponse{}, err
+	}
+	if app.ID == "" || app.Status != appServiceConstants.AppServiceStatusActive {
+		return models.CreateResponse{}, pkgErr.InvalidRequest("app service is not active")
+	}
+
+	held, err := u.roleRepo.GetRoleCodesByUserID(ctx, userID, req.AppServiceID)
+	if err != nil {
+		return models.CreateResponse{}, err
+	}
+	if slices.Contains(held, role.Code) {
+		return models.CreateResponse{}, pkgErr.InvalidRequest("you already hold this role")
+	}
+	pending, err := u.requestRepo.GetPending(ctx, userID, req.RoleID)
+	if err != nil {
+		return models.CreateResponse{}, err
+	}
+	if pending.ID != "" {
+		return models.CreateResponse{}, pkgErr.InvalidRequest("a pending request for this role already exists")
+	}
+
+	var requestID string
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		var err error
+		requestID, err = u.requestRepo.Create(ctx, entity.AccessRequest{
+			UserID:        userID,
+			AppServiceID:  req.AppServiceID,
+			RoleID:        req.RoleID,
+			Justification: req.Justification,
+			DurationHours: req.DurationHours,
+		})
+		if err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAccessRequestCreated,
+			TargetType: activityConstants.TargetTypeAccessRequest,
+			TargetID:   requestID,
+			After:      req,
+			Meta:       map[string]any{"app_code": app.AppCode, "role_code": role.Code},
+		})
+	})
+	if err != nil {
+		return models.CreateResponse{}, err
+	}
+	return models.CreateResponse{ID: requestID}, nil
+}
+
+func (u *usecase) ListMine(ctx context.Context, req models.ListRequest) (models.ListResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return models.ListResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	items, err := u.requestRepo.List(ctx, models.ListFilter{UserID: pkgCtx.GetUserID(ctx), Status: req.Status})
+	if err != nil {
+		return models.ListResponse{}, err
+	}
+	return models.ListResponse{Items: items}, nil
+}
+
+func (u *usecase) ListQueue(ctx context.Context, req models.ListRequest) (models.ListResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return models.ListResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	// the queue spans every app the caller approves in; none yields an empty queue
+	permsByApp, err := u.roleRepo.GetPermissionCodesGroupedByApp(ctx, pkgCtx.GetUserID(ctx))
+	if err != nil {
+		return models.ListResponse{}, err
+	}
+	appCodes := []string{}
+	for appCode, perms := range permsByApp {
+		if slices.Contains(perms, roleConstants.PERM_ACCESS_REQUEST_APPROVE) {
+			appCodes = append(appCodes, appCode)
+		}
+	}
+
+	items, err := u.requestRepo.List(ctx, models.ListFilter{AppCodes: appCodes, Status: req.Status})
+	if err != nil {
+		return models.ListResponse{}, err
+	}
+	return models.ListResponse{Items: items}, nil
+}
+
+func (u *usecase) ListRequestableRoles(ctx context.Context) (models.RequestableRolesResponse, error) {
+	roles, err := u.roleRepo.List(ctx, roleModels.ListRequest{})
+	if err != nil {
+		return models.RequestableRolesResponse{}, err
+	}
+
+	appIDs := make([]string, 0, len(roles))
+	for _, role := range roles {
+		appIDs = append(appIDs, role.AppID)
+	}
+	appsByID, err := u.appServiceRepo.GetByIDs(ctx, appIDs)
+	if err != nil {
+		return models.RequestableRolesResponse{}, err
+	}
+
+	// skip the roles the caller already holds, keyed app_code/role_code
+	userID := pkgCtx.GetUserID(ctx)
+	heldByUser, err := u.roleRepo.GetRoleCodesGroupedByAppByUserIDs(ctx, []string{userID})
+	if err != nil {
+		return models.RequestableRolesResponse{}, err
+	}
+	held := map[string]bool{}
+	for _, assignment := range heldByUser[userID] {
+		held[assignment.AppCode+"/"+assignment.RoleCode] = true
+	}
+
+	items := []models.RequestableRole{}
+	for _, role := range roles {
+		app, ok := appsByID[role.AppID]
+		if !ok || app.Status != appServiceConstants.AppServiceStatusActive || held[role.AppCode+"/"+role.Code] {
+			continue
+		}
+		items = append(items, models.RequestableRole{
+			RoleID:       role.ID,
+			RoleCode:     role.Code,
+			RoleName:     role.Name,
+			Description:  role.Description,
+			AppServiceID: role.AppID,
+			AppCode:      app.AppCode,
+			AppName:      app.AppName,
+		})
+	}
+	return models.RequestableRolesResponse{Items: items}, nil
+}
+
+// Approve grants the requested role for DurationHours from now through the
+// role repository's time-bound membership, so the role-member-expiry job
+// removes it when the window closes.
+func (u *usecase) Approve(ctx context.Context, id string, req models.DecisionRequest) error {
+	// validation
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	request, err := u.getDecidable(ctx, id)
+	if err != nil {
+		return err
+	}
+	role, err := u.roleRepo.GetByID(ctx, request.RoleID)
+	if err != nil {
+		return err
+	}
+	if role.ID == "" {
+		return pkgErr.InvalidRequest("the requested role no longer exists")
+	}
+
+	approverID := pkgCtx.GetUserID(ctx)
+	expiresAt := time.Now().UTC().Add(time.Duration(request.DurationHours) * time.Hour)
+	appServiceID := request.AppServiceID
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		// re-adding a member moves its window, which would put an expiry on an
+		// assignment the requester was granted some other way since asking;
+		// a pending or lapsed one counts too, though it grants nothing now
+		held, err := u.roleRepo.HasMember(ctx, request.RoleID, request.UserID, &appServiceID)
+		if err != nil {
+			return err
+		}
+		if held {
+			return pkgErr.InvalidRequest("the requester already holds this role")
+		}
+
+		// conditional update guards against a concurrent decision
+		decided, err := u.requestRepo.MarkDecided(ctx, id, constants.AccessRequestStatusApproved, approverID, req.Note, expiresAt)
+		if err != nil {
+			return err
+		}
+		if !decided {
+			return pkgErr.InvalidRequest("access request already decided")
+		}
+
+		window := roleModels.MemberWindow{ExpiresAt: &expiresAt}
+		if err := u.roleRepo.AddMembers(ctx, request.RoleID, []string{request.UserID}, &appServiceID, window); err != nil {
+			return err
+		}
+
+		// the grant shows up in the role's trail as well as the request's
+		if err := u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleMembersAdded,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   request.RoleID,
+			After: roleModels.AddMembersRequest{
+				UserIDs:   []string{request.UserID},
+				ExpiresAt: expiresAt.Format(time.RFC3339),
+			},
+			Meta: map[string]any{"app_service_id": request.AppServiceID, "access_request_id": id},
+		}); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAccessRequestApproved,
+			TargetType: activityConstants.TargetTypeAccessRequest,
+			TargetID:   id,
+			Before:     map[string]any{"status": request.Status},
+			After: map[string]any{
+				"status":     int32(constants.AccessRequestStatusApproved),
+				"note":       req.Note,
+				"expires_at": expiresAt.Format(time.RFC3339),
+			},
+			Meta: map[string]any{"user_id": request.UserID, "role_id": request.RoleID, "app_service_id": request.AppServiceID},
+		})
+	})
+}
+
+func (u *usecase) Deny(ctx context.Context, id string, req models.DecisionRequest) error {
+	// validation
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	request, err := u.getDecidable(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	approverID := pkgCtx.GetUserID(ctx)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		decided, err := u.requestRepo.MarkDecided(ctx, id, constants.AccessRequestStatusDenied, approverID, req.Note, time.Time{})
+		if err != nil {
+			return err
+		}
+		if !decided {
+			return pkgErr.InvalidRequest("access request already decided")
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAccessRequestDenied,
+			TargetType: activityConstants.TargetTypeAccessRequest,
+			TargetID:   id,
+			Before:     map[string]any{"status": request.Status},
+			After:      map[string]any{"status": int32(constants.AccessRequestStatusDenied), "note": req.Note},
+			Meta:       map[string]any{"user_id": request.UserID, "role_id": request.RoleID, "app_service_id": request.AppServiceID},
+		})
+	})
+}
+
+func (u *usecase) Cancel(ctx context.Context, id string) error {
+	request, err := u.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	// another user's request is reported as missing, not forbidden
+	userID := pkgCtx.GetUserID(ctx)
+	if request.ID == "" || request.UserID != userID {
+		return pkgErr.NotFound("access request not found")
+	}
+	if request.Status != int32(constants.AccessRequestStatusPending) {
+		return pkgErr.InvalidRequest("access request already decided")
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		decided, err := u.requestRepo.MarkDecided(ctx, id, constants.AccessRequestStatusCancelled, userID, "", time.Time{})
+		if err != nil {
+			return err
+		}
+		if !decided {
+			return pkgErr.InvalidRequest("access request already decided")
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAccessRequestCancelled,
+			TargetType: activityConstants.TargetTypeAccessRequest,
+			TargetID:   id,
+			Before:     map[string]any{"status": request.Status},
+			After:      map[string]any{"status": int32(constants.AccessRequestStatusCancelled)},
+			Meta:       map[string]any{"role_id": request.RoleID, "app_service_id": request.AppServiceID},
+		})
+	})
+}
+
+// getDecidable loads a pending request the caller may approve or deny: they
+// must hold access_request:approve in the request's app and must not be the
+// requester.
+func (u *usecase) getDecidable(ctx context.Context, id string) (entity.AccessRequest, error) {
+	request, err := u.requestRepo.GetByID(ctx, id)
+	if err != nil {
+		return entity.AccessRequest{}, err
+	}
+	if request.ID == "" {
+		return entity.AccessRequest{}, pkgErr.NotFound("access request not found")
+	}
+
+	approverID := pkgCtx.GetUserID(ctx)
+	perms, err := u.roleRepo.GetPermissionCodesByUserID(ctx, approverID, request.AppServiceID)
+	if err != nil {
+		return entity.AccessRequest{}, err
+	}
+	if !slices.Contains(perms, roleConstants.PERM_ACCESS_REQUEST_APPROVE) {
+		return entity.AccessRequest{}, pkgErr.Forbidden("access_request:approve is required in this app")
+	}
+	if request.UserID == approverID {
+		return entity.AccessRequest{}, pkgErr.Forbidden("you cannot decide your own access request")
+	}
+	if request.Status != int32(constants.AccessRequestStatusPending) {
+		return entity.AccessRequest{}, pkgErr.InvalidRequest("access request already decided")
+	}
+	return request, nil
+}
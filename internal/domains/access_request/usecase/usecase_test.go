@@ -0,0 +1,600 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/access_request/constants"
+	"github.com/vukyn/isme/internal/domains/access_request/entity"
+	"github.com/vukyn/isme/internal/domains/access_request/models"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	appServiceConstants "github.com/vukyn/isme/internal/domains/app_service/constants"
+	appServiceEntity "github.com/vukyn/isme/internal/domains/app_service/entity"
+	appServiceModels "github.com/vukyn/isme/internal/domains/app_service/models"
+	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	roleEntity "github.com/vukyn/isme/internal/domains/role/entity"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
+	"github.com/vukyn/isme/internal/transaction"
+
+	pkgCtx "github.com/vukyn/kuery/ctx"
+)
+
+// === role repository fake ===
+
+type addMembersCall struct {
+	roleID       string
+	userIDs      []string
+	appServiceID *string
+	window       roleModels.MemberWindow
+}
+
+type fakeRoleRepository struct {
+	roles map[string]roleEntity.Role
+	// roleCodes and perms are keyed "userID/appID"
+	roleCodes map[string][]string
+	perms     map[string][]string
+	// members are user_roles rows in any window, keyed "roleID/userID"
+	members         map[string]bool
+	addMembersCalls []addMembersCall
+}
+
+var _ roleRepo.IRepository = (*fakeRoleRepository)(nil)
+
+func (f *fakeRoleRepository) Create(ctx context.Context, req roleModels.CreateRequest) (string, error) {
+	return "", nil
+}
+
+func (f *fakeRoleRepository) GetByID(ctx context.Context, id string) (roleEntity.Role, error) {
+	return f.roles[id], nil
+}
+
+func (f *fakeRoleRepository) GetByAppAndCode(ctx context.Context, appID string, code string) (roleEntity.Role, error) {
+	return roleEntity.Role{}, nil
+}
+
+func (f *fakeRoleRepository) List(ctx context.Context, req roleModels.ListRequest) ([]roleModels.RoleListItem, error) {
+	items := []roleModels.RoleListItem{}
+	for _, role := range f.roles {
+		items = append(items, roleModels.RoleListItem{ID: role.ID, AppID: role.AppID, AppCode: appCodes[role.AppID], Code: role.Code, Name: role.Name})
+	}
+	slices.SortFunc(items, func(a, b roleModels.RoleListItem) int { return strings.Compare(a.ID, b.ID) })
+	return items, nil
+}
+
+func (f *fakeRoleRepository) Update(ctx context.Context, id string, req roleModels.UpdateRequest) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) SoftDelete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) ListPermissions(ctx context.Context, req roleModels.ListPermissionsRequest) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) CreatePermissions(ctx context.Context, appID string, perms []roleModels.PermissionItem) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetPermissionByID(ctx context.Context, permissionID int64) (roleEntity.Permission, error) {
+	return roleEntity.Permission{}, nil
+}
+
+func (f *fakeRoleRepository) DeletePermission(ctx context.Context, permissionID int64) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) UpdatePermissionAppearance(ctx context.Context, appID string, resource string, icon string, color string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]roleEntity.Role, error) {
+	return map[string][]roleEntity.Role{}, nil
+}
+
+func (f *fakeRoleRepository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	return roleIDs, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRolePermissions(ctx context.Context, roleID string, permissionIDs []int64) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) ListMembers(ctx context.Context, roleID string, req roleModels.ListMembersRequest) ([]roleModels.MemberItem, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeRoleRepository) CountMembersByRoleID(ctx context.Context, roleID string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window roleModels.MemberWindow) error {
+	f.addMembersCalls = append(f.addMembersCalls, addMembersCall{roleID: roleID, userIDs: userIDs, appServiceID: appServiceID, window: window})
+	return nil
+}
+
+func (f *fakeRoleRepository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]roleEntity.UserRole, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	return f.members[roleID+"/"+userID], nil
+}
+
+func (f *fakeRoleRepository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) GetPermissionCodesByUserID(ctx context.Context, userID string, appID string) ([]string, error) {
+	return f.perms[userID+"/"+appID], nil
+}
+
+func (f *fakeRoleRepository) GetPermissionCodesGroupedByApp(ctx context.Context, userID string) (map[string][]string, error) {
+	result := map[string][]string{}
+	for key, perms := range f.perms {
+		user, appID, _ := strings.Cut(key, "/")
+		if user == userID {
+			result[appCodes[appID]] = perms
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRoleRepository) GetAppCodesByUserID(ctx context.Context, userID string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetRoleCodesByUserID(ctx context.Context, userID string, appServiceID string) ([]string, error) {
+	return f.roleCodes[userID+"/"+appServiceID], nil
+}
+
+func (f *fakeRoleRepository) GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, userIDs []string) (map[string][]roleModels.UserAppRole, error) {
+	result := map[string][]roleModels.UserAppRole{}
+	for key, codes := range f.roleCodes {
+		user, appID, _ := strings.Cut(key, "/")
+		if !slices.Contains(userIDs, user) {
+			continue
+		}
+		for _, code := range codes {
+			result[user] = append(result[user], roleModels.UserAppRole{AppCode: appCodes[appID], RoleCode: code})
+		}
+	}
+	return result, nil
+}
+
+// === app_service repository fake ===
+
+type fakeAppServiceRepository struct {
+	apps map[string]appServiceEntity.AppService
+}
+
+var _ appServiceRepo.IRepository = (*fakeAppServiceRepository)(nil)
+
+func (f *fakeAppServiceRepository) Create(ctx context.Context, req appServiceEntity.CreateRequest) (string, error) {
+	return "", nil
+}
+
+func (f *fakeAppServiceRepository) GetByID(ctx context.Context, id string) (appServiceEntity.AppService, error) {
+	return f.apps[id], nil
+}
+
+func (f *fakeAppServiceRepository) GetByIDs(ctx context.Context, ids []string) (map[string]appServiceEntity.AppService, error) {
+	result := map[string]appServiceEntity.AppService{}
+	for _, id := range ids {
+		if app, ok := f.apps[id]; ok {
+			result[id] = app
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeAppServiceRepository) GetByCode(ctx context.Context, code string) (appServiceEntity.AppService, error) {
+	return appServiceEntity.AppService{}, nil
+}
+
+func (f *fakeAppServiceRepository) Update(ctx context.Context, req appServiceEntity.UpdateRequest) error {
+	return nil
+}
+
+func (f *fakeAppServiceRepository) List(ctx context.Context, req appServiceModels.ListRequest) ([]appServiceEntity.AppService, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeAppServiceRepository) UpdateStatus(ctx context.Context, id string, status int32) error {
+	return nil
+}
+
+// === access request repository fake (in-memory) ===
+
+type fakeRequestRepository struct {
+	requests map[string]*entity.AccessRequest
+	nextID   int
+}
+
+func (f *fakeRequestRepository) Create(ctx context.Context, request entity.AccessRequest) (string, error) {
+	f.nextID++
+	request.ID = fmt.Sprintf("acr-%d", f.nextID)
+	request.Status = int32(constants.AccessRequestStatusPending)
+	f.requests[request.ID] = &request
+	return request.ID, nil
+}
+
+func (f *fakeRequestRepository) GetByID(ctx context.Context, id string) (entity.AccessRequest, error) {
+	if request, ok := f.requests[id]; ok {
+		return *request, nil
+	}
+	return entity.AccessRequest{}, nil
+}
+
+func (f *fakeRequestRepository) GetPending(ctx context.Context, userID string, roleID string) (entity.AccessRequest, error) {
+	for _, request := range f.requests {
+		if request.UserID == userID && request.RoleID == roleID && request.Status == int32(constants.AccessRequestStatusPending) {
+			return *request, nil
+		}
+	}
+	return entity.AccessRequest{}, nil
+}
+
+func (f *fakeRequestRepository) List(ctx context.Context, filter models.ListFilter) ([]models.AccessRequestItem, error) {
+	items := []models.AccessRequestItem{}
+	for _, request := range f.requests {
+		if filter.UserID != "" && request.UserID != filter.UserID {
+			continue
+		}
+		if filter.AppCodes != nil && !slices.Contains(filter.AppCodes, appCodes[request.AppServiceID]) {
+			continue
+		}
+		if filter.Status != 0 && request.Status != filter.Status {
+			continue
+		}
+		items = append(items, models.AccessRequestItem{ID: request.ID, UserID: request.UserID, AppServiceID: request.AppServiceID, Status: request.Status})
+	}
+	slices.SortFunc(items, func(a, b models.AccessRequestItem) int { return strings.Compare(a.ID, b.ID) })
+	return items, nil
+}
+
+func (f *fakeRequestRepository) MarkDecided(ctx context.Context, id string, status int32, decidedBy string, note string, expiresAt time.Time) (bool, error) {
+	request, ok := f.requests[id]
+	if !ok || request.Status != int32(constants.AccessRequestStatusPending) {
+		return false, nil
+	}
+	request.Status = status
+	request.DecidedBy = decidedBy
+	request.DecisionNote = note
+	request.ExpiresAt = expiresAt
+	return true, nil
+}
+
+// === helpers ===
+
+var appCodes = map[string]string{"app_isme": "isme", "app_medioa2": "medioa2", "app_rainy": "rainy"}
+
+func ctxWithUser(userID string) context.Context {
+	return context.WithValue(context.Background(), pkgCtx.UserIDKey, userID)
+}
+
+// newTestFixture seeds two apps with one role each (rainy is disabled), an
+// approver holding access_request:approve in isme only, and a requester with
+// no roles.
+func newTestFixture() (*fakeRequestRepository, *fakeRoleRepository, *fakeActivityUsecase, IUseCase) {
+	requestRepository := &fakeRequestRepository{requests: map[string]*entity.AccessRequest{}}
+	roleRepository := &fakeRoleRepository{
+		roles: map[string]roleEntity.Role{
+			"rol_member": {ID: "rol_member", AppID: "app_isme", Code: "member", Name: "Member"},
+			"rol_editor": {ID: "rol_editor", AppID: "app_medioa2", Code: "editor", Name: "Editor"},
+			"rol_viewer": {ID: "rol_viewer", AppID: "app_rainy", Code: "viewer", Name: "Viewer"},
+		},
+		roleCodes: map[string][]string{"approver/app_isme": {"admin"}},
+		perms:     map[string][]string{"approver/app_isme": {roleConstants.PERM_ACCESS_REQUEST_APPROVE}},
+		members:   map[string]bool{},
+	}
+	appServiceRepository := &fakeAppServiceRepository{
+		apps: map[string]appServiceEntity.AppService{
+			"app_isme":    {ID: "app_isme", AppCode: "isme", AppName: "ISME", Status: appServiceConstants.AppServiceStatusActive},
+			"app_medioa2": {ID: "app_medioa2", AppCode: "medioa2", AppName: "Medioa", Status: appServiceConstants.AppServiceStatusActive},
+			"app_rainy":   {ID: "app_rainy", AppCode: "rainy", AppName: "Rainy", Status: appServiceConstants.AppServiceStatusInactive},
+		},
+	}
+	activity := &fakeActivityUsecase{}
+	uc := NewUsecase(requestRepository, roleRepository, appServiceRepository, activity, transaction.NoopRunner{})
+	return requestRepository, roleRepository, activity, uc
+}
+
+func createMemberRequest(t *testing.T, uc IUseCase) string {
+	t.Helper()
+	res, err := uc.Create(ctxWithUser("requester"), models.CreateRequest{
+		AppServiceID:  "app_isme",
+		RoleID:        "rol_member",
+		Justification: "on-call this week",
+		DurationHours: 8,
+	})
+	if err != nil {
+		t.Fatalf("expected create to succeed, got: %v", err)
+	}
+	return res.ID
+}
+
+// === Create ===
+
+func TestCreateRejectsInvalidTargets(t *testing.T) {
+	_, roleRepository, _, uc := newTestFixture()
+	roleRepository.roleCodes["requester/app_medioa2"] = []string{"editor"}
+
+	cases := []struct {
+		name string
+		req  models.CreateRequest
+	}{
+		{"cross-app role", models.CreateRequest{AppServiceID: "app_medioa2", RoleID: "rol_member"}},
+		{"disabled app", models.CreateRequest{AppServiceID: "app_rainy", RoleID: "rol_viewer"}},
+		{"missing role", models.CreateRequest{AppServiceID: "app_isme", RoleID: "rol_missing"}},
+		{"role already held", models.CreateRequest{AppServiceID: "app_medioa2", RoleID: "rol_editor"}},
+		{"duration out of range", models.CreateRequest{AppServiceID: "app_isme", RoleID: "rol_member", DurationHours: constants.MaxDurationHours + 1}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Justification = "need it"
+			if tc.req.DurationHours == 0 {
+				tc.req.DurationHours = 4
+			}
+			if _, err := uc.Create(ctxWithUser("requester"), tc.req); err == nil {
+				t.Fatal("expected create to be rejected")
+			}
+		})
+	}
+}
+
+func TestCreateRejectsDuplicatePending(t *testing.T) {
+	_, _, activity, uc := newTestFixture()
+	createMemberRequest(t, uc)
+
+	_, err := uc.Create(ctxWithUser("requester"), models.CreateRequest{
+		AppServiceID:  "app_isme",
+		RoleID:        "rol_member",
+		Justification: "again",
+		DurationHours: 8,
+	})
+	if err == nil || !strings.Contains(err.Error(), "pending") {
+		t.Fatalf("expected duplicate pending request to be rejected, got: %v", err)
+	}
+	if len(activity.auditEntries) != 1 || activity.auditEntries[0].Type != activityConstants.ActivityTypeAccessRequestCreated {
+		t.Fatalf("expected a single created audit, got %+v", activity.auditEntries)
+	}
+}
+
+// === Approve / Deny ===
+
+func TestApproveGrantsTimeBoundMembership(t *testing.T) {
+	requestRepository, roleRepository, activity, uc := newTestFixture()
+	id := createMemberRequest(t, uc)
+	activity.auditEntries = nil
+
+	before := time.Now().UTC()
+	if err := uc.Approve(ctxWithUser("approver"), id, models.DecisionRequest{Note: "ok"}); err != nil {
+		t.Fatalf("expected approve to succeed, got: %v", err)
+	}
+
+	if len(roleRepository.addMembersCalls) != 1 {
+		t.Fatalf("expected one AddMembers call, got %d", len(roleRepository.addMembersCalls))
+	}
+	call := roleRepository.addMembersCalls[0]
+	if call.roleID != "rol_member" || !slices.Equal(call.userIDs, []string{"requester"}) || call.appServiceID == nil || *call.appServiceID != "app_isme" {
+		t.Errorf("unexpected AddMembers call: %+v", call)
+	}
+	if call.window.ExpiresAt == nil {
+		t.Fatal("expected the membership to carry an expiry")
+	}
+	if got := call.window.ExpiresAt.Sub(before); got < 8*time.Hour || got > 8*time.Hour+time.Minute {
+		t.Errorf("expected expiry ~8h from now, got %v", got)
+	}
+
+	stored := requestRepository.requests[id]
+	if stored.Status != int32(constants.AccessRequestStatusApproved) || stored.DecidedBy != "approver" || !stored.ExpiresAt.Equal(*call.window.ExpiresAt) {
+		t.Errorf("unexpected stored decision: %+v", stored)
+	}
+
+	types := []string{}
+	for _, entry := range activity.auditEntries {
+		types = append(types, entry.Type)
+	}
+	want := []string{activityConstants.ActivityTypeRoleMembersAdded, activityConstants.ActivityTypeAccessRequestApproved}
+	if !slices.Equal(types, want) {
+		t.Errorf("expected audits %v, got %v", want, types)
+	}
+}
+
+// An assignment the requester got some other way since asking may be pending
+// or lapsed, so it holds no role code; approving must still leave its window
+// alone.
+func TestApproveRejectsExistingAssignment(t *testing.T) {
+	requestRepository, roleRepository, activity, uc := newTestFixture()
+	id := createMemberRequest(t, uc)
+	activity.auditEntries = nil
+	roleRepository.members["rol_member/requester"] = true
+
+	if err := uc.Approve(ctxWithUser("approver"), id, models.DecisionRequest{}); err == nil {
+		t.Fatal("expected approve to be rejected")
+	}
+	if len(roleRepository.addMembersCalls) != 0 {
+		t.Errorf("expected no AddMembers call, got %+v", roleRepository.addMembersCalls)
+	}
+	if stored := requestRepository.requests[id]; stored.Status != int32(constants.AccessRequestStatusPending) {
+		t.Errorf("expected the request to stay pending, got status %d", stored.Status)
+	}
+	if len(activity.auditEntries) != 0 {
+		t.Errorf("expected no audits, got %+v", activity.auditEntries)
+	}
+}
+
+func TestDecisionRequiresApprovePermissionInTheApp(t *testing.T) {
+	_, roleRepository, _, uc := newTestFixture()
+	// approver holds the permission in isme only; a request for medioa2 is out of reach
+	res, err := uc.Create(ctxWithUser("requester"), models.CreateRequest{
+		AppServiceID:  "app_medioa2",
+		RoleID:        "rol_editor",
+		Justification: "release day",
+		DurationHours: 2,
+	})
+	if err != nil {
+		t.Fatalf("expected create to succeed, got: %v", err)
+	}
+
+	if err := uc.Approve(ctxWithUser("approver"), res.ID, models.DecisionRequest{}); err == nil {
+		t.Fatal("expected approve without the permission in medioa2 to be forbidden")
+	}
+	if err := uc.Deny(ctxWithUser("approver"), res.ID, models.DecisionRequest{}); err == nil {
+		t.Fatal("expected deny without the permission in medioa2 to be forbidden")
+	}
+	if len(roleRepository.addMembersCalls) != 0 {
+		t.Error("a forbidden approval must not grant anything")
+	}
+}
+
+func TestApproverCannotDecideOwnRequest(t *testing.T) {
+	_, roleRepository, _, uc := newTestFixture()
+	res, err := uc.Create(ctxWithUser("approver"), models.CreateRequest{
+		AppServiceID:  "app_isme",
+		RoleID:        "rol_member",
+		Justification: "self-service",
+		DurationHours: 1,
+	})
+	if err != nil {
+		t.Fatalf("expected create to succeed, got: %v", err)
+	}
+
+	err = uc.Approve(ctxWithUser("approver"), res.ID, models.DecisionRequest{})
+	if err == nil || !strings.Contains(err.Error(), "own") {
+		t.Fatalf("expected self-approval to be forbidden, got: %v", err)
+	}
+	if len(roleRepository.addMembersCalls) != 0 {
+		t.Error("a self-approval must not grant anything")
+	}
+}
+
+func TestDecidedRequestCannotBeDecidedAgain(t *testing.T) {
+	_, roleRepository, _, uc := newTestFixture()
+	id := createMemberRequest(t, uc)
+
+	if err := uc.Deny(ctxWithUser("approver"), id, models.DecisionRequest{Note: "not now"}); err != nil {
+		t.Fatalf("expected deny to succeed, got: %v", err)
+	}
+	if err := uc.Approve(ctxWithUser("approver"), id, models.DecisionRequest{}); err == nil {
+		t.Fatal("expected approving a denied request to fail")
+	}
+	if err := uc.Cancel(ctxWithUser("requester"), id); err == nil {
+		t.Fatal("expected cancelling a denied request to fail")
+	}
+	if len(roleRepository.addMembersCalls) != 0 {
+		t.Error("a denied request must not grant anything")
+	}
+}
+
+func TestApproveFailsWhenAuditFails(t *testing.T) {
+	_, _, activity, uc := newTestFixture()
+	id := createMemberRequest(t, uc)
+	activity.auditErr = errors.New("audit down")
+
+	if err := uc.Approve(ctxWithUser("approver"), id, models.DecisionRequest{}); err == nil {
+		t.Fatal("expected approve to surface the audit failure")
+	}
+}
+
+// === Cancel ===
+
+func TestCancelOnlyByRequester(t *testing.T) {
+	requestRepository, _, activity, uc := newTestFixture()
+	id := createMemberRequest(t, uc)
+
+	if err := uc.Cancel(ctxWithUser("approver"), id); err == nil {
+		t.Fatal("expected another user's cancel to be rejected")
+	}
+	if err := uc.Cancel(ctxWithUser("requester"), id); err != nil {
+		t.Fatalf("expected requester cancel to succeed, got: %v", err)
+	}
+	if got := requestRepository.requests[id].Status; got != int32(constants.AccessRequestStatusCancelled) {
+		t.Errorf("expected cancelled status, got %d", got)
+	}
+	last := activity.auditEntries[len(activity.auditEntries)-1]
+	if last.Type != activityConstants.ActivityTypeAccessRequestCancelled || last.TargetID != id {
+		t.Errorf("unexpected cancel audit: %+v", last)
+	}
+}
+
+// === Lists ===
+
+func TestListQueueScopedToApproverApps(t *testing.T) {
+	_, _, _, uc := newTestFixture()
+	memberID := createMemberRequest(t, uc)
+	if _, err := uc.Create(ctxWithUser("requester"), models.CreateRequest{
+		AppServiceID:  "app_medioa2",
+		RoleID:        "rol_editor",
+		Justification: "release day",
+		DurationHours: 2,
+	}); err != nil {
+		t.Fatalf("expected create to succeed, got: %v", err)
+	}
+
+	res, err := uc.ListQueue(ctxWithUser("approver"), models.ListRequest{})
+	if err != nil {
+		t.Fatalf("expected queue to list, got: %v", err)
+	}
+	if len(res.Items) != 1 || res.Items[0].ID != memberID {
+		t.Errorf("expected only the isme request in the queue, got %+v", res.Items)
+	}
+
+	res, err = uc.ListQueue(ctxWithUser("requester"), models.ListRequest{})
+	if err != nil {
+		t.Fatalf("expected queue to list, got: %v", err)
+	}
+	if len(res.Items) != 0 {
+		t.Errorf("a user approving in no app must see an empty queue, got %+v", res.Items)
+	}
+}
+
+func TestListRequestableRolesSkipsHeldAndDisabled(t *testing.T) {
+	_, roleRepository, _, uc := newTestFixture()
+	roleRepository.roleCodes["requester/app_isme"] = []string{"member"}
+
+	res, err := uc.ListRequestableRoles(ctxWithUser("requester"))
+	if err != nil {
+		t.Fatalf("expected requestable roles to list, got: %v", err)
+	}
+	got := []string{}
+	for _, item := range res.Items {
+		got = append(got, item.RoleID)
+	}
+	if !slices.Equal(got, []string{"rol_editor"}) {
+		t.Errorf("expected only rol_editor (member held, rainy disabled), got %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+// Package chain computes the tamper-evident hash chain over activity_events and
+// the HMAC signatures of prune checkpoints. It is pure (no I/O) so the
+// repository, the verifier and the offline CLI all hash rows identically.
+//
+// A row's hash is SHA-256 over a canonical JSON array of its fields followed by
+// prev_hash, so editing any field, re-ordering rows or deleting one from the
+// middle breaks the link to the next row. Deleting the newest rows cannot be
+// detected from the chain alone — compare the reported head against a copy
+// recorded elsewhere: events shipped to an external log sink carry their seq
+// and hash, so the sink's highest seq must not be past the head.
+package chain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/domains/activity/entity"
+)
+
+// Genesis is the prev_hash of the first row in the chain.
+var Genesis = strings.Repeat("0", sha256.Size*2)
+
+// Timestamp normalizes a created_at for hashing. Postgres TIMESTAMPTZ keeps
+// microseconds, so the chain is computed at that precision on every dialect
+// and a row hashes the same before and after a database round trip.
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Hash returns the chain hash of event, covering every persisted column
+// except the hash itself.
+func Hash(event entity.ActivityEvent) string {
+	payload, _ := json.Marshal([]any{
+		event.Seq,
+		event.ID,
+		event.UserID,
+		event.Type,
+		event.TargetType,
+		event.TargetID,
+		event.ClientIP,
+		event.UserAgent,
+		event.Meta,
+		Timestamp(event.CreatedAt).Format(time.RFC3339Nano),
+		event.PrevHash,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign returns the HMAC-SHA256 signature of checkpoint under key.
+func Sign(key []byte, checkpoint entity.ActivityCheckpoint) string {
+	payload, _ := json.Marshal([]any{
+		checkpoint.ID,
+		checkpoint.FromSeq,
+		checkpoint.ToSeq,
+		checkpoint.RowCount,
+		checkpoint.LastHash,
+		Timestamp(checkpoint.PrunedBefore).Format(time.RFC3339Nano),
+	})
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether checkpoint carries a valid signature under key.
+func Verify(key []byte, checkpoint entity.ActivityCheckpoint) bool {
+	expected, err := hex.DecodeString(Sign(key, checkpoint))
+	if err != nil {
+		return false
+	}
+	actual, err := hex.DecodeString(checkpoint.Signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
+
+// SigningKey returns the checkpoint signing key: AUDIT_CHAIN_KEY, falling back
+// to AES_SECRET so existing deployments sign checkpoints without new config.
+func SigningKey(cfg *config.Config) []byte {
+	if cfg.Audit.ChainKey != "" {
+		return []byte(cfg.Audit.ChainKey)
+	}
+	return []byte(cfg.AES.Secret)
+}
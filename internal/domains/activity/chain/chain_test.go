@@ -0,0 +1,86 @@
+package chain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/entity"
+)
+
+func sampleEvent() entity.ActivityEvent {
+	return entity.ActivityEvent{
+		ID:         "evt-1",
+		UserID:     "admin-1",
+		Type:       "role_updated",
+		Meta:       `{"after":{"name":"Ops"}}`,
+		CreatedAt:  time.Date(2026, 3, 1, 10, 0, 0, 123456789, time.UTC),
+		TargetType: "role",
+		TargetID:   "rol_1",
+		ClientIP:   "10.0.0.1",
+		UserAgent:  "curl/8",
+		Seq:        7,
+		PrevHash:   Genesis,
+	}
+}
+
+// TestHashCoversEveryField proves changing any hashed column changes the hash.
+func TestHashCoversEveryField(t *testing.T) {
+	base := Hash(sampleEvent())
+
+	edits := map[string]func(*entity.ActivityEvent){
+		"id":          func(e *entity.ActivityEvent) { e.ID = "evt-2" },
+		"user_id":     func(e *entity.ActivityEvent) { e.UserID = "admin-2" },
+		"type":        func(e *entity.ActivityEvent) { e.Type = "role_deleted" },
+		"meta":        func(e *entity.ActivityEvent) { e.Meta = `{}` },
+		"created_at":  func(e *entity.ActivityEvent) { e.CreatedAt = e.CreatedAt.Add(time.Second) },
+		"target_type": func(e *entity.ActivityEvent) { e.TargetType = "user" },
+		"target_id":   func(e *entity.ActivityEvent) { e.TargetID = "rol_2" },
+		"client_ip":   func(e *entity.ActivityEvent) { e.ClientIP = "10.0.0.2" },
+		"user_agent":  func(e *entity.ActivityEvent) { e.UserAgent = "wget" },
+		"seq":         func(e *entity.ActivityEvent) { e.Seq = 8 },
+		"prev_hash":   func(e *entity.ActivityEvent) { e.PrevHash = "ff" },
+	}
+	for name, edit := range edits {
+		event := sampleEvent()
+		edit(&event)
+		if Hash(event) == base {
+			t.Errorf("editing %s did not change the hash", name)
+		}
+	}
+}
+
+// TestHashIgnoresSubMicrosecondAndZone proves a database round trip (which
+// drops nanoseconds on Postgres and may change the zone) keeps the hash.
+func TestHashIgnoresSubMicrosecondAndZone(t *testing.T) {
+	event := sampleEvent()
+	base := Hash(event)
+
+	event.CreatedAt = event.CreatedAt.Truncate(time.Microsecond).In(time.FixedZone("ICT", 7*3600))
+	if Hash(event) != base {
+		t.Fatal("expected the hash to survive a microsecond/zone round trip")
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte("secret")
+	checkpoint := entity.ActivityCheckpoint{
+		ID:           "cp-1",
+		FromSeq:      1,
+		ToSeq:        10,
+		RowCount:     10,
+		LastHash:     "abc",
+		PrunedBefore: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	checkpoint.Signature = Sign(key, checkpoint)
+
+	if !Verify(key, checkpoint) {
+		t.Fatal("expected a freshly signed checkpoint to verify")
+	}
+	if Verify([]byte("other"), checkpoint) {
+		t.Fatal("expected a different key to fail verification")
+	}
+	checkpoint.ToSeq = 11
+	if Verify(key, checkpoint) {
+		t.Fatal("expected an edited checkpoint to fail verification")
+	}
+}
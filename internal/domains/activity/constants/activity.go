@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // Activity event types — the v1 taxonomy. The frontend maps each type to an
 // icon/tone/copy, so these strings are part of the API contract.
 const (
@@ -10,8 +12,102 @@ const (
 	ActivityTypeProfileUpdated  = "profile_updated"
 )
 
+// Admin audit event types — one per privileged mutation. They share the
+// activity_events table but always carry a target, which keeps them out of the
+// self-service Welcome feed (see ListByUserID).
+const (
+	// role
+	ActivityTypeRoleCreated                 = "role_created"
+	ActivityTypeRoleUpdated                 = "role_updated"
+	ActivityTypeRoleDeleted                 = "role_deleted"
+	ActivityTypeRolePermissionsSet          = "role_permissions_set"
+	ActivityTypeRoleIncludesSet             = "role_includes_set"
+	ActivityTypeRoleMembersAdded            = "role_members_added"
+	ActivityTypeRoleMemberRemoved           = "role_member_removed"
+	ActivityTypeRoleMemberExpired           = "role_member_expired"
+	ActivityTypePermissionsCreated          = "permissions_created"
+	ActivityTypePermissionDeleted           = "permission_deleted"
+	ActivityTypePermissionAppearanceUpdated = "permission_appearance_updated"
+	ActivityTypePermissionsSynced           = "permissions_synced"
+	// app_service
+	ActivityTypeAppServiceRegistered    = "app_service_registered"
+	ActivityTypeAppServiceSecretRotated = "app_service_secret_rotated"
+	ActivityTypeAppServiceStatusChanged = "app_service_status_changed"
+	ActivityTypeAppServiceUpdated       = "app_service_updated"
+	ActivityTypeAppServiceAuthFailed    = "app_service_auth_failed"
+	// user
+	ActivityTypeUserCreated        = "user_created"
+	ActivityTypeUserStatusChanged  = "user_status_changed"
+	ActivityTypeUserVerified       = "user_verified"
+	ActivityTypeUserDeleted        = "user_deleted"
+	ActivityTypeUserSessionRevoked = "user_session_revoked"
+	// user_invitation
+	ActivityTypeInvitationCreated  = "invitation_created"
+	ActivityTypeInvitationRevoked  = "invitation_revoked"
+	ActivityTypeInvitationAccepted = "invitation_accepted"
+	// access_request
+	ActivityTypeAccessRequestCreated   = "access_request_created"
+	ActivityTypeAccessRequestApproved  = "access_request_approved"
+	ActivityTypeAccessRequestDenied    = "access_request_denied"
+	ActivityTypeAccessRequestCancelled = "access_request_cancelled"
+	// group
+	ActivityTypeGroupCreated       = "group_created"
+	ActivityTypeGroupUpdated       = "group_updated"
+	ActivityTypeGroupDeleted       = "group_deleted"
+	ActivityTypeGroupMembersAdded  = "group_members_added"
+	ActivityTypeGroupMemberRemoved = "group_member_removed"
+	ActivityTypeGroupRolesSet      = "group_roles_set"
+	// settings
+	ActivityTypeScheduleUpdated = "schedule_updated"
+	// audit
+	ActivityTypeAuditExported = "audit_exported"
+)
+
+// Audit target types — the kind of resource an admin audit event acted on.
+const (
+	TargetTypeRole          = "role"
+	TargetTypePermission    = "permission"
+	TargetTypeAppService    = "app_service"
+	TargetTypeUser          = "user"
+	TargetTypeSession       = "session"
+	TargetTypeInvitation    = "invitation"
+	TargetTypeAccessRequest = "access_request"
+	TargetTypeGroup         = "group"
+	TargetTypeSchedule      = "schedule"
+	TargetTypeAuditLog      = "audit_log"
+)
+
+// AuditActorSystem is recorded as the actor when an audited mutation runs
+// without an authenticated caller on the context (e.g. an internal call).
+const AuditActorSystem = "system"
+
 // Limits for the "Recent activity" feed.
 const (
 	DefaultActivityLimit = 8
 	MaxActivityLimit     = 50
 )
+
+// Limits for the admin audit search. Export streams every matching row in
+// ExportPageSize keyset pages instead of honouring a limit.
+const (
+	DefaultAuditSearchLimit = 50
+	MaxAuditSearchLimit     = 200
+	AuditExportPageSize     = 500
+)
+
+// AuditExportTimeout bounds a streamed export. The body is written after the
+// handler returns, so the export runs on its own context, not the request's.
+const AuditExportTimeout = 10 * time.Minute
+
+// Audit export formats.
+const (
+	AuditExportFormatCSV    = "csv"
+	AuditExportFormatNDJSON = "ndjson"
+)
+
+// Hash chain verification limits. The verifier walks the chain in
+// ChainVerifyPageSize pages and reports at most MaxChainIssues problems.
+const (
+	ChainVerifyPageSize = 1000
+	MaxChainIssues      = 100
+)
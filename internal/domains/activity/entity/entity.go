@@ -8,9 +8,14 @@ import (
 )
 
 // ActivityEvent is an append-only audit record powering the Welcome "Recent
-// activity" feed. Meta holds a type-specific JSON blob (e.g. {device, client_ip}
-// for sign_in). The table grows unbounded — retention is deferred (see migration
-// 026 for the note).
+// activity" feed and the admin audit trail. UserID is always the ACTOR. Meta
+// holds a type-specific JSON blob (e.g. {device, client_ip} for sign_in, or the
+// {before, after} diff for an admin mutation). The target and request-origin
+// columns are empty for self-service events (see migration 032).
+//
+// Seq/PrevHash/Hash link the row into the tamper-evident hash chain (see
+// migration 034 and package chain). Seq is zero (NULL) on rows written before
+// the chain existed.
 type ActivityEvent struct {
 	bun.BaseModel `bun:"table:activity_events,alias:ae"`
 	ID            string    `bun:"id,pk,notnull"`
@@ -18,13 +23,54 @@ type ActivityEvent struct {
 	Type          string    `bun:"type,notnull"`
 	Meta          string    `bun:"meta,notnull,default:'{}'"`
 	CreatedAt     time.Time `bun:"created_at,default:current_timestamp,notnull"`
+	TargetType    string    `bun:"target_type,notnull,default:''"`
+	TargetID      string    `bun:"target_id,notnull,default:''"`
+	ClientIP      string    `bun:"client_ip,notnull,default:''"`
+	UserAgent     string    `bun:"user_agent,notnull,default:''"`
+	Seq           int64     `bun:"seq,nullzero"`
+	PrevHash      string    `bun:"prev_hash,notnull,default:''"`
+	Hash          string    `bun:"hash,notnull,default:''"`
+}
+
+// ActivityChainHead is the single row (ID 1) tracking the newest chained event.
+// Appends update it first so they serialize on its lock.
+type ActivityChainHead struct {
+	bun.BaseModel `bun:"table:activity_chain_head,alias:ach"`
+	ID            int64  `bun:"id,pk"`
+	Seq           int64  `bun:"seq,notnull"`
+	Hash          string `bun:"hash,notnull"`
+}
+
+// ActivityCheckpoint seals a pruned prefix of the hash chain. LastHash is the
+// hash of row ToSeq, i.e. the prev_hash the first surviving row links to, and
+// Signature is an HMAC over the checkpoint fields so a forged checkpoint cannot
+// paper over deleted rows.
+type ActivityCheckpoint struct {
+	bun.BaseModel `bun:"table:activity_checkpoints,alias:ac"`
+	ID            string    `bun:"id,pk,notnull"`
+	FromSeq       int64     `bun:"from_seq,notnull"`
+	ToSeq         int64     `bun:"to_seq,notnull"`
+	RowCount      int64     `bun:"row_count,notnull"`
+	LastHash      string    `bun:"last_hash,notnull"`
+	PrunedBefore  time.Time `bun:"pruned_before,notnull"`
+	Signature     string    `bun:"signature,notnull"`
+	CreatedAt     time.Time `bun:"created_at,default:current_timestamp,notnull"`
 }
 
 // === Hooks ===
 
 func (ae *ActivityEvent) BeforeAppendModel(ctx context.Context, query bun.Query) error {
-	if _, ok := query.(*bun.InsertQuery); ok {
+	// the chained Create stamps created_at itself (it is part of the hash), so
+	// only fill it when the caller left it empty.
+	if _, ok := query.(*bun.InsertQuery); ok && ae.CreatedAt.IsZero() {
 		ae.CreatedAt = time.Now().UTC()
 	}
 	return nil
 }
+
+func (ac *ActivityCheckpoint) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.InsertQuery); ok && ac.CreatedAt.IsZero() {
+		ac.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
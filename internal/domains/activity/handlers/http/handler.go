@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+
+	idi "github.com/vukyn/isme/internal/di"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/tracing"
+
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgHttp "github.com/vukyn/kuery/http/fiber"
+	"github.com/vukyn/kuery/log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SearchAuditEvents(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetActivityUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	searchRequest := models.AuditSearchRequest{}
+	if err := c.QueryParser(&searchRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	searchResponse, err := uc.SearchAudit(tracing.NewContextFromFiberCtx(c), searchRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, searchResponse)
+}
+
+func VerifyAuditChain(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetActivityUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	report, err := uc.VerifyChain(tracing.NewContextFromFiberCtx(c))
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, report)
+}
+
+// ExportAuditEvents streams the filtered audit trail as a download. The body is
+// written after the handler returns (fasthttp drives the stream writer), so the
+// request container is released from inside the writer rather than deferred,
+// and the rows are read on a detached context bounded by AuditExportTimeout —
+// fasthttp may recycle the request ctx by then. Once streaming has started the
+// status is already sent, so a mid-stream failure can only be logged and ends
+// the download early.
+func ExportAuditEvents(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+
+	uc, err := idi.GetActivityUsecase(ctn)
+	if err != nil {
+		ctn.Delete()
+		return pkgHttp.Err(c, err)
+	}
+
+	exportRequest := models.AuditExportRequest{}
+	if err := c.QueryParser(&exportRequest); err != nil {
+		ctn.Delete()
+		return pkgHttp.Err(c, err)
+	}
+
+	ctx := tracing.NewContextFromFiberCtx(c)
+	export, err := uc.ExportAudit(ctx, exportRequest)
+	if err != nil {
+		ctn.Delete()
+		return pkgHttp.Err(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer ctn.Delete()
+		ctx, cancel := context.WithTimeout(context.Background(), activityConstants.AuditExportTimeout)
+		defer cancel()
+		if err := export.Write(ctx, w); err != nil {
+			log.New().Errorf("audit export failed mid-stream: %v", err)
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	iapp "github.com/vukyn/isme/internal/app"
+	"github.com/vukyn/isme/internal/constants"
+	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/openapi"
+
+	"github.com/vukyn/kuery/rbac"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupActivityRoutes(router fiber.Router) {
+	middleware := idi.GetMiddleware(iapp.App)
+
+	rAudit := router.Group(constants.AUDIT_GROUP_NAME, middleware.AuthMiddleware)
+	// register the static /events/export ahead of any future /events/:id
+	rAudit.Get(constants.AUDIT_ENDPOINT_EVENTS_EXPORT, rbac.RequirePermission(roleConstants.PERM_AUDIT_READ), ExportAuditEvents)
+	rAudit.Get(constants.AUDIT_ENDPOINT_EVENTS, rbac.RequirePermission(roleConstants.PERM_AUDIT_READ), SearchAuditEvents)
+	rAudit.Get(constants.AUDIT_ENDPOINT_VERIFY, rbac.RequirePermission(roleConstants.PERM_AUDIT_READ), VerifyAuditChain)
+}
+
+// Operations documents the routes registered by SetupActivityRoutes; keep the
+// two in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: constants.AUDIT_GROUP_NAME + constants.AUDIT_ENDPOINT_EVENTS_EXPORT, Tag: "audit", Summary: "Download the filtered audit trail as CSV or NDJSON",
+		Query: models.AuditExportRequest{}, Produces: []string{"text/csv", "application/x-ndjson"}, Auth: true, Permission: roleConstants.PERM_AUDIT_READ},
+	{Method: fiber.MethodGet, Path: constants.AUDIT_GROUP_NAME + constants.AUDIT_ENDPOINT_EVENTS, Tag: "audit", Summary: "Search the audit trail",
+		Query: models.AuditSearchRequest{}, Response: models.AuditSearchResponse{}, Auth: true, Permission: roleConstants.PERM_AUDIT_READ},
+	{Method: fiber.MethodGet, Path: constants.AUDIT_GROUP_NAME + constants.AUDIT_ENDPOINT_VERIFY, Tag: "audit", Summary: "Verify the audit hash chain",
+		Response: models.ChainReport{}, Auth: true, Permission: roleConstants.PERM_AUDIT_READ},
+}
@@ -1,5 +1,13 @@
 package models
 
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
 // ActivityItem is a single recent-activity entry returned to the client. The
 // server emits a STRUCTURED record (type + meta) and the frontend composes the
 // display copy/icon/tone from the type — no server-composed body.
@@ -9,3 +17,182 @@ type ActivityItem struct {
 	Meta      map[string]any `json:"meta"`
 	CreatedAt string         `json:"created_at"`
 }
+
+// AuditEntry describes one privileged mutation to record in the admin audit
+// trail. The actor, client IP and user agent are taken from the request context
+// (pkgCtx) by the recorder; ActorID only overrides the actor for flows where the
+// caller is not yet authenticated (e.g. accepting an invitation).
+//
+// Before/After are any JSON-marshalable snapshots of the target. They are
+// reduced to a diff before persisting: only top-level keys whose value changed
+// are kept, so an update records just the edited fields while a create (nil
+// Before) or delete (nil After) records the full snapshot on one side.
+type AuditEntry struct {
+	Type       string
+	TargetType string
+	TargetID   string
+	ActorID    string
+	Before     any
+	After      any
+	// Meta carries extra context merged alongside the diff (e.g. app_id).
+	Meta map[string]any
+}
+
+// AuditSearchRequest filters the admin audit trail. Every filter is optional and
+// they combine with AND. Type accepts a comma-separated list of event types;
+// From/To are RFC3339 timestamps bounding created_at (From inclusive, To
+// exclusive); Query is a case-insensitive free-text match over the target id,
+// user agent and the meta blob. Cursor is the opaque next_cursor of a previous
+// page.
+type AuditSearchRequest struct {
+	ActorID    string `json:"actor_id" query:"actor_id"`
+	Type       string `json:"type" query:"type"`
+	TargetType string `json:"target_type" query:"target_type"`
+	TargetID   string `json:"target_id" query:"target_id"`
+	ClientIP   string `json:"ip" query:"ip"`
+	From       string `json:"from" query:"from"`
+	To         string `json:"to" query:"to"`
+	Query      string `json:"query" query:"query"`
+	Cursor     string `json:"cursor" query:"cursor"`
+	Limit      int    `json:"limit" query:"limit"`
+}
+
+// Filter parses the request into a repository filter. The cursor is decoded by
+// the usecase, so the returned filter starts at the newest row.
+func (r AuditSearchRequest) Filter() (AuditFilter, error) {
+	filter := AuditFilter{
+		ActorID:    strings.TrimSpace(r.ActorID),
+		TargetType: strings.TrimSpace(r.TargetType),
+		TargetID:   strings.TrimSpace(r.TargetID),
+		ClientIP:   strings.TrimSpace(r.ClientIP),
+		Query:      strings.TrimSpace(r.Query),
+	}
+	for _, eventType := range strings.Split(r.Type, ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+	if r.From != "" {
+		from, err := time.Parse(time.RFC3339, r.From)
+		if err != nil {
+			return AuditFilter{}, errors.New("from must be an RFC3339 timestamp")
+		}
+		filter.From = from.UTC()
+	}
+	if r.To != "" {
+		to, err := time.Parse(time.RFC3339, r.To)
+		if err != nil {
+			return AuditFilter{}, errors.New("to must be an RFC3339 timestamp")
+		}
+		filter.To = to.UTC()
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return AuditFilter{}, errors.New("from must be before to")
+	}
+	return filter, nil
+}
+
+// AuditExportRequest is an AuditSearchRequest plus the output format (csv or
+// ndjson). Cursor and Limit are ignored: an export streams every matching row.
+type AuditExportRequest struct {
+	AuditSearchRequest
+	Format string `json:"format" query:"format"`
+}
+
+// AuditFilter is the parsed, repository-level form of an audit search. The
+// After* pair is the keyset position: only rows strictly older than
+// (AfterCreatedAt, AfterID) in (created_at DESC, id DESC) order are returned.
+type AuditFilter struct {
+	ActorID    string
+	Types      []string
+	TargetType string
+	TargetID   string
+	ClientIP   string
+	From       time.Time
+	To         time.Time
+	Query      string
+
+	AfterCreatedAt time.Time
+	AfterID        string
+}
+
+// AuditEvent is one audit trail row as returned to an admin and shipped to the
+// sinks. Seq and Hash are its place in the hash chain; both are empty for rows
+// recorded before the chain began.
+type AuditEvent struct {
+	ID         string         `json:"id"`
+	Seq        int64          `json:"seq,omitempty"`
+	Hash       string         `json:"hash,omitempty"`
+	ActorID    string         `json:"actor_id"`
+	Type       string         `json:"type"`
+	TargetType string         `json:"target_type"`
+	TargetID   string         `json:"target_id"`
+	ClientIP   string         `json:"client_ip"`
+	UserAgent  string         `json:"user_agent"`
+	Meta       map[string]any `json:"meta"`
+	CreatedAt  string         `json:"created_at"`
+}
+
+// AuditSearchResponse is one page of the audit trail. NextCursor is empty on the
+// last page.
+type AuditSearchResponse struct {
+	Items      []AuditEvent `json:"items"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+// AuditExport is a validated export ready to stream. The handler sets the
+// headers from ContentType/Filename and then calls Write with the response body
+// writer; filters are checked before Write so a bad request fails with a normal
+// error response instead of a truncated download. Write takes its own ctx
+// because it runs after the request has been handed back to fasthttp.
+type AuditExport struct {
+	ContentType string
+	Filename    string
+	Write       func(ctx context.Context, w io.Writer) error
+}
+
+// ChainRange is a contiguous seq range of the hash chain; LastHash is the hash
+// of row ToSeq. RowCount is zero when the range is empty.
+type ChainRange struct {
+	FromSeq  int64
+	ToSeq    int64
+	RowCount int64
+	LastHash string
+}
+
+// Chain issue kinds reported by the verifier.
+const (
+	ChainIssueGap                 = "gap"
+	ChainIssueHashMismatch        = "hash_mismatch"
+	ChainIssueLinkMismatch        = "link_mismatch"
+	ChainIssueCheckpointSignature = "checkpoint_signature"
+	ChainIssueCheckpointGap       = "checkpoint_gap"
+	ChainIssueUnchained           = "unchained"
+	ChainIssueHeadMismatch        = "head_mismatch"
+)
+
+// ChainIssue is one problem found while walking the chain. Seq is the position
+// the problem was detected at; EventID is set when a stored row is involved.
+type ChainIssue struct {
+	Kind    string `json:"kind"`
+	Seq     int64  `json:"seq"`
+	EventID string `json:"event_id,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+// ChainReport is the result of verifying the audit hash chain. HeadSeq and
+// HeadHash identify the newest chained row: record them out-of-band to detect
+// truncation of the newest rows, which the chain alone cannot reveal.
+type ChainReport struct {
+	Valid           bool         `json:"valid"`
+	CheckedEvents   int64        `json:"checked_events"`
+	Checkpoints     int          `json:"checkpoints"`
+	PrunedEvents    int64        `json:"pruned_events"`
+	UnchainedEvents int64        `json:"unchained_events"`
+	HeadSeq         int64        `json:"head_seq"`
+	HeadHash        string       `json:"head_hash"`
+	Issues          []ChainIssue `json:"issues"`
+	// IssuesTruncated is set when more issues were found than reported.
+	IssuesTruncated bool   `json:"issues_truncated"`
+	VerifiedAt      string `json:"verified_at"`
+}
@@ -5,15 +5,41 @@ import (
 	"time"
 
 	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
 )
 
 type IRepository interface {
-	// Create appends an activity event. A ULID id is generated when empty.
-	Create(ctx context.Context, event entity.ActivityEvent) error
-	// ListByUserID returns the most recent events for a user, newest first.
+	// Create appends an activity event to the hash chain: it stamps created_at,
+	// assigns the next seq, links prev_hash to the current head and stores the
+	// row hash, filling those fields in on event. Appends are serialized so the
+	// chain never forks. A ULID id is generated when empty.
+	Create(ctx context.Context, event *entity.ActivityEvent) error
+	// ListByUserID returns the most recent self-service events for a user,
+	// newest first. Admin audit events (those with a target) are excluded so the
+	// Welcome feed is not flooded by an admin's own mutations.
 	ListByUserID(ctx context.Context, userID string, limit int) ([]entity.ActivityEvent, error)
+	// Search returns up to limit events across all users matching filter,
+	// newest first by (created_at, id). Unlike ListByUserID it includes both
+	// self-service and admin audit rows. Callers page by feeding the last row's
+	// created_at/id back as filter.AfterCreatedAt/AfterID.
+	Search(ctx context.Context, filter models.AuditFilter, limit int) ([]entity.ActivityEvent, error)
 	// PruneBefore deletes activity events created before the given time and
 	// returns the number of rows removed. Driven by the activity-cleanup
-	// scheduler to keep activity_events bounded.
+	// scheduler to keep activity_events bounded. Chained rows are pruned as a
+	// contiguous seq prefix (through ChainRangeBefore's ToSeq) so the surviving
+	// chain has no holes; run both in one transaction with the checkpoint write.
 	PruneBefore(ctx context.Context, before time.Time) (int64, error)
+	// ChainRangeBefore returns the chained prefix PruneBefore would delete.
+	ChainRangeBefore(ctx context.Context, before time.Time) (models.ChainRange, error)
+	// CreateCheckpoint stores a signed checkpoint of a pruned range.
+	CreateCheckpoint(ctx context.Context, checkpoint entity.ActivityCheckpoint) error
+	// ListCheckpoints returns every checkpoint, oldest range first.
+	ListCheckpoints(ctx context.Context) ([]entity.ActivityCheckpoint, error)
+	// ListChain returns up to limit chained events with seq > afterSeq, in seq
+	// order.
+	ListChain(ctx context.Context, afterSeq int64, limit int) ([]entity.ActivityEvent, error)
+	// GetChainHead returns the chain head row (newest seq and its hash).
+	GetChainHead(ctx context.Context) (entity.ActivityChainHead, error)
+	// CountUnchained counts rows outside the chain created at or after since.
+	CountUnchained(ctx context.Context, since time.Time) (int64, error)
 }
@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"time"
 
+	"github.com/vukyn/isme/internal/domains/activity/chain"
 	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	"github.com/uptrace/bun"
+	pkgBunQuery "github.com/vukyn/kuery/bun/query"
 	"github.com/vukyn/kuery/cryp"
 	pkgErr "github.com/vukyn/kuery/http/errors"
 )
@@ -21,7 +27,7 @@ func NewRepository(
 	return &repository{db: db}
 }
 
-func (r *repository) Create(ctx context.Context, event entity.ActivityEvent) error {
+func (r *repository) Create(ctx context.Context, event *entity.ActivityEvent) error {
 	if event.UserID == "" {
 		return pkgErr.InvalidRequest("user_id is required")
 	}
@@ -35,13 +41,49 @@ func (r *repository) Create(ctx context.Context, event entity.ActivityEvent) err
 		event.Meta = "{}"
 	}
 
-	_, err := r.db.NewInsert().
-		Model(&event).
-		Exec(ctx)
-	if err != nil {
-		return pkgErr.DatabaseError(err.Error())
-	}
-	return nil
+	// Joins the caller's transaction when there is one (an audited mutation),
+	// so the head bump, the row and the mutation commit together.
+	return transaction.NewRunner(r.db).Run(ctx, func(ctx context.Context) error {
+		conn := transaction.Conn(ctx, r.db)
+
+		// bump the head first: the UPDATE takes the lock that serializes appends
+		// until this transaction ends, so the read below sees the true head.
+		_, err := conn.NewUpdate().
+			Model((*entity.ActivityChainHead)(nil)).
+			Set("seq = seq + 1").
+			Where("id = 1").
+			Exec(ctx)
+		if err != nil {
+			return pkgErr.DatabaseError(err.Error())
+		}
+		head := entity.ActivityChainHead{}
+		if err := conn.NewSelect().Model(&head).Where("id = 1").Scan(ctx); err != nil {
+			return pkgErr.DatabaseError(err.Error())
+		}
+
+		event.Seq = head.Seq
+		event.PrevHash = head.Hash
+		// keep a caller-stamped created_at (the recorder fixes it up front so
+		// the copy it ships to the sinks matches); otherwise stamp it here.
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now()
+		}
+		event.CreatedAt = chain.Timestamp(event.CreatedAt)
+		event.Hash = chain.Hash(*event)
+
+		if _, err := conn.NewInsert().Model(event).Exec(ctx); err != nil {
+			return pkgErr.DatabaseError(err.Error())
+		}
+		_, err = conn.NewUpdate().
+			Model((*entity.ActivityChainHead)(nil)).
+			Set("hash = ?", event.Hash).
+			Where("id = 1").
+			Exec(ctx)
+		if err != nil {
+			return pkgErr.DatabaseError(err.Error())
+		}
+		return nil
+	})
 }
 
 func (r *repository) ListByUserID(ctx context.Context, userID string, limit int) ([]entity.ActivityEvent, error) {
@@ -50,9 +92,10 @@ func (r *repository) ListByUserID(ctx context.Context, userID string, limit int)
 	}
 
 	events := make([]entity.ActivityEvent, 0, limit)
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&events).
 		Where("user_id = ?", userID).
+		Where("target_type = ''").
 		Order("created_at DESC").
 		Limit(limit).
 		Scan(ctx)
@@ -62,11 +105,84 @@ func (r *repository) ListByUserID(ctx context.Context, userID string, limit int)
 	return events, nil
 }
 
+// likeEscaper escapes the LIKE wildcards in a search term; likeEscape names
+// the escape character for the pattern it produces.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+const likeEscape = ` ESCAPE '\'`
+
+func (r *repository) Search(ctx context.Context, filter models.AuditFilter, limit int) ([]entity.ActivityEvent, error) {
+	query := transaction.Conn(ctx, r.db).NewSelect().
+		Model((*entity.ActivityEvent)(nil))
+
+	if filter.ActorID != "" {
+		query = query.Where("user_id = ?", filter.ActorID)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN (?)", bun.In(filter.Types))
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.ClientIP != "" {
+		query = query.Where("client_ip = ?", filter.ClientIP)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.Query != "" {
+		// the query is matched literally: its own % and _ are escaped
+		search := "%" + likeEscaper.Replace(filter.Query) + "%"
+		// dialect-aware case-insensitive match (ILIKE on Postgres, LIKE on SQLite)
+		query = query.Where(
+			"("+pkgBunQuery.ILike(r.db, "target_id")+likeEscape+" OR "+
+				pkgBunQuery.ILike(r.db, "user_agent")+likeEscape+" OR "+
+				pkgBunQuery.ILike(r.db, "meta")+likeEscape+")",
+			search, search, search,
+		)
+	}
+	// keyset pagination: strictly after the previous page's last row in the
+	// (created_at DESC, id DESC) order, so concurrent inserts never shift pages.
+	if !filter.AfterCreatedAt.IsZero() {
+		query = query.Where(
+			"(created_at < ? OR (created_at = ? AND id < ?))",
+			filter.AfterCreatedAt, filter.AfterCreatedAt, filter.AfterID,
+		)
+	}
+
+	events := make([]entity.ActivityEvent, 0, limit)
+	err := query.
+		Order("created_at DESC", "id DESC").
+		Limit(limit).
+		Scan(ctx, &events)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+	return events, nil
+}
+
 func (r *repository) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
-	res, err := r.db.NewDelete().
-		Model((*entity.ActivityEvent)(nil)).
-		Where("created_at < ?", before).
-		Exec(ctx)
+	chainRange, err := r.ChainRangeBefore(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	// unchained (pre-034) rows go by age; chained rows go as the seq prefix
+	// ending at the newest expired row, so the survivors stay contiguous.
+	query := transaction.Conn(ctx, r.db).NewDelete().
+		Model((*entity.ActivityEvent)(nil))
+	if chainRange.RowCount > 0 {
+		query = query.Where("(seq IS NULL AND created_at < ?) OR seq <= ?", before, chainRange.ToSeq)
+	} else {
+		query = query.Where("seq IS NULL AND created_at < ?", before)
+	}
+	res, err := query.Exec(ctx)
 	if err != nil {
 		return 0, pkgErr.DatabaseError(err.Error())
 	}
@@ -76,3 +192,110 @@ func (r *repository) PruneBefore(ctx context.Context, before time.Time) (int64,
 	}
 	return count, nil
 }
+
+func (r *repository) ChainRangeBefore(ctx context.Context, before time.Time) (models.ChainRange, error) {
+	conn := transaction.Conn(ctx, r.db)
+
+	var bounds struct {
+		FromSeq  sql.NullInt64 `bun:"from_seq"`
+		ToSeq    sql.NullInt64 `bun:"to_seq"`
+		RowCount int64         `bun:"row_count"`
+	}
+	// ToSeq is the newest EXPIRED chained row; everything up to it goes, even a
+	// row stamped later that sits below it in the chain.
+	err := conn.NewSelect().
+		Model((*entity.ActivityEvent)(nil)).
+		ColumnExpr("MIN(seq) AS from_seq").
+		ColumnExpr("MAX(seq) AS to_seq").
+		ColumnExpr("COUNT(*) AS row_count").
+		Where("seq IS NOT NULL").
+		Where("seq <= (?)", conn.NewSelect().
+			Model((*entity.ActivityEvent)(nil)).
+			ColumnExpr("MAX(seq)").
+			Where("seq IS NOT NULL").
+			Where("created_at < ?", before)).
+		Scan(ctx, &bounds)
+	if err != nil {
+		return models.ChainRange{}, pkgErr.DatabaseError(err.Error())
+	}
+	if !bounds.ToSeq.Valid {
+		return models.ChainRange{}, nil
+	}
+
+	last := entity.ActivityEvent{}
+	err = conn.NewSelect().
+		Model(&last).
+		Column("hash").
+		Where("seq = ?", bounds.ToSeq.Int64).
+		Scan(ctx)
+	if err != nil {
+		return models.ChainRange{}, pkgErr.DatabaseError(err.Error())
+	}
+	return models.ChainRange{
+		FromSeq:  bounds.FromSeq.Int64,
+		ToSeq:    bounds.ToSeq.Int64,
+		RowCount: bounds.RowCount,
+		LastHash: last.Hash,
+	}, nil
+}
+
+func (r *repository) CreateCheckpoint(ctx context.Context, checkpoint entity.ActivityCheckpoint) error {
+	if checkpoint.ID == "" {
+		checkpoint.ID = cryp.ULID()
+	}
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
+		Model(&checkpoint).
+		Exec(ctx)
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
+func (r *repository) ListCheckpoints(ctx context.Context) ([]entity.ActivityCheckpoint, error) {
+	checkpoints := []entity.ActivityCheckpoint{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&checkpoints).
+		Order("to_seq ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+	return checkpoints, nil
+}
+
+func (r *repository) ListChain(ctx context.Context, afterSeq int64, limit int) ([]entity.ActivityEvent, error) {
+	events := make([]entity.ActivityEvent, 0, limit)
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&events).
+		Where("seq > ?", afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+	return events, nil
+}
+
+func (r *repository) GetChainHead(ctx context.Context) (entity.ActivityChainHead, error) {
+	head := entity.ActivityChainHead{}
+	if err := transaction.Conn(ctx, r.db).NewSelect().Model(&head).Where("id = 1").Scan(ctx); err != nil {
+		return entity.ActivityChainHead{}, pkgErr.DatabaseError(err.Error())
+	}
+	return head, nil
+}
+
+func (r *repository) CountUnchained(ctx context.Context, since time.Time) (int64, error) {
+	query := transaction.Conn(ctx, r.db).NewSelect().
+		Model((*entity.ActivityEvent)(nil)).
+		Where("seq IS NULL")
+	if !since.IsZero() {
+		query = query.Where("created_at >= ?", since)
+	}
+	count, err := query.Count(ctx)
+	if err != nil {
+		return 0, pkgErr.DatabaseError(err.Error())
+	}
+	return int64(count), nil
+}
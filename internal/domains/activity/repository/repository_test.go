@@ -3,12 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/domains/activity/chain"
 	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
 	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/sqlitedialect"
@@ -42,7 +46,7 @@ func TestCreateGeneratesIDAndDefaultsMeta(t *testing.T) {
 	db := newTestDB(t)
 	repository := NewRepository(db)
 
-	err := repository.Create(context.Background(), entity.ActivityEvent{
+	err := repository.Create(context.Background(), &entity.ActivityEvent{
 		UserID: "user-1",
 		Type:   activityConstants.ActivityTypeSignOut,
 	})
@@ -77,7 +81,7 @@ func TestListByUserIDNewestFirst(t *testing.T) {
 		activityConstants.ActivityTypePasswordChanged,
 		activityConstants.ActivityTypeSignOut,
 	} {
-		if err := repository.Create(context.Background(), entity.ActivityEvent{
+		if err := repository.Create(context.Background(), &entity.ActivityEvent{
 			ID:     "id-" + typ,
 			UserID: "user-1",
 			Type:   typ,
@@ -108,7 +112,7 @@ func TestListByUserIDLimit(t *testing.T) {
 	repository := NewRepository(db)
 
 	for i := 0; i < 5; i++ {
-		if err := repository.Create(context.Background(), entity.ActivityEvent{
+		if err := repository.Create(context.Background(), &entity.ActivityEvent{
 			UserID: "user-1",
 			Type:   activityConstants.ActivityTypeSignIn,
 		}); err != nil {
@@ -130,10 +134,10 @@ func TestListByUserIDScopedToUser(t *testing.T) {
 	db := newTestDB(t)
 	repository := NewRepository(db)
 
-	if err := repository.Create(context.Background(), entity.ActivityEvent{UserID: "user-1", Type: activityConstants.ActivityTypeSignIn}); err != nil {
+	if err := repository.Create(context.Background(), &entity.ActivityEvent{UserID: "user-1", Type: activityConstants.ActivityTypeSignIn}); err != nil {
 		t.Fatalf("create user-1: %v", err)
 	}
-	if err := repository.Create(context.Background(), entity.ActivityEvent{UserID: "user-2", Type: activityConstants.ActivityTypeSignIn}); err != nil {
+	if err := repository.Create(context.Background(), &entity.ActivityEvent{UserID: "user-2", Type: activityConstants.ActivityTypeSignIn}); err != nil {
 		t.Fatalf("create user-2: %v", err)
 	}
 
@@ -167,8 +171,45 @@ func TestListByUserIDEmptyNonNil(t *testing.T) {
 	}
 }
 
+// TestListByUserIDExcludesAuditEvents proves admin audit rows (which carry a
+// target) stay out of the self-service feed while remaining in the table.
+func TestListByUserIDExcludesAuditEvents(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+
+	if err := repository.Create(context.Background(), &entity.ActivityEvent{UserID: "admin-1", Type: activityConstants.ActivityTypeSignIn}); err != nil {
+		t.Fatalf("create sign_in: %v", err)
+	}
+	if err := repository.Create(context.Background(), &entity.ActivityEvent{
+		UserID:     "admin-1",
+		Type:       activityConstants.ActivityTypeRoleCreated,
+		TargetType: activityConstants.TargetTypeRole,
+		TargetID:   "rol_1",
+		ClientIP:   "10.0.0.1",
+		UserAgent:  "curl/8",
+	}); err != nil {
+		t.Fatalf("create role_created: %v", err)
+	}
+
+	events, err := repository.ListByUserID(context.Background(), "admin-1", 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != activityConstants.ActivityTypeSignIn {
+		t.Fatalf("expected only the sign_in event in the feed, got %+v", events)
+	}
+
+	count, err := db.NewSelect().Model((*entity.ActivityEvent)(nil)).Where("target_id = ?", "rol_1").Count(context.Background())
+	if err != nil {
+		t.Fatalf("count audit rows: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the audit row to be persisted, got %d", count)
+	}
+}
+
 // seedEventAt inserts an event and forces its created_at to the given time
-// (BeforeAppendModel stamps time.Now on insert, so we overwrite the column).
+// (Create stamps time.Now on insert, so we overwrite the column).
 func seedEventAt(t *testing.T, db *bun.DB, id string, createdAt time.Time) {
 	t.Helper()
 	if err := (&repository{db: db}).Create(context.Background(), entity.ActivityEvent{
@@ -228,3 +269,264 @@ func TestPruneBeforeEmptyTable(t *testing.T) {
 		t.Fatalf("expected 0 rows pruned on empty table, got %d", pruned)
 	}
 }
+
+// TestSearchFiltersAcrossUsers proves Search spans every actor (including
+// audit rows hidden from the feed) and that the filters combine with AND.
+func TestSearchFiltersAcrossUsers(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	ctx := context.Background()
+
+	for _, event := range []entity.ActivityEvent{
+		{ID: "e1", UserID: "user-1", Type: activityConstants.ActivityTypeSignIn, ClientIP: "10.0.0.1", Meta: `{"device":"Firefox"}`},
+		{ID: "e2", UserID: "admin-1", Type: activityConstants.ActivityTypeRoleCreated, TargetType: activityConstants.TargetTypeRole, TargetID: "rol_ops", ClientIP: "10.0.0.2", UserAgent: "curl/8"},
+		{ID: "e3", UserID: "admin-1", Type: activityConstants.ActivityTypeRoleDeleted, TargetType: activityConstants.TargetTypeRole, TargetID: "rol_old", ClientIP: "10.0.0.2"},
+	} {
+		if err := repository.Create(ctx, &event); err != nil {
+			t.Fatalf("create %s: %v", event.ID, err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter models.AuditFilter
+		want   int
+	}{
+		{"all", models.AuditFilter{}, 3},
+		{"actor", models.AuditFilter{ActorID: "admin-1"}, 2},
+		{"types", models.AuditFilter{Types: []string{activityConstants.ActivityTypeSignIn, activityConstants.ActivityTypeRoleDeleted}}, 2},
+		{"ip", models.AuditFilter{ClientIP: "10.0.0.1"}, 1},
+		{"target", models.AuditFilter{TargetType: activityConstants.TargetTypeRole, TargetID: "rol_ops"}, 1},
+		{"free text meta", models.AuditFilter{Query: "firefox"}, 1},
+		{"free text user agent", models.AuditFilter{Query: "CURL"}, 1},
+		{"percent is literal", models.AuditFilter{Query: "rol%"}, 0},
+		{"underscore is literal", models.AuditFilter{Query: "_"}, 2},
+		{"combined", models.AuditFilter{ActorID: "admin-1", ClientIP: "10.0.0.1"}, 0},
+	}
+	for _, tc := range cases {
+		events, err := repository.Search(ctx, tc.filter, 10)
+		if err != nil {
+			t.Fatalf("%s: search: %v", tc.name, err)
+		}
+		if len(events) != tc.want {
+			t.Errorf("%s: expected %d events, got %d", tc.name, tc.want, len(events))
+		}
+	}
+}
+
+// TestSearchDateRange proves From is inclusive and To exclusive.
+func TestSearchDateRange(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	seedEventAt(t, db, "old", now.Add(-48*time.Hour))
+	seedEventAt(t, db, "edge", now.Add(-24*time.Hour))
+	seedEventAt(t, db, "recent", now.Add(-1*time.Hour))
+
+	events, err := repository.Search(context.Background(), models.AuditFilter{
+		From: now.Add(-24 * time.Hour),
+		To:   now.Add(-1 * time.Hour),
+	}, 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "edge" {
+		t.Fatalf("expected only the edge event, got %+v", events)
+	}
+}
+
+// TestSearchKeysetPagination proves paging by the last row's (created_at, id)
+// visits every row exactly once, including rows sharing a timestamp.
+func TestSearchKeysetPagination(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	now := time.Now().UTC()
+
+	seedEventAt(t, db, "a", now.Add(-3*time.Minute))
+	seedEventAt(t, db, "b", now.Add(-2*time.Minute))
+	seedEventAt(t, db, "c", now.Add(-2*time.Minute))
+	seedEventAt(t, db, "d", now.Add(-1*time.Minute))
+
+	var seen []string
+	filter := models.AuditFilter{}
+	for page := 0; page < 4; page++ {
+		events, err := repository.Search(context.Background(), filter, 2)
+		if err != nil {
+			t.Fatalf("search page %d: %v", page, err)
+		}
+		for _, event := range events {
+			seen = append(seen, event.ID)
+		}
+		if len(events) < 2 {
+			break
+		}
+		last := events[len(events)-1]
+		filter.AfterCreatedAt, filter.AfterID = last.CreatedAt, last.ID
+	}
+
+	want := []string{"d", "c", "b", "a"}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+	}
+}
+
+// TestCreateLinksHashChain proves each append takes the next seq, links to the
+// previous hash, stores a hash that re-computes from the read-back row, and
+// advances the chain head.
+func TestCreateLinksHashChain(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := repository.Create(ctx, &entity.ActivityEvent{UserID: "user-1", Type: activityConstants.ActivityTypeSignIn}); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+
+	events, err := repository.ListChain(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListChain: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 chained events, got %d", len(events))
+	}
+	prevHash := chain.Genesis
+	for i, event := range events {
+		if event.Seq != int64(i+1) {
+			t.Fatalf("expected seq %d, got %d", i+1, event.Seq)
+		}
+		if event.PrevHash != prevHash {
+			t.Fatalf("seq %d does not link to the previous hash", event.Seq)
+		}
+		if chain.Hash(event) != event.Hash {
+			t.Fatalf("seq %d hash does not re-compute after a round trip", event.Seq)
+		}
+		prevHash = event.Hash
+	}
+
+	head, err := repository.GetChainHead(ctx)
+	if err != nil {
+		t.Fatalf("GetChainHead: %v", err)
+	}
+	if head.Seq != 3 || head.Hash != prevHash {
+		t.Fatalf("expected head at seq 3 with the last hash, got %+v", head)
+	}
+}
+
+// TestCreateRollbackDoesNotAdvanceChain proves an append inside a rolled-back
+// transaction leaves no hole: the head bump rolls back with the row.
+func TestCreateRollbackDoesNotAdvanceChain(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	ctx := context.Background()
+
+	err := transaction.NewRunner(db).Run(ctx, func(ctx context.Context) error {
+		if err := repository.Create(ctx, &entity.ActivityEvent{UserID: "admin-1", Type: activityConstants.ActivityTypeRoleCreated, TargetType: activityConstants.TargetTypeRole}); err != nil {
+			return err
+		}
+		return errors.New("mutation failed")
+	})
+	if err == nil {
+		t.Fatal("expected the rollback error")
+	}
+	if err := repository.Create(ctx, &entity.ActivityEvent{UserID: "user-1", Type: activityConstants.ActivityTypeSignIn}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	events, err := repository.ListChain(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListChain: %v", err)
+	}
+	if len(events) != 1 || events[0].Seq != 1 || events[0].PrevHash != chain.Genesis {
+		t.Fatalf("expected a single genesis-linked row at seq 1, got %+v", events)
+	}
+}
+
+// TestPruneBeforeKeepsChainContiguous proves the chained prune removes the seq
+// prefix through the newest expired row, even a row below it in the chain that
+// is newer by created_at, and that ChainRangeBefore describes exactly that.
+func TestPruneBeforeKeepsChainContiguous(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	seedEventAt(t, db, "s1", now.Add(-100*24*time.Hour))
+	seedEventAt(t, db, "s2", now.Add(-1*24*time.Hour)) // recent, but below an expired row
+	seedEventAt(t, db, "s3", now.Add(-95*24*time.Hour))
+	seedEventAt(t, db, "s4", now.Add(-1*time.Hour))
+	before := now.Add(-90 * 24 * time.Hour)
+
+	chainRange, err := repository.ChainRangeBefore(ctx, before)
+	if err != nil {
+		t.Fatalf("ChainRangeBefore: %v", err)
+	}
+	if chainRange.FromSeq != 1 || chainRange.ToSeq != 3 || chainRange.RowCount != 3 {
+		t.Fatalf("expected range 1-3 (3 rows), got %+v", chainRange)
+	}
+
+	events, err := repository.ListChain(ctx, 2, 1)
+	if err != nil {
+		t.Fatalf("ListChain: %v", err)
+	}
+	if chainRange.LastHash != events[0].Hash {
+		t.Fatal("expected LastHash to be the hash of seq 3")
+	}
+
+	pruned, err := repository.PruneBefore(ctx, before)
+	if err != nil {
+		t.Fatalf("PruneBefore: %v", err)
+	}
+	if pruned != 3 {
+		t.Fatalf("expected 3 rows pruned, got %d", pruned)
+	}
+	remaining, err := repository.ListChain(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("ListChain: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Seq != 4 || remaining[0].PrevHash != chainRange.LastHash {
+		t.Fatalf("expected seq 4 linked to the pruned range, got %+v", remaining)
+	}
+}
+
+// TestPruneBeforeUnchainedByAge proves rows from before the chain existed are
+// still pruned by age alone.
+func TestPruneBeforeUnchainedByAge(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for id, createdAt := range map[string]time.Time{
+		"legacy-old": now.Add(-100 * 24 * time.Hour),
+		"legacy-new": now.Add(-1 * 24 * time.Hour),
+	} {
+		if _, err := db.NewInsert().Model(&entity.ActivityEvent{
+			ID: id, UserID: "user-1", Type: activityConstants.ActivityTypeSignIn, Meta: "{}", CreatedAt: createdAt,
+		}).Exec(ctx); err != nil {
+			t.Fatalf("insert %s: %v", id, err)
+		}
+	}
+
+	pruned, err := repository.PruneBefore(ctx, now.Add(-90*24*time.Hour))
+	if err != nil {
+		t.Fatalf("PruneBefore: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected only the old legacy row pruned, got %d", pruned)
+	}
+	unchained, err := repository.CountUnchained(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("CountUnchained: %v", err)
+	}
+	if unchained != 1 {
+		t.Fatalf("expected 1 unchained row left, got %d", unchained)
+	}
+}
@@ -0,0 +1,141 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+// rotatedSuffixLayout timestamps rotated files; it sorts lexically in time
+// order, which pruning relies on.
+const rotatedSuffixLayout = "20060102T150405.000000000Z"
+
+// FileSink appends one JSON object per line to a local file, opened
+// O_APPEND so existing lines are never rewritten. When the file would grow past
+// maxSize it is renamed to "<path>.<UTC timestamp>" and a fresh file started;
+// only the newest maxBackups rotated files are kept. Each batch is fsynced.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	s := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *FileSink) Write(ctx context.Context, events []models.AuditEvent) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+	for i, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return i, err
+		}
+		line = append(line, '\n')
+		if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+			if err := s.rotate(); err != nil {
+				return i, err
+			}
+		}
+		n, err := s.file.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return i, err
+		}
+	}
+	// the lines are written; a failed sync is reported but not retried, as a
+	// retry would append them again
+	return len(events), s.file.Sync()
+}
+
+// rotate leaves s.file nil on any failure, so the next Write reopens the path
+// instead of writing to a closed handle.
+func (s *FileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+	rotated := s.path + "." + time.Now().UTC().Format(rotatedSuffixLayout)
+	if err := os.Rename(s.path, rotated); err != nil {
+		return err
+	}
+	if err := s.pruneBackups(); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// pruneBackups removes the oldest rotated files beyond maxBackups.
+func (s *FileSink) pruneBackups() error {
+	if s.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return err
+	}
+	if len(backups) <= s.maxBackups {
+		return nil
+	}
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-s.maxBackups] {
+		if err := os.Remove(backup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
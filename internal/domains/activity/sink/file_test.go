@@ -0,0 +1,121 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+func readJSONLines(t *testing.T, path string) []models.AuditEvent {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+	var events []models.AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestFileSinkAppendsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "events.jsonl")
+
+	s, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	if _, err := s.Write(context.Background(), []models.AuditEvent{{ID: "a"}, {ID: "b"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	s.Close()
+
+	s, err = NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Write(context.Background(), []models.AuditEvent{{ID: "c"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	events := readJSONLines(t, path)
+	if len(events) != 3 || events[0].ID != "a" || events[2].ID != "c" {
+		t.Fatalf("unexpected file contents %+v", events)
+	}
+}
+
+func TestFileSinkRotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+
+	line, _ := json.Marshal(models.AuditEvent{ID: "x"})
+	// room for two lines per file
+	s, err := NewFileSink(path, int64(2*(len(line)+1)), 2)
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 9; i++ {
+		if _, err := s.Write(context.Background(), []models.AuditEvent{{ID: "x"}}); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+
+	if got := len(readJSONLines(t, path)); got != 1 {
+		t.Fatalf("active file should hold the ninth event only, has %d", got)
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 rotated files to be kept, got %v", backups)
+	}
+	for _, backup := range backups {
+		if got := len(readJSONLines(t, backup)); got != 2 {
+			t.Fatalf("rotated file %s has %d lines, want 2", backup, got)
+		}
+	}
+}
+
+func TestFileSinkRecoversFromFailedRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	line, _ := json.Marshal(models.AuditEvent{ID: "x"})
+	s, err := NewFileSink(path, int64(len(line)+1), 0)
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Write(context.Background(), []models.AuditEvent{{ID: "x"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// closing the handle underneath makes the rotate's Close fail
+	s.file.Close()
+	if n, err := s.Write(context.Background(), []models.AuditEvent{{ID: "y"}}); err == nil || n != 0 {
+		t.Fatalf("write over a failed rotate = (%d, %v), want (0, error)", n, err)
+	}
+
+	if _, err := s.Write(context.Background(), []models.AuditEvent{{ID: "y"}}); err != nil {
+		t.Fatalf("write after the failed rotate: %v", err)
+	}
+	// the reopened file is still full, so this write rotates it for real
+	if events := readJSONLines(t, path); len(events) != 1 || events[0].ID != "y" {
+		t.Fatalf("unexpected file contents %+v", events)
+	}
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 1 {
+		t.Fatalf("expected the first line in one rotated file, got %v", backups)
+	}
+}
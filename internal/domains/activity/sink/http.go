@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+// HTTPSink POSTs each batch as a JSON array to a collector endpoint. Any
+// non-2xx response is an error, so the dispatcher retries the batch.
+type HTTPSink struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+func NewHTTPSink(endpoint, token string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		endpoint: endpoint,
+		token:    token,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+func (s *HTTPSink) Write(ctx context.Context, events []models.AuditEvent) (int, error) {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain so the keep-alive connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, fmt.Errorf("collector responded %s", resp.Status)
+	}
+	// the collector takes the batch as a whole
+	return len(events), nil
+}
+
+func (s *HTTPSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
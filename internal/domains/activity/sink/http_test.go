@@ -0,0 +1,51 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+func TestHTTPSinkPostsBatch(t *testing.T) {
+	var (
+		gotAuth   string
+		gotEvents []models.AuditEvent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotEvents)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSink(srv.URL, "secret", time.Second)
+	defer s.Close()
+
+	events := []models.AuditEvent{{ID: "a", Type: "login"}, {ID: "b", Type: "logout"}}
+	if _, err := s.Write(context.Background(), events); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Fatalf("unexpected Authorization %q", gotAuth)
+	}
+	if len(gotEvents) != 2 || gotEvents[1].Type != "logout" {
+		t.Fatalf("unexpected body %+v", gotEvents)
+	}
+}
+
+func TestHTTPSinkNon2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSink(srv.URL, "", time.Second)
+	if _, err := s.Write(context.Background(), []models.AuditEvent{{ID: "a"}}); err == nil {
+		t.Fatal("expected a 503 to fail the write")
+	}
+}
@@ -0,0 +1,250 @@
+// Package sink ships activity events to external log destinations (syslog, a
+// JSON-lines file, an HTTP collector) in addition to the activity_events table.
+//
+// The recorder never talks to a sink directly: it hands each committed event
+// to a Dispatcher, which queues it per sink and returns immediately. One
+// goroutine per sink drains its queue in batches, so a slow or unreachable
+// sink only ever fills its own bounded buffer — once full, further events for
+// that sink are dropped and counted instead of blocking Login or any other
+// audited action. The database row remains the source of truth.
+//
+// A failed batch is retried from the first event the sink did not deliver, so
+// a partial write is not re-sent. Delivery is still at-least-once — a write
+// that times out after the destination accepted it is retried whole — so each
+// event carries its chain seq and hash, and a receiver dedupes on seq.
+package sink
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+
+	"github.com/vukyn/kuery/log"
+)
+
+// Sink writes a batch of events to one external destination. Write is called
+// from a single goroutine per sink and returns how many events, from the front
+// of the batch, were delivered; on an error the dispatcher retries the rest.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, events []models.AuditEvent) (int, error)
+	Close() error
+}
+
+// Publisher accepts committed events for shipping. Publish must not block.
+type Publisher interface {
+	Publish(event models.AuditEvent)
+}
+
+// NoopPublisher discards events. Used when no sink is configured and by tests
+// and CLIs that build the activity usecase without a dispatcher.
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(event models.AuditEvent) {}
+
+// Options tunes the dispatcher's buffering and retry behaviour.
+type Options struct {
+	// BufferSize is the per-sink queue capacity.
+	BufferSize int
+	// BatchSize caps the events handed to one Write.
+	BatchSize int
+	// FlushInterval bounds how long a partial batch waits.
+	FlushInterval time.Duration
+	// MaxRetries is how many times a failed batch is retried before it is
+	// dropped; the wait doubles from RetryBackoff on each attempt.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// WriteTimeout bounds a single Write call.
+	WriteTimeout time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.BufferSize <= 0 {
+		o.BufferSize = 1024
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 200 * time.Millisecond
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = 10 * time.Second
+	}
+	return o
+}
+
+// Stats is a point-in-time view of one sink's delivery counters.
+type Stats struct {
+	Queued  int   `json:"queued"`
+	Written int64 `json:"written"`
+	Dropped int64 `json:"dropped"`
+	Failed  int64 `json:"failed"`
+}
+
+// Dispatcher fans events out to its sinks through per-sink bounded queues.
+type Dispatcher struct {
+	mu      sync.RWMutex
+	closed  bool
+	workers []*worker
+	wg      sync.WaitGroup
+}
+
+type worker struct {
+	sink    Sink
+	opts    Options
+	queue   chan models.AuditEvent
+	written atomic.Int64
+	// dropped counts events rejected because the queue was full (or the
+	// dispatcher closed); failed counts events in batches that exhausted their
+	// retries.
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewDispatcher starts one worker per sink.
+func NewDispatcher(opts Options, sinks ...Sink) *Dispatcher {
+	opts = opts.withDefaults()
+	d := &Dispatcher{}
+	for _, s := range sinks {
+		w := &worker{sink: s, opts: opts, queue: make(chan models.AuditEvent, opts.BufferSize)}
+		d.workers = append(d.workers, w)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			w.run()
+		}()
+	}
+	return d
+}
+
+// Publish enqueues event on every sink without blocking. A sink whose queue is
+// full misses the event; the drop is counted and logged at 1, 2, 4, 8, ...
+// drops so a stuck sink cannot flood the log either.
+func (d *Dispatcher) Publish(event models.AuditEvent) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, w := range d.workers {
+		if d.closed {
+			w.dropped.Add(1)
+			continue
+		}
+		select {
+		case w.queue <- event:
+		default:
+			if n := w.dropped.Add(1); n&(n-1) == 0 {
+				log.New().Warnf("Audit sink %s is falling behind: %d event(s) dropped so far", w.sink.Name(), n)
+			}
+		}
+	}
+}
+
+// Stats returns the delivery counters keyed by sink name.
+func (d *Dispatcher) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(d.workers))
+	for _, w := range d.workers {
+		stats[w.sink.Name()] = Stats{
+			Queued:  len(w.queue),
+			Written: w.written.Load(),
+			Dropped: w.dropped.Load(),
+			Failed:  w.failed.Load(),
+		}
+	}
+	return stats
+}
+
+// Close stops accepting events, lets every worker flush what is queued, and
+// closes the sinks. It gives up waiting when ctx is done; events still queued
+// at that point are lost (they remain in activity_events).
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for _, w := range d.workers {
+		if err := w.sink.Close(); err != nil {
+			log.New().Errorf("Audit sink %s: close failed: %v", w.sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (w *worker) run() {
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.AuditEvent, 0, w.opts.BatchSize)
+	for {
+		select {
+		case event, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= w.opts.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush writes batch, retrying with exponential backoff from the first event
+// not yet delivered. While it retries the queue keeps absorbing (and, once
+// full, dropping) new events.
+func (w *worker) flush(batch []models.AuditEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	backoff := w.opts.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= w.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), w.opts.WriteTimeout)
+		var n int
+		n, err = w.sink.Write(ctx, batch)
+		cancel()
+		n = min(max(n, 0), len(batch))
+		w.written.Add(int64(n))
+		batch = batch[n:]
+		if err == nil || len(batch) == 0 {
+			return
+		}
+	}
+	w.failed.Add(int64(len(batch)))
+	log.New().Errorf("Audit sink %s: dropping %d event(s) after %d attempt(s): %v", w.sink.Name(), len(batch), w.opts.MaxRetries+1, err)
+}
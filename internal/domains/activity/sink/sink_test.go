@@ -0,0 +1,173 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+// recordingSink captures every batch; block, when set, stalls Write until it
+// is closed so tests can simulate a hung destination. A failing Write first
+// delivers up to partial events of the batch.
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]models.AuditEvent
+	block   chan struct{}
+	fail    int
+	partial int
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Write(ctx context.Context, events []models.AuditEvent) (int, error) {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail > 0 {
+		s.fail--
+		n := min(s.partial, len(events))
+		if n > 0 {
+			s.batches = append(s.batches, append([]models.AuditEvent(nil), events[:n]...))
+		}
+		return n, errors.New("collector unavailable")
+	}
+	s.batches = append(s.batches, append([]models.AuditEvent(nil), events...))
+	return len(events), nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) events() []models.AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []models.AuditEvent
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func TestDispatcherBatchesAndFlushesOnClose(t *testing.T) {
+	rec := &recordingSink{}
+	d := NewDispatcher(Options{BatchSize: 2, FlushInterval: time.Hour}, rec)
+
+	for _, id := range []string{"a", "b", "c"} {
+		d.Publish(models.AuditEvent{ID: id})
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(rec.batches) != 2 || len(rec.batches[0]) != 2 || len(rec.batches[1]) != 1 {
+		t.Fatalf("expected batches of 2 and 1, got %v", rec.batches)
+	}
+	got := rec.events()
+	if got[0].ID != "a" || got[1].ID != "b" || got[2].ID != "c" {
+		t.Fatalf("events out of order: %v", got)
+	}
+	if stats := d.Stats()["recording"]; stats.Written != 3 || stats.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestDispatcherFlushesPartialBatchOnInterval(t *testing.T) {
+	rec := &recordingSink{}
+	d := NewDispatcher(Options{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, rec)
+	defer d.Close(context.Background())
+
+	d.Publish(models.AuditEvent{ID: "a"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.events()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was never flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherPublishNeverBlocksOnStuckSink(t *testing.T) {
+	rec := &recordingSink{block: make(chan struct{})}
+	d := NewDispatcher(Options{BufferSize: 2, BatchSize: 1, FlushInterval: time.Hour}, rec)
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		d.Publish(models.AuditEvent{ID: "x"})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("publish blocked for %s", elapsed)
+	}
+
+	stats := d.Stats()["recording"]
+	// one event is held by the blocked Write, two fill the queue
+	if stats.Dropped < 47 {
+		t.Fatalf("expected overflow to be dropped, got %+v", stats)
+	}
+
+	close(rec.block)
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := int64(len(rec.events())) + d.Stats()["recording"].Dropped; got != 50 {
+		t.Fatalf("written + dropped = %d, want 50", got)
+	}
+}
+
+func TestDispatcherRetriesFailedBatch(t *testing.T) {
+	rec := &recordingSink{fail: 2}
+	d := NewDispatcher(Options{BatchSize: 1, MaxRetries: 2, RetryBackoff: time.Millisecond}, rec)
+
+	d.Publish(models.AuditEvent{ID: "a"})
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if got := rec.events(); len(got) != 1 {
+		t.Fatalf("expected the batch to succeed on the third attempt, got %v", got)
+	}
+	if stats := d.Stats()["recording"]; stats.Failed != 0 || stats.Written != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+// TestDispatcherRetriesOnlyUndeliveredEvents proves a retry after a partial
+// write resumes at the first undelivered event instead of re-sending the batch.
+func TestDispatcherRetriesOnlyUndeliveredEvents(t *testing.T) {
+	rec := &recordingSink{fail: 1, partial: 2}
+	d := NewDispatcher(Options{BatchSize: 3, FlushInterval: time.Hour, MaxRetries: 1, RetryBackoff: time.Millisecond}, rec)
+
+	for _, id := range []string{"a", "b", "c"} {
+		d.Publish(models.AuditEvent{ID: id})
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	got := rec.events()
+	if len(got) != 3 || got[0].ID != "a" || got[1].ID != "b" || got[2].ID != "c" {
+		t.Fatalf("expected a, b, c delivered once each, got %v", got)
+	}
+	if stats := d.Stats()["recording"]; stats.Written != 3 || stats.Failed != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestDispatcherDropsBatchAfterRetries(t *testing.T) {
+	rec := &recordingSink{fail: 10}
+	d := NewDispatcher(Options{BatchSize: 1, MaxRetries: 1, RetryBackoff: time.Millisecond}, rec)
+
+	d.Publish(models.AuditEvent{ID: "a"})
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if stats := d.Stats()["recording"]; stats.Failed != 1 || stats.Written != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,193 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+const (
+	// syslogFacilityAudit is facility 13, "log audit" (RFC 5424 §6.2.1).
+	syslogFacilityAudit = 13
+	// admin mutations are notable; self-service events are routine.
+	syslogSeverityNotice = 5
+	syslogSeverityInfo   = 6
+	// syslogSDID names the structured-data element. 32473 is the private
+	// enterprise number reserved for documentation (RFC 5612).
+	syslogSDID = "isme@32473"
+)
+
+// SyslogSink sends each event as an RFC 5424 message. Over UDP every message
+// is one datagram; over TCP messages are octet-counted (RFC 6587 §3.4.1) so
+// the collector can frame messages containing newlines. The connection is
+// dialled lazily and re-dialled after a write error.
+type SyslogSink struct {
+	network  string
+	address  string
+	appName  string
+	hostname string
+	procID   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink parses address as "udp://host:port" or "tcp://host:port".
+func NewSyslogSink(address, appName string) (*SyslogSink, error) {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", address, err)
+	}
+	if parsed.Scheme != "udp" && parsed.Scheme != "tcp" {
+		return nil, fmt.Errorf("syslog address %q must use udp:// or tcp://", address)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("syslog address %q has no host", address)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	if appName == "" {
+		appName = "-"
+	}
+	return &SyslogSink{
+		network:  parsed.Scheme,
+		address:  parsed.Host,
+		appName:  appName,
+		hostname: hostname,
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+func (s *SyslogSink) Name() string {
+	return "syslog"
+}
+
+func (s *SyslogSink) Write(ctx context.Context, events []models.AuditEvent) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{}
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return 0, err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+
+	for i, event := range events {
+		message := FormatRFC5424(event, s.hostname, s.appName, s.procID)
+		if s.network == "tcp" {
+			message = strconv.Itoa(len(message)) + " " + message
+		}
+		if _, err := s.conn.Write([]byte(message)); err != nil {
+			// drop the connection so the retry re-dials
+			_ = s.conn.Close()
+			s.conn = nil
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// FormatRFC5424 renders event as a syslog message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ELEMENT] MSG
+//
+// MSGID is the event type, the structured data carries the searchable fields
+// and MSG is the full event as JSON.
+func FormatRFC5424(event models.AuditEvent, hostname, appName, procID string) string {
+	severity := syslogSeverityInfo
+	if event.TargetType != "" {
+		severity = syslogSeverityNotice
+	}
+	timestamp := event.CreatedAt
+	if timestamp == "" {
+		timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		body = []byte("{}")
+	}
+
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	seq := ""
+	if event.Seq > 0 {
+		seq = strconv.FormatInt(event.Seq, 10)
+	}
+	for _, param := range [][2]string{
+		{"id", event.ID},
+		{"seq", seq},
+		{"actor", event.ActorID},
+		{"target_type", event.TargetType},
+		{"target_id", event.TargetID},
+		{"client_ip", event.ClientIP},
+	} {
+		if param[1] == "" {
+			continue
+		}
+		sd.WriteString(" " + param[0] + `="` + escapeSDParam(param[1]) + `"`)
+	}
+	sd.WriteString("]")
+
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		syslogFacilityAudit*8+severity,
+		timestamp,
+		syslogHeaderField(hostname, 255),
+		syslogHeaderField(appName, 48),
+		syslogHeaderField(procID, 128),
+		syslogHeaderField(event.Type, 32),
+		sd.String(),
+		body,
+	)
+}
+
+// escapeSDParam escapes the three characters RFC 5424 §6.3.3 reserves inside
+// a PARAM-VALUE.
+func escapeSDParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// syslogHeaderField restricts a header field to printable US-ASCII without
+// spaces and to its maximum length, using the NILVALUE "-" when empty.
+func syslogHeaderField(value string, maxLen int) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if len(cleaned) > maxLen {
+		cleaned = cleaned[:maxLen]
+	}
+	if cleaned == "" {
+		return "-"
+	}
+	return cleaned
+}
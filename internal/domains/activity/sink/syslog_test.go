@@ -0,0 +1,142 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+func TestFormatRFC5424(t *testing.T) {
+	event := models.AuditEvent{
+		ID:         "01J0",
+		ActorID:    "usr_admin",
+		Type:       "role_assigned",
+		TargetType: "user",
+		TargetID:   `usr_"quoted"]\`,
+		ClientIP:   "10.0.0.1",
+		CreatedAt:  "2026-01-02T03:04:05.123456Z",
+	}
+
+	msg := FormatRFC5424(event, "host one", "isme", "42")
+
+	// facility 13 (audit) * 8 + severity 5 (notice)
+	wantPrefix := `<109>1 2026-01-02T03:04:05.123456Z hostone isme 42 role_assigned [isme@32473 id="01J0" actor="usr_admin" target_type="user" target_id="usr_\"quoted\"\]\\" client_ip="10.0.0.1"] `
+	if !strings.HasPrefix(msg, wantPrefix) {
+		t.Fatalf("unexpected message:\n got %s\nwant %s...", msg, wantPrefix)
+	}
+	var body models.AuditEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(msg, wantPrefix)), &body); err != nil {
+		t.Fatalf("MSG is not the event JSON: %v", err)
+	}
+	if body.TargetID != event.TargetID {
+		t.Fatalf("MSG lost the target id: %+v", body)
+	}
+}
+
+func TestFormatRFC5424SelfServiceIsInfo(t *testing.T) {
+	msg := FormatRFC5424(models.AuditEvent{Type: "login", ActorID: "usr_1"}, "h", "", "1")
+	if !strings.HasPrefix(msg, "<110>1 ") {
+		t.Fatalf("expected severity info, got %s", msg)
+	}
+	if !strings.Contains(msg, " h - 1 login [isme@32473 actor=\"usr_1\"] ") {
+		t.Fatalf("expected NILVALUE app name and sparse SD, got %s", msg)
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	s, err := NewSyslogSink("udp://"+conn.LocalAddr().String(), "isme")
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	defer s.Close()
+
+	events := []models.AuditEvent{{ID: "a", Type: "login"}, {ID: "b", Type: "logout"}}
+	if _, err := s.Write(context.Background(), events); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	for _, want := range []string{" login ", " logout "} {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if got := string(buf[:n]); !strings.HasPrefix(got, "<110>1 ") || !strings.Contains(got, want) {
+			t.Fatalf("unexpected datagram %q", got)
+		}
+	}
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var frames []string
+		for len(frames) < 2 {
+			prefix, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(prefix))
+			frame := make([]byte, size)
+			if _, err := reader.Read(frame); err != nil {
+				break
+			}
+			frames = append(frames, string(frame))
+		}
+		received <- frames
+	}()
+
+	s, err := NewSyslogSink("tcp://"+ln.Addr().String(), "isme")
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	defer s.Close()
+
+	events := []models.AuditEvent{{ID: "a", Type: "login"}, {ID: "b", Type: "logout"}}
+	if _, err := s.Write(context.Background(), events); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	select {
+	case frames := <-received:
+		if len(frames) != 2 || !strings.Contains(frames[0], " login ") || !strings.Contains(frames[1], " logout ") {
+			t.Fatalf("unexpected frames %q", frames)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("collector received nothing")
+	}
+}
+
+func TestNewSyslogSinkRejectsBadAddress(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:514", "http://host:514", "udp://"} {
+		if _, err := NewSyslogSink(address, "isme"); err == nil {
+			t.Fatalf("expected %q to be rejected", address)
+		}
+	}
+}
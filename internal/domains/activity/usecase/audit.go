@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/constants"
+	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+
+	pkgErr "github.com/vukyn/kuery/http/errors"
+)
+
+// auditCSVHeader is the column order of a CSV export. meta is emitted as its
+// raw JSON blob so nested before/after diffs survive the flat format.
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "type", "target_type", "target_id", "client_ip", "user_agent", "meta"}
+
+func (u *usecase) SearchAudit(ctx context.Context, req models.AuditSearchRequest) (models.AuditSearchResponse, error) {
+	filter, err := req.Filter()
+	if err != nil {
+		return models.AuditSearchResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+	if req.Cursor != "" {
+		filter.AfterCreatedAt, filter.AfterID, err = decodeAuditCursor(req.Cursor)
+		if err != nil {
+			return models.AuditSearchResponse{}, pkgErr.InvalidRequest("invalid cursor")
+		}
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = constants.DefaultAuditSearchLimit
+	}
+	if limit > constants.MaxAuditSearchLimit {
+		limit = constants.MaxAuditSearchLimit
+	}
+
+	// fetch one extra row to learn whether a next page exists without a COUNT.
+	events, err := u.activityRepo.Search(ctx, filter, limit+1)
+	if err != nil {
+		return models.AuditSearchResponse{}, err
+	}
+
+	response := models.AuditSearchResponse{Items: make([]models.AuditEvent, 0, min(len(events), limit))}
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[len(events)-1]
+		response.NextCursor = encodeAuditCursor(last.CreatedAt, last.ID)
+	}
+	for _, event := range events {
+		response.Items = append(response.Items, toAuditEvent(event))
+	}
+	return response, nil
+}
+
+func (u *usecase) ExportAudit(ctx context.Context, req models.AuditExportRequest) (models.AuditExport, error) {
+	filter, err := req.Filter()
+	if err != nil {
+		return models.AuditExport{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if format == "" {
+		format = constants.AuditExportFormatCSV
+	}
+	export := models.AuditExport{}
+	switch format {
+	case constants.AuditExportFormatCSV:
+		export.ContentType = "text/csv; charset=utf-8"
+		export.Filename = "audit-events.csv"
+	case constants.AuditExportFormatNDJSON:
+		export.ContentType = "application/x-ndjson"
+		export.Filename = "audit-events.ndjson"
+	default:
+		return models.AuditExport{}, pkgErr.InvalidRequest("format must be csv or ndjson")
+	}
+
+	// Reading the trail is itself sensitive: record who exported what before
+	// any row leaves the server.
+	if err := u.RecordAudit(ctx, models.AuditEntry{
+		Type:       constants.ActivityTypeAuditExported,
+		TargetType: constants.TargetTypeAuditLog,
+		Meta: map[string]any{
+			"format": format,
+			"filter": req.AuditSearchRequest,
+		},
+	}); err != nil {
+		return models.AuditExport{}, err
+	}
+
+	export.Write = func(ctx context.Context, w io.Writer) error {
+		if format == constants.AuditExportFormatNDJSON {
+			return u.writeAuditNDJSON(ctx, filter, w)
+		}
+		return u.writeAuditCSV(ctx, filter, w)
+	}
+	return export, nil
+}
+
+// eachAuditPage walks every event matching filter in AuditExportPageSize
+// keyset pages, calling fn once per page.
+func (u *usecase) eachAuditPage(ctx context.Context, filter models.AuditFilter, fn func([]entity.ActivityEvent) error) error {
+	for {
+		events, err := u.activityRepo.Search(ctx, filter, constants.AuditExportPageSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := fn(events); err != nil {
+			return err
+		}
+		if len(events) < constants.AuditExportPageSize {
+			return nil
+		}
+		last := events[len(events)-1]
+		filter.AfterCreatedAt, filter.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+func (u *usecase) writeAuditCSV(ctx context.Context, filter models.AuditFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+	err := u.eachAuditPage(ctx, filter, func(events []entity.ActivityEvent) error {
+		for _, event := range events {
+			if err := writer.Write([]string{
+				event.ID,
+				formatAuditTime(event.CreatedAt),
+				event.UserID,
+				event.Type,
+				event.TargetType,
+				event.TargetID,
+				event.ClientIP,
+				event.UserAgent,
+				event.Meta,
+			}); err != nil {
+				return err
+			}
+		}
+		// flush per page so the client sees progress on a long export
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (u *usecase) writeAuditNDJSON(ctx context.Context, filter models.AuditFilter, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return u.eachAuditPage(ctx, filter, func(events []entity.ActivityEvent) error {
+		for _, event := range events {
+			if err := encoder.Encode(toAuditEvent(event)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func toAuditEvent(event entity.ActivityEvent) models.AuditEvent {
+	meta := map[string]any{}
+	if event.Meta != "" {
+		// best-effort, as in List: a malformed blob yields an empty map.
+		_ = json.Unmarshal([]byte(event.Meta), &meta)
+	}
+	return models.AuditEvent{
+		ID:         event.ID,
+		Seq:        event.Seq,
+		Hash:       event.Hash,
+		ActorID:    event.UserID,
+		Type:       event.Type,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		ClientIP:   event.ClientIP,
+		UserAgent:  event.UserAgent,
+		Meta:       meta,
+		CreatedAt:  formatAuditTime(event.CreatedAt),
+	}
+}
+
+func formatAuditTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// encodeAuditCursor packs a keyset position into an opaque URL-safe token. The
+// timestamp keeps full nanosecond precision so the next page resumes exactly
+// after the last row even when several rows share a second.
+func encodeAuditCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAuditCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	createdAtRaw, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return time.Time{}, "", pkgErr.InvalidRequest("invalid cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return createdAt, id, nil
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/domains/activity/constants"
+	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
+)
+
+// searchRepo serves a fixed newest-first event list and honours the keyset
+// position, so tests can walk pages exactly like the real repository. It also
+// captures created events (the export audit row).
+type searchRepo struct {
+	fakeRepository
+	events  []entity.ActivityEvent
+	filters []models.AuditFilter
+}
+
+func (s *searchRepo) Search(ctx context.Context, filter models.AuditFilter, limit int) ([]entity.ActivityEvent, error) {
+	s.filters = append(s.filters, filter)
+	start := 0
+	if filter.AfterID != "" {
+		for i, event := range s.events {
+			if event.ID == filter.AfterID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := min(start+limit, len(s.events))
+	return s.events[start:end], nil
+}
+
+// newSearchRepo seeds n events newest first, one second apart.
+func newSearchRepo(n int) *searchRepo {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &searchRepo{}
+	for i := 0; i < n; i++ {
+		repo.events = append(repo.events, entity.ActivityEvent{
+			ID:         fmt.Sprintf("evt-%04d", n-i),
+			UserID:     "admin-1",
+			Type:       constants.ActivityTypeRoleUpdated,
+			TargetType: constants.TargetTypeRole,
+			TargetID:   "rol_1",
+			Meta:       `{"after":{"name":"Ops, \"core\""}}`,
+			CreatedAt:  base.Add(-time.Duration(i) * time.Second),
+		})
+	}
+	return repo
+}
+
+func TestSearchAuditPaginatesWithCursor(t *testing.T) {
+	repo := newSearchRepo(5)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	var seen []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		response, err := uc.SearchAudit(context.Background(), models.AuditSearchRequest{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("SearchAudit page %d: %v", page, err)
+		}
+		for _, item := range response.Items {
+			seen = append(seen, item.ID)
+		}
+		cursor = response.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("expected all 5 events across pages, got %v", seen)
+	}
+	if seen[0] != "evt-0005" || seen[4] != "evt-0001" {
+		t.Fatalf("expected newest-first order, got %v", seen)
+	}
+	// the second query resumes strictly after the first page's last row
+	if got := repo.filters[1].AfterID; got != "evt-0004" {
+		t.Fatalf("expected the cursor to carry evt-0004, got %q", got)
+	}
+	if !repo.filters[1].AfterCreatedAt.Equal(repo.events[1].CreatedAt) {
+		t.Fatalf("expected the cursor timestamp to round-trip, got %v", repo.filters[1].AfterCreatedAt)
+	}
+}
+
+func TestSearchAuditRejectsInvalidInput(t *testing.T) {
+	uc := NewUsecase(newSearchRepo(1), &config.Config{}, sink.NoopPublisher{})
+
+	cases := map[string]models.AuditSearchRequest{
+		"bad from":       {From: "yesterday"},
+		"inverted range": {From: "2026-01-02T00:00:00Z", To: "2026-01-01T00:00:00Z"},
+		"bad cursor":     {Cursor: "not-a-cursor"},
+	}
+	for name, req := range cases {
+		if _, err := uc.SearchAudit(context.Background(), req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSearchAuditParsesFilters(t *testing.T) {
+	repo := newSearchRepo(1)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	_, err := uc.SearchAudit(context.Background(), models.AuditSearchRequest{
+		ActorID:  "admin-1",
+		Type:     "role_created, role_deleted",
+		ClientIP: "10.0.0.1",
+		From:     "2026-01-01T00:00:00Z",
+		To:       "2026-01-02T00:00:00Z",
+		Query:    "ops",
+	})
+	if err != nil {
+		t.Fatalf("SearchAudit: %v", err)
+	}
+	filter := repo.filters[0]
+	if len(filter.Types) != 2 || filter.Types[1] != constants.ActivityTypeRoleDeleted {
+		t.Fatalf("expected the comma-separated types to split, got %v", filter.Types)
+	}
+	if filter.ActorID != "admin-1" || filter.ClientIP != "10.0.0.1" || filter.Query != "ops" {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+	if filter.From.IsZero() || filter.To.IsZero() {
+		t.Fatalf("expected the date range to parse, got %+v", filter)
+	}
+}
+
+// TestExportAuditCSVStreamsEveryPage proves the export walks past the page size
+// and escapes the raw meta JSON as a single CSV field.
+func TestExportAuditCSVStreamsEveryPage(t *testing.T) {
+	total := constants.AuditExportPageSize + 3
+	repo := newSearchRepo(total)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	export, err := uc.ExportAudit(context.Background(), models.AuditExportRequest{Format: "csv"})
+	if err != nil {
+		t.Fatalf("ExportAudit: %v", err)
+	}
+	if export.ContentType != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", export.ContentType)
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(context.Background(), &buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != total+1 {
+		t.Fatalf("expected header + %d rows, got %d", total, len(records))
+	}
+	if records[1][8] != repo.events[0].Meta {
+		t.Fatalf("expected meta to round-trip, got %q", records[1][8])
+	}
+	if len(repo.filters) != 2 {
+		t.Fatalf("expected 2 keyset pages, got %d", len(repo.filters))
+	}
+}
+
+func TestExportAuditNDJSON(t *testing.T) {
+	repo := newSearchRepo(3)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	export, err := uc.ExportAudit(context.Background(), models.AuditExportRequest{Format: "ndjson"})
+	if err != nil {
+		t.Fatalf("ExportAudit: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := export.Write(context.Background(), &buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event models.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %d is not JSON: %v", lines, err)
+		}
+		if event.ActorID != "admin-1" || event.Meta["after"] == nil {
+			t.Fatalf("unexpected event %+v", event)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Fatalf("expected 3 lines, got %d", lines)
+	}
+}
+
+// TestExportAuditRecordsExport proves the export itself lands in the trail,
+// and an unknown format is rejected before anything is recorded.
+func TestExportAuditRecordsExport(t *testing.T) {
+	repo := newSearchRepo(1)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	if _, err := uc.ExportAudit(context.Background(), models.AuditExportRequest{Format: "xml"}); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("expected no audit row for a rejected export, got %d", len(repo.created))
+	}
+
+	if _, err := uc.ExportAudit(context.Background(), models.AuditExportRequest{
+		AuditSearchRequest: models.AuditSearchRequest{ActorID: "admin-1"},
+	}); err != nil {
+		t.Fatalf("ExportAudit: %v", err)
+	}
+	if len(repo.created) != 1 {
+		t.Fatalf("expected 1 audit row, got %d", len(repo.created))
+	}
+	event := repo.created[0]
+	if event.Type != constants.ActivityTypeAuditExported || event.TargetType != constants.TargetTypeAuditLog {
+		t.Fatalf("unexpected audit row %+v", event)
+	}
+	if decodeMeta(t, event.Meta)["format"] != constants.AuditExportFormatCSV {
+		t.Fatalf("expected csv to be the default format, got %s", event.Meta)
+	}
+}
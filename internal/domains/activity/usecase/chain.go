@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/activity/chain"
+	"github.com/vukyn/isme/internal/domains/activity/constants"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+// chainVerifier accumulates issues while walking the chain, capping the list
+// so a badly damaged table does not produce an unbounded report.
+type chainVerifier struct {
+	report *models.ChainReport
+}
+
+func (v chainVerifier) add(kind string, seq int64, eventID, detail string) {
+	if len(v.report.Issues) >= constants.MaxChainIssues {
+		v.report.IssuesTruncated = true
+		return
+	}
+	v.report.Issues = append(v.report.Issues, models.ChainIssue{Kind: kind, Seq: seq, EventID: eventID, Detail: detail})
+}
+
+func (u *usecase) VerifyChain(ctx context.Context) (models.ChainReport, error) {
+	report := models.ChainReport{Issues: []models.ChainIssue{}}
+	verifier := chainVerifier{report: &report}
+
+	// 1. checkpoints: each must be authentic and pick up where the previous
+	// one ended; together they stand in for the pruned prefix.
+	checkpoints, err := u.activityRepo.ListCheckpoints(ctx)
+	if err != nil {
+		return models.ChainReport{}, err
+	}
+	report.Checkpoints = len(checkpoints)
+	expectedSeq, prevHash := int64(1), chain.Genesis
+	for _, checkpoint := range checkpoints {
+		if !chain.Verify(u.chainKey, checkpoint) {
+			verifier.add(models.ChainIssueCheckpointSignature, checkpoint.ToSeq, "",
+				fmt.Sprintf("checkpoint %s has an invalid signature", checkpoint.ID))
+		}
+		if checkpoint.FromSeq != expectedSeq {
+			verifier.add(models.ChainIssueCheckpointGap, expectedSeq, "",
+				fmt.Sprintf("checkpoint %s starts at seq %d, expected %d", checkpoint.ID, checkpoint.FromSeq, expectedSeq))
+		}
+		report.PrunedEvents += checkpoint.RowCount
+		expectedSeq, prevHash = checkpoint.ToSeq+1, checkpoint.LastHash
+	}
+
+	// 2. the head is read before the walk, which stops at the head's seq: a
+	// row recorded while we walk lands past it (the head advances in the same
+	// transaction as the row), so a concurrent insert is never mistaken for a
+	// head mismatch.
+	head, err := u.activityRepo.GetChainHead(ctx)
+	if err != nil {
+		return models.ChainReport{}, err
+	}
+
+	// 3. live rows in seq order, up to the head: contiguous, correctly linked,
+	// unmodified.
+	var firstChainedAt time.Time
+	lastSeq := int64(0)
+	for walking := true; walking; {
+		events, err := u.activityRepo.ListChain(ctx, lastSeq, constants.ChainVerifyPageSize)
+		if err != nil {
+			return models.ChainReport{}, err
+		}
+		for _, event := range events {
+			if event.Seq > head.Seq {
+				walking = false
+				break
+			}
+			if firstChainedAt.IsZero() {
+				firstChainedAt = event.CreatedAt
+			}
+			if event.Seq != expectedSeq {
+				// a hole: the link check is meaningless across it, so resync
+				verifier.add(models.ChainIssueGap, expectedSeq, event.ID,
+					fmt.Sprintf("seq %d-%d missing", expectedSeq, event.Seq-1))
+			} else if event.PrevHash != prevHash {
+				verifier.add(models.ChainIssueLinkMismatch, event.Seq, event.ID,
+					"prev_hash does not match the previous row")
+			}
+			if chain.Hash(event) != event.Hash {
+				verifier.add(models.ChainIssueHashMismatch, event.Seq, event.ID,
+					"row contents do not match its hash")
+			}
+			report.CheckedEvents++
+			expectedSeq, prevHash = event.Seq+1, event.Hash
+			lastSeq = event.Seq
+		}
+		if len(events) < constants.ChainVerifyPageSize {
+			walking = false
+		}
+	}
+	report.HeadSeq, report.HeadHash = expectedSeq-1, prevHash
+
+	// 4. the head row must agree with the newest row it covers, else rows were
+	// cut off the end.
+	if head.Seq != report.HeadSeq || head.Hash != report.HeadHash {
+		verifier.add(models.ChainIssueHeadMismatch, head.Seq, "",
+			fmt.Sprintf("chain head records seq %d but the newest row is seq %d", head.Seq, report.HeadSeq))
+	}
+
+	// 5. rows written outside the chain after it started were inserted
+	// directly, bypassing the recorder. Older unchained rows predate the chain.
+	if !firstChainedAt.IsZero() {
+		unchained, err := u.activityRepo.CountUnchained(ctx, firstChainedAt)
+		if err != nil {
+			return models.ChainReport{}, err
+		}
+		report.UnchainedEvents = unchained
+		if unchained > 0 {
+			verifier.add(models.ChainIssueUnchained, 0, "",
+				fmt.Sprintf("%d row(s) created after the chain began carry no seq", unchained))
+		}
+	}
+
+	report.Valid = len(report.Issues) == 0
+	report.VerifiedAt = time.Now().UTC().Format(time.RFC3339)
+	return report, nil
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/domains/activity/chain"
+	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
+)
+
+// chainRepo serves an in-memory chain to the verifier.
+type chainRepo struct {
+	fakeRepository
+	events      []entity.ActivityEvent
+	checkpoints []entity.ActivityCheckpoint
+	head        entity.ActivityChainHead
+	unchained   int64
+}
+
+func (c *chainRepo) ListChain(ctx context.Context, afterSeq int64, limit int) ([]entity.ActivityEvent, error) {
+	page := []entity.ActivityEvent{}
+	for _, event := range c.events {
+		if event.Seq > afterSeq && len(page) < limit {
+			page = append(page, event)
+		}
+	}
+	return page, nil
+}
+
+func (c *chainRepo) ListCheckpoints(ctx context.Context) ([]entity.ActivityCheckpoint, error) {
+	return c.checkpoints, nil
+}
+
+func (c *chainRepo) GetChainHead(ctx context.Context) (entity.ActivityChainHead, error) {
+	return c.head, nil
+}
+
+func (c *chainRepo) CountUnchained(ctx context.Context, since time.Time) (int64, error) {
+	return c.unchained, nil
+}
+
+const testChainKey = "chain-key"
+
+func newChainConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Audit.ChainKey = testChainKey
+	return cfg
+}
+
+// buildChain returns a valid chain of n rows with the head pointing at the
+// last one.
+func buildChain(n int) *chainRepo {
+	repo := &chainRepo{}
+	prevHash := chain.Genesis
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		event := entity.ActivityEvent{
+			ID:        fmt.Sprintf("evt-%d", i),
+			UserID:    "admin-1",
+			Type:      "role_updated",
+			Meta:      "{}",
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			Seq:       int64(i),
+			PrevHash:  prevHash,
+		}
+		event.Hash = chain.Hash(event)
+		prevHash = event.Hash
+		repo.events = append(repo.events, event)
+	}
+	repo.head = entity.ActivityChainHead{ID: 1, Seq: int64(n), Hash: prevHash}
+	return repo
+}
+
+func issueKinds(report models.ChainReport) []string {
+	kinds := []string{}
+	for _, issue := range report.Issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return kinds
+}
+
+func TestVerifyChainValid(t *testing.T) {
+	repo := buildChain(5)
+	report, err := NewUsecase(repo, newChainConfig(), sink.NoopPublisher{}).VerifyChain(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !report.Valid || report.CheckedEvents != 5 || report.HeadSeq != 5 {
+		t.Fatalf("expected a valid 5-row chain, got %+v", report)
+	}
+}
+
+func TestVerifyChainDetectsTampering(t *testing.T) {
+	cases := map[string]struct {
+		tamper func(*chainRepo)
+		want   string
+	}{
+		"edited row": {
+			tamper: func(c *chainRepo) { c.events[2].Meta = `{"forged":true}` },
+			want:   models.ChainIssueHashMismatch,
+		},
+		"deleted row": {
+			tamper: func(c *chainRepo) { c.events = append(c.events[:2], c.events[3:]...) },
+			want:   models.ChainIssueGap,
+		},
+		"rehashed row": {
+			// an attacker who recomputes an edited row's hash still breaks the
+			// next row's link
+			tamper: func(c *chainRepo) {
+				c.events[2].Meta = `{"forged":true}`
+				c.events[2].Hash = chain.Hash(c.events[2])
+			},
+			want: models.ChainIssueLinkMismatch,
+		},
+		"truncated tail": {
+			tamper: func(c *chainRepo) { c.events = c.events[:4] },
+			want:   models.ChainIssueHeadMismatch,
+		},
+		"inserted outside chain": {
+			tamper: func(c *chainRepo) { c.unchained = 2 },
+			want:   models.ChainIssueUnchained,
+		},
+	}
+	for name, tc := range cases {
+		repo := buildChain(5)
+		tc.tamper(repo)
+		report, err := NewUsecase(repo, newChainConfig(), sink.NoopPublisher{}).VerifyChain(context.Background())
+		if err != nil {
+			t.Fatalf("%s: VerifyChain: %v", name, err)
+		}
+		if report.Valid {
+			t.Errorf("%s: expected the chain to be invalid", name)
+		}
+		kinds := issueKinds(report)
+		if len(kinds) == 0 || kinds[0] != tc.want {
+			t.Errorf("%s: expected first issue %q, got %v", name, tc.want, kinds)
+		}
+	}
+}
+
+// TestVerifyChainStopsAtTheHead proves a row recorded after the head was read
+// (a concurrent insert) is left for the next run instead of reported as a
+// head mismatch.
+func TestVerifyChainStopsAtTheHead(t *testing.T) {
+	repo := buildChain(6)
+	repo.head = entity.ActivityChainHead{ID: 1, Seq: 5, Hash: repo.events[4].Hash}
+	report, err := NewUsecase(repo, newChainConfig(), sink.NoopPublisher{}).VerifyChain(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !report.Valid || report.CheckedEvents != 5 || report.HeadSeq != 5 {
+		t.Fatalf("expected the walk to stop at the head, got %+v", report)
+	}
+}
+
+// TestVerifyChainResumesFromCheckpoint proves a signed checkpoint stands in
+// for a pruned prefix, and a forged one is reported.
+func TestVerifyChainResumesFromCheckpoint(t *testing.T) {
+	repo := buildChain(5)
+	checkpoint := entity.ActivityCheckpoint{
+		ID:           "cp-1",
+		FromSeq:      1,
+		ToSeq:        2,
+		RowCount:     2,
+		LastHash:     repo.events[1].Hash,
+		PrunedBefore: time.Date(2026, 1, 1, 0, 3, 0, 0, time.UTC),
+	}
+	checkpoint.Signature = chain.Sign([]byte(testChainKey), checkpoint)
+	repo.checkpoints = []entity.ActivityCheckpoint{checkpoint}
+	repo.events = repo.events[2:]
+
+	report, err := NewUsecase(repo, newChainConfig(), sink.NoopPublisher{}).VerifyChain(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !report.Valid || report.PrunedEvents != 2 || report.CheckedEvents != 3 {
+		t.Fatalf("expected the pruned chain to verify, got %+v", report)
+	}
+
+	repo.checkpoints[0].Signature = chain.Sign([]byte("attacker"), checkpoint)
+	report, err = NewUsecase(repo, newChainConfig(), sink.NoopPublisher{}).VerifyChain(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if report.Valid || issueKinds(report)[0] != models.ChainIssueCheckpointSignature {
+		t.Fatalf("expected a forged checkpoint to be reported, got %+v", report.Issues)
+	}
+}
@@ -18,6 +18,23 @@ type IUseCase interface {
 	RecordProfileUpdated(ctx context.Context, userID string)
 	// RecordInvitationSent records an invitation send. Best-effort.
 	RecordInvitationSent(ctx context.Context, inviterID, email string, roleNames []string)
+	// RecordAudit records a privileged admin mutation with its actor, target,
+	// before/after diff and request origin. Unlike the Record* helpers above it
+	// is NOT best-effort: callers invoke it inside the mutation's transaction and
+	// a returned error rolls the change back, so no audited change commits
+	// without its audit row.
+	RecordAudit(ctx context.Context, entry models.AuditEntry) error
+	// SearchAudit returns one page of the audit trail across all users, newest
+	// first, with an opaque cursor for the next page.
+	SearchAudit(ctx context.Context, req models.AuditSearchRequest) (models.AuditSearchResponse, error)
+	// ExportAudit validates an export request and returns a writer that streams
+	// every matching event as CSV or NDJSON in keyset pages, so an export never
+	// holds the full result in memory. The export itself is audited.
+	ExportAudit(ctx context.Context, req models.AuditExportRequest) (models.AuditExport, error)
+	// VerifyChain walks the audit hash chain from the oldest checkpoint to the
+	// head and reports gaps, edited rows, broken links, forged checkpoints and
+	// rows inserted outside the chain.
+	VerifyChain(ctx context.Context) (models.ChainReport, error)
 	// List returns the caller's most recent activity items, newest first.
 	List(ctx context.Context, userID string, limit int) ([]models.ActivityItem, error)
 }
@@ -3,28 +3,62 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 	"time"
 
+	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/domains/activity/chain"
 	"github.com/vukyn/isme/internal/domains/activity/constants"
 	"github.com/vukyn/isme/internal/domains/activity/entity"
 	"github.com/vukyn/isme/internal/domains/activity/models"
 	activityRepo "github.com/vukyn/isme/internal/domains/activity/repository"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
+	"github.com/vukyn/isme/internal/transaction"
 
+	"github.com/vukyn/kuery/cryp"
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgErr "github.com/vukyn/kuery/http/errors"
 	"github.com/vukyn/kuery/log"
 )
 
 type usecase struct {
 	activityRepo activityRepo.IRepository
+	// chainKey verifies the signatures of prune checkpoints.
+	chainKey []byte
+	// publisher ships committed events to the external sinks; it never blocks.
+	publisher sink.Publisher
 }
 
 func NewUsecase(
 	activityRepo activityRepo.IRepository,
+	cfg *config.Config,
+	publisher sink.Publisher,
 ) IUseCase {
 	return &usecase{
 		activityRepo: activityRepo,
+		chainKey:     chain.SigningKey(cfg),
+		publisher:    publisher,
 	}
 }
 
+// create writes event and, once the surrounding transaction (if any) commits,
+// hands it to the sinks. ID and created_at are fixed up front and Create fills
+// in the chain fields, so the shipped copy matches the stored row.
+func (u *usecase) create(ctx context.Context, event entity.ActivityEvent) error {
+	event.ID = cryp.ULID()
+	event.CreatedAt = chain.Timestamp(time.Now())
+	if event.Meta == "" {
+		event.Meta = "{}"
+	}
+	if err := u.activityRepo.Create(ctx, &event); err != nil {
+		return err
+	}
+	transaction.AfterCommit(ctx, func() {
+		u.publisher.Publish(toAuditEvent(event))
+	})
+	return nil
+}
+
 // record is the shared best-effort emit path: it marshals the meta map, writes
 // the event, and on any failure logs and returns — it NEVER propagates an error,
 // so a recorder failure can never fail the action being audited.
@@ -37,7 +71,7 @@ func (u *usecase) record(ctx context.Context, userID, eventType string, meta map
 		log.New().Errorf("activity: failed to marshal meta for %s: %v", eventType, err)
 		return
 	}
-	if err := u.activityRepo.Create(ctx, entity.ActivityEvent{
+	if err := u.create(ctx, entity.ActivityEvent{
 		UserID: userID,
 		Type:   eventType,
 		Meta:   string(metaJSON),
@@ -76,6 +110,102 @@ func (u *usecase) RecordInvitationSent(ctx context.Context, inviterID, email str
 	})
 }
 
+func (u *usecase) RecordAudit(ctx context.Context, entry models.AuditEntry) error {
+	if entry.Type == "" || entry.TargetType == "" {
+		return pkgErr.InvalidRequest("audit type and target type are required")
+	}
+
+	actorID := entry.ActorID
+	if actorID == "" {
+		actorID = pkgCtx.GetUserID(ctx)
+	}
+	if actorID == "" {
+		actorID = constants.AuditActorSystem
+	}
+
+	before, after, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		return pkgErr.InternalServerError("failed to build audit diff: " + err.Error())
+	}
+	meta := make(map[string]any, len(entry.Meta)+2)
+	for key, value := range entry.Meta {
+		meta[key] = value
+	}
+	if before != nil {
+		meta["before"] = before
+	}
+	if after != nil {
+		meta["after"] = after
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return pkgErr.InternalServerError("failed to marshal audit meta: " + err.Error())
+	}
+
+	return u.create(ctx, entity.ActivityEvent{
+		UserID:     actorID,
+		Type:       entry.Type,
+		Meta:       string(metaJSON),
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		ClientIP:   pkgCtx.GetClientIP(ctx),
+		UserAgent:  pkgCtx.GetUserAgent(ctx),
+	})
+}
+
+// auditDiff reduces a before/after snapshot pair to the fields that changed.
+// Both sides are normalized through JSON so structs, maps and entities compare
+// by their wire shape. When both are objects only the top-level keys whose value
+// differs are kept (a key present on one side only counts as changed); any other
+// shape (nil, slice, scalar) is kept whole. A nil side stays nil.
+func auditDiff(before, after any) (any, any, error) {
+	beforeValue, err := normalizeAuditValue(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterValue, err := normalizeAuditValue(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	beforeMap, beforeIsMap := beforeValue.(map[string]any)
+	afterMap, afterIsMap := afterValue.(map[string]any)
+	if !beforeIsMap || !afterIsMap {
+		return beforeValue, afterValue, nil
+	}
+
+	changedBefore := map[string]any{}
+	changedAfter := map[string]any{}
+	for key, value := range beforeMap {
+		if other, ok := afterMap[key]; !ok || !reflect.DeepEqual(value, other) {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range afterMap {
+		if other, ok := beforeMap[key]; !ok || !reflect.DeepEqual(value, other) {
+			changedAfter[key] = value
+		}
+	}
+	return changedBefore, changedAfter, nil
+}
+
+// normalizeAuditValue round-trips a snapshot through JSON so every shape
+// becomes a plain map/slice/scalar. nil stays nil.
+func normalizeAuditValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
 func (u *usecase) List(ctx context.Context, userID string, limit int) ([]models.ActivityItem, error) {
 	events, err := u.activityRepo.ListByUserID(ctx, userID, limit)
 	if err != nil {
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/vukyn/isme/internal/config"
 	"github.com/vukyn/isme/internal/domains/activity/constants"
 	"github.com/vukyn/isme/internal/domains/activity/entity"
+	"github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/domains/activity/sink"
 )
 
 // fakeRepository captures created events and can be made to fail, so tests can
@@ -18,8 +22,11 @@ type fakeRepository struct {
 	createErr error
 }
 
-func (f *fakeRepository) Create(ctx context.Context, event entity.ActivityEvent) error {
-	f.created = append(f.created, event)
+func (f *fakeRepository) Create(ctx context.Context, event *entity.ActivityEvent) error {
+	// stand in for the chain: the real repository fills these in on event
+	event.Seq = int64(len(f.created) + 1)
+	event.Hash = "hash-" + strconv.FormatInt(event.Seq, 10)
+	f.created = append(f.created, *event)
 	return f.createErr
 }
 
@@ -27,10 +34,38 @@ func (f *fakeRepository) ListByUserID(ctx context.Context, userID string, limit
 	return nil, nil
 }
 
+func (f *fakeRepository) Search(ctx context.Context, filter models.AuditFilter, limit int) ([]entity.ActivityEvent, error) {
+	return nil, nil
+}
+
 func (f *fakeRepository) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
 	return 0, nil
 }
 
+func (f *fakeRepository) ChainRangeBefore(ctx context.Context, before time.Time) (models.ChainRange, error) {
+	return models.ChainRange{}, nil
+}
+
+func (f *fakeRepository) CreateCheckpoint(ctx context.Context, checkpoint entity.ActivityCheckpoint) error {
+	return nil
+}
+
+func (f *fakeRepository) ListCheckpoints(ctx context.Context) ([]entity.ActivityCheckpoint, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) ListChain(ctx context.Context, afterSeq int64, limit int) ([]entity.ActivityEvent, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) GetChainHead(ctx context.Context) (entity.ActivityChainHead, error) {
+	return entity.ActivityChainHead{}, nil
+}
+
+func (f *fakeRepository) CountUnchained(ctx context.Context, since time.Time) (int64, error) {
+	return 0, nil
+}
+
 func decodeMeta(t *testing.T, raw string) map[string]any {
 	t.Helper()
 	meta := map[string]any{}
@@ -42,7 +77,7 @@ func decodeMeta(t *testing.T, raw string) map[string]any {
 
 func TestRecordSignInBuildsTypeAndMeta(t *testing.T) {
 	repo := &fakeRepository{}
-	uc := NewUsecase(repo)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
 
 	uc.RecordSignIn(context.Background(), "user-1", "Chrome on macOS", "127.0.0.1")
 
@@ -67,7 +102,7 @@ func TestRecordSignInBuildsTypeAndMeta(t *testing.T) {
 
 func TestRecordSignOutBuildsTypeAndEmptyMeta(t *testing.T) {
 	repo := &fakeRepository{}
-	uc := NewUsecase(repo)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
 
 	uc.RecordSignOut(context.Background(), "user-1")
 
@@ -84,7 +119,7 @@ func TestRecordSignOutBuildsTypeAndEmptyMeta(t *testing.T) {
 
 func TestRecordPasswordChangedBuildsType(t *testing.T) {
 	repo := &fakeRepository{}
-	uc := NewUsecase(repo)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
 
 	uc.RecordPasswordChanged(context.Background(), "user-1")
 
@@ -95,7 +130,7 @@ func TestRecordPasswordChangedBuildsType(t *testing.T) {
 
 func TestRecordInvitationSentBuildsTypeAndMeta(t *testing.T) {
 	repo := &fakeRepository{}
-	uc := NewUsecase(repo)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
 
 	uc.RecordInvitationSent(context.Background(), "inviter-1", "new@example.com", []string{"Member", "Editor"})
 
@@ -123,7 +158,7 @@ func TestRecordInvitationSentBuildsTypeAndMeta(t *testing.T) {
 // Record* methods return nothing and the audited action is unaffected.
 func TestRecordSwallowsRepoError(t *testing.T) {
 	repo := &fakeRepository{createErr: errors.New("database unavailable")}
-	uc := NewUsecase(repo)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
 
 	// none of these panic or propagate; they return void.
 	uc.RecordSignIn(context.Background(), "user-1", "device", "ip")
@@ -136,12 +171,99 @@ func TestRecordSwallowsRepoError(t *testing.T) {
 	}
 }
 
+// TestRecordAuditDiffsChangedFieldsOnly proves an update records just the
+// edited keys on each side, plus the target and the system actor fallback.
+func TestRecordAuditDiffsChangedFieldsOnly(t *testing.T) {
+	repo := &fakeRepository{}
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	err := uc.RecordAudit(context.Background(), models.AuditEntry{
+		Type:       constants.ActivityTypeRoleUpdated,
+		TargetType: constants.TargetTypeRole,
+		TargetID:   "rol_1",
+		Before:     map[string]any{"name": "Old", "description": "same"},
+		After:      map[string]any{"name": "New", "description": "same"},
+		Meta:       map[string]any{"app_id": "app_1"},
+	})
+	if err != nil {
+		t.Fatalf("RecordAudit: %v", err)
+	}
+	if len(repo.created) != 1 {
+		t.Fatalf("expected 1 created event, got %d", len(repo.created))
+	}
+	event := repo.created[0]
+	if event.TargetType != constants.TargetTypeRole || event.TargetID != "rol_1" {
+		t.Errorf("unexpected target %q/%q", event.TargetType, event.TargetID)
+	}
+	if event.UserID != constants.AuditActorSystem {
+		t.Errorf("expected system actor without an authenticated caller, got %q", event.UserID)
+	}
+	meta := decodeMeta(t, event.Meta)
+	before, _ := meta["before"].(map[string]any)
+	after, _ := meta["after"].(map[string]any)
+	if len(before) != 1 || before["name"] != "Old" {
+		t.Errorf("expected before diff {name: Old}, got %v", meta["before"])
+	}
+	if len(after) != 1 || after["name"] != "New" {
+		t.Errorf("expected after diff {name: New}, got %v", meta["after"])
+	}
+	if meta["app_id"] != "app_1" {
+		t.Errorf("expected extra meta to be merged, got %v", meta["app_id"])
+	}
+}
+
+// TestRecordAuditCreateKeepsFullSnapshot proves a create (nil Before) records
+// the whole after-snapshot and omits the before key, and that ActorID wins.
+func TestRecordAuditCreateKeepsFullSnapshot(t *testing.T) {
+	repo := &fakeRepository{}
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	err := uc.RecordAudit(context.Background(), models.AuditEntry{
+		Type:       constants.ActivityTypeRoleCreated,
+		TargetType: constants.TargetTypeRole,
+		TargetID:   "rol_1",
+		ActorID:    "user-1",
+		After:      map[string]any{"code": "editor", "name": "Editor"},
+	})
+	if err != nil {
+		t.Fatalf("RecordAudit: %v", err)
+	}
+	event := repo.created[0]
+	if event.UserID != "user-1" {
+		t.Errorf("expected explicit actor user-1, got %q", event.UserID)
+	}
+	meta := decodeMeta(t, event.Meta)
+	if _, ok := meta["before"]; ok {
+		t.Errorf("expected no before key on create, got %v", meta["before"])
+	}
+	after, _ := meta["after"].(map[string]any)
+	if len(after) != 2 {
+		t.Errorf("expected full after snapshot, got %v", meta["after"])
+	}
+}
+
+// TestRecordAuditPropagatesRepoError proves RecordAudit is NOT best-effort: a
+// failed write is returned so the caller's transaction rolls back.
+func TestRecordAuditPropagatesRepoError(t *testing.T) {
+	repo := &fakeRepository{createErr: errors.New("database unavailable")}
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
+
+	err := uc.RecordAudit(context.Background(), models.AuditEntry{
+		Type:       constants.ActivityTypeUserDeleted,
+		TargetType: constants.TargetTypeUser,
+		TargetID:   "user-2",
+	})
+	if err == nil {
+		t.Fatal("expected the repository error to propagate")
+	}
+}
+
 func TestListMapsEventsToItems(t *testing.T) {
 	repo := &listRepo{events: []entity.ActivityEvent{
 		{ID: "id-1", UserID: "user-1", Type: constants.ActivityTypeSignIn, Meta: `{"device":"Chrome","client_ip":"127.0.0.1"}`},
 		{ID: "id-2", UserID: "user-1", Type: constants.ActivityTypeSignOut, Meta: "{}"},
 	}}
-	uc := NewUsecase(repo)
+	uc := NewUsecase(repo, &config.Config{}, sink.NoopPublisher{})
 
 	items, err := uc.List(context.Background(), "user-1", 10)
 	if err != nil {
@@ -160,7 +282,7 @@ func TestListMapsEventsToItems(t *testing.T) {
 
 // TestListEmptyNonNil proves an empty feed maps to a non-nil slice.
 func TestListEmptyNonNil(t *testing.T) {
-	uc := NewUsecase(&listRepo{events: []entity.ActivityEvent{}})
+	uc := NewUsecase(&listRepo{events: []entity.ActivityEvent{}}, &config.Config{}, sink.NoopPublisher{})
 
 	items, err := uc.List(context.Background(), "user-1", 10)
 	if err != nil {
@@ -173,15 +295,70 @@ func TestListEmptyNonNil(t *testing.T) {
 
 // listRepo returns a fixed set of events for List tests.
 type listRepo struct {
+	fakeRepository
 	events []entity.ActivityEvent
 }
 
-func (l *listRepo) Create(ctx context.Context, event entity.ActivityEvent) error { return nil }
+func (l *listRepo) Create(ctx context.Context, event *entity.ActivityEvent) error { return nil }
 
 func (l *listRepo) ListByUserID(ctx context.Context, userID string, limit int) ([]entity.ActivityEvent, error) {
 	return l.events, nil
 }
 
+func (l *listRepo) Search(ctx context.Context, filter models.AuditFilter, limit int) ([]entity.ActivityEvent, error) {
+	return nil, nil
+}
+
 func (l *listRepo) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
 	return 0, nil
 }
+
+// capturePublisher records what the usecase hands to the sinks.
+type capturePublisher struct {
+	published []models.AuditEvent
+}
+
+func (p *capturePublisher) Publish(event models.AuditEvent) {
+	p.published = append(p.published, event)
+}
+
+// TestRecordPublishesStoredEvent proves the copy shipped to the sinks carries
+// the stored row's id, timestamp and chain position, and that a failed write
+// ships nothing.
+func TestRecordPublishesStoredEvent(t *testing.T) {
+	repo := &fakeRepository{}
+	publisher := &capturePublisher{}
+	uc := NewUsecase(repo, &config.Config{}, publisher)
+
+	err := uc.RecordAudit(context.Background(), models.AuditEntry{
+		ActorID:    "admin-1",
+		Type:       constants.ActivityTypeUserStatusChanged,
+		TargetType: constants.TargetTypeUser,
+		TargetID:   "user-1",
+	})
+	if err != nil {
+		t.Fatalf("record audit: %v", err)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected 1 published event, got %d", len(publisher.published))
+	}
+	stored, shipped := repo.created[0], publisher.published[0]
+	if stored.ID == "" || shipped.ID != stored.ID {
+		t.Errorf("expected shipped id %q to match stored id %q", shipped.ID, stored.ID)
+	}
+	if shipped.CreatedAt != formatAuditTime(stored.CreatedAt) {
+		t.Errorf("expected shipped created_at %q to match stored %v", shipped.CreatedAt, stored.CreatedAt)
+	}
+	if shipped.Seq == 0 || shipped.Seq != stored.Seq || shipped.Hash != stored.Hash {
+		t.Errorf("expected shipped seq/hash %d/%q to match stored %d/%q", shipped.Seq, shipped.Hash, stored.Seq, stored.Hash)
+	}
+	if shipped.ActorID != "admin-1" || shipped.TargetID != "user-1" {
+		t.Errorf("unexpected shipped event %+v", shipped)
+	}
+
+	repo.createErr = errors.New("disk full")
+	uc.RecordSignOut(context.Background(), "user-1")
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected a failed write not to be published, got %d events", len(publisher.published))
+	}
+}
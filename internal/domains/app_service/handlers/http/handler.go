@@ -3,6 +3,7 @@ package handlers
 import (
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/app_service/models"
+	"github.com/vukyn/isme/internal/tracing"
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgHttp "github.com/vukyn/kuery/http/fiber"
 
@@ -23,7 +24,7 @@ func RegisterApp(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	registerResponse, err := uc.RegisterApp(pkgCtx.NewContextFromFiberCtx(c), registerRequest)
+	registerResponse, err := uc.RegisterApp(tracing.NewContextFromFiberCtx(c), registerRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -45,7 +46,7 @@ func VerifyApp(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	verifyResponse, err := uc.VerifyApp(pkgCtx.NewContextFromFiberCtx(c), verifyRequest)
+	verifyResponse, err := uc.VerifyApp(tracing.NewContextFromFiberCtx(c), verifyRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -53,6 +54,28 @@ func VerifyApp(c *fiber.Ctx) error {
 	return pkgHttp.OK(c, verifyResponse)
 }
 
+func SyncPermissions(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAppServiceUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	syncRequest := models.SyncPermissionsRequest{}
+	if err := c.BodyParser(&syncRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	syncResponse, err := uc.SyncPermissions(tracing.NewContextFromFiberCtx(c), syncRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, syncResponse)
+}
+
 func RefreshApp(c *fiber.Ctx) error {
 	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
 	defer ctn.Delete()
@@ -67,7 +90,7 @@ func RefreshApp(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	refreshResponse, err := uc.RefreshApp(pkgCtx.NewContextFromFiberCtx(c), refreshRequest)
+	refreshResponse, err := uc.RefreshApp(tracing.NewContextFromFiberCtx(c), refreshRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -89,7 +112,7 @@ func ListApps(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	listResponse, err := uc.ListApps(pkgCtx.NewContextFromFiberCtx(c), listRequest)
+	listResponse, err := uc.ListApps(tracing.NewContextFromFiberCtx(c), listRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -106,7 +129,7 @@ func GetApp(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	appService, err := uc.GetApp(pkgCtx.NewContextFromFiberCtx(c), c.Params("appServiceID"))
+	appService, err := uc.GetApp(tracing.NewContextFromFiberCtx(c), c.Params("appServiceID"))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -128,7 +151,7 @@ func UpdateAppAppearance(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdateAppearance(pkgCtx.NewContextFromFiberCtx(c), c.Params("appServiceID"), updateAppearanceRequest); err != nil {
+	if err := uc.UpdateAppearance(tracing.NewContextFromFiberCtx(c), c.Params("appServiceID"), updateAppearanceRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -149,7 +172,7 @@ func UpdateAppStatus(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdateStatus(pkgCtx.NewContextFromFiberCtx(c), c.Params("appServiceID"), updateStatusRequest); err != nil {
+	if err := uc.UpdateStatus(tracing.NewContextFromFiberCtx(c), c.Params("appServiceID"), updateStatusRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
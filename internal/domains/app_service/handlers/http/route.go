@@ -4,7 +4,10 @@ import (
 	iapp "github.com/vukyn/isme/internal/app"
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/app_service/models"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/vukyn/kuery/rbac"
 
@@ -17,8 +20,30 @@ func SetupAppServiceRoutes(router fiber.Router) {
 	rAppService.Post(constants.APP_SERVICE_ENDPOINT_REGISTER, middleware.AuthMiddleware, RegisterApp)
 	rAppService.Post(constants.APP_SERVICE_ENDPOINT_VERIFY, VerifyApp)
 	rAppService.Post(constants.APP_SERVICE_ENDPOINT_REFRESH, middleware.AuthMiddleware, RefreshApp)
+	rAppService.Post(constants.APP_SERVICE_ENDPOINT_PERMISSIONS_SYNC, SyncPermissions)
 	rAppService.Get(constants.APP_SERVICE_ENDPOINT_ROOT, middleware.AuthMiddleware, rbac.RequirePermission(roleConstants.PERM_APP_SERVICE_READ), ListApps)
 	rAppService.Get(constants.APP_SERVICE_ENDPOINT_DETAIL, middleware.AuthMiddleware, rbac.RequirePermission(roleConstants.PERM_APP_SERVICE_READ), GetApp)
 	rAppService.Patch(constants.APP_SERVICE_ENDPOINT_DETAIL, middleware.AuthMiddleware, rbac.RequirePermission(roleConstants.PERM_APP_SERVICE_UPDATE), UpdateAppAppearance)
 	rAppService.Patch(constants.APP_SERVICE_ENDPOINT_STATUS, middleware.AuthMiddleware, rbac.RequirePermission(roleConstants.PERM_APP_SERVICE_UPDATE), UpdateAppStatus)
 }
+
+// Operations documents the routes registered by SetupAppServiceRoutes; keep the
+// two in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodPost, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_REGISTER, Tag: "app-service", Summary: "Register an app service",
+		Body: models.RegisterRequest{}, Response: models.RegisterResponse{}, Auth: true},
+	{Method: fiber.MethodPost, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_VERIFY, Tag: "app-service", Summary: "Verify an app service's credentials",
+		Body: models.VerifyRequest{}, Response: models.VerifyResponse{}, Errors: []int{fiber.StatusUnauthorized}},
+	{Method: fiber.MethodPost, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_REFRESH, Tag: "app-service", Summary: "Rotate an app service's secret",
+		Body: models.RefreshRequest{}, Response: models.RefreshResponse{}, Auth: true},
+	{Method: fiber.MethodPost, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_PERMISSIONS_SYNC, Tag: "app-service", Summary: "Sync an app service's permission catalog",
+		Body: models.SyncPermissionsRequest{}, Response: roleModels.SyncPermissionsResponse{}, Errors: []int{fiber.StatusForbidden}},
+	{Method: fiber.MethodGet, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_ROOT, Tag: "app-service", Summary: "List app services",
+		Query: models.ListRequest{}, Response: models.ListResponse{}, Auth: true, Permission: roleConstants.PERM_APP_SERVICE_READ},
+	{Method: fiber.MethodGet, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_DETAIL, Tag: "app-service", Summary: "Get an app service",
+		Response: models.AppServiceListItem{}, Auth: true, Permission: roleConstants.PERM_APP_SERVICE_READ, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPatch, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_DETAIL, Tag: "app-service", Summary: "Update an app service's appearance",
+		Body: models.UpdateAppearanceRequest{}, Auth: true, Permission: roleConstants.PERM_APP_SERVICE_UPDATE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPatch, Path: constants.APP_SERVICE_GROUP_NAME + constants.APP_SERVICE_ENDPOINT_STATUS, Tag: "app-service", Summary: "Activate or deactivate an app service",
+		Body: models.UpdateStatusRequest{}, Auth: true, Permission: roleConstants.PERM_APP_SERVICE_UPDATE, Errors: []int{fiber.StatusNotFound}},
+}
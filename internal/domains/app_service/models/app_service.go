@@ -7,6 +7,7 @@ import (
 
 	"github.com/vukyn/isme/internal/domains/app_service/constants"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
 )
 
 // MaxAdditionalRedirectURLs caps the OAuth-style allowlist of EXTRA permitted
@@ -108,6 +109,22 @@ type VerifyResponse struct {
 	Ok bool `json:"ok"`
 }
 
+// SyncPermissionsRequest is an app service pushing its full permission catalog,
+// authenticated with the same credentials as VerifyRequest.
+type SyncPermissionsRequest struct {
+	AppCode     string                      `json:"app_code"`
+	CtxInfo     string                      `json:"ctx_info"`
+	AppSecret   string                      `json:"app_secret"`
+	Permissions []roleModels.PermissionPair `json:"permissions"`
+}
+
+func (r SyncPermissionsRequest) Validate() error {
+	if err := (VerifyRequest{AppCode: r.AppCode, CtxInfo: r.CtxInfo, AppSecret: r.AppSecret}).Validate(); err != nil {
+		return err
+	}
+	return roleModels.SyncPermissionsRequest{Permissions: r.Permissions}.Validate()
+}
+
 type RefreshRequest struct {
 	AppCode   string `json:"app_code"`
 	AppSecret string `json:"app_secret"`
@@ -131,6 +148,20 @@ type RefreshResponse struct {
 	AppSecret string `json:"app_secret"`
 }
 
+// RotateSecretRequest replaces an app's secret without the current one. Via
+// names the operator tool for the audit trail (e.g. "ismectl").
+type RotateSecretRequest struct {
+	AppCode string
+	Via     string
+}
+
+func (r RotateSecretRequest) Validate() error {
+	if r.AppCode == "" {
+		return errors.New("app_code is required")
+	}
+	return nil
+}
+
 // ListRequest for listing app services with pagination and filters
 type ListRequest struct {
 	Page     int    `json:"page" query:"page"`
@@ -7,6 +7,7 @@ import (
 
 	"github.com/vukyn/isme/internal/domains/app_service/entity"
 	"github.com/vukyn/isme/internal/domains/app_service/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgBunQuery "github.com/vukyn/kuery/bun/query"
 	pkgCtx "github.com/vukyn/kuery/ctx"
@@ -41,7 +42,7 @@ func (r *repository) Create(ctx context.Context, req entity.CreateRequest) (stri
 		Color:        req.Color,
 		CreatedBy:    userID,
 	}
-	_, err := r.db.NewInsert().
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
 		Model(appService).
 		Exec(ctx)
 	if err != nil {
@@ -56,7 +57,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (entity.AppService,
 	}
 
 	appService := entity.AppService{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&appService).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -76,7 +77,7 @@ func (r *repository) GetByIDs(ctx context.Context, ids []string) (map[string]ent
 	}
 
 	appServices := []entity.AppService{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&appServices).
 		Where("id IN (?)", bun.In(ids)).
 		Scan(ctx)
@@ -96,7 +97,7 @@ func (r *repository) GetByCode(ctx context.Context, code string) (entity.AppServ
 	}
 
 	appService := entity.AppService{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&appService).
 		Where("app_code = ?", code).
 		Scan(ctx)
@@ -155,7 +156,7 @@ func (r *repository) Update(ctx context.Context, req entity.UpdateRequest) error
 		appService.UpdatedBy = userID
 		fields = append(fields, "updated_by")
 
-		_, err := r.db.NewUpdate().
+		_, err := transaction.Conn(ctx, r.db).NewUpdate().
 			Model(appService).
 			Column(fields...).
 			Where("id = ?", req.ID).
@@ -169,7 +170,7 @@ func (r *repository) Update(ctx context.Context, req entity.UpdateRequest) error
 }
 
 func (r *repository) List(ctx context.Context, req models.ListRequest) ([]entity.AppService, int64, error) {
-	query := r.db.NewSelect().
+	query := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.AppService)(nil))
 
 	// Apply search filter (dialect-aware case-insensitive match; LowerLike folds
@@ -225,7 +226,7 @@ func (r *repository) UpdateStatus(ctx context.Context, id string, status int32)
 		Status:    status,
 		UpdatedBy: userID,
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(appService).
 		Column("status", "updated_by").
 		Where("id = ?", id).
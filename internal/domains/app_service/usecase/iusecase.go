@@ -4,12 +4,18 @@ import (
 	"context"
 
 	"github.com/vukyn/isme/internal/domains/app_service/models"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
 )
 
 type IUseCase interface {
 	RegisterApp(ctx context.Context, req models.RegisterRequest) (models.RegisterResponse, error)
 	VerifyApp(ctx context.Context, req models.VerifyRequest) (models.VerifyResponse, error)
+	SyncPermissions(ctx context.Context, req models.SyncPermissionsRequest) (roleModels.SyncPermissionsResponse, error)
 	RefreshApp(ctx context.Context, req models.RefreshRequest) (models.RefreshResponse, error)
+	// RotateSecret issues a new secret without the current one — the way back
+	// when it is lost. It skips RefreshApp's credential and creator checks, so
+	// only operator tooling with database access calls it.
+	RotateSecret(ctx context.Context, req models.RotateSecretRequest) (models.RefreshResponse, error)
 	ListApps(ctx context.Context, req models.ListRequest) (models.ListResponse, error)
 	GetApp(ctx context.Context, id string) (models.AppServiceListItem, error)
 	UpdateStatus(ctx context.Context, id string, req models.UpdateStatusRequest) error
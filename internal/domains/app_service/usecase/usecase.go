@@ -2,39 +2,51 @@ package usecase
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"time"
 
 	"github.com/vukyn/isme/internal/config"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
 	"github.com/vukyn/isme/internal/domains/app_service/constants"
 	"github.com/vukyn/isme/internal/domains/app_service/entity"
 	"github.com/vukyn/isme/internal/domains/app_service/models"
 	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
 	roleUsecase "github.com/vukyn/isme/internal/domains/role/usecase"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	"github.com/vukyn/isme/internal/transaction"
 	"github.com/vukyn/kuery/cryp/aes"
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
 )
 
 type usecase struct {
-	cfg            *config.Config
-	appServiceRepo appServiceRepo.IRepository
-	userRepo       userRepo.IRepository
-	roleUsecase    roleUsecase.IUseCase
+	cfg             *config.Config
+	appServiceRepo  appServiceRepo.IRepository
+	userRepo        userRepo.IRepository
+	roleUsecase     roleUsecase.IUseCase
+	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
 }
 
 func NewUsecase(
 	appServiceRepo appServiceRepo.IRepository,
 	userRepo userRepo.IRepository,
 	roleUsecase roleUsecase.IUseCase,
+	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
 	cfg *config.Config,
 ) IUseCase {
 	return &usecase{
-		cfg:            cfg,
-		appServiceRepo: appServiceRepo,
-		userRepo:       userRepo,
-		roleUsecase:    roleUsecase,
+		cfg:             cfg,
+		appServiceRepo:  appServiceRepo,
+		userRepo:        userRepo,
+		roleUsecase:     roleUsecase,
+		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
 	}
 }
 
@@ -100,27 +112,41 @@ func (u *usecase) RegisterApp(ctx context.Context, req models.RegisterRequest) (
 		return models.RegisterResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
-	// create app service
-	appServiceID, err := u.appServiceRepo.Create(ctx, entity.CreateRequest{
-		AppCode:      req.AppCode,
-		AppName:      req.AppName,
-		AppSecret:    encryptedSecret,
-		RedirectURL:  req.RedirectURL,
-		RedirectURLs: marshalRedirectURLs(cleanedRedirectURLs),
-		CtxInfo:      req.CtxInfo,
-		Status:       constants.AppServiceStatusActive,
-		Icon:         req.Icon,
-		Color:        req.Color,
+	// create the app service, its default roles and the audit row in one
+	// transaction so a half-provisioned app never commits
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		appServiceID, err := u.appServiceRepo.Create(ctx, entity.CreateRequest{
+			AppCode:      req.AppCode,
+			AppName:      req.AppName,
+			AppSecret:    encryptedSecret,
+			RedirectURL:  req.RedirectURL,
+			RedirectURLs: marshalRedirectURLs(cleanedRedirectURLs),
+			CtxInfo:      req.CtxInfo,
+			Status:       constants.AppServiceStatusActive,
+			Icon:         req.Icon,
+			Color:        req.Color,
+		})
+		if err != nil {
+			return err
+		}
+
+		// auto-provision the default per-app role set (admin role + approval permission)
+		if err := u.roleUsecase.ProvisionDefaultRoles(ctx, appServiceID); err != nil {
+			return err
+		}
+
+		// the request carries no secret — safe to record as the after snapshot
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAppServiceRegistered,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   appServiceID,
+			After:      req,
+		})
 	})
 	if err != nil {
 		return models.RegisterResponse{}, err
 	}
 
-	// auto-provision the default per-app role set (admin role + CRUD perm seed)
-	if err := u.roleUsecase.ProvisionDefaultRoles(ctx, appServiceID); err != nil {
-		return models.RegisterResponse{}, err
-	}
-
 	return models.RegisterResponse{
 		AppSecret: appSecret,
 	}, nil
@@ -132,49 +158,81 @@ func (u *usecase) VerifyApp(ctx context.Context, req models.VerifyRequest) (mode
 		return models.VerifyResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
-	// get app service by code
-	appService, err := u.appServiceRepo.GetByCode(ctx, req.AppCode)
+	_, ok, err := u.authenticateApp(ctx, authViaVerify, req.AppCode, req.CtxInfo, req.AppSecret)
 	if err != nil {
 		return models.VerifyResponse{}, err
 	}
+	return models.VerifyResponse{
+		Ok: ok,
+	}, nil
+}
 
-	// check if app_code is valid
-	if appService.ID == "" {
-		return models.VerifyResponse{
-			Ok: false,
-		}, nil
+// SyncPermissions lets an app service push its own permission catalog. The
+// credentials are checked like VerifyApp; the catalog is then reconciled by
+// the role usecase, which flags omitted permissions instead of deleting them.
+func (u *usecase) SyncPermissions(ctx context.Context, req models.SyncPermissionsRequest) (roleModels.SyncPermissionsResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return roleModels.SyncPermissionsResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
-	// only active app services can be verified
-	if appService.Status != constants.AppServiceStatusActive {
-		return models.VerifyResponse{
-			Ok: false,
-		}, nil
+	appService, ok, err := u.authenticateApp(ctx, authViaPermissionsSync, req.AppCode, req.CtxInfo, req.AppSecret)
+	if err != nil {
+		return roleModels.SyncPermissionsResponse{}, err
 	}
-
-	// verify ctx_info matches
-	if req.CtxInfo != appService.CtxInfo {
-		return models.VerifyResponse{
-			Ok: false,
-		}, nil
+	if !ok {
+		return roleModels.SyncPermissionsResponse{}, pkgErr.Forbidden("invalid app credentials")
 	}
 
-	// decrypt app_secret from database
-	decryptedAppSecret, err := aes.Decrypt(appService.AppSecret, u.cfg.AES.Secret, appService.CtxInfo)
+	return u.roleUsecase.SyncPermissions(ctx, appService.ID, roleModels.SyncPermissionsRequest{
+		Permissions: req.Permissions,
+	})
+}
+
+// Endpoints an app authenticates on, recorded with a failed attempt.
+const (
+	authViaVerify          = "verify"
+	authViaPermissionsSync = "permissions_sync"
+)
+
+// authenticateApp resolves the app service the credentials belong to. ok is
+// false unless the app exists, is active, and both ctx_info and the decrypted
+// app_secret match. Every failed attempt is audited with its reason, so
+// credential probing shows in the trail.
+func (u *usecase) authenticateApp(ctx context.Context, via, appCode, ctxInfo, appSecret string) (entity.AppService, bool, error) {
+	// get app service by code
+	appService, err := u.appServiceRepo.GetByCode(ctx, appCode)
 	if err != nil {
-		return models.VerifyResponse{
-			Ok: false,
-		}, nil
+		return entity.AppService{}, false, err
+	}
+
+	reason := ""
+	switch {
+	case appService.ID == "":
+		reason = "unknown_app"
+	case appService.Status != constants.AppServiceStatusActive:
+		// only active app services can authenticate
+		reason = "inactive_app"
+	case ctxInfo != appService.CtxInfo:
+		reason = "ctx_info_mismatch"
+	default:
+		// decrypt app_secret from database and compare in constant time
+		decryptedAppSecret, err := aes.Decrypt(appService.AppSecret, u.cfg.AES.Secret, appService.CtxInfo)
+		if err != nil || subtle.ConstantTimeCompare([]byte(decryptedAppSecret), []byte(appSecret)) != 1 {
+			reason = "bad_secret"
+		}
 	}
-	if decryptedAppSecret != req.AppSecret {
-		return models.VerifyResponse{
-			Ok: false,
-		}, nil
+	if reason == "" {
+		return appService, true, nil
 	}
 
-	return models.VerifyResponse{
-		Ok: true,
-	}, nil
+	err = u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+		Type:       activityConstants.ActivityTypeAppServiceAuthFailed,
+		TargetType: activityConstants.TargetTypeAppService,
+		TargetID:   appService.ID,
+		Meta:       map[string]any{"app_code": appCode, "via": via, "reason": reason},
+	})
+	return entity.AppService{}, false, err
 }
 
 func (u *usecase) RefreshApp(ctx context.Context, req models.RefreshRequest) (models.RefreshResponse, error) {
@@ -231,16 +289,65 @@ func (u *usecase) RefreshApp(ctx context.Context, req models.RefreshRequest) (mo
 		return models.RefreshResponse{}, pkgErr.InvalidRequest("unauthorized: only the creator can refresh app secret")
 	}
 
+	return u.rotateSecret(ctx, appService, map[string]any{"app_code": appService.AppCode})
+}
+
+func (u *usecase) RotateSecret(ctx context.Context, req models.RotateSecretRequest) (models.RefreshResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return models.RefreshResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	// get app service by code
+	appService, err := u.appServiceRepo.GetByCode(ctx, req.AppCode)
+	if err != nil {
+		return models.RefreshResponse{}, err
+	}
+	if appService.ID == "" {
+		return models.RefreshResponse{}, pkgErr.NotFound("app_code not found")
+	}
+
+	// the isme platform app is read-only — its secret cannot be rotated
+	if constants.IsPlatformApp(appService.ID) {
+		return models.RefreshResponse{}, pkgErr.Forbidden("the isme platform app is read-only and cannot be modified")
+	}
+
+	// terminated app services cannot be rotated
+	if appService.Status == constants.AppServiceStatusTerminated {
+		return models.RefreshResponse{}, pkgErr.InvalidRequest("app service is terminated")
+	}
+
+	meta := map[string]any{"app_code": appService.AppCode}
+	if req.Via != "" {
+		meta["via"] = req.Via
+	}
+	return u.rotateSecret(ctx, appService, meta)
+}
+
+// rotateSecret generates and stores a new secret for appService and audits the
+// rotation with meta. The audit row never carries the secret (old or new), only
+// the fact of the rotation.
+func (u *usecase) rotateSecret(ctx context.Context, appService entity.AppService, meta map[string]any) (models.RefreshResponse, error) {
 	// generate new app_secret
-	appSecret, encryptedSecret, err := generateAndEncryptAppSecret(u.cfg.AES.Secret, req.CtxInfo)
+	appSecret, encryptedSecret, err := generateAndEncryptAppSecret(u.cfg.AES.Secret, appService.CtxInfo)
 	if err != nil {
 		return models.RefreshResponse{}, err
 	}
 
 	// update app service with new secret
-	err = u.appServiceRepo.Update(ctx, entity.UpdateRequest{
-		ID:        appService.ID,
-		AppSecret: &encryptedSecret,
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.appServiceRepo.Update(ctx, entity.UpdateRequest{
+			ID:        appService.ID,
+			AppSecret: &encryptedSecret,
+		}); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAppServiceSecretRotated,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   appService.ID,
+			Meta:       meta,
+		})
 	})
 	if err != nil {
 		return models.RefreshResponse{}, err
@@ -345,7 +452,18 @@ func (u *usecase) UpdateStatus(ctx context.Context, id string, req models.Update
 		return nil
 	}
 
-	return u.appServiceRepo.UpdateStatus(ctx, id, req.Status)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.appServiceRepo.UpdateStatus(ctx, id, req.Status); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAppServiceStatusChanged,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   id,
+			Before:     models.UpdateStatusRequest{Status: appService.Status},
+			After:      req,
+		})
+	})
 }
 
 func (u *usecase) GetApp(ctx context.Context, id string) (models.AppServiceListItem, error) {
@@ -427,12 +545,57 @@ func (u *usecase) UpdateAppearance(ctx context.Context, id string, req models.Up
 		redirectURLs = &marshaled
 	}
 
-	return u.appServiceRepo.Update(ctx, entity.UpdateRequest{
-		ID:           id,
-		AppName:      req.AppName,
-		RedirectURL:  req.RedirectURL,
-		RedirectURLs: redirectURLs,
-		Icon:         req.Icon,
-		Color:        req.Color,
+	before, after := appearanceAudit(appService, req, redirectURLs)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.appServiceRepo.Update(ctx, entity.UpdateRequest{
+			ID:           id,
+			AppName:      req.AppName,
+			RedirectURL:  req.RedirectURL,
+			RedirectURLs: redirectURLs,
+			Icon:         req.Icon,
+			Color:        req.Color,
+		}); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeAppServiceUpdated,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   id,
+			Before:     before,
+			After:      after,
+		})
 	})
 }
+
+// appearanceAudit builds the before/after audit snapshots of an appearance
+// edit. The after side is the current row overlaid with only the fields the
+// request sets (nil = unchanged), so the recorded diff holds just the edits.
+func appearanceAudit(appService entity.AppService, req models.UpdateAppearanceRequest, redirectURLs *string) (map[string]any, map[string]any) {
+	before := map[string]any{
+		"app_name":      appService.AppName,
+		"redirect_url":  appService.RedirectURL,
+		"redirect_urls": unmarshalRedirectURLs(appService.RedirectURLs),
+		"icon":          appService.Icon,
+		"color":         appService.Color,
+	}
+	after := make(map[string]any, len(before))
+	for key, value := range before {
+		after[key] = value
+	}
+	if req.AppName != nil {
+		after["app_name"] = *req.AppName
+	}
+	if req.RedirectURL != nil {
+		after["redirect_url"] = *req.RedirectURL
+	}
+	if redirectURLs != nil {
+		after["redirect_urls"] = unmarshalRedirectURLs(*redirectURLs)
+	}
+	if req.Icon != nil {
+		after["icon"] = *req.Icon
+	}
+	if req.Color != nil {
+		after["color"] = *req.Color
+	}
+	return before, after
+}
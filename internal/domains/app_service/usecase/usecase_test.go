@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/vukyn/isme/internal/config"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
 	"github.com/vukyn/isme/internal/domains/app_service/constants"
 	"github.com/vukyn/isme/internal/domains/app_service/entity"
 	"github.com/vukyn/isme/internal/domains/app_service/models"
@@ -15,6 +16,7 @@ import (
 	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
 	userModels "github.com/vukyn/isme/internal/domains/user/models"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	"github.com/vukyn/kuery/cryp/aes"
 	pkgCtx "github.com/vukyn/kuery/ctx"
@@ -134,6 +136,7 @@ func (f *fakeUserRepository) SoftDelete(ctx context.Context, id string) error {
 
 type fakeRoleUsecase struct {
 	provisionedAppIDs []string
+	syncedAppIDs      []string
 }
 
 var _ roleUsecase.IUseCase = (*fakeRoleUsecase)(nil)
@@ -162,6 +165,10 @@ func (f *fakeRoleUsecase) SetPermissions(ctx context.Context, id string, req rol
 	return nil
 }
 
+func (f *fakeRoleUsecase) SetIncludes(ctx context.Context, id string, req roleModels.SetIncludesRequest) error {
+	return nil
+}
+
 func (f *fakeRoleUsecase) ListPermissions(ctx context.Context, req roleModels.ListPermissionsRequest) ([]roleModels.PermissionItem, error) {
 	return nil, nil
 }
@@ -170,6 +177,11 @@ func (f *fakeRoleUsecase) CreatePermissions(ctx context.Context, req roleModels.
 	return nil, nil
 }
 
+func (f *fakeRoleUsecase) SyncPermissions(ctx context.Context, appID string, req roleModels.SyncPermissionsRequest) (roleModels.SyncPermissionsResponse, error) {
+	f.syncedAppIDs = append(f.syncedAppIDs, appID)
+	return roleModels.SyncPermissionsResponse{}, nil
+}
+
 func (f *fakeRoleUsecase) DeletePermission(ctx context.Context, permissionID int64) error {
 	return nil
 }
@@ -198,7 +210,7 @@ func (f *fakeRoleUsecase) RemoveMember(ctx context.Context, id string, userID st
 func newTestUsecase(fakeAppService *fakeAppServiceRepository) IUseCase {
 	cfg := &config.Config{}
 	cfg.AES.Secret = testAESSecret
-	return NewUsecase(fakeAppService, &fakeUserRepository{}, &fakeRoleUsecase{}, cfg)
+	return NewUsecase(fakeAppService, &fakeUserRepository{}, &fakeRoleUsecase{}, &fakeActivityUsecase{}, transaction.NoopRunner{}, cfg)
 }
 
 func encryptTestSecret(t *testing.T, plainSecret string, ctxInfo string) string {
@@ -423,6 +435,73 @@ func TestVerifyApp(t *testing.T) {
 	}
 }
 
+func TestSyncPermissionsAuthenticatesApp(t *testing.T) {
+	plainSecret := "plain-secret"
+	tests := []struct {
+		name       string
+		appCode    string
+		status     int32
+		ctxInfo    string
+		appSecret  string
+		wantReason string // audited failure reason; empty when the sync goes through
+	}{
+		{"valid credentials sync", "code-1", constants.AppServiceStatusActive, constants.CtxInfoAuthen, plainSecret, ""},
+		{"wrong secret rejected", "code-1", constants.AppServiceStatusActive, constants.CtxInfoAuthen, "other-secret", "bad_secret"},
+		{"wrong ctx_info rejected", "code-1", constants.AppServiceStatusActive, constants.CtxInfoAppService, plainSecret, "ctx_info_mismatch"},
+		{"inactive app rejected", "code-1", constants.AppServiceStatusInactive, constants.CtxInfoAuthen, plainSecret, "inactive_app"},
+		{"unknown app rejected", "code-2", constants.AppServiceStatusActive, constants.CtxInfoAuthen, plainSecret, "unknown_app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAppService := newFakeAppServiceRepository()
+			fakeAppService.appServicesByCode["code-1"] = entity.AppService{
+				ID:        "app-1",
+				AppCode:   "code-1",
+				AppSecret: encryptTestSecret(t, plainSecret, constants.CtxInfoAuthen),
+				CtxInfo:   constants.CtxInfoAuthen,
+				Status:    tt.status,
+			}
+			fakeRole := &fakeRoleUsecase{}
+			cfg := &config.Config{}
+			cfg.AES.Secret = testAESSecret
+			activity := &fakeActivityUsecase{}
+			testUsecase := NewUsecase(fakeAppService, &fakeUserRepository{}, fakeRole, activity, transaction.NoopRunner{}, cfg)
+
+			_, err := testUsecase.SyncPermissions(context.Background(), models.SyncPermissionsRequest{
+				AppCode:     tt.appCode,
+				CtxInfo:     tt.ctxInfo,
+				AppSecret:   tt.appSecret,
+				Permissions: []roleModels.PermissionPair{{Resource: "invoice", Action: "read"}},
+			})
+			wantErr := tt.wantReason != ""
+			if (err != nil) != wantErr {
+				t.Fatalf("SyncPermissions() error = %v, wantErr %v", err, wantErr)
+			}
+			if wantErr && len(fakeRole.syncedAppIDs) != 0 {
+				t.Errorf("synced %v, want no sync for rejected credentials", fakeRole.syncedAppIDs)
+			}
+			if !wantErr && (len(fakeRole.syncedAppIDs) != 1 || fakeRole.syncedAppIDs[0] != "app-1") {
+				t.Errorf("synced %v, want [app-1]", fakeRole.syncedAppIDs)
+			}
+
+			// a rejected attempt leaves one audit entry naming why
+			if !wantErr {
+				if len(activity.auditEntries) != 0 {
+					t.Errorf("audited %+v, want nothing for accepted credentials", activity.auditEntries)
+				}
+				return
+			}
+			if len(activity.auditEntries) != 1 {
+				t.Fatalf("audited %d entries, want 1", len(activity.auditEntries))
+			}
+			entry := activity.auditEntries[0]
+			if entry.Type != activityConstants.ActivityTypeAppServiceAuthFailed || entry.Meta["reason"] != tt.wantReason || entry.Meta["via"] != authViaPermissionsSync {
+				t.Errorf("audit entry = %+v, want an auth failure for %s", entry, tt.wantReason)
+			}
+		})
+	}
+}
+
 func TestRefreshApp(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -478,6 +557,62 @@ func TestRefreshApp(t *testing.T) {
 	}
 }
 
+// TestRotateSecret proves the operator rotation needs no current secret, goes
+// through the same write and audit as RefreshApp, and keeps its refusals.
+func TestRotateSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		status  int32
+		wantErr bool
+	}{
+		{"active app rotated", "app-1", constants.AppServiceStatusActive, false},
+		{"terminated app rejected", "app-1", constants.AppServiceStatusTerminated, true},
+		{"platform app rejected", constants.PlatformAppID, constants.AppServiceStatusActive, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAppService := newFakeAppServiceRepository()
+			fakeAppService.appServicesByCode["code-1"] = entity.AppService{
+				ID:        tt.id,
+				AppCode:   "code-1",
+				AppSecret: encryptTestSecret(t, "lost-secret", constants.CtxInfoAuthen),
+				CtxInfo:   constants.CtxInfoAuthen,
+				Status:    tt.status,
+			}
+			activity := &fakeActivityUsecase{}
+			cfg := &config.Config{}
+			cfg.AES.Secret = testAESSecret
+			testUsecase := NewUsecase(fakeAppService, &fakeUserRepository{}, &fakeRoleUsecase{}, activity, transaction.NoopRunner{}, cfg)
+
+			response, err := testUsecase.RotateSecret(context.Background(), models.RotateSecretRequest{AppCode: "code-1", Via: "ismectl"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if len(fakeAppService.updatedSecrets) != 0 || len(activity.auditEntries) != 0 {
+					t.Error("secret was rotated despite rejection")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RotateSecret() error = %v", err)
+			}
+			stored, ok := fakeAppService.updatedSecrets[tt.id]
+			if !ok {
+				t.Fatal("rotated secret was not persisted")
+			}
+			decrypted, err := aes.Decrypt(stored, testAESSecret, constants.CtxInfoAuthen)
+			if err != nil || decrypted != response.AppSecret {
+				t.Errorf("stored secret decrypts to %q (%v), want the returned %q", decrypted, err, response.AppSecret)
+			}
+			if len(activity.auditEntries) != 1 || activity.auditEntries[0].Meta["via"] != "ismectl" {
+				t.Errorf("expected one rotation audit via ismectl, got %+v", activity.auditEntries)
+			}
+		})
+	}
+}
+
 func TestGetApp(t *testing.T) {
 	t.Run("returns appearance fields", func(t *testing.T) {
 		fakeAppService := newFakeAppServiceRepository()
@@ -634,3 +769,28 @@ func TestPlatformAppReadOnly(t *testing.T) {
 		}
 	})
 }
+
+// The appearance audit diff records only the fields the request sets; nil
+// fields keep their current value on both sides and drop out of the diff.
+func TestAppearanceAuditOverlaysOnlySetFields(t *testing.T) {
+	appService := entity.AppService{
+		AppName:      "Billing",
+		RedirectURL:  "https://billing.example.com/cb",
+		RedirectURLs: "[]",
+		Icon:         "wallet",
+		Color:        "blue",
+	}
+	newName := "Billing Portal"
+
+	before, after := appearanceAudit(appService, models.UpdateAppearanceRequest{AppName: &newName}, nil)
+
+	if before["app_name"] != "Billing" {
+		t.Errorf("before app_name = %v, want Billing", before["app_name"])
+	}
+	if after["app_name"] != "Billing Portal" {
+		t.Errorf("after app_name = %v, want Billing Portal", after["app_name"])
+	}
+	if after["icon"] != before["icon"] || after["color"] != before["color"] {
+		t.Errorf("unset fields changed: before=%v after=%v", before, after)
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/auth/models"
+	"github.com/vukyn/isme/internal/tracing"
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgHttp "github.com/vukyn/kuery/http/fiber"
 
@@ -23,7 +24,7 @@ func Login(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	loginResponse, err := uc.Login(pkgCtx.NewContextFromFiberCtx(c), loginRequest)
+	loginResponse, err := uc.Login(tracing.NewContextFromFiberCtx(c), loginRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -40,7 +41,7 @@ func GetMe(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	getMeResponse, err := uc.GetMe(pkgCtx.NewContextFromFiberCtx(c))
+	getMeResponse, err := uc.GetMe(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -62,7 +63,7 @@ func UpdateMe(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	getMeResponse, err := uc.UpdateMe(pkgCtx.NewContextFromFiberCtx(c), updateMeRequest)
+	getMeResponse, err := uc.UpdateMe(tracing.NewContextFromFiberCtx(c), updateMeRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -84,7 +85,7 @@ func RefreshToken(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	refreshTokenResponse, err := uc.RefreshToken(pkgCtx.NewContextFromFiberCtx(c), refreshTokenRequest)
+	refreshTokenResponse, err := uc.RefreshToken(tracing.NewContextFromFiberCtx(c), refreshTokenRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -106,7 +107,7 @@ func ChangePassword(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	err = uc.ChangePassword(pkgCtx.NewContextFromFiberCtx(c), changePasswordRequest)
+	err = uc.ChangePassword(tracing.NewContextFromFiberCtx(c), changePasswordRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -123,7 +124,7 @@ func Logout(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	err = uc.Logout(pkgCtx.NewContextFromFiberCtx(c))
+	err = uc.Logout(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -145,7 +146,7 @@ func RequestLogin(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	requestLoginResponse, err := uc.RequestLogin(pkgCtx.NewContextFromFiberCtx(c), requestLoginRequest)
+	requestLoginResponse, err := uc.RequestLogin(tracing.NewContextFromFiberCtx(c), requestLoginRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -167,7 +168,7 @@ func ExchangeCode(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	exchangeCodeResponse, err := uc.ExchangeCode(pkgCtx.NewContextFromFiberCtx(c), exchangeCodeRequest)
+	exchangeCodeResponse, err := uc.ExchangeCode(tracing.NewContextFromFiberCtx(c), exchangeCodeRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -189,7 +190,7 @@ func SSOCheck(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	ssoCheckResponse, err := uc.SSOCheck(pkgCtx.NewContextFromFiberCtx(c), ssoCheckRequest)
+	ssoCheckResponse, err := uc.SSOCheck(tracing.NewContextFromFiberCtx(c), ssoCheckRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -206,7 +207,7 @@ func ListMySessions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	sessions, err := uc.ListMySessions(pkgCtx.NewContextFromFiberCtx(c))
+	sessions, err := uc.ListMySessions(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -223,7 +224,7 @@ func CountMySessions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	count, err := uc.CountMySessions(pkgCtx.NewContextFromFiberCtx(c))
+	count, err := uc.CountMySessions(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -240,7 +241,7 @@ func RevokeMySession(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	err = uc.RevokeMySession(pkgCtx.NewContextFromFiberCtx(c), c.Params("id"))
+	err = uc.RevokeMySession(tracing.NewContextFromFiberCtx(c), c.Params("id"))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -257,7 +258,7 @@ func RevokeMyOtherSessions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	err = uc.RevokeMyOtherSessions(pkgCtx.NewContextFromFiberCtx(c))
+	err = uc.RevokeMyOtherSessions(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -274,7 +275,7 @@ func GetMyActivity(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	activity, err := uc.GetMyActivity(pkgCtx.NewContextFromFiberCtx(c), c.QueryInt("limit", 0))
+	activity, err := uc.GetMyActivity(tracing.NewContextFromFiberCtx(c), c.QueryInt("limit", 0))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -296,10 +297,31 @@ func SSOConsent(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	ssoConsentResponse, err := uc.SSOConsent(pkgCtx.NewContextFromFiberCtx(c), ssoConsentRequest)
+	ssoConsentResponse, err := uc.SSOConsent(tracing.NewContextFromFiberCtx(c), ssoConsentRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
 	return pkgHttp.OK(c, ssoConsentResponse)
 }
+
+// GetJWKS serves the access-token key set bare (no envelope) so standard JOSE
+// libraries can fetch it; the short max-age lets verifiers pick up a rotated
+// key without hammering isme.
+func GetJWKS(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetAuthUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	jwks, err := uc.GetJWKS(tracing.NewContextFromFiberCtx(c))
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(jwks)
+}
@@ -4,6 +4,9 @@ import (
 	iapp "github.com/vukyn/isme/internal/app"
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	"github.com/vukyn/isme/internal/domains/auth/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,6 +22,8 @@ func SetupAuthRoutes(router fiber.Router) {
 	r.Post(constants.AUTH_ENDPOINT_LOGOUT, middleware.AuthMiddleware, Logout)
 	r.Post(constants.AUTH_ENDPOINT_REQUEST_LOGIN, RequestLogin)
 	r.Post(constants.AUTH_ENDPOINT_EXCHANGE_CODE, ExchangeCode)
+	// Public: access-token verification keys for services validating locally
+	r.Get(constants.AUTH_ENDPOINT_JWKS, GetJWKS)
 	// Public: AuthMiddleware → VerifyToken would reject an expired access token
 	// before the handler runs, breaking the refresh-token probe branch. These
 	// endpoints validate the tokens passed in the body themselves.
@@ -34,3 +39,42 @@ func SetupAuthRoutes(router fiber.Router) {
 	// Self-service recent-activity feed (self-scoped, no RBAC permission gate).
 	r.Get(constants.AUTH_ENDPOINT_MY_ACTIVITY, middleware.AuthMiddleware, GetMyActivity)
 }
+
+// Operations documents the routes registered by SetupAuthRoutes; keep the two
+// in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_LOGIN, Tag: "auth", Summary: "Sign in with email and password",
+		Body: models.LoginRequest{}, Response: models.LoginResponse{}, Errors: []int{fiber.StatusUnauthorized, fiber.StatusForbidden}},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_REFRESH, Tag: "auth", Summary: "Exchange a refresh token for a new token pair",
+		Body: models.RefreshTokenRequest{}, Response: models.RefreshTokenResponse{}, Errors: []int{fiber.StatusUnauthorized}},
+	{Method: fiber.MethodGet, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_ME, Tag: "auth", Summary: "Get the signed-in user's profile",
+		Response: models.GetMeResponse{}, Auth: true},
+	{Method: fiber.MethodPatch, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_ME, Tag: "auth", Summary: "Update the signed-in user's profile",
+		Body: models.UpdateMeRequest{}, Response: models.GetMeResponse{}, Auth: true},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_CHANGE_PASSWORD, Tag: "auth", Summary: "Change the signed-in user's password",
+		Body: models.ChangePasswordRequest{}, Response: openapi.Message{}, Auth: true},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_LOGOUT, Tag: "auth", Summary: "Sign out the current session",
+		Response: openapi.Message{}, Auth: true},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_REQUEST_LOGIN, Tag: "auth", Summary: "Start an SSO login for a registered app",
+		Body: models.RequestLoginRequest{}, Response: models.RequestLoginResponse{}},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_EXCHANGE_CODE, Tag: "auth", Summary: "Exchange an SSO authorization code for tokens",
+		Body: models.ExchangeCodeRequest{}, Response: models.ExchangeCodeResponse{}, Errors: []int{fiber.StatusUnauthorized}},
+	{Method: fiber.MethodGet, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_JWKS, Tag: "auth", Summary: "Get the access-token verification keys (JWKS)",
+		Response: models.JWKS{}, Bare: true},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_SSO_CHECK, Tag: "auth", Summary: "Check whether an SSO login can proceed without a password",
+		Body: models.SSOCheckRequest{}, Response: models.SSOCheckResponse{}},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_SSO_CONSENT, Tag: "auth", Summary: "Grant consent and issue an SSO authorization code",
+		Body: models.SSOConsentRequest{}, Response: models.SSOConsentResponse{}, Errors: []int{fiber.StatusUnauthorized}},
+	{Method: fiber.MethodGet, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_MY_SESSIONS, Tag: "auth", Summary: "List the signed-in user's sessions",
+		Response: []models.MySessionItem{}, Auth: true},
+	{Method: fiber.MethodGet, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_MY_SESSIONS_COUNT, Tag: "auth", Summary: "Count the signed-in user's sessions",
+		Response: models.MySessionCount{}, Auth: true},
+	{Method: fiber.MethodDelete, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_REVOKE_MY_OTHER_SESSIONS, Tag: "auth", Summary: "Revoke every session but the current one",
+		Response: openapi.Message{}, Auth: true},
+	{Method: fiber.MethodDelete, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_REVOKE_MY_SESSION, Tag: "auth", Summary: "Revoke one of the signed-in user's sessions",
+		Response: openapi.Message{}, Auth: true, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_MY_ACTIVITY, Tag: "auth", Summary: "List the signed-in user's recent activity",
+		Query: struct {
+			Limit int `query:"limit"`
+		}{}, Response: []activityModels.ActivityItem{}, Auth: true},
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// JWKS is the public JSON Web Key Set downstream services fetch to verify isme
+// access tokens locally. It is served bare, not in the response envelope, so
+// any JOSE library can consume it.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is one RSA verification key (RFC 7517). Kid is the RFC 7638 thumbprint,
+// so it changes exactly when the key does and needs no separate config.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewJWKS publishes the access-token public key (AUTH_ACCESS_TOKEN_PUBLIC_KEY),
+// accepting both PKIX ("PUBLIC KEY") and PKCS#1 ("RSA PUBLIC KEY") PEM.
+func NewJWKS(publicKeyPEM string) (JWKS, error) {
+	// env files often carry the PEM on one line with literal \n escapes
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(publicKeyPEM, `\n`, "\n")))
+	if block == nil {
+		return JWKS{}, errors.New("access token public key is not PEM encoded")
+	}
+
+	var publicKey *rsa.PublicKey
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return JWKS{}, err
+		}
+		publicKey = key
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return JWKS{}, err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return JWKS{}, errors.New("access token public key is not an RSA key")
+		}
+		publicKey = rsaKey
+	}
+
+	n := base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	// RFC 7638: SHA-256 over the required members in lexicographic order
+	thumbprint := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return JWKS{Keys: []JWK{{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+		N:   n,
+		E:   e,
+	}}}, nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestNewJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	pkixPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	pkcs1PEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}))
+
+	var kids []string
+	for name, in := range map[string]string{
+		"pkix":    pkixPEM,
+		"pkcs1":   pkcs1PEM,
+		"escaped": strings.ReplaceAll(pkixPEM, "\n", `\n`),
+	} {
+		jwks, err := NewJWKS(in)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(jwks.Keys) != 1 {
+			t.Fatalf("%s: %d keys", name, len(jwks.Keys))
+		}
+		jwk := jwks.Keys[0]
+		n, _ := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, _ := base64.RawURLEncoding.DecodeString(jwk.E)
+		if new(big.Int).SetBytes(n).Cmp(key.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != key.E {
+			t.Errorf("%s: key material does not round-trip", name)
+		}
+		if jwk.Kty != "RSA" || jwk.Alg != "RS256" || jwk.Use != "sig" {
+			t.Errorf("%s: jwk = %+v", name, jwk)
+		}
+		kids = append(kids, jwk.Kid)
+	}
+	if kids[0] == "" || kids[0] != kids[1] || kids[1] != kids[2] {
+		t.Errorf("kid must be a stable thumbprint of the key, got %v", kids)
+	}
+
+	if _, err := NewJWKS("not a key"); err == nil {
+		t.Error("garbage accepted")
+	}
+}
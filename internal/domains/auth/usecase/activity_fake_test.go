@@ -19,6 +19,7 @@ type fakeActivityUsecase struct {
 	passwordChangedCalls []string
 	profileUpdatedCalls  []string
 	invitationSentCalls  []fakeInvitationCall
+	auditEntries         []activityModels.AuditEntry
 
 	listItems []activityModels.ActivityItem
 	listErr   error
@@ -56,9 +57,26 @@ func (f *fakeActivityUsecase) RecordInvitationSent(ctx context.Context, inviterI
 	f.invitationSentCalls = append(f.invitationSentCalls, fakeInvitationCall{inviterID: inviterID, email: email, roleNames: roleNames})
 }
 
+func (f *fakeActivityUsecase) RecordAudit(ctx context.Context, entry activityModels.AuditEntry) error {
+	f.auditEntries = append(f.auditEntries, entry)
+	return nil
+}
+
 func (f *fakeActivityUsecase) List(ctx context.Context, userID string, limit int) ([]activityModels.ActivityItem, error) {
 	if f.listErr != nil {
 		return nil, f.listErr
 	}
 	return f.listItems, nil
 }
+
+func (f *fakeActivityUsecase) SearchAudit(ctx context.Context, req activityModels.AuditSearchRequest) (activityModels.AuditSearchResponse, error) {
+	return activityModels.AuditSearchResponse{}, nil
+}
+
+func (f *fakeActivityUsecase) ExportAudit(ctx context.Context, req activityModels.AuditExportRequest) (activityModels.AuditExport, error) {
+	return activityModels.AuditExport{}, nil
+}
+
+func (f *fakeActivityUsecase) VerifyChain(ctx context.Context) (activityModels.ChainReport, error) {
+	return activityModels.ChainReport{}, nil
+}
@@ -11,6 +11,7 @@ import (
 	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
 	userSessionConstants "github.com/vukyn/isme/internal/domains/user_session/constants"
 	userSessionModels "github.com/vukyn/isme/internal/domains/user_session/models"
+	"github.com/vukyn/isme/internal/tracing"
 	pkgClaims "github.com/vukyn/kuery/claims"
 	"github.com/vukyn/kuery/cryp"
 	pkgCtx "github.com/vukyn/kuery/ctx"
@@ -45,22 +46,33 @@ func buildTokenScope(groupedPerms map[string][]string, appCode string) (map[stri
 	return resourceAccess, audience
 }
 
-func (u *usecase) generateAccessTokens(userID, email string, resourceAccess map[string][]string, audience []string) (string, pkgClaims.Claims, error) {
+func (u *usecase) generateAccessTokens(ctx context.Context, userID, email string, resourceAccess map[string][]string, audience []string) (string, pkgClaims.Claims, error) {
+	// RSA signing is the costliest step of issuing a token
+	_, span := tracing.Start(ctx, "auth.generateAccessTokens", tracing.WithAttributes(
+		tracing.Int("auth.audience_count", len(audience)),
+	))
+	defer span.End()
+
 	authCfg := u.cfg.Auth
 	claims := pkgClaims.NewClaims(userID, email, int64(authCfg.AccessTokenExpireIn)).
 		WithResourceAccess(resourceAccess).
 		WithAudience(audience)
 	accessToken, err := jwt.GenerateJWTWithRSAPrivateKeyFromClaims(authCfg.AccessTokenPrivateKey, claims)
 	if err != nil {
+		span.RecordError(err)
 		return "", pkgClaims.Claims{}, err
 	}
 	return accessToken, claims, nil
 }
 
-func (u *usecase) generateRefreshTokens(userID, email string) (string, pkgClaims.Claims, error) {
+func (u *usecase) generateRefreshTokens(ctx context.Context, userID, email string) (string, pkgClaims.Claims, error) {
+	_, span := tracing.Start(ctx, "auth.generateRefreshTokens")
+	defer span.End()
+
 	authCfg := u.cfg.Auth
 	refreshToken, claims, err := jwt.GenerateJWT(authCfg.RefreshTokenSecretKey, authCfg.RefreshTokenExpireIn, userID, email)
 	if err != nil {
+		span.RecordError(err)
 		return "", pkgClaims.Claims{}, err
 	}
 	return refreshToken, claims, nil
@@ -78,12 +90,12 @@ func (u *usecase) establishIdPSession(ctx context.Context, user userEntity.User,
 	// empty appCode → full/isme scope (all apps the user has roles in, plus isme)
 	resourceAccess, audience := buildTokenScope(groupedPerms, "")
 
-	accessToken, accessTokenClaims, err := u.generateAccessTokens(user.ID, user.Email, resourceAccess, audience)
+	accessToken, accessTokenClaims, err := u.generateAccessTokens(ctx, user.ID, user.Email, resourceAccess, audience)
 	if err != nil {
 		return "", "", "", err
 	}
 
-	refreshToken, _, err = u.generateRefreshTokens(user.ID, user.Email)
+	refreshToken, _, err = u.generateRefreshTokens(ctx, user.ID, user.Email)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -98,6 +110,11 @@ func (u *usecase) establishIdPSession(ctx context.Context, user userEntity.User,
 }
 
 func (u *usecase) createUserSession(ctx context.Context, userID, tokenID, email, refreshToken, appServiceID string, expiresAt time.Time) (string, error) {
+	ctx, span := tracing.Start(ctx, "auth.createUserSession", tracing.WithAttributes(
+		tracing.Bool("auth.sso", appServiceID != ""),
+	))
+	defer span.End()
+
 	res, err := u.userSessionRepo.Create(ctx, userSessionModels.CreateRequest{
 		UserID:       userID,
 		TokenID:      tokenID,
@@ -109,6 +126,7 @@ func (u *usecase) createUserSession(ctx context.Context, userID, tokenID, email,
 		AppServiceID: appServiceID,
 	})
 	if err != nil {
+		span.RecordError(err)
 		return "", err
 	}
 	return res.ID, nil
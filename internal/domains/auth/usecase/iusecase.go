@@ -24,4 +24,5 @@ type IUseCase interface {
 	RevokeMySession(ctx context.Context, sessionID string) error
 	RevokeMyOtherSessions(ctx context.Context) error
 	GetMyActivity(ctx context.Context, limit int) ([]activityModels.ActivityItem, error)
+	GetJWKS(ctx context.Context) (models.JWKS, error)
 }
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vukyn/isme/internal/domains/auth/models"
+	userConstants "github.com/vukyn/isme/internal/domains/user/constants"
+	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
+	"github.com/vukyn/isme/internal/metrics"
+
+	"github.com/vukyn/kuery/cryp"
+)
+
+// TestLoginCountsOutcomeByReason proves each login outcome lands on its own
+// reason series, even though the client sees the same error for several.
+func TestLoginCountsOutcomeByReason(t *testing.T) {
+	userRepository := &fakeUserRepository{
+		user: userEntity.User{
+			ID:         "user-1",
+			Email:      "user@example.com",
+			Password:   cryp.HashArgon2id("s3cret-password"),
+			Status:     userConstants.UserStatusActive,
+			IsVerified: true,
+		},
+	}
+	authUsecase := newTestUsecase(t, userRepository)
+
+	cases := []struct {
+		password string
+		result   string
+		reason   string
+	}{
+		{"wrong-password", metrics.ResultFailure, metrics.LoginReasonBadPassword},
+		{"s3cret-password", metrics.ResultSuccess, metrics.LoginReasonSuccess},
+	}
+	for _, tc := range cases {
+		counter := metrics.Logins.With(tc.result, tc.reason)
+		before := counter.Value()
+
+		_, _ = authUsecase.Login(context.Background(), models.LoginRequest{
+			Email:    "user@example.com",
+			Password: tc.password,
+		})
+
+		if got := counter.Value() - before; got != 1 {
+			t.Errorf("reason %s: expected 1 login counted, got %v", tc.reason, got)
+		}
+	}
+}
@@ -98,6 +98,10 @@ func (f *fakeUserSessionRepository) InactiveSessionByID(ctx context.Context, ses
 	return nil
 }
 
+func (f *fakeUserSessionRepository) CountActive(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 func (f *fakeUserSessionRepository) InactiveAllUserSessionExcept(ctx context.Context, userID string, exceptTokenID string) error {
 	return nil
 }
@@ -187,10 +191,34 @@ func (f *fakeRoleRepository) UpdatePermissionAppearance(ctx context.Context, app
 	return nil
 }
 
+func (f *fakeRoleRepository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	return nil
+}
+
 func (f *fakeRoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
 	return nil, nil
 }
 
+func (f *fakeRoleRepository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]roleEntity.Role, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	return roleIDs, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	return nil
+}
+
 func (f *fakeRoleRepository) ReplaceRolePermissions(ctx context.Context, roleID string, permissionIDs []int64) error {
 	return nil
 }
@@ -203,10 +231,18 @@ func (f *fakeRoleRepository) CountMembersByRoleID(ctx context.Context, roleID st
 	return 0, nil
 }
 
-func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string) error {
+func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window roleModels.MemberWindow) error {
 	return nil
 }
 
+func (f *fakeRoleRepository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]roleEntity.UserRole, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	return false, nil
+}
+
 func (f *fakeRoleRepository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
 	return nil
 }
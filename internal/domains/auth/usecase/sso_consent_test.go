@@ -14,6 +14,7 @@ import (
 	userSessionConstants "github.com/vukyn/isme/internal/domains/user_session/constants"
 	userSessionEntity "github.com/vukyn/isme/internal/domains/user_session/entity"
 	userSessionModels "github.com/vukyn/isme/internal/domains/user_session/models"
+	"github.com/vukyn/isme/internal/metrics"
 
 	pkgCache "github.com/vukyn/kuery/cache"
 	"github.com/vukyn/kuery/jwt"
@@ -90,6 +91,10 @@ func (s *ssoUserSessionRepo) InactiveSessionByID(ctx context.Context, sessionID
 	s.inactiveByIDCalls = append(s.inactiveByIDCalls, sessionID)
 	return nil
 }
+func (s *ssoUserSessionRepo) CountActive(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 func (s *ssoUserSessionRepo) InactiveAllUserSessionExcept(ctx context.Context, userID string, exceptTokenID string) error {
 	s.exceptTokenIDCalls = append(s.exceptTokenIDCalls, exceptTokenID)
 	return nil
@@ -125,7 +130,7 @@ func (s *ssoUserSessionRepo) CountActiveByUserIDs(ctx context.Context, userIDs [
 // ssoFixture wires a usecase with controllable cache, session, user and app.
 type ssoFixture struct {
 	usecase      *usecase
-	cache        *pkgCache.Cache[string, string]
+	cache        *metrics.Cache
 	sessionRepo  *ssoUserSessionRepo
 	cfg          *config.Config
 	activity     *fakeActivityUsecase
@@ -138,7 +143,7 @@ func newSSOFixture(t *testing.T, sessionStatus int32, sessionExpiresAt time.Time
 	t.Helper()
 
 	cfg := newTestConfig(t)
-	cache := pkgCache.NewCache[string, string]()
+	cache := metrics.NewCache(pkgCache.NewCache[string, string]())
 
 	const userID = "user-sso"
 	const email = "sso@example.com"
@@ -13,6 +13,7 @@ import (
 	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
 	userSessionConstants "github.com/vukyn/isme/internal/domains/user_session/constants"
 	userSessionEntity "github.com/vukyn/isme/internal/domains/user_session/entity"
+	"github.com/vukyn/isme/internal/metrics"
 
 	pkgCache "github.com/vukyn/kuery/cache"
 	"github.com/vukyn/kuery/cryp"
@@ -31,7 +32,7 @@ func newSSOLoginFixture(t *testing.T, sessionID string, grouped map[string][]str
 	const password = "s3cret-password"
 
 	cfg := newTestConfig(t)
-	cache := pkgCache.NewCache[string, string]()
+	cache := metrics.NewCache(pkgCache.NewCache[string, string]())
 
 	user := userEntity.User{
 		ID:         userID,
@@ -6,6 +6,7 @@ import (
 
 	appServiceEntity "github.com/vukyn/isme/internal/domains/app_service/entity"
 	"github.com/vukyn/isme/internal/domains/auth/models"
+	"github.com/vukyn/isme/internal/metrics"
 
 	pkgCache "github.com/vukyn/kuery/cache"
 	"github.com/vukyn/kuery/cryp/aes"
@@ -47,7 +48,7 @@ func TestEncodeDecodeSSOSession(t *testing.T) {
 // requestLoginFixture builds a usecase + an app whose encrypted secret matches a
 // known plaintext, so RequestLogin's secret check passes and the redirect_uri
 // allowlist logic can be exercised.
-func requestLoginFixture(t *testing.T, app appServiceEntity.AppService) (*usecase, *pkgCache.Cache[string, string], string) {
+func requestLoginFixture(t *testing.T, app appServiceEntity.AppService) (*usecase, *metrics.Cache, string) {
 	t.Helper()
 
 	const aesSecret = "test-aes-secret"
@@ -64,7 +65,7 @@ func requestLoginFixture(t *testing.T, app appServiceEntity.AppService) (*usecas
 	cfg.Auth.EndpointWebSSOLogin = "https://sso.isme.local/login"
 	cfg.Auth.ExternalLoginSessionTTL = 300
 
-	cache := pkgCache.NewCache[string, string]()
+	cache := metrics.NewCache(pkgCache.NewCache[string, string]())
 	appRepo := &byCodeAppServiceRepo{ssoAppServiceRepo: ssoAppServiceRepo{app: app}}
 	uc := NewUsecase(cfg, cache, &fakeUserRepository{}, &ssoUserSessionRepo{}, appRepo, &fakeRoleRepository{}, &fakeActivityUsecase{}).(*usecase)
 
@@ -93,7 +94,7 @@ func TestRequestLoginRedirectURI(t *testing.T) {
 	}
 
 	// extract the cached, frozen redirect from the only cache entry created.
-	frozenRedirect := func(t *testing.T, cache *pkgCache.Cache[string, string], rawSessionID string) string {
+	frozenRedirect := func(t *testing.T, cache *metrics.Cache, rawSessionID string) string {
 		t.Helper()
 		raw, ok := cache.Get(rawSessionID)
 		if !ok {
@@ -17,7 +17,8 @@ import (
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
 	userSessionConstants "github.com/vukyn/isme/internal/domains/user_session/constants"
 	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
-	pkgCache "github.com/vukyn/kuery/cache"
+	"github.com/vukyn/isme/internal/metrics"
+	"github.com/vukyn/isme/internal/tracing"
 	pkgClaims "github.com/vukyn/kuery/claims"
 	"github.com/vukyn/kuery/cryp/aes"
 	pkgCtx "github.com/vukyn/kuery/ctx"
@@ -29,7 +30,7 @@ import (
 
 type usecase struct {
 	cfg             *config.Config
-	cache           *pkgCache.Cache[string, string]
+	cache           *metrics.Cache
 	userRepo        userRepo.IRepository
 	userSessionRepo userSessionRepo.IRepository
 	appServiceRepo  appServiceRepo.IRepository
@@ -39,7 +40,7 @@ type usecase struct {
 
 func NewUsecase(
 	cfg *config.Config,
-	cache *pkgCache.Cache[string, string],
+	cache *metrics.Cache,
 	userRepo userRepo.IRepository,
 	userSessionRepo userSessionRepo.IRepository,
 	appServiceRepo appServiceRepo.IRepository,
@@ -57,6 +58,16 @@ func NewUsecase(
 	}
 }
 
+// GetJWKS publishes the access-token verification key so downstream services
+// can validate tokens without a round trip per request.
+func (u *usecase) GetJWKS(ctx context.Context) (models.JWKS, error) {
+	jwks, err := models.NewJWKS(u.cfg.Auth.AccessTokenPublicKey)
+	if err != nil {
+		return models.JWKS{}, pkgErr.InternalServerError(err.Error())
+	}
+	return jwks, nil
+}
+
 func (u *usecase) GetMe(ctx context.Context) (models.GetMeResponse, error) {
 	userId := pkgCtx.GetUserID(ctx)
 	if userId == "" {
@@ -187,8 +198,33 @@ func (u *usecase) VerifyToken(ctx context.Context, req models.VerifyTokenRequest
 }
 
 func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.LoginResponse, error) {
+	// metrics: every early return below sets its own reason; anything else
+	// (database, signing) is counted as an error.
+	reason, ssoApp := metrics.LoginReasonError, ""
+	ctx, span := tracing.Start(ctx, "auth.Login")
+	defer func() {
+		span.SetAttributes(tracing.String("auth.login.reason", reason), tracing.Bool("auth.sso", req.SessionID != ""))
+		if reason == metrics.LoginReasonError {
+			span.SetStatus(tracing.StatusError, "")
+		}
+		span.End()
+
+		metrics.ObserveLogin(reason)
+		if req.SessionID != "" {
+			result := metrics.ResultInvalid
+			switch reason {
+			case metrics.LoginReasonSuccess:
+				result = metrics.ResultSuccess
+			case metrics.LoginReasonError:
+				result = metrics.ResultError
+			}
+			metrics.ObserveSSO(ssoApp, metrics.SSOStepLogin, result)
+		}
+	}()
+
 	// validation
 	if err := req.Validate(); err != nil {
+		reason = metrics.LoginReasonInvalidRequest
 		return models.LoginResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
@@ -197,10 +233,12 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 	if req.SessionID != "" {
 		rawSession, ok := u.cache.Get(req.SessionID)
 		if !ok {
+			reason = metrics.LoginReasonInvalidSession
 			return models.LoginResponse{}, pkgErr.InvalidRequest("invalid session_id")
 		}
 		session, ok := decodeSSOSession(rawSession)
 		if !ok {
+			reason = metrics.LoginReasonInvalidSession
 			return models.LoginResponse{}, pkgErr.InvalidRequest("invalid session_id")
 		}
 		appServiceID = session.AppServiceID
@@ -209,6 +247,7 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 			return models.LoginResponse{}, err
 		}
 		if appService.ID == "" {
+			reason = metrics.LoginReasonInvalidSession
 			return models.LoginResponse{}, pkgErr.InvalidRequest("invalid session_id")
 		}
 		// use ONLY the frozen session redirect; never re-derive from client input.
@@ -218,6 +257,7 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 			redirectURL = appService.RedirectURL
 		}
 		appCode = appService.AppCode
+		ssoApp = appCode
 	}
 
 	// check if user exists
@@ -226,21 +266,29 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 		return models.LoginResponse{}, err
 	}
 	if user.ID == "" {
+		reason = metrics.LoginReasonUnknownUser
 		return models.LoginResponse{}, pkgErr.InvalidRequest("invalid email or password")
 	}
 	if user.Status != userConstants.UserStatusActive {
+		reason = metrics.LoginReasonInactiveUser
 		return models.LoginResponse{}, pkgErr.InvalidRequest("invalid email or password")
 	}
 
-	// check if password is correct
+	// check if password is correct; hashing is deliberately slow, so it gets a
+	// span of its own
+	_, verifySpan := tracing.Start(ctx, "auth.VerifyPassword")
 	ok, needsRehash := cryp.VerifyPassword(req.Password, user.Password)
+	verifySpan.SetAttributes(tracing.Bool("auth.password.needs_rehash", needsRehash))
+	verifySpan.End()
 	if !ok {
+		reason = metrics.LoginReasonBadPassword
 		return models.LoginResponse{}, pkgErr.InvalidRequest("invalid email or password")
 	}
 
 	// block unverified accounts only after the credentials checked out,
 	// so a wrong password never leaks the verification state
 	if !user.IsVerified {
+		reason = metrics.LoginReasonUnverified
 		return models.LoginResponse{}, pkgErr.Forbidden("account pending verification")
 	}
 
@@ -262,14 +310,14 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 	resourceAccess, audience := buildTokenScope(groupedPerms, appCode)
 
 	// generate access tokens
-	accessToken, accessTokenClaims, err := u.generateAccessTokens(user.ID, user.Email, resourceAccess, audience)
+	accessToken, accessTokenClaims, err := u.generateAccessTokens(ctx, user.ID, user.Email, resourceAccess, audience)
 	if err != nil {
 		return models.LoginResponse{}, err
 	}
 	expiresAt := accessTokenClaims.GetExpiredAt().Format(time.RFC3339)
 
 	// generate refresh tokens
-	refreshToken, _, err := u.generateRefreshTokens(user.ID, user.Email)
+	refreshToken, _, err := u.generateRefreshTokens(ctx, user.ID, user.Email)
 	if err != nil {
 		return models.LoginResponse{}, err
 	}
@@ -316,6 +364,7 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 		expiresAt = idpExpires
 	}
 
+	reason = metrics.LoginReasonSuccess
 	return models.LoginResponse{
 		AccessToken:       accessToken,
 		RefreshToken:      refreshToken,
@@ -326,8 +375,13 @@ func (u *usecase) Login(ctx context.Context, req models.LoginRequest) (models.Lo
 }
 
 func (u *usecase) RefreshToken(ctx context.Context, req models.RefreshTokenRequest) (models.RefreshTokenResponse, error) {
+	// metrics: client-side rejections are "invalid"; the rest start as "error"
+	result := metrics.ResultError
+	defer func() { metrics.TokenRefreshes.With(result).Inc() }()
+
 	// validation
 	if err := req.Validate(); err != nil {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
@@ -335,11 +389,13 @@ func (u *usecase) RefreshToken(ctx context.Context, req models.RefreshTokenReque
 	authCfg := u.cfg.Auth
 	claims, err := jwt.ValidateJWT(req.RefreshToken, authCfg.RefreshTokenSecretKey)
 	if err != nil {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest("invalid refresh token")
 	}
 
 	// check if token is expired
 	if claims.IsExpired() {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest("invalid refresh token")
 	}
 
@@ -349,9 +405,11 @@ func (u *usecase) RefreshToken(ctx context.Context, req models.RefreshTokenReque
 		return models.RefreshTokenResponse{}, err
 	}
 	if userSession.ID == "" {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest("invalid refresh token")
 	}
 	if userSession.Status != userSessionConstants.UserSessionStatusActive {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest("invalid refresh token")
 	}
 
@@ -361,9 +419,11 @@ func (u *usecase) RefreshToken(ctx context.Context, req models.RefreshTokenReque
 		return models.RefreshTokenResponse{}, err
 	}
 	if user.ID == "" {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest("invalid refresh token")
 	}
 	if user.Status != userConstants.UserStatusActive {
+		result = metrics.ResultInvalid
 		return models.RefreshTokenResponse{}, pkgErr.InvalidRequest("invalid refresh token")
 	}
 
@@ -386,13 +446,13 @@ func (u *usecase) RefreshToken(ctx context.Context, req models.RefreshTokenReque
 	resourceAccess, audience := buildTokenScope(groupedPerms, appCode)
 
 	// generate new access tokens
-	newAccessToken, accessTokenClaims, err := u.generateAccessTokens(user.ID, user.Email, resourceAccess, audience)
+	newAccessToken, accessTokenClaims, err := u.generateAccessTokens(ctx, user.ID, user.Email, resourceAccess, audience)
 	if err != nil {
 		return models.RefreshTokenResponse{}, err
 	}
 
 	// generate new refresh tokens
-	newRefreshToken, _, err := u.generateRefreshTokens(user.ID, user.Email)
+	newRefreshToken, _, err := u.generateRefreshTokens(ctx, user.ID, user.Email)
 	if err != nil {
 		return models.RefreshTokenResponse{}, err
 	}
@@ -403,6 +463,7 @@ func (u *usecase) RefreshToken(ctx context.Context, req models.RefreshTokenReque
 		return models.RefreshTokenResponse{}, err
 	}
 
+	result = metrics.ResultSuccess
 	return models.RefreshTokenResponse{
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshToken,
@@ -494,8 +555,14 @@ func (u *usecase) Logout(ctx context.Context) error {
 }
 
 func (u *usecase) RequestLogin(ctx context.Context, req models.RequestLoginRequest) (models.RequestLoginResponse, error) {
+	// metrics: labelled with the app code only once it resolved to a
+	// registered app (see metrics.SSOAppUnknown)
+	result, ssoApp := metrics.ResultError, ""
+	defer func() { metrics.ObserveSSO(ssoApp, metrics.SSOStepRequestLogin, result) }()
+
 	// validation
 	if err := req.Validate(); err != nil {
+		result = metrics.ResultInvalid
 		return models.RequestLoginResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
@@ -506,11 +573,14 @@ func (u *usecase) RequestLogin(ctx context.Context, req models.RequestLoginReque
 	}
 
 	if appService.ID == "" {
+		result = metrics.ResultInvalid
 		return models.RequestLoginResponse{}, pkgErr.InvalidRequest("app service not found")
 	}
+	ssoApp = appService.AppCode
 
 	// verify ctx_info matches
 	if req.CtxInfo != appService.CtxInfo {
+		result = metrics.ResultInvalid
 		return models.RequestLoginResponse{}, pkgErr.InvalidRequest("invalid ctx_info")
 	}
 
@@ -520,6 +590,7 @@ func (u *usecase) RequestLogin(ctx context.Context, req models.RequestLoginReque
 		return models.RequestLoginResponse{}, err
 	}
 	if decryptedAppSecret != req.AppSecret {
+		result = metrics.ResultInvalid
 		return models.RequestLoginResponse{}, pkgErr.InvalidRequest("invalid app_secret")
 	}
 
@@ -528,6 +599,7 @@ func (u *usecase) RequestLogin(ctx context.Context, req models.RequestLoginReque
 	// redirect_url or one of its additional redirect_urls (the allowlist union).
 	chosenRedirectURL, allowed := chooseRedirectURL(appService, req.RedirectURI)
 	if !allowed {
+		result = metrics.ResultInvalid
 		return models.RequestLoginResponse{}, pkgErr.InvalidRequest("redirect_uri is not allowed")
 	}
 
@@ -540,13 +612,18 @@ func (u *usecase) RequestLogin(ctx context.Context, req models.RequestLoginReque
 		RedirectURL:  chosenRedirectURL,
 	}), time.Duration(u.cfg.Auth.ExternalLoginSessionTTL)*time.Second)
 
-	// return response
+	result = metrics.ResultSuccess
 	return models.RequestLoginResponse{
 		RedirectURL: fmt.Sprintf("%s?session_id=%s", u.cfg.Auth.EndpointWebSSOLogin, sessionID),
 	}, nil
 }
 
 func (u *usecase) ExchangeCode(ctx context.Context, req models.ExchangeCodeRequest) (models.ExchangeCodeResponse, error) {
+	// metrics: the code alone does not identify the app, so exchanges are
+	// counted under metrics.SSOAppUnknown
+	result := metrics.ResultInvalid
+	defer func() { metrics.ObserveSSO("", metrics.SSOStepExchange, result) }()
+
 	// validation
 	if err := req.Validate(); err != nil {
 		return models.ExchangeCodeResponse{}, pkgErr.InvalidRequest(err.Error())
@@ -578,7 +655,7 @@ func (u *usecase) ExchangeCode(ctx context.Context, req models.ExchangeCodeReque
 	u.cache.Delete(refreshTokenKey)
 	u.cache.Delete(expiresAtKey)
 
-	// return response
+	result = metrics.ResultSuccess
 	return models.ExchangeCodeResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -672,18 +749,25 @@ func (u *usecase) SSOCheck(ctx context.Context, req models.SSOCheckRequest) (mod
 // browser stays logged in, and the issued token is aud-restricted to the
 // requesting app via buildTokenScope(appService.AppCode).
 func (u *usecase) SSOConsent(ctx context.Context, req models.SSOConsentRequest) (models.SSOConsentResponse, error) {
+	// metrics: client-side rejections are "invalid"; the rest start as "error"
+	result, ssoApp := metrics.ResultError, ""
+	defer func() { metrics.ObserveSSO(ssoApp, metrics.SSOStepConsent, result) }()
+
 	// validation
 	if err := req.Validate(); err != nil {
+		result = metrics.ResultInvalid
 		return models.SSOConsentResponse{}, pkgErr.InvalidRequest(err.Error())
 	}
 
 	// resolve session_id → requesting app service (independent re-resolution)
 	rawSession, ok := u.cache.Get(req.SessionID)
 	if !ok {
+		result = metrics.ResultInvalid
 		return models.SSOConsentResponse{}, pkgErr.InvalidRequest("invalid session_id")
 	}
 	session, ok := decodeSSOSession(rawSession)
 	if !ok {
+		result = metrics.ResultInvalid
 		return models.SSOConsentResponse{}, pkgErr.InvalidRequest("invalid session_id")
 	}
 	appServiceID := session.AppServiceID
@@ -692,9 +776,12 @@ func (u *usecase) SSOConsent(ctx context.Context, req models.SSOConsentRequest)
 		return models.SSOConsentResponse{}, err
 	}
 	if appService.ID == "" {
+		result = metrics.ResultInvalid
 		return models.SSOConsentResponse{}, pkgErr.InvalidRequest("invalid session_id")
 	}
 
+	ssoApp = appService.AppCode
+
 	// the redirect is taken ONLY from the frozen session — never re-derived from
 	// client input — so consent cannot be steered to an unvalidated destination.
 	// legacy sessions (no frozen redirect) fall back to the app's primary URL.
@@ -707,11 +794,13 @@ func (u *usecase) SSOConsent(ctx context.Context, req models.SSOConsentRequest)
 	// capture the user so we can mint a fresh app-scoped session for them below.
 	user, valid := u.validateSessionForConsent(ctx, req.AccessToken, req.RefreshToken)
 	if !valid {
+		result = metrics.ResultInvalid
 		return models.SSOConsentResponse{}, pkgErr.InvalidRequest("session is no longer valid")
 	}
 
 	// validate + consume the single-use CSRF nonce (replay guard)
 	if !u.consumeConsentNonce(req.SessionID, req.Nonce) {
+		result = metrics.ResultInvalid
 		return models.SSOConsentResponse{}, pkgErr.InvalidRequest("invalid or expired nonce")
 	}
 
@@ -729,14 +818,14 @@ func (u *usecase) SSOConsent(ctx context.Context, req models.SSOConsentRequest)
 	resourceAccess, audience := buildTokenScope(groupedPerms, appService.AppCode)
 
 	// generate access tokens
-	accessToken, accessTokenClaims, err := u.generateAccessTokens(user.ID, user.Email, resourceAccess, audience)
+	accessToken, accessTokenClaims, err := u.generateAccessTokens(ctx, user.ID, user.Email, resourceAccess, audience)
 	if err != nil {
 		return models.SSOConsentResponse{}, err
 	}
 	expiresAt := accessTokenClaims.GetExpiredAt().Format(time.RFC3339)
 
 	// generate refresh tokens
-	refreshToken, _, err := u.generateRefreshTokens(user.ID, user.Email)
+	refreshToken, _, err := u.generateRefreshTokens(ctx, user.ID, user.Email)
 	if err != nil {
 		return models.SSOConsentResponse{}, err
 	}
@@ -751,6 +840,7 @@ func (u *usecase) SSOConsent(ctx context.Context, req models.SSOConsentRequest)
 	// mint a one-time authorization code (deletes session_id atomically)
 	authorizationCode := u.mintAuthorizationCode(accessToken, refreshToken, expiresAt, req.SessionID)
 
+	result = metrics.ResultSuccess
 	return models.SSOConsentResponse{
 		RedirectURL:       consentRedirectURL,
 		AuthorizationCode: authorizationCode,
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// UserGroup is a named set of users holding a set of roles; every member
+// inherits the group's roles. The name is unique among live groups.
+type UserGroup struct {
+	bun.BaseModel `bun:"table:user_groups,alias:ugp"`
+	ID            string    `bun:"id,pk,notnull"`
+	Name          string    `bun:"name,notnull"`
+	Description   string    `bun:"description"`
+	CreatedAt     time.Time `bun:"created_at,default:current_timestamp"`
+	CreatedBy     string    `bun:"created_by,nullzero"`
+	UpdatedAt     time.Time `bun:"updated_at,default:current_timestamp"`
+	UpdatedBy     string    `bun:"updated_by,nullzero"`
+	DeletedAt     time.Time `bun:"deleted_at,soft_delete,nullzero"`
+	DeletedBy     string    `bun:"deleted_by,nullzero"`
+}
+
+// === Hooks ===
+
+func (g *UserGroup) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch q := query.(type) {
+	case *bun.InsertQuery:
+		g.CreatedAt = time.Now().UTC()
+	case *bun.UpdateQuery:
+		q.Column("updated_at")
+		g.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
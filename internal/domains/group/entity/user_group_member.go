@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type UserGroupMember struct {
+	bun.BaseModel `bun:"table:user_group_members,alias:ugm"`
+	GroupID       string    `bun:"group_id,pk,notnull"`
+	UserID        string    `bun:"user_id,pk,notnull"`
+	CreatedAt     time.Time `bun:"created_at,default:current_timestamp"`
+	CreatedBy     string    `bun:"created_by,nullzero"`
+}
+
+// === Hooks ===
+
+func (m *UserGroupMember) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		m.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// UserGroupRole grants RoleID to every member of GroupID, in the role's own
+// app.
+type UserGroupRole struct {
+	bun.BaseModel `bun:"table:user_group_roles,alias:ugr"`
+	GroupID       string    `bun:"group_id,pk,notnull"`
+	RoleID        string    `bun:"role_id,pk,notnull"`
+	CreatedAt     time.Time `bun:"created_at,default:current_timestamp"`
+	CreatedBy     string    `bun:"created_by,nullzero"`
+}
+
+// === Hooks ===
+
+func (r *UserGroupRole) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		r.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
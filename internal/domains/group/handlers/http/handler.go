@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/group/models"
+	"github.com/vukyn/isme/internal/tracing"
+
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgHttp "github.com/vukyn/kuery/http/fiber"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ListGroups(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	groups, err := uc.List(tracing.NewContextFromFiberCtx(c))
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, groups)
+}
+
+func CreateGroup(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	createRequest := models.CreateRequest{}
+	if err := c.BodyParser(&createRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	createResponse, err := uc.Create(tracing.NewContextFromFiberCtx(c), createRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, createResponse)
+}
+
+func GetGroupDetail(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	detailResponse, err := uc.GetDetail(tracing.NewContextFromFiberCtx(c), c.Params("groupID"))
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, detailResponse)
+}
+
+func UpdateGroup(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	updateRequest := models.UpdateRequest{}
+	if err := c.BodyParser(&updateRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.Update(tracing.NewContextFromFiberCtx(c), c.Params("groupID"), updateRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func DeleteGroup(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.Delete(tracing.NewContextFromFiberCtx(c), c.Params("groupID")); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func SetGroupRoles(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	setRolesRequest := models.SetRolesRequest{}
+	if err := c.BodyParser(&setRolesRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.SetRoles(tracing.NewContextFromFiberCtx(c), c.Params("groupID"), setRolesRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func ListGroupMembers(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	listMembersRequest := models.ListMembersRequest{}
+	if err := c.QueryParser(&listMembersRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	listMembersResponse, err := uc.ListMembers(tracing.NewContextFromFiberCtx(c), c.Params("groupID"), listMembersRequest)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, listMembersResponse)
+}
+
+func AddGroupMembers(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	addMembersRequest := models.AddMembersRequest{}
+	if err := c.BodyParser(&addMembersRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.AddMembers(tracing.NewContextFromFiberCtx(c), c.Params("groupID"), addMembersRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func RemoveGroupMember(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetGroupUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.RemoveMember(tracing.NewContextFromFiberCtx(c), c.Params("groupID"), c.Params("userID")); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	iapp "github.com/vukyn/isme/internal/app"
+	"github.com/vukyn/isme/internal/constants"
+	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/group/models"
+	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/openapi"
+
+	"github.com/vukyn/kuery/rbac"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupGroupRoutes registers the group routes. Groups reuse the role
+// permissions: reading them is role:read, and every change is role:assign
+// since membership and group roles decide who holds which role.
+func SetupGroupRoutes(router fiber.Router) {
+	middleware := idi.GetMiddleware(iapp.App)
+
+	rGroup := router.Group(constants.GROUP_GROUP_NAME, middleware.AuthMiddleware)
+	rGroup.Get(constants.GROUP_ENDPOINT_ROOT, rbac.RequirePermission(roleConstants.PERM_ROLE_READ), ListGroups)
+	rGroup.Post(constants.GROUP_ENDPOINT_ROOT, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), CreateGroup)
+	rGroup.Get(constants.GROUP_ENDPOINT_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_READ), GetGroupDetail)
+	rGroup.Put(constants.GROUP_ENDPOINT_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), UpdateGroup)
+	rGroup.Delete(constants.GROUP_ENDPOINT_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), DeleteGroup)
+	rGroup.Put(constants.GROUP_ENDPOINT_ROLES, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), SetGroupRoles)
+	rGroup.Get(constants.GROUP_ENDPOINT_MEMBERS, rbac.RequirePermission(roleConstants.PERM_ROLE_READ), ListGroupMembers)
+	rGroup.Post(constants.GROUP_ENDPOINT_MEMBERS, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), AddGroupMembers)
+	rGroup.Delete(constants.GROUP_ENDPOINT_MEMBER_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), RemoveGroupMember)
+}
+
+// Operations documents the routes registered by SetupGroupRoutes; keep the two
+// in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_ROOT, Tag: "groups", Summary: "List groups",
+		Response: []models.GroupListItem{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ},
+	{Method: fiber.MethodPost, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_ROOT, Tag: "groups", Summary: "Create a group",
+		Body: models.CreateRequest{}, Response: models.CreateResponse{}, Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN},
+	{Method: fiber.MethodGet, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_DETAIL, Tag: "groups", Summary: "Get a group with the roles it grants",
+		Response: models.GroupDetailResponse{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_DETAIL, Tag: "groups", Summary: "Update a group",
+		Body: models.UpdateRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_DETAIL, Tag: "groups", Summary: "Delete a group",
+		Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_ROLES, Tag: "groups", Summary: "Replace the roles a group grants its members",
+		Body: models.SetRolesRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_MEMBERS, Tag: "groups", Summary: "List a group's members",
+		Query: models.ListMembersRequest{}, Response: models.ListMembersResponse{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_MEMBERS, Tag: "groups", Summary: "Add users to a group",
+		Body: models.AddMembersRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: constants.GROUP_GROUP_NAME + constants.GROUP_ENDPOINT_MEMBER_DETAIL, Tag: "groups", Summary: "Remove a user from a group",
+		Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	pkgBase "github.com/vukyn/kuery/http/base"
+)
+
+type CreateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (r CreateRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type CreateResponse struct {
+	ID string `json:"id"`
+}
+
+type UpdateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (r UpdateRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// AddMembersRequest adds users to a group; users already in it are skipped.
+type AddMembersRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+func (r AddMembersRequest) Validate() error {
+	if len(r.UserIDs) == 0 {
+		return errors.New("user_ids is required")
+	}
+	for _, userID := range r.UserIDs {
+		if userID == "" {
+			return errors.New("user_ids must not contain empty values")
+		}
+	}
+	return nil
+}
+
+// SetRolesRequest replaces the roles a group grants its members. Roles may
+// belong to different apps; each is held in its own app. An empty list clears
+// it.
+type SetRolesRequest struct {
+	RoleIDs []string `json:"role_ids"`
+}
+
+func (r SetRolesRequest) Validate() error {
+	seen := map[string]bool{}
+	for _, roleID := range r.RoleIDs {
+		if roleID == "" {
+			return errors.New("role_ids must not contain empty values")
+		}
+		if seen[roleID] {
+			return errors.New("role_ids must not contain duplicates")
+		}
+		seen[roleID] = true
+	}
+	return nil
+}
+
+type ListMembersRequest struct {
+	pkgBase.Pagination
+	Query string `json:"query" query:"query"`
+}
+
+func (r ListMembersRequest) Validate() error {
+	return nil
+}
+
+type GroupListItem struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	MembersCount int    `json:"members_count"`
+	RolesCount   int    `json:"roles_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// RoleItem is a role a group grants, with its owning app.
+type RoleItem struct {
+	ID      string `json:"id"`
+	AppID   string `json:"app_id"`
+	AppCode string `json:"app_code"`
+	AppName string `json:"app_name"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+}
+
+type GroupDetailResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	MembersCount int        `json:"members_count"`
+	Roles        []RoleItem `json:"roles"`
+	CreatedAt    string     `json:"created_at"`
+}
+
+type MemberItem struct {
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListMembersResponse struct {
+	Items []MemberItem `json:"items"`
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vukyn/isme/internal/domains/group/entity"
+	"github.com/vukyn/isme/internal/domains/group/models"
+)
+
+type IRepository interface {
+	// Create a group. Returns the new id.
+	Create(ctx context.Context, req models.CreateRequest) (string, error)
+	// Get group by ID
+	GetByID(ctx context.Context, id string) (entity.UserGroup, error)
+	// Get the live group with the given name
+	GetByName(ctx context.Context, name string) (entity.UserGroup, error)
+	// List groups with member and role counts, by name
+	List(ctx context.Context) ([]models.GroupListItem, error)
+	// Update group name and description
+	Update(ctx context.Context, id string, req models.UpdateRequest) error
+	// Soft delete a group; its members stop inheriting its roles
+	SoftDelete(ctx context.Context, id string) error
+	// List the group's live members with pagination
+	ListMembers(ctx context.Context, groupID string, req models.ListMembersRequest) ([]models.MemberItem, int, error)
+	// Count the group's live members
+	CountMembers(ctx context.Context, groupID string) (int, error)
+	// Add users to a group, skipping current members
+	AddMembers(ctx context.Context, groupID string, userIDs []string) error
+	// Remove a user from a group
+	RemoveMember(ctx context.Context, groupID string, userID string) error
+	// List the live roles a group grants, with their owning app
+	ListRoles(ctx context.Context, groupID string) ([]models.RoleItem, error)
+	// Replace the roles a group grants
+	ReplaceRoles(ctx context.Context, groupID string, roleIDs []string) error
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vukyn/isme/internal/domains/group/entity"
+	"github.com/vukyn/isme/internal/domains/group/models"
+	"github.com/vukyn/isme/internal/transaction"
+
+	pkgBunQuery "github.com/vukyn/kuery/bun/query"
+	pkgCtx "github.com/vukyn/kuery/ctx"
+	pkgErr "github.com/vukyn/kuery/http/errors"
+
+	"github.com/uptrace/bun"
+	"github.com/vukyn/kuery/cryp"
+)
+
+type repository struct {
+	db *bun.DB
+}
+
+func NewRepository(
+	db *bun.DB,
+) IRepository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, req models.CreateRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", pkgErr.InvalidRequest(err.Error())
+	}
+
+	group := &entity.UserGroup{
+		ID:          cryp.ULID(),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   pkgCtx.GetUserID(ctx),
+	}
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
+		Model(group).
+		Exec(ctx)
+	if err != nil {
+		return "", pkgErr.DatabaseError(err.Error())
+	}
+	return group.ID, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (entity.UserGroup, error) {
+	if id == "" {
+		return entity.UserGroup{}, pkgErr.InvalidRequest("id is required")
+	}
+
+	group := entity.UserGroup{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&group).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.UserGroup{}, nil
+		}
+		return entity.UserGroup{}, pkgErr.DatabaseError(err.Error())
+	}
+	return group, nil
+}
+
+func (r *repository) GetByName(ctx context.Context, name string) (entity.UserGroup, error) {
+	if name == "" {
+		return entity.UserGroup{}, pkgErr.InvalidRequest("name is required")
+	}
+
+	group := entity.UserGroup{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&group).
+		Where("name = ?", name).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.UserGroup{}, nil
+		}
+		return entity.UserGroup{}, pkgErr.DatabaseError(err.Error())
+	}
+	return group, nil
+}
+
+func (r *repository) List(ctx context.Context) ([]models.GroupListItem, error) {
+	type groupListRow struct {
+		entity.UserGroup `bun:",extend"`
+		MembersCount     int `bun:"members_count,scanonly"`
+		RolesCount       int `bun:"roles_count,scanonly"`
+	}
+
+	rows := []groupListRow{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&rows).
+		ColumnExpr("ugp.*").
+		ColumnExpr("(SELECT COUNT(*) FROM user_group_members ugm JOIN users usr ON usr.id = ugm.user_id AND usr.deleted_at IS NULL WHERE ugm.group_id = ugp.id) AS members_count").
+		ColumnExpr("(SELECT COUNT(*) FROM user_group_roles ugr JOIN roles rol ON rol.id = ugr.role_id AND rol.deleted_at IS NULL WHERE ugr.group_id = ugp.id) AS roles_count").
+		Order("ugp.name ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+
+	items := make([]models.GroupListItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, models.GroupListItem{
+			ID:           row.ID,
+			Name:         row.Name,
+			Description:  row.Description,
+			MembersCount: row.MembersCount,
+			RolesCount:   row.RolesCount,
+			CreatedAt:    row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return items, nil
+}
+
+func (r *repository) Update(ctx context.Context, id string, req models.UpdateRequest) error {
+	if id == "" {
+		return pkgErr.InvalidRequest("id is required")
+	}
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	group := &entity.UserGroup{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		UpdatedBy:   pkgCtx.GetUserID(ctx),
+	}
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
+		Model(group).
+		Column("name", "description", "updated_by").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
+func (r *repository) SoftDelete(ctx context.Context, id string) error {
+	if id == "" {
+		return pkgErr.InvalidRequest("id is required")
+	}
+
+	group := &entity.UserGroup{
+		ID:        id,
+		DeletedAt: time.Now().UTC(),
+		DeletedBy: pkgCtx.GetUserID(ctx),
+	}
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
+		Model(group).
+		Column("deleted_at", "deleted_by").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
+func (r *repository) ListMembers(ctx context.Context, groupID string, req models.ListMembersRequest) ([]models.MemberItem, int, error) {
+	if groupID == "" {
+		return nil, 0, pkgErr.InvalidRequest("group_id is required")
+	}
+
+	type memberRow struct {
+		UserID    string    `bun:"user_id"`
+		Name      string    `bun:"name"`
+		Email     string    `bun:"email"`
+		CreatedAt time.Time `bun:"created_at"`
+	}
+
+	buildQuery := func() *bun.SelectQuery {
+		query := transaction.Conn(ctx, r.db).NewSelect().
+			TableExpr("user_group_members AS ugm").
+			Join("JOIN users AS usr ON usr.id = ugm.user_id AND usr.deleted_at IS NULL").
+			Where("ugm.group_id = ?", groupID)
+		if req.Query != "" {
+			search := "%" + req.Query + "%"
+			// dialect-aware case-insensitive match (ILIKE on Postgres, LIKE on SQLite)
+			query = query.Where(
+				"("+pkgBunQuery.ILike(r.db, "usr.name")+" OR "+pkgBunQuery.ILike(r.db, "usr.email")+")",
+				search, search,
+			)
+		}
+		return query
+	}
+
+	total, err := buildQuery().Count(ctx)
+	if err != nil {
+		return nil, 0, pkgErr.DatabaseError(err.Error())
+	}
+
+	rows := []memberRow{}
+	query := buildQuery().
+		ColumnExpr("ugm.user_id").
+		ColumnExpr("usr.name").
+		ColumnExpr("usr.email").
+		ColumnExpr("ugm.created_at")
+	query = pkgBunQuery.SelectWithPagination(query, req.Pagination, "ugm.created_at DESC")
+	if err := query.Scan(ctx, &rows); err != nil {
+		return nil, 0, pkgErr.DatabaseError(err.Error())
+	}
+
+	items := make([]models.MemberItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, models.MemberItem{
+			UserID:    row.UserID,
+			Name:      row.Name,
+			Email:     row.Email,
+			CreatedAt: row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return items, total, nil
+}
+
+func (r *repository) CountMembers(ctx context.Context, groupID string) (int, error) {
+	if groupID == "" {
+		return 0, pkgErr.InvalidRequest("group_id is required")
+	}
+
+	count, err := transaction.Conn(ctx, r.db).NewSelect().
+		TableExpr("user_group_members AS ugm").
+		Join("JOIN users AS usr ON usr.id = ugm.user_id AND usr.deleted_at IS NULL").
+		Where("ugm.group_id = ?", groupID).
+		Count(ctx)
+	if err != nil {
+		return 0, pkgErr.DatabaseError(err.Error())
+	}
+	return count, nil
+}
+
+func (r *repository) AddMembers(ctx context.Context, groupID string, userIDs []string) error {
+	if groupID == "" {
+		return pkgErr.InvalidRequest("group_id is required")
+	}
+	if len(userIDs) == 0 {
+		return pkgErr.InvalidRequest("user_ids is required")
+	}
+
+	createdBy := pkgCtx.GetUserID(ctx)
+	members := make([]entity.UserGroupMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, entity.UserGroupMember{
+			GroupID:   groupID,
+			UserID:    userID,
+			CreatedBy: createdBy,
+		})
+	}
+
+	// current members keep their row (and its created_at)
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
+		Model(&members).
+		Ignore().
+		Exec(ctx)
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
+func (r *repository) RemoveMember(ctx context.Context, groupID string, userID string) error {
+	if groupID == "" {
+		return pkgErr.InvalidRequest("group_id is required")
+	}
+	if userID == "" {
+		return pkgErr.InvalidRequest("user_id is required")
+	}
+
+	_, err := transaction.Conn(ctx, r.db).NewDelete().
+		Model((*entity.UserGroupMember)(nil)).
+		Where("group_id = ?", groupID).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
+func (r *repository) ListRoles(ctx context.Context, groupID string) ([]models.RoleItem, error) {
+	if groupID == "" {
+		return nil, pkgErr.InvalidRequest("group_id is required")
+	}
+
+	type roleRow struct {
+		ID      string `bun:"id"`
+		AppID   string `bun:"app_id"`
+		AppCode string `bun:"app_code"`
+		AppName string `bun:"app_name"`
+		Code    string `bun:"code"`
+		Name    string `bun:"name"`
+	}
+
+	rows := []roleRow{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		TableExpr("user_group_roles AS ugr").
+		ColumnExpr("rol.id AS id").
+		ColumnExpr("rol.app_id AS app_id").
+		ColumnExpr("app.app_code AS app_code").
+		ColumnExpr("app.app_name AS app_name").
+		ColumnExpr("rol.code AS code").
+		ColumnExpr("rol.name AS name").
+		Join("JOIN roles AS rol ON rol.id = ugr.role_id AND rol.deleted_at IS NULL").
+		Join("JOIN app_services AS app ON app.id = rol.app_id").
+		Where("ugr.group_id = ?", groupID).
+		Order("app.app_code ASC").
+		Order("rol.code ASC").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+
+	items := make([]models.RoleItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, models.RoleItem{
+			ID:      row.ID,
+			AppID:   row.AppID,
+			AppCode: row.AppCode,
+			AppName: row.AppName,
+			Code:    row.Code,
+			Name:    row.Name,
+		})
+	}
+	return items, nil
+}
+
+func (r *repository) ReplaceRoles(ctx context.Context, groupID string, roleIDs []string) error {
+	if groupID == "" {
+		return pkgErr.InvalidRequest("group_id is required")
+	}
+
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().
+			Model((*entity.UserGroupRole)(nil)).
+			Where("group_id = ?", groupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		createdBy := pkgCtx.GetUserID(ctx)
+		groupRoles := make([]entity.UserGroupRole, 0, len(roleIDs))
+		for _, roleID := range roleIDs {
+			groupRoles = append(groupRoles, entity.UserGroupRole{
+				GroupID:   groupID,
+				RoleID:    roleID,
+				CreatedBy: createdBy,
+			})
+		}
+		_, err = tx.NewInsert().
+			Model(&groupRoles).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
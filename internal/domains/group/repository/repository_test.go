@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
+	"github.com/vukyn/isme/internal/domains/group/models"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+// newTestDB opens an in-memory SQLite database and applies every migration
+// from db/history/sqlite.
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	sqldb, err := sql.Open(sqliteshim.ShimName, ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory sqlite: %v", err)
+	}
+	// a single connection keeps every query on the same in-memory database
+	sqldb.SetMaxOpenConns(1)
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	for _, migration := range sqliteHistory.Migrations {
+		if err := migration.Up(db); err != nil {
+			t.Fatalf("migration %s failed: %v", migration.Name, err)
+		}
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertUser(t *testing.T, db *bun.DB, id string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO users (id, name, email, password, status)
+		VALUES (?, ?, ?, 'secret', 1)
+	`, id, id, id+"@example.com")
+	if err != nil {
+		t.Fatalf("insert user %s: %v", id, err)
+	}
+}
+
+func TestMembersAndRoles(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	groupRepository := NewRepository(db)
+
+	groupID, err := groupRepository.Create(ctx, models.CreateRequest{Name: "ops"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	insertUser(t, db, "user-1")
+	insertUser(t, db, "user-2")
+
+	// re-adding a member is a no-op
+	for range 2 {
+		if err := groupRepository.AddMembers(ctx, groupID, []string{"user-1", "user-2"}); err != nil {
+			t.Fatalf("AddMembers() error = %v", err)
+		}
+	}
+	if err := groupRepository.RemoveMember(ctx, groupID, "user-2"); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+	members, total, err := groupRepository.ListMembers(ctx, groupID, models.ListMembersRequest{})
+	if err != nil || total != 1 || len(members) != 1 || members[0].UserID != "user-1" {
+		t.Errorf("ListMembers() = %+v, %d (err %v), want only user-1", members, total, err)
+	}
+
+	if err := groupRepository.ReplaceRoles(ctx, groupID, []string{"rol_member", "rol_viewer"}); err != nil {
+		t.Fatalf("ReplaceRoles() error = %v", err)
+	}
+	if err := groupRepository.ReplaceRoles(ctx, groupID, []string{"rol_viewer"}); err != nil {
+		t.Fatalf("ReplaceRoles(again) error = %v", err)
+	}
+	roles, err := groupRepository.ListRoles(ctx, groupID)
+	if err != nil || len(roles) != 1 || roles[0].Code != "viewer" || roles[0].AppCode != "isme" {
+		t.Errorf("ListRoles() = %+v (err %v), want the isme viewer role only", roles, err)
+	}
+
+	groups, err := groupRepository.List(ctx)
+	if err != nil || len(groups) != 1 || groups[0].MembersCount != 1 || groups[0].RolesCount != 1 {
+		t.Errorf("List() = %+v (err %v), want ops with one member and one role", groups, err)
+	}
+}
+
+// A deleted group frees its name and drops out of the list.
+func TestSoftDeleteFreesTheName(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	groupRepository := NewRepository(db)
+
+	groupID, err := groupRepository.Create(ctx, models.CreateRequest{Name: "ops"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := groupRepository.Create(ctx, models.CreateRequest{Name: "ops"}); err == nil {
+		t.Fatal("expected a second live group named ops to be rejected")
+	}
+
+	if err := groupRepository.SoftDelete(ctx, groupID); err != nil {
+		t.Fatalf("SoftDelete() error = %v", err)
+	}
+	if group, err := groupRepository.GetByName(ctx, "ops"); err != nil || group.ID != "" {
+		t.Errorf("GetByName() after delete = %+v (err %v), want none", group, err)
+	}
+	if _, err := groupRepository.Create(ctx, models.CreateRequest{Name: "ops"}); err != nil {
+		t.Errorf("Create() after delete error = %v", err)
+	}
+	if groups, err := groupRepository.List(ctx); err != nil || len(groups) != 1 || groups[0].ID == groupID {
+		t.Errorf("List() = %+v (err %v), want only the new ops", groups, err)
+	}
+}
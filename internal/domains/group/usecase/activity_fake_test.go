@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+)
+
+// fakeActivityUsecase is a test double for the activity recorder. It records
+// each audit entry so tests can assert the right events were emitted, and can
+// be made to fail (auditErr) to prove a failed audit fails the call.
+type fakeActivityUsecase struct {
+	auditEntries []activityModels.AuditEntry
+	auditErr     error
+}
+
+func (f *fakeActivityUsecase) RecordSignIn(ctx context.Context, userID, device, clientIP string) {
+}
+
+func (f *fakeActivityUsecase) RecordSignOut(ctx context.Context, userID string) {}
+
+func (f *fakeActivityUsecase) RecordPasswordChanged(ctx context.Context, userID string) {}
+
+func (f *fakeActivityUsecase) RecordProfileUpdated(ctx context.Context, userID string) {}
+
+func (f *fakeActivityUsecase) RecordInvitationSent(ctx context.Context, inviterID, email string, roleNames []string) {
+}
+
+func (f *fakeActivityUsecase) RecordAudit(ctx context.Context, entry activityModels.AuditEntry) error {
+	f.auditEntries = append(f.auditEntries, entry)
+	return f.auditErr
+}
+
+func (f *fakeActivityUsecase) List(ctx context.Context, userID string, limit int) ([]activityModels.ActivityItem, error) {
+	return nil, nil
+}
+
+func (f *fakeActivityUsecase) SearchAudit(ctx context.Context, req activityModels.AuditSearchRequest) (activityModels.AuditSearchResponse, error) {
+	return activityModels.AuditSearchResponse{}, nil
+}
+
+func (f *fakeActivityUsecase) ExportAudit(ctx context.Context, req activityModels.AuditExportRequest) (activityModels.AuditExport, error) {
+	return activityModels.AuditExport{}, nil
+}
+
+func (f *fakeActivityUsecase) VerifyChain(ctx context.Context) (activityModels.ChainReport, error) {
+	return activityModels.ChainReport{}, nil
+}
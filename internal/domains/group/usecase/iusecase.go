@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/vukyn/isme/internal/domains/group/models"
+)
+
+type IUseCase interface {
+	// List groups with member and role counts
+	List(ctx context.Context) ([]models.GroupListItem, error)
+	// Create a group (names are unique among live groups)
+	Create(ctx context.Context, req models.CreateRequest) (models.CreateResponse, error)
+	// Get group detail including the roles it grants
+	GetDetail(ctx context.Context, id string) (models.GroupDetailResponse, error)
+	// Update group name and description
+	Update(ctx context.Context, id string, req models.UpdateRequest) error
+	// Delete a group; its members stop inheriting its roles
+	Delete(ctx context.Context, id string) error
+	// List group members with pagination
+	ListMembers(ctx context.Context, id string, req models.ListMembersRequest) (models.ListMembersResponse, error)
+	// Add users to a group
+	AddMembers(ctx context.Context, id string, req models.AddMembersRequest) error
+	// Remove a user from a group
+	RemoveMember(ctx context.Context, id string, userID string) error
+	// Replace the roles a group grants its members
+	SetRoles(ctx context.Context, id string, req models.SetRolesRequest) error
+}
@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
+	"github.com/vukyn/isme/internal/domains/group/entity"
+	"github.com/vukyn/isme/internal/domains/group/models"
+	groupRepo "github.com/vukyn/isme/internal/domains/group/repository"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
+	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	"github.com/vukyn/isme/internal/transaction"
+
+	pkgErr "github.com/vukyn/kuery/http/errors"
+)
+
+type usecase struct {
+	groupRepo       groupRepo.IRepository
+	userRepo        userRepo.IRepository
+	roleRepo        roleRepo.IRepository
+	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
+}
+
+func NewUsecase(
+	groupRepo groupRepo.IRepository,
+	userRepo userRepo.IRepository,
+	roleRepo roleRepo.IRepository,
+	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
+) IUseCase {
+	return &usecase{
+		groupRepo:       groupRepo,
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
+	}
+}
+
+func (u *usecase) List(ctx context.Context) ([]models.GroupListItem, error) {
+	return u.groupRepo.List(ctx)
+}
+
+func (u *usecase) Create(ctx context.Context, req models.CreateRequest) (models.CreateResponse, error) {
+	// validation
+	req.Name = strings.TrimSpace(req.Name)
+	if err := req.Validate(); err != nil {
+		return models.CreateResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	// check name uniqueness
+	if err := u.checkNameFree(ctx, req.Name, ""); err != nil {
+		return models.CreateResponse{}, err
+	}
+
+	var groupID string
+	err := u.txRunner.Run(ctx, func(ctx context.Context) error {
+		var err error
+		groupID, err = u.groupRepo.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeGroupCreated,
+			TargetType: activityConstants.TargetTypeGroup,
+			TargetID:   groupID,
+			After:      req,
+		})
+	})
+	if err != nil {
+		return models.CreateResponse{}, err
+	}
+
+	return models.CreateResponse{
+		ID: groupID,
+	}, nil
+}
+
+func (u *usecase) GetDetail(ctx context.Context, id string) (models.GroupDetailResponse, error) {
+	group, err := u.getGroup(ctx, id)
+	if err != nil {
+		return models.GroupDetailResponse{}, err
+	}
+
+	membersCount, err := u.groupRepo.CountMembers(ctx, id)
+	if err != nil {
+		return models.GroupDetailResponse{}, err
+	}
+	roles, err := u.groupRepo.ListRoles(ctx, id)
+	if err != nil {
+		return models.GroupDetailResponse{}, err
+	}
+
+	return models.GroupDetailResponse{
+		ID:           group.ID,
+		Name:         group.Name,
+		Description:  group.Description,
+		MembersCount: membersCount,
+		Roles:        roles,
+		CreatedAt:    group.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (u *usecase) Update(ctx context.Context, id string, req models.UpdateRequest) error {
+	// validation
+	req.Name = strings.TrimSpace(req.Name)
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	group, err := u.getGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkNameFree(ctx, req.Name, id); err != nil {
+		return err
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.Update(ctx, id, req); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeGroupUpdated,
+			TargetType: activityConstants.TargetTypeGroup,
+			TargetID:   id,
+			Before: models.UpdateRequest{
+				Name:        group.Name,
+				Description: group.Description,
+			},
+			After: req,
+		})
+	})
+}
+
+func (u *usecase) Delete(ctx context.Context, id string) error {
+	group, err := u.getGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.SoftDelete(ctx, id); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeGroupDeleted,
+			TargetType: activityConstants.TargetTypeGroup,
+			TargetID:   id,
+			Before: models.UpdateRequest{
+				Name:        group.Name,
+				Description: group.Description,
+			},
+		})
+	})
+}
+
+func (u *usecase) ListMembers(ctx context.Context, id string, req models.ListMembersRequest) (models.ListMembersResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return models.ListMembersResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	if _, err := u.getGroup(ctx, id); err != nil {
+		return models.ListMembersResponse{}, err
+	}
+
+	items, total, err := u.groupRepo.ListMembers(ctx, id, req)
+	if err != nil {
+		return models.ListMembersResponse{}, err
+	}
+
+	return models.ListMembersResponse{
+		Items: items,
+		Total: total,
+		Page:  req.Page,
+	}, nil
+}
+
+func (u *usecase) AddMembers(ctx context.Context, id string, req models.AddMembersRequest) error {
+	// validation
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	if _, err := u.getGroup(ctx, id); err != nil {
+		return err
+	}
+
+	// check users exist
+	for _, userID := range req.UserIDs {
+		user, err := u.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.ID == "" {
+			return pkgErr.InvalidRequest("user not found: " + userID)
+		}
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.AddMembers(ctx, id, req.UserIDs); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeGroupMembersAdded,
+			TargetType: activityConstants.TargetTypeGroup,
+			TargetID:   id,
+			After:      req,
+		})
+	})
+}
+
+func (u *usecase) RemoveMember(ctx context.Context, id string, userID string) error {
+	if _, err := u.getGroup(ctx, id); err != nil {
+		return err
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.RemoveMember(ctx, id, userID); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeGroupMemberRemoved,
+			TargetType: activityConstants.TargetTypeGroup,
+			TargetID:   id,
+			Before:     models.AddMembersRequest{UserIDs: []string{userID}},
+		})
+	})
+}
+
+func (u *usecase) SetRoles(ctx context.Context, id string, req models.SetRolesRequest) error {
+	// validation
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	if _, err := u.getGroup(ctx, id); err != nil {
+		return err
+	}
+
+	// check roles exist; a group may grant roles of several apps
+	for _, roleID := range req.RoleIDs {
+		role, err := u.roleRepo.GetByID(ctx, roleID)
+		if err != nil {
+			return err
+		}
+		if role.ID == "" {
+			return pkgErr.InvalidRequest("role not found: " + roleID)
+		}
+	}
+
+	before, err := u.groupRepo.ListRoles(ctx, id)
+	if err != nil {
+		return err
+	}
+	beforeRoleIDs := make([]string, 0, len(before))
+	for _, role := range before {
+		beforeRoleIDs = append(beforeRoleIDs, role.ID)
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.ReplaceRoles(ctx, id, req.RoleIDs); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeGroupRolesSet,
+			TargetType: activityConstants.TargetTypeGroup,
+			TargetID:   id,
+			Before:     models.SetRolesRequest{RoleIDs: beforeRoleIDs},
+			After:      req,
+		})
+	})
+}
+
+// getGroup loads a live group or fails with not found.
+func (u *usecase) getGroup(ctx context.Context, id string) (entity.UserGroup, error) {
+	group, err := u.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return entity.UserGroup{}, err
+	}
+	if group.ID == "" {
+		return entity.UserGroup{}, pkgErr.NotFound("group not found")
+	}
+	return group, nil
+}
+
+// checkNameFree rejects a name another live group already uses; selfID is the
+// group being renamed, empty on create.
+func (u *usecase) checkNameFree(ctx context.Context, name string, selfID string) error {
+	existing, err := u.groupRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing.ID != "" && existing.ID != selfID {
+		return pkgErr.InvalidRequest("group name already exists")
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	"github.com/vukyn/isme/internal/domains/group/entity"
+	"github.com/vukyn/isme/internal/domains/group/models"
+	groupRepo "github.com/vukyn/isme/internal/domains/group/repository"
+	roleEntity "github.com/vukyn/isme/internal/domains/role/entity"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
+	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
+	userModels "github.com/vukyn/isme/internal/domains/user/models"
+	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	"github.com/vukyn/isme/internal/transaction"
+)
+
+// === group repository fake ===
+
+type fakeGroupRepository struct {
+	groups  map[string]entity.UserGroup
+	members map[string][]string
+	roles   map[string][]string
+}
+
+var _ groupRepo.IRepository = (*fakeGroupRepository)(nil)
+
+func (f *fakeGroupRepository) Create(ctx context.Context, req models.CreateRequest) (string, error) {
+	id := "grp_" + req.Name
+	f.groups[id] = entity.UserGroup{ID: id, Name: req.Name, Description: req.Description}
+	return id, nil
+}
+
+func (f *fakeGroupRepository) GetByID(ctx context.Context, id string) (entity.UserGroup, error) {
+	return f.groups[id], nil
+}
+
+func (f *fakeGroupRepository) GetByName(ctx context.Context, name string) (entity.UserGroup, error) {
+	for _, group := range f.groups {
+		if group.Name == name {
+			return group, nil
+		}
+	}
+	return entity.UserGroup{}, nil
+}
+
+func (f *fakeGroupRepository) List(ctx context.Context) ([]models.GroupListItem, error) {
+	return nil, nil
+}
+
+func (f *fakeGroupRepository) Update(ctx context.Context, id string, req models.UpdateRequest) error {
+	group := f.groups[id]
+	group.Name, group.Description = req.Name, req.Description
+	f.groups[id] = group
+	return nil
+}
+
+func (f *fakeGroupRepository) SoftDelete(ctx context.Context, id string) error {
+	delete(f.groups, id)
+	return nil
+}
+
+func (f *fakeGroupRepository) ListMembers(ctx context.Context, groupID string, req models.ListMembersRequest) ([]models.MemberItem, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeGroupRepository) CountMembers(ctx context.Context, groupID string) (int, error) {
+	return len(f.members[groupID]), nil
+}
+
+func (f *fakeGroupRepository) AddMembers(ctx context.Context, groupID string, userIDs []string) error {
+	for _, userID := range userIDs {
+		if !slices.Contains(f.members[groupID], userID) {
+			f.members[groupID] = append(f.members[groupID], userID)
+		}
+	}
+	return nil
+}
+
+func (f *fakeGroupRepository) RemoveMember(ctx context.Context, groupID string, userID string) error {
+	f.members[groupID] = slices.DeleteFunc(f.members[groupID], func(id string) bool { return id == userID })
+	return nil
+}
+
+func (f *fakeGroupRepository) ListRoles(ctx context.Context, groupID string) ([]models.RoleItem, error) {
+	items := []models.RoleItem{}
+	for _, roleID := range f.roles[groupID] {
+		items = append(items, models.RoleItem{ID: roleID})
+	}
+	return items, nil
+}
+
+func (f *fakeGroupRepository) ReplaceRoles(ctx context.Context, groupID string, roleIDs []string) error {
+	f.roles[groupID] = roleIDs
+	return nil
+}
+
+// === user repository fake ===
+
+type fakeUserRepository struct {
+	users map[string]bool
+}
+
+var _ userRepo.IRepository = (*fakeUserRepository)(nil)
+
+func (f *fakeUserRepository) Create(ctx context.Context, req userModels.CreateRequest) (string, error) {
+	return "", nil
+}
+
+func (f *fakeUserRepository) GetByID(ctx context.Context, id string) (userEntity.User, error) {
+	if !f.users[id] {
+		return userEntity.User{}, nil
+	}
+	return userEntity.User{ID: id}, nil
+}
+
+func (f *fakeUserRepository) GetByEmail(ctx context.Context, email string) (userEntity.User, error) {
+	return userEntity.User{}, nil
+}
+
+func (f *fakeUserRepository) SetPassword(ctx context.Context, id string, password string) error {
+	return nil
+}
+
+func (f *fakeUserRepository) UpdateProfile(ctx context.Context, id string, name string, avatarURL string) error {
+	return nil
+}
+
+func (f *fakeUserRepository) UpdateLastLogin(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeUserRepository) Verify(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeUserRepository) List(ctx context.Context, req userModels.ListRequest) ([]userEntity.User, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeUserRepository) UpdateStatus(ctx context.Context, id string, status int32) error {
+	return nil
+}
+
+func (f *fakeUserRepository) SoftDelete(ctx context.Context, id string) error {
+	return nil
+}
+
+// === role repository fake ===
+
+type fakeRoleRepository struct {
+	roles map[string]roleEntity.Role
+}
+
+var _ roleRepo.IRepository = (*fakeRoleRepository)(nil)
+
+func (f *fakeRoleRepository) Create(ctx context.Context, req roleModels.CreateRequest) (string, error) {
+	return "", nil
+}
+
+func (f *fakeRoleRepository) GetByID(ctx context.Context, id string) (roleEntity.Role, error) {
+	return f.roles[id], nil
+}
+
+func (f *fakeRoleRepository) GetByAppAndCode(ctx context.Context, appID string, code string) (roleEntity.Role, error) {
+	return roleEntity.Role{}, nil
+}
+
+func (f *fakeRoleRepository) List(ctx context.Context, req roleModels.ListRequest) ([]roleModels.RoleListItem, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) Update(ctx context.Context, id string, req roleModels.UpdateRequest) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) SoftDelete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) ListPermissions(ctx context.Context, req roleModels.ListPermissionsRequest) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) CreatePermissions(ctx context.Context, appID string, perms []roleModels.PermissionItem) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetPermissionByID(ctx context.Context, permissionID int64) (roleEntity.Permission, error) {
+	return roleEntity.Permission{}, nil
+}
+
+func (f *fakeRoleRepository) DeletePermission(ctx context.Context, permissionID int64) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) UpdatePermissionAppearance(ctx context.Context, appID string, resource string, icon string, color string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]roleEntity.Role, error) {
+	return map[string][]roleEntity.Role{}, nil
+}
+
+func (f *fakeRoleRepository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	return roleIDs, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRolePermissions(ctx context.Context, roleID string, permissionIDs []int64) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) ListMembers(ctx context.Context, roleID string, req roleModels.ListMembersRequest) ([]roleModels.MemberItem, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeRoleRepository) CountMembersByRoleID(ctx context.Context, roleID string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window roleModels.MemberWindow) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]roleEntity.UserRole, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeRoleRepository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) GetPermissionCodesByUserID(ctx context.Context, userID string, appID string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetPermissionCodesGroupedByApp(ctx context.Context, userID string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) GetAppCodesByUserID(ctx context.Context, userID string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetRoleCodesByUserID(ctx context.Context, userID string, appServiceID string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, userIDs []string) (map[string][]roleModels.UserAppRole, error) {
+	return map[string][]roleModels.UserAppRole{}, nil
+}
+
+// === Tests ===
+
+func newTestUsecase(activity *fakeActivityUsecase) (IUseCase, *fakeGroupRepository) {
+	fakeGroup := &fakeGroupRepository{
+		groups: map[string]entity.UserGroup{
+			"grp_ops": {ID: "grp_ops", Name: "ops"},
+		},
+		members: map[string][]string{},
+		roles:   map[string][]string{},
+	}
+	fakeUser := &fakeUserRepository{users: map[string]bool{"usr-1": true, "usr-2": true}}
+	fakeRole := &fakeRoleRepository{roles: map[string]roleEntity.Role{
+		"rol_viewer":        {ID: "rol_viewer", AppID: "app_isme", Code: "viewer"},
+		"rol_medioa_editor": {ID: "rol_medioa_editor", AppID: "app_medioa", Code: "editor"},
+	}}
+	return NewUsecase(fakeGroup, fakeUser, fakeRole, activity, transaction.NoopRunner{}), fakeGroup
+}
+
+func TestCreateRejectsTakenName(t *testing.T) {
+	activity := &fakeActivityUsecase{}
+	uc, _ := newTestUsecase(activity)
+	ctx := context.Background()
+
+	if _, err := uc.Create(ctx, models.CreateRequest{Name: "  ops "}); err == nil {
+		t.Fatal("expected a name another group uses to be rejected")
+	}
+	if _, err := uc.Create(ctx, models.CreateRequest{Name: " "}); err == nil {
+		t.Fatal("expected a blank name to be rejected")
+	}
+
+	created, err := uc.Create(ctx, models.CreateRequest{Name: " support "})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID != "grp_support" {
+		t.Errorf("Create() id = %q, want the trimmed name to be stored", created.ID)
+	}
+	if len(activity.auditEntries) != 1 || activity.auditEntries[0].Type != activityConstants.ActivityTypeGroupCreated {
+		t.Errorf("audit entries = %+v, want one group_created", activity.auditEntries)
+	}
+
+	// renaming a group to its own name is not a conflict
+	if err := uc.Update(ctx, "grp_ops", models.UpdateRequest{Name: "ops", Description: "on call"}); err != nil {
+		t.Errorf("Update(own name) error = %v", err)
+	}
+	if err := uc.Update(ctx, "grp_support", models.UpdateRequest{Name: "ops"}); err == nil {
+		t.Error("expected renaming onto another group's name to be rejected")
+	}
+}
+
+func TestMembershipChangesAreAudited(t *testing.T) {
+	activity := &fakeActivityUsecase{}
+	uc, fakeGroup := newTestUsecase(activity)
+	ctx := context.Background()
+
+	if err := uc.AddMembers(ctx, "grp_ops", models.AddMembersRequest{UserIDs: []string{"usr-1", "usr-ghost"}}); err == nil {
+		t.Fatal("expected an unknown user to be rejected")
+	}
+	if len(fakeGroup.members["grp_ops"]) != 0 || len(activity.auditEntries) != 0 {
+		t.Fatalf("rejected add changed state: members %v, audit %+v", fakeGroup.members, activity.auditEntries)
+	}
+
+	if err := uc.AddMembers(ctx, "grp_ops", models.AddMembersRequest{UserIDs: []string{"usr-1", "usr-2"}}); err != nil {
+		t.Fatalf("AddMembers() error = %v", err)
+	}
+	if err := uc.RemoveMember(ctx, "grp_ops", "usr-1"); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+	if !slices.Equal(fakeGroup.members["grp_ops"], []string{"usr-2"}) {
+		t.Errorf("members = %v, want [usr-2]", fakeGroup.members["grp_ops"])
+	}
+
+	wantTypes := []string{activityConstants.ActivityTypeGroupMembersAdded, activityConstants.ActivityTypeGroupMemberRemoved}
+	if len(activity.auditEntries) != len(wantTypes) {
+		t.Fatalf("audit entries = %+v, want %v", activity.auditEntries, wantTypes)
+	}
+	for i, entry := range activity.auditEntries {
+		if entry.Type != wantTypes[i] || entry.TargetType != activityConstants.TargetTypeGroup || entry.TargetID != "grp_ops" {
+			t.Errorf("audit entry %d = %+v, want %s on group grp_ops", i, entry, wantTypes[i])
+		}
+	}
+	removed, ok := activity.auditEntries[1].Before.(models.AddMembersRequest)
+	if !ok || !slices.Equal(removed.UserIDs, []string{"usr-1"}) {
+		t.Errorf("member_removed before = %+v, want usr-1", activity.auditEntries[1].Before)
+	}
+
+	if err := uc.AddMembers(ctx, "grp_missing", models.AddMembersRequest{UserIDs: []string{"usr-1"}}); err == nil {
+		t.Error("expected adding to a missing group to be rejected")
+	}
+}
+
+func TestSetRolesAcrossApps(t *testing.T) {
+	activity := &fakeActivityUsecase{}
+	uc, fakeGroup := newTestUsecase(activity)
+	ctx := context.Background()
+	fakeGroup.roles["grp_ops"] = []string{"rol_viewer"}
+
+	if err := uc.SetRoles(ctx, "grp_ops", models.SetRolesRequest{RoleIDs: []string{"rol_missing"}}); err == nil {
+		t.Fatal("expected an unknown role to be rejected")
+	}
+	if err := uc.SetRoles(ctx, "grp_ops", models.SetRolesRequest{RoleIDs: []string{"rol_viewer", "rol_viewer"}}); err == nil {
+		t.Fatal("expected duplicate roles to be rejected")
+	}
+
+	// roles of different apps may share a group
+	want := []string{"rol_viewer", "rol_medioa_editor"}
+	if err := uc.SetRoles(ctx, "grp_ops", models.SetRolesRequest{RoleIDs: want}); err != nil {
+		t.Fatalf("SetRoles() error = %v", err)
+	}
+	if !slices.Equal(fakeGroup.roles["grp_ops"], want) {
+		t.Errorf("roles = %v, want %v", fakeGroup.roles["grp_ops"], want)
+	}
+	if len(activity.auditEntries) != 1 {
+		t.Fatalf("audit entries = %+v, want one group_roles_set", activity.auditEntries)
+	}
+	before, ok := activity.auditEntries[0].Before.(models.SetRolesRequest)
+	if !ok || !slices.Equal(before.RoleIDs, []string{"rol_viewer"}) {
+		t.Errorf("roles_set before = %+v, want the previous roles", activity.auditEntries[0].Before)
+	}
+}
+
+func TestFailedAuditFailsTheCall(t *testing.T) {
+	activity := &fakeActivityUsecase{auditErr: errors.New("audit store down")}
+	uc, _ := newTestUsecase(activity)
+
+	if err := uc.Delete(context.Background(), "grp_ops"); err == nil {
+		t.Error("expected Delete() to fail when the audit row cannot be written")
+	}
+}
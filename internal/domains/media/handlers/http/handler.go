@@ -6,6 +6,7 @@ import (
 
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/media/models"
+	"github.com/vukyn/isme/internal/tracing"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -45,7 +46,7 @@ func Upload(c *fiber.Ctx) error {
 		Size:        fileHeader.Size,
 	}
 
-	resp, err := uc.Upload(pkgCtx.NewContextFromFiberCtx(c), req)
+	resp, err := uc.Upload(tracing.NewContextFromFiberCtx(c), req)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -4,6 +4,8 @@ import (
 	iapp "github.com/vukyn/isme/internal/app"
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
+	"github.com/vukyn/isme/internal/domains/media/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,3 +20,10 @@ func SetupMediaRoutes(router fiber.Router) {
 	// returned URL is only persisted on the caller's own user record.
 	r.Post(constants.MEDIA_ENDPOINT_UPLOAD, middleware.AuthMiddleware, Upload)
 }
+
+// Operations documents the routes registered by SetupMediaRoutes; keep the two
+// in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodPost, Path: constants.MEDIA_GROUP_NAME + constants.MEDIA_ENDPOINT_UPLOAD, Tag: "media", Summary: "Upload an avatar image (PNG, JPEG or WebP, at most 2MB)",
+		Upload: "file", Response: models.UploadResponse{}, Auth: true, Errors: []int{fiber.StatusRequestEntityTooLarge, fiber.StatusBadGateway}},
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/vukyn/isme/internal/config"
 	"github.com/vukyn/isme/internal/domains/media/exceptions"
 	"github.com/vukyn/isme/internal/domains/media/models"
+	"github.com/vukyn/isme/internal/tracing"
 
 	pkgBase "github.com/vukyn/kuery/http/base"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -39,6 +40,14 @@ func (u *usecase) Upload(ctx context.Context, req models.UploadRequest) (models.
 		})
 	}
 
+	// The SDK owns its HTTP client, so the trace is not propagated to medioa;
+	// the client span still times the call from this side.
+	ctx, span := tracing.Start(ctx, "medioa.Upload", tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(
+		tracing.String("peer.service", "medioa"),
+		tracing.Int64("file.size", req.Size),
+	))
+	defer span.End()
+
 	// Avatars are small images — single-shot upload under the avatars path.
 	result, err := u.medioaClient.Upload(ctx, medioa.UploadInput{
 		File:        req.File,
@@ -48,6 +57,7 @@ func (u *usecase) Upload(ctx context.Context, req models.UploadRequest) (models.
 		Path:        pathAvatars,
 	})
 	if err != nil {
+		span.RecordError(err)
 		return models.UploadResponse{}, exceptions.MapMediaError(err)
 	}
 
@@ -4,7 +4,7 @@ import (
 	"github.com/vukyn/kuery/rbac"
 )
 
-// Permission codes (must match the catalog seeded by 010_seed_rbac + 012_add_user_verification + 022_create_session_revoke_config — 21 permissions)
+// Permission codes (must match the catalog seeded by 010_seed_rbac + 012_add_user_verification + 022_create_session_revoke_config + 033_seed_audit_permission + 039_create_access_requests_table — 23 permissions)
 var (
 	PERM_USER_READ           = rbac.Perm("user", "read")
 	PERM_USER_CREATE         = rbac.Perm("user", "create")
@@ -31,8 +31,29 @@ var (
 
 	PERM_SETTINGS_READ   = rbac.Perm("settings", "read")
 	PERM_SETTINGS_UPDATE = rbac.Perm("settings", "update")
+
+	PERM_AUDIT_READ = rbac.Perm("audit", "read")
+
+	// PERM_ACCESS_REQUEST_APPROVE is reserved in every app's catalog, not just
+	// isme's: holding it in an app makes a user an approver of access requests
+	// for that app's roles.
+	PERM_ACCESS_REQUEST_APPROVE = rbac.Perm("access_request", "approve")
 )
 
+// Resource and action of the reserved approval permission, for the catalog
+// writes that create it outside of an app's own sync.
+const (
+	RESOURCE_ACCESS_REQUEST = "access_request"
+	ACTION_APPROVE          = "approve"
+)
+
+// IsReservedPermission reports whether a resource:action code is owned by isme
+// rather than by the app whose catalog holds it. Catalog syncs never flag it,
+// manifests never prune it and it cannot be deleted.
+func IsReservedPermission(code string) bool {
+	return code == PERM_ACCESS_REQUEST_APPROVE
+}
+
 // isme self-app identifiers. isme is itself an app_service that owns the
 // original permission catalog and system roles (seeded by 014/015).
 const (
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/uptrace/bun"
 )
 
@@ -19,4 +21,8 @@ type Permission struct {
 	// fallback. All rows of the same (app_id, resource) share the same color,
 	// exactly like Icon. NOT NULL DEFAULT '' (no nullzero).
 	Color string `bun:"color"`
+	// FlaggedAt is set when the owning app's last catalog sync no longer lists
+	// the permission; it stays (with its grants) until an admin deletes it.
+	// Zero = not flagged.
+	FlaggedAt time.Time `bun:"flagged_at,nullzero"`
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// RoleInclude makes RoleID grant everything IncludedRoleID grants. Both roles
+// belong to the same app; cycles are rejected before the row is written.
+type RoleInclude struct {
+	bun.BaseModel  `bun:"table:role_includes,alias:ri"`
+	RoleID         string    `bun:"role_id,pk,notnull"`
+	IncludedRoleID string    `bun:"included_role_id,pk,notnull"`
+	CreatedAt      time.Time `bun:"created_at,default:current_timestamp"`
+	CreatedBy      string    `bun:"created_by,nullzero"`
+}
+
+// === Hooks ===
+
+func (ri *RoleInclude) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery:
+		ri.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
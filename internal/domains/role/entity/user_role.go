@@ -15,6 +15,10 @@ type UserRole struct {
 	AppServiceID  *string   `bun:"app_service_id"`
 	CreatedAt     time.Time `bun:"created_at,default:current_timestamp"`
 	CreatedBy     string    `bun:"created_by,nullzero"`
+	// StartsAt/ExpiresAt bound when the assignment is in force; nil is an open
+	// bound (active from creation / never expires).
+	StartsAt  *time.Time `bun:"starts_at"`
+	ExpiresAt *time.Time `bun:"expires_at"`
 }
 
 // === Hooks ===
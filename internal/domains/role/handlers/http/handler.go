@@ -5,6 +5,7 @@ import (
 
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/role/models"
+	"github.com/vukyn/isme/internal/tracing"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -27,7 +28,7 @@ func ListRoles(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	roles, err := uc.List(pkgCtx.NewContextFromFiberCtx(c), listRequest)
+	roles, err := uc.List(tracing.NewContextFromFiberCtx(c), listRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -49,7 +50,7 @@ func CreateRole(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	createResponse, err := uc.Create(pkgCtx.NewContextFromFiberCtx(c), createRequest)
+	createResponse, err := uc.Create(tracing.NewContextFromFiberCtx(c), createRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -66,7 +67,7 @@ func GetRoleDetail(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	detailResponse, err := uc.GetDetail(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID"))
+	detailResponse, err := uc.GetDetail(tracing.NewContextFromFiberCtx(c), c.Params("roleID"))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -88,7 +89,7 @@ func UpdateRole(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.Update(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID"), updateRequest); err != nil {
+	if err := uc.Update(tracing.NewContextFromFiberCtx(c), c.Params("roleID"), updateRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -104,7 +105,7 @@ func DeleteRole(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.Delete(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID")); err != nil {
+	if err := uc.Delete(tracing.NewContextFromFiberCtx(c), c.Params("roleID")); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -125,7 +126,28 @@ func SetRolePermissions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.SetPermissions(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID"), setPermissionsRequest); err != nil {
+	if err := uc.SetPermissions(tracing.NewContextFromFiberCtx(c), c.Params("roleID"), setPermissionsRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	return pkgHttp.OK(c, nil)
+}
+
+func SetRoleIncludes(c *fiber.Ctx) error {
+	ctn := pkgCtx.GetDiContainerRequestFromFiberCtx(c)
+	defer ctn.Delete()
+
+	uc, err := idi.GetRoleUsecase(ctn)
+	if err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	setIncludesRequest := models.SetIncludesRequest{}
+	if err := c.BodyParser(&setIncludesRequest); err != nil {
+		return pkgHttp.Err(c, err)
+	}
+
+	if err := uc.SetIncludes(tracing.NewContextFromFiberCtx(c), c.Params("roleID"), setIncludesRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -146,7 +168,7 @@ func ListRoleMembers(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	listMembersResponse, err := uc.ListMembers(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID"), listMembersRequest)
+	listMembersResponse, err := uc.ListMembers(tracing.NewContextFromFiberCtx(c), c.Params("roleID"), listMembersRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -168,7 +190,7 @@ func AddRoleMembers(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.AddMembers(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID"), addMembersRequest); err != nil {
+	if err := uc.AddMembers(tracing.NewContextFromFiberCtx(c), c.Params("roleID"), addMembersRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -189,7 +211,7 @@ func RemoveRoleMember(c *fiber.Ctx) error {
 		appServiceID = &appServiceIDQuery
 	}
 
-	if err := uc.RemoveMember(pkgCtx.NewContextFromFiberCtx(c), c.Params("roleID"), c.Params("userID"), appServiceID); err != nil {
+	if err := uc.RemoveMember(tracing.NewContextFromFiberCtx(c), c.Params("roleID"), c.Params("userID"), appServiceID); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -210,7 +232,7 @@ func ListPermissions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	permissions, err := uc.ListPermissions(pkgCtx.NewContextFromFiberCtx(c), listPermissionsRequest)
+	permissions, err := uc.ListPermissions(tracing.NewContextFromFiberCtx(c), listPermissionsRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -232,7 +254,7 @@ func CreatePermissions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	permissions, err := uc.CreatePermissions(pkgCtx.NewContextFromFiberCtx(c), createPermissionsRequest)
+	permissions, err := uc.CreatePermissions(tracing.NewContextFromFiberCtx(c), createPermissionsRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -254,7 +276,7 @@ func UpdatePermissionAppearance(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdatePermissionAppearance(pkgCtx.NewContextFromFiberCtx(c), updateAppearanceRequest); err != nil {
+	if err := uc.UpdatePermissionAppearance(tracing.NewContextFromFiberCtx(c), updateAppearanceRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -275,7 +297,7 @@ func DeletePermission(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, pkgErr.InvalidRequest("invalid permission id"))
 	}
 
-	if err := uc.DeletePermission(pkgCtx.NewContextFromFiberCtx(c), permissionID); err != nil {
+	if err := uc.DeletePermission(tracing.NewContextFromFiberCtx(c), permissionID); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
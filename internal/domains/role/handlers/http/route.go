@@ -5,6 +5,8 @@ import (
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/domains/role/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/vukyn/kuery/rbac"
 
@@ -21,6 +23,7 @@ func SetupRoleRoutes(router fiber.Router) {
 	rRole.Put(constants.ROLE_ENDPOINT_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_UPDATE), UpdateRole)
 	rRole.Delete(constants.ROLE_ENDPOINT_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_DELETE), DeleteRole)
 	rRole.Put(constants.ROLE_ENDPOINT_PERMISSIONS, rbac.RequirePermission(roleConstants.PERM_ROLE_UPDATE), SetRolePermissions)
+	rRole.Put(constants.ROLE_ENDPOINT_INCLUDES, rbac.RequirePermission(roleConstants.PERM_ROLE_UPDATE), SetRoleIncludes)
 	rRole.Get(constants.ROLE_ENDPOINT_MEMBERS, rbac.RequirePermission(roleConstants.PERM_ROLE_READ), ListRoleMembers)
 	rRole.Post(constants.ROLE_ENDPOINT_MEMBERS, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), AddRoleMembers)
 	rRole.Delete(constants.ROLE_ENDPOINT_MEMBER_DETAIL, rbac.RequirePermission(roleConstants.PERM_ROLE_ASSIGN), RemoveRoleMember)
@@ -30,3 +33,39 @@ func SetupRoleRoutes(router fiber.Router) {
 	router.Put(constants.PERMISSION_ENDPOINT_APPEARANCE, middleware.AuthMiddleware, rbac.RequirePermission(roleConstants.PERM_ROLE_UPDATE), UpdatePermissionAppearance)
 	router.Delete(constants.PERMISSION_ENDPOINT_DETAIL, middleware.AuthMiddleware, rbac.RequirePermission(roleConstants.PERM_ROLE_DELETE), DeletePermission)
 }
+
+// Operations documents the routes registered by SetupRoleRoutes; keep the two
+// in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_ROOT, Tag: "roles", Summary: "List roles",
+		Query: models.ListRequest{}, Response: []models.RoleListItem{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ},
+	{Method: fiber.MethodPost, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_ROOT, Tag: "roles", Summary: "Create a role",
+		Body: models.CreateRequest{}, Response: models.CreateResponse{}, Auth: true, Permission: roleConstants.PERM_ROLE_CREATE, Errors: []int{fiber.StatusConflict}},
+	{Method: fiber.MethodGet, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_DETAIL, Tag: "roles", Summary: "Get a role with its direct and inherited permissions",
+		Response: models.RoleDetailResponse{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_DETAIL, Tag: "roles", Summary: "Update a role",
+		Body: models.UpdateRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_UPDATE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_DETAIL, Tag: "roles", Summary: "Delete a role",
+		Auth: true, Permission: roleConstants.PERM_ROLE_DELETE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_PERMISSIONS, Tag: "roles", Summary: "Replace a role's permissions",
+		Body: models.SetPermissionsRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_UPDATE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_INCLUDES, Tag: "roles", Summary: "Replace the roles a role includes",
+		Body: models.SetIncludesRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_UPDATE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_MEMBERS, Tag: "roles", Summary: "List a role's members",
+		Query: models.ListMembersRequest{}, Response: models.ListMembersResponse{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_MEMBERS, Tag: "roles", Summary: "Assign a role to users, optionally for a bounded window",
+		Body: models.AddMembersRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: constants.ROLE_GROUP_NAME + constants.ROLE_ENDPOINT_MEMBER_DETAIL, Tag: "roles", Summary: "Remove a user from a role",
+		Query: struct {
+			AppServiceID string `query:"app_service_id"`
+		}{}, Auth: true, Permission: roleConstants.PERM_ROLE_ASSIGN, Errors: []int{fiber.StatusNotFound}},
+
+	{Method: fiber.MethodGet, Path: constants.PERMISSION_ENDPOINT_CATALOG, Tag: "permissions", Summary: "List the permission catalog",
+		Query: models.ListPermissionsRequest{}, Response: []models.PermissionItem{}, Auth: true, Permission: roleConstants.PERM_ROLE_READ},
+	{Method: fiber.MethodPost, Path: constants.PERMISSION_ENDPOINT_CATALOG, Tag: "permissions", Summary: "Add permissions to the catalog",
+		Body: models.CreatePermissionsRequest{}, Response: []models.PermissionItem{}, Auth: true, Permission: roleConstants.PERM_ROLE_CREATE, Errors: []int{fiber.StatusConflict}},
+	{Method: fiber.MethodPut, Path: constants.PERMISSION_ENDPOINT_APPEARANCE, Tag: "permissions", Summary: "Set a resource's icon and color",
+		Body: models.UpdatePermissionAppearanceRequest{}, Auth: true, Permission: roleConstants.PERM_ROLE_UPDATE},
+	{Method: fiber.MethodDelete, Path: constants.PERMISSION_ENDPOINT_DETAIL, Tag: "permissions", Summary: "Delete a permission from the catalog",
+		Auth: true, Permission: roleConstants.PERM_ROLE_DELETE, Errors: []int{fiber.StatusNotFound}},
+}
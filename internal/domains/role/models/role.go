@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"regexp"
+	"time"
 
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
 
@@ -62,10 +63,13 @@ func (r ListRequest) Validate() error {
 	return nil
 }
 
-// ListPermissionsRequest filters the permission catalog by owning app (empty = all apps).
+// ListPermissionsRequest filters the permission catalog by owning app (empty =
+// all apps). Flagged narrows it to the permissions awaiting an admin's removal
+// confirmation after an app's catalog sync.
 type ListPermissionsRequest struct {
 	AppID   string `json:"app_id" query:"app_id"`
 	AppCode string `json:"app_code" query:"app_code"`
+	Flagged bool   `json:"flagged" query:"flagged"`
 }
 
 func (r ListPermissionsRequest) Validate() error {
@@ -106,7 +110,11 @@ func (r CreatePermissionsRequest) Validate() error {
 	if len(r.Permissions) == 0 {
 		return errors.New("permissions is required")
 	}
-	for _, permission := range r.Permissions {
+	return validatePermissionPairs(r.Permissions)
+}
+
+func validatePermissionPairs(pairs []PermissionPair) error {
+	for _, permission := range pairs {
 		if permission.Resource == "" {
 			return errors.New("resource is required")
 		}
@@ -129,6 +137,28 @@ func (r CreatePermissionsRequest) Validate() error {
 	return nil
 }
 
+// SyncPermissionsRequest is an app's full permission catalog as the app itself
+// checks it. Pairs are upserted like CreatePermissionsRequest; catalog entries
+// it omits are flagged for an admin to confirm, never deleted. An empty list
+// flags the whole catalog.
+type SyncPermissionsRequest struct {
+	Permissions []PermissionPair `json:"permissions"`
+}
+
+func (r SyncPermissionsRequest) Validate() error {
+	return validatePermissionPairs(r.Permissions)
+}
+
+// SyncPermissionsResponse lists what a catalog sync changed, as
+// resource:action codes: Created were added, Flagged await an admin's removal
+// confirmation, Restored were flagged before and are listed again.
+type SyncPermissionsResponse struct {
+	Created   []string `json:"created"`
+	Flagged   []string `json:"flagged"`
+	Restored  []string `json:"restored"`
+	Unchanged int      `json:"unchanged"`
+}
+
 // UpdatePermissionAppearanceRequest changes the per-resource appearance (icon +
 // color) of every resource:action row of an (app_id, resource). Icon and color
 // are allowlist keys; empty is allowed (a resource may have no icon/color set).
@@ -199,11 +229,37 @@ func (r SetPermissionsRequest) Validate() error {
 	return nil
 }
 
+// SetIncludesRequest replaces the roles a role includes. The role then grants
+// everything the included roles grant, transitively. An empty list clears it.
+type SetIncludesRequest struct {
+	RoleIDs []string `json:"role_ids"`
+}
+
+func (r SetIncludesRequest) Validate() error {
+	seen := map[string]bool{}
+	for _, roleID := range r.RoleIDs {
+		if roleID == "" {
+			return errors.New("role_ids must not contain empty values")
+		}
+		if seen[roleID] {
+			return errors.New("role_ids must not contain duplicates")
+		}
+		seen[roleID] = true
+	}
+	return nil
+}
+
 // AppServiceID is intentionally not a field: the assignment's app_service_id is
 // derived server-side from the role's owning app_id (the perm query enforces
 // ur.app_service_id = rol.app_id), so it must never be client-supplied.
+//
+// StartsAt/ExpiresAt (RFC 3339, optional) make the assignment time-bound. They
+// are written to every listed user, so re-adding an existing member moves its
+// window; omitting both makes the membership permanent.
 type AddMembersRequest struct {
-	UserIDs []string `json:"user_ids"`
+	UserIDs   []string `json:"user_ids"`
+	StartsAt  string   `json:"starts_at,omitempty"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
 }
 
 func (r AddMembersRequest) Validate() error {
@@ -215,7 +271,40 @@ func (r AddMembersRequest) Validate() error {
 			return errors.New("user_ids must not contain empty values")
 		}
 	}
-	return nil
+	_, err := r.Window()
+	return err
+}
+
+// Window parses the optional bounds of the assignment.
+func (r AddMembersRequest) Window() (MemberWindow, error) {
+	window := MemberWindow{}
+	if r.StartsAt != "" {
+		startsAt, err := time.Parse(time.RFC3339, r.StartsAt)
+		if err != nil {
+			return MemberWindow{}, errors.New("starts_at must be an RFC 3339 timestamp")
+		}
+		startsAt = startsAt.UTC()
+		window.StartsAt = &startsAt
+	}
+	if r.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, r.ExpiresAt)
+		if err != nil {
+			return MemberWindow{}, errors.New("expires_at must be an RFC 3339 timestamp")
+		}
+		expiresAt = expiresAt.UTC()
+		window.ExpiresAt = &expiresAt
+	}
+	if window.StartsAt != nil && window.ExpiresAt != nil && !window.ExpiresAt.After(*window.StartsAt) {
+		return MemberWindow{}, errors.New("expires_at must be after starts_at")
+	}
+	return window, nil
+}
+
+// MemberWindow bounds when a role assignment is in force. A nil bound is open:
+// no StartsAt = active from creation, no ExpiresAt = permanent.
+type MemberWindow struct {
+	StartsAt  *time.Time
+	ExpiresAt *time.Time
 }
 
 type ListMembersRequest struct {
@@ -251,36 +340,65 @@ type PermissionItem struct {
 	// Color is the per-resource color palette key shared by all rows of the
 	// same (app_id, resource); empty = neutral fallback in the UI.
 	Color string `json:"color"`
+	// FlaggedAt (RFC 3339) is set when the owning app's catalog sync no longer
+	// lists the permission; an admin confirms the removal by deleting it.
+	FlaggedAt string `json:"flagged_at,omitempty"`
+}
+
+// RoleRef names a role another role points at.
+type RoleRef struct {
+	ID   string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// InheritedPermissionItem is a permission a role grants only through the roles
+// it includes. Via lists the codes of the directly included roles it comes
+// through.
+type InheritedPermissionItem struct {
+	PermissionItem
+	Via []string `json:"via"`
 }
 
+// RoleDetailResponse splits the role's grants: Permissions are assigned to the
+// role itself, InheritedPermissions come only from Includes.
 type RoleDetailResponse struct {
-	ID          string           `json:"id"`
-	AppID       string           `json:"app_id"`
-	AppCode     string           `json:"app_code"`
-	Code        string           `json:"code"`
-	Name        string           `json:"name"`
-	Description string           `json:"description"`
-	Icon        string           `json:"icon"`
-	Color       string           `json:"color"`
-	IsSystem    bool             `json:"is_system"`
-	Permissions []PermissionItem `json:"permissions"`
+	ID                   string                    `json:"id"`
+	AppID                string                    `json:"app_id"`
+	AppCode              string                    `json:"app_code"`
+	Code                 string                    `json:"code"`
+	Name                 string                    `json:"name"`
+	Description          string                    `json:"description"`
+	Icon                 string                    `json:"icon"`
+	Color                string                    `json:"color"`
+	IsSystem             bool                      `json:"is_system"`
+	Permissions          []PermissionItem          `json:"permissions"`
+	Includes             []RoleRef                 `json:"includes"`
+	InheritedPermissions []InheritedPermissionItem `json:"inherited_permissions"`
 }
 
 // UserAppRole is one app-scoped role a user holds, used by the user list to
-// render app:role chips. It carries both codes and display names.
+// render app:role chips. It carries both codes and display names. Groups names
+// the groups the role is inherited through; the user may hold it directly too.
 type UserAppRole struct {
-	AppCode  string `json:"app_code"`
-	AppName  string `json:"app_name"`
-	RoleCode string `json:"role_code"`
-	RoleName string `json:"role_name"`
+	AppCode  string   `json:"app_code"`
+	AppName  string   `json:"app_name"`
+	RoleCode string   `json:"role_code"`
+	RoleName string   `json:"role_name"`
+	Groups   []string `json:"groups,omitempty"`
 }
 
+// MemberItem lists every assignment of the role, including ones not yet
+// started and expired ones the role-member-expiry job has not removed yet.
+// StartsAt/ExpiresAt (RFC 3339) are empty for an open bound.
 type MemberItem struct {
 	UserID       string  `json:"user_id"`
 	Name         string  `json:"name"`
 	Email        string  `json:"email"`
 	AppServiceID *string `json:"app_service_id"`
 	CreatedAt    string  `json:"created_at"`
+	StartsAt     string  `json:"starts_at,omitempty"`
+	ExpiresAt    string  `json:"expires_at,omitempty"`
 }
 
 type ListMembersResponse struct {
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/vukyn/isme/internal/domains/role/entity"
 	"github.com/vukyn/isme/internal/domains/role/models"
@@ -24,6 +25,9 @@ type IRepository interface {
 	ListPermissions(ctx context.Context, req models.ListPermissionsRequest) ([]entity.Permission, error)
 	// Create permissions for an app (idempotent); returns the resulting permission IDs keyed by code
 	CreatePermissions(ctx context.Context, appID string, perms []models.PermissionItem) (map[string]int64, error)
+	// Flag permissions an app's catalog sync no longer lists; a zero flaggedAt
+	// clears the flag
+	FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error
 	// Get a single permission by ID
 	GetPermissionByID(ctx context.Context, permissionID int64) (entity.Permission, error)
 	// Delete a permission from the catalog; first clears any role_permissions
@@ -32,31 +36,53 @@ type IRepository interface {
 	// Update the per-resource appearance (icon + color) for every row of an
 	// (app_id, resource) in one statement
 	UpdatePermissionAppearance(ctx context.Context, appID string, resource string, icon string, color string) error
-	// Get permissions assigned to a role
+	// Get a role's effective permissions: its own plus those of the roles it
+	// includes, transitively
 	GetPermissionsByRoleID(ctx context.Context, roleID string) ([]entity.Permission, error)
-	// Get the resource:action permission codes granted by each role, keyed by
-	// role_id (batched to avoid an N+1 over a set of roles)
+	// Get the permissions assigned to a role itself, ignoring included roles
+	GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]entity.Permission, error)
+	// Get the resource:action permission codes each role grants effectively,
+	// keyed by role_id (batched to avoid an N+1 over a set of roles)
 	GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error)
+	// Get the resource:action permission codes each role grants itself, keyed
+	// by role_id
+	GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error)
+	// Get the live roles each role includes directly, keyed by role_id
+	GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]entity.Role, error)
+	// Expand roles to themselves plus every live role they include, transitively
+	ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error)
+	// Replace the roles a role includes (delete-then-insert in a transaction),
+	// serializing include changes within the role's app
+	ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error
 	// Replace all permissions of a role (delete-then-insert in a transaction)
 	ReplaceRolePermissions(ctx context.Context, roleID string, permissionIDs []int64) error
 	// List role members with pagination and optional name/email search
 	ListMembers(ctx context.Context, roleID string, req models.ListMembersRequest) ([]models.MemberItem, int, error)
 	// Count members assigned to a role
 	CountMembersByRoleID(ctx context.Context, roleID string) (int, error)
-	// Add members to a role; nil appServiceID means a global assignment
-	AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string) error
+	// Add members to a role; nil appServiceID means a global assignment. The
+	// window is written to every listed user, including existing members
+	AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window models.MemberWindow) error
+	// Delete the assignments expired at now and return the removed rows
+	DeleteExpiredMembers(ctx context.Context, now time.Time) ([]entity.UserRole, error)
+	// Report whether the user has an assignment of the role, whatever its
+	// window; nil appServiceID targets the global assignment
+	HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error)
 	// Remove a member from a role; nil appServiceID targets the global assignment
 	RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error
-	// Get permission codes for a user scoped to a concrete app_id (matched against
-	// the owning role's app_id); empty appID resolves assignments across all apps
+	// Get effective permission codes for a user's active assignments scoped to
+	// a concrete app_id (matched against the owning role's app_id); empty appID
+	// resolves assignments across all apps
 	GetPermissionCodesByUserID(ctx context.Context, userID string, appID string) ([]string, error)
-	// Get permission codes for a user grouped by owning app_code (feeds resource_access)
+	// Get effective permission codes for a user's active assignments grouped by
+	// owning app_code (feeds resource_access)
 	GetPermissionCodesGroupedByApp(ctx context.Context, userID string) (map[string][]string, error)
-	// Get the app_codes a user holds any role in (feeds the token audience)
+	// Get the app_codes a user actively holds any role in (feeds the token audience)
 	GetAppCodesByUserID(ctx context.Context, userID string) ([]string, error)
-	// Get role codes for a user; empty appServiceID resolves global assignments only
+	// Get role codes for a user's active assignments; empty appServiceID resolves
+	// global assignments only
 	GetRoleCodesByUserID(ctx context.Context, userID string, appServiceID string) ([]string, error)
-	// Get every user's full set of app-scoped roles, keyed by user_id (batched
+	// Get every user's active app-scoped roles, keyed by user_id (batched
 	// for the user list — each entry carries app + role codes and display names)
 	GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, userIDs []string) (map[string][]models.UserAppRole, error)
 }
@@ -1,19 +1,25 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/vukyn/isme/internal/domains/role/entity"
 	"github.com/vukyn/isme/internal/domains/role/models"
+	"github.com/vukyn/isme/internal/tracing"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgBunQuery "github.com/vukyn/kuery/bun/query"
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
 
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
 	"github.com/vukyn/kuery/cryp"
 )
 
@@ -42,7 +48,7 @@ func (r *repository) Create(ctx context.Context, req models.CreateRequest) (stri
 		Color:       req.Color,
 		CreatedBy:   pkgCtx.GetUserID(ctx),
 	}
-	_, err := r.db.NewInsert().
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
 		Model(role).
 		Exec(ctx)
 	if err != nil {
@@ -57,7 +63,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (entity.Role, error
 	}
 
 	role := entity.Role{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&role).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -79,7 +85,7 @@ func (r *repository) GetByAppAndCode(ctx context.Context, appID string, code str
 	}
 
 	role := entity.Role{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&role).
 		Where("app_id = ?", appID).
 		Where("code = ?", code).
@@ -101,7 +107,7 @@ func (r *repository) List(ctx context.Context, req models.ListRequest) ([]models
 	}
 
 	rows := []roleListRow{}
-	query := r.db.NewSelect().
+	query := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&rows).
 		ColumnExpr("rol.*").
 		ColumnExpr("app.app_code AS app_code").
@@ -152,7 +158,7 @@ func (r *repository) Update(ctx context.Context, id string, req models.UpdateReq
 		Color:       req.Color,
 		UpdatedBy:   pkgCtx.GetUserID(ctx),
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(role).
 		Column("name", "description", "icon", "color", "updated_by").
 		Where("id = ?", id).
@@ -173,7 +179,7 @@ func (r *repository) SoftDelete(ctx context.Context, id string) error {
 		DeletedAt: time.Now().UTC(),
 		DeletedBy: pkgCtx.GetUserID(ctx),
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(role).
 		Column("deleted_at", "deleted_by").
 		Where("id = ?", id).
@@ -186,7 +192,7 @@ func (r *repository) SoftDelete(ctx context.Context, id string) error {
 
 func (r *repository) ListPermissions(ctx context.Context, req models.ListPermissionsRequest) ([]entity.Permission, error) {
 	permissions := []entity.Permission{}
-	query := r.db.NewSelect().
+	query := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&permissions).
 		Order("perm.id ASC")
 	if req.AppID != "" {
@@ -197,6 +203,9 @@ func (r *repository) ListPermissions(ctx context.Context, req models.ListPermiss
 			Join("JOIN app_services AS app ON app.id = perm.app_id").
 			Where("app.app_code = ?", req.AppCode)
 	}
+	if req.Flagged {
+		query = query.Where("perm.flagged_at IS NOT NULL")
+	}
 	if err := query.Scan(ctx); err != nil {
 		return nil, pkgErr.DatabaseError(err.Error())
 	}
@@ -245,7 +254,7 @@ func (r *repository) CreatePermissions(ctx context.Context, appID string, perms
 
 	permissionIDs := map[string]int64{}
 	for _, perm := range perms {
-		if _, err := r.db.NewInsert().
+		if _, err := transaction.Conn(ctx, r.db).NewInsert().
 			Model(&entity.Permission{
 				AppID:    appID,
 				Resource: perm.Resource,
@@ -259,7 +268,7 @@ func (r *repository) CreatePermissions(ctx context.Context, appID string, perms
 		}
 
 		var permissionID int64
-		err := r.db.NewSelect().
+		err := transaction.Conn(ctx, r.db).NewSelect().
 			Model((*entity.Permission)(nil)).
 			Column("id").
 			Where("app_id = ?", appID).
@@ -278,7 +287,7 @@ func (r *repository) CreatePermissions(ctx context.Context, appID string, perms
 // given (app_id, resource), or "" when the resource has no rows yet.
 func (r *repository) getResourceIcon(ctx context.Context, appID string, resource string) (string, error) {
 	var icon string
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.Permission)(nil)).
 		Column("icon").
 		Where("app_id = ?", appID).
@@ -300,7 +309,7 @@ func (r *repository) getResourceIcon(ctx context.Context, appID string, resource
 // getResourceIcon — the per-resource color is read from the resource's first row.
 func (r *repository) getResourceColor(ctx context.Context, appID string, resource string) (string, error) {
 	var color string
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.Permission)(nil)).
 		Column("color").
 		Where("app_id = ?", appID).
@@ -317,13 +326,36 @@ func (r *repository) getResourceColor(ctx context.Context, appID string, resourc
 	return color, nil
 }
 
+// FlagPermissions sets flagged_at on the given permissions, or clears it when
+// flaggedAt is zero. Grants are untouched: a flagged permission keeps working
+// until an admin deletes it.
+func (r *repository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+
+	var value any
+	if !flaggedAt.IsZero() {
+		value = flaggedAt
+	}
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
+		Model((*entity.Permission)(nil)).
+		Set("flagged_at = ?", value).
+		Where("id IN (?)", bun.In(permissionIDs)).
+		Exec(ctx)
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
 func (r *repository) GetPermissionByID(ctx context.Context, permissionID int64) (entity.Permission, error) {
 	if permissionID == 0 {
 		return entity.Permission{}, pkgErr.InvalidRequest("permission_id is required")
 	}
 
 	permission := entity.Permission{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&permission).
 		Where("id = ?", permissionID).
 		Scan(ctx)
@@ -344,7 +376,7 @@ func (r *repository) DeletePermission(ctx context.Context, permissionID int64) e
 		return pkgErr.InvalidRequest("permission_id is required")
 	}
 
-	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
 		// clear grants referencing this permission
 		_, err := tx.NewDelete().
 			Model((*entity.RolePermission)(nil)).
@@ -380,7 +412,7 @@ func (r *repository) UpdatePermissionAppearance(ctx context.Context, appID strin
 		return pkgErr.InvalidRequest("resource is required")
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model((*entity.Permission)(nil)).
 		Set("icon = ?", icon).
 		Set("color = ?", color).
@@ -393,13 +425,53 @@ func (r *repository) UpdatePermissionAppearance(ctx context.Context, appID strin
 	return nil
 }
 
+// roleClosure is the body of the recursive role_closure(root_id, role_id) CTE:
+// every root role paired with itself and with each live role it includes,
+// directly or transitively. roots is anything an IN (?) accepts — a single id,
+// bun.In(ids) or a subquery. UNION (not UNION ALL) drops repeated pairs, which
+// also ends the recursion should the include graph ever contain a cycle.
+func roleClosure(conn bun.IDB, roots any) *bun.RawQuery {
+	return conn.NewRaw(`
+		SELECT rol.id AS root_id, rol.id AS role_id
+		FROM roles AS rol
+		WHERE rol.id IN (?)
+		UNION
+		SELECT rc.root_id, ri.included_role_id
+		FROM role_closure AS rc
+		JOIN role_includes AS ri ON ri.role_id = rc.role_id
+		JOIN roles AS inc ON inc.id = ri.included_role_id AND inc.deleted_at IS NULL`,
+		roots,
+	)
+}
+
+// GetPermissionsByRoleID returns the role's effective permissions: its own
+// grants plus those of every role it includes, directly or transitively.
 func (r *repository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]entity.Permission, error) {
 	if roleID == "" {
 		return nil, pkgErr.InvalidRequest("role_id is required")
 	}
 
+	conn := transaction.Conn(ctx, r.db)
+	permissions := []entity.Permission{}
+	err := conn.NewSelect().
+		WithRecursive("role_closure", roleClosure(conn, roleID)).
+		Model(&permissions).
+		Where("perm.id IN (SELECT rp.permission_id FROM role_permissions AS rp JOIN role_closure AS rc ON rc.role_id = rp.role_id)").
+		Order("perm.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+	return permissions, nil
+}
+
+func (r *repository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]entity.Permission, error) {
+	if roleID == "" {
+		return nil, pkgErr.InvalidRequest("role_id is required")
+	}
+
 	permissions := []entity.Permission{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&permissions).
 		Join("JOIN role_permissions AS rp ON rp.permission_id = perm.id").
 		Where("rp.role_id = ?", roleID).
@@ -412,21 +484,59 @@ func (r *repository) GetPermissionsByRoleID(ctx context.Context, roleID string)
 }
 
 // GetPermissionCodesByRoleIDs returns the resource:action permission codes
-// granted by each role, keyed by role_id. Used pre-auth to preview what an
-// invited role grants — scoped strictly to the given role ids.
+// each role grants effectively (its own plus those of its included roles),
+// keyed by role_id. Used pre-auth to preview what an invited role grants —
+// scoped strictly to the given role ids and what they include.
 func (r *repository) GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
 	codesByRole := map[string][]string{}
 	if len(roleIDs) == 0 {
 		return codesByRole, nil
 	}
 
+	type roleCodeRow struct {
+		RoleID       string `bun:"role_id"`
+		PermissionID int64  `bun:"permission_id"`
+		Code         string `bun:"code"`
+	}
+
+	conn := transaction.Conn(ctx, r.db)
+	rows := []roleCodeRow{}
+	err := conn.NewSelect().
+		WithRecursive("role_closure", roleClosure(conn, bun.In(roleIDs))).
+		TableExpr("role_closure AS rc").
+		// a permission reached through two included roles is listed once
+		ColumnExpr("DISTINCT rc.root_id AS role_id").
+		ColumnExpr("perm.id AS permission_id").
+		ColumnExpr("perm.resource || ':' || perm.action AS code").
+		Join("JOIN role_permissions AS rp ON rp.role_id = rc.role_id").
+		Join("JOIN permissions AS perm ON perm.id = rp.permission_id").
+		Order("perm.id ASC").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+
+	for _, row := range rows {
+		codesByRole[row.RoleID] = append(codesByRole[row.RoleID], row.Code)
+	}
+	return codesByRole, nil
+}
+
+// GetDirectPermissionCodesByRoleIDs returns the resource:action permission
+// codes each role grants itself, ignoring included roles, keyed by role_id.
+func (r *repository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	codesByRole := map[string][]string{}
+	if len(roleIDs) == 0 {
+		return codesByRole, nil
+	}
+
 	type roleCodeRow struct {
 		RoleID string `bun:"role_id"`
 		Code   string `bun:"code"`
 	}
 
 	rows := []roleCodeRow{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		TableExpr("role_permissions AS rp").
 		ColumnExpr("rp.role_id").
 		ColumnExpr("perm.resource || ':' || perm.action AS code").
@@ -444,32 +554,148 @@ func (r *repository) GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []
 	return codesByRole, nil
 }
 
-// GetPermissionCodesGroupedByApp returns the user's permission codes grouped by the
-// owning app's app_code. The owning role's app_id is authoritative and the
-// assignment scope (user_roles.app_service_id) must match it.
+// GetIncludedRoles returns the live roles each role includes directly, keyed
+// by role_id.
+func (r *repository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]entity.Role, error) {
+	includedByRole := map[string][]entity.Role{}
+	if len(roleIDs) == 0 {
+		return includedByRole, nil
+	}
+
+	type includedRow struct {
+		entity.Role `bun:",extend"`
+		IncluderID  string `bun:"includer_id,scanonly"`
+	}
+
+	rows := []includedRow{}
+	err := transaction.Conn(ctx, r.db).NewSelect().
+		Model(&rows).
+		ColumnExpr("rol.*").
+		ColumnExpr("ri.role_id AS includer_id").
+		Join("JOIN role_includes AS ri ON ri.included_role_id = rol.id").
+		Where("ri.role_id IN (?)", bun.In(roleIDs)).
+		Order("rol.code ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+
+	for _, row := range rows {
+		includedByRole[row.IncluderID] = append(includedByRole[row.IncluderID], row.Role)
+	}
+	return includedByRole, nil
+}
+
+// ExpandRoleIDs returns the given roles plus every live role they include,
+// directly or transitively.
+func (r *repository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	if len(roleIDs) == 0 {
+		return []string{}, nil
+	}
+
+	conn := transaction.Conn(ctx, r.db)
+	expanded := []string{}
+	err := conn.NewSelect().
+		WithRecursive("role_closure", roleClosure(conn, bun.In(roleIDs))).
+		TableExpr("role_closure AS rc").
+		ColumnExpr("DISTINCT rc.role_id").
+		Scan(ctx, &expanded)
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+	return expanded, nil
+}
+
+// ReplaceRoleIncludes replaces the roles a role includes (delete-then-insert in
+// a transaction, like ReplaceRolePermissions). Cycle and same-app checks are
+// the caller's, made after this in the same transaction: on Postgres the app's
+// roles are locked first, so concurrent include changes within an app run one
+// after the other and each check sees the edges the others committed (SQLite
+// serializes writers on its own).
+func (r *repository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	if roleID == "" {
+		return pkgErr.InvalidRequest("role_id is required")
+	}
+
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if tx.Dialect().Name() == dialect.PG {
+			_, err := tx.NewSelect().
+				Model((*entity.Role)(nil)).
+				Column("id").
+				Where("app_id = (SELECT app_id FROM roles WHERE id = ?)", roleID).
+				For("UPDATE").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.NewDelete().
+			Model((*entity.RoleInclude)(nil)).
+			Where("role_id = ?", roleID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(includedRoleIDs) == 0 {
+			return nil
+		}
+
+		createdBy := pkgCtx.GetUserID(ctx)
+		roleIncludes := make([]entity.RoleInclude, 0, len(includedRoleIDs))
+		for _, includedRoleID := range includedRoleIDs {
+			roleIncludes = append(roleIncludes, entity.RoleInclude{
+				RoleID:         roleID,
+				IncludedRoleID: includedRoleID,
+				CreatedBy:      createdBy,
+			})
+		}
+		_, err = tx.NewInsert().
+			Model(&roleIncludes).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return pkgErr.DatabaseError(err.Error())
+	}
+	return nil
+}
+
+// GetPermissionCodesGroupedByApp returns the user's effective permission codes
+// grouped by the owning app's app_code: the grants of every role assigned to
+// them or to one of their groups, and of the roles it includes. The owning
+// role's app_id is authoritative and the assignment scope
+// (user_roles.app_service_id) must match it.
 func (r *repository) GetPermissionCodesGroupedByApp(ctx context.Context, userID string) (map[string][]string, error) {
 	if userID == "" {
 		return nil, pkgErr.InvalidRequest("user_id is required")
 	}
 
+	// runs on every token issue; its own span separates the join from the
+	// surrounding token work
+	ctx, span := tracing.Start(ctx, "role.GetPermissionCodesGroupedByApp")
+	defer span.End()
+
 	type groupedRow struct {
 		AppCode string `bun:"app_code"`
 		Code    string `bun:"code"`
 	}
 
+	conn := transaction.Conn(ctx, r.db)
 	rows := []groupedRow{}
-	err := r.db.NewSelect().
-		TableExpr("user_roles AS ur").
+	err := conn.NewSelect().
+		WithRecursive("role_closure", roleClosure(conn, r.assignedRoleIDs(conn, userID, ""))).
+		TableExpr("role_closure AS rc").
 		ColumnExpr("DISTINCT app.app_code AS app_code").
 		ColumnExpr("perm.resource || ':' || perm.action AS code").
-		Join("JOIN roles AS rol ON rol.id = ur.role_id AND rol.deleted_at IS NULL").
+		Join("JOIN roles AS rol ON rol.id = rc.root_id").
 		Join("JOIN app_services AS app ON app.id = rol.app_id").
-		Join("JOIN role_permissions AS rp ON rp.role_id = ur.role_id").
+		Join("JOIN role_permissions AS rp ON rp.role_id = rc.role_id").
 		Join("JOIN permissions AS perm ON perm.id = rp.permission_id").
-		Where("ur.user_id = ?", userID).
-		Where("ur.app_service_id = rol.app_id").
 		Scan(ctx, &rows)
 	if err != nil {
+		span.RecordError(err)
 		return nil, pkgErr.DatabaseError(err.Error())
 	}
 
@@ -477,23 +703,24 @@ func (r *repository) GetPermissionCodesGroupedByApp(ctx context.Context, userID
 	for _, row := range rows {
 		grouped[row.AppCode] = append(grouped[row.AppCode], row.Code)
 	}
+	span.SetAttributes(tracing.Int("role.app_count", len(grouped)), tracing.Int("role.permission_count", len(rows)))
 	return grouped, nil
 }
 
-// GetAppCodesByUserID returns the distinct app_codes the user holds any role in.
+// GetAppCodesByUserID returns the distinct app_codes the user holds any role
+// in, directly or through a group.
 func (r *repository) GetAppCodesByUserID(ctx context.Context, userID string) ([]string, error) {
 	if userID == "" {
 		return nil, pkgErr.InvalidRequest("user_id is required")
 	}
 
+	conn := transaction.Conn(ctx, r.db)
 	appCodes := []string{}
-	err := r.db.NewSelect().
-		TableExpr("user_roles AS ur").
+	err := conn.NewSelect().
+		TableExpr("roles AS rol").
 		ColumnExpr("DISTINCT app.app_code").
-		Join("JOIN roles AS rol ON rol.id = ur.role_id AND rol.deleted_at IS NULL").
 		Join("JOIN app_services AS app ON app.id = rol.app_id").
-		Where("ur.user_id = ?", userID).
-		Where("ur.app_service_id = rol.app_id").
+		Where("rol.id IN (?)", r.assignedRoleIDs(conn, userID, "")).
 		Scan(ctx, &appCodes)
 	if err != nil {
 		return nil, pkgErr.DatabaseError(err.Error())
@@ -506,7 +733,7 @@ func (r *repository) ReplaceRolePermissions(ctx context.Context, roleID string,
 		return pkgErr.InvalidRequest("role_id is required")
 	}
 
-	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
 		_, err := tx.NewDelete().
 			Model((*entity.RolePermission)(nil)).
 			Where("role_id = ?", roleID).
@@ -543,15 +770,17 @@ func (r *repository) ListMembers(ctx context.Context, roleID string, req models.
 	}
 
 	type memberRow struct {
-		UserID       string    `bun:"user_id"`
-		Name         string    `bun:"name"`
-		Email        string    `bun:"email"`
-		AppServiceID *string   `bun:"app_service_id"`
-		CreatedAt    time.Time `bun:"created_at"`
+		UserID       string     `bun:"user_id"`
+		Name         string     `bun:"name"`
+		Email        string     `bun:"email"`
+		AppServiceID *string    `bun:"app_service_id"`
+		CreatedAt    time.Time  `bun:"created_at"`
+		StartsAt     *time.Time `bun:"starts_at"`
+		ExpiresAt    *time.Time `bun:"expires_at"`
 	}
 
 	buildQuery := func() *bun.SelectQuery {
-		query := r.db.NewSelect().
+		query := transaction.Conn(ctx, r.db).NewSelect().
 			TableExpr("user_roles AS ur").
 			Join("JOIN users AS usr ON usr.id = ur.user_id AND usr.deleted_at IS NULL").
 			Where("ur.role_id = ?", roleID)
@@ -577,7 +806,9 @@ func (r *repository) ListMembers(ctx context.Context, roleID string, req models.
 		ColumnExpr("usr.name").
 		ColumnExpr("usr.email").
 		ColumnExpr("ur.app_service_id").
-		ColumnExpr("ur.created_at")
+		ColumnExpr("ur.created_at").
+		ColumnExpr("ur.starts_at").
+		ColumnExpr("ur.expires_at")
 	query = pkgBunQuery.SelectWithPagination(query, req.Pagination, "ur.created_at DESC")
 	if err := query.Scan(ctx, &rows); err != nil {
 		return nil, 0, pkgErr.DatabaseError(err.Error())
@@ -585,13 +816,20 @@ func (r *repository) ListMembers(ctx context.Context, roleID string, req models.
 
 	items := make([]models.MemberItem, 0, len(rows))
 	for _, row := range rows {
-		items = append(items, models.MemberItem{
+		item := models.MemberItem{
 			UserID:       row.UserID,
 			Name:         row.Name,
 			Email:        row.Email,
 			AppServiceID: row.AppServiceID,
 			CreatedAt:    row.CreatedAt.Format(time.RFC3339),
-		})
+		}
+		if row.StartsAt != nil {
+			item.StartsAt = row.StartsAt.UTC().Format(time.RFC3339)
+		}
+		if row.ExpiresAt != nil {
+			item.ExpiresAt = row.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		items = append(items, item)
 	}
 	return items, total, nil
 }
@@ -601,7 +839,7 @@ func (r *repository) CountMembersByRoleID(ctx context.Context, roleID string) (i
 		return 0, pkgErr.InvalidRequest("role_id is required")
 	}
 
-	count, err := r.db.NewSelect().
+	count, err := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.UserRole)(nil)).
 		Where("role_id = ?", roleID).
 		Count(ctx)
@@ -611,7 +849,7 @@ func (r *repository) CountMembersByRoleID(ctx context.Context, roleID string) (i
 	return count, nil
 }
 
-func (r *repository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string) error {
+func (r *repository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window models.MemberWindow) error {
 	if roleID == "" {
 		return pkgErr.InvalidRequest("role_id is required")
 	}
@@ -628,19 +866,99 @@ func (r *repository) AddMembers(ctx context.Context, roleID string, userIDs []st
 			RoleID:       roleID,
 			AppServiceID: appServiceID,
 			CreatedBy:    createdBy,
+			StartsAt:     window.StartsAt,
+			ExpiresAt:    window.ExpiresAt,
 		})
 	}
 
-	_, err := r.db.NewInsert().
-		Model(&userRoles).
-		Ignore().
-		Exec(ctx)
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(&userRoles).
+			Ignore().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		// the insert skips users who already hold the role; move their window
+		// too, so re-adding extends, shortens or clears an expiry
+		query := tx.NewUpdate().
+			Model((*entity.UserRole)(nil)).
+			Set("starts_at = ?", window.StartsAt).
+			Set("expires_at = ?", window.ExpiresAt).
+			Where("role_id = ?", roleID).
+			Where("user_id IN (?)", bun.In(userIDs))
+		if appServiceID == nil {
+			query = query.Where("app_service_id IS NULL")
+		} else {
+			query = query.Where("app_service_id = ?", *appServiceID)
+		}
+		_, err = query.Exec(ctx)
+		return err
+	})
 	if err != nil {
 		return pkgErr.DatabaseError(err.Error())
 	}
 	return nil
 }
 
+// DeleteExpiredMembers removes the assignments whose expires_at is at or before
+// now and returns the removed rows, so the caller can audit each one and revoke
+// the sessions still carrying its permissions.
+func (r *repository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]entity.UserRole, error) {
+	expired := []entity.UserRole{}
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewSelect().
+			Model(&expired).
+			Where("ur.expires_at IS NOT NULL").
+			Where("ur.expires_at <= ?", now).
+			Order("ur.expires_at ASC").
+			Scan(ctx)
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(expired))
+		for _, userRole := range expired {
+			ids = append(ids, userRole.ID)
+		}
+		_, err = tx.NewDelete().
+			Model((*entity.UserRole)(nil)).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+	return expired, nil
+}
+
+func (r *repository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	if roleID == "" {
+		return false, pkgErr.InvalidRequest("role_id is required")
+	}
+	if userID == "" {
+		return false, pkgErr.InvalidRequest("user_id is required")
+	}
+
+	query := transaction.Conn(ctx, r.db).NewSelect().
+		Model((*entity.UserRole)(nil)).
+		Where("role_id = ?", roleID).
+		Where("user_id = ?", userID)
+	if appServiceID == nil {
+		query = query.Where("app_service_id IS NULL")
+	} else {
+		query = query.Where("app_service_id = ?", *appServiceID)
+	}
+
+	exists, err := query.Exists(ctx)
+	if err != nil {
+		return false, pkgErr.DatabaseError(err.Error())
+	}
+	return exists, nil
+}
+
 func (r *repository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
 	if roleID == "" {
 		return pkgErr.InvalidRequest("role_id is required")
@@ -649,7 +967,7 @@ func (r *repository) RemoveMember(ctx context.Context, roleID string, userID str
 		return pkgErr.InvalidRequest("user_id is required")
 	}
 
-	query := r.db.NewDelete().
+	query := transaction.Conn(ctx, r.db).NewDelete().
 		Model((*entity.UserRole)(nil)).
 		Where("role_id = ?", roleID).
 		Where("user_id = ?", userID)
@@ -666,57 +984,100 @@ func (r *repository) RemoveMember(ctx context.Context, roleID string, userID str
 	return nil
 }
 
-func (r *repository) GetPermissionCodesByUserID(ctx context.Context, userID string, appID string) ([]string, error) {
-	if userID == "" {
-		return nil, pkgErr.InvalidRequest("user_id is required")
-	}
-
-	query := r.db.NewSelect().
+// assignedRoleIDs selects the ids of the live roles the user holds, the roots
+// of a role_closure: those actively assigned to them, whose assignment scope
+// must match the owning role's app, and those of the live groups they belong
+// to. A non-empty appID narrows the roles to that app.
+func (r *repository) assignedRoleIDs(conn bun.IDB, userID string, appID string) *bun.SelectQuery {
+	direct := conn.NewSelect().
 		TableExpr("user_roles AS ur").
-		ColumnExpr("DISTINCT perm.resource || ':' || perm.action").
-		Join("JOIN role_permissions AS rp ON rp.role_id = ur.role_id").
-		Join("JOIN permissions AS perm ON perm.id = rp.permission_id").
-		Join("JOIN roles AS rol ON rol.id = ur.role_id AND rol.deleted_at IS NULL").
+		ColumnExpr("ur.role_id").
+		Join("JOIN roles AS own ON own.id = ur.role_id").
 		Where("ur.user_id = ?", userID).
-		// the assignment scope must match the owning role's app
-		Where("ur.app_service_id = rol.app_id")
+		Where("ur.app_service_id = own.app_id")
+
+	// two IN predicates rather than a UNION: bun parenthesizes each side of a
+	// compound select, which SQLite rejects
+	query := conn.NewSelect().
+		TableExpr("roles AS rol").
+		ColumnExpr("rol.id").
+		Where("rol.deleted_at IS NULL").
+		Where("(rol.id IN (?) OR rol.id IN (?))", activeAssignment(direct, time.Now().UTC()), groupRoleIDs(conn, userID))
 	if appID != "" {
 		query = query.Where("rol.app_id = ?", appID)
 	}
+	return query
+}
+
+// groupRoleIDs selects the ids of the roles granted to the live groups the user
+// belongs to. Group grants carry no window: they hold while the user stays a
+// member. Callers filter out deleted roles.
+func groupRoleIDs(conn bun.IDB, userID string) *bun.SelectQuery {
+	return conn.NewSelect().
+		TableExpr("user_group_members AS ugm").
+		ColumnExpr("ugr.role_id").
+		Join("JOIN user_groups AS ugp ON ugp.id = ugm.group_id AND ugp.deleted_at IS NULL").
+		Join("JOIN user_group_roles AS ugr ON ugr.group_id = ugm.group_id").
+		Where("ugm.user_id = ?", userID)
+}
 
+// activeAssignment narrows a query over user_roles (aliased ur) to the
+// assignments in force at now: already started and not yet expired. Expired
+// rows linger until the role-member-expiry job removes them, so every read
+// that grants or reports a held role must apply it.
+func activeAssignment(query *bun.SelectQuery, now time.Time) *bun.SelectQuery {
+	return query.
+		Where("(ur.starts_at IS NULL OR ur.starts_at <= ?)", now).
+		Where("(ur.expires_at IS NULL OR ur.expires_at > ?)", now)
+}
+
+// GetPermissionCodesByUserID returns the user's effective permission codes:
+// the grants of every assigned role and of the roles it includes.
+func (r *repository) GetPermissionCodesByUserID(ctx context.Context, userID string, appID string) ([]string, error) {
+	if userID == "" {
+		return nil, pkgErr.InvalidRequest("user_id is required")
+	}
+
+	conn := transaction.Conn(ctx, r.db)
 	codes := []string{}
-	if err := query.Scan(ctx, &codes); err != nil {
+	err := conn.NewSelect().
+		WithRecursive("role_closure", roleClosure(conn, r.assignedRoleIDs(conn, userID, appID))).
+		TableExpr("role_closure AS rc").
+		ColumnExpr("DISTINCT perm.resource || ':' || perm.action").
+		Join("JOIN role_permissions AS rp ON rp.role_id = rc.role_id").
+		Join("JOIN permissions AS perm ON perm.id = rp.permission_id").
+		Scan(ctx, &codes)
+	if err != nil {
 		return nil, pkgErr.DatabaseError(err.Error())
 	}
 	return codes, nil
 }
 
+// GetRoleCodesByUserID returns the codes of the roles the user holds, directly
+// or through a group, without the roles those include.
 func (r *repository) GetRoleCodesByUserID(ctx context.Context, userID string, appServiceID string) ([]string, error) {
 	if userID == "" {
 		return nil, pkgErr.InvalidRequest("user_id is required")
 	}
 
-	query := r.db.NewSelect().
-		TableExpr("user_roles AS ur").
-		ColumnExpr("DISTINCT rol.code").
-		Join("JOIN roles AS rol ON rol.id = ur.role_id AND rol.deleted_at IS NULL").
-		Where("ur.user_id = ?", userID).
-		Where("ur.app_service_id = rol.app_id")
-	if appServiceID != "" {
-		query = query.Where("rol.app_id = ?", appServiceID)
-	}
-
+	conn := transaction.Conn(ctx, r.db)
 	codes := []string{}
-	if err := query.Scan(ctx, &codes); err != nil {
+	err := conn.NewSelect().
+		TableExpr("roles AS rol").
+		ColumnExpr("DISTINCT rol.code").
+		Where("rol.id IN (?)", r.assignedRoleIDs(conn, userID, appServiceID)).
+		Scan(ctx, &codes)
+	if err != nil {
 		return nil, pkgErr.DatabaseError(err.Error())
 	}
 	return codes, nil
 }
 
 // GetRoleCodesGroupedByAppByUserIDs returns every app-scoped role each user
-// holds, keyed by user_id. The owning role's app_id is authoritative and the
-// assignment scope (user_roles.app_service_id) must match it. Batched over the
-// whole page to avoid an N+1.
+// holds, keyed by user_id: their active direct assignments, whose scope
+// (user_roles.app_service_id) must match the owning role's app_id, and the roles
+// of the live groups they belong to. A role held both ways appears once, with
+// the granting groups listed. Batched over the whole page to avoid an N+1.
 func (r *repository) GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, userIDs []string) (map[string][]models.UserAppRole, error) {
 	rolesByUser := map[string][]models.UserAppRole{}
 	if len(userIDs) == 0 {
@@ -724,15 +1085,17 @@ func (r *repository) GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, user
 	}
 
 	type rolesRow struct {
-		UserID   string `bun:"user_id"`
-		AppCode  string `bun:"app_code"`
-		AppName  string `bun:"app_name"`
-		RoleCode string `bun:"role_code"`
-		RoleName string `bun:"role_name"`
+		UserID    string `bun:"user_id"`
+		AppCode   string `bun:"app_code"`
+		AppName   string `bun:"app_name"`
+		RoleCode  string `bun:"role_code"`
+		RoleName  string `bun:"role_name"`
+		GroupName string `bun:"group_name"`
 	}
 
-	rows := []rolesRow{}
-	err := r.db.NewSelect().
+	conn := transaction.Conn(ctx, r.db)
+	directRows := []rolesRow{}
+	query := conn.NewSelect().
 		TableExpr("user_roles AS ur").
 		ColumnExpr("ur.user_id").
 		ColumnExpr("app.app_code AS app_code").
@@ -742,20 +1105,56 @@ func (r *repository) GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, user
 		Join("JOIN roles AS rol ON rol.id = ur.role_id AND rol.deleted_at IS NULL").
 		Join("JOIN app_services AS app ON app.id = rol.app_id").
 		Where("ur.user_id IN (?)", bun.In(userIDs)).
-		Where("ur.app_service_id = rol.app_id").
-		Order("app.app_code ASC").
-		Order("rol.code ASC").
-		Scan(ctx, &rows)
+		Where("ur.app_service_id = rol.app_id")
+	if err := activeAssignment(query, time.Now().UTC()).Scan(ctx, &directRows); err != nil {
+		return nil, pkgErr.DatabaseError(err.Error())
+	}
+
+	groupRows := []rolesRow{}
+	err := conn.NewSelect().
+		TableExpr("user_group_members AS ugm").
+		ColumnExpr("ugm.user_id").
+		ColumnExpr("app.app_code AS app_code").
+		ColumnExpr("app.app_name AS app_name").
+		ColumnExpr("rol.code AS role_code").
+		ColumnExpr("rol.name AS role_name").
+		ColumnExpr("ugp.name AS group_name").
+		Join("JOIN user_groups AS ugp ON ugp.id = ugm.group_id AND ugp.deleted_at IS NULL").
+		Join("JOIN user_group_roles AS ugr ON ugr.group_id = ugm.group_id").
+		Join("JOIN roles AS rol ON rol.id = ugr.role_id AND rol.deleted_at IS NULL").
+		Join("JOIN app_services AS app ON app.id = rol.app_id").
+		Where("ugm.user_id IN (?)", bun.In(userIDs)).
+		Order("ugp.name ASC").
+		Scan(ctx, &groupRows)
 	if err != nil {
 		return nil, pkgErr.DatabaseError(err.Error())
 	}
 
-	for _, row := range rows {
-		rolesByUser[row.UserID] = append(rolesByUser[row.UserID], models.UserAppRole{
-			AppCode:  row.AppCode,
-			AppName:  row.AppName,
-			RoleCode: row.RoleCode,
-			RoleName: row.RoleName,
+	// merge on (user, app, role): the direct row first, then each group adds
+	// its name to the same entry
+	type roleKey struct{ userID, appCode, roleCode string }
+	index := map[roleKey]int{}
+	for _, row := range append(directRows, groupRows...) {
+		key := roleKey{row.UserID, row.AppCode, row.RoleCode}
+		i, ok := index[key]
+		if !ok {
+			i = len(rolesByUser[row.UserID])
+			index[key] = i
+			rolesByUser[row.UserID] = append(rolesByUser[row.UserID], models.UserAppRole{
+				AppCode:  row.AppCode,
+				AppName:  row.AppName,
+				RoleCode: row.RoleCode,
+				RoleName: row.RoleName,
+			})
+		}
+		if row.GroupName != "" {
+			role := &rolesByUser[row.UserID][i]
+			role.Groups = append(role.Groups, row.GroupName)
+		}
+	}
+	for _, roles := range rolesByUser {
+		slices.SortFunc(roles, func(a, b models.UserAppRole) int {
+			return cmp.Or(strings.Compare(a.AppCode, b.AppCode), strings.Compare(a.RoleCode, b.RoleCode))
 		})
 	}
 	return rolesByUser, nil
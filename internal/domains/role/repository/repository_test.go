@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"slices"
 	"testing"
+	"time"
 
 	sqliteHistory "github.com/vukyn/isme/db/history/sqlite"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
@@ -236,6 +237,200 @@ func TestGetAppCodesByUserID(t *testing.T) {
 	}
 }
 
+// A role grants its included roles' permissions, transitively, in the token
+// claims and the role's effective set; a soft-deleted include drops out.
+func TestRoleIncludesResolveTransitively(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	roleRepository := NewRepository(db)
+
+	insertApp(t, db, "app_medioa2", "medioa2", []string{"object", "bucket"})
+	for _, role := range []struct{ id, resource string }{
+		{"rol_viewer", "object"},
+		{"rol_editor", "bucket"},
+		{"rol_owner", ""},
+	} {
+		if _, err := db.Exec(`
+			INSERT INTO roles (id, app_id, code, name, is_system) VALUES (?, 'app_medioa2', ?, ?, 0)
+		`, role.id, role.id, role.id); err != nil {
+			t.Fatalf("insert role %s: %v", role.id, err)
+		}
+		if role.resource == "" {
+			continue
+		}
+		if _, err := db.Exec(`
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT ?, id FROM permissions WHERE app_id = 'app_medioa2' AND resource = ? AND action = 'read'
+		`, role.id, role.resource); err != nil {
+			t.Fatalf("grant %s to %s: %v", role.resource, role.id, err)
+		}
+	}
+	// owner -> editor -> viewer
+	if err := roleRepository.ReplaceRoleIncludes(ctx, "rol_editor", []string{"rol_viewer"}); err != nil {
+		t.Fatalf("ReplaceRoleIncludes(editor) error = %v", err)
+	}
+	if err := roleRepository.ReplaceRoleIncludes(ctx, "rol_owner", []string{"rol_editor"}); err != nil {
+		t.Fatalf("ReplaceRoleIncludes(owner) error = %v", err)
+	}
+	insertUser(t, db, "user-owner")
+	assignRole(t, db, "user-owner", "rol_owner", "app_medioa2")
+
+	grouped, err := roleRepository.GetPermissionCodesGroupedByApp(ctx, "user-owner")
+	if err != nil {
+		t.Fatalf("GetPermissionCodesGroupedByApp() error = %v", err)
+	}
+	if got := grouped["medioa2"]; len(got) != 2 || !slices.Contains(got, "object:read") || !slices.Contains(got, "bucket:read") {
+		t.Errorf("medioa2 codes = %v, want object:read + bucket:read through the includes", got)
+	}
+
+	direct, err := roleRepository.GetDirectPermissionsByRoleID(ctx, "rol_owner")
+	if err != nil || len(direct) != 0 {
+		t.Errorf("direct permissions = %v (err %v), want none", direct, err)
+	}
+	expanded, err := roleRepository.ExpandRoleIDs(ctx, []string{"rol_owner"})
+	if err != nil || len(expanded) != 3 || !slices.Contains(expanded, "rol_viewer") {
+		t.Errorf("ExpandRoleIDs(owner) = %v (err %v), want owner, editor and viewer", expanded, err)
+	}
+
+	if _, err := db.Exec(`UPDATE roles SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'rol_viewer'`); err != nil {
+		t.Fatalf("soft delete viewer: %v", err)
+	}
+	effective, err := roleRepository.GetPermissionsByRoleID(ctx, "rol_owner")
+	if err != nil {
+		t.Fatalf("GetPermissionsByRoleID() error = %v", err)
+	}
+	if len(effective) != 1 || effective[0].Resource != "bucket" {
+		t.Errorf("effective permissions = %v, want only bucket:read once viewer is deleted", effective)
+	}
+}
+
+// Only assignments in force count toward the token: a not-yet-started or
+// expired membership grants nothing until it starts or is re-added, and the
+// expiry sweep removes exactly the expired rows.
+func TestTimeBoundAssignments(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	roleRepository := NewRepository(db)
+
+	insertApp(t, db, "app_medioa2", "medioa2", []string{"object"})
+	appID := "app_medioa2"
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	windows := map[string]models.MemberWindow{
+		"user-permanent": {},
+		"user-running":   {StartsAt: &past, ExpiresAt: &future},
+		"user-pending":   {StartsAt: &future},
+		"user-expired":   {ExpiresAt: &past},
+	}
+	for userID, window := range windows {
+		insertUser(t, db, userID)
+		if err := roleRepository.AddMembers(ctx, "rol_medioa2_admin", []string{userID}, &appID, window); err != nil {
+			t.Fatalf("AddMembers(%s) error = %v", userID, err)
+		}
+	}
+
+	for userID, wantActive := range map[string]bool{"user-permanent": true, "user-running": true, "user-pending": false, "user-expired": false} {
+		grouped, err := roleRepository.GetPermissionCodesGroupedByApp(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetPermissionCodesGroupedByApp(%s) error = %v", userID, err)
+		}
+		if got := len(grouped["medioa2"]) > 0; got != wantActive {
+			t.Errorf("%s holds medioa2 permissions = %v, want %v", userID, got, wantActive)
+		}
+	}
+
+	expired, err := roleRepository.DeleteExpiredMembers(ctx, now)
+	if err != nil {
+		t.Fatalf("DeleteExpiredMembers() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].UserID != "user-expired" {
+		t.Fatalf("DeleteExpiredMembers() = %+v, want only user-expired", expired)
+	}
+	if count, err := roleRepository.CountMembersByRoleID(ctx, "rol_medioa2_admin"); err != nil || count != 3 {
+		t.Fatalf("CountMembersByRoleID() = %d (err %v), want 3", count, err)
+	}
+
+	// a pending membership is still an assignment; an expired one is gone
+	for userID, want := range map[string]bool{"user-pending": true, "user-expired": false} {
+		if held, err := roleRepository.HasMember(ctx, "rol_medioa2_admin", userID, &appID); err != nil || held != want {
+			t.Errorf("HasMember(%s) = %v (err %v), want %v", userID, held, err, want)
+		}
+	}
+
+	// re-adding without a window makes the pending membership permanent
+	if err := roleRepository.AddMembers(ctx, "rol_medioa2_admin", []string{"user-pending"}, &appID, models.MemberWindow{}); err != nil {
+		t.Fatalf("AddMembers(re-add) error = %v", err)
+	}
+	grouped, err := roleRepository.GetPermissionCodesGroupedByApp(ctx, "user-pending")
+	if err != nil || len(grouped["medioa2"]) == 0 {
+		t.Errorf("re-added user-pending codes = %v (err %v), want medioa2 permissions", grouped, err)
+	}
+}
+
+// Members of a live group hold its roles wherever a direct assignment counts:
+// the token claims, the role and app codes, and the user list chips (which
+// name the group). Deleting the group takes the roles away.
+func TestGroupRolesAreInherited(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	roleRepository := NewRepository(db)
+
+	insertApp(t, db, "app_medioa2", "medioa2", []string{"object"})
+	insertUser(t, db, "user-grouped")
+	assignRole(t, db, "user-grouped", "rol_member", roleConstants.APP_ID_ISME)
+	for _, stmt := range []string{
+		`INSERT INTO user_groups (id, name) VALUES ('grp_ops', 'Ops')`,
+		`INSERT INTO user_group_members (group_id, user_id) VALUES ('grp_ops', 'user-grouped')`,
+		`INSERT INTO user_group_roles (group_id, role_id) VALUES ('grp_ops', 'rol_medioa2_admin')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed group: %v", err)
+		}
+	}
+
+	grouped, err := roleRepository.GetPermissionCodesGroupedByApp(ctx, "user-grouped")
+	if err != nil {
+		t.Fatalf("GetPermissionCodesGroupedByApp() error = %v", err)
+	}
+	if !slices.Contains(grouped["medioa2"], "object:read") || !slices.Contains(grouped["isme"], "user:read") {
+		t.Errorf("codes = %v, want medioa2 object:read through the group and isme user:read directly", grouped)
+	}
+	roleCodes, err := roleRepository.GetRoleCodesByUserID(ctx, "user-grouped", "app_medioa2")
+	if err != nil || !slices.Equal(roleCodes, []string{"admin"}) {
+		t.Errorf("GetRoleCodesByUserID(medioa2) = %v (err %v), want [admin]", roleCodes, err)
+	}
+	appCodes, err := roleRepository.GetAppCodesByUserID(ctx, "user-grouped")
+	if err != nil || !slices.Contains(appCodes, "medioa2") {
+		t.Errorf("GetAppCodesByUserID() = %v (err %v), want medioa2", appCodes, err)
+	}
+
+	rolesByUser, err := roleRepository.GetRoleCodesGroupedByAppByUserIDs(ctx, []string{"user-grouped"})
+	if err != nil {
+		t.Fatalf("GetRoleCodesGroupedByAppByUserIDs() error = %v", err)
+	}
+	want := []models.UserAppRole{
+		{AppCode: "isme", RoleCode: "member"},
+		{AppCode: "medioa2", RoleCode: "admin", Groups: []string{"Ops"}},
+	}
+	got := rolesByUser["user-grouped"]
+	if len(got) != len(want) {
+		t.Fatalf("chips = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].AppCode != want[i].AppCode || got[i].RoleCode != want[i].RoleCode || !slices.Equal(got[i].Groups, want[i].Groups) {
+			t.Errorf("chip %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := db.Exec(`UPDATE user_groups SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'grp_ops'`); err != nil {
+		t.Fatalf("soft delete group: %v", err)
+	}
+	grouped, err = roleRepository.GetPermissionCodesGroupedByApp(ctx, "user-grouped")
+	if err != nil || len(grouped["medioa2"]) != 0 {
+		t.Errorf("codes after group delete = %v (err %v), want no medioa2 permissions", grouped, err)
+	}
+}
+
 // CreatePermissions stores the icon on a brand-new resource and reuses it for
 // later rows of the same resource (never overwriting), so a resource keeps one
 // consistent icon and ListPermissions reports it on every row.
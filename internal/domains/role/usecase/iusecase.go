@@ -11,7 +11,7 @@ type IUseCase interface {
 	List(ctx context.Context, req models.ListRequest) ([]models.RoleListItem, error)
 	// Create a role, optionally cloning permissions from another role
 	Create(ctx context.Context, req models.CreateRequest) (models.CreateResponse, error)
-	// Get role detail including its permissions
+	// Get role detail including its direct and inherited permissions
 	GetDetail(ctx context.Context, id string) (models.RoleDetailResponse, error)
 	// Update role name and description
 	Update(ctx context.Context, id string, req models.UpdateRequest) error
@@ -19,21 +19,29 @@ type IUseCase interface {
 	Delete(ctx context.Context, id string) error
 	// Replace the permissions of a role
 	SetPermissions(ctx context.Context, id string, req models.SetPermissionsRequest) error
+	// Replace the roles a role includes (same app only; cycles are rejected)
+	SetIncludes(ctx context.Context, id string, req models.SetIncludesRequest) error
 	// List the permission catalog, filtered by owning app
 	ListPermissions(ctx context.Context, req models.ListPermissionsRequest) ([]models.PermissionItem, error)
 	// Create resource:action permissions for an app (rejected for the isme system app)
 	CreatePermissions(ctx context.Context, req models.CreatePermissionsRequest) ([]models.PermissionItem, error)
+	// Converge an app's catalog on the list the app pushed: create missing
+	// pairs, flag omitted ones for removal, restore re-listed flagged ones
+	// (rejected for the isme system app)
+	SyncPermissions(ctx context.Context, appID string, req models.SyncPermissionsRequest) (models.SyncPermissionsResponse, error)
 	// Delete a catalog permission and clear its grants (rejected for the isme system app)
 	DeletePermission(ctx context.Context, permissionID int64) error
 	// Update a resource's appearance (icon + color) across all its catalog rows
 	// (rejected for the isme system app; resource must exist in the app catalog)
 	UpdatePermissionAppearance(ctx context.Context, req models.UpdatePermissionAppearanceRequest) error
 	// ProvisionDefaultRoles seeds the default per-app role set (an admin role
-	// holding the app's full CRUD permission catalog) for a newly created app
+	// holding the reserved access_request:approve permission) for a newly
+	// created app
 	ProvisionDefaultRoles(ctx context.Context, appID string) error
 	// List role members with pagination
 	ListMembers(ctx context.Context, id string, req models.ListMembersRequest) (models.ListMembersResponse, error)
-	// Add members to a role
+	// Add members to a role, optionally for a bounded window (re-adding a
+	// member moves its window)
 	AddMembers(ctx context.Context, id string, req models.AddMembersRequest) error
 	// Remove a member from a role
 	RemoveMember(ctx context.Context, id string, userID string, appServiceID *string) error
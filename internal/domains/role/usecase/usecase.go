@@ -2,32 +2,45 @@ package usecase
 
 import (
 	"context"
+	"slices"
+	"strconv"
+	"time"
 
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
 	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
 	"github.com/vukyn/isme/internal/domains/role/entity"
 	"github.com/vukyn/isme/internal/domains/role/models"
 	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgErr "github.com/vukyn/kuery/http/errors"
 )
 
 type usecase struct {
-	roleRepo       roleRepo.IRepository
-	userRepo       userRepo.IRepository
-	appServiceRepo appServiceRepo.IRepository
+	roleRepo        roleRepo.IRepository
+	userRepo        userRepo.IRepository
+	appServiceRepo  appServiceRepo.IRepository
+	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
 }
 
 func NewUsecase(
 	roleRepo roleRepo.IRepository,
 	userRepo userRepo.IRepository,
 	appServiceRepo appServiceRepo.IRepository,
+	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
 ) IUseCase {
 	return &usecase{
-		roleRepo:       roleRepo,
-		userRepo:       userRepo,
-		appServiceRepo: appServiceRepo,
+		roleRepo:        roleRepo,
+		userRepo:        userRepo,
+		appServiceRepo:  appServiceRepo,
+		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
 	}
 }
 
@@ -74,27 +87,45 @@ func (u *usecase) Create(ctx context.Context, req models.CreateRequest) (models.
 		if sourceRole.AppID != req.AppID {
 			return models.CreateResponse{}, pkgErr.InvalidRequest("clone source role must belong to the same app")
 		}
+		// the effective set: the clone grants what the source grants, with
+		// the source's included roles flattened into direct permissions
 		clonedPermissions, err = u.roleRepo.GetPermissionsByRoleID(ctx, sourceRole.ID)
 		if err != nil {
 			return models.CreateResponse{}, err
 		}
 	}
 
-	// create role
-	roleID, err := u.roleRepo.Create(ctx, req)
-	if err != nil {
-		return models.CreateResponse{}, err
-	}
+	// create the role, copy the clone source's permissions and record the audit
+	// row in one transaction
+	var roleID string
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		var err error
+		roleID, err = u.roleRepo.Create(ctx, req)
+		if err != nil {
+			return err
+		}
 
-	// copy permissions from the clone source
-	if len(clonedPermissions) > 0 {
+		// copy permissions from the clone source
 		permissionIDs := make([]int64, 0, len(clonedPermissions))
 		for _, permission := range clonedPermissions {
 			permissionIDs = append(permissionIDs, permission.ID)
 		}
-		if err := u.roleRepo.ReplaceRolePermissions(ctx, roleID, permissionIDs); err != nil {
-			return models.CreateResponse{}, err
+		if len(permissionIDs) > 0 {
+			if err := u.roleRepo.ReplaceRolePermissions(ctx, roleID, permissionIDs); err != nil {
+				return err
+			}
 		}
+
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleCreated,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   roleID,
+			After:      req,
+			Meta:       map[string]any{"permission_ids": permissionIDs},
+		})
+	})
+	if err != nil {
+		return models.CreateResponse{}, err
 	}
 
 	return models.CreateResponse{
@@ -111,20 +142,54 @@ func (u *usecase) GetDetail(ctx context.Context, id string) (models.RoleDetailRe
 		return models.RoleDetailResponse{}, pkgErr.NotFound("role not found")
 	}
 
-	permissions, err := u.roleRepo.GetPermissionsByRoleID(ctx, role.ID)
+	permissions, err := u.roleRepo.GetDirectPermissionsByRoleID(ctx, role.ID)
 	if err != nil {
 		return models.RoleDetailResponse{}, err
 	}
-
+	directIDs := map[int64]bool{}
 	permissionItems := make([]models.PermissionItem, 0, len(permissions))
 	for _, permission := range permissions {
-		permissionItems = append(permissionItems, models.PermissionItem{
-			ID:       permission.ID,
-			AppID:    permission.AppID,
-			Resource: permission.Resource,
-			Action:   permission.Action,
-			Icon:     permission.Icon,
-			Color:    permission.Color,
+		directIDs[permission.ID] = true
+		permissionItems = append(permissionItems, permissionItem(permission))
+	}
+
+	// included roles, and what each of them grants effectively, to attribute
+	// every inherited permission to the include(s) it comes through
+	includedByRole, err := u.roleRepo.GetIncludedRoles(ctx, []string{role.ID})
+	if err != nil {
+		return models.RoleDetailResponse{}, err
+	}
+	included := includedByRole[role.ID]
+	includes := make([]models.RoleRef, 0, len(included))
+	includedIDs := make([]string, 0, len(included))
+	for _, includedRole := range included {
+		includes = append(includes, models.RoleRef{ID: includedRole.ID, Code: includedRole.Code, Name: includedRole.Name})
+		includedIDs = append(includedIDs, includedRole.ID)
+	}
+	codesByInclude, err := u.roleRepo.GetPermissionCodesByRoleIDs(ctx, includedIDs)
+	if err != nil {
+		return models.RoleDetailResponse{}, err
+	}
+
+	effective, err := u.roleRepo.GetPermissionsByRoleID(ctx, role.ID)
+	if err != nil {
+		return models.RoleDetailResponse{}, err
+	}
+	inheritedItems := []models.InheritedPermissionItem{}
+	for _, permission := range effective {
+		if directIDs[permission.ID] {
+			continue
+		}
+		code := permission.Resource + ":" + permission.Action
+		via := []string{}
+		for _, includedRole := range included {
+			if slices.Contains(codesByInclude[includedRole.ID], code) {
+				via = append(via, includedRole.Code)
+			}
+		}
+		inheritedItems = append(inheritedItems, models.InheritedPermissionItem{
+			PermissionItem: permissionItem(permission),
+			Via:            via,
 		})
 	}
 
@@ -139,16 +204,18 @@ func (u *usecase) GetDetail(ctx context.Context, id string) (models.RoleDetailRe
 	}
 
 	return models.RoleDetailResponse{
-		ID:          role.ID,
-		AppID:       role.AppID,
-		AppCode:     appCode,
-		Code:        role.Code,
-		Name:        role.Name,
-		Description: role.Description,
-		Icon:        role.Icon,
-		Color:       role.Color,
-		IsSystem:    role.IsSystem,
-		Permissions: permissionItems,
+		ID:                   role.ID,
+		AppID:                role.AppID,
+		AppCode:              appCode,
+		Code:                 role.Code,
+		Name:                 role.Name,
+		Description:          role.Description,
+		Icon:                 role.Icon,
+		Color:                role.Color,
+		IsSystem:             role.IsSystem,
+		Permissions:          permissionItems,
+		Includes:             includes,
+		InheritedPermissions: inheritedItems,
 	}, nil
 }
 
@@ -170,7 +237,23 @@ func (u *usecase) Update(ctx context.Context, id string, req models.UpdateReques
 		return pkgErr.Forbidden("system role cannot be modified")
 	}
 
-	return u.roleRepo.Update(ctx, id, req)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Update(ctx, id, req); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleUpdated,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   id,
+			Before: models.UpdateRequest{
+				Name:        role.Name,
+				Description: role.Description,
+				Icon:        role.Icon,
+				Color:       role.Color,
+			},
+			After: req,
+		})
+	})
 }
 
 func (u *usecase) Delete(ctx context.Context, id string) error {
@@ -195,7 +278,17 @@ func (u *usecase) Delete(ctx context.Context, id string) error {
 		return pkgErr.InvalidRequest("role has members; reassign first")
 	}
 
-	return u.roleRepo.SoftDelete(ctx, id)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.SoftDelete(ctx, id); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleDeleted,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   id,
+			Before:     roleSnapshot(role),
+		})
+	})
 }
 
 func (u *usecase) SetPermissions(ctx context.Context, id string, req models.SetPermissionsRequest) error {
@@ -216,7 +309,96 @@ func (u *usecase) SetPermissions(ctx context.Context, id string, req models.SetP
 		return pkgErr.Forbidden("system role cannot be modified")
 	}
 
-	return u.roleRepo.ReplaceRolePermissions(ctx, id, req.PermissionIDs)
+	currentPermissions, err := u.roleRepo.GetDirectPermissionsByRoleID(ctx, id)
+	if err != nil {
+		return err
+	}
+	currentPermissionIDs := make([]int64, 0, len(currentPermissions))
+	for _, permission := range currentPermissions {
+		currentPermissionIDs = append(currentPermissionIDs, permission.ID)
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.ReplaceRolePermissions(ctx, id, req.PermissionIDs); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRolePermissionsSet,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   id,
+			Before:     models.SetPermissionsRequest{PermissionIDs: currentPermissionIDs},
+			After:      req,
+		})
+	})
+}
+
+func (u *usecase) SetIncludes(ctx context.Context, id string, req models.SetIncludesRequest) error {
+	// validation
+	if err := req.Validate(); err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+
+	// check role exists and is editable
+	role, err := u.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.ID == "" {
+		return pkgErr.NotFound("role not found")
+	}
+	if role.IsSystem {
+		return pkgErr.Forbidden("system role cannot be modified")
+	}
+
+	// an included role must exist and belong to the same app — a role never
+	// grants another app's permissions
+	for _, includedRoleID := range req.RoleIDs {
+		if includedRoleID == id {
+			return pkgErr.InvalidRequest("a role cannot include itself")
+		}
+		includedRole, err := u.roleRepo.GetByID(ctx, includedRoleID)
+		if err != nil {
+			return err
+		}
+		if includedRole.ID == "" {
+			return pkgErr.InvalidRequest("included role not found")
+		}
+		if includedRole.AppID != role.AppID {
+			return pkgErr.InvalidRequest("included role must belong to the same app")
+		}
+	}
+
+	includedByRole, err := u.roleRepo.GetIncludedRoles(ctx, []string{id})
+	if err != nil {
+		return err
+	}
+	currentRoleIDs := make([]string, 0, len(includedByRole[id]))
+	for _, includedRole := range includedByRole[id] {
+		currentRoleIDs = append(currentRoleIDs, includedRole.ID)
+	}
+
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		// write first, then reject a cycle: no candidate may reach this role
+		// through its includes. The write holds the app's include changes, so
+		// a concurrent SetIncludes checks against this one, not around it
+		if err := u.roleRepo.ReplaceRoleIncludes(ctx, id, req.RoleIDs); err != nil {
+			return err
+		}
+		reachable, err := u.roleRepo.ExpandRoleIDs(ctx, req.RoleIDs)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(reachable, id) {
+			return pkgErr.InvalidRequest("including these roles would create a cycle")
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleIncludesSet,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   id,
+			Before:     models.SetIncludesRequest{RoleIDs: currentRoleIDs},
+			After:      req,
+		})
+	})
 }
 
 func (u *usecase) ListPermissions(ctx context.Context, req models.ListPermissionsRequest) ([]models.PermissionItem, error) {
@@ -242,14 +424,18 @@ func (u *usecase) ListPermissions(ctx context.Context, req models.ListPermission
 	items := make([]models.PermissionItem, 0, len(permissions))
 	for _, permission := range permissions {
 		key := permission.AppID + "\x00" + permission.Resource
-		items = append(items, models.PermissionItem{
+		item := models.PermissionItem{
 			ID:       permission.ID,
 			AppID:    permission.AppID,
 			Resource: permission.Resource,
 			Action:   permission.Action,
 			Icon:     iconByResource[key],
 			Color:    colorByResource[key],
-		})
+		}
+		if !permission.FlaggedAt.IsZero() {
+			item.FlaggedAt = permission.FlaggedAt.UTC().Format(time.RFC3339)
+		}
+		items = append(items, item)
 	}
 	return items, nil
 }
@@ -287,7 +473,21 @@ func (u *usecase) CreatePermissions(ctx context.Context, req models.CreatePermis
 		perms = append(perms, models.PermissionItem{Resource: permission.Resource, Action: permission.Action, Icon: permission.Icon, Color: permission.Color})
 	}
 
-	permissionIDsByCode, err := u.roleRepo.CreatePermissions(ctx, req.AppID, perms)
+	var permissionIDsByCode map[string]int64
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		var err error
+		permissionIDsByCode, err = u.roleRepo.CreatePermissions(ctx, req.AppID, perms)
+		if err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypePermissionsCreated,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   req.AppID,
+			After:      perms,
+			Meta:       map[string]any{"permission_ids": permissionIDsByCode},
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -322,6 +522,105 @@ func (u *usecase) CreatePermissions(ctx context.Context, req models.CreatePermis
 	return items, nil
 }
 
+// SyncPermissions converges an app's catalog on the full list the app pushed:
+// missing pairs are created (with CreatePermissions' per-resource icon and
+// color rule), catalog rows the list omits are flagged for an admin to confirm
+// by deleting them, and flagged rows listed again are restored. Role grants
+// are left alone. A sync that changes nothing is not audited, so apps may sync
+// on every start. The isme system app is rejected.
+func (u *usecase) SyncPermissions(ctx context.Context, appID string, req models.SyncPermissionsRequest) (models.SyncPermissionsResponse, error) {
+	// validation
+	if err := req.Validate(); err != nil {
+		return models.SyncPermissionsResponse{}, pkgErr.InvalidRequest(err.Error())
+	}
+
+	// the isme system app owns its permission catalog and is read-only
+	if appID == roleConstants.APP_ID_ISME {
+		return models.SyncPermissionsResponse{}, pkgErr.Forbidden("isme system app permissions are read-only")
+	}
+	app, err := u.appServiceRepo.GetByID(ctx, appID)
+	if err != nil {
+		return models.SyncPermissionsResponse{}, err
+	}
+	if app.ID == "" {
+		return models.SyncPermissionsResponse{}, pkgErr.InvalidRequest("app service not found")
+	}
+	if app.AppCode == roleConstants.APP_CODE_ISME {
+		return models.SyncPermissionsResponse{}, pkgErr.Forbidden("isme system app permissions are read-only")
+	}
+
+	catalog, err := u.roleRepo.ListPermissions(ctx, models.ListPermissionsRequest{AppID: appID})
+	if err != nil {
+		return models.SyncPermissionsResponse{}, err
+	}
+	current := make(map[string]entity.Permission, len(catalog))
+	for _, permission := range catalog {
+		current[permission.Resource+":"+permission.Action] = permission
+	}
+
+	res := models.SyncPermissionsResponse{Created: []string{}, Flagged: []string{}, Restored: []string{}}
+	listed := map[string]bool{}
+	var create []models.PermissionItem
+	var restore []int64
+	for _, pair := range req.Permissions {
+		code := pair.Resource + ":" + pair.Action
+		if listed[code] {
+			continue
+		}
+		listed[code] = true
+		permission, exists := current[code]
+		switch {
+		case !exists:
+			create = append(create, models.PermissionItem{Resource: pair.Resource, Action: pair.Action, Icon: pair.Icon, Color: pair.Color})
+			res.Created = append(res.Created, code)
+		case !permission.FlaggedAt.IsZero():
+			restore = append(restore, permission.ID)
+			res.Restored = append(res.Restored, code)
+		default:
+			res.Unchanged++
+		}
+	}
+	var flag []int64
+	for code, permission := range current {
+		// the reserved approval permission is isme's, never the app's to drop
+		if !listed[code] && permission.FlaggedAt.IsZero() && !roleConstants.IsReservedPermission(code) {
+			flag = append(flag, permission.ID)
+			res.Flagged = append(res.Flagged, code)
+		}
+	}
+	slices.Sort(res.Created)
+	slices.Sort(res.Flagged)
+	slices.Sort(res.Restored)
+
+	if len(create) == 0 && len(flag) == 0 && len(restore) == 0 {
+		return res, nil
+	}
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if len(create) > 0 {
+			if _, err := u.roleRepo.CreatePermissions(ctx, appID, create); err != nil {
+				return err
+			}
+		}
+		if err := u.roleRepo.FlagPermissions(ctx, flag, time.Now()); err != nil {
+			return err
+		}
+		if err := u.roleRepo.FlagPermissions(ctx, restore, time.Time{}); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypePermissionsSynced,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   appID,
+			After:      res,
+			Meta:       map[string]any{"app_code": app.AppCode},
+		})
+	})
+	if err != nil {
+		return models.SyncPermissionsResponse{}, err
+	}
+	return res, nil
+}
+
 // DeletePermission removes a resource:action permission from an app's catalog
 // and clears any role grants referencing it. Deleting a permission owned by the
 // isme system app is rejected — its catalog is seeded and read-only.
@@ -343,6 +642,9 @@ func (u *usecase) DeletePermission(ctx context.Context, permissionID int64) erro
 	if permission.AppID == roleConstants.APP_ID_ISME {
 		return pkgErr.Forbidden("isme system app permissions are read-only")
 	}
+	if roleConstants.IsReservedPermission(permission.Resource + ":" + permission.Action) {
+		return pkgErr.Forbidden("access_request:approve is reserved and cannot be deleted")
+	}
 
 	// resolve the owning app and guard by code too (defense in depth)
 	app, err := u.appServiceRepo.GetByID(ctx, permission.AppID)
@@ -353,7 +655,24 @@ func (u *usecase) DeletePermission(ctx context.Context, permissionID int64) erro
 		return pkgErr.Forbidden("isme system app permissions are read-only")
 	}
 
-	return u.roleRepo.DeletePermission(ctx, permissionID)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.DeletePermission(ctx, permissionID); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypePermissionDeleted,
+			TargetType: activityConstants.TargetTypePermission,
+			TargetID:   strconv.FormatInt(permissionID, 10),
+			Before: models.PermissionItem{
+				ID:       permission.ID,
+				AppID:    permission.AppID,
+				Resource: permission.Resource,
+				Action:   permission.Action,
+				Icon:     permission.Icon,
+				Color:    permission.Color,
+			},
+		})
+	})
 }
 
 // UpdatePermissionAppearance changes a resource's per-resource icon and color
@@ -389,9 +708,11 @@ func (u *usecase) UpdatePermissionAppearance(ctx context.Context, req models.Upd
 	if err != nil {
 		return err
 	}
+	var current models.PermissionItem
 	found := false
 	for _, item := range items {
 		if item.Resource == req.Resource {
+			current = item
 			found = true
 			break
 		}
@@ -400,13 +721,30 @@ func (u *usecase) UpdatePermissionAppearance(ctx context.Context, req models.Upd
 		return pkgErr.NotFound("resource not found in app catalog")
 	}
 
-	return u.roleRepo.UpdatePermissionAppearance(ctx, req.AppID, req.Resource, req.Icon, req.Color)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.UpdatePermissionAppearance(ctx, req.AppID, req.Resource, req.Icon, req.Color); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypePermissionAppearanceUpdated,
+			TargetType: activityConstants.TargetTypeAppService,
+			TargetID:   req.AppID,
+			Before: models.UpdatePermissionAppearanceRequest{
+				AppID:    req.AppID,
+				Resource: req.Resource,
+				Icon:     current.Icon,
+				Color:    current.Color,
+			},
+			After: req,
+		})
+	})
 }
 
-// ProvisionDefaultRoles seeds an empty "admin" role for a newly created app.
-// The role starts with zero permissions — resource:action permissions are
-// created and assigned manually afterward (see CreatePermissions). Idempotent:
-// re-running for an app that already has the admin role is a no-op.
+// ProvisionDefaultRoles seeds an "admin" role for a newly created app, holding
+// only the reserved access_request:approve permission so the app has an
+// approver for access requests from day one. Every other resource:action
+// permission is created and assigned afterward (see CreatePermissions).
+// Idempotent: re-running for an app that already has the admin role is a no-op.
 func (u *usecase) ProvisionDefaultRoles(ctx context.Context, appID string) error {
 	if appID == "" {
 		return pkgErr.InvalidRequest("app_id is required")
@@ -421,14 +759,36 @@ func (u *usecase) ProvisionDefaultRoles(ctx context.Context, appID string) error
 		return nil
 	}
 
-	// create the empty admin role; no permissions are seeded
-	_, err = u.roleRepo.Create(ctx, models.CreateRequest{
+	// create the admin role with the approval permission. Runs inside the
+	// caller's transaction when there is one (RegisterApp), so the app and its
+	// default role commit together.
+	req := models.CreateRequest{
 		AppID:       appID,
 		Code:        roleConstants.ROLE_CODE_ADMIN,
 		Name:        "Admin",
 		Description: "Full access to every resource",
+	}
+	approve := models.PermissionItem{Resource: roleConstants.RESOURCE_ACCESS_REQUEST, Action: roleConstants.ACTION_APPROVE}
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		roleID, err := u.roleRepo.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+		permissionIDsByCode, err := u.roleRepo.CreatePermissions(ctx, appID, []models.PermissionItem{approve})
+		if err != nil {
+			return err
+		}
+		if err := u.roleRepo.ReplaceRolePermissions(ctx, roleID, []int64{permissionIDsByCode[roleConstants.PERM_ACCESS_REQUEST_APPROVE]}); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleCreated,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   roleID,
+			After:      req,
+			Meta:       map[string]any{"permissions": []string{roleConstants.PERM_ACCESS_REQUEST_APPROVE}},
+		})
 	})
-	return err
 }
 
 func (u *usecase) ListMembers(ctx context.Context, id string, req models.ListMembersRequest) (models.ListMembersResponse, error) {
@@ -463,6 +823,13 @@ func (u *usecase) AddMembers(ctx context.Context, id string, req models.AddMembe
 	if err := req.Validate(); err != nil {
 		return pkgErr.InvalidRequest(err.Error())
 	}
+	window, err := req.Window()
+	if err != nil {
+		return pkgErr.InvalidRequest(err.Error())
+	}
+	if window.ExpiresAt != nil && !window.ExpiresAt.After(time.Now()) {
+		return pkgErr.InvalidRequest("expires_at must be in the future")
+	}
 
 	// check role exists
 	role, err := u.roleRepo.GetByID(ctx, id)
@@ -489,7 +856,18 @@ func (u *usecase) AddMembers(ctx context.Context, id string, req models.AddMembe
 	// grants no perms in the issued token. Derive it from the role rather than
 	// trusting the client (the UI add-to-role flow omits it).
 	appServiceID := role.AppID
-	return u.roleRepo.AddMembers(ctx, id, req.UserIDs, &appServiceID)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.AddMembers(ctx, id, req.UserIDs, &appServiceID, window); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleMembersAdded,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   id,
+			After:      req,
+			Meta:       map[string]any{"app_service_id": appServiceID},
+		})
+	})
 }
 
 func (u *usecase) RemoveMember(ctx context.Context, id string, userID string, appServiceID *string) error {
@@ -502,5 +880,45 @@ func (u *usecase) RemoveMember(ctx context.Context, id string, userID string, ap
 		return pkgErr.NotFound("role not found")
 	}
 
-	return u.roleRepo.RemoveMember(ctx, id, userID, appServiceID)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.RemoveMember(ctx, id, userID, appServiceID); err != nil {
+			return err
+		}
+		meta := map[string]any{}
+		if appServiceID != nil {
+			meta["app_service_id"] = *appServiceID
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeRoleMemberRemoved,
+			TargetType: activityConstants.TargetTypeRole,
+			TargetID:   id,
+			Before:     models.AddMembersRequest{UserIDs: []string{userID}},
+			Meta:       meta,
+		})
+	})
+}
+
+// permissionItem is the API view of a permission row.
+func permissionItem(permission entity.Permission) models.PermissionItem {
+	return models.PermissionItem{
+		ID:       permission.ID,
+		AppID:    permission.AppID,
+		Resource: permission.Resource,
+		Action:   permission.Action,
+		Icon:     permission.Icon,
+		Color:    permission.Color,
+	}
+}
+
+// roleSnapshot is the audit view of a role: its identity and editable fields,
+// never its bookkeeping columns.
+func roleSnapshot(role entity.Role) map[string]any {
+	return map[string]any{
+		"app_id":      role.AppID,
+		"code":        role.Code,
+		"name":        role.Name,
+		"description": role.Description,
+		"icon":        role.Icon,
+		"color":       role.Color,
+	}
 }
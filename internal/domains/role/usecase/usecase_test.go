@@ -2,10 +2,14 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"maps"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
 	appServiceEntity "github.com/vukyn/isme/internal/domains/app_service/entity"
 	appServiceModels "github.com/vukyn/isme/internal/domains/app_service/models"
 	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
@@ -16,6 +20,7 @@ import (
 	userEntity "github.com/vukyn/isme/internal/domains/user/entity"
 	userModels "github.com/vukyn/isme/internal/domains/user/models"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
+	"github.com/vukyn/isme/internal/transaction"
 )
 
 // === Fakes ===
@@ -32,6 +37,9 @@ type fakeRoleRepository struct {
 	createdPermissions   map[string][]models.PermissionItem
 	deletedPermissionIDs []int64
 	updatedAppearances   []string
+	flaggedAt            map[int64]time.Time
+	includes             map[string][]string
+	memberWindows        map[string]models.MemberWindow
 }
 
 var _ roleRepo.IRepository = (*fakeRoleRepository)(nil)
@@ -44,6 +52,9 @@ func newFakeRoleRepository() *fakeRoleRepository {
 		membersCount:        map[string]int{},
 		replacedPermissions: map[string][]int64{},
 		createdPermissions:  map[string][]models.PermissionItem{},
+		flaggedAt:           map[int64]time.Time{},
+		includes:            map[string][]string{},
+		memberWindows:       map[string]models.MemberWindow{},
 	}
 }
 
@@ -81,13 +92,18 @@ func (f *fakeRoleRepository) SoftDelete(ctx context.Context, id string) error {
 func (f *fakeRoleRepository) ListPermissions(ctx context.Context, req models.ListPermissionsRequest) ([]entity.Permission, error) {
 	items := []entity.Permission{}
 	for _, perm := range f.createdPermissions[req.AppID] {
+		flaggedAt := f.flaggedAt[perm.ID]
+		if req.Flagged && flaggedAt.IsZero() {
+			continue
+		}
 		items = append(items, entity.Permission{
-			ID:       perm.ID,
-			AppID:    req.AppID,
-			Resource: perm.Resource,
-			Action:   perm.Action,
-			Icon:     perm.Icon,
-			Color:    perm.Color,
+			ID:        perm.ID,
+			AppID:     req.AppID,
+			Resource:  perm.Resource,
+			Action:    perm.Action,
+			Icon:      perm.Icon,
+			Color:     perm.Color,
+			FlaggedAt: flaggedAt,
 		})
 	}
 	return items, nil
@@ -117,8 +133,8 @@ func (f *fakeRoleRepository) CreatePermissions(ctx context.Context, appID string
 	}
 
 	ids := map[string]int64{}
-	for i, perm := range perms {
-		id := int64(len(f.createdPermissions[appID]) + i + 1)
+	for _, perm := range perms {
+		id := int64(len(f.createdPermissions[appID]) + 1)
 		f.createdPermissions[appID] = append(f.createdPermissions[appID], models.PermissionItem{
 			ID:       id,
 			AppID:    appID,
@@ -151,15 +167,72 @@ func (f *fakeRoleRepository) UpdatePermissionAppearance(ctx context.Context, app
 	return nil
 }
 
+func (f *fakeRoleRepository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	for _, id := range permissionIDs {
+		if flaggedAt.IsZero() {
+			delete(f.flaggedAt, id)
+		} else {
+			f.flaggedAt[id] = flaggedAt
+		}
+	}
+	return nil
+}
+
 func (f *fakeRoleRepository) DeletePermission(ctx context.Context, permissionID int64) error {
 	f.deletedPermissionIDs = append(f.deletedPermissionIDs, permissionID)
 	return nil
 }
 
 func (f *fakeRoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]entity.Permission, error) {
+	closure, _ := f.ExpandRoleIDs(ctx, []string{roleID})
+	seen := map[int64]bool{}
+	permissions := []entity.Permission{}
+	for _, id := range closure {
+		for _, permission := range f.permissionsByRole[id] {
+			if !seen[permission.ID] {
+				seen[permission.ID] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	slices.SortFunc(permissions, func(a, b entity.Permission) int { return int(a.ID - b.ID) })
+	return permissions, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]entity.Permission, error) {
 	return f.permissionsByRole[roleID], nil
 }
 
+func (f *fakeRoleRepository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]entity.Role, error) {
+	result := map[string][]entity.Role{}
+	for _, roleID := range roleIDs {
+		for _, includedID := range f.includes[roleID] {
+			result[roleID] = append(result[roleID], f.rolesByID[includedID])
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRoleRepository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	expanded := []string{}
+	queue := slices.Clone(roleIDs)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if slices.Contains(expanded, id) {
+			continue
+		}
+		expanded = append(expanded, id)
+		queue = append(queue, f.includes[id]...)
+	}
+	return expanded, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	f.includes[roleID] = includedRoleIDs
+	return nil
+}
+
 func (f *fakeRoleRepository) ReplaceRolePermissions(ctx context.Context, roleID string, permissionIDs []int64) error {
 	f.replacedPermissions[roleID] = permissionIDs
 	return nil
@@ -173,10 +246,21 @@ func (f *fakeRoleRepository) CountMembersByRoleID(ctx context.Context, roleID st
 	return f.membersCount[roleID], nil
 }
 
-func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string) error {
+func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window models.MemberWindow) error {
+	for _, userID := range userIDs {
+		f.memberWindows[roleID+"/"+userID] = window
+	}
 	return nil
 }
 
+func (f *fakeRoleRepository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]entity.UserRole, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	return false, nil
+}
+
 func (f *fakeRoleRepository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
 	return nil
 }
@@ -198,7 +282,24 @@ func (f *fakeRoleRepository) GetRoleCodesByUserID(ctx context.Context, userID st
 }
 
 func (f *fakeRoleRepository) GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
-	return map[string][]string{}, nil
+	result := map[string][]string{}
+	for _, roleID := range roleIDs {
+		permissions, _ := f.GetPermissionsByRoleID(ctx, roleID)
+		for _, permission := range permissions {
+			result[roleID] = append(result[roleID], permission.Resource+":"+permission.Action)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	result := map[string][]string{}
+	for _, roleID := range roleIDs {
+		for _, permission := range f.permissionsByRole[roleID] {
+			result[roleID] = append(result[roleID], permission.Resource+":"+permission.Action)
+		}
+	}
+	return result, nil
 }
 
 func (f *fakeRoleRepository) GetRoleCodesGroupedByAppByUserIDs(ctx context.Context, userIDs []string) (map[string][]models.UserAppRole, error) {
@@ -214,7 +315,7 @@ func (f *fakeUserRepository) Create(ctx context.Context, req userModels.CreateRe
 }
 
 func (f *fakeUserRepository) GetByID(ctx context.Context, id string) (userEntity.User, error) {
-	return userEntity.User{}, nil
+	return userEntity.User{ID: id}, nil
 }
 
 func (f *fakeUserRepository) GetByEmail(ctx context.Context, email string) (userEntity.User, error) {
@@ -292,12 +393,32 @@ func (f *fakeAppServiceRepository) UpdateStatus(ctx context.Context, id string,
 const testAppID = "app_test"
 
 func newTestUsecase(fakeRole *fakeRoleRepository) IUseCase {
+	return newTestUsecaseWithActivity(fakeRole, &fakeActivityUsecase{})
+}
+
+func newTestUsecaseWithActivity(fakeRole *fakeRoleRepository, activity *fakeActivityUsecase) IUseCase {
 	fakeAppService := &fakeAppServiceRepository{
 		appServicesByID: map[string]appServiceEntity.AppService{
 			testAppID: {ID: testAppID, AppCode: "test"},
 		},
 	}
-	return NewUsecase(fakeRole, &fakeUserRepository{}, fakeAppService)
+	return NewUsecase(fakeRole, &fakeUserRepository{}, fakeAppService, activity, transaction.NoopRunner{})
+}
+
+// rollbackRunner stands in for the transaction around SetIncludes: when fn
+// fails, the fake includes are restored to their state before Run, the way a
+// real rollback discards the rewritten rows.
+type rollbackRunner struct {
+	roles *fakeRoleRepository
+}
+
+func (r rollbackRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := maps.Clone(r.roles.includes)
+	if err := fn(ctx); err != nil {
+		r.roles.includes = snapshot
+		return err
+	}
+	return nil
 }
 
 // === Tests ===
@@ -329,6 +450,13 @@ func TestSystemRoleImmutability(t *testing.T) {
 			},
 			wantErr: "system role cannot be modified",
 		},
+		{
+			name: "set includes rejected",
+			operation: func(u IUseCase, ctx context.Context) error {
+				return u.SetIncludes(ctx, "rol_admin", models.SetIncludesRequest{RoleIDs: []string{"rol_viewer"}})
+			},
+			wantErr: "system role cannot be modified",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -342,7 +470,7 @@ func TestSystemRoleImmutability(t *testing.T) {
 			if err.Error() != tt.wantErr {
 				t.Errorf("error = %q, want %q", err.Error(), tt.wantErr)
 			}
-			if len(fakeRole.updatedIDs) != 0 || len(fakeRole.deletedIDs) != 0 || len(fakeRole.replacedPermissions) != 0 {
+			if len(fakeRole.updatedIDs) != 0 || len(fakeRole.deletedIDs) != 0 || len(fakeRole.replacedPermissions) != 0 || len(fakeRole.includes) != 0 {
 				t.Error("repository was mutated for a system role")
 			}
 		})
@@ -416,7 +544,155 @@ func TestCreateRejectsCrossAppClone(t *testing.T) {
 	}
 }
 
-func TestProvisionDefaultRolesSeedsEmptyAdminRole(t *testing.T) {
+func TestSetIncludesRejectsCycle(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	for _, code := range []string{"viewer", "editor", "owner"} {
+		fakeRole.rolesByID["rol_"+code] = entity.Role{ID: "rol_" + code, AppID: testAppID, Code: code, Name: code}
+	}
+	// owner -> editor -> viewer
+	fakeRole.includes["rol_owner"] = []string{"rol_editor"}
+	fakeRole.includes["rol_editor"] = []string{"rol_viewer"}
+	fakeAppService := &fakeAppServiceRepository{
+		appServicesByID: map[string]appServiceEntity.AppService{testAppID: {ID: testAppID, AppCode: "test"}},
+	}
+	u := NewUsecase(fakeRole, &fakeUserRepository{}, fakeAppService, &fakeActivityUsecase{}, rollbackRunner{roles: fakeRole})
+
+	err := u.SetIncludes(context.Background(), "rol_viewer", models.SetIncludesRequest{RoleIDs: []string{"rol_owner"}})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("error = %v, want a cycle rejection", err)
+	}
+	err = u.SetIncludes(context.Background(), "rol_viewer", models.SetIncludesRequest{RoleIDs: []string{"rol_viewer"}})
+	if err == nil || !strings.Contains(err.Error(), "itself") {
+		t.Fatalf("error = %v, want a self-include rejection", err)
+	}
+	if len(fakeRole.includes["rol_viewer"]) != 0 {
+		t.Errorf("includes = %v, want the rejected sets rolled back", fakeRole.includes["rol_viewer"])
+	}
+}
+
+func TestSetIncludesRejectsCrossApp(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.rolesByID["rol_editor"] = entity.Role{ID: "rol_editor", AppID: testAppID, Code: "editor", Name: "Editor"}
+	fakeRole.rolesByID["rol_other"] = entity.Role{ID: "rol_other", AppID: "app_other", Code: "other", Name: "Other"}
+
+	err := newTestUsecase(fakeRole).SetIncludes(context.Background(), "rol_editor", models.SetIncludesRequest{RoleIDs: []string{"rol_other"}})
+	if err == nil || !strings.Contains(err.Error(), "same app") {
+		t.Fatalf("error = %v, want a same-app rejection", err)
+	}
+	if _, written := fakeRole.includes["rol_editor"]; written {
+		t.Error("includes were replaced despite the cross-app rejection")
+	}
+}
+
+func TestSetIncludesRecordsAudit(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.rolesByID["rol_viewer"] = entity.Role{ID: "rol_viewer", AppID: testAppID, Code: "viewer", Name: "Viewer"}
+	fakeRole.rolesByID["rol_editor"] = entity.Role{ID: "rol_editor", AppID: testAppID, Code: "editor", Name: "Editor"}
+	activity := &fakeActivityUsecase{}
+
+	err := newTestUsecaseWithActivity(fakeRole, activity).SetIncludes(context.Background(), "rol_editor", models.SetIncludesRequest{RoleIDs: []string{"rol_viewer"}})
+	if err != nil {
+		t.Fatalf("SetIncludes: %v", err)
+	}
+	if got := fakeRole.includes["rol_editor"]; !slices.Equal(got, []string{"rol_viewer"}) {
+		t.Errorf("includes = %v, want [rol_viewer]", got)
+	}
+	if len(activity.auditEntries) != 1 || activity.auditEntries[0].Type != activityConstants.ActivityTypeRoleIncludesSet {
+		t.Fatalf("audit entries = %+v, want one role_includes_set", activity.auditEntries)
+	}
+}
+
+// A time-bound membership reaches the repository as a parsed UTC window, and
+// the audit row carries the bounds the admin asked for.
+func TestAddMembersStoresWindow(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.rolesByID["rol_oncall"] = entity.Role{ID: "rol_oncall", AppID: testAppID, Code: "oncall"}
+	activity := &fakeActivityUsecase{}
+	expiresAt := time.Now().Add(8 * time.Hour).UTC().Truncate(time.Second)
+
+	req := models.AddMembersRequest{UserIDs: []string{"usr_1"}, ExpiresAt: expiresAt.Format(time.RFC3339)}
+	if err := newTestUsecaseWithActivity(fakeRole, activity).AddMembers(context.Background(), "rol_oncall", req); err != nil {
+		t.Fatalf("AddMembers: %v", err)
+	}
+	window := fakeRole.memberWindows["rol_oncall/usr_1"]
+	if window.StartsAt != nil {
+		t.Errorf("starts_at = %v, want open", window.StartsAt)
+	}
+	if window.ExpiresAt == nil || !window.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("expires_at = %v, want %v", window.ExpiresAt, expiresAt)
+	}
+	if len(activity.auditEntries) != 1 || activity.auditEntries[0].Type != activityConstants.ActivityTypeRoleMembersAdded {
+		t.Fatalf("audit entries = %+v, want one role_members_added", activity.auditEntries)
+	}
+	if after, ok := activity.auditEntries[0].After.(models.AddMembersRequest); !ok || after.ExpiresAt != req.ExpiresAt {
+		t.Errorf("audit after = %+v, want the requested expires_at", activity.auditEntries[0].After)
+	}
+}
+
+func TestAddMembersRejectsBadWindow(t *testing.T) {
+	now := time.Now().UTC()
+	cases := []struct {
+		name string
+		req  models.AddMembersRequest
+	}{
+		{"expired", models.AddMembersRequest{UserIDs: []string{"usr_1"}, ExpiresAt: now.Add(-time.Minute).Format(time.RFC3339)}},
+		{"inverted", models.AddMembersRequest{UserIDs: []string{"usr_1"}, StartsAt: now.Add(2 * time.Hour).Format(time.RFC3339), ExpiresAt: now.Add(time.Hour).Format(time.RFC3339)}},
+		{"malformed", models.AddMembersRequest{UserIDs: []string{"usr_1"}, ExpiresAt: "tomorrow"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeRole := newFakeRoleRepository()
+			fakeRole.rolesByID["rol_oncall"] = entity.Role{ID: "rol_oncall", AppID: testAppID, Code: "oncall"}
+
+			err := newTestUsecase(fakeRole).AddMembers(context.Background(), "rol_oncall", tc.req)
+			if err == nil {
+				t.Fatal("expected the window to be rejected")
+			}
+			if len(fakeRole.memberWindows) != 0 {
+				t.Errorf("members added despite a rejected window: %v", fakeRole.memberWindows)
+			}
+		})
+	}
+}
+
+// The detail lists the role's own grants apart from the ones it only inherits,
+// each inherited permission naming the direct include(s) it comes through.
+func TestGetDetailSplitsInheritedPermissions(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	for _, code := range []string{"viewer", "editor", "owner"} {
+		fakeRole.rolesByID["rol_"+code] = entity.Role{ID: "rol_" + code, AppID: testAppID, Code: code, Name: code}
+	}
+	read := entity.Permission{ID: 1, AppID: testAppID, Resource: "file", Action: "read"}
+	write := entity.Permission{ID: 2, AppID: testAppID, Resource: "file", Action: "write"}
+	share := entity.Permission{ID: 3, AppID: testAppID, Resource: "file", Action: "share"}
+	fakeRole.permissionsByRole["rol_viewer"] = []entity.Permission{read}
+	fakeRole.permissionsByRole["rol_editor"] = []entity.Permission{write}
+	fakeRole.permissionsByRole["rol_owner"] = []entity.Permission{write, share}
+	// owner -> editor -> viewer
+	fakeRole.includes["rol_owner"] = []string{"rol_editor"}
+	fakeRole.includes["rol_editor"] = []string{"rol_viewer"}
+
+	detail, err := newTestUsecase(fakeRole).GetDetail(context.Background(), "rol_owner")
+	if err != nil {
+		t.Fatalf("GetDetail: %v", err)
+	}
+	if len(detail.Permissions) != 2 {
+		t.Errorf("direct permissions = %+v, want write and share", detail.Permissions)
+	}
+	if len(detail.Includes) != 1 || detail.Includes[0].Code != "editor" {
+		t.Errorf("includes = %+v, want [editor]", detail.Includes)
+	}
+	// write is granted directly too, so only read is inherited
+	if len(detail.InheritedPermissions) != 1 {
+		t.Fatalf("inherited = %+v, want only file:read", detail.InheritedPermissions)
+	}
+	inherited := detail.InheritedPermissions[0]
+	if inherited.ID != read.ID || !slices.Equal(inherited.Via, []string{"editor"}) {
+		t.Errorf("inherited = %+v, want file:read via editor", inherited)
+	}
+}
+
+func TestProvisionDefaultRolesSeedsApprovalPermissionOnly(t *testing.T) {
 	fakeRole := newFakeRoleRepository()
 	fakeRole.createID = "rol_admin_new"
 
@@ -425,12 +701,14 @@ func TestProvisionDefaultRolesSeedsEmptyAdminRole(t *testing.T) {
 		t.Fatalf("ProvisionDefaultRoles() error = %v", err)
 	}
 
-	// the admin role is created with ZERO permissions — none are seeded on app create
-	if len(fakeRole.replacedPermissions) != 0 {
-		t.Error("expected no permissions to be seeded for the admin role")
+	// the only permission seeded on app create is the reserved approval one,
+	// granted to the admin role so the app has an access-request approver
+	created := fakeRole.createdPermissions[testAppID]
+	if len(created) != 1 || created[0].Resource+":"+created[0].Action != roleConstants.PERM_ACCESS_REQUEST_APPROVE {
+		t.Fatalf("created permissions = %+v, want only access_request:approve", created)
 	}
-	if len(fakeRole.createdPermissions) != 0 {
-		t.Error("expected no permissions to be created on app provisioning")
+	if got := fakeRole.replacedPermissions["rol_admin_new"]; !slices.Equal(got, []int64{created[0].ID}) {
+		t.Errorf("admin grants = %v, want [%d]", got, created[0].ID)
 	}
 }
 
@@ -603,6 +881,19 @@ func TestDeletePermissionRejectsIsmeSystemApp(t *testing.T) {
 	}
 }
 
+func TestDeletePermissionRejectsReservedApprovalPermission(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.permissionsByID[9] = entity.Permission{ID: 9, AppID: testAppID, Resource: "access_request", Action: "approve"}
+
+	err := newTestUsecase(fakeRole).DeletePermission(context.Background(), 9)
+	if err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Fatalf("error = %v, want the approval permission to be reserved", err)
+	}
+	if len(fakeRole.deletedPermissionIDs) != 0 {
+		t.Error("the reserved approval permission was deleted")
+	}
+}
+
 func TestDeletePermissionNotFound(t *testing.T) {
 	fakeRole := newFakeRoleRepository()
 
@@ -618,6 +909,98 @@ func TestDeletePermissionNotFound(t *testing.T) {
 	}
 }
 
+func TestSyncPermissionsFlagsInsteadOfDeleting(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	activity := &fakeActivityUsecase{}
+	usecase := newTestUsecaseWithActivity(fakeRole, activity)
+	if _, err := usecase.CreatePermissions(context.Background(), models.CreatePermissionsRequest{
+		AppID: testAppID,
+		Permissions: []models.PermissionPair{
+			{Resource: "report", Action: "read"},
+			{Resource: "report", Action: "export"},
+		},
+	}); err != nil {
+		t.Fatalf("seed CreatePermissions() error = %v", err)
+	}
+	activity.auditEntries = nil
+
+	res, err := usecase.SyncPermissions(context.Background(), testAppID, models.SyncPermissionsRequest{
+		Permissions: []models.PermissionPair{
+			{Resource: "report", Action: "read"},
+			{Resource: "report", Action: "share"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SyncPermissions() error = %v", err)
+	}
+	if !slices.Equal(res.Created, []string{"report:share"}) || !slices.Equal(res.Flagged, []string{"report:export"}) || res.Unchanged != 1 {
+		t.Fatalf("sync = %+v", res)
+	}
+	if len(fakeRole.deletedPermissionIDs) != 0 {
+		t.Errorf("deleted %v, want omitted permissions flagged, not deleted", fakeRole.deletedPermissionIDs)
+	}
+	flagged, _ := usecase.ListPermissions(context.Background(), models.ListPermissionsRequest{AppID: testAppID, Flagged: true})
+	if len(flagged) != 1 || flagged[0].Action != "export" || flagged[0].FlaggedAt == "" {
+		t.Fatalf("flagged = %+v, want report:export with flagged_at", flagged)
+	}
+	if len(activity.auditEntries) != 1 || activity.auditEntries[0].Type != activityConstants.ActivityTypePermissionsSynced {
+		t.Fatalf("audit = %+v, want one permissions_synced entry", activity.auditEntries)
+	}
+
+	// listing the permission again restores it; an unchanged sync is not audited
+	res, err = usecase.SyncPermissions(context.Background(), testAppID, models.SyncPermissionsRequest{
+		Permissions: []models.PermissionPair{
+			{Resource: "report", Action: "read"},
+			{Resource: "report", Action: "export"},
+			{Resource: "report", Action: "share"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SyncPermissions() error = %v", err)
+	}
+	if !slices.Equal(res.Restored, []string{"report:export"}) || len(res.Created) != 0 || len(fakeRole.flaggedAt) != 0 {
+		t.Fatalf("restore sync = %+v, flagged = %v", res, fakeRole.flaggedAt)
+	}
+	if _, err := usecase.SyncPermissions(context.Background(), testAppID, models.SyncPermissionsRequest{
+		Permissions: []models.PermissionPair{
+			{Resource: "report", Action: "read"},
+			{Resource: "report", Action: "export"},
+			{Resource: "report", Action: "share"},
+		},
+	}); err != nil {
+		t.Fatalf("SyncPermissions() error = %v", err)
+	}
+	if len(activity.auditEntries) != 2 {
+		t.Errorf("audit entries = %d, want the no-op sync left unaudited", len(activity.auditEntries))
+	}
+}
+
+func TestSyncPermissionsNeverFlagsApprovalPermission(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.createID = "rol_admin_new"
+	usecase := newTestUsecase(fakeRole)
+	if err := usecase.ProvisionDefaultRoles(context.Background(), testAppID); err != nil {
+		t.Fatalf("ProvisionDefaultRoles() error = %v", err)
+	}
+
+	res, err := usecase.SyncPermissions(context.Background(), testAppID, models.SyncPermissionsRequest{
+		Permissions: []models.PermissionPair{{Resource: "report", Action: "read"}},
+	})
+	if err != nil {
+		t.Fatalf("SyncPermissions() error = %v", err)
+	}
+	if len(res.Flagged) != 0 || len(fakeRole.flaggedAt) != 0 {
+		t.Fatalf("sync = %+v, want access_request:approve left unflagged", res)
+	}
+}
+
+func TestSyncPermissionsRejectsIsmeSystemApp(t *testing.T) {
+	_, err := newTestUsecase(newFakeRoleRepository()).SyncPermissions(context.Background(), roleConstants.APP_ID_ISME, models.SyncPermissionsRequest{})
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("error = %v, want the isme catalog to be read-only", err)
+	}
+}
+
 func TestCreatePermissionsValidation(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -731,7 +1114,7 @@ func TestUpdatePermissionAppearanceRejectsIsmeByCode(t *testing.T) {
 			"app_alias": {ID: "app_alias", AppCode: roleConstants.APP_CODE_ISME},
 		},
 	}
-	uc := NewUsecase(fakeRole, &fakeUserRepository{}, fakeAppService)
+	uc := NewUsecase(fakeRole, &fakeUserRepository{}, fakeAppService, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	err := uc.UpdatePermissionAppearance(context.Background(), models.UpdatePermissionAppearanceRequest{
 		AppID:    "app_alias",
@@ -838,3 +1221,40 @@ func TestCreateCodeSlugValidation(t *testing.T) {
 		})
 	}
 }
+
+// Updating a role records a role_updated audit entry whose before/after carry
+// the previous and requested editable fields.
+func TestUpdateRecordsAudit(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.rolesByID["rol_custom"] = entity.Role{ID: "rol_custom", AppID: testAppID, Code: "support", Name: "Support"}
+	activity := &fakeActivityUsecase{}
+
+	err := newTestUsecaseWithActivity(fakeRole, activity).Update(context.Background(), "rol_custom", models.UpdateRequest{Name: "Support Team"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(activity.auditEntries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(activity.auditEntries))
+	}
+	entry := activity.auditEntries[0]
+	if entry.Type != activityConstants.ActivityTypeRoleUpdated || entry.TargetID != "rol_custom" {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+	before, ok := entry.Before.(models.UpdateRequest)
+	if !ok || before.Name != "Support" {
+		t.Errorf("expected before snapshot with the old name, got %+v", entry.Before)
+	}
+}
+
+// A failed audit write fails the mutation, so the surrounding transaction rolls
+// the change back instead of committing it unaudited.
+func TestAuditFailureFailsMutation(t *testing.T) {
+	fakeRole := newFakeRoleRepository()
+	fakeRole.rolesByID["rol_custom"] = entity.Role{ID: "rol_custom", AppID: testAppID, Code: "support", Name: "Support"}
+	activity := &fakeActivityUsecase{auditErr: errors.New("audit unavailable")}
+
+	err := newTestUsecaseWithActivity(fakeRole, activity).Delete(context.Background(), "rol_custom")
+	if err == nil {
+		t.Fatal("expected the audit failure to fail the delete, got nil")
+	}
+}
@@ -12,10 +12,11 @@ import (
 // the settings repository/usecase, and the scheduler's JobKey consts, so the
 // "session_revoke"/"rotation_cleanup" strings are never scattered as literals.
 const (
-	JobKeySessionRevoke   = "session_revoke"
-	JobKeyRotationCleanup = "rotation_cleanup"
-	JobKeyActivityCleanup = "activity_cleanup"
-	JobKeyDatabaseBackup  = "database_backup"
+	JobKeySessionRevoke    = "session_revoke"
+	JobKeyRotationCleanup  = "rotation_cleanup"
+	JobKeyActivityCleanup  = "activity_cleanup"
+	JobKeyDatabaseBackup   = "database_backup"
+	JobKeyRoleMemberExpiry = "role_member_expiry"
 )
 
 // ScheduleConfig is the generic, job-keyed config that drives every scheduled
@@ -3,6 +3,7 @@ package handlers
 import (
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/settings/models"
+	"github.com/vukyn/isme/internal/tracing"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgHttp "github.com/vukyn/kuery/http/fiber"
@@ -19,7 +20,7 @@ func GetSessionRevokeConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	getResponse, err := uc.Get(pkgCtx.NewContextFromFiberCtx(c))
+	getResponse, err := uc.Get(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -41,7 +42,7 @@ func UpdateSessionRevokeConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.Update(pkgCtx.NewContextFromFiberCtx(c), updateRequest); err != nil {
+	if err := uc.Update(tracing.NewContextFromFiberCtx(c), updateRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -57,7 +58,7 @@ func GetRotationCleanupConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	getResponse, err := uc.GetRotationCleanup(pkgCtx.NewContextFromFiberCtx(c))
+	getResponse, err := uc.GetRotationCleanup(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -79,7 +80,7 @@ func UpdateRotationCleanupConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdateRotationCleanup(pkgCtx.NewContextFromFiberCtx(c), updateRequest); err != nil {
+	if err := uc.UpdateRotationCleanup(tracing.NewContextFromFiberCtx(c), updateRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -95,7 +96,7 @@ func GetActivityCleanupConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	getResponse, err := uc.GetActivityCleanup(pkgCtx.NewContextFromFiberCtx(c))
+	getResponse, err := uc.GetActivityCleanup(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -117,7 +118,7 @@ func UpdateActivityCleanupConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdateActivityCleanup(pkgCtx.NewContextFromFiberCtx(c), updateRequest); err != nil {
+	if err := uc.UpdateActivityCleanup(tracing.NewContextFromFiberCtx(c), updateRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -133,7 +134,7 @@ func GetDatabaseBackupConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	getResponse, err := uc.GetDatabaseBackup(pkgCtx.NewContextFromFiberCtx(c))
+	getResponse, err := uc.GetDatabaseBackup(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -155,7 +156,7 @@ func UpdateDatabaseBackupConfig(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdateDatabaseBackup(pkgCtx.NewContextFromFiberCtx(c), updateRequest); err != nil {
+	if err := uc.UpdateDatabaseBackup(tracing.NewContextFromFiberCtx(c), updateRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -5,6 +5,8 @@ import (
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/domains/settings/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/vukyn/kuery/rbac"
 
@@ -24,3 +26,24 @@ func SetupSettingsRoutes(router fiber.Router) {
 	rSettings.Get(constants.SETTINGS_ENDPOINT_DATABASE_BACKUP, rbac.RequirePermission(roleConstants.PERM_SETTINGS_READ), GetDatabaseBackupConfig)
 	rSettings.Put(constants.SETTINGS_ENDPOINT_DATABASE_BACKUP, rbac.RequirePermission(roleConstants.PERM_SETTINGS_UPDATE), UpdateDatabaseBackupConfig)
 }
+
+// Operations documents the routes registered by SetupSettingsRoutes; keep the
+// two in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_SESSION_REVOKE, Tag: "settings", Summary: "Get the session revocation policy",
+		Response: models.GetResponse{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_READ},
+	{Method: fiber.MethodPut, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_SESSION_REVOKE, Tag: "settings", Summary: "Update the session revocation policy",
+		Body: models.UpdateRequest{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_UPDATE},
+	{Method: fiber.MethodGet, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_ROTATION_CLEANUP, Tag: "settings", Summary: "Get the rotated-token cleanup schedule",
+		Response: models.RotationCleanupGetResponse{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_READ},
+	{Method: fiber.MethodPut, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_ROTATION_CLEANUP, Tag: "settings", Summary: "Update the rotated-token cleanup schedule",
+		Body: models.RotationCleanupUpdateRequest{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_UPDATE},
+	{Method: fiber.MethodGet, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_ACTIVITY_CLEANUP, Tag: "settings", Summary: "Get the activity retention schedule",
+		Response: models.ActivityCleanupGetResponse{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_READ},
+	{Method: fiber.MethodPut, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_ACTIVITY_CLEANUP, Tag: "settings", Summary: "Update the activity retention schedule",
+		Body: models.ActivityCleanupUpdateRequest{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_UPDATE},
+	{Method: fiber.MethodGet, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_DATABASE_BACKUP, Tag: "settings", Summary: "Get the database backup schedule",
+		Response: models.DatabaseBackupGetResponse{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_READ},
+	{Method: fiber.MethodPut, Path: constants.SETTINGS_GROUP_NAME + constants.SETTINGS_ENDPOINT_DATABASE_BACKUP, Tag: "settings", Summary: "Update the database backup schedule",
+		Body: models.DatabaseBackupUpdateRequest{}, Auth: true, Permission: roleConstants.PERM_SETTINGS_UPDATE},
+}
@@ -22,6 +22,15 @@ type DatabaseBackupGetResponse struct {
 	LastRunAt      *int64  `json:"last_run_at"`
 	LastBackupPath *string `json:"last_backup_path"`
 	LastKeptCount  *int64  `json:"last_kept_count"`
+	// LastRemote* report the off-host upload of the last run; they stay null
+	// when no backup store is configured.
+	LastRemoteUploaded *bool   `json:"last_remote_uploaded"`
+	LastRemoteKey      *string `json:"last_remote_key"`
+	LastRemoteError    *string `json:"last_remote_error"`
+	// LastEncrypted reports whether the last snapshot was sealed with the
+	// backup key identified by LastKeyID.
+	LastEncrypted *bool   `json:"last_encrypted"`
+	LastKeyID     *string `json:"last_key_id"`
 }
 
 // DatabaseBackupUpdateRequest sets the database-backup schedule and the number
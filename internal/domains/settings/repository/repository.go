@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/vukyn/isme/internal/domains/settings/entity"
+	"github.com/vukyn/isme/internal/transaction"
 
 	"github.com/uptrace/bun"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -24,7 +25,7 @@ func NewRepository(
 
 func (r *repository) GetSchedule(ctx context.Context, jobKey string) (entity.ScheduleConfig, error) {
 	config := entity.ScheduleConfig{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&config).
 		Where("job_key = ?", jobKey).
 		Scan(ctx)
@@ -46,7 +47,7 @@ func (r *repository) UpdateSchedule(ctx context.Context, jobKey string, enabled
 		UpdatedBy: updatedBy,
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(&config).
 		Column("enabled", "cron", "params", "updated_at", "updated_by").
 		Where("job_key = ?", jobKey).
@@ -64,7 +65,7 @@ func (r *repository) RecordScheduleRun(ctx context.Context, jobKey string, ranAt
 		LastResult: &result,
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(&config).
 		Column("last_run_at", "last_result", "updated_at").
 		Where("job_key = ?", jobKey).
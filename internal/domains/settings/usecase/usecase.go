@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
 	"github.com/vukyn/isme/internal/domains/settings/entity"
 	"github.com/vukyn/isme/internal/domains/settings/models"
 	settingsRepo "github.com/vukyn/isme/internal/domains/settings/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -52,23 +56,81 @@ type databaseBackupResult struct {
 	Kept       int64  `json:"kept"`
 	Deleted    int64  `json:"deleted"`
 	Bytes      int64  `json:"bytes"`
+	// Remote* are set only when an off-host store is configured.
+	RemoteUploaded *bool  `json:"remote_uploaded"`
+	RemoteKey      string `json:"remote_key"`
+	RemoteError    string `json:"remote_error"`
+	// Encrypted is absent from results recorded before backup encryption.
+	Encrypted *bool  `json:"encrypted"`
+	KeyID     string `json:"key_id"`
 }
 
 type usecase struct {
-	settingsRepo settingsRepo.IRepository
-	reloader     pkgScheduler.IReloader
+	settingsRepo    settingsRepo.IRepository
+	reloader        pkgScheduler.IReloader
+	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
 }
 
 func NewUsecase(
 	settingsRepo settingsRepo.IRepository,
 	reloader pkgScheduler.IReloader,
+	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
 ) IUseCase {
 	return &usecase{
-		settingsRepo: settingsRepo,
-		reloader:     reloader,
+		settingsRepo:    settingsRepo,
+		reloader:        reloader,
+		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
 	}
 }
 
+// updateSchedule persists a job's schedule config and records a
+// schedule_updated audit row (before/after of enabled, cron and params) in one
+// transaction. The caller reloads the scheduler AFTER this returns: the reload
+// reads the config back through the DB, which must see the committed row and
+// must not contend with the still-open transaction.
+func (u *usecase) updateSchedule(ctx context.Context, jobKey string, enabled bool, cron string, params string) error {
+	current, err := u.settingsRepo.GetSchedule(ctx, jobKey)
+	if err != nil {
+		return err
+	}
+
+	updatedBy := pkgCtx.GetUserID(ctx)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.settingsRepo.UpdateSchedule(ctx, jobKey, enabled, cron, params, updatedBy); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeScheduleUpdated,
+			TargetType: activityConstants.TargetTypeSchedule,
+			TargetID:   jobKey,
+			Before:     scheduleSnapshot(current.Enabled, current.Cron, current.Params),
+			After:      scheduleSnapshot(enabled, cron, params),
+		})
+	})
+}
+
+// scheduleSnapshot is the audit view of a schedule row. params is decoded so
+// the diff names the individual changed knob (e.g. retention_days) rather than
+// the whole JSON string; a malformed blob is kept verbatim.
+func scheduleSnapshot(enabled bool, cron string, params string) map[string]any {
+	snapshot := map[string]any{
+		"enabled": enabled,
+		"cron":    cron,
+	}
+	decoded := map[string]any{}
+	if params != "" && json.Unmarshal([]byte(params), &decoded) != nil {
+		snapshot["params"] = params
+		return snapshot
+	}
+	for key, value := range decoded {
+		snapshot[key] = value
+	}
+	return snapshot
+}
+
 func (u *usecase) Get(ctx context.Context) (models.GetResponse, error) {
 	config, err := u.settingsRepo.GetSchedule(ctx, entity.JobKeySessionRevoke)
 	if err != nil {
@@ -99,8 +161,7 @@ func (u *usecase) Update(ctx context.Context, req models.UpdateRequest) error {
 		return pkgErr.InvalidRequest(err.Error())
 	}
 
-	updatedBy := pkgCtx.GetUserID(ctx)
-	if err := u.settingsRepo.UpdateSchedule(ctx, entity.JobKeySessionRevoke, req.Enabled, req.Cron, emptyParams, updatedBy); err != nil {
+	if err := u.updateSchedule(ctx, entity.JobKeySessionRevoke, req.Enabled, req.Cron, emptyParams); err != nil {
 		return err
 	}
 
@@ -150,8 +211,7 @@ func (u *usecase) UpdateRotationCleanup(ctx context.Context, req models.Rotation
 		return pkgErr.InternalServerError(err.Error())
 	}
 
-	updatedBy := pkgCtx.GetUserID(ctx)
-	if err := u.settingsRepo.UpdateSchedule(ctx, entity.JobKeyRotationCleanup, req.Enabled, req.Cron, string(params), updatedBy); err != nil {
+	if err := u.updateSchedule(ctx, entity.JobKeyRotationCleanup, req.Enabled, req.Cron, string(params)); err != nil {
 		return err
 	}
 
@@ -203,8 +263,7 @@ func (u *usecase) UpdateActivityCleanup(ctx context.Context, req models.Activity
 		return pkgErr.InternalServerError(err.Error())
 	}
 
-	updatedBy := pkgCtx.GetUserID(ctx)
-	if err := u.settingsRepo.UpdateSchedule(ctx, entity.JobKeyActivityCleanup, req.Enabled, req.Cron, string(params), updatedBy); err != nil {
+	if err := u.updateSchedule(ctx, entity.JobKeyActivityCleanup, req.Enabled, req.Cron, string(params)); err != nil {
 		return err
 	}
 
@@ -244,6 +303,15 @@ func (u *usecase) GetDatabaseBackup(ctx context.Context) (models.DatabaseBackupG
 		response.LastBackupPath = &backupPath
 		kept := result.Kept
 		response.LastKeptCount = &kept
+		if result.RemoteUploaded != nil {
+			response.LastRemoteUploaded = result.RemoteUploaded
+			response.LastRemoteKey = &result.RemoteKey
+			response.LastRemoteError = &result.RemoteError
+		}
+		response.LastEncrypted = result.Encrypted
+		if result.KeyID != "" {
+			response.LastKeyID = &result.KeyID
+		}
 	}
 	return response, nil
 }
@@ -258,8 +326,7 @@ func (u *usecase) UpdateDatabaseBackup(ctx context.Context, req models.DatabaseB
 		return pkgErr.InternalServerError(err.Error())
 	}
 
-	updatedBy := pkgCtx.GetUserID(ctx)
-	if err := u.settingsRepo.UpdateSchedule(ctx, entity.JobKeyDatabaseBackup, req.Enabled, req.Cron, string(params), updatedBy); err != nil {
+	if err := u.updateSchedule(ctx, entity.JobKeyDatabaseBackup, req.Enabled, req.Cron, string(params)); err != nil {
 		return err
 	}
 
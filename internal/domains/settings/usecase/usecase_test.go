@@ -2,11 +2,14 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
 	"github.com/vukyn/isme/internal/domains/settings/entity"
 	"github.com/vukyn/isme/internal/domains/settings/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgScheduler "github.com/vukyn/kuery/scheduler"
 )
@@ -87,7 +90,7 @@ func (f *fakeReloader) Reload(ctx context.Context, jobKey pkgScheduler.JobKey, e
 func TestUpdatePersistsAndReloads(t *testing.T) {
 	repo := newFakeRepo()
 	reloader := &fakeReloader{}
-	uc := NewUsecase(repo, reloader)
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	req := models.UpdateRequest{Enabled: true, Cron: "0 3 * * *"}
 	if err := uc.Update(context.Background(), req); err != nil {
@@ -120,7 +123,7 @@ func TestUpdatePersistsAndReloads(t *testing.T) {
 func TestUpdateRejectsBadCron(t *testing.T) {
 	repo := newFakeRepo()
 	reloader := &fakeReloader{}
-	uc := NewUsecase(repo, reloader)
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	req := models.UpdateRequest{Enabled: true, Cron: "bogus"}
 	if err := uc.Update(context.Background(), req); err == nil {
@@ -146,7 +149,7 @@ func TestGetMapsConfig(t *testing.T) {
 		LastRunAt:  &ranAt,
 		LastResult: &lastResult,
 	}
-	uc := NewUsecase(repo, &fakeReloader{})
+	uc := NewUsecase(repo, &fakeReloader{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	resp, err := uc.Get(context.Background())
 	if err != nil {
@@ -166,7 +169,7 @@ func TestGetMapsConfig(t *testing.T) {
 func TestUpdateRotationCleanupPersistsAndReloads(t *testing.T) {
 	repo := newFakeRepo()
 	reloader := &fakeReloader{}
-	uc := NewUsecase(repo, reloader)
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	req := models.RotationCleanupUpdateRequest{Enabled: true, Cron: "0 4 * * *", RetentionHours: 48}
 	if err := uc.UpdateRotationCleanup(context.Background(), req); err != nil {
@@ -199,7 +202,7 @@ func TestUpdateRotationCleanupPersistsAndReloads(t *testing.T) {
 func TestUpdateRotationCleanupRejectsLowRetention(t *testing.T) {
 	repo := newFakeRepo()
 	reloader := &fakeReloader{}
-	uc := NewUsecase(repo, reloader)
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	req := models.RotationCleanupUpdateRequest{Enabled: true, Cron: "0 4 * * *", RetentionHours: 12}
 	if err := uc.UpdateRotationCleanup(context.Background(), req); err == nil {
@@ -225,7 +228,7 @@ func TestGetRotationCleanupMapsConfig(t *testing.T) {
 		LastRunAt:  &ranAt,
 		LastResult: &lastResult,
 	}
-	uc := NewUsecase(repo, &fakeReloader{})
+	uc := NewUsecase(repo, &fakeReloader{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	resp, err := uc.GetRotationCleanup(context.Background())
 	if err != nil {
@@ -247,7 +250,7 @@ func TestGetRotationCleanupMapsConfig(t *testing.T) {
 // back identically via GetRotationCleanup (the new generic storage path).
 func TestRotationCleanupRoundTripsParamsThroughJSON(t *testing.T) {
 	repo := newFakeRepo()
-	uc := NewUsecase(repo, &fakeReloader{})
+	uc := NewUsecase(repo, &fakeReloader{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	if err := uc.UpdateRotationCleanup(context.Background(), models.RotationCleanupUpdateRequest{
 		Enabled: true, Cron: "0 4 * * *", RetentionHours: 96,
@@ -267,7 +270,7 @@ func TestRotationCleanupRoundTripsParamsThroughJSON(t *testing.T) {
 func TestUpdateActivityCleanupPersistsAndReloads(t *testing.T) {
 	repo := newFakeRepo()
 	reloader := &fakeReloader{}
-	uc := NewUsecase(repo, reloader)
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	req := models.ActivityCleanupUpdateRequest{Enabled: true, Cron: "0 5 * * *", RetentionDays: 90}
 	if err := uc.UpdateActivityCleanup(context.Background(), req); err != nil {
@@ -300,7 +303,7 @@ func TestUpdateActivityCleanupPersistsAndReloads(t *testing.T) {
 func TestUpdateActivityCleanupRejectsLowRetention(t *testing.T) {
 	repo := newFakeRepo()
 	reloader := &fakeReloader{}
-	uc := NewUsecase(repo, reloader)
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	req := models.ActivityCleanupUpdateRequest{Enabled: true, Cron: "0 5 * * *", RetentionDays: 3}
 	if err := uc.UpdateActivityCleanup(context.Background(), req); err == nil {
@@ -326,7 +329,7 @@ func TestGetActivityCleanupMapsConfig(t *testing.T) {
 		LastRunAt:  &ranAt,
 		LastResult: &lastResult,
 	}
-	uc := NewUsecase(repo, &fakeReloader{})
+	uc := NewUsecase(repo, &fakeReloader{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	resp, err := uc.GetActivityCleanup(context.Background())
 	if err != nil {
@@ -348,7 +351,7 @@ func TestGetActivityCleanupMapsConfig(t *testing.T) {
 // back identically via GetActivityCleanup.
 func TestActivityCleanupRoundTripsParamsThroughJSON(t *testing.T) {
 	repo := newFakeRepo()
-	uc := NewUsecase(repo, &fakeReloader{})
+	uc := NewUsecase(repo, &fakeReloader{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 	if err := uc.UpdateActivityCleanup(context.Background(), models.ActivityCleanupUpdateRequest{
 		Enabled: true, Cron: "0 5 * * *", RetentionDays: 365,
@@ -364,3 +367,50 @@ func TestActivityCleanupRoundTripsParamsThroughJSON(t *testing.T) {
 		t.Fatalf("retention did not round-trip through params JSON: got %d, want 365", resp.RetentionDays)
 	}
 }
+
+// A retention-only edit records a schedule_updated audit row whose before/after
+// carry the decoded params, so the diff names the changed knob.
+func TestUpdateActivityCleanupRecordsAudit(t *testing.T) {
+	repo := newFakeRepo()
+	repo.configs[entity.JobKeyActivityCleanup] = entity.ScheduleConfig{
+		JobKey:  entity.JobKeyActivityCleanup,
+		Enabled: true,
+		Cron:    "0 5 * * *",
+		Params:  `{"retention_days":90}`,
+	}
+	activity := &fakeActivityUsecase{}
+	uc := NewUsecase(repo, &fakeReloader{}, activity, transaction.NoopRunner{})
+
+	req := models.ActivityCleanupUpdateRequest{Enabled: true, Cron: "0 5 * * *", RetentionDays: 30}
+	if err := uc.UpdateActivityCleanup(context.Background(), req); err != nil {
+		t.Fatalf("UpdateActivityCleanup: %v", err)
+	}
+
+	if len(activity.auditEntries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(activity.auditEntries))
+	}
+	entry := activity.auditEntries[0]
+	if entry.Type != activityConstants.ActivityTypeScheduleUpdated || entry.TargetID != entity.JobKeyActivityCleanup {
+		t.Fatalf("unexpected audit entry %+v", entry)
+	}
+	before, _ := entry.Before.(map[string]any)
+	after, _ := entry.After.(map[string]any)
+	if before["retention_days"] != float64(90) || after["retention_days"] != float64(30) {
+		t.Fatalf("expected retention_days 90 -> 30, got before=%v after=%v", before, after)
+	}
+}
+
+// A failed audit write fails the update and skips the reload — the schedule
+// change is rolled back, so the engine must not pick it up either.
+func TestUpdateAuditFailureSkipsReload(t *testing.T) {
+	repo := newFakeRepo()
+	reloader := &fakeReloader{}
+	uc := NewUsecase(repo, reloader, &fakeActivityUsecase{auditErr: errors.New("audit unavailable")}, transaction.NoopRunner{})
+
+	if err := uc.Update(context.Background(), models.UpdateRequest{Enabled: true, Cron: "*/5 * * * *"}); err == nil {
+		t.Fatal("expected the audit failure to fail the update, got nil")
+	}
+	if reloader.called {
+		t.Fatal("expected no reload after a failed update")
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/user/models"
+	"github.com/vukyn/isme/internal/tracing"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgHttp "github.com/vukyn/kuery/http/fiber"
@@ -24,7 +25,7 @@ func ListUsers(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	listResponse, err := uc.List(pkgCtx.NewContextFromFiberCtx(c), listRequest)
+	listResponse, err := uc.List(tracing.NewContextFromFiberCtx(c), listRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -46,7 +47,7 @@ func UpdateUserStatus(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.UpdateStatus(pkgCtx.NewContextFromFiberCtx(c), c.Params("userID"), updateStatusRequest); err != nil {
+	if err := uc.UpdateStatus(tracing.NewContextFromFiberCtx(c), c.Params("userID"), updateStatusRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -62,7 +63,7 @@ func VerifyUser(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.VerifyUser(pkgCtx.NewContextFromFiberCtx(c), c.Params("userID")); err != nil {
+	if err := uc.VerifyUser(tracing.NewContextFromFiberCtx(c), c.Params("userID")); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -78,7 +79,7 @@ func DeleteUser(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.SoftDelete(pkgCtx.NewContextFromFiberCtx(c), c.Params("userID")); err != nil {
+	if err := uc.SoftDelete(tracing.NewContextFromFiberCtx(c), c.Params("userID")); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -94,7 +95,7 @@ func ListUserSessions(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	sessions, err := uc.ListSessions(pkgCtx.NewContextFromFiberCtx(c), c.Params("userID"))
+	sessions, err := uc.ListSessions(tracing.NewContextFromFiberCtx(c), c.Params("userID"))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -111,7 +112,7 @@ func RevokeUserSession(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.RevokeSession(pkgCtx.NewContextFromFiberCtx(c), c.Params("userID"), c.Params("sessionID")); err != nil {
+	if err := uc.RevokeSession(tracing.NewContextFromFiberCtx(c), c.Params("userID"), c.Params("sessionID")); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
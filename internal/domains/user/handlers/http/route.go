@@ -5,6 +5,8 @@ import (
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/domains/user/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/vukyn/kuery/rbac"
 
@@ -22,3 +24,20 @@ func SetupUserRoutes(router fiber.Router) {
 	rUser.Get(constants.USER_ENDPOINT_SESSIONS, rbac.RequirePermission(roleConstants.PERM_USER_SESSION_READ), ListUserSessions)
 	rUser.Post(constants.USER_ENDPOINT_SESSION_REVOKE, rbac.RequirePermission(roleConstants.PERM_USER_SESSION_REVOKE), RevokeUserSession)
 }
+
+// Operations documents the routes registered by SetupUserRoutes; keep the two
+// in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_ROOT, Tag: "users", Summary: "List users",
+		Query: models.ListRequest{}, Response: models.ListResponse{}, Auth: true, Permission: roleConstants.PERM_USER_READ},
+	{Method: fiber.MethodPatch, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_STATUS, Tag: "users", Summary: "Activate or deactivate a user",
+		Body: models.UpdateStatusRequest{}, Auth: true, Permission: roleConstants.PERM_USER_UPDATE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_VERIFY, Tag: "users", Summary: "Mark a user as verified",
+		Auth: true, Permission: roleConstants.PERM_USER_VERIFY, Errors: []int{fiber.StatusBadRequest, fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_DETAIL, Tag: "users", Summary: "Soft-delete a user",
+		Auth: true, Permission: roleConstants.PERM_USER_DELETE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_SESSIONS, Tag: "users", Summary: "List a user's sessions",
+		Response: []models.SessionItem{}, Auth: true, Permission: roleConstants.PERM_USER_SESSION_READ, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_SESSION_REVOKE, Tag: "users", Summary: "Revoke one of a user's sessions",
+		Auth: true, Permission: roleConstants.PERM_USER_SESSION_REVOKE, Errors: []int{fiber.StatusNotFound}},
+}
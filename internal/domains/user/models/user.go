@@ -60,6 +60,8 @@ type AppRole struct {
 	AppName  string `json:"app_name"`
 	RoleCode string `json:"role_code"`
 	RoleName string `json:"role_name"`
+	// Groups names the groups the role is inherited through.
+	Groups []string `json:"groups,omitempty"`
 }
 
 // UserListItem represents a user in the list response
@@ -9,6 +9,7 @@ import (
 	"github.com/vukyn/isme/internal/domains/user/constants"
 	"github.com/vukyn/isme/internal/domains/user/entity"
 	"github.com/vukyn/isme/internal/domains/user/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgErr "github.com/vukyn/kuery/http/errors"
 
@@ -37,7 +38,7 @@ func (r *repository) Create(ctx context.Context, req models.CreateRequest) (stri
 		Email:  req.Email,
 		Status: int32(constants.UserStatusActive),
 	}
-	_, err := r.db.NewInsert().
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
 		Model(user).
 		Exec(ctx)
 	if err != nil {
@@ -52,7 +53,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (entity.User, error
 	}
 
 	user := entity.User{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&user).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -74,7 +75,7 @@ func (r *repository) GetByEmail(ctx context.Context, email string) (entity.User,
 	}
 
 	user := entity.User{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&user).
 		Where("email = ?", email).
 		Scan(ctx)
@@ -100,7 +101,7 @@ func (r *repository) SetPassword(ctx context.Context, id string, password string
 		Password: cryp.HashArgon2id(password),
 	}
 	columns := []string{"password"}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(user).
 		Column(columns...).
 		Where("id = ?", id).
@@ -124,7 +125,7 @@ func (r *repository) UpdateProfile(ctx context.Context, id string, name string,
 		Name:      name,
 		AvatarURL: avatarURL,
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(user).
 		Column("name", "avatar_url").
 		Where("id = ?", id).
@@ -144,7 +145,7 @@ func (r *repository) UpdateLastLogin(ctx context.Context, id string) error {
 		ID:          id,
 		LastLoginAt: time.Now().UTC(),
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(user).
 		Column("last_login_at").
 		Where("id = ?", id).
@@ -161,7 +162,7 @@ func (r *repository) List(ctx context.Context, req models.ListRequest) ([]entity
 		return nil, 0, pkgErr.InvalidRequest(err.Error())
 	}
 
-	query := r.db.NewSelect().
+	query := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.User)(nil)).
 		Where("deleted_at IS NULL")
 
@@ -185,19 +186,34 @@ func (r *repository) List(ctx context.Context, req models.ListRequest) ([]entity
 	// when scoped to an app — mirror the UI contract: ignore the role predicate
 	// unless an app is also chosen. Restrict to users holding a matching role via
 	// the user_roles → roles → app_services chain, keeping soft-delete semantics
-	// on roles and matching the assignment scope to the owning role's app.
+	// on roles, matching the assignment scope to the owning role's app and
+	// counting only assignments in force now (as the role chips do). Roles
+	// inherited through a live group count too.
 	if req.AppCode != "" {
-		subQuery := r.db.NewSelect().
+		conn := transaction.Conn(ctx, r.db)
+		now := time.Now().UTC()
+		subQuery := conn.NewSelect().
 			TableExpr("user_roles AS ur").
 			ColumnExpr("ur.user_id").
 			Join("JOIN roles AS rol ON rol.id = ur.role_id AND rol.deleted_at IS NULL").
 			Join("JOIN app_services AS app ON app.id = rol.app_id").
 			Where("ur.app_service_id = rol.app_id").
+			Where("(ur.starts_at IS NULL OR ur.starts_at <= ?)", now).
+			Where("(ur.expires_at IS NULL OR ur.expires_at > ?)", now).
+			Where("app.app_code = ?", req.AppCode)
+		groupSubQuery := conn.NewSelect().
+			TableExpr("user_group_members AS ugm").
+			ColumnExpr("ugm.user_id").
+			Join("JOIN user_groups AS ugp ON ugp.id = ugm.group_id AND ugp.deleted_at IS NULL").
+			Join("JOIN user_group_roles AS ugr ON ugr.group_id = ugm.group_id").
+			Join("JOIN roles AS rol ON rol.id = ugr.role_id AND rol.deleted_at IS NULL").
+			Join("JOIN app_services AS app ON app.id = rol.app_id").
 			Where("app.app_code = ?", req.AppCode)
 		if req.RoleID != "" {
 			subQuery = subQuery.Where("rol.code = ?", req.RoleID)
+			groupSubQuery = groupSubQuery.Where("rol.code = ?", req.RoleID)
 		}
-		query = query.Where("id IN (?)", subQuery)
+		query = query.Where("(id IN (?) OR id IN (?))", subQuery, groupSubQuery)
 	}
 
 	// Get total count
@@ -230,7 +246,7 @@ func (r *repository) Verify(ctx context.Context, id string) error {
 		ID:         id,
 		IsVerified: true,
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(user).
 		Column("is_verified").
 		Where("id = ?", id).
@@ -253,7 +269,7 @@ func (r *repository) UpdateStatus(ctx context.Context, id string, status int32)
 		ID:     id,
 		Status: status,
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(user).
 		Column("status").
 		Where("id = ?", id).
@@ -273,7 +289,7 @@ func (r *repository) SoftDelete(ctx context.Context, id string) error {
 		ID:        id,
 		DeletedAt: time.Now().UTC(),
 	}
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(user).
 		Column("deleted_at").
 		Where("id = ?", id).
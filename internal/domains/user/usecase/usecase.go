@@ -4,10 +4,14 @@ import (
 	"context"
 	"time"
 
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
+	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
 	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	"github.com/vukyn/isme/internal/domains/user/models"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
 	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -17,17 +21,23 @@ type usecase struct {
 	userRepo        userRepo.IRepository
 	userSessionRepo userSessionRepo.IRepository
 	roleRepo        roleRepo.IRepository
+	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
 }
 
 func NewUsecase(
 	userRepo userRepo.IRepository,
 	userSessionRepo userSessionRepo.IRepository,
 	roleRepo roleRepo.IRepository,
+	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
 ) IUseCase {
 	return &usecase{
 		userRepo:        userRepo,
 		userSessionRepo: userSessionRepo,
 		roleRepo:        roleRepo,
+		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
 	}
 }
 
@@ -69,6 +79,7 @@ func (u *usecase) List(ctx context.Context, req models.ListRequest) (models.List
 				AppName:  role.AppName,
 				RoleCode: role.RoleCode,
 				RoleName: role.RoleName,
+				Groups:   role.Groups,
 			})
 		}
 		items = append(items, models.UserListItem{
@@ -106,7 +117,18 @@ func (u *usecase) UpdateStatus(ctx context.Context, id string, req models.Update
 		return pkgErr.NotFound("user not found")
 	}
 
-	return u.userRepo.UpdateStatus(ctx, id, req.Status)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.UpdateStatus(ctx, id, req.Status); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeUserStatusChanged,
+			TargetType: activityConstants.TargetTypeUser,
+			TargetID:   id,
+			Before:     models.UpdateStatusRequest{Status: user.Status},
+			After:      req,
+		})
+	})
 }
 
 func (u *usecase) VerifyUser(ctx context.Context, id string) error {
@@ -124,7 +146,18 @@ func (u *usecase) VerifyUser(ctx context.Context, id string) error {
 		return pkgErr.InvalidRequest("user already verified")
 	}
 
-	return u.userRepo.Verify(ctx, id)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Verify(ctx, id); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeUserVerified,
+			TargetType: activityConstants.TargetTypeUser,
+			TargetID:   id,
+			Before:     map[string]any{"is_verified": false},
+			After:      map[string]any{"is_verified": true},
+		})
+	})
 }
 
 func (u *usecase) SoftDelete(ctx context.Context, id string) error {
@@ -142,11 +175,25 @@ func (u *usecase) SoftDelete(ctx context.Context, id string) error {
 		return pkgErr.NotFound("user not found")
 	}
 
-	// delete user and revoke all sessions
-	if err := u.userRepo.SoftDelete(ctx, id); err != nil {
-		return err
-	}
-	return u.userSessionRepo.InactiveAllUserSession(ctx, id)
+	// delete user, revoke all sessions and record the audit row together
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.SoftDelete(ctx, id); err != nil {
+			return err
+		}
+		if err := u.userSessionRepo.InactiveAllUserSession(ctx, id); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeUserDeleted,
+			TargetType: activityConstants.TargetTypeUser,
+			TargetID:   id,
+			Before: map[string]any{
+				"email":  user.Email,
+				"name":   user.Name,
+				"status": user.Status,
+			},
+		})
+	})
 }
 
 func (u *usecase) ListSessions(ctx context.Context, userID string) ([]models.SessionItem, error) {
@@ -188,5 +235,19 @@ func (u *usecase) RevokeSession(ctx context.Context, userID string, sessionID st
 		return pkgErr.NotFound("session not found")
 	}
 
-	return u.userSessionRepo.InactiveSessionByID(ctx, sessionID)
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		if err := u.userSessionRepo.InactiveSessionByID(ctx, sessionID); err != nil {
+			return err
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeUserSessionRevoked,
+			TargetType: activityConstants.TargetTypeSession,
+			TargetID:   sessionID,
+			Meta: map[string]any{
+				"user_id":    userID,
+				"client_ip":  session.ClientIP,
+				"user_agent": session.UserAgent,
+			},
+		})
+	})
 }
@@ -2,10 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"slices"
 	"testing"
 	"time"
 
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
 	roleEntity "github.com/vukyn/isme/internal/domains/role/entity"
 	roleModels "github.com/vukyn/isme/internal/domains/role/models"
 	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
@@ -15,6 +17,7 @@ import (
 	userSessionEntity "github.com/vukyn/isme/internal/domains/user_session/entity"
 	userSessionModels "github.com/vukyn/isme/internal/domains/user_session/models"
 	userSessionRepo "github.com/vukyn/isme/internal/domains/user_session/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 )
@@ -116,6 +119,10 @@ func (f *fakeUserSessionRepository) InactiveSessionByID(ctx context.Context, ses
 	return nil
 }
 
+func (f *fakeUserSessionRepository) CountActive(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 func (f *fakeUserSessionRepository) InactiveAllUserSessionExcept(ctx context.Context, userID string, exceptTokenID string) error {
 	return nil
 }
@@ -204,10 +211,34 @@ func (f *fakeRoleRepository) UpdatePermissionAppearance(ctx context.Context, app
 	return nil
 }
 
+func (f *fakeRoleRepository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	return nil
+}
+
 func (f *fakeRoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
 	return nil, nil
 }
 
+func (f *fakeRoleRepository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]roleEntity.Role, error) {
+	return map[string][]roleEntity.Role{}, nil
+}
+
+func (f *fakeRoleRepository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	return roleIDs, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	return nil
+}
+
 func (f *fakeRoleRepository) ReplaceRolePermissions(ctx context.Context, roleID string, permissionIDs []int64) error {
 	return nil
 }
@@ -220,10 +251,18 @@ func (f *fakeRoleRepository) CountMembersByRoleID(ctx context.Context, roleID st
 	return 0, nil
 }
 
-func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string) error {
+func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window roleModels.MemberWindow) error {
 	return nil
 }
 
+func (f *fakeRoleRepository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]roleEntity.UserRole, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	return false, nil
+}
+
 func (f *fakeRoleRepository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
 	return nil
 }
@@ -286,7 +325,7 @@ func TestRevokeSession(t *testing.T) {
 			fakeUser := newFakeUserRepository()
 			fakeUserSession := newFakeUserSessionRepository()
 			fakeUserSession.sessionsByID["session-1"] = userSessionEntity.UserSession{ID: "session-1", UserID: "user-a"}
-			testUsecase := NewUsecase(fakeUser, fakeUserSession, &fakeRoleRepository{})
+			testUsecase := NewUsecase(fakeUser, fakeUserSession, &fakeRoleRepository{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 			err := testUsecase.RevokeSession(context.Background(), tt.userID, tt.sessionID)
 			if tt.wantErr != "" {
@@ -342,7 +381,7 @@ func TestSoftDelete(t *testing.T) {
 			fakeUser.usersByID["user-a"] = entity.User{ID: "user-a"}
 			fakeUser.usersByID["user-b"] = entity.User{ID: "user-b"}
 			fakeUserSession := newFakeUserSessionRepository()
-			testUsecase := NewUsecase(fakeUser, fakeUserSession, &fakeRoleRepository{})
+			testUsecase := NewUsecase(fakeUser, fakeUserSession, &fakeRoleRepository{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 			ctx := context.WithValue(context.Background(), pkgCtx.UserIDKey, tt.callerUserID)
 			err := testUsecase.SoftDelete(ctx, tt.targetUserID)
@@ -386,7 +425,7 @@ func TestVerifyUser(t *testing.T) {
 			fakeUser := newFakeUserRepository()
 			fakeUser.usersByID["user-unverified"] = entity.User{ID: "user-unverified"}
 			fakeUser.usersByID["user-verified"] = entity.User{ID: "user-verified", IsVerified: true}
-			testUsecase := NewUsecase(fakeUser, newFakeUserSessionRepository(), &fakeRoleRepository{})
+			testUsecase := NewUsecase(fakeUser, newFakeUserSessionRepository(), &fakeRoleRepository{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 			err := testUsecase.VerifyUser(context.Background(), tt.targetUserID)
 			if tt.wantErr != "" {
@@ -428,7 +467,7 @@ func TestUpdateStatus(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			fakeUser := newFakeUserRepository()
 			fakeUser.usersByID["user-a"] = entity.User{ID: "user-a"}
-			testUsecase := NewUsecase(fakeUser, newFakeUserSessionRepository(), &fakeRoleRepository{})
+			testUsecase := NewUsecase(fakeUser, newFakeUserSessionRepository(), &fakeRoleRepository{}, &fakeActivityUsecase{}, transaction.NoopRunner{})
 
 			err := testUsecase.UpdateStatus(context.Background(), tt.targetUserID, models.UpdateStatusRequest{Status: tt.status})
 			if tt.wantErr != "" {
@@ -452,3 +491,30 @@ func TestUpdateStatus(t *testing.T) {
 		})
 	}
 }
+
+// Deleting a user records a user_deleted audit entry targeting that user, and a
+// failed audit write fails the delete so the transaction rolls it back.
+func TestSoftDeleteRecordsAudit(t *testing.T) {
+	fakeUser := newFakeUserRepository()
+	fakeUser.usersByID["user-b"] = entity.User{ID: "user-b", Email: "b@example.com"}
+	activity := &fakeActivityUsecase{}
+	testUsecase := NewUsecase(fakeUser, newFakeUserSessionRepository(), &fakeRoleRepository{}, activity, transaction.NoopRunner{})
+
+	ctx := context.WithValue(context.Background(), pkgCtx.UserIDKey, "user-a")
+	if err := testUsecase.SoftDelete(ctx, "user-b"); err != nil {
+		t.Fatalf("SoftDelete() error = %v", err)
+	}
+	if len(activity.auditEntries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(activity.auditEntries))
+	}
+	entry := activity.auditEntries[0]
+	if entry.Type != activityConstants.ActivityTypeUserDeleted || entry.TargetID != "user-b" {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+
+	activity.auditErr = errors.New("audit unavailable")
+	fakeUser.usersByID["user-c"] = entity.User{ID: "user-c"}
+	if err := testUsecase.SoftDelete(ctx, "user-c"); err == nil {
+		t.Fatal("expected the audit failure to fail the delete, got nil")
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	idi "github.com/vukyn/isme/internal/di"
 	"github.com/vukyn/isme/internal/domains/user_invitation/models"
+	"github.com/vukyn/isme/internal/tracing"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgHttp "github.com/vukyn/kuery/http/fiber"
@@ -24,7 +25,7 @@ func CreateInvitation(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	createResponse, err := uc.Create(pkgCtx.NewContextFromFiberCtx(c), createRequest)
+	createResponse, err := uc.Create(tracing.NewContextFromFiberCtx(c), createRequest)
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -41,7 +42,7 @@ func ListInvitations(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	listResponse, err := uc.List(pkgCtx.NewContextFromFiberCtx(c))
+	listResponse, err := uc.List(tracing.NewContextFromFiberCtx(c))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -58,7 +59,7 @@ func RevokeInvitation(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.Revoke(pkgCtx.NewContextFromFiberCtx(c), c.Params("invitationID")); err != nil {
+	if err := uc.Revoke(tracing.NewContextFromFiberCtx(c), c.Params("invitationID")); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -74,7 +75,7 @@ func GetInvitationByToken(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	detailResponse, err := uc.GetByToken(pkgCtx.NewContextFromFiberCtx(c), c.Params("token"))
+	detailResponse, err := uc.GetByToken(tracing.NewContextFromFiberCtx(c), c.Params("token"))
 	if err != nil {
 		return pkgHttp.Err(c, err)
 	}
@@ -96,7 +97,7 @@ func AcceptInvitation(c *fiber.Ctx) error {
 		return pkgHttp.Err(c, err)
 	}
 
-	if err := uc.Accept(pkgCtx.NewContextFromFiberCtx(c), acceptRequest); err != nil {
+	if err := uc.Accept(tracing.NewContextFromFiberCtx(c), acceptRequest); err != nil {
 		return pkgHttp.Err(c, err)
 	}
 
@@ -5,6 +5,8 @@ import (
 	"github.com/vukyn/isme/internal/constants"
 	idi "github.com/vukyn/isme/internal/di"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/domains/user_invitation/models"
+	"github.com/vukyn/isme/internal/openapi"
 
 	"github.com/vukyn/kuery/rbac"
 
@@ -26,3 +28,18 @@ func SetupUserInvitationRoutes(router fiber.Router) {
 	rAuth.Get(constants.AUTH_ENDPOINT_INVITE_DETAIL, GetInvitationByToken)
 	rAuth.Post(constants.AUTH_ENDPOINT_ACCEPT_INVITE, AcceptInvitation)
 }
+
+// Operations documents the routes registered by SetupUserInvitationRoutes; keep
+// the two in step (the server's drift test fails otherwise).
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodPost, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_INVITES, Tag: "invitations", Summary: "Invite a user by email",
+		Body: models.CreateRequest{}, Response: models.CreateResponse{}, Auth: true, Permission: roleConstants.PERM_USER_CREATE, Errors: []int{fiber.StatusConflict}},
+	{Method: fiber.MethodGet, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_INVITES, Tag: "invitations", Summary: "List invitations",
+		Response: models.ListResponse{}, Auth: true, Permission: roleConstants.PERM_USER_READ},
+	{Method: fiber.MethodPost, Path: constants.USER_GROUP_NAME + constants.USER_ENDPOINT_INVITE_REVOKE, Tag: "invitations", Summary: "Revoke a pending invitation",
+		Auth: true, Permission: roleConstants.PERM_USER_CREATE, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_INVITE_DETAIL, Tag: "invitations", Summary: "Resolve an invitation token",
+		Response: models.InviteDetailResponse{}, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: constants.AUTH_GROUP_NAME + constants.AUTH_ENDPOINT_ACCEPT_INVITE, Tag: "invitations", Summary: "Accept an invitation and set a password",
+		Body: models.AcceptRequest{}, Errors: []int{fiber.StatusNotFound}},
+}
@@ -25,6 +25,4 @@ type IRepository interface {
 	MarkAccepted(ctx context.Context, id string) (bool, error)
 	// Atomically flip a pending invitation to revoked; false when it was not pending
 	MarkRevoked(ctx context.Context, id string) (bool, error)
-	// Roll an accepted claim back to pending (compensation when user creation fails)
-	RevertToPending(ctx context.Context, id string) error
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/vukyn/isme/internal/domains/user_invitation/constants"
 	"github.com/vukyn/isme/internal/domains/user_invitation/entity"
 	"github.com/vukyn/isme/internal/domains/user_invitation/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgErr "github.com/vukyn/kuery/http/errors"
 
@@ -40,7 +41,7 @@ func (r *repository) Create(ctx context.Context, invitation entity.UserInvitatio
 	invitation.ID = cryp.ULID()
 	invitation.Status = int32(constants.InvitationStatusPending)
 
-	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
 		if _, err := tx.NewInsert().Model(&invitation).Exec(ctx); err != nil {
 			return err
 		}
@@ -70,7 +71,7 @@ func (r *repository) GetAssignmentsByInvitationID(ctx context.Context, invitatio
 	}
 
 	assignments := []entity.UserInvitationRole{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&assignments).
 		Where("invitation_id = ?", invitationID).
 		Order("created_at ASC").
@@ -87,7 +88,7 @@ func (r *repository) GetByID(ctx context.Context, id string) (entity.UserInvitat
 	}
 
 	invitation := entity.UserInvitation{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&invitation).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -106,7 +107,7 @@ func (r *repository) GetByTokenHash(ctx context.Context, tokenHash string) (enti
 	}
 
 	invitation := entity.UserInvitation{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&invitation).
 		Where("token_hash = ?", tokenHash).
 		Scan(ctx)
@@ -125,7 +126,7 @@ func (r *repository) GetPendingByEmail(ctx context.Context, email string) (entit
 	}
 
 	invitation := entity.UserInvitation{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&invitation).
 		Where("email = ?", email).
 		Where("status = ?", constants.InvitationStatusPending).
@@ -141,7 +142,7 @@ func (r *repository) GetPendingByEmail(ctx context.Context, email string) (entit
 
 func (r *repository) List(ctx context.Context) ([]models.InvitationListItem, error) {
 	invitations := []entity.UserInvitation{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&invitations).
 		Order("created_at DESC").
 		Scan(ctx)
@@ -169,7 +170,7 @@ func (r *repository) List(ctx context.Context) ([]models.InvitationListItem, err
 		AppName      string `bun:"app_name"`
 	}
 	rows := []assignmentRow{}
-	err = r.db.NewSelect().
+	err = transaction.Conn(ctx, r.db).NewSelect().
 		TableExpr("user_invitation_roles AS uir").
 		ColumnExpr("uir.invitation_id").
 		ColumnExpr("uir.role_id").
@@ -229,7 +230,7 @@ func (r *repository) MarkAccepted(ctx context.Context, id string) (bool, error)
 	}
 
 	now := time.Now().UTC()
-	result, err := r.db.NewUpdate().
+	result, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model((*entity.UserInvitation)(nil)).
 		Set("status = ?", constants.InvitationStatusAccepted).
 		Set("accepted_at = ?", now).
@@ -253,7 +254,7 @@ func (r *repository) MarkRevoked(ctx context.Context, id string) (bool, error) {
 	}
 
 	now := time.Now().UTC()
-	result, err := r.db.NewUpdate().
+	result, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model((*entity.UserInvitation)(nil)).
 		Set("status = ?", constants.InvitationStatusRevoked).
 		Set("updated_at = ?", now).
@@ -269,22 +270,3 @@ func (r *repository) MarkRevoked(ctx context.Context, id string) (bool, error) {
 	}
 	return rowsAffected > 0, nil
 }
-
-func (r *repository) RevertToPending(ctx context.Context, id string) error {
-	if id == "" {
-		return pkgErr.InvalidRequest("id is required")
-	}
-
-	now := time.Now().UTC()
-	_, err := r.db.NewUpdate().
-		Model((*entity.UserInvitation)(nil)).
-		Set("status = ?", constants.InvitationStatusPending).
-		Set("accepted_at = NULL").
-		Set("updated_at = ?", now).
-		Where("id = ?", id).
-		Exec(ctx)
-	if err != nil {
-		return pkgErr.DatabaseError(err.Error())
-	}
-	return nil
-}
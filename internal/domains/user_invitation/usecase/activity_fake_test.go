@@ -14,6 +14,8 @@ type fakeActivityUsecase struct {
 	recordErr bool
 
 	invitationSentCalls []fakeInvitationCall
+	auditEntries        []activityModels.AuditEntry
+	auditErr            error
 }
 
 type fakeInvitationCall struct {
@@ -35,6 +37,23 @@ func (f *fakeActivityUsecase) RecordInvitationSent(ctx context.Context, inviterI
 	f.invitationSentCalls = append(f.invitationSentCalls, fakeInvitationCall{inviterID: inviterID, email: email, roleNames: roleNames})
 }
 
+func (f *fakeActivityUsecase) RecordAudit(ctx context.Context, entry activityModels.AuditEntry) error {
+	f.auditEntries = append(f.auditEntries, entry)
+	return f.auditErr
+}
+
 func (f *fakeActivityUsecase) List(ctx context.Context, userID string, limit int) ([]activityModels.ActivityItem, error) {
 	return nil, nil
 }
+
+func (f *fakeActivityUsecase) SearchAudit(ctx context.Context, req activityModels.AuditSearchRequest) (activityModels.AuditSearchResponse, error) {
+	return activityModels.AuditSearchResponse{}, nil
+}
+
+func (f *fakeActivityUsecase) ExportAudit(ctx context.Context, req activityModels.AuditExportRequest) (activityModels.AuditExport, error) {
+	return activityModels.AuditExport{}, nil
+}
+
+func (f *fakeActivityUsecase) VerifyChain(ctx context.Context) (activityModels.ChainReport, error) {
+	return activityModels.ChainReport{}, nil
+}
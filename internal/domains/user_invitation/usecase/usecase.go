@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"github.com/vukyn/isme/internal/config"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
+	activityModels "github.com/vukyn/isme/internal/domains/activity/models"
 	activityUsecase "github.com/vukyn/isme/internal/domains/activity/usecase"
 	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
 	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
 	userModels "github.com/vukyn/isme/internal/domains/user/models"
 	userRepo "github.com/vukyn/isme/internal/domains/user/repository"
@@ -15,6 +18,7 @@ import (
 	"github.com/vukyn/isme/internal/domains/user_invitation/entity"
 	"github.com/vukyn/isme/internal/domains/user_invitation/models"
 	invitationRepo "github.com/vukyn/isme/internal/domains/user_invitation/repository"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
 	pkgErr "github.com/vukyn/kuery/http/errors"
@@ -30,6 +34,7 @@ type usecase struct {
 	roleRepo        roleRepo.IRepository
 	appServiceRepo  appServiceRepo.IRepository
 	activityUsecase activityUsecase.IUseCase
+	txRunner        transaction.Runner
 }
 
 func NewUsecase(
@@ -39,6 +44,7 @@ func NewUsecase(
 	roleRepo roleRepo.IRepository,
 	appServiceRepo appServiceRepo.IRepository,
 	activityUsecase activityUsecase.IUseCase,
+	txRunner transaction.Runner,
 ) IUseCase {
 	return &usecase{
 		cfg:             cfg,
@@ -47,6 +53,7 @@ func NewUsecase(
 		roleRepo:        roleRepo,
 		appServiceRepo:  appServiceRepo,
 		activityUsecase: activityUsecase,
+		txRunner:        txRunner,
 	}
 }
 
@@ -98,12 +105,26 @@ func (u *usecase) Create(ctx context.Context, req models.CreateRequest) (models.
 
 	// generate the one-time token; only its hash is persisted
 	rawToken := base64.RawURLEncoding.EncodeToString([]byte(rand.RandString(32)))
-	invitationID, err := u.invitationRepo.Create(ctx, entity.UserInvitation{
-		Email:     req.Email,
-		TokenHash: cryp.HashSHA256(rawToken),
-		ExpiresAt: time.Now().UTC().Add(constants.InvitationTTL),
-		CreatedBy: pkgCtx.GetUserID(ctx),
-	}, assignments)
+	var invitationID string
+	err = u.txRunner.Run(ctx, func(ctx context.Context) error {
+		var err error
+		invitationID, err = u.invitationRepo.Create(ctx, entity.UserInvitation{
+			Email:     req.Email,
+			TokenHash: cryp.HashSHA256(rawToken),
+			ExpiresAt: time.Now().UTC().Add(constants.InvitationTTL),
+			CreatedBy: pkgCtx.GetUserID(ctx),
+		}, assignments)
+		if err != nil {
+			return err
+		}
+		// admin audit row; the token never leaves this function
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeInvitationCreated,
+			TargetType: activityConstants.TargetTypeInvitation,
+			TargetID:   invitationID,
+			After:      req,
+		})
+	})
 	if err != nil {
 		return models.CreateResponse{}, err
 	}
@@ -143,15 +164,24 @@ func (u *usecase) Revoke(ctx context.Context, id string) error {
 		return pkgErr.InvalidRequest("invitation already revoked")
 	}
 
-	// conditional update guards against a concurrent accept
-	revoked, err := u.invitationRepo.MarkRevoked(ctx, id)
-	if err != nil {
-		return err
-	}
-	if !revoked {
-		return pkgErr.InvalidRequest("invitation already used")
-	}
-	return nil
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		// conditional update guards against a concurrent accept
+		revoked, err := u.invitationRepo.MarkRevoked(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return pkgErr.InvalidRequest("invitation already used")
+		}
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeInvitationRevoked,
+			TargetType: activityConstants.TargetTypeInvitation,
+			TargetID:   id,
+			Before:     map[string]any{"status": invitation.Status},
+			After:      map[string]any{"status": int32(constants.InvitationStatusRevoked)},
+			Meta:       map[string]any{"email": invitation.Email},
+		})
+	})
 }
 
 func (u *usecase) GetByToken(ctx context.Context, token string) (models.InviteDetailResponse, error) {
@@ -283,45 +313,55 @@ func (u *usecase) Accept(ctx context.Context, req models.AcceptRequest) error {
 		return pkgErr.InvalidRequest("invitation has no role assignments")
 	}
 
-	// claim the invitation atomically — a lost race means it was already used
-	accepted, err := u.invitationRepo.MarkAccepted(ctx, invitation.ID)
-	if err != nil {
-		return err
-	}
-	if !accepted {
-		return pkgErr.InvalidRequest("invitation already used")
-	}
-
-	// create the user; roll the claim back on failure so the link stays usable.
-	// Roles are assigned per-assignment below, not by user create.
-	userID, err := u.userRepo.Create(ctx, userModels.CreateRequest{
-		Name:  req.Name,
-		Email: invitation.Email,
-	})
-	if err != nil {
-		_ = u.invitationRepo.RevertToPending(ctx, invitation.ID)
-		return err
-	}
+	// claim, create, grant and audit in one transaction: a failure at any step
+	// rolls the whole acceptance back and the link stays usable
+	return u.txRunner.Run(ctx, func(ctx context.Context) error {
+		// claim the invitation atomically — a lost race means it was already used
+		accepted, err := u.invitationRepo.MarkAccepted(ctx, invitation.ID)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return pkgErr.InvalidRequest("invitation already used")
+		}
 
-	// set user password
-	if err := u.userRepo.SetPassword(ctx, userID, req.Password); err != nil {
-		return err
-	}
+		// create the user. Roles are assigned per-assignment below, not by user create.
+		userID, err := u.userRepo.Create(ctx, userModels.CreateRequest{
+			Name:  req.Name,
+			Email: invitation.Email,
+		})
+		if err != nil {
+			return err
+		}
 
-	// the inviting admin vouched for this account — no separate verify step
-	if err := u.userRepo.Verify(ctx, userID); err != nil {
-		return err
-	}
+		// set user password
+		if err := u.userRepo.SetPassword(ctx, userID, req.Password); err != nil {
+			return err
+		}
 
-	// grant one app-scoped role per assignment (ur.app_service_id = roles.app_id)
-	for _, assignment := range assignments {
-		appServiceID := assignment.AppServiceID
-		if err := u.roleRepo.AddMembers(ctx, assignment.RoleID, []string{userID}, &appServiceID); err != nil {
+		// the inviting admin vouched for this account — no separate verify step
+		if err := u.userRepo.Verify(ctx, userID); err != nil {
 			return err
 		}
-	}
 
-	return nil
+		// grant one permanent app-scoped role per assignment
+		// (ur.app_service_id = roles.app_id)
+		for _, assignment := range assignments {
+			appServiceID := assignment.AppServiceID
+			if err := u.roleRepo.AddMembers(ctx, assignment.RoleID, []string{userID}, &appServiceID, roleModels.MemberWindow{}); err != nil {
+				return err
+			}
+		}
+
+		// the caller is unauthenticated — the new account is the actor
+		return u.activityUsecase.RecordAudit(ctx, activityModels.AuditEntry{
+			Type:       activityConstants.ActivityTypeInvitationAccepted,
+			TargetType: activityConstants.TargetTypeInvitation,
+			TargetID:   invitation.ID,
+			ActorID:    userID,
+			Meta:       map[string]any{"email": invitation.Email, "user_id": userID},
+		})
+	})
 }
 
 // resolveToken maps a raw token to its live pending invitation. Every failure
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/vukyn/isme/internal/config"
+	activityConstants "github.com/vukyn/isme/internal/domains/activity/constants"
 	appServiceEntity "github.com/vukyn/isme/internal/domains/app_service/entity"
 	appServiceModels "github.com/vukyn/isme/internal/domains/app_service/models"
 	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
@@ -148,10 +149,34 @@ func (f *fakeRoleRepository) UpdatePermissionAppearance(ctx context.Context, app
 	return nil
 }
 
+func (f *fakeRoleRepository) FlagPermissions(ctx context.Context, permissionIDs []int64, flaggedAt time.Time) error {
+	return nil
+}
+
 func (f *fakeRoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
 	return nil, nil
 }
 
+func (f *fakeRoleRepository) GetDirectPermissionsByRoleID(ctx context.Context, roleID string) ([]roleEntity.Permission, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) GetDirectPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeRoleRepository) GetIncludedRoles(ctx context.Context, roleIDs []string) (map[string][]roleEntity.Role, error) {
+	return map[string][]roleEntity.Role{}, nil
+}
+
+func (f *fakeRoleRepository) ExpandRoleIDs(ctx context.Context, roleIDs []string) ([]string, error) {
+	return roleIDs, nil
+}
+
+func (f *fakeRoleRepository) ReplaceRoleIncludes(ctx context.Context, roleID string, includedRoleIDs []string) error {
+	return nil
+}
+
 func (f *fakeRoleRepository) GetPermissionCodesByRoleIDs(ctx context.Context, roleIDs []string) (map[string][]string, error) {
 	result := map[string][]string{}
 	for _, roleID := range roleIDs {
@@ -174,11 +199,19 @@ func (f *fakeRoleRepository) CountMembersByRoleID(ctx context.Context, roleID st
 	return 0, nil
 }
 
-func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string) error {
+func (f *fakeRoleRepository) AddMembers(ctx context.Context, roleID string, userIDs []string, appServiceID *string, window roleModels.MemberWindow) error {
 	f.addMembersCalls = append(f.addMembersCalls, addMembersCall{roleID: roleID, userIDs: userIDs, appServiceID: appServiceID})
 	return nil
 }
 
+func (f *fakeRoleRepository) DeleteExpiredMembers(ctx context.Context, now time.Time) ([]roleEntity.UserRole, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) HasMember(ctx context.Context, roleID string, userID string, appServiceID *string) (bool, error) {
+	return false, nil
+}
+
 func (f *fakeRoleRepository) RemoveMember(ctx context.Context, roleID string, userID string, appServiceID *string) error {
 	return nil
 }
@@ -340,13 +373,24 @@ func (f *fakeInvitationRepository) MarkRevoked(ctx context.Context, id string) (
 	return true, nil
 }
 
-func (f *fakeInvitationRepository) RevertToPending(ctx context.Context, id string) error {
-	invitation, ok := f.invitations[id]
-	if !ok {
-		return nil
+// rollbackRunner stands in for the transaction around Accept: when fn fails,
+// the fake invitation store is restored to its state before Run, the way a
+// real rollback discards the claim.
+type rollbackRunner struct {
+	invitations *fakeInvitationRepository
+}
+
+func (r rollbackRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[string]entity.UserInvitation, len(r.invitations.invitations))
+	for id, invitation := range r.invitations.invitations {
+		snapshot[id] = *invitation
+	}
+	if err := fn(ctx); err != nil {
+		for id, invitation := range snapshot {
+			*r.invitations.invitations[id] = invitation
+		}
+		return err
 	}
-	invitation.Status = int32(constants.InvitationStatusPending)
-	invitation.AcceptedAt = time.Time{}
 	return nil
 }
 
@@ -393,7 +437,7 @@ func newTestFixtureWithActivity() (*fakeInvitationRepository, *fakeUserRepositor
 		},
 	}
 	activity := &fakeActivityUsecase{}
-	invitationUsecase := NewUsecase(newTestConfig(), invitationRepository, userRepository, roleRepository, appServiceRepository, activity)
+	invitationUsecase := NewUsecase(newTestConfig(), invitationRepository, userRepository, roleRepository, appServiceRepository, activity, rollbackRunner{invitations: invitationRepository})
 	return invitationRepository, userRepository, roleRepository, invitationUsecase, activity
 }
 
@@ -738,7 +782,7 @@ func TestAcceptInvitationRejections(t *testing.T) {
 		}
 	})
 
-	t.Run("user create failure reverts the claim", func(t *testing.T) {
+	t.Run("user create failure rolls the claim back", func(t *testing.T) {
 		invitationRepository, userRepository, _, invitationUsecase := newTestFixture()
 		invitationID, rawToken := createInvitation(t, invitationUsecase, "fail@hasaki.vn")
 		userRepository.createErr = errors.New("database unavailable")
@@ -748,7 +792,7 @@ func TestAcceptInvitationRejections(t *testing.T) {
 			t.Fatal("expected accept to fail when user creation fails")
 		}
 		if invitationRepository.invitations[invitationID].Status != int32(constants.InvitationStatusPending) {
-			t.Error("expected invitation to be reverted to pending after user create failure")
+			t.Error("expected the claim to roll back to pending after user create failure")
 		}
 	})
 }
@@ -790,6 +834,44 @@ func TestRevokeInvitationTransitions(t *testing.T) {
 	}
 }
 
+// TestInvitationLifecycleRecordsAudit proves create, accept and revoke each
+// write an admin audit row targeting the invitation, and that the accept row
+// names the new account as its actor.
+func TestInvitationLifecycleRecordsAudit(t *testing.T) {
+	_, userRepository, _, invitationUsecase, activity := newTestFixtureWithActivity()
+
+	acceptedID, rawToken := createInvitation(t, invitationUsecase, "audit@hasaki.vn")
+	if err := invitationUsecase.Accept(context.Background(), models.AcceptRequest{Token: rawToken, Name: "Audit", Password: "s3cret-pass"}); err != nil {
+		t.Fatalf("accept failed: %v", err)
+	}
+	revokedID, _ := createInvitation(t, invitationUsecase, "revoke@hasaki.vn")
+	if err := invitationUsecase.Revoke(context.Background(), revokedID); err != nil {
+		t.Fatalf("revoke failed: %v", err)
+	}
+
+	wantTypes := []string{
+		activityConstants.ActivityTypeInvitationCreated,
+		activityConstants.ActivityTypeInvitationAccepted,
+		activityConstants.ActivityTypeInvitationCreated,
+		activityConstants.ActivityTypeInvitationRevoked,
+	}
+	if len(activity.auditEntries) != len(wantTypes) {
+		t.Fatalf("expected %d audit entries, got %d", len(wantTypes), len(activity.auditEntries))
+	}
+	for i, want := range wantTypes {
+		if activity.auditEntries[i].Type != want {
+			t.Errorf("audit entry %d type = %q, want %q", i, activity.auditEntries[i].Type, want)
+		}
+	}
+	accept := activity.auditEntries[1]
+	if accept.TargetID != acceptedID {
+		t.Errorf("accept audit target = %q, want %q", accept.TargetID, acceptedID)
+	}
+	if len(userRepository.createCalls) != 1 || accept.ActorID != "user-1" {
+		t.Errorf("accept audit actor = %q, want the new user user-1", accept.ActorID)
+	}
+}
+
 // === GetByToken (public invite detail) ===
 
 func TestGetInvitationByToken(t *testing.T) {
@@ -32,6 +32,8 @@ type IRepository interface {
 	GetListActiveByUserID(ctx context.Context, userID string) ([]entity.UserSession, error)
 	// Count active sessions per user for multiple user IDs
 	CountActiveByUserIDs(ctx context.Context, userIDs []string) (map[string]int, error)
+	// Count every active session (all users); feeds the active-session gauge
+	CountActive(ctx context.Context) (int, error)
 	// Count active sessions for a user created after the given time
 	CountActiveByUserIDCreatedAfter(ctx context.Context, userID string, after time.Time) (int, error)
 	// Count token rotation events for a user at or after the given time (sliding 24h window)
@@ -9,6 +9,7 @@ import (
 	"github.com/vukyn/isme/internal/domains/user_session/constants"
 	"github.com/vukyn/isme/internal/domains/user_session/entity"
 	"github.com/vukyn/isme/internal/domains/user_session/models"
+	"github.com/vukyn/isme/internal/transaction"
 
 	pkgErr "github.com/vukyn/kuery/http/errors"
 
@@ -45,7 +46,7 @@ func (r *repository) Create(ctx context.Context, req models.CreateRequest) (enti
 		AppServiceID: req.AppServiceID,
 	}
 
-	_, err := r.db.NewInsert().
+	_, err := transaction.Conn(ctx, r.db).NewInsert().
 		Model(&userSession).
 		Exec(ctx)
 	if err != nil {
@@ -81,7 +82,7 @@ func (r *repository) UpdateLastLogin(ctx context.Context, req models.UpdateLastL
 	// Rotate the session (bump refresh_count + stamp last_refreshed_at) and
 	// record the rotation event atomically so the per-session counters and the
 	// 24h event log never diverge.
-	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+	err := transaction.Conn(ctx, r.db).RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
 		if _, err := tx.NewUpdate().
 			Model(&userSession).
 			Column("last_login_at", "refresh_token", "client_ip", "user_agent", "expires_at", "token_id", "last_refreshed_at").
@@ -115,7 +116,7 @@ func (r *repository) CountRotationsByUserIDSince(ctx context.Context, userID str
 		return 0, pkgErr.InvalidRequest("user_id is required")
 	}
 
-	count, err := r.db.NewSelect().
+	count, err := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.TokenRotationEvent)(nil)).
 		Where("user_id = ?", userID).
 		Where("rotated_at >= ?", since).
@@ -130,7 +131,7 @@ func (r *repository) CountRotationsByUserIDSince(ctx context.Context, userID str
 // and returns the number of rows removed. Driven by the rotation-cleanup
 // scheduler to keep token_rotation_events bounded.
 func (r *repository) PruneRotationsBefore(ctx context.Context, before time.Time) (int64, error) {
-	res, err := r.db.NewDelete().
+	res, err := transaction.Conn(ctx, r.db).NewDelete().
 		Model((*entity.TokenRotationEvent)(nil)).
 		Where("rotated_at < ?", before).
 		Exec(ctx)
@@ -153,7 +154,7 @@ func (r *repository) InactiveAllUserSession(ctx context.Context, userID string)
 		Status: constants.UserSessionStatusInactive,
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(&userSession).
 		Column("status").
 		Where("user_id = ?", userID).
@@ -173,7 +174,7 @@ func (r *repository) InactiveSessionByTokenID(ctx context.Context, tokenID strin
 		Status: constants.UserSessionStatusInactive,
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(&userSession).
 		Column("status").
 		Where("token_id = ?", tokenID).
@@ -190,7 +191,7 @@ func (r *repository) FindByRefreshToken(ctx context.Context, refreshToken string
 	}
 
 	userSession := entity.UserSession{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&userSession).
 		Where("refresh_token = ?", cryp.HashSHA256(refreshToken)).
 		Scan(ctx)
@@ -209,7 +210,7 @@ func (r *repository) FindByTokenID(ctx context.Context, tokenID string) (entity.
 	}
 
 	userSession := entity.UserSession{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&userSession).
 		Where("token_id = ?", tokenID).
 		Scan(ctx)
@@ -229,7 +230,7 @@ func (r *repository) GetListActiveByUserID(ctx context.Context, userID string) (
 	}
 
 	var userSessions []entity.UserSession
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&userSessions).
 		Where("user_id = ? AND status = ?", userID, constants.UserSessionStatusActive).
 		Scan(ctx)
@@ -249,7 +250,7 @@ func (r *repository) CountActiveByUserIDs(ctx context.Context, userIDs []string)
 		Count  int    `bun:"count"`
 	}
 
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.UserSession)(nil)).
 		Column("user_id").
 		ColumnExpr("COUNT(*) as count").
@@ -271,12 +272,23 @@ func (r *repository) CountActiveByUserIDs(ctx context.Context, userIDs []string)
 	return counts, nil
 }
 
+func (r *repository) CountActive(ctx context.Context) (int, error) {
+	count, err := transaction.Conn(ctx, r.db).NewSelect().
+		Model((*entity.UserSession)(nil)).
+		Where("status = ?", constants.UserSessionStatusActive).
+		Count(ctx)
+	if err != nil {
+		return 0, pkgErr.DatabaseError(err.Error())
+	}
+	return count, nil
+}
+
 func (r *repository) CountActiveByUserIDCreatedAfter(ctx context.Context, userID string, after time.Time) (int, error) {
 	if userID == "" {
 		return 0, pkgErr.InvalidRequest("user_id is required")
 	}
 
-	count, err := r.db.NewSelect().
+	count, err := transaction.Conn(ctx, r.db).NewSelect().
 		Model((*entity.UserSession)(nil)).
 		Where("user_id = ?", userID).
 		Where("status = ?", constants.UserSessionStatusActive).
@@ -300,7 +312,7 @@ func (r *repository) InactiveAllUserSessionExcept(ctx context.Context, userID st
 		Status: constants.UserSessionStatusInactive,
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(&userSession).
 		Column("status").
 		Where("user_id = ?", userID).
@@ -318,7 +330,7 @@ func (r *repository) GetByID(ctx context.Context, sessionID string) (entity.User
 	}
 
 	userSession := entity.UserSession{}
-	err := r.db.NewSelect().
+	err := transaction.Conn(ctx, r.db).NewSelect().
 		Model(&userSession).
 		Where("id = ?", sessionID).
 		Scan(ctx)
@@ -333,7 +345,7 @@ func (r *repository) GetByID(ctx context.Context, sessionID string) (entity.User
 }
 
 func (r *repository) InactiveExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
-	res, err := r.db.NewUpdate().
+	res, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model((*entity.UserSession)(nil)).
 		Set("status = ?", constants.UserSessionStatusInactive).
 		Where("status = ?", constants.UserSessionStatusActive).
@@ -358,7 +370,7 @@ func (r *repository) InactiveSessionByID(ctx context.Context, sessionID string)
 		Status: constants.UserSessionStatusInactive,
 	}
 
-	_, err := r.db.NewUpdate().
+	_, err := transaction.Conn(ctx, r.db).NewUpdate().
 		Model(&userSession).
 		Column("status").
 		Where("id = ?", sessionID).
@@ -107,6 +107,24 @@ func TestInactiveExpiredSessionsNoMatches(t *testing.T) {
 	}
 }
 
+func TestCountActive(t *testing.T) {
+	db := newTestDB(t)
+	repository := NewRepository(db)
+	now := time.Now().UTC()
+
+	insertSession(t, db, "active_1", constants.UserSessionStatusActive, now.Add(time.Hour))
+	insertSession(t, db, "active_2", constants.UserSessionStatusActive, now.Add(-time.Hour))
+	insertSession(t, db, "inactive", constants.UserSessionStatusInactive, now.Add(time.Hour))
+
+	count, err := repository.CountActive(context.Background())
+	if err != nil {
+		t.Fatalf("CountActive: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 active sessions, got %d", count)
+	}
+}
+
 func insertRotation(t *testing.T, db *bun.DB, id string, rotatedAt time.Time) {
 	t.Helper()
 	event := entity.TokenRotationEvent{
@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vukyn/isme/internal/metrics"
+
+	"github.com/uptrace/bun"
+)
+
+// DatabaseCheck pings the connection pool.
+func DatabaseCheck(db *bun.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Probe: func(ctx context.Context) error {
+			return db.PingContext(ctx)
+		},
+	}
+}
+
+// MigrationsCheck compares the migrations bookkeeping table against the
+// migration names this build ships: required is the incremental history,
+// optional the names that may be applied but need not be (the fresh-install
+// baseline). A pending migration means the code is ahead of the schema, which
+// fails queries at random, so it is critical. A database that has applied
+// migrations this build does not know (a rollback of the binary) usually still
+// works and is only degraded.
+func MigrationsCheck(db *bun.DB, required, optional []string) Check {
+	return Check{
+		Name:     "migrations",
+		Critical: true,
+		Probe: func(ctx context.Context) error {
+			applied := []string{}
+			err := db.NewSelect().Table("migrations").Column("name").Scan(ctx, &applied)
+			if err != nil {
+				return fmt.Errorf("read migrations table: %w", err)
+			}
+			return compareMigrations(required, optional, applied)
+		},
+	}
+}
+
+func compareMigrations(required, optional, applied []string) error {
+	appliedSet := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		appliedSet[name] = true
+	}
+	knownSet := make(map[string]bool, len(required)+len(optional))
+	for _, name := range optional {
+		knownSet[name] = true
+	}
+	var pending []string
+	for _, name := range required {
+		knownSet[name] = true
+		if !appliedSet[name] {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migration(s): %s", len(pending), strings.Join(pending, ", "))
+	}
+
+	var unknown int
+	for _, name := range applied {
+		if !knownSet[name] {
+			unknown++
+		}
+	}
+	if unknown > 0 {
+		return Degraded(fmt.Errorf("database has %d migration(s) this build does not know", unknown))
+	}
+	return nil
+}
+
+// CacheCheck round-trips a short-lived probe entry through the cache.
+func CacheCheck(cache *metrics.Cache) Check {
+	return Check{
+		Name:     "cache",
+		Critical: true,
+		Probe: func(ctx context.Context) error {
+			var nonce [8]byte
+			_, _ = rand.Read(nonce[:])
+			key, value := "health:probe:"+hex.EncodeToString(nonce[:]), time.Now().String()
+
+			cache.Set(key, value, time.Minute)
+			defer cache.Delete(key)
+			if got, ok := cache.Get(key); !ok || got != value {
+				return errors.New("probe entry did not round-trip")
+			}
+			return nil
+		},
+	}
+}
+
+// HTTPCheck reports whether baseURL answers at all: any response below 500,
+// including 401/404, proves the service is up. It is never critical — it
+// covers optional upstreams such as medioa.
+func HTTPCheck(name, baseURL string) Check {
+	client := &http.Client{}
+	return Check{
+		Name: name,
+		Probe: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("responded %s", resp.Status)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pkgScheduler "github.com/vukyn/kuery/scheduler"
+)
+
+func TestCompareMigrations(t *testing.T) {
+	required := []string{"001_a", "002_b", "003_c"}
+	optional := []string{"000_baseline"}
+
+	if err := compareMigrations(required, optional, []string{"000_baseline", "001_a", "002_b", "003_c"}); err != nil {
+		t.Fatalf("fully migrated: %v", err)
+	}
+
+	err := compareMigrations(required, optional, []string{"001_a"})
+	if err == nil || !strings.Contains(err.Error(), "002_b, 003_c") {
+		t.Fatalf("pending: err = %v, want the pending names", err)
+	}
+	var degraded degradedError
+	if errors.As(err, &degraded) {
+		t.Fatal("pending migrations must not be merely degraded")
+	}
+
+	err = compareMigrations(required, optional, []string{"001_a", "002_b", "003_c", "004_d"})
+	if !errors.As(err, &degraded) {
+		t.Fatalf("database ahead of build: err = %v, want degraded", err)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	status := http.StatusUnauthorized
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	check := HTTPCheck("medioa", srv.URL)
+	if check.Critical {
+		t.Fatal("upstream checks must not be critical")
+	}
+	if err := check.Probe(context.Background()); err != nil {
+		t.Fatalf("401 means the service is up: %v", err)
+	}
+	status = http.StatusBadGateway
+	if err := check.Probe(context.Background()); err == nil {
+		t.Fatal("5xx passed")
+	}
+}
+
+type fakeEngine struct{ started, stopped bool }
+
+func (e *fakeEngine) Start(context.Context, pkgScheduler.ScheduleProvider) { e.started = true }
+func (e *fakeEngine) Stop()                                                { e.stopped = true }
+
+func TestSchedulerCheck(t *testing.T) {
+	tracker := NewSchedulerTracker()
+	check := tracker.Check(true)
+
+	if err := check.Probe(context.Background()); err == nil {
+		t.Fatal("a scheduler that never started passed")
+	}
+	if err := tracker.Check(false).Probe(context.Background()); err != nil {
+		t.Fatalf("a disabled scheduler has nothing to check: %v", err)
+	}
+
+	engine := &fakeEngine{}
+	wrapped := tracker.Wrap(engine)
+	wrapped.Start(context.Background(), nil)
+	if !engine.started {
+		t.Fatal("Start not delegated")
+	}
+	if err := check.Probe(context.Background()); err != nil {
+		t.Fatalf("running scheduler: %v", err)
+	}
+
+	failing := tracker.TrackJob("activity_cleanup", func(context.Context) error { return errors.New("disk full") })
+	_ = failing(context.Background())
+	if err := check.Probe(context.Background()); err == nil || !strings.Contains(err.Error(), "activity_cleanup") {
+		t.Fatalf("failed job: err = %v, want it named", err)
+	}
+	recovered := tracker.TrackJob("activity_cleanup", func(context.Context) error { return nil })
+	_ = recovered(context.Background())
+	if err := check.Probe(context.Background()); err != nil {
+		t.Fatalf("job recovered: %v", err)
+	}
+
+	wrapped.Stop()
+	if !engine.stopped || check.Probe(context.Background()) == nil {
+		t.Fatal("stopped scheduler still reported running")
+	}
+}
@@ -0,0 +1,150 @@
+// Package health runs the dependency checks behind /readyz: each check probes
+// one dependency under a timeout, and the report rolls the results up into one
+// of three states.
+//
+//   - ok: every check passed.
+//   - degraded: a non-critical dependency failed (scheduler, medioa) or a
+//     critical one is slow. The instance still serves traffic.
+//   - unhealthy: a critical dependency (database, schema) failed; the instance
+//     must be taken out of rotation.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusOK        Status = "ok"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+func (s Status) rank() int {
+	switch s {
+	case StatusDegraded:
+		return 1
+	case StatusUnhealthy:
+		return 2
+	}
+	return 0
+}
+
+// Check probes one dependency. A nil error passes; an error fails the check,
+// making the report unhealthy when Critical and degraded otherwise. A probe
+// may return Degraded(err) to report an impaired but working dependency.
+type Check struct {
+	Name     string
+	Critical bool
+	Probe    func(ctx context.Context) error
+}
+
+type degradedError struct{ err error }
+
+func (e degradedError) Error() string { return e.err.Error() }
+func (e degradedError) Unwrap() error { return e.err }
+
+// Degraded marks a probe failure as degraded even on a critical check.
+func Degraded(err error) error {
+	return degradedError{err: err}
+}
+
+// CheckResult is one check's outcome as served on /readyz.
+type CheckResult struct {
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the /readyz body.
+type Report struct {
+	Status    Status                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs a fixed set of checks concurrently.
+type Checker struct {
+	checks []Check
+	// timeout bounds each probe; a probe still running then fails.
+	timeout time.Duration
+	// slow is the latency above which a passing probe reports degraded.
+	slow time.Duration
+}
+
+func NewChecker(timeout, slow time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout, slow: slow}
+}
+
+// Run probes every check and rolls the results up: the report takes the worst
+// check status.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusOK,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status.rank() > report.Status.rank() {
+				report.Status = result.Status
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// the probe runs on its own goroutine so one that ignores ctx still cannot
+	// hold up the report past the timeout
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- check.Probe(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+	latency := time.Since(start)
+
+	result := CheckResult{
+		Status:    StatusOK,
+		Critical:  check.Critical,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	var degraded degradedError
+	switch {
+	case err == nil && c.slow > 0 && latency > c.slow:
+		result.Status = StatusDegraded
+		result.Error = fmt.Sprintf("slow: took longer than %s", c.slow)
+	case err == nil:
+	case errors.As(err, &degraded), !check.Critical:
+		result.Status, result.Error = StatusDegraded, err.Error()
+	default:
+		result.Status, result.Error = StatusUnhealthy, err.Error()
+	}
+	return result
+}
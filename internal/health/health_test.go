@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func probe(err error) func(context.Context) error {
+	return func(context.Context) error { return err }
+}
+
+func TestRunRollsUpWorstStatus(t *testing.T) {
+	boom := errors.New("boom")
+	for _, tc := range []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"all pass", []Check{
+			{Name: "db", Critical: true, Probe: probe(nil)},
+			{Name: "medioa", Probe: probe(nil)},
+		}, StatusOK},
+		{"optional fails", []Check{
+			{Name: "db", Critical: true, Probe: probe(nil)},
+			{Name: "medioa", Probe: probe(boom)},
+		}, StatusDegraded},
+		{"critical degraded", []Check{
+			{Name: "migrations", Critical: true, Probe: probe(Degraded(boom))},
+		}, StatusDegraded},
+		{"critical fails", []Check{
+			{Name: "db", Critical: true, Probe: probe(boom)},
+			{Name: "medioa", Probe: probe(boom)},
+		}, StatusUnhealthy},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := NewChecker(time.Second, 0, tc.checks...).Run(context.Background())
+			if report.Status != tc.want {
+				t.Fatalf("status = %s, want %s (%+v)", report.Status, tc.want, report.Checks)
+			}
+			if len(report.Checks) != len(tc.checks) {
+				t.Fatalf("got %d results, want %d", len(report.Checks), len(tc.checks))
+			}
+		})
+	}
+}
+
+func TestRunTimesOutHungProbe(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	hung := Check{Name: "db", Critical: true, Probe: func(context.Context) error {
+		<-release // ignores ctx on purpose
+		return nil
+	}}
+
+	start := time.Now()
+	report := NewChecker(50*time.Millisecond, 0, hung).Run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Run took %s, want it bounded by the timeout", elapsed)
+	}
+	if got := report.Checks["db"]; got.Status != StatusUnhealthy || got.Error == "" {
+		t.Fatalf("hung probe = %+v, want unhealthy with an error", got)
+	}
+}
+
+func TestRunFlagsSlowProbeDegraded(t *testing.T) {
+	slow := Check{Name: "db", Critical: true, Probe: func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}
+	report := NewChecker(time.Second, time.Millisecond, slow).Run(context.Background())
+	if got := report.Checks["db"]; got.Status != StatusDegraded || got.LatencyMs < 20 {
+		t.Fatalf("slow probe = %+v, want degraded with its latency", got)
+	}
+}
+
+func TestReadinessHandlerStatusCodes(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{nil, fiber.StatusOK},
+		{Degraded(errors.New("slow")), fiber.StatusOK},
+		{errors.New("down"), fiber.StatusServiceUnavailable},
+	} {
+		app := fiber.New()
+		app.Get("/readyz", ReadinessHandler(NewChecker(time.Second, 0, Check{Name: "db", Critical: true, Probe: probe(tc.err)})))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		var report Report
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("probe error %v: status %d, want %d", tc.err, resp.StatusCode, tc.want)
+		}
+		if _, ok := report.Checks["db"]; !ok {
+			t.Errorf("report has no db check: %+v", report)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LivenessHandler serves /healthz: it answers as long as the process can serve
+// HTTP and never touches a dependency, so a database outage takes the
+// instance out of rotation (via /readyz) instead of having the platform
+// restart it in a loop.
+func LivenessHandler() fiber.Handler {
+	started := time.Now()
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(fiber.Map{
+			"status":         StatusOK,
+			"uptime_seconds": int64(time.Since(started).Seconds()),
+		})
+	}
+}
+
+// ReadinessHandler serves /readyz: it runs every check and answers 200 while
+// the report is ok or degraded, and 503 once it is unhealthy.
+func ReadinessHandler(checker *Checker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := checker.Run(c.UserContext())
+		status := fiber.StatusOK
+		if report.Status == StatusUnhealthy {
+			status = fiber.StatusServiceUnavailable
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Status(status).JSON(report)
+	}
+}
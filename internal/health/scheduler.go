@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pkgScheduler "github.com/vukyn/kuery/scheduler"
+)
+
+// SchedulerEngine is the part of the scheduler engine the process drives.
+type SchedulerEngine interface {
+	Start(ctx context.Context, provider pkgScheduler.ScheduleProvider)
+	Stop()
+}
+
+// SchedulerTracker follows the background scheduler for the readiness check:
+// whether it is running and how each job's last run ended. The engine does not
+// expose either, so the tracker wraps the engine and the job bodies.
+type SchedulerTracker struct {
+	mu      sync.Mutex
+	running bool
+	jobs    map[string]jobRun
+}
+
+type jobRun struct {
+	at  time.Time
+	err error
+}
+
+// Scheduler is the tracker the scheduler wiring reports into.
+var Scheduler = NewSchedulerTracker()
+
+func NewSchedulerTracker() *SchedulerTracker {
+	return &SchedulerTracker{jobs: map[string]jobRun{}}
+}
+
+// Wrap returns engine with Start and Stop recorded.
+func (t *SchedulerTracker) Wrap(engine SchedulerEngine) SchedulerEngine {
+	return trackedEngine{SchedulerEngine: engine, tracker: t}
+}
+
+type trackedEngine struct {
+	SchedulerEngine
+	tracker *SchedulerTracker
+}
+
+func (e trackedEngine) Start(ctx context.Context, provider pkgScheduler.ScheduleProvider) {
+	e.SchedulerEngine.Start(ctx, provider)
+	e.tracker.setRunning(true)
+}
+
+func (e trackedEngine) Stop() {
+	e.tracker.setRunning(false)
+	e.SchedulerEngine.Stop()
+}
+
+func (t *SchedulerTracker) setRunning(running bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running = running
+}
+
+// TrackJob wraps a job body to record the outcome of its last run.
+func (t *SchedulerTracker) TrackJob(job string, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		err := run(ctx)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.jobs[job] = jobRun{at: time.Now(), err: err}
+		return err
+	}
+}
+
+// Check reports the scheduler. It is never critical: the jobs are housekeeping
+// (session revoke, cleanup, backups) and requests are served without them. With
+// the scheduler disabled by config there is nothing to check.
+func (t *SchedulerTracker) Check(enabled bool) Check {
+	return Check{
+		Name: "scheduler",
+		Probe: func(ctx context.Context) error {
+			if !enabled {
+				return nil
+			}
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.running {
+				return errors.New("scheduler is not running")
+			}
+			var failed []string
+			for job, run := range t.jobs {
+				if run.err != nil {
+					failed = append(failed, fmt.Sprintf("%s (at %s): %v", job, run.at.UTC().Format(time.RFC3339), run.err))
+				}
+			}
+			if len(failed) > 0 {
+				sort.Strings(failed)
+				return fmt.Errorf("last run failed: %s", strings.Join(failed, "; "))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// QueryHook feeds DBQueryDuration and DBQueryErrors. It is added next to the
+// logging query hook, so every query bun runs is timed.
+type QueryHook struct{}
+
+var _ bun.QueryHook = QueryHook{}
+
+func (QueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	operation := event.Operation()
+	DBQueryDuration.With(operation).Observe(time.Since(event.StartTime).Seconds())
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		DBQueryErrors.With(operation).Inc()
+	}
+}
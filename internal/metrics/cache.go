@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	pkgCache "github.com/vukyn/kuery/cache"
+)
+
+// Cache wraps the in-process cache to count its entries for
+// isme_cache_entries. It tracks the deadline of every key it sets, drops it on
+// Delete, and prunes lapsed deadlines when counted, so an entry the cache
+// expires on its own stops counting at the same moment.
+type Cache struct {
+	*pkgCache.Cache[string, string]
+
+	mu        sync.Mutex
+	deadlines map[string]time.Time
+	now       func() time.Time
+}
+
+func NewCache(cache *pkgCache.Cache[string, string]) *Cache {
+	return &Cache{Cache: cache, deadlines: map[string]time.Time{}, now: time.Now}
+}
+
+// Set stores the entry; a ttl of zero or less never expires.
+func (c *Cache) Set(key string, value string, ttl time.Duration) {
+	c.Cache.Set(key, value, ttl)
+	var deadline time.Time
+	if ttl > 0 {
+		deadline = c.now().Add(ttl)
+	}
+	c.mu.Lock()
+	c.deadlines[key] = deadline
+	c.mu.Unlock()
+}
+
+func (c *Cache) Delete(key string) {
+	c.Cache.Delete(key)
+	c.mu.Lock()
+	delete(c.deadlines, key)
+	c.mu.Unlock()
+}
+
+// Len returns the number of entries that have not expired.
+func (c *Cache) Len() int {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, deadline := range c.deadlines {
+		if !deadline.IsZero() && !now.Before(deadline) {
+			delete(c.deadlines, key)
+		}
+	}
+	return len(c.deadlines)
+}
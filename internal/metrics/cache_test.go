@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	pkgCache "github.com/vukyn/kuery/cache"
+)
+
+func TestCacheCountsLiveEntries(t *testing.T) {
+	inner := pkgCache.NewCache[string, string]()
+	defer inner.Close()
+	cache := NewCache(inner)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Set("session", "a", time.Minute)
+	cache.Set("code", "b", time.Second)
+	cache.Set("code", "c", time.Second) // overwrite counts once
+	cache.Set("nonce", "d", time.Minute)
+	cache.Delete("nonce")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+	if value, ok := cache.Get("code"); !ok || value != "c" {
+		t.Fatalf("Get(code) = %q, %v; want the wrapped cache to serve it", value, ok)
+	}
+
+	now = now.Add(2 * time.Second)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("Len() after the code expired = %d, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware records request count and latency per matched route. The route
+// template (e.g. /api/v1/users/:userID), not the raw path, is the label, so the
+// series count stays bounded.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// the error handler has not written the response yet
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		method := c.Method()
+		route := c.Route().Path
+		HTTPRequests.With(method, route, strconv.Itoa(status)).Inc()
+		HTTPRequestDuration.With(method, route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Handler serves the registry in the Prometheus text format. When token is set
+// the scraper must send it as "Authorization: Bearer <token>".
+func Handler(registry *Registry, token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token != "" {
+			provided, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="metrics"`)
+				return c.SendStatus(fiber.StatusUnauthorized)
+			}
+		}
+		var buf bytes.Buffer
+		if err := registry.Write(&buf); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Send(buf.Bytes())
+	}
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/users/:userID", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+	app.Get("/boom", func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusTeapot, "boom") })
+
+	for _, path := range []string{"/users/1", "/users/2", "/boom"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	var buf strings.Builder
+	if err := Default.Write(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`isme_http_requests_total{method="GET",route="/users/:userID",status="204"} 2`,
+		`isme_http_requests_total{method="GET",route="/boom",status="418"} 1`,
+		`isme_http_request_duration_seconds_count{method="GET",route="/users/:userID"} 2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestHandlerRequiresBearerToken(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeFunc("test_up", "Up.", func() float64 { return 1 }))
+	app := fiber.New()
+	app.Get("/metrics", Handler(r, "s3cret"))
+
+	for _, tc := range []struct {
+		auth string
+		want int
+	}{
+		{"", fiber.StatusUnauthorized},
+		{"Bearer wrong", fiber.StatusUnauthorized},
+		{"s3cret", fiber.StatusUnauthorized},
+		{"Bearer s3cret", fiber.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("auth %q: expected %d, got %d", tc.auth, tc.want, resp.StatusCode)
+		}
+		if tc.want == fiber.StatusOK && !strings.Contains(string(body), "test_up 1\n") {
+			t.Fatalf("unexpected body %q", body)
+		}
+	}
+}
+
+func TestInstrumentJob(t *testing.T) {
+	failing := InstrumentJob("test-job", func(ctx context.Context) error { return errors.New("failed") })
+	passing := InstrumentJob("test-job", func(ctx context.Context) error { return nil })
+
+	_ = failing(context.Background())
+	_ = passing(context.Background())
+	_ = passing(context.Background())
+
+	var buf strings.Builder
+	if err := Default.Write(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`isme_scheduler_job_runs_total{job="test-job",result="error"} 1`,
+		`isme_scheduler_job_runs_total{job="test-job",result="success"} 2`,
+		`isme_scheduler_job_duration_seconds_count{job="test-job"} 3`,
+		`isme_scheduler_job_last_success_timestamp_seconds{job="test-job"} `,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
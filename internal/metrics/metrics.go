@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// Default is the registry served on /metrics.
+var Default = NewRegistry()
+
+// Login outcomes. Every failure reason the client sees as "invalid email or
+// password" is still told apart here, so a credential-stuffing run (unknown
+// users, bad passwords) is distinguishable from locked-out accounts.
+const (
+	LoginReasonSuccess        = "success"
+	LoginReasonInvalidRequest = "invalid_request"
+	LoginReasonInvalidSession = "invalid_session"
+	LoginReasonUnknownUser    = "unknown_user"
+	LoginReasonInactiveUser   = "inactive_user"
+	LoginReasonBadPassword    = "bad_password"
+	LoginReasonUnverified     = "unverified"
+	LoginReasonError          = "error"
+)
+
+// Results shared by the counters that split on outcome. ResultInvalid is a
+// client-side rejection (bad token, unknown code); ResultError is a server-side
+// failure (database, signing). Logins only split success from ResultFailure and
+// carry the detail in the reason label.
+const (
+	ResultSuccess = "success"
+	ResultInvalid = "invalid"
+	ResultError   = "error"
+	ResultFailure = "failure"
+)
+
+// SSO handshake steps, in the order a relying app drives them.
+const (
+	SSOStepRequestLogin = "request_login"
+	SSOStepLogin        = "login"
+	SSOStepConsent      = "consent"
+	SSOStepExchange     = "exchange"
+)
+
+// SSOAppUnknown labels handshakes that never resolved to a registered app, so
+// arbitrary client-supplied app codes cannot blow up the label cardinality.
+const SSOAppUnknown = "unknown"
+
+var (
+	HTTPRequests = NewCounterVec(
+		"isme_http_requests_total",
+		"HTTP requests by method, matched route and status code.",
+		"method", "route", "status",
+	)
+	HTTPRequestDuration = NewHistogramVec(
+		"isme_http_request_duration_seconds",
+		"HTTP request latency by method and matched route.",
+		DefBuckets,
+		"method", "route",
+	)
+	Logins = NewCounterVec(
+		"isme_auth_logins_total",
+		"Password logins by result and reason.",
+		"result", "reason",
+	)
+	TokenRefreshes = NewCounterVec(
+		"isme_auth_token_refreshes_total",
+		"Refresh-token exchanges by result.",
+		"result",
+	)
+	SSOHandshakes = NewCounterVec(
+		"isme_sso_handshakes_total",
+		"SSO handshake steps by relying app code, step and result.",
+		"app", "step", "result",
+	)
+	DBQueryDuration = NewHistogramVec(
+		"isme_db_query_duration_seconds",
+		"Database query latency by operation.",
+		[]float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		"operation",
+	)
+	DBQueryErrors = NewCounterVec(
+		"isme_db_query_errors_total",
+		"Database queries that returned an error (other than no rows), by operation.",
+		"operation",
+	)
+	SchedulerJobRuns = NewCounterVec(
+		"isme_scheduler_job_runs_total",
+		"Scheduler job runs by job and result.",
+		"job", "result",
+	)
+	SchedulerJobDuration = NewHistogramVec(
+		"isme_scheduler_job_duration_seconds",
+		"Scheduler job run time by job.",
+		[]float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		"job",
+	)
+	SchedulerJobLastSuccess = NewGaugeVec(
+		"isme_scheduler_job_last_success_timestamp_seconds",
+		"Unix time of each job's last successful run.",
+		"job",
+	)
+)
+
+func init() {
+	Default.Register(
+		HTTPRequests,
+		HTTPRequestDuration,
+		Logins,
+		TokenRefreshes,
+		SSOHandshakes,
+		DBQueryDuration,
+		DBQueryErrors,
+		SchedulerJobRuns,
+		SchedulerJobDuration,
+		SchedulerJobLastSuccess,
+	)
+}
+
+// ObserveLogin counts one login attempt.
+func ObserveLogin(reason string) {
+	result := ResultFailure
+	if reason == LoginReasonSuccess {
+		result = ResultSuccess
+	}
+	Logins.With(result, reason).Inc()
+}
+
+// ObserveSSO counts one SSO handshake step; an empty app is SSOAppUnknown.
+func ObserveSSO(app, step, result string) {
+	if app == "" {
+		app = SSOAppUnknown
+	}
+	SSOHandshakes.With(app, step, result).Inc()
+}
+
+// InstrumentJob wraps a scheduler job body with run counts, duration and the
+// last-success timestamp.
+func InstrumentJob(job string, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := run(ctx)
+		SchedulerJobDuration.With(job).Observe(time.Since(start).Seconds())
+		if err != nil {
+			SchedulerJobRuns.With(job, ResultError).Inc()
+			return err
+		}
+		SchedulerJobRuns.With(job, ResultSuccess).Inc()
+		SchedulerJobLastSuccess.With(job).Set(float64(time.Now().Unix()))
+		return nil
+	}
+}
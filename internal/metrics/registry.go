@@ -0,0 +1,366 @@
+// Package metrics is isme's Prometheus instrumentation: a small registry that
+// renders the Prometheus text exposition format (version 0.0.4), the isme metric
+// families, and the hooks that feed them (HTTP middleware, bun query hook,
+// scheduler job wrapper).
+//
+// It implements only what isme exposes — counters, gauges, histograms and
+// scrape-time gauge functions — rather than pulling in the full client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets are the default latency buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metric is one metric family that can render itself.
+type Metric interface {
+	Name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds the metric families exposed on /metrics.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]Metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]Metric{}}
+}
+
+// Register adds the metrics, replacing any already registered under the same
+// name — so a rebuilt DI container re-points scrape-time gauges at its own
+// dependencies instead of failing.
+func (r *Registry) Register(metrics ...Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range metrics {
+		r.metrics[m.Name()] = m
+	}
+}
+
+// Write renders every family in name order.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	families := make([]Metric, 0, len(names))
+	for _, name := range names {
+		families = append(families, r.metrics[name])
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range families {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// desc is the identity shared by every family type.
+type desc struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+func (d desc) Name() string {
+	return d.name
+}
+
+func (d desc) writeHeader(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, kind)
+}
+
+// vec stores one child per distinct label-value tuple.
+type vec[T any] struct {
+	desc
+	mu       sync.RWMutex
+	children map[string]*child[T]
+	newChild func() T
+}
+
+type child[T any] struct {
+	values []string
+	metric T
+}
+
+func newVec[T any](name, help string, labelNames []string, newChild func() T) vec[T] {
+	return vec[T]{
+		desc:     desc{name: name, help: help, labelNames: labelNames},
+		children: map[string]*child[T]{},
+		newChild: newChild,
+	}
+}
+
+func (v *vec[T]) with(values ...string) T {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labelNames), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	c, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return c.metric
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok := v.children[key]; ok {
+		return c.metric
+	}
+	c = &child[T]{values: append([]string(nil), values...), metric: v.newChild()}
+	v.children[key] = c
+	return c.metric
+}
+
+// sorted returns the children in label order so output is deterministic.
+func (v *vec[T]) sorted() []*child[T] {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	keys := make([]string, 0, len(v.children))
+	for key := range v.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	children := make([]*child[T], 0, len(keys))
+	for _, key := range keys {
+		children = append(children, v.children[key])
+	}
+	return children
+}
+
+// value is a float64 updated atomically.
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if v.bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+func (v *value) set(f float64) {
+	v.bits.Store(math.Float64bits(f))
+}
+
+func (v *value) get() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+// Counter only goes up.
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add increments by delta; negative deltas are ignored.
+func (c *Counter) Add(delta float64) {
+	if delta > 0 {
+		c.v.add(delta)
+	}
+}
+
+// Value returns the current count.
+func (c *Counter) Value() float64 {
+	return c.v.get()
+}
+
+type CounterVec struct {
+	vec[*Counter]
+}
+
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, labelNames, func() *Counter { return &Counter{} })}
+}
+
+func (v *CounterVec) With(values ...string) *Counter {
+	return v.with(values...)
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.writeHeader(w, "counter")
+	for _, c := range v.sorted() {
+		writeSample(w, v.name, v.labelNames, c.values, "", "", c.metric.v.get())
+	}
+}
+
+// Gauge goes up and down.
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(f float64) {
+	g.v.set(f)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.v.add(delta)
+}
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 {
+	return g.v.get()
+}
+
+type GaugeVec struct {
+	vec[*Gauge]
+}
+
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{newVec(name, help, labelNames, func() *Gauge { return &Gauge{} })}
+}
+
+func (v *GaugeVec) With(values ...string) *Gauge {
+	return v.with(values...)
+}
+
+func (v *GaugeVec) write(w *bufio.Writer) {
+	v.writeHeader(w, "gauge")
+	for _, c := range v.sorted() {
+		writeSample(w, v.name, v.labelNames, c.values, "", "", c.metric.v.get())
+	}
+}
+
+// GaugeFunc is a gauge whose value is read at scrape time.
+type GaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	return &GaugeFunc{desc: desc{name: name, help: help}, fn: fn}
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.writeHeader(w, "gauge")
+	writeSample(w, g.name, nil, nil, "", "", g.fn())
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomic.Uint64
+	count       atomic.Uint64
+	sum         value
+}
+
+func (h *Histogram) Observe(f float64) {
+	// counts are per bucket here and made cumulative when written
+	i := sort.SearchFloat64s(h.upperBounds, f)
+	if i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+	h.count.Add(1)
+	h.sum.add(f)
+}
+
+type HistogramVec struct {
+	vec[*Histogram]
+	buckets []float64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{
+		vec: newVec(name, help, labelNames, func() *Histogram {
+			return &Histogram{upperBounds: buckets, counts: make([]atomic.Uint64, len(buckets))}
+		}),
+		buckets: buckets,
+	}
+}
+
+func (v *HistogramVec) With(values ...string) *Histogram {
+	return v.with(values...)
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.writeHeader(w, "histogram")
+	for _, c := range v.sorted() {
+		h := c.metric
+		var cumulative uint64
+		for i, bound := range h.upperBounds {
+			cumulative += h.counts[i].Load()
+			writeSample(w, v.name+"_bucket", v.labelNames, c.values, "le", formatFloat(bound), float64(cumulative))
+		}
+		count := h.count.Load()
+		writeSample(w, v.name+"_bucket", v.labelNames, c.values, "le", "+Inf", float64(count))
+		writeSample(w, v.name+"_sum", v.labelNames, c.values, "", "", h.sum.get())
+		writeSample(w, v.name+"_count", v.labelNames, c.values, "", "", float64(count))
+	}
+}
+
+// writeSample writes one line; extraName/extraValue append a trailing label
+// (the histogram "le").
+func writeSample(w *bufio.Writer, name string, labelNames, labelValues []string, extraName, extraValue string, f float64) {
+	w.WriteString(name)
+	if len(labelNames) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, labelName, escapeLabelValue(labelValues[i]))
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(f))
+	w.WriteByte('\n')
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
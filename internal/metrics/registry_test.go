@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRegistryWritesTextFormat(t *testing.T) {
+	r := NewRegistry()
+	requests := NewCounterVec("test_requests_total", "Requests.\nSecond line.", "route", "status")
+	inflight := NewGaugeVec("test_inflight", "In flight.", "route")
+	r.Register(requests, inflight, NewGaugeFunc("test_entries", "Entries.", func() float64 { return 7 }))
+
+	requests.With("/b", "200").Add(2)
+	requests.With("/a", "500").Inc()
+	requests.With("/a", "500").Add(-5) // ignored
+	inflight.With(`/q"x\`).Set(3)
+	inflight.With(`/q"x\`).Add(-1)
+
+	want := `# HELP test_entries Entries.
+# TYPE test_entries gauge
+test_entries 7
+# HELP test_inflight In flight.
+# TYPE test_inflight gauge
+test_inflight{route="/q\"x\\"} 2
+# HELP test_requests_total Requests.\nSecond line.
+# TYPE test_requests_total counter
+test_requests_total{route="/a",status="500"} 1
+test_requests_total{route="/b",status="200"} 2
+`
+	if got := render(t, r); got != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramBucketsAreCumulative(t *testing.T) {
+	r := NewRegistry()
+	latency := NewHistogramVec("test_seconds", "Latency.", []float64{1, 0.1}, "op")
+	r.Register(latency)
+
+	for _, v := range []float64{0.05, 0.1, 0.5, 2} {
+		latency.With("select").Observe(v)
+	}
+
+	want := `# HELP test_seconds Latency.
+# TYPE test_seconds histogram
+test_seconds_bucket{op="select",le="0.1"} 2
+test_seconds_bucket{op="select",le="1"} 3
+test_seconds_bucket{op="select",le="+Inf"} 4
+test_seconds_sum{op="select"} 2.65
+test_seconds_count{op="select"} 4
+`
+	if got := render(t, r); got != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegisterReplacesByName(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeFunc("test_entries", "Entries.", func() float64 { return 1 }))
+	r.Register(NewGaugeFunc("test_entries", "Entries.", func() float64 { return math.NaN() }))
+
+	got := render(t, r)
+	if strings.Count(got, "# TYPE test_entries") != 1 || !strings.Contains(got, "test_entries NaN\n") {
+		t.Fatalf("expected the second registration to win, got:\n%s", got)
+	}
+}
+
+func TestWithPanicsOnLabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a missing label value")
+		}
+	}()
+	NewCounterVec("test_total", "Total.", "a", "b").With("only-one")
+}
@@ -5,6 +5,7 @@ import (
 
 	authModels "github.com/vukyn/isme/internal/domains/auth/models"
 	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	"github.com/vukyn/isme/internal/tracing"
 
 	"github.com/vukyn/kuery/log"
 
@@ -27,7 +28,7 @@ func (m *Middleware) AuthMiddleware(c *fiber.Ctx) error {
 
 	tokenStr := tokenParts[1]
 
-	verifyTokenResponse, err := m.authUC.VerifyToken(pkgCtx.NewContextFromFiberCtx(c), authModels.VerifyTokenRequest{
+	verifyTokenResponse, err := m.authUC.VerifyToken(tracing.NewContextFromFiberCtx(c), authModels.VerifyTokenRequest{
 		Token: tokenStr,
 	})
 	if err != nil {
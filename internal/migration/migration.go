@@ -0,0 +1,449 @@
+// Package migration reports on the schema migrations of an isme database:
+// which are applied and which pending, what SQL the pending ones would run,
+// and whether the database drifted from what its migrations left behind.
+//
+// The migration runner keeps its own `migrations` table (id, name,
+// executed_at). Next to it this package keeps migration_checksums, one row per
+// applied migration with the checksum of the migration's code when it was
+// applied and a fingerprint of the live schema right after. Verify compares
+// both against the present: a changed code checksum means a migration was
+// edited after it ran, a changed schema fingerprint that the schema was
+// altered outside the migrations.
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+const (
+	// migrationsTable is the runner's bookkeeping table.
+	migrationsTable = "migrations"
+	// ChecksumsTable holds the checksums Record writes. Like migrationsTable it
+	// belongs to the tooling, not the application schema.
+	ChecksumsTable = "migration_checksums"
+)
+
+// Entry is one migration in a Status report.
+type Entry struct {
+	Name string `json:"name"`
+	// AppliedAt is zero for a pending migration.
+	AppliedAt time.Time `json:"applied_at,omitzero"`
+	// Pending marks a migration this build has that the database has not run.
+	Pending bool `json:"pending,omitempty"`
+	// Unknown marks a migration the database ran that this build does not
+	// have: the database was migrated by a newer build.
+	Unknown bool `json:"unknown,omitempty"`
+	// Checksum is the code checksum recorded when the migration was applied,
+	// empty when none was.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+type appliedRow struct {
+	ID         int64     `bun:"id"`
+	Name       string    `bun:"name"`
+	ExecutedAt time.Time `bun:"executed_at"`
+}
+
+type checksumRow struct {
+	Name           string    `bun:"name"`
+	Checksum       string    `bun:"checksum"`
+	SchemaChecksum string    `bun:"schema_checksum"`
+	RecordedAt     time.Time `bun:"recorded_at"`
+}
+
+// Status lists the applied migrations in the order they ran, then the
+// pending ones in the order they will run. migrations is the set this build
+// applies in order; optional names may be applied but need not be (the
+// fresh-install baseline). A database without a migrations table has
+// everything pending.
+func Status(ctx context.Context, db bun.IDB, migrations []pkgMigrate.Migration, optional ...string) ([]Entry, error) {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := recordedChecksums(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	known := names(migrations)
+
+	entries := make([]Entry, 0, len(applied)+len(migrations))
+	done := map[string]bool{}
+	for _, row := range applied {
+		done[row.Name] = true
+		entries = append(entries, Entry{
+			Name:      row.Name,
+			AppliedAt: row.ExecutedAt,
+			Unknown:   !slices.Contains(known, row.Name) && !slices.Contains(optional, row.Name),
+			Checksum:  checksums[row.Name].Checksum,
+		})
+	}
+	for _, name := range known {
+		if !done[name] {
+			entries = append(entries, Entry{Name: name, Pending: true})
+		}
+	}
+	return entries, nil
+}
+
+// Pending returns the names of the migrations this build has that the
+// database has not run.
+func Pending(ctx context.Context, db bun.IDB, migrations []pkgMigrate.Migration) ([]string, error) {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	done := map[string]bool{}
+	for _, row := range applied {
+		done[row.Name] = true
+	}
+	pending := []string{}
+	for _, migration := range migrations {
+		if !done[migration.Name] {
+			pending = append(pending, migration.Name)
+		}
+	}
+	return pending, nil
+}
+
+// Applied returns the names of the applied migrations in the order they ran.
+func Applied(ctx context.Context, db bun.IDB) ([]string, error) {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(applied))
+	for i, row := range applied {
+		names[i] = row.Name
+	}
+	return names, nil
+}
+
+// Statement is one query a migration ran during DryRun.
+type Statement struct {
+	Migration string
+	Query     string
+}
+
+// DryRun runs every pending migration inside a transaction that is always
+// rolled back and returns the statements they issued, so nothing is
+// committed and later migrations see the effect of earlier ones. The runner's
+// own bookkeeping insert is not part of the result. A migration that fails
+// stops the run; its error is returned with the statements issued so far.
+func DryRun(ctx context.Context, db *bun.DB, migrations []pkgMigrate.Migration) ([]Statement, error) {
+	pending, err := Pending(ctx, db, migrations)
+	if err != nil {
+		return nil, err
+	}
+	// the hook goes on a copy of db, so it is gone with the dry run
+	recorder := &statementRecorder{}
+	recorded := db.WithQueryHook(recorder)
+
+	errRollback := errors.New("dry run")
+	err = recorded.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		defer func() { recorder.migration = "" }()
+		for _, migration := range migrations {
+			if !slices.Contains(pending, migration.Name) {
+				continue
+			}
+			recorder.migration = migration.Name
+			if err := migration.Up(tx); err != nil {
+				return fmt.Errorf("%s: %w", migration.Name, err)
+			}
+		}
+		return errRollback
+	})
+	if errors.Is(err, errRollback) {
+		err = nil
+	}
+	return recorder.statements, err
+}
+
+// statementRecorder is a query hook collecting the statements issued while a
+// migration is set.
+type statementRecorder struct {
+	migration  string
+	statements []Statement
+}
+
+var _ bun.QueryHook = (*statementRecorder)(nil)
+
+func (r *statementRecorder) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	if r.migration != "" {
+		r.statements = append(r.statements, Statement{Migration: r.migration, Query: event.Query})
+	}
+	return ctx
+}
+
+func (r *statementRecorder) AfterQuery(context.Context, *bun.QueryEvent) {}
+
+// Record stores the code checksum of each named migration, as given by
+// checksum, together with the current schema fingerprint. Call it right
+// after the runner applied them; an existing row is overwritten. An empty
+// checksum means the migration's code is not tracked: its row only carries
+// the fingerprint.
+func Record(ctx context.Context, db bun.IDB, applied []string, checksum func(name string) (string, error)) error {
+	if len(applied) == 0 {
+		return nil
+	}
+	if err := ensureChecksumsTable(ctx, db); err != nil {
+		return err
+	}
+	fingerprint, err := Fingerprint(ctx, db)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, name := range applied {
+		sum, err := checksum(name)
+		if err != nil {
+			return err
+		}
+		row := &checksumRow{Name: name, Checksum: sum, SchemaChecksum: fingerprint, RecordedAt: now}
+		if _, err := db.NewInsert().Model(row).ModelTableExpr(ChecksumsTable).
+			On("CONFLICT (name) DO UPDATE").
+			Set("checksum = EXCLUDED.checksum").
+			Set("schema_checksum = EXCLUDED.schema_checksum").
+			Set("recorded_at = EXCLUDED.recorded_at").
+			Exec(ctx); err != nil {
+			return fmt.Errorf("record checksum of %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Forget removes the checksums of migrations that are no longer applied,
+// after a rollback, and stamps the current schema as what the last remaining
+// migration left behind.
+func Forget(ctx context.Context, db bun.IDB) error {
+	exists, err := tableExists(ctx, db, ChecksumsTable)
+	if err != nil || !exists {
+		return err
+	}
+	applied, err := Applied(ctx, db)
+	if err != nil {
+		return err
+	}
+	remove := db.NewDelete().TableExpr(ChecksumsTable)
+	if len(applied) > 0 {
+		remove = remove.Where("name NOT IN (?)", bun.In(applied))
+	} else {
+		remove = remove.Where("1 = 1")
+	}
+	if _, err := remove.Exec(ctx); err != nil {
+		return fmt.Errorf("forget rolled back checksums: %w", err)
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+	fingerprint, err := Fingerprint(ctx, db)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewUpdate().TableExpr(ChecksumsTable).
+		Set("schema_checksum = ?", fingerprint).
+		Where("name = ?", applied[len(applied)-1]).
+		Exec(ctx)
+	return err
+}
+
+// Drift is what Verify found.
+type Drift struct {
+	// Changed are applied migrations whose code differs from the checksum
+	// recorded when they ran.
+	Changed []string `json:"changed"`
+	// Unrecorded are applied migrations with no checksum, typically applied
+	// before checksums were kept.
+	Unrecorded []string `json:"unrecorded"`
+	// SchemaChanged reports that the live schema no longer matches the
+	// fingerprint recorded after the last applied migration.
+	SchemaChanged bool `json:"schema_changed"`
+	// ExpectedSchema and ActualSchema are the recorded and live fingerprints.
+	ExpectedSchema string `json:"expected_schema,omitempty"`
+	ActualSchema   string `json:"actual_schema"`
+}
+
+// OK reports whether no drift was found. Unrecorded migrations cannot be
+// checked but are not drift.
+func (d Drift) OK() bool {
+	return len(d.Changed) == 0 && !d.SchemaChanged
+}
+
+// Verify recomputes the code checksum of every applied migration with
+// checksum and the live schema fingerprint, and compares them with what
+// Record stored. Migrations checksum does not know (applied by a newer build)
+// or gives an empty checksum for are skipped.
+func Verify(ctx context.Context, db bun.IDB, checksum func(name string) (string, error)) (Drift, error) {
+	drift := Drift{Changed: []string{}, Unrecorded: []string{}}
+	applied, err := Applied(ctx, db)
+	if err != nil {
+		return drift, err
+	}
+	recorded, err := recordedChecksums(ctx, db)
+	if err != nil {
+		return drift, err
+	}
+	for _, name := range applied {
+		row, ok := recorded[name]
+		if !ok {
+			drift.Unrecorded = append(drift.Unrecorded, name)
+			continue
+		}
+		sum, err := checksum(name)
+		if err != nil || sum == "" {
+			continue
+		}
+		if sum != row.Checksum {
+			drift.Changed = append(drift.Changed, name)
+		}
+	}
+
+	if drift.ActualSchema, err = Fingerprint(ctx, db); err != nil {
+		return drift, err
+	}
+	if len(applied) > 0 {
+		if row, ok := recorded[applied[len(applied)-1]]; ok {
+			drift.ExpectedSchema = row.SchemaChecksum
+			drift.SchemaChanged = row.SchemaChecksum != drift.ActualSchema
+		}
+	}
+	return drift, nil
+}
+
+// Fingerprint hashes the application schema: every table, column, index and
+// constraint outside the bookkeeping tables, as the database describes them.
+// The fingerprint depends on the dialect, so it is only compared within one
+// database.
+func Fingerprint(ctx context.Context, db bun.IDB) (string, error) {
+	lines, err := Schema(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	for _, line := range lines {
+		fmt.Fprintln(sum, line)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// whitespace collapses the layout of the CREATE statements SQLite keeps.
+var whitespace = regexp.MustCompile(`\s+`)
+
+// Schema describes the application schema as sorted lines, one per table,
+// column, index or constraint.
+func Schema(ctx context.Context, db bun.IDB) ([]string, error) {
+	var lines []string
+	if db.Dialect().Name() == dialect.PG {
+		if err := db.NewRaw(`
+			SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type || ' ' ||
+				is_nullable || ' ' || COALESCE(column_default, '')
+			FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name NOT IN (?, ?)
+			UNION ALL
+			SELECT 'index ' || tablename || '.' || indexname || ' ' || indexdef
+			FROM pg_indexes
+			WHERE schemaname = current_schema() AND tablename NOT IN (?, ?)
+			UNION ALL
+			SELECT 'constraint ' || rel.relname || '.' || c.conname || ' ' || pg_get_constraintdef(c.oid)
+			FROM pg_constraint c JOIN pg_class rel ON rel.oid = c.conrelid
+			WHERE rel.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())
+				AND rel.relname NOT IN (?, ?)`,
+			migrationsTable, ChecksumsTable, migrationsTable, ChecksumsTable, migrationsTable, ChecksumsTable).
+			Scan(ctx, &lines); err != nil {
+			return nil, fmt.Errorf("read schema: %w", err)
+		}
+	} else {
+		var objects []struct {
+			Type string         `bun:"type"`
+			Name string         `bun:"name"`
+			SQL  sql.NullString `bun:"sql"`
+		}
+		if err := db.NewRaw(`SELECT type, name, sql FROM sqlite_master
+			WHERE name NOT LIKE 'sqlite_%' AND tbl_name NOT IN (?, ?)`,
+			migrationsTable, ChecksumsTable).Scan(ctx, &objects); err != nil {
+			return nil, fmt.Errorf("read schema: %w", err)
+		}
+		for _, object := range objects {
+			definition := strings.TrimSpace(whitespace.ReplaceAllString(object.SQL.String, " "))
+			lines = append(lines, object.Type+" "+object.Name+" "+definition)
+		}
+	}
+	slices.Sort(lines)
+	return lines, nil
+}
+
+func appliedMigrations(ctx context.Context, db bun.IDB) ([]appliedRow, error) {
+	exists, err := tableExists(ctx, db, migrationsTable)
+	if err != nil || !exists {
+		return nil, err
+	}
+	rows := []appliedRow{}
+	if err := db.NewRaw(`SELECT id, name, executed_at FROM ? ORDER BY id`, bun.Ident(migrationsTable)).Scan(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	return rows, nil
+}
+
+func recordedChecksums(ctx context.Context, db bun.IDB) (map[string]checksumRow, error) {
+	recorded := map[string]checksumRow{}
+	exists, err := tableExists(ctx, db, ChecksumsTable)
+	if err != nil || !exists {
+		return recorded, err
+	}
+	rows := []checksumRow{}
+	if err := db.NewRaw(`SELECT name, checksum, schema_checksum, recorded_at FROM ?`, bun.Ident(ChecksumsTable)).Scan(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("read migration checksums: %w", err)
+	}
+	for _, row := range rows {
+		recorded[row.Name] = row
+	}
+	return recorded, nil
+}
+
+func ensureChecksumsTable(ctx context.Context, db bun.IDB) error {
+	timeType := "DATETIME"
+	if db.Dialect().Name() == dialect.PG {
+		timeType = "TIMESTAMPTZ"
+	}
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+ChecksumsTable+` (
+		name TEXT PRIMARY KEY NOT NULL,
+		checksum TEXT NOT NULL,
+		schema_checksum TEXT NOT NULL,
+		recorded_at `+timeType+` NOT NULL
+	)`)
+	return err
+}
+
+func tableExists(ctx context.Context, db bun.IDB, table string) (bool, error) {
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if db.Dialect().Name() == dialect.PG {
+		query = `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?`
+	}
+	var n int
+	if err := db.NewRaw(query, table).Scan(ctx, &n); err != nil {
+		return false, fmt.Errorf("look up table %s: %w", table, err)
+	}
+	return n > 0, nil
+}
+
+func names(migrations []pkgMigrate.Migration) []string {
+	names := make([]string, len(migrations))
+	for i, migration := range migrations {
+		names[i] = migration.Name
+	}
+	return names
+}
@@ -0,0 +1,236 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+
+	pkgMigrate "github.com/vukyn/kuery/bun/migrate"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+var testMigrations = []pkgMigrate.Migration{
+	{Name: "001_create_things", Up: exec(`CREATE TABLE things (id TEXT PRIMARY KEY)`)},
+	{Name: "002_add_label", Up: exec(`ALTER TABLE things ADD COLUMN label TEXT`)},
+	{Name: "003_seed_thing", Up: exec(`INSERT INTO things (id, label) VALUES ('a', 'first')`)},
+}
+
+func exec(query string) func(bun.IDB) error {
+	return func(db bun.IDB) error {
+		_, err := db.ExecContext(context.Background(), query)
+		return err
+	}
+}
+
+// fakeChecksum stands in for the source checksum; edited simulates a
+// migration changed after it ran.
+func fakeChecksum(edited ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if slices.Contains(edited, name) {
+			return "edited-" + name, nil
+		}
+		return "sum-" + name, nil
+	}
+}
+
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqldb, err := sql.Open(sqliteshim.ShimName, ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory sqlite: %v", err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// apply runs migrations the way the runner does: Up, then the bookkeeping row.
+func apply(t *testing.T, db *bun.DB, migrations ...pkgMigrate.Migration) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS migrations (
+		id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE, executed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range migrations {
+		if err := migration.Up(db); err != nil {
+			t.Fatalf("%s: %v", migration.Name, err)
+		}
+		if _, err := db.ExecContext(ctx, `INSERT INTO migrations (name) VALUES (?)`, migration.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStatusAndPending(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	pending, err := Pending(ctx, db, testMigrations)
+	if err != nil {
+		t.Fatalf("Pending on a fresh database: %v", err)
+	}
+	if len(pending) != 3 {
+		t.Fatalf("fresh database: pending = %v, want all three", pending)
+	}
+
+	apply(t, db, testMigrations[0], pkgMigrate.Migration{Name: "000_baseline", Up: exec(`SELECT 1`)})
+	entries, err := Status(ctx, db, testMigrations, "000_baseline")
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		state := "applied"
+		if entry.Pending {
+			state = "pending"
+		}
+		if entry.Unknown {
+			state = "unknown"
+		}
+		if !entry.Pending && entry.AppliedAt.IsZero() {
+			t.Errorf("%s: applied without a time", entry.Name)
+		}
+		got = append(got, entry.Name+" "+state)
+	}
+	want := []string{"001_create_things applied", "000_baseline applied", "002_add_label pending", "003_seed_thing pending"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("status = %v, want %v", got, want)
+	}
+
+	if entries, _ := Status(ctx, db, testMigrations[1:]); !entries[0].Unknown {
+		t.Fatal("a migration this build does not have must be unknown")
+	}
+}
+
+func TestDryRunCommitsNothing(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	apply(t, db, testMigrations[0])
+
+	statements, err := DryRun(ctx, db, testMigrations)
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if len(statements) != 2 || statements[0].Migration != "002_add_label" || !strings.Contains(statements[1].Query, "INSERT INTO things") {
+		t.Fatalf("statements = %+v", statements)
+	}
+	// the seed only works because the ALTER ran first, inside the transaction
+	columns, err := Schema(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range columns {
+		if strings.Contains(line, "label") {
+			t.Fatalf("dry run left the schema changed: %s", line)
+		}
+	}
+	if pending, _ := Pending(ctx, db, testMigrations); len(pending) != 2 {
+		t.Fatalf("dry run applied migrations: pending = %v", pending)
+	}
+
+	failing := append(slices.Clone(testMigrations), pkgMigrate.Migration{Name: "004_broken", Up: exec(`ALTER TABLE nope ADD COLUMN x TEXT`)})
+	if _, err := DryRun(ctx, db, failing); err == nil || !strings.Contains(err.Error(), "004_broken") {
+		t.Fatalf("a failing migration must be reported, err = %v", err)
+	}
+}
+
+func TestVerifyDetectsDrift(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	apply(t, db, testMigrations...)
+
+	drift, err := Verify(ctx, db, fakeChecksum())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !drift.OK() || len(drift.Unrecorded) != 3 {
+		t.Fatalf("before recording: drift = %+v, want ok with three unrecorded", drift)
+	}
+
+	if err := Record(ctx, db, []string{"001_create_things", "002_add_label", "003_seed_thing"}, fakeChecksum()); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if drift, _ := Verify(ctx, db, fakeChecksum()); !drift.OK() || len(drift.Unrecorded) != 0 {
+		t.Fatalf("after recording: drift = %+v", drift)
+	}
+
+	drift, _ = Verify(ctx, db, fakeChecksum("002_add_label"))
+	if drift.OK() || !slices.Equal(drift.Changed, []string{"002_add_label"}) {
+		t.Fatalf("edited migration: drift = %+v", drift)
+	}
+
+	// rows are not schema: only a hand-made schema change is drift
+	if _, err := db.ExecContext(ctx, `INSERT INTO things (id) VALUES ('b')`); err != nil {
+		t.Fatal(err)
+	}
+	if drift, _ := Verify(ctx, db, fakeChecksum()); drift.SchemaChanged {
+		t.Fatal("a data change was reported as schema drift")
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX idx_things_label ON things (label)`); err != nil {
+		t.Fatal(err)
+	}
+	if drift, _ := Verify(ctx, db, fakeChecksum()); !drift.SchemaChanged || drift.OK() {
+		t.Fatalf("hand-added index: drift = %+v", drift)
+	}
+}
+
+func TestVerifySkipsUntrackedCode(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	apply(t, db, testMigrations[0])
+	untracked := func(string) (string, error) { return "", nil }
+
+	// a checksum recorded before the code went untracked is no longer compared
+	if err := Record(ctx, db, []string{"001_create_things"}, fakeChecksum()); err != nil {
+		t.Fatal(err)
+	}
+	if drift, _ := Verify(ctx, db, untracked); !drift.OK() {
+		t.Fatalf("untracked code reported as drift: %+v", drift)
+	}
+
+	// its row still carries the fingerprint the schema is checked against
+	if err := Record(ctx, db, []string{"001_create_things"}, untracked); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX idx_things_id ON things (id)`); err != nil {
+		t.Fatal(err)
+	}
+	if drift, _ := Verify(ctx, db, untracked); !drift.SchemaChanged || len(drift.Changed) != 0 {
+		t.Fatalf("hand-added index: drift = %+v", drift)
+	}
+}
+
+func TestForgetAfterRollback(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	apply(t, db, testMigrations[:2]...)
+	if err := Record(ctx, db, []string{"001_create_things", "002_add_label"}, fakeChecksum()); err != nil {
+		t.Fatal(err)
+	}
+
+	// roll 002 back the way the runner would
+	if _, err := db.ExecContext(ctx, `ALTER TABLE things DROP COLUMN label`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM migrations WHERE name = '002_add_label'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Forget(ctx, db); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+
+	entries, _ := Status(ctx, db, testMigrations)
+	if entries[0].Checksum == "" || entries[1].Checksum != "" {
+		t.Fatalf("after rollback: %+v", entries)
+	}
+	if drift, _ := Verify(ctx, db, fakeChecksum()); !drift.OK() {
+		t.Fatalf("after rollback the schema must be current: %+v", drift)
+	}
+}
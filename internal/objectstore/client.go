@@ -0,0 +1,230 @@
+// Package objectstore is a minimal client for S3-compatible object storage
+// (AWS S3, Cloudflare R2, MinIO): put, get, list and delete objects in one
+// private bucket, signed with AWS Signature Version 4. It covers what the
+// off-host database backups need and nothing more.
+package objectstore
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultRegion is used when Config.Region is empty. R2 accepts "auto" and
+// MinIO any region; AWS needs the bucket's real region.
+const defaultRegion = "us-east-1"
+
+// ErrNotFound is returned by Get for a missing key.
+var ErrNotFound = errors.New("object not found")
+
+// Config locates the bucket and holds the credentials to reach it.
+type Config struct {
+	// Endpoint is the service origin, e.g. https://<account>.r2.cloudflarestorage.com
+	// or http://127.0.0.1:9000 for MinIO.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<endpoint>/<key>. MinIO and R2 accept both; most self-hosted
+	// stores only the former.
+	PathStyle bool
+	// HTTPClient defaults to a client with a 5 minute timeout.
+	HTTPClient *http.Client
+}
+
+// Object is one entry of a List.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Client talks to one bucket.
+type Client struct {
+	cfg      Config
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+}
+
+// New validates cfg and returns a client for its bucket.
+func New(cfg Config) (*Client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("objectstore: endpoint and bucket are required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("objectstore: access key id and secret access key are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("objectstore: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &Client{cfg: cfg, endpoint: endpoint, http: client, now: time.Now}, nil
+}
+
+// Bucket returns the bucket name, for messages.
+func (c *Client) Bucket() string { return c.cfg.Bucket }
+
+// Put uploads size bytes from body as key, replacing any existing object.
+func (c *Client) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := c.do(req, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	return drain(res)
+}
+
+// Get downloads key. The caller closes the returned body.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	return drain(res)
+}
+
+// List returns every object whose key starts with prefix, in key order,
+// following ListObjectsV2 continuation tokens.
+func (c *Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	objects := []Object{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := c.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.do(req, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+		var page listBucketResult
+		err = xml.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("objectstore: decode list: %w", err)
+		}
+		for _, content := range page.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Error is a non-2xx response from the store.
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("objectstore: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("objectstore: HTTP %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := *c.endpoint
+	path := strings.TrimRight(u.Path, "/")
+	if c.cfg.PathStyle {
+		path += "/" + c.cfg.Bucket
+	} else {
+		u.Host = c.cfg.Bucket + "." + u.Host
+	}
+	path += "/" + key
+	u.Path = path
+	// Send the path exactly as it is signed.
+	u.RawPath = canonicalPath(&u)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: %w", err)
+	}
+	return req, nil
+}
+
+// do signs and sends req, turning a non-2xx response into an *Error (or
+// ErrNotFound for a missing key).
+func (c *Client) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	sign(req, c.cfg.AccessKeyID, c.cfg.SecretAccessKey, c.cfg.Region, payloadHash, c.now())
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: %w", err)
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	apiErr := &Error{StatusCode: res.StatusCode}
+	_ = xml.NewDecoder(io.LimitReader(res.Body, 64<<10)).Decode(apiErr)
+	if res.StatusCode == http.StatusNotFound && (apiErr.Code == "" || apiErr.Code == "NoSuchKey") {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, req.URL.Path)
+	}
+	return nil, apiErr
+}
+
+func drain(res *http.Response) error {
+	defer res.Body.Close()
+	_, err := io.Copy(io.Discard, res.Body)
+	return err
+}
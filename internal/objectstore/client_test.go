@@ -0,0 +1,84 @@
+package objectstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/vukyn/isme/internal/objectstore"
+	"github.com/vukyn/isme/internal/objectstore/objectstoretest"
+)
+
+func TestClientRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := objectstoretest.New(t)
+	client := store.Client(t)
+
+	for _, key := range []string{"backups/app-1.db", "backups/app-2.db", "backups/app 3.db", "other/x.db"} {
+		data := []byte("data of " + key)
+		if err := client.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/octet-stream"); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+	}
+
+	objects, err := client.List(ctx, "backups/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	if want := []string{"backups/app 3.db", "backups/app-1.db", "backups/app-2.db"}; !slices.Equal(keys, want) {
+		t.Fatalf("List keys = %v, want %v (across continuation pages)", keys, want)
+	}
+
+	body, err := client.Get(ctx, "backups/app 3.db")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "data of backups/app 3.db" {
+		t.Fatalf("Get = %q", data)
+	}
+
+	if err := client.Delete(ctx, "backups/app-1.db"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := client.Get(ctx, "backups/app-1.db"); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientSurfacesStoreErrors(t *testing.T) {
+	store := objectstoretest.New(t)
+	cfg := store.Config()
+	cfg.AccessKeyID = "someone-else"
+	client, err := objectstore.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.List(context.Background(), "")
+	var apiErr *objectstore.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 403 || apiErr.Code != "AccessDenied" {
+		t.Fatalf("List with a foreign key = %v, want a 403 AccessDenied *Error", err)
+	}
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	for _, cfg := range []objectstore.Config{
+		{Bucket: "b", AccessKeyID: "k", SecretAccessKey: "s"},
+		{Endpoint: "http://127.0.0.1:9000", AccessKeyID: "k", SecretAccessKey: "s"},
+		{Endpoint: "http://127.0.0.1:9000", Bucket: "b"},
+		{Endpoint: "not a url", Bucket: "b", AccessKeyID: "k", SecretAccessKey: "s"},
+	} {
+		if _, err := objectstore.New(cfg); err == nil {
+			t.Fatalf("New(%+v) accepted an incomplete config", cfg)
+		}
+	}
+}
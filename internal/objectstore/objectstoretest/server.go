@@ -0,0 +1,193 @@
+// Package objectstoretest runs an in-memory, S3-compatible stand-in for
+// tests: path-style PUT/GET/DELETE of objects and ListObjectsV2 for a single
+// bucket. It checks that requests are signed with the configured access key
+// but does not verify the signature itself.
+package objectstoretest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vukyn/isme/internal/objectstore"
+)
+
+// Credentials the stand-in accepts.
+const (
+	Bucket          = "isme-test"
+	AccessKeyID     = "test-access-key"
+	SecretAccessKey = "test-secret-key"
+)
+
+// listPageSize is small so tests exercise continuation tokens.
+const listPageSize = 2
+
+type object struct {
+	data         []byte
+	lastModified time.Time
+}
+
+// Server is the running stand-in.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string]object
+	now     func() time.Time
+}
+
+// New starts a stand-in that is closed when the test ends.
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{objects: map[string]object{}, now: time.Now}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Config returns a client config for the stand-in's bucket.
+func (s *Server) Config() objectstore.Config {
+	return objectstore.Config{
+		Endpoint:        s.URL,
+		Bucket:          Bucket,
+		AccessKeyID:     AccessKeyID,
+		SecretAccessKey: SecretAccessKey,
+		PathStyle:       true,
+	}
+}
+
+// Client returns a client for the stand-in's bucket.
+func (s *Server) Client(t testing.TB) *objectstore.Client {
+	t.Helper()
+	client, err := objectstore.New(s.Config())
+	if err != nil {
+		t.Fatalf("objectstore.New: %v", err)
+	}
+	return client
+}
+
+// Put stores an object directly, bypassing the API.
+func (s *Server) Put(key string, data []byte, lastModified time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = object{data: data, lastModified: lastModified}
+}
+
+// Object returns a stored object's bytes.
+func (s *Server) Object(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	return obj.data, ok
+}
+
+// Keys returns the stored keys in order.
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+AccessKeyID+"/") {
+		writeError(w, http.StatusForbidden, "AccessDenied", "missing or foreign signature")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != Bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket "+bucket+" does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		s.list(w, r)
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		if r.ContentLength >= 0 && int64(len(data)) != r.ContentLength {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", "body does not match Content-Length")
+			return
+		}
+		s.objects[key] = object{data: data, lastModified: s.now().UTC().Truncate(time.Second)}
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet:
+		obj, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "key "+key+" does not exist")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Write(obj.data)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+type listContent struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type listResult struct {
+	XMLName               xml.Name      `xml:"ListBucketResult"`
+	Contents              []listContent `xml:"Contents"`
+	IsTruncated           bool          `xml:"IsTruncated"`
+	NextContinuationToken string        `xml:"NextContinuationToken,omitempty"`
+}
+
+// list serves ListObjectsV2; the continuation token is the offset of the
+// next page.
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	keys := []string{}
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	end := min(offset+listPageSize, len(keys))
+	result := listResult{Contents: []listContent{}}
+	for _, key := range keys[min(offset, end):end] {
+		obj := s.objects[key]
+		result.Contents = append(result.Contents, listContent{Key: key, Size: int64(len(obj.data)), LastModified: obj.lastModified})
+	}
+	if end < len(keys) {
+		result.IsTruncated = true
+		result.NextContinuationToken = strconv.Itoa(end)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
@@ -0,0 +1,38 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage string
+
+// SpecHandler serves the document. It is marshaled once up front: the routes
+// are fixed for the life of the process, so there is nothing to rebuild per
+// request.
+func SpecHandler(doc *Document) (fiber.Handler, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(body)
+	}, nil
+}
+
+// DocsHandler serves the self-contained docs viewer, pointed at specURL. The
+// page carries its own script and styles rather than pulling a viewer from a
+// CDN, so it works on air-gapped deployments and under a strict CSP.
+func DocsHandler(specURL string) fiber.Handler {
+	page := strings.ReplaceAll(docsPage, "{{SPEC_URL}}", html.EscapeString(specURL))
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(page)
+	}
+}
@@ -0,0 +1,330 @@
+// Package openapi builds the OpenAPI 3.1 document for the /api/v1 surface from
+// the route tables each domain declares next to its Setup*Routes, reflecting
+// request and response schemas straight off the model types so the spec cannot
+// describe a field the handlers do not bind.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version the document declares.
+const Version = "3.1.0"
+
+// BearerAuth is the security scheme name of the access-token bearer header
+// checked by AuthMiddleware.
+const BearerAuth = "bearerAuth"
+
+// Operation describes one registered route. Path uses Fiber syntax
+// (":param") relative to the API base, exactly as the route constants compose
+// it, so the drift test can compare it against the router verbatim.
+type Operation struct {
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+
+	// Query is a struct whose `query`-tagged fields are bound with
+	// c.QueryParser; Body is the JSON body bound with c.BodyParser. Both are
+	// zero values used only for their type.
+	Query any
+	Body  any
+	// Upload names the multipart form field carrying a file, for handlers that
+	// read c.FormFile instead of a JSON body.
+	Upload string
+
+	// Response is the type of the envelope's data field; nil documents a null
+	// data, which is what pkgHttp.OK(c, nil) sends.
+	Response any
+	// Bare sends Response as the whole body instead of the envelope's data, for
+	// documents a standard client fetches (e.g. the JWKS).
+	Bare bool
+	// Produces replaces the JSON envelope for handlers that stream a raw body
+	// (e.g. the audit export); each entry is a media type.
+	Produces []string
+
+	// Auth marks routes behind AuthMiddleware; Permission is the RBAC code
+	// checked by rbac.RequirePermission on top of it.
+	Auth       bool
+	Permission string
+	// Errors lists status codes beyond the ones implied by the other fields
+	// (400 for a body or query, 401 for Auth, 403 for Permission, 500 always).
+	Errors []int
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem maps a lower-case HTTP method to its operation.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	// Permission is the RBAC code the route requires, as a vendor extension
+	// so clients and the docs viewer can show it.
+	Permission string `json:"x-permission,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Build assembles the document for ops served under basePath (e.g. "/api/v1").
+// It panics on a duplicate method+path: the route tables are static, so that
+// is a programming error caught by the first test run, not a runtime input.
+func Build(info Info, basePath string, ops ...Operation) *Document {
+	reg := newRegistry()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: []Server{{URL: basePath}},
+		Paths:   map[string]*PathItem{},
+	}
+
+	tags := map[string]bool{}
+	for _, op := range ops {
+		path := SpecPath(op.Path)
+		method := strings.ToLower(op.Method)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		if _, dup := (*item)[method]; dup {
+			panic(fmt.Sprintf("openapi: duplicate operation %s %s", op.Method, path))
+		}
+		(*item)[method] = buildOperation(reg, op, path)
+		if op.Tag != "" && !tags[op.Tag] {
+			tags[op.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: op.Tag})
+		}
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	reg.schemas["Response"] = envelopeSchema()
+	reg.schemas["Error"] = errorSchema()
+	doc.Components = Components{
+		Schemas: reg.schemas,
+		SecuritySchemes: map[string]SecurityScheme{
+			BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+	return doc
+}
+
+func buildOperation(reg *registry, op Operation, path string) *OperationObject {
+	obj := &OperationObject{
+		OperationID: operationID(op.Method, path),
+		Summary:     op.Summary,
+		Responses:   map[string]*Response{},
+		Permission:  op.Permission,
+	}
+	if op.Tag != "" {
+		obj.Tags = []string{op.Tag}
+	}
+
+	for _, name := range pathParams(path) {
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	if op.Query != nil {
+		obj.Parameters = append(obj.Parameters, reg.queryParameters(op.Query)...)
+	}
+
+	switch {
+	case op.Body != nil:
+		obj.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: reg.schemaOf(op.Body)},
+		}}
+	case op.Upload != "":
+		obj.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Required:   []string{op.Upload},
+				Properties: map[string]*Schema{op.Upload: {Type: "string", ContentMediaType: "application/octet-stream"}},
+			}},
+		}}
+	}
+
+	if len(op.Produces) > 0 {
+		content := map[string]*MediaType{}
+		for _, mediaType := range op.Produces {
+			content[mediaType] = &MediaType{Schema: &Schema{Type: "string"}}
+		}
+		obj.Responses["200"] = &Response{Description: "OK", Content: content}
+	} else {
+		schema := envelopeOf(reg, op.Response)
+		if op.Bare {
+			schema = reg.schemaOf(op.Response)
+		}
+		obj.Responses["200"] = &Response{Description: "OK", Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		}}
+	}
+
+	codes := map[int]bool{http.StatusInternalServerError: true}
+	if op.Body != nil || op.Query != nil || op.Upload != "" {
+		codes[http.StatusBadRequest] = true
+	}
+	if op.Auth {
+		codes[http.StatusUnauthorized] = true
+		obj.Security = []map[string][]string{{BearerAuth: {}}}
+	}
+	if op.Permission != "" {
+		codes[http.StatusForbidden] = true
+		obj.Description = "Requires the `" + op.Permission + "` permission."
+	}
+	for _, code := range op.Errors {
+		codes[code] = true
+	}
+	for code := range codes {
+		obj.Responses[strconv.Itoa(code)] = &Response{
+			Description: http.StatusText(code),
+			Content: map[string]*MediaType{
+				"application/json": {Schema: &Schema{Ref: refPrefix + "Error"}},
+			},
+		}
+	}
+	return obj
+}
+
+// SpecPath converts a Fiber route path to OpenAPI template syntax
+// ("/roles/:roleID" -> "/roles/{roleID}") and normalizes the slashes the
+// route constants leave to Group (e.g. "auth" + "/login").
+func SpecPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, segment[1:len(segment)-1])
+		}
+	}
+	return params
+}
+
+// operationID derives a stable id from the method and path, e.g.
+// "DELETE /roles/{roleID}/members/{userID}" -> "deleteRolesRoleIDMembersUserID",
+// so generated clients keep their method names across releases.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// envelopeSchema mirrors kuery's http/base.Response, the body every handler
+// answers with through pkgHttp.OK / pkgHttp.Err.
+func envelopeSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"code", "message", "data"},
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Description: "Mirrors the HTTP status code."},
+			"message": {Type: "string"},
+			"data":    {},
+		},
+	}
+}
+
+// errorSchema is the envelope as sent on failure: no data, and a message
+// describing the error.
+func errorSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"code", "message"},
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Description: "Mirrors the HTTP status code."},
+			"message": {Type: "string"},
+		},
+	}
+}
+
+func envelopeOf(reg *registry, data any) *Schema {
+	dataSchema := &Schema{Type: "null"}
+	if data != nil {
+		dataSchema = reg.schemaOf(data)
+	}
+	return &Schema{AllOf: []*Schema{
+		{Ref: refPrefix + "Response"},
+		{Type: "object", Properties: map[string]*Schema{"data": dataSchema}},
+	}}
+}
+
+// Message is the data of handlers that answer with a bare confirmation, e.g.
+// pkgHttp.OK(c, map[string]string{"message": "Logged out successfully"}).
+type Message struct {
+	Message string `json:"message"`
+}
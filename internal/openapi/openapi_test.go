@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type page struct {
+	Page int `json:"page" query:"page"`
+	Size int `json:"size" query:"size"`
+}
+
+type listRequest struct {
+	page
+	Query  string `json:"query" query:"query"`
+	hidden string
+}
+
+type node struct {
+	ID        string            `json:"id"`
+	Note      *string           `json:"note,omitempty"`
+	Parent    *node             `json:"parent"`
+	Children  []node            `json:"children"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"created_at"`
+	Secret    string            `json:"-"`
+}
+
+func TestSpecPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"auth/login":                     "/auth/login",
+		"/roles":                         "/roles",
+		"/roles/:roleID/members/:userID": "/roles/{roleID}/members/{userID}",
+		"app-service/":                   "/app-service",
+	} {
+		if got := SpecPath(in); got != want {
+			t.Errorf("SpecPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSchemaFollowsEncodingJSON(t *testing.T) {
+	reg := newRegistry()
+	ref := reg.schemaOf(node{})
+	if ref.Ref != refPrefix+"OpenapiNode" {
+		t.Fatalf("ref = %q", ref.Ref)
+	}
+	schema := reg.schemas["OpenapiNode"]
+	if _, ok := schema.Properties["Secret"]; ok {
+		t.Error(`json:"-" field documented`)
+	}
+	if got := strings.Join(schema.Required, ","); got != "id,parent,children,labels,created_at" {
+		t.Errorf("required = %s, want every field without omitempty", got)
+	}
+	if typ := schema.Properties["note"].Type; typ.([]string)[1] != "null" {
+		t.Errorf("*string type = %v, want nullable", typ)
+	}
+	if parent := schema.Properties["parent"]; len(parent.AnyOf) != 2 || parent.AnyOf[0].Ref != ref.Ref {
+		t.Errorf("recursive *node = %+v, want a nullable self-reference", parent)
+	}
+	if created := schema.Properties["created_at"]; created.Format != "date-time" {
+		t.Errorf("time.Time = %+v", created)
+	}
+	if labels := schema.Properties["labels"]; labels.AdditionalProperties.Type != "string" {
+		t.Errorf("map = %+v", labels)
+	}
+
+	list := reg.schemas[strings.TrimPrefix(reg.schemaOf(listRequest{}).Ref, refPrefix)]
+	for _, name := range []string{"page", "size", "query"} {
+		if _, ok := list.Properties[name]; !ok {
+			t.Errorf("embedded/own field %q missing: %+v", name, list.Properties)
+		}
+	}
+	if _, ok := list.Properties["hidden"]; ok {
+		t.Error("unexported field documented")
+	}
+}
+
+func TestBuildOperation(t *testing.T) {
+	doc := Build(Info{Title: "test", Version: "v1"}, "/api/v1",
+		Operation{Method: fiber.MethodGet, Path: "/roles/:roleID/members", Query: listRequest{}, Response: []node{},
+			Auth: true, Permission: "role:read", Errors: []int{http.StatusNotFound}},
+		Operation{Method: fiber.MethodPost, Path: "auth/login", Body: node{}},
+	)
+
+	op := (*doc.Paths["/roles/{roleID}/members"])["get"]
+	if op == nil {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+	var names []string
+	for _, param := range op.Parameters {
+		names = append(names, param.In+":"+param.Name)
+	}
+	if got := strings.Join(names, ","); got != "path:roleID,query:page,query:size,query:query" {
+		t.Errorf("parameters = %s", got)
+	}
+	for _, code := range []string{"200", "400", "401", "403", "404", "500"} {
+		if op.Responses[code] == nil {
+			t.Errorf("missing %s response", code)
+		}
+	}
+	if op.Permission != "role:read" || len(op.Security) != 1 {
+		t.Errorf("permission/security = %q/%v", op.Permission, op.Security)
+	}
+
+	login := (*doc.Paths["/auth/login"])["post"]
+	if login.Responses["401"] != nil || login.Responses["403"] != nil {
+		t.Error("public operation documents auth errors")
+	}
+	data := login.Responses["200"].Content["application/json"].Schema.AllOf[1].Properties["data"]
+	if data.Type != "null" {
+		t.Errorf("nil Response data = %+v, want null", data)
+	}
+	if doc.Components.Schemas["Response"] == nil || doc.Components.Schemas["Error"] == nil {
+		t.Error("envelope schemas missing")
+	}
+}
+
+func TestBuildPanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate operation accepted")
+		}
+	}()
+	op := Operation{Method: fiber.MethodGet, Path: "/roles"}
+	Build(Info{}, "/api/v1", op, op)
+}
+
+func TestHandlers(t *testing.T) {
+	specHandler, err := SpecHandler(Build(Info{Title: "test", Version: "v1"}, "/api/v1",
+		Operation{Method: fiber.MethodGet, Path: "/roles"}))
+	if err != nil {
+		t.Fatalf("SpecHandler: %v", err)
+	}
+	app := fiber.New()
+	app.Get("/openapi.json", specHandler)
+	app.Get("/docs", DocsHandler("/api/v1/openapi.json"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("spec request: %v", err)
+	}
+	var doc map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if doc["openapi"] != Version {
+		t.Errorf("openapi = %v", doc["openapi"])
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if err != nil {
+		t.Fatalf("docs request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"/api/v1/openapi.json"`) || strings.Contains(string(body), "{{SPEC_URL}}") {
+		t.Error("docs page not pointed at the spec")
+	}
+	if strings.Contains(string(body), "<script src=") {
+		t.Error("docs page loads an external script")
+	}
+}
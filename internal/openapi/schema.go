@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const refPrefix = "#/components/schemas/"
+
+// Schema is the subset of JSON Schema 2020-12 the generator emits. Type is a
+// string, or a []string for nullable pointers ("string", "null").
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	ContentMediaType     string             `json:"contentMediaType,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// registry collects the named struct schemas referenced from operations into
+// components/schemas.
+type registry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newRegistry() *registry {
+	return &registry{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+func (r *registry) schemaOf(v any) *Schema {
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *registry) schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := r.schemaFor(t.Elem())
+		if inner.Ref != "" {
+			return &Schema{AnyOf: []*Schema{inner, {Type: "null"}}}
+		}
+		if typ, ok := inner.Type.(string); ok {
+			inner.Type = []string{typ, "null"}
+		}
+		return inner
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json sends []byte as base64
+			return &Schema{Type: "string", ContentMediaType: "application/octet-stream"}
+		}
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return r.objectSchema(t)
+		}
+		return r.ref(t)
+	}
+	return &Schema{}
+}
+
+// ref registers a named struct once and returns a $ref to it. The name is
+// registered before the properties are walked so a recursive type refers to
+// itself instead of looping.
+func (r *registry) ref(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = r.uniqueName(t)
+		r.names[t] = name
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.objectSchema(t)
+	}
+	return &Schema{Ref: refPrefix + name}
+}
+
+// uniqueName prefixes the type name with its owning domain, since most domains
+// have a ListRequest or a CreateRequest of their own:
+// .../domains/user_invitation/models.CreateRequest -> UserInvitationCreateRequest.
+func (r *registry) uniqueName(t reflect.Type) string {
+	base := t.Name()
+	if i := strings.IndexByte(base, '['); i >= 0 {
+		base = base[:i]
+	}
+	segments := strings.Split(t.PkgPath(), "/")
+	owner := ""
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] != "models" {
+			owner = segments[i]
+			break
+		}
+	}
+	name := camel(owner) + camel(base)
+	if r.schemas[name] == nil {
+		return name
+	}
+	for n := 2; ; n++ {
+		if candidate := name + strconv.Itoa(n); r.schemas[candidate] == nil {
+			return candidate
+		}
+	}
+}
+
+func (r *registry) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(schema, t)
+	return schema
+}
+
+// addFields follows encoding/json: an untagged embedded struct is flattened
+// into its parent, `json:"-"` is skipped, and an untagged field keeps its Go
+// name. Fields without omitempty are always sent, so they are required.
+func (r *registry) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = r.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// queryParameters lists the fields c.QueryParser binds: the `query` tag, or the
+// Go field name when untagged, flattening embedded structs such as
+// pkgBase.Pagination.
+func (r *registry) queryParameters(v any) []Parameter {
+	var params []Parameter
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && name == "" {
+				walk(field.Type)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			params = append(params, Parameter{Name: name, In: "query", Schema: r.schemaFor(field.Type)})
+		}
+	}
+	walk(reflect.TypeOf(v))
+	return params
+}
+
+func camel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
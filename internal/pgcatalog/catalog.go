@@ -0,0 +1,149 @@
+// Package pgcatalog reads the shape of the isme schema from a Postgres
+// catalog: its tables in foreign-key order, their columns, and the sequences
+// behind serial and identity keys. Backups and the SQLite to Postgres copy use
+// it to move rows without hard-coding the table list.
+package pgcatalog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// Column is one insertable column of a table.
+type Column struct {
+	Name     string `bun:"column_name"`
+	DataType string `bun:"data_type"`
+	// Identity marks a GENERATED ... AS IDENTITY column, which takes explicit
+	// values only with OVERRIDING SYSTEM VALUE.
+	Identity bool `bun:"identity"`
+}
+
+// Tables lists the base tables of the current schema, parents before the
+// tables whose foreign keys reference them and otherwise by name.
+func Tables(ctx context.Context, db bun.IDB) ([]string, error) {
+	tables := []string{}
+	if err := db.NewRaw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`).Scan(ctx, &tables); err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	var edges []struct {
+		Child  string `bun:"child"`
+		Parent string `bun:"parent"`
+	}
+	if err := db.NewRaw(`SELECT child.relname AS child, parent.relname AS parent
+		FROM pg_constraint c
+		JOIN pg_class child ON child.oid = c.conrelid
+		JOIN pg_class parent ON parent.oid = c.confrelid
+		WHERE c.contype = 'f' AND child.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())`).
+		Scan(ctx, &edges); err != nil {
+		return nil, fmt.Errorf("list foreign keys: %w", err)
+	}
+	parents := map[string][]string{}
+	for _, edge := range edges {
+		if edge.Child != edge.Parent {
+			parents[edge.Child] = append(parents[edge.Child], edge.Parent)
+		}
+	}
+	return ParentsFirst(tables, parents), nil
+}
+
+// ParentsFirst orders tables so each follows the tables it references,
+// keeping the given order among independent tables. Tables caught in a
+// reference cycle keep their given order at the end.
+func ParentsFirst(tables []string, parents map[string][]string) []string {
+	ordered := make([]string, 0, len(tables))
+	placed := map[string]bool{}
+	for len(ordered) < len(tables) {
+		progress := false
+		for _, table := range tables {
+			if placed[table] {
+				continue
+			}
+			ready := true
+			for _, parent := range parents[table] {
+				if !placed[parent] && slices.Contains(tables, parent) {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, table)
+				placed[table] = true
+				progress = true
+			}
+		}
+		if !progress {
+			for _, table := range tables {
+				if !placed[table] {
+					ordered = append(ordered, table)
+					placed[table] = true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// Columns lists a table's insertable columns in declaration order; generated
+// columns are left out.
+func Columns(ctx context.Context, db bun.IDB, table string) ([]Column, error) {
+	columns := []Column{}
+	if err := db.NewRaw(`SELECT column_name, data_type, is_identity = 'YES' AS identity
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ? AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`, table).Scan(ctx, &columns); err != nil {
+		return nil, fmt.Errorf("list columns of %s: %w", table, err)
+	}
+	return columns, nil
+}
+
+// HasIdentity reports whether any of columns is an identity column.
+func HasIdentity(columns []Column) bool {
+	return slices.ContainsFunc(columns, func(c Column) bool { return c.Identity })
+}
+
+// Names returns the column names.
+func Names(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// ResetSequences moves every serial and identity sequence of the schema past
+// the largest value in its column, so new rows do not collide with copied or
+// restored keys.
+func ResetSequences(ctx context.Context, db bun.IDB) error {
+	var columns []struct {
+		Table  string `bun:"table_name"`
+		Column string `bun:"column_name"`
+	}
+	if err := db.NewRaw(`SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND (is_identity = 'YES' OR column_default LIKE 'nextval(%')`).
+		Scan(ctx, &columns); err != nil {
+		return fmt.Errorf("list sequences: %w", err)
+	}
+	for _, c := range columns {
+		if _, err := db.ExecContext(ctx, "SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(?), 0) + 1, false) FROM ?",
+			string(Idents(c.Table)), c.Column, bun.Ident(c.Column), bun.Ident(c.Table)); err != nil {
+			return fmt.Errorf("reset sequence of %s.%s: %w", c.Table, c.Column, err)
+		}
+	}
+	return nil
+}
+
+// Idents is a comma-separated list of quoted identifiers for a query.
+func Idents(names ...string) bun.Safe {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+	return bun.Safe(strings.Join(quoted, ", "))
+}
@@ -0,0 +1,29 @@
+package pgcatalog
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParentsFirst(t *testing.T) {
+	tables := []string{"activity_events", "roles", "user_roles", "users"}
+	parents := map[string][]string{
+		"user_roles":      {"users", "roles"},
+		"activity_events": {"users"},
+	}
+	got := ParentsFirst(tables, parents)
+	if want := []string{"roles", "users", "activity_events", "user_roles"}; !slices.Equal(got, want) {
+		t.Fatalf("ParentsFirst = %v, want %v", got, want)
+	}
+
+	cycle := ParentsFirst([]string{"a", "b", "c"}, map[string][]string{"a": {"b"}, "b": {"a"}})
+	if want := []string{"c", "a", "b"}; !slices.Equal(cycle, want) {
+		t.Fatalf("ParentsFirst with a cycle = %v, want %v", cycle, want)
+	}
+}
+
+func TestIdents(t *testing.T) {
+	if got := string(Idents("users", `we"ird`)); got != `"users", "we""ird"` {
+		t.Fatalf("Idents = %s", got)
+	}
+}
@@ -0,0 +1,243 @@
+// Package rbacmanifest describes app services, their permission catalogs and
+// their roles as a reviewed YAML (or JSON) file, and converges the database on
+// it. A manifest lists apps by code:
+//
+//	apps:
+//	  - code: medioa
+//	    name: Medioa
+//	    redirect_url: https://medioa.example.com/callback
+//	    resources:
+//	      - name: file
+//	        icon: file
+//	        color: violet
+//	        actions: [create, read, update, delete]
+//	    roles:
+//	      - code: editor
+//	        name: Editor
+//	        permissions: ["file:*"]
+//
+// A role grant is "resource:action", "resource:*" for every action of a
+// declared resource, or "*" for every declared permission. The manifest is
+// authoritative for what it declares: a declared role gets exactly its listed
+// grants. Roles and permissions the manifest does not declare are left alone
+// unless pruning is asked for. The isme platform app is seeded by migrations
+// and cannot be managed here.
+package rbacmanifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	appServiceConstants "github.com/vukyn/isme/internal/domains/app_service/constants"
+	appServiceModels "github.com/vukyn/isme/internal/domains/app_service/models"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// wildcard grants every declared action, of one resource or of the app.
+const wildcard = "*"
+
+type Manifest struct {
+	Apps []App `yaml:"apps"`
+}
+
+type App struct {
+	Code         string   `yaml:"code"`
+	Name         string   `yaml:"name"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	RedirectURLs []string `yaml:"redirect_urls"`
+	// CtxInfo is only used when the app is registered; the app secret is
+	// encrypted with it, so it cannot change afterwards. Empty = "authen".
+	CtxInfo   string     `yaml:"ctx_info"`
+	Icon      string     `yaml:"icon"`
+	Color     string     `yaml:"color"`
+	Resources []Resource `yaml:"resources"`
+	Roles     []Role     `yaml:"roles"`
+}
+
+// Resource is one entry of the permission catalog: a resource:action
+// permission per action, all sharing the resource's icon and color.
+type Resource struct {
+	Name    string   `yaml:"name"`
+	Icon    string   `yaml:"icon"`
+	Color   string   `yaml:"color"`
+	Actions []string `yaml:"actions"`
+}
+
+type Role struct {
+	Code        string   `yaml:"code"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Icon        string   `yaml:"icon"`
+	Color       string   `yaml:"color"`
+	Permissions []string `yaml:"permissions"`
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse decodes a YAML or JSON manifest and validates it. Unknown keys are
+// rejected so a misspelt field cannot silently drop a setting.
+func Parse(r io.Reader) (*Manifest, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	var m Manifest
+	if err := decoder.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	for i := range m.Apps {
+		if m.Apps[i].CtxInfo == "" {
+			m.Apps[i].CtxInfo = appServiceConstants.CtxInfoAuthen
+		}
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate applies the checks the console applies to each entity, plus the
+// manifest's own: no duplicates, and grants that name declared permissions.
+func (m *Manifest) Validate() error {
+	seen := map[string]bool{}
+	for _, app := range m.Apps {
+		if seen[app.Code] {
+			return fmt.Errorf("app %s is declared twice", app.Code)
+		}
+		seen[app.Code] = true
+		if err := app.validate(); err != nil {
+			return fmt.Errorf("app %s: %w", app.Code, err)
+		}
+	}
+	return nil
+}
+
+func (a App) validate() error {
+	if appServiceConstants.IsPlatformApp(a.Code) {
+		return errors.New("the isme platform app is managed by migrations")
+	}
+	err := appServiceModels.RegisterRequest{
+		AppCode:      a.Code,
+		AppName:      a.Name,
+		RedirectURL:  a.RedirectURL,
+		RedirectURLs: a.RedirectURLs,
+		CtxInfo:      a.CtxInfo,
+		Icon:         a.Icon,
+		Color:        a.Color,
+	}.Validate()
+	if err != nil {
+		return err
+	}
+	if _, ok := appServiceConstants.AllowedCtxInfos[a.CtxInfo]; !ok {
+		return errors.New("invalid ctx_info")
+	}
+
+	resources := map[string]bool{}
+	for _, resource := range a.Resources {
+		if resources[resource.Name] {
+			return fmt.Errorf("resource %s is declared twice", resource.Name)
+		}
+		resources[resource.Name] = true
+		if len(resource.Actions) == 0 {
+			return fmt.Errorf("resource %s has no actions", resource.Name)
+		}
+		actions := map[string]bool{}
+		pairs := make([]roleModels.PermissionPair, 0, len(resource.Actions))
+		for _, action := range resource.Actions {
+			if actions[action] {
+				return fmt.Errorf("resource %s: action %s is declared twice", resource.Name, action)
+			}
+			actions[action] = true
+			pairs = append(pairs, roleModels.PermissionPair{Resource: resource.Name, Action: action, Icon: resource.Icon, Color: resource.Color})
+		}
+		// the app id is not known before registration; the code stands in
+		if err := (roleModels.CreatePermissionsRequest{AppID: a.Code, Permissions: pairs}).Validate(); err != nil {
+			return fmt.Errorf("resource %s: %w", resource.Name, err)
+		}
+	}
+
+	roles := map[string]bool{}
+	for _, role := range a.Roles {
+		if roles[role.Code] {
+			return fmt.Errorf("role %s is declared twice", role.Code)
+		}
+		roles[role.Code] = true
+		err := roleModels.CreateRequest{
+			AppID:       a.Code,
+			Code:        role.Code,
+			Name:        role.Name,
+			Description: role.Description,
+			Icon:        role.Icon,
+			Color:       role.Color,
+		}.Validate()
+		if err != nil {
+			return fmt.Errorf("role %s: %w", role.Code, err)
+		}
+		if _, err := a.grants(role); err != nil {
+			return fmt.Errorf("role %s: %w", role.Code, err)
+		}
+	}
+	return nil
+}
+
+// codes lists the app's declared permissions as resource:action codes.
+func (a App) codes() []string {
+	var codes []string
+	for _, resource := range a.Resources {
+		for _, action := range resource.Actions {
+			codes = append(codes, resource.Name+":"+action)
+		}
+	}
+	return codes
+}
+
+// grants expands the role's permission list against the app's catalog into
+// sorted resource:action codes.
+func (a App) grants(role Role) ([]string, error) {
+	declared := a.codes()
+	granted := map[string]bool{}
+	for _, grant := range role.Permissions {
+		if grant == wildcard {
+			for _, code := range declared {
+				granted[code] = true
+			}
+			continue
+		}
+		resource, action, ok := strings.Cut(grant, ":")
+		if !ok {
+			return nil, fmt.Errorf("permission %q is not resource:action", grant)
+		}
+		matched := false
+		for _, code := range declared {
+			if code == grant || (action == wildcard && strings.HasPrefix(code, resource+":")) {
+				granted[code] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("permission %s is not declared in the app's resources", grant)
+		}
+	}
+	codes := make([]string, 0, len(granted))
+	for code := range granted {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes, nil
+}
@@ -0,0 +1,181 @@
+package rbacmanifest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	appServiceEntity "github.com/vukyn/isme/internal/domains/app_service/entity"
+	roleEntity "github.com/vukyn/isme/internal/domains/role/entity"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+)
+
+func TestLoadExample(t *testing.T) {
+	m, err := Load("testdata/medioa.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	app := m.Apps[0]
+	if app.CtxInfo != "authen" {
+		t.Fatalf("ctx_info = %q, want the authen default", app.CtxInfo)
+	}
+	grants, err := app.grants(app.Roles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grants) != 13 || !slices.Contains(grants, "bucket:quota") || !slices.Contains(grants, "storage:read") || slices.Contains(grants, "storage:delete") {
+		t.Fatalf("editor grants = %v", grants)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	m, err := Parse(strings.NewReader(`{"apps": [{"code": "billing", "name": "Billing", "redirect_url": "https://billing.example.com/cb",
+		"resources": [{"name": "invoice", "actions": ["read", "pay"]}],
+		"roles": [{"code": "owner", "name": "Owner", "permissions": ["*"]}]}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	grants, _ := m.Apps[0].grants(m.Apps[0].Roles[0])
+	if !slices.Equal(grants, []string{"invoice:pay", "invoice:read"}) {
+		t.Fatalf("grants = %v", grants)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	const app = `
+apps:
+  - code: billing
+    name: Billing
+    redirect_url: https://billing.example.com/cb
+`
+	tests := []struct {
+		name, manifest, want string
+	}{
+		{"unknown key", app + "    colour: rose\n", "colour"},
+		{"platform app", "apps: [{code: isme, name: isme, redirect_url: https://isme.example.com}]", "platform"},
+		{"duplicate app", app + strings.Replace(app, "apps:\n", "", 1), "declared twice"},
+		{"bad ctx_info", app + "    ctx_info: nope\n", "ctx_info"},
+		{"bad color", app + "    resources: [{name: invoice, color: pink, actions: [read]}]\n", "color"},
+		{"no actions", app + "    resources: [{name: invoice}]\n", "no actions"},
+		{"duplicate action", app + "    resources: [{name: invoice, actions: [read, read]}]\n", "declared twice"},
+		{"bad role code", app + "    roles: [{code: Owner, name: Owner}]\n", "slug"},
+		{"undeclared grant", app + "    resources: [{name: invoice, actions: [read]}]\n    roles: [{code: owner, name: Owner, permissions: ['invoice:pay']}]\n", "not declared"},
+		{"undeclared resource", app + "    roles: [{code: owner, name: Owner, permissions: ['refund:*']}]\n", "not declared"},
+		{"malformed grant", app + "    roles: [{code: owner, name: Owner, permissions: [invoice]}]\n", "resource:action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.manifest))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffApp(t *testing.T) {
+	app := App{Code: "billing", Name: "Billing", RedirectURL: "https://billing.example.com/cb", CtxInfo: "authen", Color: "rose"}
+	current := appServiceEntity.AppService{ID: "app_billing", AppCode: "billing", AppName: "Billing", RedirectURL: app.RedirectURL, RedirectURLs: "[]", CtxInfo: "authen", Color: "rose"}
+
+	if _, changes, err := diffApp(app, current); err != nil || len(changes) != 0 {
+		t.Fatalf("unchanged app: changes = %v, err = %v", changes, err)
+	}
+
+	app.Name = "Billing v2"
+	app.RedirectURLs = []string{"https://billing.example.com/alt"}
+	req, changes, err := diffApp(app, current)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("changes = %v, err = %v", changes, err)
+	}
+	if req.AppName == nil || *req.AppName != "Billing v2" || req.RedirectURLs == nil || req.Color != nil || req.RedirectURL != nil {
+		t.Fatalf("update = %+v, want only the name and redirect_urls", req)
+	}
+
+	current.CtxInfo = "app_service"
+	if _, _, err := diffApp(app, current); err == nil {
+		t.Fatal("a ctx_info change must be refused")
+	}
+}
+
+func TestDiffCatalog(t *testing.T) {
+	app := App{Code: "billing", Resources: []Resource{
+		{Name: "invoice", Icon: "file", Actions: []string{"read", "pay"}},
+		{Name: "refund", Actions: []string{"create"}},
+	}}
+	permissions := []roleEntity.Permission{
+		{ID: 1, Resource: "invoice", Action: "read", Icon: "box"},
+		{ID: 2, Resource: "invoice", Action: "void", Icon: "box"},
+		{ID: 3, Resource: "access_request", Action: "approve"},
+	}
+
+	diff := diffCatalog(app, permissions, false)
+	if got := changeLines(diff.changes); !slices.Equal(got, []string{
+		"create permission invoice:pay",
+		"update resource invoice",
+		"create resource refund",
+	}) {
+		t.Fatalf("changes = %v", got)
+	}
+	if len(diff.create) != 2 || len(diff.appearance) != 1 || diff.appearance[0].Icon != "file" || len(diff.remove) != 0 {
+		t.Fatalf("diff = %+v", diff)
+	}
+
+	diff = diffCatalog(app, permissions, true)
+	if len(diff.remove) != 1 || diff.remove[0].ID != 2 {
+		t.Fatalf("prune removes = %+v, want invoice:void (the reserved approval permission stays)", diff.remove)
+	}
+}
+
+func TestDiffRoles(t *testing.T) {
+	app := App{
+		Code:      "billing",
+		Resources: []Resource{{Name: "invoice", Actions: []string{"read", "pay"}}},
+		Roles: []Role{
+			{Code: "owner", Name: "Owner", Permissions: []string{"*"}},
+			{Code: "clerk", Name: "Clerk", Permissions: []string{"invoice:read"}},
+			{Code: "auditor", Name: "Auditor"},
+		},
+	}
+	roles := []roleModels.RoleListItem{
+		{ID: "r_admin", Code: "admin", Name: "Admin"},
+		{ID: "r_owner", Code: "owner", Name: "Owner"},
+		{ID: "r_clerk", Code: "clerk", Name: "Cashier"},
+		{ID: "r_old", Code: "old", Name: "Old", MembersCount: 2},
+	}
+	grants := map[string][]string{
+		"r_owner": {"invoice:read", "invoice:pay"},
+		"r_clerk": {"invoice:pay"},
+	}
+
+	steps, changes, err := diffRoles(app, roles, grants, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := changeLines(changes); !slices.Equal(got, []string{
+		"update role clerk",
+		"update grants clerk",
+		"create role auditor",
+		"delete role old",
+	}) {
+		t.Fatalf("changes = %v", got)
+	}
+	if changes[1].Detail != "+invoice:read -invoice:pay" || !strings.Contains(changes[3].Detail, "2 member(s)") {
+		t.Fatalf("details = %q, %q", changes[1].Detail, changes[3].Detail)
+	}
+	if len(steps) != 3 || steps[1].id != "" || steps[1].grants != nil || !steps[2].delete {
+		t.Fatalf("steps = %+v", steps)
+	}
+
+	roles[1].IsSystem = true
+	if _, _, err := diffRoles(app, roles, grants, false); err == nil {
+		t.Fatal("declaring a system role must be refused")
+	}
+}
+
+func changeLines(changes []Change) []string {
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		lines = append(lines, change.Action+" "+change.Kind+" "+change.Target)
+	}
+	return lines
+}
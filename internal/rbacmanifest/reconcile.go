@@ -0,0 +1,506 @@
+package rbacmanifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	appServiceEntity "github.com/vukyn/isme/internal/domains/app_service/entity"
+	appServiceModels "github.com/vukyn/isme/internal/domains/app_service/models"
+	appServiceRepo "github.com/vukyn/isme/internal/domains/app_service/repository"
+	appServiceUsecase "github.com/vukyn/isme/internal/domains/app_service/usecase"
+	roleConstants "github.com/vukyn/isme/internal/domains/role/constants"
+	roleEntity "github.com/vukyn/isme/internal/domains/role/entity"
+	roleModels "github.com/vukyn/isme/internal/domains/role/models"
+	roleRepo "github.com/vukyn/isme/internal/domains/role/repository"
+	roleUsecase "github.com/vukyn/isme/internal/domains/role/usecase"
+	"github.com/vukyn/isme/internal/transaction"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	KindApp        = "app"
+	KindResource   = "resource"
+	KindPermission = "permission"
+	KindRole       = "role"
+	KindGrants     = "grants"
+)
+
+// Change is one difference between the manifest and the database, in the
+// order apply makes it.
+type Change struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	App    string `json:"app"`
+	Target string `json:"target"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Secret is the secret of an app registered by Apply; isme cannot show it
+// again.
+type Secret struct {
+	AppCode   string `json:"app_code"`
+	AppSecret string `json:"app_secret"`
+}
+
+type Result struct {
+	Changes []Change `json:"changes"`
+	Secrets []Secret `json:"secrets"`
+}
+
+// Reconciler diffs a manifest against the database through the role and
+// app_service repositories and writes through their usecases, so every change
+// apply makes is validated and audited like a console edit.
+type Reconciler struct {
+	roleRepo          roleRepo.IRepository
+	appServiceRepo    appServiceRepo.IRepository
+	roleUsecase       roleUsecase.IUseCase
+	appServiceUsecase appServiceUsecase.IUseCase
+	txRunner          transaction.Runner
+}
+
+func NewReconciler(
+	roleRepo roleRepo.IRepository,
+	appServiceRepo appServiceRepo.IRepository,
+	roleUsecase roleUsecase.IUseCase,
+	appServiceUsecase appServiceUsecase.IUseCase,
+	txRunner transaction.Runner,
+) *Reconciler {
+	return &Reconciler{
+		roleRepo:          roleRepo,
+		appServiceRepo:    appServiceRepo,
+		roleUsecase:       roleUsecase,
+		appServiceUsecase: appServiceUsecase,
+		txRunner:          txRunner,
+	}
+}
+
+// Plan lists the changes Apply would make, without making them. With prune,
+// roles and permissions of the manifest's apps that it does not declare are
+// deleted; apps it does not list are never touched.
+func (r *Reconciler) Plan(ctx context.Context, m *Manifest, prune bool) ([]Change, error) {
+	p := &pass{Reconciler: r, prune: prune}
+	for _, app := range m.Apps {
+		if err := p.reconcile(ctx, app); err != nil {
+			return nil, err
+		}
+	}
+	return p.result.Changes, nil
+}
+
+// Apply converges the database on the manifest in one transaction: a failure
+// leaves it as it was. Applying the same manifest again changes nothing.
+func (r *Reconciler) Apply(ctx context.Context, m *Manifest, prune bool) (Result, error) {
+	p := &pass{Reconciler: r, prune: prune, apply: true}
+	err := r.txRunner.Run(ctx, func(ctx context.Context) error {
+		for _, app := range m.Apps {
+			if err := p.reconcile(ctx, app); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return p.result, nil
+}
+
+// pass is one run of Plan or Apply. Apply re-reads the state before each step
+// so it builds on its own writes; Plan reads the same state throughout.
+type pass struct {
+	*Reconciler
+	prune  bool
+	apply  bool
+	result Result
+}
+
+func (p *pass) add(changes ...Change) {
+	p.result.Changes = append(p.result.Changes, changes...)
+}
+
+func (p *pass) reconcile(ctx context.Context, app App) error {
+	current, err := p.appServiceRepo.GetByCode(ctx, app.Code)
+	if err != nil {
+		return err
+	}
+	if current.ID == "" {
+		p.add(Change{Action: ActionCreate, Kind: KindApp, App: app.Code, Target: app.Code, Detail: app.Name})
+		if p.apply {
+			if current, err = p.register(ctx, app); err != nil {
+				return err
+			}
+		}
+	} else {
+		update, changes, err := diffApp(app, current)
+		if err != nil {
+			return fmt.Errorf("app %s: %w", app.Code, err)
+		}
+		p.add(changes...)
+		if p.apply && len(changes) > 0 {
+			if err := p.appServiceUsecase.UpdateAppearance(ctx, current.ID, update); err != nil {
+				return fmt.Errorf("app %s: %w", app.Code, err)
+			}
+		}
+	}
+	// the app has no id only while planning its registration; it has no
+	// catalog or roles yet
+	permissions, err := p.permissions(ctx, current.ID)
+	if err != nil {
+		return err
+	}
+	catalog := diffCatalog(app, permissions, p.prune)
+	p.add(catalog.changes...)
+	if p.apply {
+		if err := p.applyCatalog(ctx, current.ID, catalog); err != nil {
+			return fmt.Errorf("app %s: %w", app.Code, err)
+		}
+	}
+
+	roles, grants, err := p.roles(ctx, current.ID)
+	if err != nil {
+		return err
+	}
+	steps, changes, err := diffRoles(app, roles, grants, p.prune)
+	if err != nil {
+		return fmt.Errorf("app %s: %w", app.Code, err)
+	}
+	p.add(changes...)
+	if p.apply {
+		if err := p.applyRoles(ctx, current.ID, steps); err != nil {
+			return fmt.Errorf("app %s: %w", app.Code, err)
+		}
+		// pruned permissions go last, after no declared role needs them
+		for _, permission := range catalog.remove {
+			if err := p.roleUsecase.DeletePermission(ctx, permission.ID); err != nil {
+				return fmt.Errorf("app %s: delete permission %s:%s: %w", app.Code, permission.Resource, permission.Action, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *pass) register(ctx context.Context, app App) (appServiceEntity.AppService, error) {
+	res, err := p.appServiceUsecase.RegisterApp(ctx, appServiceModels.RegisterRequest{
+		AppCode:      app.Code,
+		AppName:      app.Name,
+		RedirectURL:  app.RedirectURL,
+		RedirectURLs: app.RedirectURLs,
+		CtxInfo:      app.CtxInfo,
+		Icon:         app.Icon,
+		Color:        app.Color,
+	})
+	if err != nil {
+		return appServiceEntity.AppService{}, fmt.Errorf("register app %s: %w", app.Code, err)
+	}
+	p.result.Secrets = append(p.result.Secrets, Secret{AppCode: app.Code, AppSecret: res.AppSecret})
+	return p.appServiceRepo.GetByCode(ctx, app.Code)
+}
+
+func (p *pass) permissions(ctx context.Context, appID string) ([]roleEntity.Permission, error) {
+	if appID == "" {
+		return nil, nil
+	}
+	return p.roleRepo.ListPermissions(ctx, roleModels.ListPermissionsRequest{AppID: appID})
+}
+
+func (p *pass) roles(ctx context.Context, appID string) ([]roleModels.RoleListItem, map[string][]string, error) {
+	if appID == "" {
+		return nil, nil, nil
+	}
+	roles, err := p.roleRepo.List(ctx, roleModels.ListRequest{AppID: appID})
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+	grants, err := p.roleRepo.GetDirectPermissionCodesByRoleIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return roles, grants, nil
+}
+
+func (p *pass) applyCatalog(ctx context.Context, appID string, catalog catalogDiff) error {
+	if len(catalog.create) > 0 {
+		_, err := p.roleUsecase.CreatePermissions(ctx, roleModels.CreatePermissionsRequest{AppID: appID, Permissions: catalog.create})
+		if err != nil {
+			return err
+		}
+	}
+	for _, req := range catalog.appearance {
+		req.AppID = appID
+		if err := p.roleUsecase.UpdatePermissionAppearance(ctx, req); err != nil {
+			return fmt.Errorf("resource %s: %w", req.Resource, err)
+		}
+	}
+	return nil
+}
+
+func (p *pass) applyRoles(ctx context.Context, appID string, steps []roleStep) error {
+	permissions, err := p.permissions(ctx, appID)
+	if err != nil {
+		return err
+	}
+	idsByCode := make(map[string]int64, len(permissions))
+	for _, permission := range permissions {
+		idsByCode[permission.Resource+":"+permission.Action] = permission.ID
+	}
+
+	for _, step := range steps {
+		id := step.id
+		switch {
+		case step.delete:
+			if err := p.roleUsecase.Delete(ctx, id); err != nil {
+				return fmt.Errorf("delete role %s: %w", step.role.Code, err)
+			}
+			continue
+		case id == "":
+			res, err := p.roleUsecase.Create(ctx, roleModels.CreateRequest{
+				AppID:       appID,
+				Code:        step.role.Code,
+				Name:        step.role.Name,
+				Description: step.role.Description,
+				Icon:        step.role.Icon,
+				Color:       step.role.Color,
+			})
+			if err != nil {
+				return fmt.Errorf("create role %s: %w", step.role.Code, err)
+			}
+			id = res.ID
+		case step.update:
+			err := p.roleUsecase.Update(ctx, id, roleModels.UpdateRequest{
+				Name:        step.role.Name,
+				Description: step.role.Description,
+				Icon:        step.role.Icon,
+				Color:       step.role.Color,
+			})
+			if err != nil {
+				return fmt.Errorf("update role %s: %w", step.role.Code, err)
+			}
+		}
+		if step.grants == nil {
+			continue
+		}
+		permissionIDs := make([]int64, 0, len(step.grants))
+		for _, code := range step.grants {
+			permissionIDs = append(permissionIDs, idsByCode[code])
+		}
+		if err := p.roleUsecase.SetPermissions(ctx, id, roleModels.SetPermissionsRequest{PermissionIDs: permissionIDs}); err != nil {
+			return fmt.Errorf("set permissions of role %s: %w", step.role.Code, err)
+		}
+	}
+	return nil
+}
+
+// diffApp compares the app's settings, returning the appearance edit that
+// converges them: only the fields that differ are set.
+func diffApp(app App, current appServiceEntity.AppService) (appServiceModels.UpdateAppearanceRequest, []Change, error) {
+	var req appServiceModels.UpdateAppearanceRequest
+	if app.CtxInfo != current.CtxInfo {
+		return req, nil, fmt.Errorf("ctx_info is %s and cannot change: the app secret is encrypted with it", current.CtxInfo)
+	}
+
+	var details []string
+	field := func(name, want, got string) *string {
+		if want == got {
+			return nil
+		}
+		details = append(details, fmt.Sprintf("%s %q → %q", name, got, want))
+		return &want
+	}
+	req.AppName = field("name", app.Name, current.AppName)
+	req.RedirectURL = field("redirect_url", app.RedirectURL, current.RedirectURL)
+	req.Icon = field("icon", app.Icon, current.Icon)
+	req.Color = field("color", app.Color, current.Color)
+
+	want, err := appServiceModels.ValidateRedirectURLList(app.RedirectURLs)
+	if err != nil {
+		return req, nil, err
+	}
+	var got []string
+	if current.RedirectURLs != "" {
+		if err := json.Unmarshal([]byte(current.RedirectURLs), &got); err != nil {
+			return req, nil, fmt.Errorf("stored redirect_urls: %w", err)
+		}
+	}
+	if !slices.Equal(want, got) {
+		details = append(details, fmt.Sprintf("redirect_urls %v → %v", got, want))
+		req.RedirectURLs = &want
+	}
+
+	if len(details) == 0 {
+		return req, nil, nil
+	}
+	return req, []Change{{Action: ActionUpdate, Kind: KindApp, App: app.Code, Target: app.Code, Detail: strings.Join(details, ", ")}}, nil
+}
+
+type catalogDiff struct {
+	create     []roleModels.PermissionPair
+	appearance []roleModels.UpdatePermissionAppearanceRequest
+	remove     []roleEntity.Permission
+	changes    []Change
+}
+
+// diffCatalog compares the declared resources with the app's permissions. A
+// resource's appearance is read from its lowest-id row, as the console does.
+func diffCatalog(app App, permissions []roleEntity.Permission, prune bool) catalogDiff {
+	var diff catalogDiff
+	existing := map[string]bool{}
+	appearance := map[string]roleEntity.Permission{}
+	for _, permission := range permissions {
+		existing[permission.Resource+":"+permission.Action] = true
+		if _, seen := appearance[permission.Resource]; !seen {
+			appearance[permission.Resource] = permission
+		}
+	}
+
+	declared := map[string]bool{}
+	for _, resource := range app.Resources {
+		current, exists := appearance[resource.Name]
+		var missing []string
+		for _, action := range resource.Actions {
+			code := resource.Name + ":" + action
+			declared[code] = true
+			if existing[code] {
+				continue
+			}
+			missing = append(missing, action)
+			diff.create = append(diff.create, roleModels.PermissionPair{Resource: resource.Name, Action: action, Icon: resource.Icon, Color: resource.Color})
+		}
+
+		if !exists {
+			diff.changes = append(diff.changes, Change{Action: ActionCreate, Kind: KindResource, App: app.Code, Target: resource.Name, Detail: strings.Join(missing, ", ")})
+			continue
+		}
+		for _, action := range missing {
+			diff.changes = append(diff.changes, Change{Action: ActionCreate, Kind: KindPermission, App: app.Code, Target: resource.Name + ":" + action})
+		}
+		if current.Icon != resource.Icon || current.Color != resource.Color {
+			diff.appearance = append(diff.appearance, roleModels.UpdatePermissionAppearanceRequest{Resource: resource.Name, Icon: resource.Icon, Color: resource.Color})
+			diff.changes = append(diff.changes, Change{
+				Action: ActionUpdate,
+				Kind:   KindResource,
+				App:    app.Code,
+				Target: resource.Name,
+				Detail: fmt.Sprintf("icon %q → %q, color %q → %q", current.Icon, resource.Icon, current.Color, resource.Color),
+			})
+		}
+	}
+
+	if !prune {
+		return diff
+	}
+	for _, permission := range permissions {
+		code := permission.Resource + ":" + permission.Action
+		if declared[code] || roleConstants.IsReservedPermission(code) {
+			continue
+		}
+		diff.remove = append(diff.remove, permission)
+		diff.changes = append(diff.changes, Change{Action: ActionDelete, Kind: KindPermission, App: app.Code, Target: code})
+	}
+	return diff
+}
+
+// roleStep is what apply does to one role: create it (no id), update it,
+// set its grants (nil = unchanged) or delete it.
+type roleStep struct {
+	id     string
+	role   Role
+	update bool
+	grants []string
+	delete bool
+}
+
+// diffRoles compares the declared roles with the app's roles and their
+// granted permission codes. System roles are read-only, so the manifest may not
+// declare them; pruning keeps them and the admin role every app is registered
+// with.
+func diffRoles(app App, roles []roleModels.RoleListItem, grants map[string][]string, prune bool) ([]roleStep, []Change, error) {
+	var steps []roleStep
+	var changes []Change
+	byCode := make(map[string]roleModels.RoleListItem, len(roles))
+	for _, role := range roles {
+		byCode[role.Code] = role
+	}
+
+	for _, role := range app.Roles {
+		// grants were checked by Validate
+		want, _ := app.grants(role)
+		current, exists := byCode[role.Code]
+		if current.IsSystem {
+			return nil, nil, fmt.Errorf("role %s is a system role and cannot be managed by a manifest", role.Code)
+		}
+		step := roleStep{id: current.ID, role: role}
+		if !exists {
+			changes = append(changes, Change{Action: ActionCreate, Kind: KindRole, App: app.Code, Target: role.Code, Detail: role.Name})
+		} else if details := roleDetails(role, current); len(details) > 0 {
+			step.update = true
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindRole, App: app.Code, Target: role.Code, Detail: strings.Join(details, ", ")})
+		}
+
+		got := slices.Clone(grants[current.ID])
+		slices.Sort(got)
+		if !slices.Equal(want, got) {
+			step.grants = want
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindGrants, App: app.Code, Target: role.Code, Detail: grantDetails(want, got)})
+		}
+		if step.id == "" || step.update || step.grants != nil {
+			steps = append(steps, step)
+		}
+	}
+
+	if !prune {
+		return steps, changes, nil
+	}
+	for _, current := range roles {
+		if current.IsSystem || current.Code == roleConstants.ROLE_CODE_ADMIN || slices.ContainsFunc(app.Roles, func(role Role) bool { return role.Code == current.Code }) {
+			continue
+		}
+		detail := ""
+		if current.MembersCount > 0 {
+			detail = fmt.Sprintf("%d member(s) must be reassigned first", current.MembersCount)
+		}
+		steps = append(steps, roleStep{id: current.ID, role: Role{Code: current.Code}, delete: true})
+		changes = append(changes, Change{Action: ActionDelete, Kind: KindRole, App: app.Code, Target: current.Code, Detail: detail})
+	}
+	return steps, changes, nil
+}
+
+func roleDetails(role Role, current roleModels.RoleListItem) []string {
+	var details []string
+	for _, field := range [][3]string{
+		{"name", current.Name, role.Name},
+		{"description", current.Description, role.Description},
+		{"icon", current.Icon, role.Icon},
+		{"color", current.Color, role.Color},
+	} {
+		if field[1] != field[2] {
+			details = append(details, fmt.Sprintf("%s %q → %q", field[0], field[1], field[2]))
+		}
+	}
+	return details
+}
+
+// grantDetails lists the codes a grant change adds (+) and removes (-).
+func grantDetails(want, got []string) string {
+	var details []string
+	for _, code := range want {
+		if !slices.Contains(got, code) {
+			details = append(details, "+"+code)
+		}
+	}
+	for _, code := range got {
+		if !slices.Contains(want, code) {
+			details = append(details, "-"+code)
+		}
+	}
+	return strings.Join(details, " ")
+}
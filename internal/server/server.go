@@ -8,13 +8,22 @@ import (
 
 	iapp "github.com/vukyn/isme/internal/app"
 	"github.com/vukyn/isme/internal/config"
+	"github.com/vukyn/isme/internal/constants"
+	idi "github.com/vukyn/isme/internal/di"
+	accessRequestHandlers "github.com/vukyn/isme/internal/domains/access_request/handlers/http"
+	activityHandlers "github.com/vukyn/isme/internal/domains/activity/handlers/http"
 	appServiceHandlers "github.com/vukyn/isme/internal/domains/app_service/handlers/http"
 	authHandlers "github.com/vukyn/isme/internal/domains/auth/handlers/http"
+	groupHandlers "github.com/vukyn/isme/internal/domains/group/handlers/http"
 	mediaHandlers "github.com/vukyn/isme/internal/domains/media/handlers/http"
 	roleHandlers "github.com/vukyn/isme/internal/domains/role/handlers/http"
 	settingsHandlers "github.com/vukyn/isme/internal/domains/settings/handlers/http"
 	userHandlers "github.com/vukyn/isme/internal/domains/user/handlers/http"
 	userInvitationHandlers "github.com/vukyn/isme/internal/domains/user_invitation/handlers/http"
+	"github.com/vukyn/isme/internal/health"
+	"github.com/vukyn/isme/internal/metrics"
+	"github.com/vukyn/isme/internal/openapi"
+	"github.com/vukyn/isme/internal/tracing"
 	"github.com/vukyn/isme/internal/web"
 
 	pkgCtx "github.com/vukyn/kuery/ctx"
@@ -56,12 +65,20 @@ func (s *Server) Start() {
 	})
 
 	// Middlewares
+	// server span per request, continuing an incoming traceparent; first so it
+	// covers every other middleware
+	s.app.Use(tracing.Middleware())
 	s.app.Use(cors.New())
 	zerologLogger := log.New().Zerolog()
 	s.app.Use(fiberzerolog.New(fiberzerolog.Config{
 		Logger: &zerologLogger,
 	}))
 
+	// request count/latency per matched route, fed to /metrics
+	if s.cfg.Metrics.Enabled {
+		s.app.Use(metrics.Middleware())
+	}
+
 	// inject di container to fiber ctx
 	s.app.Use(diContainerMiddleware)
 
@@ -79,16 +96,30 @@ func (s *Server) Start() {
 		Root: http.FS(assetsFS),
 	}))
 
+	// Liveness/readiness probes; registered ahead of the SPA catch-all so an
+	// unhealthy instance cannot answer a probe with the SPA shell
+	s.app.Get(constants.HEALTHZ_ENDPOINT, health.LivenessHandler())
+	s.app.Get(constants.READYZ_ENDPOINT, health.ReadinessHandler(idi.GetHealthChecker(iapp.App)))
+
+	// Prometheus scrape endpoint; registered ahead of the SPA catch-all
+	if s.cfg.Metrics.Enabled {
+		s.app.Get(constants.METRICS_ENDPOINT, metrics.Handler(metrics.Default, s.cfg.Metrics.Token))
+	}
+
 	// api/v1
-	apiV1 := s.app.Group("/api/v1")
-	authHandlers.SetupAuthRoutes(apiV1)
-	appServiceHandlers.SetupAppServiceRoutes(apiV1)
-	// before user routes so /users/invites is matched ahead of /users/:userID
-	userInvitationHandlers.SetupUserInvitationRoutes(apiV1)
-	userHandlers.SetupUserRoutes(apiV1)
-	roleHandlers.SetupRoleRoutes(apiV1)
-	settingsHandlers.SetupSettingsRoutes(apiV1)
-	mediaHandlers.SetupMediaRoutes(apiV1)
+	apiV1 := s.app.Group(apiBasePath)
+	// spec and docs first so neither can be shadowed by a domain param route
+	specHandler, err := openapi.SpecHandler(openapi.Build(openapi.Info{
+		Title:   s.cfg.App.Name + " API",
+		Version: "v1",
+	}, apiBasePath, apiOperations()...))
+	if err != nil {
+		log.New().Errorf("Failed to build OpenAPI document: %v", err)
+		os.Exit(1)
+	}
+	apiV1.Get(constants.OPENAPI_ENDPOINT, specHandler)
+	apiV1.Get(constants.DOCS_ENDPOINT, openapi.DocsHandler(apiBasePath+constants.OPENAPI_ENDPOINT))
+	registerAPIRoutes(apiV1)
 
 	// web routes
 	s.webRoutes(s.app, uiFS)
@@ -107,6 +138,57 @@ func (s *Server) Stop() error {
 	return s.app.Shutdown()
 }
 
+// NewAPI returns an app serving only the api/v1 routes: no UI, probes, metrics,
+// tracing or request log. iapp.App must already be built, since the routes
+// resolve their middleware from it. testkit serves it from an httptest-style
+// listener.
+func NewAPI() *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(diContainerMiddleware)
+	app.Use(pkgRecover.NewFiberRecover())
+	registerAPIRoutes(app.Group(apiBasePath))
+	return app
+}
+
+const apiBasePath = "/api/v1"
+
+// registerAPIRoutes mounts every domain router under router. Each domain's
+// route table must list exactly the routes its Setup*Routes registers:
+// apiOperations feeds them to the OpenAPI document, and the drift test in this
+// package compares the two.
+func registerAPIRoutes(router fiber.Router) {
+	authHandlers.SetupAuthRoutes(router)
+	appServiceHandlers.SetupAppServiceRoutes(router)
+	// before user routes so /users/invites is matched ahead of /users/:userID
+	userInvitationHandlers.SetupUserInvitationRoutes(router)
+	userHandlers.SetupUserRoutes(router)
+	roleHandlers.SetupRoleRoutes(router)
+	settingsHandlers.SetupSettingsRoutes(router)
+	mediaHandlers.SetupMediaRoutes(router)
+	activityHandlers.SetupActivityRoutes(router)
+	accessRequestHandlers.SetupAccessRequestRoutes(router)
+	groupHandlers.SetupGroupRoutes(router)
+}
+
+func apiOperations() []openapi.Operation {
+	var ops []openapi.Operation
+	for _, domain := range [][]openapi.Operation{
+		authHandlers.Operations,
+		appServiceHandlers.Operations,
+		userInvitationHandlers.Operations,
+		userHandlers.Operations,
+		roleHandlers.Operations,
+		settingsHandlers.Operations,
+		mediaHandlers.Operations,
+		activityHandlers.Operations,
+		accessRequestHandlers.Operations,
+		groupHandlers.Operations,
+	} {
+		ops = append(ops, domain...)
+	}
+	return ops
+}
+
 func diContainerMiddleware(c *fiber.Ctx) error {
 	request, err := iapp.App.SubContainer()
 	if err != nil {
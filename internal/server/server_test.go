@@ -0,0 +1,68 @@
+package server
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	iapp "github.com/vukyn/isme/internal/app"
+	"github.com/vukyn/isme/internal/constants"
+	"github.com/vukyn/isme/internal/middlewares"
+	"github.com/vukyn/isme/internal/openapi"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sarulabs/di/v2"
+)
+
+// TestOpenAPIMatchesRouter fails when a route is registered without an entry in
+// its domain's route table, or a table entry no longer has a route behind it.
+// The middleware is a zero value: routes are only registered, never served.
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	builder, err := di.NewBuilder()
+	if err != nil {
+		t.Fatalf("builder: %v", err)
+	}
+	if err := builder.Set(constants.CONTAINER_NAME_MIDDLEWARE, &middlewares.Middleware{}); err != nil {
+		t.Fatalf("set middleware: %v", err)
+	}
+	iapp.App = builder.Build()
+	t.Cleanup(func() { iapp.App = nil })
+
+	app := fiber.New()
+	registerAPIRoutes(app.Group(apiBasePath))
+
+	registered := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		// fiber adds a HEAD route for every GET on its own
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		registered[route.Method+" "+openapi.SpecPath(strings.TrimPrefix(route.Path, apiBasePath))] = true
+	}
+
+	documented := map[string]bool{}
+	doc := openapi.Build(openapi.Info{Title: "isme API", Version: "v1"}, apiBasePath, apiOperations()...)
+	for path, item := range doc.Paths {
+		for method := range *item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	if missing := difference(registered, documented); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI route tables:\n  %s", strings.Join(missing, "\n  "))
+	}
+	if stale := difference(documented, registered); len(stale) > 0 {
+		t.Errorf("OpenAPI operations with no registered route:\n  %s", strings.Join(stale, "\n  "))
+	}
+}
+
+func difference(a, b map[string]bool) []string {
+	var out []string
+	for key := range a {
+		if !b[key] {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+)
+
+// QueryHook opens a client span per query. The statement itself is not
+// recorded: bun inlines the arguments, which include password hashes and
+// token digests.
+type QueryHook struct{}
+
+var _ bun.QueryHook = QueryHook{}
+
+type querySpanKey struct{}
+
+func (QueryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	operation := event.Operation()
+	attributes := []Attribute{
+		String("db.system", "sql"),
+		String("db.operation", operation),
+	}
+	name := "db " + operation
+	if event.IQuery != nil {
+		if table := event.IQuery.GetTableName(); table != "" {
+			attributes = append(attributes, String("db.sql.table", table))
+			name += " " + table
+		}
+	}
+	ctx, span := Start(ctx, name, WithKind(SpanKindClient), WithAttributes(attributes...))
+	if span == nil {
+		return ctx
+	}
+	// a dedicated key, so AfterQuery never ends the caller's span
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+func (QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	span, _ := ctx.Value(querySpanKey{}).(*Span)
+	if span == nil {
+		return
+	}
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		span.RecordError(event.Err)
+	}
+	span.End()
+}
@@ -0,0 +1,292 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshot is an ended span's data, copied under its lock.
+type snapshot struct {
+	sc         SpanContext
+	parent     SpanID
+	kind       SpanKind
+	start      time.Time
+	end        time.Time
+	name       string
+	attributes []Attribute
+	events     []Event
+	status     StatusCode
+	statusMsg  string
+}
+
+func (s *Span) snapshot() snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return snapshot{
+		sc:         s.sc,
+		parent:     s.parent,
+		kind:       s.kind,
+		start:      s.start,
+		end:        s.end,
+		name:       s.name,
+		attributes: append([]Attribute(nil), s.attributes...),
+		events:     append([]Event(nil), s.events...),
+		status:     s.status,
+		statusMsg:  s.statusMsg,
+	}
+}
+
+// OTLPExporter posts spans to an OTLP/HTTP collector using the JSON encoding
+// (POST <endpoint>/v1/traces).
+type OTLPExporter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewOTLPExporter targets endpoint, the collector's base URL (e.g.
+// "http://localhost:4318"). headers are sent with every export, e.g. an API key
+// for a hosted backend.
+func NewOTLPExporter(endpoint string, headers map[string]string, timeout time.Duration) *OTLPExporter {
+	return &OTLPExporter{
+		url:     strings.TrimRight(endpoint, "/") + "/v1/traces",
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// ParseHeaders reads "key1=value1,key2=value2" (the OTEL_EXPORTER_OTLP_HEADERS
+// format) into a map, skipping malformed pairs.
+func ParseHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			continue
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+// The OTLP JSON shapes: IDs are hex, 64-bit integers are decimal strings.
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              SpanKind       `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpStatus struct {
+		Code    StatusCode `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+// otlpScopeName is the instrumentation scope reported for every span.
+const otlpScopeName = "github.com/vukyn/isme/internal/tracing"
+
+func otlpRequest(spans []*Span) otlpTraces {
+	// spans from one process share one resource (the tracer's service name)
+	serviceName := "isme"
+	if spans[0].tracer != nil {
+		serviceName = spans[0].tracer.serviceName
+	}
+	out := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := span.snapshot()
+		o := otlpSpan{
+			TraceID:           s.sc.TraceID.String(),
+			SpanID:            s.sc.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attributes),
+			Status:            otlpStatus{Code: s.status, Message: s.statusMsg},
+		}
+		if s.parent.IsValid() {
+			o.ParentSpanID = s.parent.String()
+		}
+		for _, event := range s.events {
+			o.Events = append(o.Events, otlpEvent{
+				TimeUnixNano: strconv.FormatInt(event.Time.UnixNano(), 10),
+				Name:         event.Name,
+				Attributes:   otlpAttributes(event.Attributes),
+			})
+		}
+		out = append(out, o)
+	}
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: otlpAttributes([]Attribute{String("service.name", serviceName)})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: otlpScopeName},
+			Spans: out,
+		}},
+	}}}
+}
+
+func otlpAttributes(attributes []Attribute) []otlpKeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+	out := make([]otlpKeyValue, 0, len(attributes))
+	for _, attribute := range attributes {
+		var value map[string]any
+		switch v := attribute.Value.(type) {
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": v}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		out = append(out, otlpKeyValue{Key: attribute.Key, Value: value})
+	}
+	return out
+}
+
+// StdoutExporter writes one JSON object per span — for local debugging without
+// a collector.
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+type stdoutSpan struct {
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Name         string         `json:"name"`
+	Kind         string         `json:"kind"`
+	Start        time.Time      `json:"start"`
+	DurationMs   float64        `json:"duration_ms"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Events       []string       `json:"events,omitempty"`
+	Status       string         `json:"status,omitempty"`
+	StatusMsg    string         `json:"status_message,omitempty"`
+}
+
+func (e *StdoutExporter) Export(ctx context.Context, spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	encoder := json.NewEncoder(e.w)
+	for _, span := range spans {
+		s := span.snapshot()
+		out := stdoutSpan{
+			TraceID:    s.sc.TraceID.String(),
+			SpanID:     s.sc.SpanID.String(),
+			Name:       s.name,
+			Kind:       s.kind.String(),
+			Start:      s.start.UTC(),
+			DurationMs: float64(s.end.Sub(s.start).Microseconds()) / 1000,
+			StatusMsg:  s.statusMsg,
+		}
+		if s.parent.IsValid() {
+			out.ParentSpanID = s.parent.String()
+		}
+		if len(s.attributes) > 0 {
+			out.Attributes = make(map[string]any, len(s.attributes))
+			for _, attribute := range s.attributes {
+				out.Attributes[attribute.Key] = attribute.Value
+			}
+		}
+		for _, event := range s.events {
+			out.Events = append(out.Events, event.Name)
+		}
+		switch s.status {
+		case StatusOK:
+			out.Status = "ok"
+		case StatusError:
+			out.Status = "error"
+		}
+		if err := encoder.Encode(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func endedSpan() *Span {
+	start := time.Unix(1700000000, 0)
+	span := &Span{
+		tracer: &Tracer{serviceName: "isme-test"},
+		sc: SpanContext{
+			TraceID: TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:  SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			Sampled: true,
+		},
+		parent: SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		kind:   SpanKindServer,
+		start:  start,
+		end:    start.Add(1500 * time.Microsecond),
+		name:   "GET /api/v1/users/:userID",
+		ended:  true,
+	}
+	span.attributes = []Attribute{
+		String("http.route", "/api/v1/users/:userID"),
+		Int("http.response.status_code", 500),
+		Bool("auth.sso", true),
+	}
+	span.RecordError(errors.New("boom"))
+	return span
+}
+
+func TestOTLPExporterPostsJSON(t *testing.T) {
+	var (
+		gotPath, gotAuth string
+		gotBody          map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gotBody); err != nil {
+			t.Errorf("body is not JSON: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(srv.URL+"/", ParseHeaders("Authorization=Bearer k, bad"), time.Second)
+	if err := exporter.Export(context.Background(), []*Span{endedSpan()}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if gotPath != "/v1/traces" || gotAuth != "Bearer k" {
+		t.Fatalf("path %q auth %q", gotPath, gotAuth)
+	}
+
+	resourceSpans := gotBody["resourceSpans"].([]any)[0].(map[string]any)
+	resource := resourceSpans["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+	if resource["key"] != "service.name" || resource["value"].(map[string]any)["stringValue"] != "isme-test" {
+		t.Fatalf("resource = %v", resource)
+	}
+	span := resourceSpans["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	for key, want := range map[string]any{
+		"traceId":           "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":            "00f067aa0ba902b7",
+		"parentSpanId":      "0102030405060708",
+		"kind":              float64(SpanKindServer),
+		"startTimeUnixNano": "1700000000000000000",
+		"endTimeUnixNano":   "1700000000001500000",
+	} {
+		if span[key] != want {
+			t.Errorf("span[%q] = %v, want %v", key, span[key], want)
+		}
+	}
+	if code := span["status"].(map[string]any)["code"]; code != float64(StatusError) {
+		t.Errorf("status code = %v", code)
+	}
+	status := span["attributes"].([]any)[1].(map[string]any)["value"].(map[string]any)
+	if status["intValue"] != "500" {
+		t.Errorf("int attribute = %v, want the OTLP decimal string", status)
+	}
+}
+
+func TestOTLPExporterReportsRejection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := NewOTLPExporter(srv.URL, nil, time.Second).Export(context.Background(), []*Span{endedSpan()})
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("err = %v, want the collector status", err)
+	}
+}
+
+func TestStdoutExporterWritesOneLinePerSpan(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewStdoutExporter(&buf).Export(context.Background(), []*Span{endedSpan(), endedSpan()}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if got["kind"] != "server" || got["status"] != "error" || got["duration_ms"] != 1.5 {
+		t.Fatalf("line = %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	pkgCtx "github.com/vukyn/kuery/ctx"
+)
+
+// Middleware opens a server span per request, continuing the caller's trace
+// when a valid traceparent header arrives. It runs first, so the span covers
+// every other middleware; the span is named after the matched route template
+// once routing has happened.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		if sc, ok := ParseTraceparent(c.Get(TraceparentHeader)); ok {
+			ctx = ContextWithRemoteSpanContext(ctx, sc)
+		}
+		ctx, span := Start(ctx, c.Method(), WithKind(SpanKindServer), WithAttributes(
+			String("http.request.method", c.Method()),
+			String("url.path", c.Path()),
+		))
+		if span == nil {
+			c.SetUserContext(ctx)
+			return c.Next()
+		}
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// the error handler has not written the response yet
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			String("http.route", route),
+			Int("http.response.status_code", status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(StatusError, "")
+			span.RecordError(err)
+		}
+		return err
+	}
+}
+
+// NewContextFromFiberCtx is pkgCtx.NewContextFromFiberCtx plus the request's
+// span, so usecase and repository spans nest under the server span.
+func NewContextFromFiberCtx(c *fiber.Ctx) context.Context {
+	return Carry(pkgCtx.NewContextFromFiberCtx(c), c.UserContext())
+}
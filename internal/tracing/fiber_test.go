@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	rec, flush := install(t, 1)
+
+	var handlerTrace string
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/users/:userID", func(c *fiber.Ctx) error {
+		handlerTrace = Traceparent(c.UserContext())
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusServiceUnavailable, "down") })
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/boom", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	flush()
+
+	spans := rec.byName()
+	users, ok := spans["GET /users/:userID"]
+	if !ok {
+		t.Fatalf("no span named after the route template in %v", spans)
+	}
+	if users.sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || users.parent.String() != "00f067aa0ba902b7" {
+		t.Fatalf("span %s parent %s did not continue the incoming trace", users.sc.TraceID, users.parent)
+	}
+	if users.kind != SpanKindServer || users.status != StatusUnset {
+		t.Fatalf("kind=%v status=%v", users.kind, users.status)
+	}
+	if want := FormatTraceparent(users.sc); handlerTrace != want {
+		t.Fatalf("handler saw %q, want the server span %q", handlerTrace, want)
+	}
+	if boom := spans["GET /boom"]; boom.status != StatusError {
+		t.Fatalf("5xx span status = %v, want error", boom.status)
+	}
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vukyn/kuery/log"
+)
+
+// Exporter ships a batch of ended spans to a backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+	Shutdown(ctx context.Context) error
+}
+
+// ProcessorOptions tunes span batching. Ending a span never blocks: once the
+// queue is full further spans are dropped and counted.
+type ProcessorOptions struct {
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	ExportTimeout time.Duration
+}
+
+func (o ProcessorOptions) withDefaults() ProcessorOptions {
+	if o.QueueSize <= 0 {
+		o.QueueSize = 2048
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 512
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = 5 * time.Second
+	}
+	if o.ExportTimeout <= 0 {
+		o.ExportTimeout = 10 * time.Second
+	}
+	return o
+}
+
+type processor struct {
+	exporter Exporter
+	opts     ProcessorOptions
+	queue    chan *Span
+	dropped  atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+func newProcessor(exporter Exporter, opts ProcessorOptions) *processor {
+	opts = opts.withDefaults()
+	p := &processor{
+		exporter: exporter,
+		opts:     opts,
+		queue:    make(chan *Span, opts.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *processor) enqueue(span *Span) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- span:
+	default:
+		if n := p.dropped.Add(1); n&(n-1) == 0 {
+			log.New().Warnf("Tracing: export queue full, %d span(s) dropped so far", n)
+		}
+	}
+}
+
+func (p *processor) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, p.opts.BatchSize)
+	for {
+		select {
+		case span, ok := <-p.queue:
+			if !ok {
+				p.export(batch)
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= p.opts.BatchSize {
+				p.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			p.export(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// export sends one batch; a failed batch is logged and dropped — traces are
+// diagnostic, not worth retrying at the cost of memory.
+func (p *processor) export(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.ExportTimeout)
+	defer cancel()
+	if err := p.exporter.Export(ctx, batch); err != nil {
+		log.New().Warnf("Tracing: failed to export %d span(s): %v", len(batch), err)
+	}
+}
+
+func (p *processor) shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.exporter.Shutdown(ctx)
+}
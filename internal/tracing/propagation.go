@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceparentHeader is the W3C trace-context header.
+const TraceparentHeader = "traceparent"
+
+// ParseTraceparent reads a W3C traceparent value:
+//
+//	version "-" trace-id "-" parent-id "-" trace-flags
+//	00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// Unknown future versions are accepted when their first four fields parse, as
+// the spec asks; version ff and all-zero IDs are rejected.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(traceID)); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(spanID)); err != nil {
+		return SpanContext{}, false
+	}
+	var flagByte [1]byte
+	if _, err := hex.Decode(flagByte[:], []byte(flags)); err != nil {
+		return SpanContext{}, false
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flagByte[0]&0x01 == 0x01
+	sc.Remote = true
+	return sc, true
+}
+
+// FormatTraceparent renders sc as a version-00 traceparent value.
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// Traceparent returns the header value for an outgoing request made from ctx,
+// or "" when ctx carries no trace.
+func Traceparent(ctx context.Context) string {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return FormatTraceparent(sc)
+}
@@ -0,0 +1,42 @@
+package tracing
+
+import "testing"
+
+func TestParseTraceparent(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		ok      bool
+		sampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		// a future version may append fields
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", false, false},
+		{"", false, false},
+	} {
+		sc, ok := ParseTraceparent(tc.value)
+		if ok != tc.ok || sc.Sampled != tc.sampled {
+			t.Errorf("ParseTraceparent(%q) = (sampled %v, %v), want (sampled %v, %v)", tc.value, sc.Sampled, ok, tc.sampled, tc.ok)
+		}
+		if ok && !sc.Remote {
+			t.Errorf("ParseTraceparent(%q) not marked remote", tc.value)
+		}
+	}
+}
+
+func TestFormatTraceparentRoundTrips(t *testing.T) {
+	const value = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(value)
+	if !ok {
+		t.Fatal("parse failed")
+	}
+	if got := FormatTraceparent(sc); got != value {
+		t.Fatalf("FormatTraceparent = %q, want %q", got, value)
+	}
+}
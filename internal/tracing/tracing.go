@@ -0,0 +1,333 @@
+// Package tracing is isme's distributed tracing: spans carried on the context,
+// W3C trace-context propagation, and batched export to an OTLP/HTTP collector
+// (JSON encoding) or stdout.
+//
+// It follows the OpenTelemetry data model — trace/span IDs, span kinds,
+// attributes, status, the OTLP wire shape — so any OTLP-speaking collector
+// (otel-collector, Jaeger, Tempo) accepts the spans, without pulling the OTel
+// SDK into the module.
+//
+// Until Init installs a tracer every Start is a no-op returning a nil *Span,
+// and every *Span method is nil-safe, so instrumented code never checks
+// whether tracing is on.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace; SpanID a span within it.
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (t TraceID) IsValid() bool  { return t != TraceID{} }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+func (s SpanID) IsValid() bool   { return s != SpanID{} }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	// Remote marks a context extracted from an incoming request.
+	Remote bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind values match the OTLP enum.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	}
+	return "internal"
+}
+
+// StatusCode values match the OTLP enum.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Attribute is one key/value pair on a span or event. Value is a string, bool,
+// int64 or float64.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute          { return Attribute{key, value} }
+func Bool(key string, value bool) Attribute       { return Attribute{key, value} }
+func Int(key string, value int) Attribute         { return Attribute{key, int64(value)} }
+func Int64(key string, value int64) Attribute     { return Attribute{key, value} }
+func Float64(key string, value float64) Attribute { return Attribute{key, value} }
+
+// Event is a timestamped annotation on a span (e.g. a recorded error).
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// Span is one timed operation. All methods are safe on a nil *Span, which is
+// what Start returns when the trace is not being recorded.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+	parent SpanID
+	kind   SpanKind
+	start  time.Time
+
+	mu         sync.Mutex
+	name       string
+	end        time.Time
+	attributes []Attribute
+	events     []Event
+	status     StatusCode
+	statusMsg  string
+	ended      bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetName renames the span, e.g. once the matched route is known.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, attributes...)
+}
+
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status, s.statusMsg = code, message
+}
+
+// RecordError adds an "exception" event and marks the span failed. A nil err
+// is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, Event{
+		Name: "exception",
+		Time: time.Now(),
+		Attributes: []Attribute{
+			String("exception.type", fmt.Sprintf("%T", err)),
+			String("exception.message", err.Error()),
+		},
+	})
+	s.status, s.statusMsg = StatusError, err.Error()
+}
+
+// End finishes the span and hands it to the exporter; later calls are no-ops.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.tracer.processor.enqueue(s)
+}
+
+// Tracer creates spans and owns the export pipeline.
+type Tracer struct {
+	serviceName string
+	sampleRatio float64
+	processor   *processor
+}
+
+var global atomic.Pointer[Tracer]
+
+// Options configures a Tracer.
+type Options struct {
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded (0..1). Requests
+	// that arrive with a traceparent follow the caller's sampled flag instead.
+	SampleRatio float64
+	Processor   ProcessorOptions
+}
+
+// Init installs a tracer exporting through exporter and returns it; Shutdown
+// flushes it. Spans started before Init, or after Shutdown, are not recorded.
+func Init(exporter Exporter, opts Options) *Tracer {
+	if opts.ServiceName == "" {
+		opts.ServiceName = "isme"
+	}
+	t := &Tracer{
+		serviceName: opts.ServiceName,
+		sampleRatio: opts.SampleRatio,
+	}
+	t.processor = newProcessor(exporter, opts.Processor)
+	global.Store(t)
+	return t
+}
+
+// Shutdown stops recording, flushes queued spans and closes the exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	global.CompareAndSwap(t, nil)
+	return t.processor.shutdown(ctx)
+}
+
+// SpanOption configures Start.
+type SpanOption func(*Span)
+
+func WithKind(kind SpanKind) SpanOption {
+	return func(s *Span) { s.kind = kind }
+}
+
+func WithAttributes(attributes ...Attribute) SpanOption {
+	return func(s *Span) { s.attributes = append(s.attributes, attributes...) }
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// Start begins a span as a child of the span (or remote span context) carried
+// by ctx and returns a context carrying it. It returns a nil span and ctx
+// unchanged when no tracer is installed or the trace is not sampled.
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, nil
+	}
+
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID, sc.Sampled = parent.TraceID, parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample(sc.TraceID)
+	}
+	if !sc.Sampled {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer: t,
+		sc:     sc,
+		parent: parent.SpanID,
+		kind:   SpanKindInternal,
+		start:  time.Now(),
+		name:   name,
+	}
+	for _, opt := range opts {
+		opt(span)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// sample decides from the trace ID alone, so every service sampling the same
+// ratio agrees on the same traces.
+func (t *Tracer) sample(traceID TraceID) bool {
+	switch {
+	case t.sampleRatio >= 1:
+		return true
+	case t.sampleRatio <= 0:
+		return false
+	}
+	bound := uint64(t.sampleRatio * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext makes sc (extracted from an incoming request)
+// the parent of the next span started from the returned context.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext returns the current span's context, falling back to
+// a remote parent extracted from the request.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// Carry copies the tracing state of src onto dst — for when a handler builds
+// a fresh context instead of deriving it from the request's.
+func Carry(dst, src context.Context) context.Context {
+	if span := SpanFromContext(src); span != nil {
+		return context.WithValue(dst, spanKey{}, span)
+	}
+	if sc, ok := src.Value(remoteKey{}).(SpanContext); ok {
+		return context.WithValue(dst, remoteKey{}, sc)
+	}
+	return dst
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is an Exporter that keeps every exported span.
+type recorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (r *recorder) Export(_ context.Context, spans []*Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func (r *recorder) Shutdown(context.Context) error { return nil }
+
+func (r *recorder) byName() map[string]snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := map[string]snapshot{}
+	for _, span := range r.spans {
+		s := span.snapshot()
+		out[s.name] = s
+	}
+	return out
+}
+
+// install sets up a tracer for one test and returns a flush func that shuts it
+// down, so every ended span has reached the recorder when it returns.
+func install(t *testing.T, ratio float64) (*recorder, func()) {
+	t.Helper()
+	rec := &recorder{}
+	tracer := Init(rec, Options{ServiceName: "test", SampleRatio: ratio})
+	flush := func() {
+		if err := tracer.Shutdown(context.Background()); err != nil {
+			t.Fatalf("shutdown: %v", err)
+		}
+	}
+	t.Cleanup(func() { _ = tracer.Shutdown(context.Background()) })
+	return rec, flush
+}
+
+func TestStartWithoutTracerIsNoop(t *testing.T) {
+	ctx, span := Start(context.Background(), "op")
+	if span != nil {
+		t.Fatalf("span = %v, want nil without a tracer", span)
+	}
+	// every method must be safe on the nil span
+	span.SetName("x")
+	span.SetAttributes(String("k", "v"))
+	span.SetStatus(StatusError, "x")
+	span.RecordError(errors.New("x"))
+	span.End()
+	if SpanContextFromContext(ctx).IsValid() {
+		t.Fatal("context carries a span context without a tracer")
+	}
+}
+
+func TestStartNestsChildUnderParent(t *testing.T) {
+	rec, flush := install(t, 1)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child", WithKind(SpanKindClient), WithAttributes(Int("n", 1)))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	parent.End()
+	flush()
+
+	spans := rec.byName()
+	p, c := spans["parent"], spans["child"]
+	if p.sc.TraceID != c.sc.TraceID {
+		t.Fatalf("child trace %s, want parent trace %s", c.sc.TraceID, p.sc.TraceID)
+	}
+	if c.parent != p.sc.SpanID {
+		t.Fatalf("child parent %s, want %s", c.parent, p.sc.SpanID)
+	}
+	if p.parent.IsValid() {
+		t.Fatalf("root span has parent %s", p.parent)
+	}
+	if c.kind != SpanKindClient || c.status != StatusError || len(c.events) != 1 {
+		t.Fatalf("child kind=%v status=%v events=%d", c.kind, c.status, len(c.events))
+	}
+}
+
+func TestStartContinuesRemoteParent(t *testing.T) {
+	rec, flush := install(t, 0)
+
+	remote, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok {
+		t.Fatal("parse failed")
+	}
+	// ratio 0 would drop a new trace, but a sampled caller wins
+	_, span := Start(ContextWithRemoteSpanContext(context.Background(), remote), "server")
+	if span == nil {
+		t.Fatal("sampled remote parent was not followed")
+	}
+	span.End()
+
+	remote.Sampled = false
+	if _, span := Start(ContextWithRemoteSpanContext(context.Background(), remote), "dropped"); span != nil {
+		t.Fatal("unsampled remote parent was recorded")
+	}
+	flush()
+
+	s := rec.byName()["server"]
+	if s.sc.TraceID != remote.TraceID || s.parent != remote.SpanID {
+		t.Fatalf("span %s/%s, want child of %s/%s", s.sc.TraceID, s.parent, remote.TraceID, remote.SpanID)
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	install(t, 0.25)
+
+	sampled := 0
+	for range 4000 {
+		if _, span := Start(context.Background(), "op"); span != nil {
+			sampled++
+		}
+	}
+	// 1000 expected; the bound is loose enough never to flake
+	if sampled < 800 || sampled > 1200 {
+		t.Fatalf("sampled %d of 4000 at ratio 0.25", sampled)
+	}
+}
+
+func TestCarryMovesSpanToFreshContext(t *testing.T) {
+	install(t, 1)
+
+	ctx, span := Start(context.Background(), "op")
+	defer span.End()
+	carried := Carry(context.Background(), ctx)
+	if SpanFromContext(carried) != span {
+		t.Fatal("span not carried")
+	}
+}
+
+func TestProcessorDropsWhenQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	exporter := &blockingExporter{release: block}
+	p := newProcessor(exporter, ProcessorOptions{QueueSize: 2, BatchSize: 1, FlushInterval: time.Hour})
+	tracer := &Tracer{sampleRatio: 1, processor: p}
+
+	// the worker takes the first span and blocks exporting it; two more fill
+	// the queue and the rest are dropped without blocking End
+	done := make(chan struct{})
+	go func() {
+		for range 10 {
+			(&Span{tracer: tracer}).End()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("End blocked on a full queue")
+	}
+	close(block)
+	if err := p.shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if dropped := p.dropped.Load(); dropped < 7 {
+		t.Fatalf("dropped %d spans, want at least 7", dropped)
+	}
+}
+
+type blockingExporter struct {
+	release chan struct{}
+}
+
+func (e *blockingExporter) Export(context.Context, []*Span) error {
+	<-e.release
+	return nil
+}
+
+func (e *blockingExporter) Shutdown(context.Context) error { return nil }
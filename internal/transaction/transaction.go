@@ -0,0 +1,112 @@
+// Package transaction threads a bun transaction through a context.Context so a
+// usecase can span several repositories (and the audit recorder) with a single
+// commit, without every repository method growing a tx parameter.
+//
+// Repositories resolve their executor with Conn(ctx, r.db): inside Run it is the
+// ambient bun.Tx, outside it is the plain *bun.DB — so a repository method
+// behaves identically whether or not the caller opened a transaction.
+package transaction
+
+import (
+	"context"
+	"sync"
+
+	"github.com/uptrace/bun"
+)
+
+type txKey struct{}
+
+type hooksKey struct{}
+
+// afterCommitHooks collects the callbacks registered with AfterCommit during
+// the outermost Run; they fire once, in order, after the commit succeeds.
+type afterCommitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (h *afterCommitHooks) add(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = append(h.fns, fn)
+}
+
+func (h *afterCommitHooks) run() {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Runner opens a transaction and runs fn with it carried on the context.
+type Runner interface {
+	// Run executes fn inside a transaction. When ctx already carries one (a
+	// nested Run), fn joins it instead of opening a second, so the outermost
+	// caller owns the commit/rollback. Returning an error from fn rolls back.
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type runner struct {
+	db *bun.DB
+}
+
+func NewRunner(db *bun.DB) Runner {
+	return &runner{db: db}
+}
+
+func (r *runner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := From(ctx); ok {
+		return fn(ctx)
+	}
+	hooks := &afterCommitHooks{}
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(context.WithValue(WithTx(ctx, tx), hooksKey{}, hooks))
+	})
+	if err != nil {
+		return err
+	}
+	hooks.run()
+	return nil
+}
+
+// AfterCommit defers fn until the transaction carried by ctx commits, and
+// drops it on rollback — for side effects that must not announce a change
+// that never happened (e.g. shipping an audit row to an external sink). Outside
+// a Run, fn runs immediately.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(hooksKey{}).(*afterCommitHooks); ok {
+		hooks.add(fn)
+		return
+	}
+	fn()
+}
+
+// WithTx returns a copy of ctx carrying tx.
+func WithTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// From returns the transaction carried by ctx, if any.
+func From(ctx context.Context) (bun.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(bun.Tx)
+	return tx, ok
+}
+
+// Conn returns the ambient transaction when ctx carries one, else db.
+func Conn(ctx context.Context, db *bun.DB) bun.IDB {
+	if tx, ok := From(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// NoopRunner runs fn directly without a transaction. Used by usecase tests that
+// wire fake repositories and have no database to open one against.
+type NoopRunner struct{}
+
+func (NoopRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
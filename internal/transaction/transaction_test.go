@@ -0,0 +1,139 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqldb, err := sql.Open(sqliteshim.ShimName, ":memory:")
+	if err != nil {
+		t.Fatalf("open in-memory sqlite: %v", err)
+	}
+	sqldb.SetMaxOpenConns(1)
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	if _, err := db.ExecContext(context.Background(), `CREATE TABLE items (name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertItem(ctx context.Context, db *bun.DB, name string) error {
+	_, err := Conn(ctx, db).NewRaw(`INSERT INTO items (name) VALUES (?)`, name).Exec(ctx)
+	return err
+}
+
+func countItems(t *testing.T, db *bun.DB) int {
+	t.Helper()
+	var count int
+	if err := db.NewRaw(`SELECT COUNT(*) FROM items`).Scan(context.Background(), &count); err != nil {
+		t.Fatalf("count items: %v", err)
+	}
+	return count
+}
+
+// TestRunCommitsOnSuccess proves writes made through Conn inside Run commit.
+func TestRunCommitsOnSuccess(t *testing.T) {
+	db := newTestDB(t)
+
+	err := NewRunner(db).Run(context.Background(), func(ctx context.Context) error {
+		if _, ok := From(ctx); !ok {
+			t.Fatal("expected the context to carry a transaction")
+		}
+		return insertItem(ctx, db, "a")
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := countItems(t, db); got != 1 {
+		t.Fatalf("expected 1 committed row, got %d", got)
+	}
+}
+
+// TestRunRollsBackOnError proves an error from fn discards every write made
+// through Conn, including ones that succeeded before the failing step.
+func TestRunRollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+
+	err := NewRunner(db).Run(context.Background(), func(ctx context.Context) error {
+		if err := insertItem(ctx, db, "a"); err != nil {
+			return err
+		}
+		return errors.New("audit write failed")
+	})
+	if err == nil {
+		t.Fatal("expected the fn error to propagate")
+	}
+	if got := countItems(t, db); got != 0 {
+		t.Fatalf("expected the write to be rolled back, got %d rows", got)
+	}
+}
+
+// TestNestedRunJoinsOuterTransaction proves an inner Run does not commit on its
+// own: the outer failure rolls back the inner write too.
+func TestNestedRunJoinsOuterTransaction(t *testing.T) {
+	db := newTestDB(t)
+	runner := NewRunner(db)
+
+	err := runner.Run(context.Background(), func(ctx context.Context) error {
+		if err := runner.Run(ctx, func(ctx context.Context) error {
+			return insertItem(ctx, db, "inner")
+		}); err != nil {
+			return err
+		}
+		return errors.New("outer failed")
+	})
+	if err == nil {
+		t.Fatal("expected the outer error to propagate")
+	}
+	if got := countItems(t, db); got != 0 {
+		t.Fatalf("expected the inner write to roll back with the outer, got %d rows", got)
+	}
+}
+
+// TestAfterCommitRunsOnlyOnCommit proves hooks registered inside Run (nested
+// included) fire after a commit and are dropped on rollback.
+func TestAfterCommitRunsOnlyOnCommit(t *testing.T) {
+	db := newTestDB(t)
+	runner := NewRunner(db)
+
+	fired := []string{}
+	err := runner.Run(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() { fired = append(fired, "outer") })
+		return runner.Run(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func() { fired = append(fired, "inner") })
+			if len(fired) != 0 {
+				t.Fatal("expected hooks to wait for the commit")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(fired) != 2 || fired[0] != "outer" || fired[1] != "inner" {
+		t.Fatalf("expected both hooks in order after commit, got %v", fired)
+	}
+
+	fired = fired[:0]
+	_ = runner.Run(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() { fired = append(fired, "rolled back") })
+		return errors.New("failed")
+	})
+	if len(fired) != 0 {
+		t.Fatalf("expected no hooks after a rollback, got %v", fired)
+	}
+
+	AfterCommit(context.Background(), func() { fired = append(fired, "immediate") })
+	if len(fired) != 1 {
+		t.Fatal("expected a hook outside Run to fire immediately")
+	}
+}
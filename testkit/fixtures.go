@@ -0,0 +1,123 @@
+package testkit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vukyn/kuery/cryp"
+	"github.com/vukyn/kuery/cryp/aes"
+	kueryRand "github.com/vukyn/kuery/cryp/rand"
+)
+
+const (
+	statusActive = 1
+	// fixtureActor fills created_by/updated_by, as the seeder does
+	fixtureActor = ""
+	// appCtxInfo is the ctx_info every fixture app registers with; it is also
+	// the AES additional data its secret is encrypted under
+	appCtxInfo = "testkit"
+)
+
+// User is a verified, active account that can sign in with Password.
+type User struct {
+	ID       string
+	Email    string
+	Password string
+}
+
+// App is a registered app service; Code, Secret and CtxInfo are what its
+// backend passes to RequestLogin.
+type App struct {
+	ID          string
+	Code        string
+	Secret      string
+	CtxInfo     string
+	RedirectURL string
+}
+
+// CreateUser adds a user who can sign in straight away.
+func (s *Server) CreateUser(email, password string) User {
+	s.t.Helper()
+	user := User{ID: cryp.ULID(), Email: email, Password: password}
+	name, _, _ := strings.Cut(email, "@")
+	s.exec(`
+		INSERT INTO users (id, name, email, password, status, is_verified, created_by, updated_by)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+	`, user.ID, name, email, cryp.HashArgon2id(password), statusActive, fixtureActor, fixtureActor)
+	return user
+}
+
+// CreateApp registers an active app service with a generated secret.
+// redirectURL is its primary redirect_url, so RequestLogin without a
+// redirect_uri returns there.
+func (s *Server) CreateApp(code, redirectURL string) App {
+	s.t.Helper()
+	app := App{
+		ID:          cryp.ULID(),
+		Code:        code,
+		Secret:      kueryRand.RandMixedString(16, true, true),
+		CtxInfo:     appCtxInfo,
+		RedirectURL: redirectURL,
+	}
+	encrypted, err := aes.Encrypt(app.Secret, s.cfg.AES.Secret, app.CtxInfo)
+	if err != nil {
+		s.t.Fatalf("testkit: encrypt app secret: %v", err)
+	}
+	s.exec(`
+		INSERT INTO app_services (id, app_code, app_name, app_secret, redirect_url, ctx_info, status, created_by, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, app.ID, code, code, encrypted, redirectURL, app.CtxInfo, statusActive, fixtureActor, fixtureActor)
+	return app
+}
+
+// CreateRole adds a role to the app appID and grants it perms, each a
+// "resource:action" code; permissions missing from the app's catalog are
+// created. It returns the role id.
+func (s *Server) CreateRole(appID, code string, perms ...string) string {
+	s.t.Helper()
+	roleID := cryp.ULID()
+	s.exec(`
+		INSERT INTO roles (id, app_id, code, name, description, is_system, created_by, updated_by)
+		VALUES (?, ?, ?, ?, '', 0, ?, ?)
+	`, roleID, appID, code, code, fixtureActor, fixtureActor)
+	s.Grant(roleID, appID, perms...)
+	return roleID
+}
+
+// Grant adds perms to the role roleID of the app appID.
+func (s *Server) Grant(roleID, appID string, perms ...string) {
+	s.t.Helper()
+	for _, perm := range perms {
+		resource, action, ok := strings.Cut(perm, ":")
+		if !ok {
+			s.t.Fatalf("testkit: permission %q is not resource:action", perm)
+		}
+		s.exec(`INSERT OR IGNORE INTO permissions (app_id, resource, action) VALUES (?, ?, ?)`, appID, resource, action)
+
+		var permID int64
+		err := s.db.QueryRowContext(context.Background(),
+			`SELECT id FROM permissions WHERE app_id = ? AND resource = ? AND action = ?`,
+			appID, resource, action).Scan(&permID)
+		if err != nil {
+			s.t.Fatalf("testkit: look up permission %s: %v", perm, err)
+		}
+		s.exec(`INSERT OR IGNORE INTO role_permissions (role_id, permission_id) VALUES (?, ?)`, roleID, permID)
+	}
+}
+
+// AssignRole gives the user the role roleID within the app appID; the
+// permissions show up in the tokens of the user's next login.
+func (s *Server) AssignRole(userID, roleID, appID string) {
+	s.t.Helper()
+	s.exec(`
+		INSERT INTO user_roles (id, user_id, role_id, app_service_id, created_by)
+		VALUES (?, ?, ?, ?, ?)
+	`, cryp.ULID(), userID, roleID, appID, fixtureActor)
+}
+
+func (s *Server) exec(query string, args ...any) {
+	s.t.Helper()
+	if _, err := s.db.ExecContext(context.Background(), query, args...); err != nil {
+		s.t.Fatalf("testkit: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	authModels "github.com/vukyn/isme/external/auth/models"
+	"github.com/vukyn/isme/external/auth/services"
+	"github.com/vukyn/isme/external/auth/verifier"
+)
+
+func TestSSOHandshake(t *testing.T) {
+	kit := New(t)
+	user := kit.CreateUser("ada@example.com", "correct horse")
+	app := kit.CreateApp("billing", "http://billing.test/callback")
+	role := kit.CreateRole(app.ID, "viewer", "invoice:read", "invoice:export")
+	kit.AssignRole(user.ID, role, app.ID)
+	ctx := context.Background()
+
+	sdk := services.NewService(kit.Endpoint)
+	login, err := sdk.RequestLogin(ctx, &authModels.RequestLoginRequest{AppCode: app.Code, AppSecret: app.Secret, CtxInfo: app.CtxInfo})
+	if err != nil {
+		t.Fatalf("RequestLogin: %v", err)
+	}
+	code := kit.Authorize(login.Data.RedirectURL, user)
+	exchange, err := sdk.ExchangeCode(ctx, &authModels.ExchangeCodeRequest{AuthorizationCode: code})
+	if err != nil {
+		t.Fatalf("ExchangeCode: %v", err)
+	}
+
+	principal, err := verifier.New(verifier.Config{Endpoint: kit.Endpoint, AppCode: app.Code}).Verify(ctx, exchange.Data.AccessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if principal.UserID != user.ID || !principal.HasPermission("invoice:read") || !principal.HasPermission("invoice:export") {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	// a real session backs the exchanged token
+	me, err := sdk.GetMe(ctx, &authModels.GetMeRequest{AccessToken: exchange.Data.AccessToken})
+	if err != nil || me.Data.Email != user.Email {
+		t.Fatalf("GetMe = %+v, %v", me, err)
+	}
+
+	if _, err := sdk.ExchangeCode(ctx, &authModels.ExchangeCodeRequest{AuthorizationCode: code}); err == nil {
+		t.Error("authorization code exchanged twice")
+	}
+}
+
+func TestMintToken(t *testing.T) {
+	kit := New(t)
+	user := kit.CreateUser("grace@example.com", "password")
+	ctx := context.Background()
+
+	token := kit.MintToken(user, "billing", "invoice:read")
+	principal, err := verifier.New(verifier.Config{Endpoint: kit.Endpoint, AppCode: "billing"}).Verify(ctx, token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if principal.Email != user.Email || !slices.Equal(principal.Permissions, []string{"invoice:read"}) {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	_, err = verifier.New(verifier.Config{Endpoint: kit.Endpoint, AppCode: "payroll"}).Verify(ctx, token)
+	if !errors.Is(err, verifier.ErrAudienceMismatch) {
+		t.Errorf("other app: err = %v, want ErrAudienceMismatch", err)
+	}
+}
+
+func TestSyncPermissions(t *testing.T) {
+	kit := New(t)
+	app := kit.CreateApp("billing", "http://billing.test/callback")
+	kit.CreateRole(app.ID, "viewer", "invoice:read", "invoice:export")
+	ctx := context.Background()
+
+	sdk := services.NewService(kit.Endpoint)
+	sync, err := sdk.SyncPermissions(ctx, &authModels.SyncPermissionsRequest{
+		AppCode:   app.Code,
+		AppSecret: app.Secret,
+		CtxInfo:   app.CtxInfo,
+		Permissions: []authModels.PermissionPair{
+			{Resource: "invoice", Action: "read"},
+			{Resource: "invoice", Action: "pay"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SyncPermissions: %v", err)
+	}
+	if !slices.Equal(sync.Data.Created, []string{"invoice:pay"}) || !slices.Equal(sync.Data.Flagged, []string{"invoice:export"}) {
+		t.Errorf("sync = %+v", sync.Data)
+	}
+
+	_, err = sdk.SyncPermissions(ctx, &authModels.SyncPermissionsRequest{AppCode: app.Code, AppSecret: "wrong", CtxInfo: app.CtxInfo})
+	if err == nil {
+		t.Error("sync accepted a wrong app secret")
+	}
+}
@@ -0,0 +1,77 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/vukyn/isme/internal/constants"
+
+	pkgClaims "github.com/vukyn/kuery/claims"
+	"github.com/vukyn/kuery/jwt"
+)
+
+// Authorize plays the browser's part of the SSO handshake: given the
+// redirect_url RequestLogin returned, it signs user in on that login session
+// and returns the authorization code isme hands the app's callback, ready for
+// ExchangeCode.
+func (s *Server) Authorize(loginURL string, user User) string {
+	s.t.Helper()
+	parsed, err := url.Parse(loginURL)
+	if err != nil {
+		s.t.Fatalf("testkit: login url %q: %v", loginURL, err)
+	}
+	sessionID := parsed.Query().Get("session_id")
+	if sessionID == "" {
+		s.t.Fatalf("testkit: login url %q has no session_id", loginURL)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"email":      user.Email,
+		"password":   user.Password,
+		"session_id": sessionID,
+	})
+	if err != nil {
+		s.t.Fatalf("testkit: %v", err)
+	}
+	resp, err := http.Post(s.Endpoint+"/"+constants.AUTH_GROUP_NAME+constants.AUTH_ENDPOINT_LOGIN, "application/json", bytes.NewReader(body))
+	if err != nil {
+		s.t.Fatalf("testkit: login: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Message string `json:"message"`
+		Data    struct {
+			AuthorizationCode string `json:"authorization_code"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		s.t.Fatalf("testkit: login: %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || envelope.Data.AuthorizationCode == "" {
+		s.t.Fatalf("testkit: login as %s: %d %s", user.Email, resp.StatusCode, envelope.Message)
+	}
+	return envelope.Data.AuthorizationCode
+}
+
+// MintToken signs an access token for user as isme would after an SSO login to
+// appCode, granting exactly perms ("resource:action"), without touching the
+// user's roles. It verifies against the JWKS endpoint, which is all a
+// downstream service checks; no session backs it, so isme's own
+// session-checked endpoints (GetMe, refresh) reject it.
+func (s *Server) MintToken(user User, appCode string, perms ...string) string {
+	s.t.Helper()
+	if perms == nil {
+		perms = []string{}
+	}
+	claims := pkgClaims.NewClaims(user.ID, user.Email, int64(s.cfg.Auth.AccessTokenExpireIn)).
+		WithResourceAccess(map[string][]string{appCode: perms}).
+		WithAudience([]string{appCode})
+	token, err := jwt.GenerateJWTWithRSAPrivateKeyFromClaims(s.cfg.Auth.AccessTokenPrivateKey, claims)
+	if err != nil {
+		s.t.Fatalf("testkit: mint token: %v", err)
+	}
+	return token
+}
@@ -18,11 +18,13 @@ const Welcome = lazy(() => import("./pages/Welcome").then((m) => ({ default: m.W
 const Users = lazy(() => import("./pages/Users").then((m) => ({ default: m.Users })));
 const InviteUser = lazy(() => import("./pages/InviteUser").then((m) => ({ default: m.InviteUser })));
 const Roles = lazy(() => import("./pages/Roles").then((m) => ({ default: m.Roles })));
+const Groups = lazy(() => import("./pages/Groups").then((m) => ({ default: m.Groups })));
 const AppServices = lazy(() => import("./pages/AppServices").then((m) => ({ default: m.AppServices })));
 const EditAppService = lazy(() => import("./pages/EditAppService").then((m) => ({ default: m.EditAppService })));
 const Settings = lazy(() => import("./pages/Settings").then((m) => ({ default: m.Settings })));
 const Profile = lazy(() => import("./pages/Profile").then((m) => ({ default: m.Profile })));
 const Sessions = lazy(() => import("./pages/Sessions").then((m) => ({ default: m.Sessions })));
+const AccessRequests = lazy(() => import("./pages/AccessRequests").then((m) => ({ default: m.AccessRequests })));
 const Activity = lazy(() => import("./pages/Activity").then((m) => ({ default: m.Activity })));
 const NotFound = lazy(() => import("./pages/NotFound").then((m) => ({ default: m.NotFound })));
 
@@ -108,6 +110,14 @@ function App() {
 								</ProtectedRoute>
 							}
 						/>
+						<Route
+							path="/groups"
+							element={
+								<ProtectedRoute requiredPermission="role:read">
+									<Groups />
+								</ProtectedRoute>
+							}
+						/>
 						<Route
 							path="/sessions"
 							element={
@@ -116,6 +126,14 @@ function App() {
 								</ProtectedRoute>
 							}
 						/>
+						<Route
+							path="/access-requests"
+							element={
+								<ProtectedRoute>
+									<AccessRequests />
+								</ProtectedRoute>
+							}
+						/>
 						<Route
 							path="/activity"
 							element={
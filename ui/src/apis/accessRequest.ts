@@ -0,0 +1,52 @@
+import type { AccessRequestItem, AccessRequestStatus, CreateAccessRequestRequest, RequestableRole } from "@/types";
+import { API_ENDPOINTS } from "@/consts";
+import { apiClient } from "@/utils/axios";
+
+/** kuery http/base.Response envelope — every endpoint wraps its payload in `data`. */
+interface Envelope<T> {
+	code: number;
+	message: string;
+	data: T;
+}
+
+/** Ask for a role for `duration_hours`; the approvers of its app decide. */
+export const createAccessRequest = async (req: CreateAccessRequestRequest): Promise<string> => {
+	const response = await apiClient.post<Envelope<{ id: string }>>(API_ENDPOINTS.ACCESS_REQUESTS, req);
+	return response.data.data.id;
+};
+
+/** The caller's own requests, newest first. Omit `status` for every status. */
+export const listMyAccessRequests = async (status?: AccessRequestStatus): Promise<AccessRequestItem[]> => {
+	const response = await apiClient.get<Envelope<{ items: AccessRequestItem[] }>>(API_ENDPOINTS.ACCESS_REQUESTS_MINE, {
+		params: { status },
+	});
+	return response.data.data?.items ?? [];
+};
+
+/** Requests for the apps the caller holds access_request:approve in; `[]` when none. */
+export const listAccessRequestQueue = async (status?: AccessRequestStatus): Promise<AccessRequestItem[]> => {
+	const response = await apiClient.get<Envelope<{ items: AccessRequestItem[] }>>(API_ENDPOINTS.ACCESS_REQUESTS_QUEUE, {
+		params: { status },
+	});
+	return response.data.data?.items ?? [];
+};
+
+/** Roles of active apps the caller does not hold yet. */
+export const listRequestableRoles = async (): Promise<RequestableRole[]> => {
+	const response = await apiClient.get<Envelope<{ items: RequestableRole[] }>>(API_ENDPOINTS.ACCESS_REQUESTS_ROLES);
+	return response.data.data?.items ?? [];
+};
+
+/** Approve a pending request — grants the role until now + duration_hours. */
+export const approveAccessRequest = async (requestId: string, note = ""): Promise<void> => {
+	await apiClient.post(API_ENDPOINTS.ACCESS_REQUEST_APPROVE(requestId), { note });
+};
+
+export const denyAccessRequest = async (requestId: string, note = ""): Promise<void> => {
+	await apiClient.post(API_ENDPOINTS.ACCESS_REQUEST_DENY(requestId), { note });
+};
+
+/** Withdraw the caller's own pending request. */
+export const cancelAccessRequest = async (requestId: string): Promise<void> => {
+	await apiClient.post(API_ENDPOINTS.ACCESS_REQUEST_CANCEL(requestId));
+};
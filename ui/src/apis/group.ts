@@ -0,0 +1,64 @@
+import type {
+	CreateGroupRequest,
+	GroupDetailResponse,
+	GroupListItem,
+	ListGroupMembersRequest,
+	ListGroupMembersResponse,
+	UpdateGroupRequest,
+} from "@/types";
+import { API_ENDPOINTS } from "@/consts";
+import { apiClient } from "@/utils/axios";
+
+/** kuery http/base.Response envelope — every endpoint wraps its payload in `data`. */
+interface Envelope<T> {
+	code: number;
+	message: string;
+	data: T;
+}
+
+export const listGroups = async (): Promise<GroupListItem[]> => {
+	const response = await apiClient.get<Envelope<GroupListItem[]>>(API_ENDPOINTS.GROUPS);
+	return response.data.data ?? [];
+};
+
+export const getGroup = async (groupId: string): Promise<GroupDetailResponse> => {
+	const response = await apiClient.get<Envelope<GroupDetailResponse>>(API_ENDPOINTS.GROUP_DETAIL(groupId));
+	return response.data.data;
+};
+
+export const createGroup = async (request: CreateGroupRequest): Promise<string> => {
+	const response = await apiClient.post<Envelope<{ id: string }>>(API_ENDPOINTS.GROUPS, request);
+	return response.data.data.id;
+};
+
+export const updateGroup = async (groupId: string, request: UpdateGroupRequest): Promise<void> => {
+	await apiClient.put(API_ENDPOINTS.GROUP_DETAIL(groupId), request);
+};
+
+/** Members stop inheriting the group's roles at their next token refresh. */
+export const deleteGroup = async (groupId: string): Promise<void> => {
+	await apiClient.delete(API_ENDPOINTS.GROUP_DETAIL(groupId));
+};
+
+/** Replace the roles the group grants; roles of several apps may be mixed. */
+export const setGroupRoles = async (groupId: string, roleIds: string[]): Promise<void> => {
+	await apiClient.put(API_ENDPOINTS.GROUP_ROLES(groupId), { role_ids: roleIds });
+};
+
+export const listGroupMembers = async (
+	groupId: string,
+	request: ListGroupMembersRequest
+): Promise<ListGroupMembersResponse> => {
+	const response = await apiClient.get<Envelope<ListGroupMembersResponse>>(API_ENDPOINTS.GROUP_MEMBERS(groupId), {
+		params: request,
+	});
+	return response.data.data;
+};
+
+export const addGroupMembers = async (groupId: string, userIds: string[]): Promise<void> => {
+	await apiClient.post(API_ENDPOINTS.GROUP_MEMBERS(groupId), { user_ids: userIds });
+};
+
+export const removeGroupMember = async (groupId: string, userId: string): Promise<void> => {
+	await apiClient.delete(API_ENDPOINTS.GROUP_MEMBER_DETAIL(groupId, userId));
+};
@@ -6,3 +6,5 @@ export * from "./appService";
 export * from "./settings";
 export * from "./session";
 export * from "./activity";
+export * from "./accessRequest";
+export * from "./group";
@@ -62,6 +62,11 @@ export const setRolePermissions = async (roleId: string, permissionIds: number[]
 	await apiClient.put(API_ENDPOINTS.ROLE_PERMISSIONS(roleId), { permission_ids: permissionIds });
 };
 
+/** Replaces the roles a role includes (same app only; cycles are rejected). */
+export const setRoleIncludes = async (roleId: string, roleIds: string[]): Promise<void> => {
+	await apiClient.put(API_ENDPOINTS.ROLE_INCLUDES(roleId), { role_ids: roleIds });
+};
+
 /** Creates resource:action permissions in an app's catalog (rejected for the
  *  isme system app). Returns the created/resulting permission items with ids. */
 export const createPermissions = async (appId: string, permissions: PermissionPair[]): Promise<PermissionItem[]> => {
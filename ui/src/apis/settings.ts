@@ -107,6 +107,11 @@ interface DatabaseBackupConfigDTO {
 	last_run_at: number | null;
 	last_backup_path: string | null;
 	last_kept_count: number | null;
+	last_remote_uploaded: boolean | null;
+	last_remote_key: string | null;
+	last_remote_error: string | null;
+	last_encrypted: boolean | null;
+	last_key_id: string | null;
 }
 
 export const getDatabaseBackupConfig = async (): Promise<DatabaseBackupConfig> => {
@@ -119,6 +124,9 @@ export const getDatabaseBackupConfig = async (): Promise<DatabaseBackupConfig> =
 		lastRunAt: dto.last_run_at ?? null,
 		lastBackupPath: dto.last_backup_path ?? null,
 		lastKeptCount: dto.last_kept_count ?? null,
+		lastRemoteUploaded: dto.last_remote_uploaded ?? null,
+		lastRemoteError: dto.last_remote_error || null,
+		lastEncrypted: dto.last_encrypted ?? null,
 	};
 };
 
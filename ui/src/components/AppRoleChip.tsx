@@ -67,10 +67,12 @@ interface AppRoleChipProps {
 	role: string;
 	/** Optional permission count suffix (e.g. "· 9 perms"). */
 	permCount?: number;
+	/** Groups the role is inherited through — renders a "via …" suffix. */
+	groups?: string[];
 	title?: string;
 }
 
-export const AppRoleChip = ({ appCode, role, permCount, title }: AppRoleChipProps) => {
+export const AppRoleChip = ({ appCode, role, permCount, groups, title }: AppRoleChipProps) => {
 	const meta = appMeta(appCode);
 	return (
 		<HStack
@@ -98,6 +100,11 @@ export const AppRoleChip = ({ appCode, role, permCount, title }: AppRoleChipProp
 					· {permCount} perm{permCount === 1 ? "" : "s"}
 				</Text>
 			)}
+			{groups && groups.length > 0 && (
+				<Text as="span" color="fg.muted" fontWeight="medium">
+					· via {groups.length === 1 ? groups[0] : `${groups.length} groups`}
+				</Text>
+			)}
 		</HStack>
 	);
 };
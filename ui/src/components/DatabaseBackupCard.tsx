@@ -120,6 +120,9 @@ export const DatabaseBackupCard = () => {
 	const [lastRunAt, setLastRunAt] = useState<number | null>(null);
 	const [lastBackupPath, setLastBackupPath] = useState<string | null>(null);
 	const [lastKeptCount, setLastKeptCount] = useState<number | null>(null);
+	const [lastRemoteUploaded, setLastRemoteUploaded] = useState<boolean | null>(null);
+	const [lastRemoteError, setLastRemoteError] = useState<string | null>(null);
+	const [lastEncrypted, setLastEncrypted] = useState<boolean | null>(null);
 
 	// editable state
 	const [enabled, setEnabled] = useState(false);
@@ -153,6 +156,9 @@ export const DatabaseBackupCard = () => {
 				setLastRunAt(config.lastRunAt);
 				setLastBackupPath(config.lastBackupPath);
 				setLastKeptCount(config.lastKeptCount);
+				setLastRemoteUploaded(config.lastRemoteUploaded);
+				setLastRemoteError(config.lastRemoteError);
+				setLastEncrypted(config.lastEncrypted);
 				hydrateFromCron(config.cron);
 			} catch {
 				if (active) toaster.create({ title: "Failed to load settings", type: "error", meta: { closable: true } });
@@ -578,6 +584,27 @@ export const DatabaseBackupCard = () => {
 							<Text as="span" color="success" fontWeight="semibold">
 								kept {(lastKeptCount ?? 0).toLocaleString()} backup{lastKeptCount === 1 ? "" : "s"}
 							</Text>
+							{lastEncrypted !== null ? (
+								<>
+									{" "}·{" "}
+									<Text as="span" color={lastEncrypted ? "success" : "aurora.amber"} fontWeight="semibold">
+										{lastEncrypted ? "encrypted" : "not encrypted"}
+									</Text>
+								</>
+							) : null}
+							{lastRemoteUploaded !== null ? (
+								<>
+									{" "}·{" "}
+									<Text
+										as="span"
+										color={lastRemoteUploaded && !lastRemoteError ? "success" : "aurora.amber"}
+										fontWeight="semibold"
+										title={lastRemoteError ?? undefined}
+									>
+										{lastRemoteUploaded ? "copied off-host" : "off-host copy failed"}
+									</Text>
+								</>
+							) : null}
 						</Text>
 					) : (
 						<Text>Never run — the first backup happens on the next scheduled time.</Text>
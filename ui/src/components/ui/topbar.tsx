@@ -5,7 +5,7 @@ import { usePermissions } from "@/hooks/usePermissions";
 import { BrandMark } from "./brand-mark";
 import { UserChip } from "./user-chip";
 
-export type TopbarTab = "overview" | "users" | "roles" | "appServices" | "sessions" | "activity" | "settings";
+export type TopbarTab = "overview" | "users" | "roles" | "groups" | "appServices" | "sessions" | "access" | "activity" | "settings";
 
 interface TopbarProps {
 	active: TopbarTab;
@@ -18,8 +18,10 @@ const NAV: { key: TopbarTab; label: string; to: string; perm?: string }[] = [
 	{ key: "overview", label: "Overview", to: "/welcome" },
 	{ key: "users", label: "Users", to: "/users", perm: "user:read" },
 	{ key: "roles", label: "Roles & Permissions", to: "/roles", perm: "role:read" },
+	{ key: "groups", label: "Groups", to: "/groups", perm: "role:read" },
 	{ key: "appServices", label: "App Services", to: "/app-services", perm: "app_service:read" },
 	{ key: "sessions", label: "Sessions", to: "/sessions" },
+	{ key: "access", label: "Access", to: "/access-requests" },
 	// "activity" is intentionally NOT a nav entry — the Activity page is reached
 	// only from the Welcome "Recent activity → View all" link. The TopbarTab union
 	// keeps "activity" so the page can pass a valid (un-highlighted) active value.
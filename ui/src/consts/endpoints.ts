@@ -49,12 +49,29 @@ export const API_ENDPOINTS = {
 	ROLES: "/api/v1/roles",
 	ROLE_DETAIL: (roleId: string) => `/api/v1/roles/${roleId}`,
 	ROLE_PERMISSIONS: (roleId: string) => `/api/v1/roles/${roleId}/permissions`,
+	ROLE_INCLUDES: (roleId: string) => `/api/v1/roles/${roleId}/includes`,
 	ROLE_MEMBERS: (roleId: string) => `/api/v1/roles/${roleId}/members`,
 	ROLE_MEMBER_DETAIL: (roleId: string, userId: string) => `/api/v1/roles/${roleId}/members/${userId}`,
 	PERMISSIONS: "/api/v1/permissions",
 	PERMISSION_APPEARANCE: "/api/v1/permissions/appearance",
 	PERMISSION_DETAIL: (permissionId: number) => `/api/v1/permissions/${permissionId}`,
 
+	// Access requests — self-service role requests and the approver queue.
+	ACCESS_REQUESTS: "/api/v1/access-requests",
+	ACCESS_REQUESTS_MINE: "/api/v1/access-requests/mine",
+	ACCESS_REQUESTS_QUEUE: "/api/v1/access-requests/queue",
+	ACCESS_REQUESTS_ROLES: "/api/v1/access-requests/roles",
+	ACCESS_REQUEST_APPROVE: (requestId: string) => `/api/v1/access-requests/${requestId}/approve`,
+	ACCESS_REQUEST_DENY: (requestId: string) => `/api/v1/access-requests/${requestId}/deny`,
+	ACCESS_REQUEST_CANCEL: (requestId: string) => `/api/v1/access-requests/${requestId}/cancel`,
+
+	// Groups — members inherit every role of the groups they belong to.
+	GROUPS: "/api/v1/groups",
+	GROUP_DETAIL: (groupId: string) => `/api/v1/groups/${groupId}`,
+	GROUP_ROLES: (groupId: string) => `/api/v1/groups/${groupId}/roles`,
+	GROUP_MEMBERS: (groupId: string) => `/api/v1/groups/${groupId}/members`,
+	GROUP_MEMBER_DETAIL: (groupId: string, userId: string) => `/api/v1/groups/${groupId}/members/${userId}`,
+
 	// Settings
 	SETTINGS_SESSION_REVOKE: "/api/v1/settings/session-revoke",
 	SETTINGS_ROTATION_CLEANUP: "/api/v1/settings/rotation-cleanup",
@@ -9,6 +9,8 @@ interface UsePermissionsReturn {
 	 * own resources (users, roles, app_services).
 	 */
 	can: (permission: string, appCode?: string) => boolean;
+	/** True when the current user holds the permission in at least one app. */
+	canAny: (permission: string) => boolean;
 }
 
 /**
@@ -31,5 +33,11 @@ export const usePermissions = (): UsePermissionsReturn => {
 		[claims]
 	);
 
-	return { can };
+	const canAny = useCallback(
+		(permission: string) =>
+			Object.values(claims?.resource_access ?? {}).some((access) => (access?.perms ?? []).includes(permission)),
+		[claims]
+	);
+
+	return { can, canAny };
 };
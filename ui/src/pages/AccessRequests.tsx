@@ -0,0 +1,499 @@
+"use client";
+
+import { useCallback, useEffect, useMemo, useState } from "react";
+import { Box, Center, Flex, HStack, Heading, Input, NativeSelect, Spinner, Stack, Text } from "@chakra-ui/react";
+import { LuCheck, LuClock, LuInbox, LuKeyRound, LuSend, LuX } from "react-icons/lu";
+import { GlassCard } from "@/components/ui/glass-card";
+import { Button } from "@/components/ui/button";
+import { AppShell } from "@/layouts/AppShell";
+import { toaster } from "@/components/ui/toaster";
+import { AURORA_CTA_STYLE } from "@/consts/styles";
+import { useUser } from "@/hooks/useUser";
+import { usePermissions } from "@/hooks/usePermissions";
+import {
+	approveAccessRequest,
+	cancelAccessRequest,
+	createAccessRequest,
+	denyAccessRequest,
+	listAccessRequestQueue,
+	listMyAccessRequests,
+	listRequestableRoles,
+} from "@/apis";
+import type { AccessRequestItem, AccessRequestStatus, RequestableRole } from "@/types";
+import { ACCESS_REQUEST_STATUS_LABELS } from "@/types";
+import { formatRelativeTime, isZeroTime } from "@/utils/time";
+
+/**
+ * Just-in-time access: request a role for a bounded time, follow your own
+ * requests, and — for users holding access_request:approve in some app — work
+ * the approval queue. Approval grants a time-bound membership that the server
+ * expires on its own; the queue is scoped server-side to the apps you approve in.
+ */
+
+const APPROVE_PERMISSION = "access_request:approve";
+
+// Server bounds: MinDurationHours = 1, MaxDurationHours = 720 (30 days).
+const DURATION_OPTIONS = [
+	{ hours: 1, label: "1 hour" },
+	{ hours: 4, label: "4 hours" },
+	{ hours: 8, label: "8 hours" },
+	{ hours: 24, label: "1 day" },
+	{ hours: 72, label: "3 days" },
+	{ hours: 168, label: "7 days" },
+	{ hours: 720, label: "30 days" },
+];
+
+const MAX_JUSTIFICATION_LENGTH = 500;
+
+const FIELD_PROPS = {
+	h: "11",
+	borderRadius: "glassSm",
+	bg: "bg.glass",
+	borderColor: "border.strong",
+	fontSize: "sm",
+	color: "fg",
+	css: {
+		backdropFilter: "blur(12px)",
+		WebkitBackdropFilter: "blur(12px)",
+		"& option": { background: "#12122E", color: "#F4F5FF" },
+	},
+	_placeholder: { color: "fg.muted" },
+	_hover: { borderColor: "rgba(255,255,255,0.28)" },
+	_focus: { borderColor: "aurora.violet", boxShadow: "focusRing", outline: "none" },
+} as const;
+
+const STATUS_STYLE: Record<AccessRequestStatus, { color: string; borderColor: string; bg: string }> = {
+	1: { color: "aurora.amber", borderColor: "rgba(245,158,11,0.35)", bg: "rgba(245,158,11,0.10)" },
+	2: { color: "aurora.mint", borderColor: "rgba(52,211,153,0.30)", bg: "rgba(52,211,153,0.08)" },
+	3: { color: "aurora.magenta", borderColor: "rgba(236,72,153,0.35)", bg: "rgba(236,72,153,0.08)" },
+	4: { color: "fg.subtle", borderColor: "border.strong", bg: "bg.glass" },
+};
+
+const errorMessage = (error: unknown, fallback: string): string => {
+	const err = error as { response?: { data?: { message?: string } }; message?: string };
+	return err?.response?.data?.message || err?.message || fallback;
+};
+
+const durationLabel = (hours: number) =>
+	DURATION_OPTIONS.find((option) => option.hours === hours)?.label ?? `${hours} hour${hours > 1 ? "s" : ""}`;
+
+const StatusPill = ({ status }: { status: AccessRequestStatus }) => {
+	const style = STATUS_STYLE[status];
+	return (
+		<Text
+			as="span"
+			px="2.5"
+			py="1"
+			borderRadius="full"
+			borderWidth="1px"
+			fontSize="11px"
+			fontWeight="semibold"
+			whiteSpace="nowrap"
+			color={style.color}
+			borderColor={style.borderColor}
+			bg={style.bg}
+		>
+			{ACCESS_REQUEST_STATUS_LABELS[status]}
+		</Text>
+	);
+};
+
+const SectionHead = ({ title, sub }: { title: string; sub: string }) => (
+	<Box>
+		<Heading as="h2" fontSize="17px" fontWeight="semibold" color="fg">
+			{title}
+		</Heading>
+		<Text fontSize="13px" color="fg.muted" mt="0.5">
+			{sub}
+		</Text>
+	</Box>
+);
+
+const EmptyRow = ({ children }: { children: React.ReactNode }) => (
+	<HStack gap="2.5" px="5" py="6" color="fg.muted" fontSize="13px">
+		<LuInbox size={16} />
+		<Text>{children}</Text>
+	</HStack>
+);
+
+/** One request row; `actions` renders on the right (approve/deny or cancel). */
+const RequestRow = ({
+	item,
+	showRequester,
+	actions,
+}: {
+	item: AccessRequestItem;
+	showRequester: boolean;
+	actions?: React.ReactNode;
+}) => (
+	<Flex gap="4" px="5" py="4" align="flex-start" justify="space-between" wrap="wrap">
+		<Box minW="0" flex="1">
+			<HStack gap="2" wrap="wrap">
+				<Text fontSize="sm" fontWeight="semibold" color="fg">
+					{item.role_name || item.role_code}
+				</Text>
+				<Text fontSize="12px" color="fg.muted">
+					{item.app_name || item.app_code} · {durationLabel(item.duration_hours)}
+				</Text>
+				<StatusPill status={item.status} />
+			</HStack>
+			{showRequester && (
+				<Text fontSize="12.5px" color="fg.subtle" mt="1">
+					{item.user_name || item.user_email} {item.user_name && item.user_email ? `· ${item.user_email}` : ""}
+				</Text>
+			)}
+			<Text fontSize="13px" color="fg" mt="1.5">
+				{item.justification}
+			</Text>
+			<HStack gap="1.5" mt="1.5" fontSize="12px" color="fg.muted" wrap="wrap">
+				<LuClock size={12} />
+				<Text>requested {formatRelativeTime(item.created_at)}</Text>
+				{!isZeroTime(item.decided_at) && item.decided_by_name && (
+					<Text>
+						· {ACCESS_REQUEST_STATUS_LABELS[item.status].toLowerCase()} by {item.decided_by_name}
+					</Text>
+				)}
+				{item.status === 2 && !isZeroTime(item.expires_at) && (
+					<Text>· access until {new Date(item.expires_at).toLocaleString()}</Text>
+				)}
+			</HStack>
+			{item.decision_note && (
+				<Text fontSize="12.5px" color="fg.subtle" mt="1" fontStyle="italic">
+					“{item.decision_note}”
+				</Text>
+			)}
+		</Box>
+		{actions && <HStack gap="2">{actions}</HStack>}
+	</Flex>
+);
+
+const RequestList = ({ children }: { children: React.ReactNode }) => (
+	<GlassCard p="0">
+		<Stack
+			gap="0"
+			css={{
+				"& > * + *": {
+					borderTop: "1px solid var(--chakra-colors-border)",
+				},
+			}}
+		>
+			{children}
+		</Stack>
+	</GlassCard>
+);
+
+export const AccessRequests = () => {
+	const { user } = useUser();
+	const name = user?.name || "User";
+	const email = user?.email || "";
+	const { canAny } = usePermissions();
+	const isApprover = canAny(APPROVE_PERMISSION);
+
+	const [roles, setRoles] = useState<RequestableRole[]>([]);
+	const [mine, setMine] = useState<AccessRequestItem[]>([]);
+	const [queue, setQueue] = useState<AccessRequestItem[]>([]);
+	const [loading, setLoading] = useState(true);
+	const [busyId, setBusyId] = useState<string | null>(null);
+
+	const [roleId, setRoleId] = useState("");
+	const [durationHours, setDurationHours] = useState(8);
+	const [justification, setJustification] = useState("");
+	const [submitting, setSubmitting] = useState(false);
+	// Keyed by request id so notes typed in the queue don't leak across rows.
+	const [notes, setNotes] = useState<Record<string, string>>({});
+
+	const load = useCallback(async () => {
+		try {
+			const [requestable, own, pending] = await Promise.all([
+				listRequestableRoles(),
+				listMyAccessRequests(),
+				isApprover ? listAccessRequestQueue(1) : Promise.resolve([]),
+			]);
+			setRoles(requestable);
+			setMine(own);
+			setQueue(pending);
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, "Failed to load access requests"), type: "error", meta: { closable: true } });
+		} finally {
+			setLoading(false);
+		}
+	}, [isApprover]);
+
+	useEffect(() => {
+		void load();
+	}, [load]);
+
+	// Roles with a pending request of mine can't be requested again until decided.
+	const pendingRoleIds = useMemo(() => new Set(mine.filter((item) => item.status === 1).map((item) => item.role_id)), [mine]);
+	const options = useMemo(() => roles.filter((role) => !pendingRoleIds.has(role.role_id)), [roles, pendingRoleIds]);
+	const selected = options.find((role) => role.role_id === roleId) ?? options[0];
+
+	const canSubmit = !!selected && justification.trim().length > 0 && !submitting;
+
+	const handleSubmit = async () => {
+		if (!selected) return;
+		setSubmitting(true);
+		try {
+			await createAccessRequest({
+				app_service_id: selected.app_service_id,
+				role_id: selected.role_id,
+				justification: justification.trim(),
+				duration_hours: durationHours,
+			});
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, "Failed to submit request"), type: "error", meta: { closable: true } });
+			setSubmitting(false);
+			return;
+		}
+		toaster.create({ title: "Access requested", description: "An approver of the app will review it.", type: "success", meta: { closable: true } });
+		setJustification("");
+		setRoleId("");
+		setSubmitting(false);
+		await load();
+	};
+
+	const runAction = async (id: string, action: () => Promise<void>, done: string, failed: string) => {
+		setBusyId(id);
+		try {
+			await action();
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, failed), type: "error", meta: { closable: true } });
+			setBusyId(null);
+			return;
+		}
+		toaster.create({ title: done, type: "success", meta: { closable: true } });
+		setBusyId(null);
+		await load();
+	};
+
+	const renderForm = () => (
+		<GlassCard>
+			<Stack gap="4">
+				<SectionHead title="Request access" sub="Pick a role you don't hold, say why, and for how long. Access ends on its own." />
+				{options.length === 0 ? (
+					<Text fontSize="13px" color="fg.muted">
+						There are no roles left to request — you hold or have already requested every role of the active apps.
+					</Text>
+				) : (
+					<>
+						<Flex gap="3" wrap="wrap">
+							<Box flex="2" minW="240px">
+								<NativeSelect.Root size="sm" w="full">
+									<NativeSelect.Field
+										{...FIELD_PROPS}
+										aria-label="Role"
+										value={selected?.role_id ?? ""}
+										onChange={(event) => setRoleId(event.target.value)}
+									>
+										{options.map((role) => (
+											<option key={role.role_id} value={role.role_id}>
+												{role.app_name} · {role.role_name || role.role_code}
+											</option>
+										))}
+									</NativeSelect.Field>
+									<NativeSelect.Indicator color="fg.muted" />
+								</NativeSelect.Root>
+							</Box>
+							<Box flex="1" minW="160px">
+								<NativeSelect.Root size="sm" w="full">
+									<NativeSelect.Field
+										{...FIELD_PROPS}
+										aria-label="Duration"
+										value={String(durationHours)}
+										onChange={(event) => setDurationHours(Number(event.target.value))}
+									>
+										{DURATION_OPTIONS.map((option) => (
+											<option key={option.hours} value={option.hours}>
+												{option.label}
+											</option>
+										))}
+									</NativeSelect.Field>
+									<NativeSelect.Indicator color="fg.muted" />
+								</NativeSelect.Root>
+							</Box>
+						</Flex>
+						{selected?.description && (
+							<Text fontSize="12.5px" color="fg.subtle">
+								{selected.description}
+							</Text>
+						)}
+						<Input
+							{...FIELD_PROPS}
+							placeholder="Justification — e.g. on-call for the payments incident"
+							maxLength={MAX_JUSTIFICATION_LENGTH}
+							value={justification}
+							onChange={(event) => setJustification(event.target.value)}
+						/>
+						<Flex justify="flex-end">
+							<Button
+								h="11"
+								px="5"
+								borderRadius="glassSm"
+								fontSize="sm"
+								fontWeight="semibold"
+								color="white"
+								css={AURORA_CTA_STYLE}
+								boxShadow="ctaGlow"
+								_hover={{ boxShadow: "ctaGlowHi", backgroundPosition: "100% 100%" }}
+								_focusVisible={{ boxShadow: "focusRing" }}
+								disabled={!canSubmit}
+								loading={submitting}
+								onClick={handleSubmit}
+							>
+								<LuSend size={15} /> Submit request
+							</Button>
+						</Flex>
+					</>
+				)}
+			</Stack>
+		</GlassCard>
+	);
+
+	const renderQueue = () => (
+		<Stack gap="3">
+			<SectionHead title="Approval queue" sub="Pending requests for the apps you approve in. You can't decide your own." />
+			<RequestList>
+				{queue.length === 0 ? (
+					<EmptyRow>Nothing waiting for a decision.</EmptyRow>
+				) : (
+					queue.map((item) => {
+						const own = item.user_id === user?.id;
+						return (
+							<Box key={item.id}>
+								<RequestRow
+									item={item}
+									showRequester
+									actions={
+										!own && (
+											<>
+												<Input
+													{...FIELD_PROPS}
+													h="9"
+													w="200px"
+													placeholder="Note (optional)"
+													value={notes[item.id] ?? ""}
+													onChange={(event) => setNotes((prev) => ({ ...prev, [item.id]: event.target.value }))}
+												/>
+												<Button
+													h="9"
+													px="3.5"
+													borderRadius="10px"
+													fontSize="13px"
+													fontWeight="semibold"
+													color="white"
+													bg="rgba(52,211,153,0.22)"
+													borderWidth="1px"
+													borderColor="rgba(52,211,153,0.40)"
+													_hover={{ bg: "rgba(52,211,153,0.32)" }}
+													disabled={busyId !== null}
+													loading={busyId === item.id}
+													onClick={() =>
+														runAction(item.id, () => approveAccessRequest(item.id, notes[item.id]), "Request approved", "Failed to approve request")
+													}
+												>
+													<LuCheck size={14} /> Approve
+												</Button>
+												<Button
+													variant="outline"
+													h="9"
+													px="3.5"
+													borderRadius="10px"
+													fontSize="13px"
+													fontWeight="semibold"
+													color="aurora.magenta"
+													borderColor="rgba(236,72,153,0.40)"
+													_hover={{ bg: "rgba(236,72,153,0.10)" }}
+													disabled={busyId !== null}
+													onClick={() =>
+														runAction(item.id, () => denyAccessRequest(item.id, notes[item.id]), "Request denied", "Failed to deny request")
+													}
+												>
+													<LuX size={14} /> Deny
+												</Button>
+											</>
+										)
+									}
+								/>
+							</Box>
+						);
+					})
+				)}
+			</RequestList>
+		</Stack>
+	);
+
+	const renderMine = () => (
+		<Stack gap="3">
+			<SectionHead title="My requests" sub="Every request you've made, newest first." />
+			<RequestList>
+				{mine.length === 0 ? (
+					<EmptyRow>You haven't requested any access yet.</EmptyRow>
+				) : (
+					mine.map((item) => (
+						<RequestRow
+							key={item.id}
+							item={item}
+							showRequester={false}
+							actions={
+								item.status === 1 && (
+									<Button
+										variant="outline"
+										h="9"
+										px="3.5"
+										borderRadius="10px"
+										fontSize="13px"
+										fontWeight="semibold"
+										color="fg"
+										borderColor="border.strong"
+										bg="bg.glass"
+										_hover={{ bg: "bg.glassHi" }}
+										disabled={busyId !== null}
+										loading={busyId === item.id}
+										onClick={() => runAction(item.id, () => cancelAccessRequest(item.id), "Request cancelled", "Failed to cancel request")}
+									>
+										Cancel
+									</Button>
+								)
+							}
+						/>
+					))
+				)}
+			</RequestList>
+		</Stack>
+	);
+
+	return (
+		<AppShell active="access" user={{ name, email }}>
+			<Flex justify="space-between" align="flex-end" gap="4" wrap="wrap">
+				<Box>
+					<HStack gap="2.5">
+						<Box color="aurora.cyan">
+							<LuKeyRound size={26} />
+						</Box>
+						<Heading as="h1" fontSize="32px" fontWeight="bold" letterSpacing="-0.025em" lineHeight="1.1">
+							Access requests
+						</Heading>
+					</HStack>
+					<Text mt="1.5" color="fg.muted" fontSize="sm">
+						Temporary, approved access to roles you don't hold
+					</Text>
+				</Box>
+			</Flex>
+
+			{loading ? (
+				<Center py="20">
+					<Stack align="center" gap="4">
+						<Spinner size="xl" color="accent" />
+						<Text color="fg.muted">Loading...</Text>
+					</Stack>
+				</Center>
+			) : (
+				<Stack gap="8">
+					{renderForm()}
+					{isApprover && renderQueue()}
+					{renderMine()}
+				</Stack>
+			)}
+		</AppShell>
+	);
+};
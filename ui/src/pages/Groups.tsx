@@ -0,0 +1,606 @@
+"use client";
+
+import { useCallback, useEffect, useMemo, useState } from "react";
+import { Box, Center, Flex, HStack, Heading, Input, Spinner, Stack, Text } from "@chakra-ui/react";
+import { LuInbox, LuPlus, LuSave, LuTrash2, LuUserMinus, LuUserPlus, LuUsers } from "react-icons/lu";
+import { GlassCard } from "@/components/ui/glass-card";
+import { Button } from "@/components/ui/button";
+import { Checkbox } from "@/components/ui/checkbox";
+import { AppRoleChip } from "@/components/AppRoleChip";
+import { AppShell } from "@/layouts/AppShell";
+import { toaster } from "@/components/ui/toaster";
+import { AURORA_CTA_STYLE } from "@/consts/styles";
+import { useUser } from "@/hooks/useUser";
+import { usePermissions } from "@/hooks/usePermissions";
+import {
+	addGroupMembers,
+	createGroup,
+	deleteGroup,
+	getGroup,
+	listGroupMembers,
+	listGroups,
+	listRoles,
+	listUsers,
+	removeGroupMember,
+	setGroupRoles,
+	updateGroup,
+} from "@/apis";
+import type { GroupDetailResponse, GroupListItem, GroupMemberItem, RoleListItem, UserListItem } from "@/types";
+import { formatRelativeTime } from "@/utils/time";
+
+/**
+ * User groups: a named set of users holding a set of roles. Members inherit
+ * every role of their groups, each in the role's own app, until they leave the
+ * group or it is deleted — the change lands at their next token refresh.
+ * Reading needs role:read; every change needs role:assign.
+ */
+
+const MEMBERS_PAGE_SIZE = 10;
+
+const FIELD_PROPS = {
+	h: "11",
+	borderRadius: "glassSm",
+	bg: "bg.glass",
+	borderColor: "border.strong",
+	fontSize: "sm",
+	color: "fg",
+	css: {
+		backdropFilter: "blur(12px)",
+		WebkitBackdropFilter: "blur(12px)",
+	},
+	_placeholder: { color: "fg.muted" },
+	_hover: { borderColor: "rgba(255,255,255,0.28)" },
+	_focus: { borderColor: "aurora.violet", boxShadow: "focusRing", outline: "none" },
+} as const;
+
+const OUTLINE_BUTTON_PROPS = {
+	variant: "outline",
+	h: "9",
+	px: "3.5",
+	borderRadius: "10px",
+	fontSize: "13px",
+	fontWeight: "semibold",
+	color: "fg",
+	borderColor: "border.strong",
+	bg: "bg.glass",
+	_hover: { bg: "bg.glassHi" },
+} as const;
+
+const errorMessage = (error: unknown, fallback: string): string => {
+	const err = error as { response?: { data?: { message?: string } }; message?: string };
+	return err?.response?.data?.message || err?.message || fallback;
+};
+
+const SectionHead = ({ title, sub }: { title: string; sub: string }) => (
+	<Box>
+		<Heading as="h2" fontSize="17px" fontWeight="semibold" color="fg">
+			{title}
+		</Heading>
+		<Text fontSize="13px" color="fg.muted" mt="0.5">
+			{sub}
+		</Text>
+	</Box>
+);
+
+const EmptyRow = ({ children }: { children: React.ReactNode }) => (
+	<HStack gap="2.5" px="5" py="6" color="fg.muted" fontSize="13px">
+		<LuInbox size={16} />
+		<Text>{children}</Text>
+	</HStack>
+);
+
+const RowList = ({ children }: { children: React.ReactNode }) => (
+	<Stack
+		gap="0"
+		css={{
+			"& > * + *": {
+				borderTop: "1px solid var(--chakra-colors-border)",
+			},
+		}}
+	>
+		{children}
+	</Stack>
+);
+
+/** Roles grouped by owning app, apps in app_code order, for the roles editor. */
+const groupRolesByApp = (roles: RoleListItem[]): [string, RoleListItem[]][] => {
+	const byApp = new Map<string, RoleListItem[]>();
+	for (const role of roles) {
+		const list = byApp.get(role.app_code) ?? [];
+		list.push(role);
+		byApp.set(role.app_code, list);
+	}
+	return [...byApp.entries()].sort(([a], [b]) => a.localeCompare(b));
+};
+
+export const Groups = () => {
+	const { user } = useUser();
+	const name = user?.name || "User";
+	const email = user?.email || "";
+	const { can } = usePermissions();
+	const canAssign = can("role:assign");
+	const canReadUsers = can("user:read");
+
+	const [groups, setGroups] = useState<GroupListItem[]>([]);
+	const [loading, setLoading] = useState(true);
+	const [selectedId, setSelectedId] = useState<string | null>(null);
+	const [detail, setDetail] = useState<GroupDetailResponse | null>(null);
+	const [busy, setBusy] = useState(false);
+
+	const [newName, setNewName] = useState("");
+	const [newDescription, setNewDescription] = useState("");
+	const [editName, setEditName] = useState("");
+	const [editDescription, setEditDescription] = useState("");
+
+	const [allRoles, setAllRoles] = useState<RoleListItem[]>([]);
+	const [draftRoleIds, setDraftRoleIds] = useState<Set<string>>(new Set());
+
+	const [members, setMembers] = useState<GroupMemberItem[]>([]);
+	const [membersTotal, setMembersTotal] = useState(0);
+	const [membersPage, setMembersPage] = useState(1);
+	const [memberSearch, setMemberSearch] = useState("");
+	const [memberResults, setMemberResults] = useState<UserListItem[]>([]);
+	const [memberSearching, setMemberSearching] = useState(false);
+
+	const loadGroups = useCallback(async () => {
+		try {
+			const items = await listGroups();
+			setGroups(items);
+			setSelectedId((current) => (current && items.some((item) => item.id === current) ? current : (items[0]?.id ?? null)));
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, "Failed to load groups"), type: "error", meta: { closable: true } });
+		} finally {
+			setLoading(false);
+		}
+	}, []);
+
+	const loadDetail = useCallback(async (groupId: string) => {
+		try {
+			const group = await getGroup(groupId);
+			setDetail(group);
+			setEditName(group.name);
+			setEditDescription(group.description);
+			setDraftRoleIds(new Set((group.roles ?? []).map((role) => role.id)));
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, "Failed to load group"), type: "error", meta: { closable: true } });
+		}
+	}, []);
+
+	const loadMembers = useCallback(async (groupId: string, page: number) => {
+		try {
+			const response = await listGroupMembers(groupId, { page, size: MEMBERS_PAGE_SIZE });
+			setMembers(response.items ?? []);
+			setMembersTotal(response.total);
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, "Failed to load members"), type: "error", meta: { closable: true } });
+		}
+	}, []);
+
+	useEffect(() => {
+		void loadGroups();
+		// The roles editor offers every role of every app; a group may mix apps.
+		listRoles()
+			.then(setAllRoles)
+			.catch(() => setAllRoles([]));
+	}, [loadGroups]);
+
+	useEffect(() => {
+		setMembersPage(1);
+		setMemberSearch("");
+		if (!selectedId) {
+			setDetail(null);
+			return;
+		}
+		void loadDetail(selectedId);
+	}, [selectedId, loadDetail]);
+
+	useEffect(() => {
+		if (!selectedId) return;
+		void loadMembers(selectedId, membersPage);
+	}, [selectedId, membersPage, loadMembers]);
+
+	// Typeahead over users (add-member). Debounced; requires user:read.
+	useEffect(() => {
+		const query = memberSearch.trim();
+		if (!query || !canAssign || !canReadUsers) {
+			setMemberResults([]);
+			return;
+		}
+		const timer = setTimeout(() => {
+			setMemberSearching(true);
+			listUsers({ page: 1, size: 8, query })
+				.then((response) => {
+					const memberIds = new Set(members.map((member) => member.user_id));
+					setMemberResults(response.items.filter((item) => !memberIds.has(item.id)));
+				})
+				.catch(() => setMemberResults([]))
+				.finally(() => setMemberSearching(false));
+		}, 300);
+		return () => clearTimeout(timer);
+	}, [memberSearch, canAssign, canReadUsers, members]);
+
+	const rolesByApp = useMemo(() => groupRolesByApp(allRoles), [allRoles]);
+	const rolesDirty = useMemo(() => {
+		const original = new Set((detail?.roles ?? []).map((role) => role.id));
+		if (original.size !== draftRoleIds.size) return true;
+		for (const id of draftRoleIds) if (!original.has(id)) return true;
+		return false;
+	}, [detail, draftRoleIds]);
+	const detailsDirty = !!detail && (editName.trim() !== detail.name || editDescription.trim() !== detail.description);
+	const membersTotalPages = Math.max(1, Math.ceil(membersTotal / MEMBERS_PAGE_SIZE));
+
+	/** Runs one mutation, toasts the outcome, then reloads what it touched. */
+	const run = async (action: () => Promise<void>, done: string, failed: string, after: () => Promise<void>) => {
+		setBusy(true);
+		try {
+			await action();
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, failed), type: "error", meta: { closable: true } });
+			setBusy(false);
+			return;
+		}
+		toaster.create({ title: done, type: "success", meta: { closable: true } });
+		setBusy(false);
+		await after();
+	};
+
+	const reloadSelected = async () => {
+		await loadGroups();
+		if (selectedId) {
+			await Promise.all([loadDetail(selectedId), loadMembers(selectedId, membersPage)]);
+		}
+	};
+
+	const handleCreate = async () => {
+		setBusy(true);
+		let id: string;
+		try {
+			id = await createGroup({ name: newName.trim(), description: newDescription.trim() });
+		} catch (error) {
+			toaster.create({ title: errorMessage(error, "Failed to create group"), type: "error", meta: { closable: true } });
+			setBusy(false);
+			return;
+		}
+		toaster.create({ title: "Group created", type: "success", meta: { closable: true } });
+		setNewName("");
+		setNewDescription("");
+		setBusy(false);
+		await loadGroups();
+		setSelectedId(id);
+	};
+
+	const handleDelete = async () => {
+		if (!detail) return;
+		if (!window.confirm(`Delete "${detail.name}"? Its ${detail.members_count} member(s) lose the roles it grants.`)) return;
+		await run(() => deleteGroup(detail.id), "Group deleted", "Failed to delete group", loadGroups);
+	};
+
+	const toggleRole = (roleId: string, checked: boolean) => {
+		setDraftRoleIds((prev) => {
+			const next = new Set(prev);
+			if (checked) next.add(roleId);
+			else next.delete(roleId);
+			return next;
+		});
+	};
+
+	const renderGroupList = () => (
+		<Stack gap="4" flex="1" minW="260px" maxW={{ base: "full", lg: "340px" }}>
+			{canAssign && (
+				<GlassCard>
+					<Stack gap="3">
+						<SectionHead title="New group" sub="Members inherit the roles you give it." />
+						<Input {...FIELD_PROPS} placeholder="Name — e.g. On-call" value={newName} onChange={(event) => setNewName(event.target.value)} />
+						<Input
+							{...FIELD_PROPS}
+							placeholder="Description (optional)"
+							value={newDescription}
+							onChange={(event) => setNewDescription(event.target.value)}
+						/>
+						<Flex justify="flex-end">
+							<Button
+								h="10"
+								px="4"
+								borderRadius="glassSm"
+								fontSize="sm"
+								fontWeight="semibold"
+								color="white"
+								css={AURORA_CTA_STYLE}
+								boxShadow="ctaGlow"
+								_hover={{ boxShadow: "ctaGlowHi", backgroundPosition: "100% 100%" }}
+								_focusVisible={{ boxShadow: "focusRing" }}
+								disabled={!newName.trim() || busy}
+								onClick={handleCreate}
+							>
+								<LuPlus size={15} /> Create group
+							</Button>
+						</Flex>
+					</Stack>
+				</GlassCard>
+			)}
+			<GlassCard p="0">
+				<RowList>
+					{groups.length === 0 ? (
+						<EmptyRow>No groups yet.</EmptyRow>
+					) : (
+						groups.map((group) => {
+							const active = group.id === selectedId;
+							return (
+								<Box
+									key={group.id}
+									as="button"
+									textAlign="left"
+									px="5"
+									py="3.5"
+									bg={active ? "bg.glassHi" : "transparent"}
+									_hover={{ bg: "bg.glassHi" }}
+									onClick={() => setSelectedId(group.id)}
+								>
+									<Text fontSize="sm" fontWeight="semibold" color="fg">
+										{group.name}
+									</Text>
+									<Text fontSize="12px" color="fg.muted" mt="0.5">
+										{group.members_count} member{group.members_count === 1 ? "" : "s"} · {group.roles_count} role
+										{group.roles_count === 1 ? "" : "s"}
+									</Text>
+								</Box>
+							);
+						})
+					)}
+				</RowList>
+			</GlassCard>
+		</Stack>
+	);
+
+	const renderDetails = (group: GroupDetailResponse) => (
+		<GlassCard>
+			<Stack gap="3">
+				<Flex justify="space-between" align="flex-start" gap="3" wrap="wrap">
+					<SectionHead title="Details" sub={`Created ${formatRelativeTime(group.created_at)}`} />
+					{canAssign && (
+						<Button
+							{...OUTLINE_BUTTON_PROPS}
+							color="aurora.magenta"
+							borderColor="rgba(236,72,153,0.40)"
+							_hover={{ bg: "rgba(236,72,153,0.10)" }}
+							disabled={busy}
+							onClick={handleDelete}
+						>
+							<LuTrash2 size={14} /> Delete
+						</Button>
+					)}
+				</Flex>
+				<Input {...FIELD_PROPS} aria-label="Name" disabled={!canAssign} value={editName} onChange={(event) => setEditName(event.target.value)} />
+				<Input
+					{...FIELD_PROPS}
+					aria-label="Description"
+					placeholder="Description"
+					disabled={!canAssign}
+					value={editDescription}
+					onChange={(event) => setEditDescription(event.target.value)}
+				/>
+				{canAssign && (
+					<Flex justify="flex-end">
+						<Button
+							{...OUTLINE_BUTTON_PROPS}
+							disabled={!detailsDirty || !editName.trim() || busy}
+							onClick={() =>
+								run(
+									() => updateGroup(group.id, { name: editName.trim(), description: editDescription.trim() }),
+									"Group updated",
+									"Failed to update group",
+									reloadSelected
+								)
+							}
+						>
+							<LuSave size={14} /> Save details
+						</Button>
+					</Flex>
+				)}
+			</Stack>
+		</GlassCard>
+	);
+
+	const renderRoles = (group: GroupDetailResponse) => (
+		<GlassCard>
+			<Stack gap="4">
+				<SectionHead title="Roles" sub="Every member holds these roles, each in its own app." />
+				{canAssign ? (
+					<>
+						{rolesByApp.length === 0 ? (
+							<Text fontSize="13px" color="fg.muted">
+								There are no roles to grant yet.
+							</Text>
+						) : (
+							rolesByApp.map(([appCode, roles]) => (
+								<Box key={appCode}>
+									<Text fontSize="12px" fontWeight="semibold" color="fg.subtle" textTransform="uppercase" letterSpacing="0.04em">
+										{appCode}
+									</Text>
+									<Flex gap="4" mt="2" wrap="wrap">
+										{roles.map((role) => (
+											<Checkbox
+												key={role.id}
+												size="sm"
+												colorPalette="purple"
+												checked={draftRoleIds.has(role.id)}
+												onCheckedChange={(details) => toggleRole(role.id, !!details.checked)}
+											>
+												<Text fontSize="sm" color="fg" title={role.description}>
+													{role.name || role.code}
+												</Text>
+											</Checkbox>
+										))}
+									</Flex>
+								</Box>
+							))
+						)}
+						<Flex justify="flex-end">
+							<Button
+								{...OUTLINE_BUTTON_PROPS}
+								disabled={!rolesDirty || busy}
+								onClick={() => run(() => setGroupRoles(group.id, [...draftRoleIds]), "Roles saved", "Failed to save roles", reloadSelected)}
+							>
+								<LuSave size={14} /> Save roles
+							</Button>
+						</Flex>
+					</>
+				) : (group.roles ?? []).length === 0 ? (
+					<Text fontSize="13px" color="fg.muted">
+						This group grants no roles.
+					</Text>
+				) : (
+					<HStack gap="1.5" wrap="wrap">
+						{group.roles.map((role) => (
+							<AppRoleChip key={role.id} appCode={role.app_code} role={role.code} title={`${role.app_name} · ${role.name}`} />
+						))}
+					</HStack>
+				)}
+			</Stack>
+		</GlassCard>
+	);
+
+	const renderMembers = (group: GroupDetailResponse) => (
+		<GlassCard p="0">
+			<Stack gap="4" px="5" pt="5">
+				<SectionHead title="Members" sub={`${membersTotal} member${membersTotal === 1 ? "" : "s"}`} />
+				{canAssign && canReadUsers && (
+					<Box pb="1">
+						<Input
+							{...FIELD_PROPS}
+							placeholder="Add a member — search by name or email"
+							value={memberSearch}
+							onChange={(event) => setMemberSearch(event.target.value)}
+						/>
+						{memberSearching && (
+							<Text fontSize="12px" color="fg.muted" mt="2">
+								Searching…
+							</Text>
+						)}
+						{memberResults.map((result) => (
+							<Flex key={result.id} justify="space-between" align="center" gap="3" py="2">
+								<Text fontSize="13px" color="fg">
+									{result.name} <Text as="span" color="fg.muted">· {result.email}</Text>
+								</Text>
+								<Button
+									{...OUTLINE_BUTTON_PROPS}
+									h="8"
+									disabled={busy}
+									onClick={() =>
+										run(
+											() => addGroupMembers(group.id, [result.id]),
+											"Member added",
+											"Failed to add member",
+											async () => {
+												setMemberSearch("");
+												await reloadSelected();
+											}
+										)
+									}
+								>
+									<LuUserPlus size={14} /> Add
+								</Button>
+							</Flex>
+						))}
+					</Box>
+				)}
+			</Stack>
+			<Box borderTop="1px solid var(--chakra-colors-border)" mt="3">
+				<RowList>
+					{members.length === 0 ? (
+						<EmptyRow>No members yet.</EmptyRow>
+					) : (
+						members.map((member) => (
+							<Flex key={member.user_id} px="5" py="3" justify="space-between" align="center" gap="3">
+								<Box minW="0">
+									<Text fontSize="sm" fontWeight="semibold" color="fg">
+										{member.name}
+									</Text>
+									<Text fontSize="12px" color="fg.muted">
+										{member.email} · added {formatRelativeTime(member.created_at)}
+									</Text>
+								</Box>
+								{canAssign && (
+									<Button
+										{...OUTLINE_BUTTON_PROPS}
+										h="8"
+										disabled={busy}
+										onClick={() =>
+											run(() => removeGroupMember(group.id, member.user_id), "Member removed", "Failed to remove member", reloadSelected)
+										}
+									>
+										<LuUserMinus size={14} /> Remove
+									</Button>
+								)}
+							</Flex>
+						))
+					)}
+				</RowList>
+			</Box>
+			{membersTotalPages > 1 && (
+				<HStack justify="flex-end" gap="2" px="5" py="3" borderTop="1px solid var(--chakra-colors-border)">
+					<Button {...OUTLINE_BUTTON_PROPS} h="8" disabled={membersPage <= 1} onClick={() => setMembersPage((page) => page - 1)}>
+						Previous
+					</Button>
+					<Text fontSize="12px" color="fg.muted">
+						{membersPage} / {membersTotalPages}
+					</Text>
+					<Button
+						{...OUTLINE_BUTTON_PROPS}
+						h="8"
+						disabled={membersPage >= membersTotalPages}
+						onClick={() => setMembersPage((page) => page + 1)}
+					>
+						Next
+					</Button>
+				</HStack>
+			)}
+		</GlassCard>
+	);
+
+	return (
+		<AppShell active="groups" user={{ name, email }}>
+			<Flex justify="space-between" align="flex-end" gap="4" wrap="wrap">
+				<Box>
+					<HStack gap="2.5">
+						<Box color="aurora.cyan">
+							<LuUsers size={26} />
+						</Box>
+						<Heading as="h1" fontSize="32px" fontWeight="bold" letterSpacing="-0.025em" lineHeight="1.1">
+							Groups
+						</Heading>
+					</HStack>
+					<Text mt="1.5" color="fg.muted" fontSize="sm">
+						Grant roles to many users at once
+					</Text>
+				</Box>
+			</Flex>
+
+			{loading ? (
+				<Center py="20">
+					<Stack align="center" gap="4">
+						<Spinner size="xl" color="accent" />
+						<Text color="fg.muted">Loading...</Text>
+					</Stack>
+				</Center>
+			) : (
+				<Flex gap="6" align="flex-start" direction={{ base: "column", lg: "row" }}>
+					{renderGroupList()}
+					<Stack gap="6" flex="2" minW="0" w="full">
+						{detail ? (
+							<>
+								{renderDetails(detail)}
+								{renderRoles(detail)}
+								{renderMembers(detail)}
+							</>
+						) : (
+							<GlassCard p="0">
+								<EmptyRow>Select a group to see its roles and members.</EmptyRow>
+							</GlassCard>
+						)}
+					</Stack>
+				</Flex>
+			)}
+		</AppShell>
+	);
+};
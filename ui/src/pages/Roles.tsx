@@ -2,7 +2,7 @@
 
 import { useCallback, useEffect, useMemo, useState } from "react";
 import type { ReactNode } from "react";
-import { Box, Button, Center, Dialog, Field, Flex, Grid, Heading, HStack, Input, Spinner, Table, Text } from "@chakra-ui/react";
+import { Box, Button, Center, Dialog, Field, Flex, Grid, Heading, HStack, Input, NativeSelect, Spinner, Table, Text } from "@chakra-ui/react";
 import {
 	LuAppWindow,
 	LuChevronLeft,
@@ -11,6 +11,7 @@ import {
 	LuFileText,
 	LuInfo,
 	LuKeyRound,
+	LuLayers,
 	LuLibrary,
 	LuLock,
 	LuPencil,
@@ -35,6 +36,7 @@ import {
 	listRoles,
 	listUsers,
 	removeRoleMember,
+	setRoleIncludes,
 	setRolePermissions,
 	updateRole,
 } from "@/apis";
@@ -59,10 +61,20 @@ import { useUser } from "@/hooks/useUser";
 import { usePermissions } from "@/hooks/usePermissions";
 import { AppShell } from "@/layouts/AppShell";
 import type { AppService, PermissionItem, PermissionPair, RoleDetailResponse, RoleListItem, RoleMemberItem, UserListItem } from "@/types";
-import { formatDateOnly } from "@/utils";
+import { formatDateOnly, formatTimeLeft } from "@/utils";
 
 const MEMBERS_PAGE_SIZE = 8;
 
+/** Membership lengths offered when adding a member; 0 = permanent. */
+const MEMBER_DURATIONS = [
+	{ label: "Permanent", hours: 0 },
+	{ label: "1 hour", hours: 1 },
+	{ label: "8 hours", hours: 8 },
+	{ label: "1 day", hours: 24 },
+	{ label: "7 days", hours: 24 * 7 },
+	{ label: "30 days", hours: 24 * 30 },
+] as const;
+
 const NO_PERMISSION_TOOLTIP = "You don't have permission";
 
 type RoleTab = "permissions" | "catalog" | "members";
@@ -351,6 +363,43 @@ const MemberAvatar = ({ id, name }: { id: string; name: string }) => (
 	</Center>
 );
 
+/**
+ * Remaining time of a time-bound membership: cyan before it starts, amber while
+ * it runs, magenta once expired (until the expiry job removes the row).
+ * Permanent, running memberships render nothing.
+ */
+const MemberWindowPill = ({ member }: { member: RoleMemberItem }) => {
+	const startsIn = formatTimeLeft(member.starts_at);
+	const expiresIn = formatTimeLeft(member.expires_at);
+	if (!startsIn && !member.expires_at) return null;
+
+	let pill = { label: `${expiresIn} left`, color: "aurora.amber", bg: "rgba(245,158,11,0.12)" };
+	if (startsIn) {
+		pill = { label: `starts in ${startsIn}`, color: "aurora.cyan", bg: "rgba(34,211,238,0.12)" };
+	} else if (!expiresIn) {
+		pill = { label: "expired", color: "aurora.magenta", bg: "rgba(236,72,153,0.12)" };
+	}
+	const tooltip = member.expires_at ? `Expires ${new Date(member.expires_at).toLocaleString()}` : "Permanent once it starts";
+
+	return (
+		<Tooltip content={tooltip} positioning={{ placement: "top" }}>
+			<Box
+				px="2"
+				py="0.5"
+				borderRadius="full"
+				fontSize="11px"
+				fontWeight="medium"
+				whiteSpace="nowrap"
+				color={pill.color}
+				bg={pill.bg}
+				css={{ fontVariantNumeric: "tabular-nums" }}
+			>
+				{pill.label}
+			</Box>
+		</Tooltip>
+	);
+};
+
 /**
  * "First run" empty state for an app whose permission catalog is empty (mock
  * .empty.first). Shown in both the matrix and catalog tabs; the CTA is hidden
@@ -439,6 +488,7 @@ export const Roles = () => {
 	const [originalPermissionIds, setOriginalPermissionIds] = useState<Set<number>>(new Set());
 	const [draftPermissionIds, setDraftPermissionIds] = useState<Set<number>>(new Set());
 	const [savingPermissions, setSavingPermissions] = useState(false);
+	const [savingIncludes, setSavingIncludes] = useState(false);
 
 	const [members, setMembers] = useState<RoleMemberItem[]>([]);
 	const [membersTotal, setMembersTotal] = useState(0);
@@ -446,6 +496,7 @@ export const Roles = () => {
 	const [membersLoading, setMembersLoading] = useState(false);
 
 	const [memberSearch, setMemberSearch] = useState("");
+	const [memberDurationHours, setMemberDurationHours] = useState(0);
 	const [memberResults, setMemberResults] = useState<UserListItem[]>([]);
 	const [memberSearching, setMemberSearching] = useState(false);
 
@@ -664,6 +715,23 @@ export const Roles = () => {
 
 	const membersTotalPages = Math.max(1, Math.ceil(membersTotal / MEMBERS_PAGE_SIZE));
 
+	// Permissions the role only inherits through its included roles → the
+	// include codes they come through (matrix tooltip).
+	const inheritedVia = useMemo(() => {
+		const map = new Map<number, string[]>();
+		for (const permission of detail?.inherited_permissions ?? []) {
+			map.set(permission.id, permission.via);
+		}
+		return map;
+	}, [detail]);
+	// Roles the selected role could include: same app, not itself, not already
+	// included. Cycles are rejected by the backend.
+	const includeCandidates = useMemo(() => {
+		if (!detail) return [];
+		const included = new Set(detail.includes.map((role) => role.id));
+		return roles.filter((role) => role.app_id === detail.app_id && role.id !== detail.id && !included.has(role.id));
+	}, [roles, detail]);
+
 	const togglePermission = (permissionId: number) => {
 		setDraftPermissionIds((previous) => {
 			const next = new Set(previous);
@@ -691,6 +759,22 @@ export const Roles = () => {
 		}
 	};
 
+	// Includes are saved immediately (no draft); the reloaded detail carries the
+	// recomputed inherited permissions.
+	const handleSetIncludes = async (roleIds: string[]) => {
+		if (!selectedRoleId) return;
+		setSavingIncludes(true);
+		try {
+			await setRoleIncludes(selectedRoleId, roleIds);
+			setDetail(await getRole(selectedRoleId));
+			toaster.create({ title: "Included roles saved", type: "success", meta: { closable: true } });
+		} catch (error: unknown) {
+			toaster.create({ title: errorMessage(error, "Failed to save included roles"), type: "error", meta: { closable: true } });
+		} finally {
+			setSavingIncludes(false);
+		}
+	};
+
 	// Sanitized live inputs (lowercase, [a-z0-9_] only — same rule the backend enforces).
 	const cleanResource = useMemo(() => sanitizeSegment(newResource), [newResource]);
 	const cleanAction = useMemo(() => sanitizeSegment(newAction), [newAction]);
@@ -856,7 +940,9 @@ export const Roles = () => {
 			// The backend scopes every membership to the role's owning app
 			// (user_roles.app_service_id = role.app_id) — required so the perm
 			// query matches; there is no global (NULL) assignment from this UI.
-			await addRoleMembers(selectedRoleId, { user_ids: [user.id] });
+			const expiresAt =
+				memberDurationHours > 0 ? new Date(Date.now() + memberDurationHours * 3600 * 1000).toISOString() : undefined;
+			await addRoleMembers(selectedRoleId, { user_ids: [user.id], expires_at: expiresAt });
 			setMemberSearch("");
 			setMemberResults([]);
 			setRoles((previous) =>
@@ -966,7 +1052,7 @@ export const Roles = () => {
 				</Text>
 			);
 		}
-		return (
+		const checkbox = (
 			<Checkbox
 				size="sm"
 				colorPalette="purple"
@@ -976,6 +1062,16 @@ export const Roles = () => {
 				onCheckedChange={() => togglePermission(permission.id)}
 			/>
 		);
+		const via = inheritedVia.get(permission.id);
+		if (!via || draftPermissionIds.has(permission.id)) return checkbox;
+		return (
+			<Tooltip content={`Inherited via ${via.join(", ")}`} positioning={{ placement: "top" }}>
+				<HStack gap="1" color="aurora.cyan">
+					{checkbox}
+					<LuLayers size={12} />
+				</HStack>
+			</Tooltip>
+		);
 	};
 
 	const tabButton = (key: RoleTab, label: string, icon: ReactNode, pill?: string) => (
@@ -1323,6 +1419,66 @@ export const Roles = () => {
 													: "System role — permissions are seeded and read-only"}
 											</HStack>
 										)}
+										{/* Included roles — the role also grants everything they grant, transitively */}
+										{!catalogEmpty && (
+											<Flex align="center" gap="2" px="4.5" py="2.5" borderBottomWidth="1px" borderColor="border" wrap="wrap" fontSize="12px">
+												<HStack gap="1.5" color="fg.muted">
+													<LuLayers size={13} /> Includes
+												</HStack>
+												{detail.includes.length === 0 && <Text color="fg.muted">no other roles</Text>}
+												{detail.includes.map((role) => (
+													<HStack key={role.id} {...PILL_BASE} color="aurora.cyan" borderColor="rgba(34,211,238,0.35)" bg="rgba(34,211,238,0.10)">
+														{role.code}
+														{matrixEditable && (
+															<Box
+																as="button"
+																aria-label={`Stop including ${role.code}`}
+																cursor="pointer"
+																color="fg.muted"
+																_hover={{ color: "fg" }}
+																onClick={() => handleSetIncludes(detail.includes.filter((included) => included.id !== role.id).map((included) => included.id))}
+															>
+																<LuX size={12} />
+															</Box>
+														)}
+													</HStack>
+												))}
+												{matrixEditable && includeCandidates.length > 0 && (
+													<NativeSelect.Root size="xs" w="40">
+														<NativeSelect.Field
+															borderRadius="full"
+															bg="bg.glass"
+															borderColor="border.strong"
+															fontSize="12px"
+															color="fg"
+															aria-label="Include a role"
+															css={{ "& option": { background: "#12122E", color: "#F4F5FF" } }}
+															_focus={{ borderColor: "aurora.violet", boxShadow: "focusRing", outline: "none" }}
+															value=""
+															disabled={savingIncludes}
+															onChange={(event) => {
+																if (event.target.value) {
+																	handleSetIncludes([...detail.includes.map((role) => role.id), event.target.value]);
+																}
+															}}
+														>
+															<option value="">+ include role…</option>
+															{includeCandidates.map((role) => (
+																<option key={role.id} value={role.id}>
+																	{role.code}
+																</option>
+															))}
+														</NativeSelect.Field>
+														<NativeSelect.Indicator color="fg.muted" />
+													</NativeSelect.Root>
+												)}
+												{detail.inherited_permissions.length > 0 && (
+													<Text ml="auto" color="fg.muted">
+														{detail.inherited_permissions.length} inherited permission{detail.inherited_permissions.length > 1 ? "s" : ""}
+													</Text>
+												)}
+											</Flex>
+										)}
 										{/* Empty catalog → "first permission" CTA instead of an empty grid (mock renderMatrix empty state) */}
 										{catalogEmpty ? (
 											<EmptyCatalogState
@@ -1701,9 +1857,29 @@ export const Roles = () => {
 																		</Text>
 																	</Table.Cell>
 																	<Table.Cell px="4.5" py="13px">
-																		<Text as="code" fontSize="13px" color="aurora.cyan" fontFamily="inherit">
-																			{resource.resource}:{action}
-																		</Text>
+																		<HStack gap="2">
+																			<Text as="code" fontSize="13px" color="aurora.cyan" fontFamily="inherit">
+																				{resource.resource}:{action}
+																			</Text>
+																			{/* The app's catalog sync no longer lists it — an admin
+																			    confirms the removal with the trash button. */}
+																			{permissionByCode.get(`${resource.resource}:${action}`)?.flagged_at && (
+																				<Box
+																					px="2"
+																					py="0.5"
+																					borderRadius="full"
+																					borderWidth="1px"
+																					borderColor="rgba(245,158,11,0.35)"
+																					bg="rgba(245,158,11,0.10)"
+																					color="aurora.amber"
+																					fontSize="11px"
+																					fontWeight="semibold"
+																					title="No longer listed by the app's catalog sync — remove to confirm"
+																				>
+																					flagged
+																				</Box>
+																			)}
+																		</HStack>
 																	</Table.Cell>
 																	{!isIsmeApp && (
 																		<Table.Cell px="4.5" py="13px" textAlign="right">
@@ -1798,18 +1974,38 @@ export const Roles = () => {
 									<>
 										{canAssign && canReadUsers && (
 											<Box p="3.5" borderBottomWidth="1px" borderColor="border" position="relative">
-												<Box position="relative" css={{ "&:focus-within .search-icon": { color: "#22D3EE" } }}>
-													<Box className="search-icon" position="absolute" left="3.5" top="3.5" color="fg.muted" pointerEvents="none">
-														<LuSearch size={16} />
+												<HStack gap="2">
+													<Box flex="1" position="relative" css={{ "&:focus-within .search-icon": { color: "#22D3EE" } }}>
+														<Box className="search-icon" position="absolute" left="3.5" top="3.5" color="fg.muted" pointerEvents="none">
+															<LuSearch size={16} />
+														</Box>
+														<Input
+															{...INPUT_PROPS}
+															pl="10"
+															placeholder="Add member — search by name or email…"
+															value={memberSearch}
+															onChange={(event) => setMemberSearch(event.target.value)}
+														/>
 													</Box>
-													<Input
-														{...INPUT_PROPS}
-														pl="10"
-														placeholder="Add member — search by name or email…"
-														value={memberSearch}
-														onChange={(event) => setMemberSearch(event.target.value)}
-													/>
-												</Box>
+													{/* Time-bound memberships expire on their own; the backend
+													    drops them from tokens and signs the holder out. */}
+													<NativeSelect.Root w="32">
+														<NativeSelect.Field
+															{...INPUT_PROPS}
+															aria-label="Membership length"
+															css={{ "& option": { background: "#12122E", color: "#F4F5FF" } }}
+															value={String(memberDurationHours)}
+															onChange={(event) => setMemberDurationHours(Number(event.target.value))}
+														>
+															{MEMBER_DURATIONS.map((duration) => (
+																<option key={duration.hours} value={duration.hours}>
+																	{duration.label}
+																</option>
+															))}
+														</NativeSelect.Field>
+														<NativeSelect.Indicator color="fg.muted" />
+													</NativeSelect.Root>
+												</HStack>
 												{(memberResults.length > 0 || memberSearching) && memberSearch.trim() && (
 													<Box
 														position="absolute"
@@ -1896,6 +2092,7 @@ export const Roles = () => {
 																{member.email}
 															</Text>
 														</Box>
+														<MemberWindowPill member={member} />
 														<Text fontSize="12px" color="fg.muted" whiteSpace="nowrap" css={{ fontVariantNumeric: "tabular-nums" }}>
 															added {formatDateOnly(member.created_at)}
 														</Text>
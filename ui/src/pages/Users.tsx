@@ -243,7 +243,8 @@ const ROLES_PREVIEW_COUNT = 3;
  * "Roles (by app)" cell. Renders one app_code:role_code chip per app-scoped role
  * the user holds (UserListItem.roles), overflowing to "+N" past
  * ROLES_PREVIEW_COUNT. Empty roles → "no roles". admin on isme = platform
- * administrator (no separate is_admin badge anymore).
+ * administrator (no separate is_admin badge anymore). Roles inherited through
+ * a group carry a "via <group>" suffix.
  */
 const RolesByAppCell = ({ roles }: { roles: AppRole[] }) => {
 	if (roles.length === 0) {
@@ -262,7 +263,12 @@ const RolesByAppCell = ({ roles }: { roles: AppRole[] }) => {
 					key={`${role.app_code}:${role.role_code}`}
 					appCode={role.app_code}
 					role={role.role_code}
-					title={`${role.app_name} · ${role.role_name}`}
+					groups={role.groups}
+					title={
+						role.groups?.length
+							? `${role.app_name} · ${role.role_name} — via ${role.groups.join(", ")}`
+							: `${role.app_name} · ${role.role_name}`
+					}
 				/>
 			))}
 			{hidden > 0 && (
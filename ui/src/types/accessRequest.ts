@@ -0,0 +1,53 @@
+/** 1 = pending, 2 = approved, 3 = denied, 4 = cancelled (access_request constants). */
+export type AccessRequestStatus = 1 | 2 | 3 | 4;
+
+export const ACCESS_REQUEST_STATUS_LABELS: Record<AccessRequestStatus, string> = {
+	1: "Pending",
+	2: "Approved",
+	3: "Denied",
+	4: "Cancelled",
+};
+
+/** Maps to internal/domains/access_request/models.AccessRequestItem. */
+export interface AccessRequestItem {
+	id: string;
+	user_id: string;
+	user_name: string;
+	user_email: string;
+	app_service_id: string;
+	app_code: string;
+	app_name: string;
+	role_id: string;
+	role_code: string;
+	role_name: string;
+	justification: string;
+	duration_hours: number;
+	status: AccessRequestStatus;
+	decided_by: string;
+	decided_by_name: string;
+	decision_note: string;
+	/** RFC3339; empty while pending. */
+	decided_at: string;
+	/** When the membership granted by an approval ends; empty unless approved. */
+	expires_at: string;
+	created_at: string;
+}
+
+/** Maps to models.CreateRequest. duration_hours is 1..720. */
+export interface CreateAccessRequestRequest {
+	app_service_id: string;
+	role_id: string;
+	justification: string;
+	duration_hours: number;
+}
+
+/** Maps to models.RequestableRole — a role of an active app the caller lacks. */
+export interface RequestableRole {
+	role_id: string;
+	role_code: string;
+	role_name: string;
+	description: string;
+	app_service_id: string;
+	app_code: string;
+	app_name: string;
+}
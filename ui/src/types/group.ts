@@ -0,0 +1,63 @@
+/** Maps to internal/domains/group/models.GroupListItem. */
+export interface GroupListItem {
+	id: string;
+	name: string;
+	description: string;
+	members_count: number;
+	roles_count: number;
+	created_at: string;
+}
+
+/** A role a group grants its members, in the role's own app (models.RoleItem). */
+export interface GroupRoleItem {
+	id: string;
+	app_id: string;
+	app_code: string;
+	app_name: string;
+	code: string;
+	name: string;
+}
+
+/** Maps to models.GroupDetailResponse (GET /api/v1/groups/:id). */
+export interface GroupDetailResponse {
+	id: string;
+	name: string;
+	description: string;
+	members_count: number;
+	roles: GroupRoleItem[];
+	created_at: string;
+}
+
+/** Maps to models.CreateRequest. Names are unique among live groups. */
+export interface CreateGroupRequest {
+	name: string;
+	description: string;
+}
+
+/** Maps to models.UpdateRequest. */
+export interface UpdateGroupRequest {
+	name: string;
+	description: string;
+}
+
+/** Maps to models.MemberItem. */
+export interface GroupMemberItem {
+	user_id: string;
+	name: string;
+	email: string;
+	created_at: string;
+}
+
+/** Maps to models.ListMembersRequest query params. */
+export interface ListGroupMembersRequest {
+	page: number;
+	size: number;
+	query?: string;
+}
+
+/** Maps to models.ListMembersResponse (GET /api/v1/groups/:id/members). */
+export interface ListGroupMembersResponse {
+	items: GroupMemberItem[];
+	total: number;
+	page: number;
+}
@@ -57,10 +57,13 @@ export type {
 	RoleListFilter,
 	PermissionItem,
 	RoleDetailResponse,
+	RoleRef,
+	InheritedPermissionItem,
 	CreateRoleRequest,
 	CreateRoleResponse,
 	UpdateRoleRequest,
 	SetRolePermissionsRequest,
+	SetRoleIncludesRequest,
 	PermissionPair,
 	CreatePermissionsRequest,
 	UpdatePermissionAppearanceRequest,
@@ -81,3 +84,20 @@ export type {
 } from "./settings";
 export type { MySessionItem, MySessionCount } from "./session";
 export type { ActivityItem } from "./activity";
+export type {
+	AccessRequestStatus,
+	AccessRequestItem,
+	CreateAccessRequestRequest,
+	RequestableRole,
+} from "./accessRequest";
+export { ACCESS_REQUEST_STATUS_LABELS } from "./accessRequest";
+export type {
+	GroupListItem,
+	GroupRoleItem,
+	GroupDetailResponse,
+	CreateGroupRequest,
+	UpdateGroupRequest,
+	GroupMemberItem,
+	ListGroupMembersRequest,
+	ListGroupMembersResponse,
+} from "./group";
@@ -33,6 +33,9 @@ export interface PermissionItem {
 	/** Per-resource color palette key (allowlist in consts/appColors). Shared by
 	 *  all rows of the same (app_id, resource); empty = neutral fallback. */
 	color: string;
+	/** RFC 3339; set when the app's catalog sync stopped listing the permission.
+	 *  Deleting it confirms the removal. */
+	flagged_at?: string;
 }
 
 /** Maps to models.RoleDetailResponse (GET /api/v1/roles/:id). */
@@ -48,7 +51,25 @@ export interface RoleDetailResponse {
 	/** Color palette key (allowlist in consts/appColors); empty = neutral fallback. */
 	color: string;
 	is_system: boolean;
+	/** Granted to the role itself. */
 	permissions: PermissionItem[];
+	/** Roles of the same app this role includes directly. */
+	includes: RoleRef[];
+	/** Granted only through `includes`, transitively. */
+	inherited_permissions: InheritedPermissionItem[];
+}
+
+/** Maps to models.RoleRef. */
+export interface RoleRef {
+	id: string;
+	code: string;
+	name: string;
+}
+
+/** Maps to models.InheritedPermissionItem. `via` lists the codes of the
+ *  directly included roles the permission comes through. */
+export interface InheritedPermissionItem extends PermissionItem {
+	via: string[];
 }
 
 /** Optional app filter for the role + permission list endpoints (empty = all apps). */
@@ -91,6 +112,12 @@ export interface SetRolePermissionsRequest {
 	permission_ids: number[];
 }
 
+/** Maps to models.SetIncludesRequest (PUT /api/v1/roles/:id/includes). Same
+ *  app only; the backend rejects cycles. Empty clears the includes. */
+export interface SetRoleIncludesRequest {
+	role_ids: string[];
+}
+
 /** One resource:action pair to create — maps to models.PermissionPair. */
 export interface PermissionPair {
 	resource: string;
@@ -125,6 +152,10 @@ export interface AddRoleMembersRequest {
 	user_ids: string[];
 	/** Scope of the assignment — omitted/null = global (user_roles.app_service_id NULL). */
 	app_service_id?: string | null;
+	/** RFC 3339; omitted = active immediately. */
+	starts_at?: string;
+	/** RFC 3339; omitted = permanent. Re-adding a member replaces its window. */
+	expires_at?: string;
 }
 
 /** Maps to models.MemberItem. */
@@ -135,6 +166,10 @@ export interface RoleMemberItem {
 	/** null = global assignment. */
 	app_service_id: string | null;
 	created_at: string;
+	/** RFC 3339; absent = active from creation. */
+	starts_at?: string;
+	/** RFC 3339; absent = permanent. Expired members linger until the expiry job runs. */
+	expires_at?: string;
 }
 
 /** Maps to models.ListMembersRequest query params. */
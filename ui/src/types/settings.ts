@@ -86,6 +86,15 @@ export interface DatabaseBackupConfig {
 	lastBackupPath: string | null;
 	/** Backup files kept after the last run, or null if it never ran. */
 	lastKeptCount: number | null;
+	/**
+	 * Whether the last run copied its backup to off-host storage; null when
+	 * off-host backups are not configured (or it never ran).
+	 */
+	lastRemoteUploaded: boolean | null;
+	/** Why the last off-host copy or prune failed, or null. */
+	lastRemoteError: string | null;
+	/** Whether the last backup was encrypted with the backup key; null if unknown. */
+	lastEncrypted: boolean | null;
 }
 
 export interface UpdateDatabaseBackupConfigRequest {
@@ -20,6 +20,9 @@ export interface AppRole {
 	app_name: string;
 	role_code: string;
 	role_name: string;
+	/** Names of the groups the role is inherited through; absent = held
+	 *  directly only. The user may hold it directly as well. */
+	groups?: string[];
 }
 
 /** Maps to internal/domains/user/models.UserListItem.
@@ -96,3 +96,24 @@ export const formatDateOnly = (timestamp?: string): string => {
 	return `${date.getFullYear()}-${pad2(date.getMonth() + 1)}-${pad2(date.getDate())}`;
 };
 
+
+/**
+ * Compact time left until a future timestamp, e.g. "45m", "5h", "2d 4h".
+ * Past / empty / zero time → "" so callers can treat it as elapsed.
+ */
+export const formatTimeLeft = (timestamp?: string): string => {
+	if (isZeroTime(timestamp)) return "";
+	const date = new Date(timestamp as string);
+	if (Number.isNaN(date.getTime())) return "";
+
+	const diffInMinutes = Math.floor((date.getTime() - Date.now()) / 60000);
+	if (diffInMinutes < 0) return "";
+	if (diffInMinutes < 60) return `${Math.max(1, diffInMinutes)}m`;
+
+	const diffInHours = Math.floor(diffInMinutes / 60);
+	if (diffInHours < 24) return `${diffInHours}h`;
+
+	const diffInDays = Math.floor(diffInHours / 24);
+	const hours = diffInHours % 24;
+	return hours > 0 && diffInDays < 7 ? `${diffInDays}d ${hours}h` : `${diffInDays}d`;
+};